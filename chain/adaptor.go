@@ -1,6 +1,9 @@
 package chain
 
-import "chain-account/rpc/account"
+import (
+	"chain-account/notifier"
+	"chain-account/rpc/account"
+)
 
 type IChainAdaptor interface {
 	GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error)                // 获取支持的链
@@ -23,3 +26,44 @@ type IChainAdaptor interface {
 	GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error)                            // 获取额外数据
 	GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error)                   // 获取NFT列表
 }
+
+// 充值监控，仅部分链支持
+type IDepositAdaptor interface {
+	WatchAddress(req *account.WatchAddressRequest) (*account.WatchAddressResponse, error)             // 注册/移除监控地址
+	GetDepositList(req *account.DepositListRequest) (*account.DepositListResponse, error)             // 查询充值记录
+	GetDepositNotify(req *account.DepositNotifyRequest) (*account.DepositNotifyResponse, error)       // 拉取未确认的充值通知
+	AckDepositNotify(req *account.AckDepositNotifyRequest) (*account.AckDepositNotifyResponse, error) // 确认充值通知
+}
+
+// 发出交易跟踪，仅部分链支持
+type IOutgoingAdaptor interface {
+	ListOutgoingTx(req *account.OutgoingTxListRequest) (*account.OutgoingTxListResponse, error) // 查询已广播交易的状态
+}
+
+// 替换交易（加速/取消），仅支持 EIP-1559 的链
+type IReplaceAdaptor interface {
+	BuildSpeedUpTransaction(req *account.ReplaceTransactionRequest) (*account.ReplaceTransactionResponse, error) // 构建加速交易
+	BuildCancelTransaction(req *account.ReplaceTransactionRequest) (*account.ReplaceTransactionResponse, error)  // 构建取消交易
+}
+
+// 按地址分配 nonce，支持账户模型的链
+type INonceAdaptor interface {
+	GetNextNonce(req *account.NextNonceRequest) (*account.NextNonceResponse, error)       // 分配下一个 nonce
+	ReleaseNonce(req *account.ReleaseNonceRequest) (*account.ReleaseNonceResponse, error) // 归还未使用的 nonce
+	GetNonceGaps(req *account.NonceGapsRequest) (*account.NonceGapsResponse, error)       // 查询 nonce 空洞
+}
+
+// UTXO 查询，支持 UTXO 模型的链
+type IUtxoAdaptor interface {
+	GetUnspentOutputs(req *account.UnspentOutputsRequest) (*account.UnspentOutputsResponse, error) // 查询地址的未花费输出
+}
+
+// 链上事件源，接入 webhook 等通知
+type IEventSource interface {
+	SetNotifier(n notifier.Notifier)
+}
+
+// 有后台任务的适配器，创建后由调度器在设置通知之后启动
+type IBackgroundAdaptor interface {
+	Start()
+}
@@ -0,0 +1,75 @@
+package aptos
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// 单签 ed25519 账户的认证方案
+const ed25519Scheme = 0x00
+
+// 账户地址，32 字节
+type Address [32]byte
+
+// AIP-40 格式：特殊地址为短格式（如 0x1），其他为 0x + 64 位十六进制
+func (a Address) String() string {
+	if a.isSpecial() {
+		return fmt.Sprintf("0x%x", a[31])
+	}
+	return "0x" + hex.EncodeToString(a[:])
+}
+
+// 0x0 ~ 0xf 为框架保留的特殊地址
+func (a Address) isSpecial() bool {
+	for _, b := range a[:31] {
+		if b != 0 {
+			return false
+		}
+	}
+	return a[31] < 0x10
+}
+
+// 解析 0x 开头的地址，允许省略前导 0（如 0x1）
+func ParseAddress(address string) (Address, error) {
+	var a Address
+	hexStr, ok := strings.CutPrefix(address, "0x")
+	if !ok || len(hexStr) == 0 || len(hexStr) > 64 {
+		return a, errors.New("invalid address")
+	}
+	if len(hexStr)%2 == 1 {
+		hexStr = "0" + hexStr
+	}
+	data, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return a, errors.New("invalid address")
+	}
+	copy(a[32-len(data):], data)
+	return a, nil
+}
+
+// 普通账户必须是长格式，特殊地址允许短格式
+func ValidateAddress(address string) bool {
+	a, err := ParseAddress(address)
+	if err != nil {
+		return false
+	}
+	return len(address) == 66 || a.isSpecial()
+}
+
+// ed25519 公钥对应的账户地址：sha3-256(公钥 || 0x00)
+func PubKeyToAddress(publicKey string) (string, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(publicKey, "0x"))
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		return "", errors.New("invalid public key")
+	}
+	return authKey(pubKey).String(), nil
+}
+
+func authKey(pubKey []byte) Address {
+	return Address(sha3.Sum256(append(append([]byte{}, pubKey...), ed25519Scheme)))
+}
@@ -0,0 +1,618 @@
+package aptos
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Aptos"
+
+// APT 的 coin 类型
+const aptosCoinType = "0x1::aptos_coin::AptosCoin"
+
+// 单次 GetBlockByRange 最多查询的区块数，每个区块需要一次请求
+const blockRangeLimit = 100
+
+// GetFee 按该 gas 用量估算转账手续费，覆盖首次转账为接收方创建账户或存储的开销
+const transferGasUnits = 1000
+
+// GetTxByAddress 默认每页数量
+const defaultPageSize = 20
+
+// 转账函数
+const (
+	functionTransfer          = "0x1::aptos_account::transfer"
+	functionTransferCoins     = "0x1::aptos_account::transfer_coins"
+	functionCoinTransfer      = "0x1::coin::transfer"
+	functionFungibleTransfer  = "0x1::primary_fungible_store::transfer"
+	functionTransferFungibles = "0x1::aptos_account::transfer_fungible_assets"
+)
+
+type ChainAdaptor struct {
+	AptosClient IAptos
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.Apt
+	aptosClient, err := NewAptosClient(node.RpcUrl, node.DataApiKey, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		AptosClient: aptosClient,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入 ed25519 公钥 转换成单签账户地址
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeEd25519); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	address, err := PubKeyToAddress(req.PublicKey)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address,
+	}, nil
+}
+
+// 地址格式验证
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 通过区块高度获取区块和其中成功执行的转账
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	block, err := c.AptosClient.GetBlockByHeight(uint64(req.Height), true)
+	if err != nil {
+		log.Error("get block by number fail", "height", req.Height, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	var blockTxList []*account.BlockInfoTransactionList
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		if tx.Type != userTransactionType || !tx.Success {
+			continue
+		}
+		if transfer := parseTransfer(tx); transfer != nil {
+			blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+				From:         transfer.From,
+				To:           transfer.To,
+				TokenAddress: transfer.ContractAddress,
+				Hash:         tx.Hash,
+				Height:       uint64(req.Height),
+				Amount:       transfer.Amount,
+			})
+		}
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          "get block by number success",
+		Height:       req.Height,
+		Hash:         block.BlockHash,
+		Transactions: blockTxList,
+	}, nil
+}
+
+// Aptos 节点不支持按区块哈希查询
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return &account.BlockResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block by hash is not supported",
+	}, nil
+}
+
+// 通过区块高度获取区块头信息，height 为 0 时返回最新区块
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	height := uint64(req.Height)
+	if height == 0 {
+		info, err := c.AptosClient.GetLedgerInfo()
+		if err != nil {
+			log.Error("get ledger info fail", "err", err)
+			return &account.BlockHeaderResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block header by number fail",
+			}, nil
+		}
+		if height, err = strconv.ParseUint(info.BlockHeight, 10, 64); err != nil {
+			log.Error("invalid block height", "height", info.BlockHeight)
+			return &account.BlockHeaderResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block header by number fail",
+			}, nil
+		}
+	}
+	block, err := c.AptosClient.GetBlockByHeight(height, false)
+	if err != nil {
+		log.Error("get block header by number fail", "height", height, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(block),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	return &account.BlockHeaderResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block header by hash is not supported",
+	}, nil
+}
+
+// 获取区间内的区块头
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseUint(req.Start, 10, 64)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseUint(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	if end-start >= blockRangeLimit {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "block range too large",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for height := start; height <= end; height++ {
+		block, err := c.AptosClient.GetBlockByHeight(height, false)
+		if err != nil {
+			log.Error("get block fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		headers = append(headers, toBlockHeader(block))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取账户余额和 sequence_number，contract_address 为 coin 类型或 fungible asset 的 metadata 地址时查询对应余额
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	address, err := ParseAddress(req.Address)
+	if err != nil {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	sequence, err := c.getSequenceNumber(address)
+	if err != nil {
+		log.Error("get account fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	balance, err := c.getBalance(address, req.ContractAddress)
+	if err != nil {
+		log.Error("get balance fail", "address", req.Address, "contract", req.ContractAddress, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get balance fail",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      strconv.FormatUint(sequence, 10),
+		Balance:       balance,
+	}, nil
+}
+
+// 获取fee，单位 octa。
+// 传入 rawTx（BuildUnSignTransaction 返回的 un_sign_tx）时返回该交易的最高手续费 max_gas_amount * gas_unit_price；
+// 否则按节点估算的三档 gas 单价乘以 transferGasUnits
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	if req.RawTx != "" {
+		tx, err := decodeRawTransaction(req.RawTx)
+		if err != nil {
+			log.Error("decode raw transaction fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.MaxGasAmount), new(big.Int).SetUint64(tx.GasUnitPrice)).String()
+		return &account.FeeResponse{
+			Code:      global_const.ReturnCode_SUCCESS,
+			Msg:       "get fee success",
+			SlowFee:   fee,
+			NormalFee: fee,
+			FastFee:   fee,
+		}, nil
+	}
+	estimate, err := c.AptosClient.EstimateGasPrice()
+	if err != nil {
+		log.Error("estimate gas price fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "estimate gas price fail",
+		}, nil
+	}
+	slow, fast := estimate.DeprioritizedGasEstimate, estimate.PrioritizedGasEstimate
+	if slow == 0 {
+		slow = estimate.GasEstimate
+	}
+	if fast == 0 {
+		fast = estimate.GasEstimate
+	}
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   strconv.FormatUint(slow*transferGasUnits, 10),
+		NormalFee: strconv.FormatUint(estimate.GasEstimate*transferGasUnits, 10),
+		FastFee:   strconv.FormatUint(fast*transferGasUnits, 10),
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的十六进制 BCS 签名交易
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	signedTx, err := hex.DecodeString(strings.TrimPrefix(req.RawTx, "0x"))
+	if err != nil || len(signedTx) == 0 {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	hash, err := c.AptosClient.SubmitTransaction(signedTx)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: hash,
+	}, nil
+}
+
+// 按地址分页查询账户发出的交易，按 sequence_number 升序，page 从 1 开始
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	address, err := ParseAddress(req.Address)
+	if err != nil {
+		return &account.TxAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	var start uint64
+	if req.Page > 1 {
+		start = uint64(req.Page-1) * uint64(pageSize)
+	}
+	list, err := c.AptosClient.GetAccountTransactions(address.String(), start, uint64(pageSize))
+	if err != nil && !IsNotFound(err) {
+		log.Error("get account transactions fail", "address", req.Address, "err", err)
+		return &account.TxAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get tx by address fail",
+		}, nil
+	}
+	var txs []*account.TxMessage
+	for i := range list {
+		txs = append(txs, toTxMessage(&list[i]))
+	}
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txs,
+	}, nil
+}
+
+// 通过交易哈希获取交易，已执行的交易通过版本号查询所在区块高度
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	tx, err := c.AptosClient.GetTransactionByHash(req.Hash)
+	if IsNotFound(err) {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get transaction fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	txMessage := toTxMessage(tx)
+	if tx.Type != pendingTransactionType && tx.Version != "" {
+		if block, err := c.AptosClient.GetBlockByVersion(tx.Version); err != nil {
+			log.Warn("get block by version fail", "version", tx.Version, "err", err)
+		} else {
+			txMessage.Height = block.BlockHeight
+		}
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   txMessage,
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// 账户不存在时 sequence_number 为 0
+func (c *ChainAdaptor) getSequenceNumber(address Address) (uint64, error) {
+	info, err := c.AptosClient.GetAccount(address.String())
+	if IsNotFound(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(info.SequenceNumber, 10, 64)
+}
+
+// contract 为空时查询 APT 余额，包含 :: 时为 coin 类型，否则为 fungible asset 的 metadata 地址
+func (c *ChainAdaptor) getBalance(owner Address, contract string) (string, error) {
+	var result []json.RawMessage
+	var err error
+	switch {
+	case contract == "":
+		result, err = c.AptosClient.View("0x1::coin::balance", []string{aptosCoinType}, []any{owner.String()})
+	case strings.Contains(contract, "::"):
+		coinType, parseErr := ParseTypeTag(contract)
+		if parseErr != nil {
+			return "", parseErr
+		}
+		result, err = c.AptosClient.View("0x1::coin::balance", []string{coinType.String()}, []any{owner.String()})
+	default:
+		metadata, parseErr := ParseAddress(contract)
+		if parseErr != nil {
+			return "", parseErr
+		}
+		result, err = c.AptosClient.View("0x1::primary_fungible_store::balance", []string{"0x1::fungible_asset::Metadata"}, []any{owner.String(), metadata.String()})
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(result) == 0 {
+		return "0", nil
+	}
+	var balance string
+	if err := json.Unmarshal(result[0], &balance); err != nil {
+		return "", err
+	}
+	return balance, nil
+}
+
+// 解析入口函数调用中的转账，APT 的 contract_address 为空
+func parseTransfer(tx *Transaction) *Transfer {
+	payload := tx.Payload
+	if payload == nil {
+		return nil
+	}
+	function := normalizeFunction(payload.Function)
+	var contract string
+	var args []json.RawMessage
+	switch function {
+	case functionTransfer:
+		args = payload.Arguments
+	case functionTransferCoins, functionCoinTransfer:
+		if len(payload.TypeArguments) != 1 {
+			return nil
+		}
+		if coinType, err := ParseTypeTag(payload.TypeArguments[0]); err != nil {
+			return nil
+		} else if coinType.String() != aptosCoinType {
+			contract = coinType.String()
+		}
+		args = payload.Arguments
+	case functionFungibleTransfer, functionTransferFungibles:
+		if len(payload.Arguments) != 3 {
+			return nil
+		}
+		metadata, ok := argAddress(payload.Arguments[0])
+		if !ok {
+			return nil
+		}
+		contract, args = metadata, payload.Arguments[1:]
+	default:
+		return nil
+	}
+	if len(args) != 2 {
+		return nil
+	}
+	to, ok := argAddress(args[0])
+	if !ok {
+		return nil
+	}
+	var amount string
+	if json.Unmarshal(args[1], &amount) != nil {
+		return nil
+	}
+	from := tx.Sender
+	if sender, err := ParseAddress(tx.Sender); err == nil {
+		from = sender.String()
+	}
+	return &Transfer{ContractAddress: contract, From: from, To: to, Amount: amount}
+}
+
+// 函数名中的模块地址统一为 AIP-40 格式
+func normalizeFunction(function string) string {
+	address, rest, ok := strings.Cut(function, "::")
+	if !ok {
+		return function
+	}
+	parsed, err := ParseAddress(address)
+	if err != nil {
+		return function
+	}
+	return parsed.String() + "::" + rest
+}
+
+// 地址参数为字符串，Object<T> 参数为 {"inner": 地址}
+func argAddress(arg json.RawMessage) (string, bool) {
+	var value string
+	if json.Unmarshal(arg, &value) != nil {
+		var object struct {
+			Inner string `json:"inner"`
+		}
+		if json.Unmarshal(arg, &object) != nil {
+			return "", false
+		}
+		value = object.Inner
+	}
+	address, err := ParseAddress(value)
+	if err != nil {
+		return "", false
+	}
+	return address.String(), true
+}
+
+func toTxMessage(tx *Transaction) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:     tx.Hash,
+		Status:   txStatus(tx),
+		Datetime: microsToSeconds(tx.Timestamp),
+	}
+	if gasUsed, ok := new(big.Int).SetString(tx.GasUsed, 10); ok {
+		if price, ok := new(big.Int).SetString(tx.GasUnitPrice, 10); ok {
+			txMessage.Fee = gasUsed.Mul(gasUsed, price).String()
+		}
+	}
+	if transfer := parseTransfer(tx); transfer != nil {
+		txMessage.From = transfer.From
+		txMessage.To = transfer.To
+		txMessage.Value = transfer.Amount
+		txMessage.ContractAddress = transfer.ContractAddress
+		if transfer.ContractAddress != "" {
+			txMessage.Type = 1
+		}
+		data, _ := json.Marshal(transfer)
+		txMessage.Data = string(data)
+	}
+	return txMessage
+}
+
+// 执行失败的交易同样上链并扣除手续费，Move abort 视为合约执行失败
+func txStatus(tx *Transaction) account.TxStatus {
+	switch {
+	case tx.Type == pendingTransactionType:
+		return account.TxStatus_Pending
+	case tx.Success:
+		return account.TxStatus_Success
+	case strings.Contains(tx.VmStatus, "Move abort"):
+		return account.TxStatus_ContractExecuteFailed
+	default:
+		return account.TxStatus_Failed
+	}
+}
+
+// 节点返回的时间戳单位为微秒
+func microsToSeconds(micros string) string {
+	value, err := strconv.ParseUint(micros, 10, 64)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatUint(value/1_000_000, 10)
+}
+
+func toBlockHeader(block *Block) *account.BlockHeader {
+	header := &account.BlockHeader{
+		Hash:   block.BlockHash,
+		Number: block.BlockHeight,
+	}
+	if timestamp, err := strconv.ParseUint(block.BlockTimestamp, 10, 64); err == nil {
+		header.Time = timestamp / 1_000_000
+	}
+	return header
+}
@@ -0,0 +1,266 @@
+package aptos
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// aptos-ts-sdk 的 ed25519 测试私钥和对应地址
+const (
+	testPrivateKey = "c5338cd251c22daa8c9c9cc94f498cc8a5c7e1d2e75287a5dda91096fe64efa5"
+	testPublicKey  = "de19e5d1880cac87d57484ce9ed2e84cf0f9599f12e7cc3a52e4e7657a763f2c"
+	testAddress    = "0x978c213990c4833df71548df7ce49d54c759d6b6d932de22b24d56060b7af2aa"
+	testReceiver   = "0x0000000000000000000000000000000000000000000000000000000000000abc"
+)
+
+// 模拟 Aptos 全节点，账户不存在，模拟执行时记录提交的签名交易
+type fakeAptosNode struct {
+	IAptos
+	simulated []byte
+}
+
+func (f *fakeAptosNode) GetLedgerInfo() (*LedgerInfo, error) {
+	return &LedgerInfo{ChainId: 2, BlockHeight: "100"}, nil
+}
+
+func (f *fakeAptosNode) GetAccount(address string) (*AccountInfo, error) {
+	return nil, &ApiError{StatusCode: http.StatusNotFound, ErrorCode: "account_not_found"}
+}
+
+func (f *fakeAptosNode) EstimateGasPrice() (*GasEstimate, error) {
+	return &GasEstimate{DeprioritizedGasEstimate: 100, GasEstimate: 150, PrioritizedGasEstimate: 200}, nil
+}
+
+func (f *fakeAptosNode) SimulateTransaction(signedTx []byte) (*Transaction, error) {
+	f.simulated = signedTx
+	return &Transaction{Success: true, VmStatus: "Executed successfully", GasUsed: "900"}, nil
+}
+
+func testKey() ed25519.PrivateKey {
+	seed, _ := hex.DecodeString(testPrivateKey)
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+func buildUnsigned(t *testing.T, adaptor *ChainAdaptor, transferTx AptosTransferTx) (*RawTransaction, []byte) {
+	txJson, _ := json.Marshal(transferTx)
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	tx, err := decodeRawTransaction(resp.UnSignTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, _ := hex.DecodeString(resp.SignHashes[0])
+	if !bytes.Equal(message, tx.SigningMessage()) {
+		t.Fatal("expected sign hash to be the signing message")
+	}
+	return tx, message
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	adaptor := &ChainAdaptor{}
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPublicKey})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Address != testAddress {
+		t.Fatalf("unexpected address %s", resp.Address)
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPublicKey, KeyType: chain.KeyTypeSecp256k1})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected secp256k1 key type to be rejected")
+	}
+
+	if !ValidateAddress(testAddress) || !ValidateAddress("0x1") || !ValidateAddress("0xa") {
+		t.Fatal("expected long and special addresses to be valid")
+	}
+	if ValidateAddress("0xabc") || ValidateAddress(testAddress[2:]) || ValidateAddress(testAddress+"00") {
+		t.Fatal("expected short, unprefixed and oversize addresses to be invalid")
+	}
+	special, _ := ParseAddress("0x0000000000000000000000000000000000000000000000000000000000000003")
+	if special.String() != "0x3" {
+		t.Fatalf("unexpected special address %s", special.String())
+	}
+	receiver, _ := ParseAddress("0xabc")
+	if receiver.String() != testReceiver {
+		t.Fatalf("unexpected address %s", receiver.String())
+	}
+}
+
+func Test_TypeTag(t *testing.T) {
+	tag, err := ParseTypeTag("0x1::coin::CoinStore<0x1::aptos_coin::AptosCoin>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.String() != "0x1::coin::CoinStore<0x1::aptos_coin::AptosCoin>" {
+		t.Fatalf("unexpected type tag %s", tag.String())
+	}
+	coin, _ := ParseTypeTag(aptosCoinType)
+	expected := append([]byte{typeTagStruct}, make([]byte, 31)...)
+	expected = append(expected, 1, 10)
+	expected = append(expected, "aptos_coin"...)
+	expected = append(expected, 9)
+	expected = append(expected, "AptosCoin"...)
+	expected = append(expected, 0)
+	if !bytes.Equal(appendTypeTag(nil, coin), expected) {
+		t.Fatalf("unexpected type tag encoding %x", appendTypeTag(nil, coin))
+	}
+	vector, err := ParseTypeTag("vector<vector<u8>>")
+	if err != nil || vector.String() != "vector<vector<u8>>" {
+		t.Fatalf("unexpected vector type %v", err)
+	}
+	for _, invalid := range []string{"", "vector<u8", "0x1::coin", "0x1::coin::Coin<u8", "0xzz::a::B"} {
+		if _, err := ParseTypeTag(invalid); err == nil {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+	prefix := (&RawTransaction{}).SigningMessage()[:32]
+	if hex.EncodeToString(prefix) != "b5e97db07fa0bd0e5598aa3643a9bc6f6693bddc1a9fec9e674a461eaa00b193" {
+		t.Fatalf("unexpected signing prefix %x", prefix)
+	}
+}
+
+func Test_BuildAptTransfer(t *testing.T) {
+	node := &fakeAptosNode{}
+	adaptor := &ChainAdaptor{AptosClient: node}
+	tx, message := buildUnsigned(t, adaptor, AptosTransferTx{
+		FromAddress: testAddress,
+		ToAddress:   testReceiver,
+		Amount:      "1000",
+		PublicKey:   testPublicKey,
+	})
+	if tx.Sender.String() != testAddress || tx.SequenceNumber != 0 || tx.ChainId != 2 || tx.GasUnitPrice != 150 || tx.MaxGasAmount != 1800 {
+		t.Fatalf("unexpected transaction %+v", tx)
+	}
+	if tx.Payload.FunctionId() != functionTransfer || len(tx.Payload.Args) != 2 || hex.EncodeToString(tx.Payload.Args[1]) != "e803000000000000" {
+		t.Fatalf("unexpected payload %+v", tx.Payload)
+	}
+	// 模拟执行使用全 0 签名
+	if !bytes.HasSuffix(node.simulated, append([]byte{ed25519.SignatureSize}, make([]byte, ed25519.SignatureSize)...)) {
+		t.Fatal("expected simulation to use an empty signature")
+	}
+
+	unSignTx := hex.EncodeToString(tx.Serialize())
+	signature := ed25519.Sign(testKey(), message)
+	resp, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  unSignTx,
+		Signature: hex.EncodeToString(signature),
+		PublicKey: testPublicKey,
+	})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", resp.Msg)
+	}
+	signedTx, _ := hex.DecodeString(resp.SignedTx)
+	if !bytes.Equal(signedTx, tx.SignedTransaction(testKey().Public().(ed25519.PublicKey), signature)) || resp.Msg != TransactionHash(signedTx) {
+		t.Fatal("unexpected signed transaction")
+	}
+
+	resp, _ = adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  unSignTx,
+		Signature: hex.EncodeToString(make([]byte, ed25519.SignatureSize)),
+		PublicKey: testPublicKey,
+	})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected invalid signature to be rejected")
+	}
+	otherKey := hex.EncodeToString(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public().(ed25519.PublicKey))
+	resp, _ = adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  unSignTx,
+		Signature: hex.EncodeToString(signature),
+		PublicKey: otherKey,
+	})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected public key of another account to be rejected")
+	}
+
+	fee, _ := adaptor.GetFee(&account.FeeRequest{RawTx: unSignTx})
+	if fee.NormalFee != "270000" {
+		t.Fatalf("unexpected fee %s", fee.NormalFee)
+	}
+	fee, _ = adaptor.GetFee(&account.FeeRequest{})
+	if fee.SlowFee != "100000" || fee.NormalFee != "150000" || fee.FastFee != "200000" {
+		t.Fatalf("unexpected fee %+v", fee)
+	}
+}
+
+func Test_BuildTokenTransfer(t *testing.T) {
+	adaptor := &ChainAdaptor{AptosClient: &fakeAptosNode{}}
+	sequence := uint64(7)
+	tx, _ := buildUnsigned(t, adaptor, AptosTransferTx{
+		FromAddress:     testAddress,
+		ToAddress:       testReceiver,
+		Amount:          "5",
+		ContractAddress: "0x1::aptos_coin::AptosCoin",
+		PublicKey:       testPublicKey,
+		SequenceNumber:  &sequence,
+		MaxGasAmount:    3000,
+		GasUnitPrice:    120,
+	})
+	if tx.SequenceNumber != 7 || tx.MaxGasAmount != 3000 || tx.GasUnitPrice != 120 {
+		t.Fatalf("unexpected transaction %+v", tx)
+	}
+	if tx.Payload.FunctionId() != functionTransferCoins || len(tx.Payload.TypeArgs) != 1 || tx.Payload.TypeArgs[0].String() != aptosCoinType {
+		t.Fatalf("unexpected coin payload %+v", tx.Payload)
+	}
+
+	metadata := "0xbae207659db88bea0cbead6da0ed00aac12edcdda169e591cd41c94180b46f3b"
+	tx, _ = buildUnsigned(t, adaptor, AptosTransferTx{
+		FromAddress:     testAddress,
+		ToAddress:       testReceiver,
+		Amount:          "5",
+		ContractAddress: metadata,
+		PublicKey:       testPublicKey,
+	})
+	if tx.Payload.FunctionId() != functionFungibleTransfer || len(tx.Payload.Args) != 3 || "0x"+hex.EncodeToString(tx.Payload.Args[0]) != metadata {
+		t.Fatalf("unexpected fungible asset payload %+v", tx.Payload)
+	}
+
+	txJson, _ := json.Marshal(AptosTransferTx{FromAddress: testReceiver, ToAddress: testReceiver, Amount: "5", PublicKey: testPublicKey})
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected from address mismatch to be rejected")
+	}
+}
+
+func Test_ParseTransfer(t *testing.T) {
+	var txs []Transaction
+	err := json.Unmarshal([]byte(`[
+		{"type":"user_transaction","hash":"0x1","success":true,"sender":"`+testAddress+`","gas_used":"10","gas_unit_price":"100","timestamp":"1700000000123456",
+		 "payload":{"type":"entry_function_payload","function":"0x1::aptos_account::transfer","type_arguments":[],"arguments":["0xabc","1000"]}},
+		{"type":"user_transaction","hash":"0x2","success":true,"sender":"`+testAddress+`",
+		 "payload":{"type":"entry_function_payload","function":"0x0000000000000000000000000000000000000000000000000000000000000001::coin::transfer","type_arguments":["0xf22bede237a07e121b56d91a491eb7bcdfd1f5907926a9e58338f964a01b17fa::asset::USDT"],"arguments":["`+testReceiver+`","5"]}},
+		{"type":"user_transaction","hash":"0x3","success":false,"vm_status":"Move abort in 0x1::fungible_asset: 0x10004","sender":"`+testAddress+`",
+		 "payload":{"type":"entry_function_payload","function":"0x1::primary_fungible_store::transfer","type_arguments":["0x1::fungible_asset::Metadata"],"arguments":[{"inner":"0xa"},"`+testReceiver+`","9"]}},
+		{"type":"pending_transaction","hash":"0x4","sender":"`+testAddress+`",
+		 "payload":{"type":"entry_function_payload","function":"0x1::aptos_account::transfer_coins","type_arguments":["0x1::aptos_coin::AptosCoin"],"arguments":["`+testReceiver+`","1"]}}
+	]`), &txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		contract, amount string
+		status           account.TxStatus
+	}{
+		{"", "1000", account.TxStatus_Success},
+		{"0xf22bede237a07e121b56d91a491eb7bcdfd1f5907926a9e58338f964a01b17fa::asset::USDT", "5", account.TxStatus_Success},
+		{"0xa", "9", account.TxStatus_ContractExecuteFailed},
+		{"", "1", account.TxStatus_Pending},
+	}
+	for i := range txs {
+		message := toTxMessage(&txs[i])
+		if message.From != testAddress || message.To != testReceiver || message.ContractAddress != expected[i].contract ||
+			message.Value != expected[i].amount || message.Status != expected[i].status {
+			t.Fatalf("unexpected tx message %d %+v", i, message)
+		}
+	}
+	if message := toTxMessage(&txs[0]); message.Fee != "1000" || message.Datetime != "1700000000" {
+		t.Fatalf("unexpected fee or datetime %+v", message)
+	}
+}
@@ -0,0 +1,265 @@
+package aptos
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// 提交和模拟 BCS 编码的签名交易使用的 Content-Type
+const signedTransactionContentType = "application/x.aptos.signed_transaction+bcs"
+
+// 交易类型
+const (
+	userTransactionType    = "user_transaction"
+	pendingTransactionType = "pending_transaction"
+)
+
+// 节点返回的错误，账户、交易不存在时 HTTP 状态为 404
+type ApiError struct {
+	StatusCode int
+	Message    string `json:"message"`
+	ErrorCode  string `json:"error_code"`
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("api error %d %s: %s", e.StatusCode, e.ErrorCode, e.Message)
+}
+
+func IsNotFound(err error) bool {
+	var apiErr *ApiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+type LedgerInfo struct {
+	ChainId         uint8  `json:"chain_id"`
+	LedgerVersion   string `json:"ledger_version"`
+	BlockHeight     string `json:"block_height"`
+	LedgerTimestamp string `json:"ledger_timestamp"`
+}
+
+type AccountInfo struct {
+	SequenceNumber    string `json:"sequence_number"`
+	AuthenticationKey string `json:"authentication_key"`
+}
+
+type GasEstimate struct {
+	DeprioritizedGasEstimate uint64 `json:"deprioritized_gas_estimate"`
+	GasEstimate              uint64 `json:"gas_estimate"`
+	PrioritizedGasEstimate   uint64 `json:"prioritized_gas_estimate"`
+}
+
+type EntryFunctionPayload struct {
+	Type          string            `json:"type"`
+	Function      string            `json:"function"`
+	TypeArguments []string          `json:"type_arguments"`
+	Arguments     []json.RawMessage `json:"arguments"`
+}
+
+type Transaction struct {
+	Type           string                `json:"type"`
+	Version        string                `json:"version"`
+	Hash           string                `json:"hash"`
+	Success        bool                  `json:"success"`
+	VmStatus       string                `json:"vm_status"`
+	GasUsed        string                `json:"gas_used"`
+	GasUnitPrice   string                `json:"gas_unit_price"`
+	MaxGasAmount   string                `json:"max_gas_amount"`
+	Sender         string                `json:"sender"`
+	SequenceNumber string                `json:"sequence_number"`
+	Timestamp      string                `json:"timestamp"`
+	Payload        *EntryFunctionPayload `json:"payload"`
+}
+
+type Block struct {
+	BlockHeight    string        `json:"block_height"`
+	BlockHash      string        `json:"block_hash"`
+	BlockTimestamp string        `json:"block_timestamp"`
+	FirstVersion   string        `json:"first_version"`
+	LastVersion    string        `json:"last_version"`
+	Transactions   []Transaction `json:"transactions"`
+}
+
+type IAptos interface {
+	GetLedgerInfo() (*LedgerInfo, error)
+	GetAccount(address string) (*AccountInfo, error)
+	GetBlockByHeight(height uint64, withTransactions bool) (*Block, error)
+	GetBlockByVersion(version string) (*Block, error)
+	EstimateGasPrice() (*GasEstimate, error)
+	View(function string, typeArgs []string, args []any) ([]json.RawMessage, error)
+	SimulateTransaction(signedTx []byte) (*Transaction, error)
+	SubmitTransaction(signedTx []byte) (string, error)
+	GetTransactionByHash(hash string) (*Transaction, error)
+	GetAccountTransactions(address string, start, limit uint64) ([]Transaction, error)
+}
+
+// Aptos 节点 REST API（/v1）客户端，apiKey 通过 Authorization 请求头传入
+type AptosClient struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewAptosClient(rpcUrl, apiKey string, timeout time.Duration) (IAptos, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &AptosClient{
+		url:    strings.TrimSuffix(rpcUrl, "/"),
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (a *AptosClient) do(method, path string, contentType string, body []byte, result any) error {
+	req, err := http.NewRequest(method, a.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	if a.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &ApiError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(respBody, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = string(respBody)
+		}
+		return apiErr
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+func (a *AptosClient) get(path string, result any) error {
+	return a.do(http.MethodGet, path, "", nil, result)
+}
+
+func (a *AptosClient) postJson(path string, params any, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return a.do(http.MethodPost, path, "application/json", body, result)
+}
+
+// 获取链 ID 和最新区块高度
+func (a *AptosClient) GetLedgerInfo() (*LedgerInfo, error) {
+	info := new(LedgerInfo)
+	if err := a.get("/v1", info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// 获取账户 sequence_number，账户不存在时返回 404
+func (a *AptosClient) GetAccount(address string) (*AccountInfo, error) {
+	info := new(AccountInfo)
+	if err := a.get("/v1/accounts/"+address, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (a *AptosClient) GetBlockByHeight(height uint64, withTransactions bool) (*Block, error) {
+	block := new(Block)
+	path := fmt.Sprintf("/v1/blocks/by_height/%d?with_transactions=%t", height, withTransactions)
+	if err := a.get(path, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// 获取包含该版本交易的区块，不返回交易列表
+func (a *AptosClient) GetBlockByVersion(version string) (*Block, error) {
+	block := new(Block)
+	if err := a.get("/v1/blocks/by_version/"+url.PathEscape(version), block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// 获取 gas 单价（octa）
+func (a *AptosClient) EstimateGasPrice() (*GasEstimate, error) {
+	estimate := new(GasEstimate)
+	if err := a.get("/v1/estimate_gas_price", estimate); err != nil {
+		return nil, err
+	}
+	return estimate, nil
+}
+
+// 调用 view 函数
+func (a *AptosClient) View(function string, typeArgs []string, args []any) ([]json.RawMessage, error) {
+	if typeArgs == nil {
+		typeArgs = []string{}
+	}
+	params := map[string]any{"function": function, "type_arguments": typeArgs, "arguments": args}
+	var result []json.RawMessage
+	if err := a.postJson("/v1/view", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 模拟执行签名交易（签名必须无效），由节点估算 gas 单价和 gas 上限
+func (a *AptosClient) SimulateTransaction(signedTx []byte) (*Transaction, error) {
+	path := "/v1/transactions/simulate?estimate_gas_unit_price=true&estimate_max_gas_amount=true"
+	var result []Transaction
+	if err := a.do(http.MethodPost, path, signedTransactionContentType, signedTx, &result); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, errors.New("empty simulation result")
+	}
+	return &result[0], nil
+}
+
+// 提交签名交易，返回交易哈希
+func (a *AptosClient) SubmitTransaction(signedTx []byte) (string, error) {
+	var result Transaction
+	if err := a.do(http.MethodPost, "/v1/transactions", signedTransactionContentType, signedTx, &result); err != nil {
+		return "", err
+	}
+	return result.Hash, nil
+}
+
+func (a *AptosClient) GetTransactionByHash(hash string) (*Transaction, error) {
+	tx := new(Transaction)
+	if err := a.get("/v1/transactions/by_hash/"+url.PathEscape(hash), tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// 账户发出的交易，start 为起始 sequence_number
+func (a *AptosClient) GetAccountTransactions(address string, start, limit uint64) ([]Transaction, error) {
+	query := url.Values{"start": {strconv.FormatUint(start, 10)}, "limit": {strconv.FormatUint(limit, 10)}}
+	var txs []Transaction
+	if err := a.get("/v1/accounts/"+address+"/transactions?"+query.Encode(), &txs); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
@@ -0,0 +1,323 @@
+package aptos
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+
+	"chain-account/common/bcs"
+)
+
+// TypeTag 的枚举序号
+const (
+	typeTagBool    = 0
+	typeTagU8      = 1
+	typeTagU64     = 2
+	typeTagU128    = 3
+	typeTagAddress = 4
+	typeTagSigner  = 5
+	typeTagVector  = 6
+	typeTagStruct  = 7
+	typeTagU16     = 8
+	typeTagU32     = 9
+	typeTagU256    = 10
+)
+
+var primitiveTypeTags = map[string]uint8{
+	"bool":    typeTagBool,
+	"u8":      typeTagU8,
+	"u16":     typeTagU16,
+	"u32":     typeTagU32,
+	"u64":     typeTagU64,
+	"u128":    typeTagU128,
+	"u256":    typeTagU256,
+	"address": typeTagAddress,
+	"signer":  typeTagSigner,
+}
+
+// TransactionPayload::EntryFunction、TransactionAuthenticator::Ed25519、Transaction::UserTransaction 的枚举序号
+const (
+	payloadEntryFunction   = 2
+	authenticatorEd25519   = 0
+	transactionUserVariant = 0
+)
+
+// 签名消息和交易哈希的域分隔前缀
+const (
+	rawTransactionSalt  = "APTOS::RawTransaction"
+	transactionHashSalt = "APTOS::Transaction"
+)
+
+const maxTypeTagDepth = 8
+
+// Move 类型，Struct 不为空时为结构体类型，Vector 不为空时为 vector<T>
+type TypeTag struct {
+	Kind   uint8
+	Vector *TypeTag
+	Struct *StructTag
+}
+
+type StructTag struct {
+	Address  Address
+	Module   string
+	Name     string
+	TypeArgs []TypeTag
+}
+
+func (t *TypeTag) String() string {
+	switch t.Kind {
+	case typeTagVector:
+		return "vector<" + t.Vector.String() + ">"
+	case typeTagStruct:
+		return t.Struct.String()
+	}
+	for name, kind := range primitiveTypeTags {
+		if kind == t.Kind {
+			return name
+		}
+	}
+	return fmt.Sprintf("unknown(%d)", t.Kind)
+}
+
+func (s *StructTag) String() string {
+	name := s.Address.String() + "::" + s.Module + "::" + s.Name
+	if len(s.TypeArgs) == 0 {
+		return name
+	}
+	args := make([]string, len(s.TypeArgs))
+	for i := range s.TypeArgs {
+		args[i] = s.TypeArgs[i].String()
+	}
+	return name + "<" + strings.Join(args, ", ") + ">"
+}
+
+// 解析类型字符串，如 0x1::aptos_coin::AptosCoin、vector<u8>、0x1::coin::CoinStore<0x1::aptos_coin::AptosCoin>
+func ParseTypeTag(s string) (*TypeTag, error) {
+	tag, rest, err := parseTypeTag(strings.ReplaceAll(s, " ", ""), 0)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("invalid type tag %s", s)
+	}
+	return tag, nil
+}
+
+func parseTypeTag(s string, depth int) (*TypeTag, string, error) {
+	if depth > maxTypeTagDepth {
+		return nil, "", errors.New("type tag nested too deep")
+	}
+	end := strings.IndexAny(s, "<>,")
+	if end < 0 {
+		end = len(s)
+	}
+	head := s[:end]
+	if kind, ok := primitiveTypeTags[head]; ok {
+		return &TypeTag{Kind: kind}, s[end:], nil
+	}
+	if head == "vector" {
+		if !strings.HasPrefix(s[end:], "<") {
+			return nil, "", errors.New("invalid vector type")
+		}
+		elem, rest, err := parseTypeTag(s[end+1:], depth+1)
+		if err != nil {
+			return nil, "", err
+		}
+		rest, ok := strings.CutPrefix(rest, ">")
+		if !ok {
+			return nil, "", errors.New("invalid vector type")
+		}
+		return &TypeTag{Kind: typeTagVector, Vector: elem}, rest, nil
+	}
+
+	parts := strings.Split(head, "::")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return nil, "", fmt.Errorf("invalid struct type %s", head)
+	}
+	address, err := ParseAddress(parts[0])
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid struct address %s", parts[0])
+	}
+	tag := &StructTag{Address: address, Module: parts[1], Name: parts[2]}
+	rest := s[end:]
+	if strings.HasPrefix(rest, "<") {
+		rest = rest[1:]
+		for {
+			arg, next, err := parseTypeTag(rest, depth+1)
+			if err != nil {
+				return nil, "", err
+			}
+			tag.TypeArgs = append(tag.TypeArgs, *arg)
+			if next, ok := strings.CutPrefix(next, ","); ok {
+				rest = next
+				continue
+			}
+			next, ok := strings.CutPrefix(next, ">")
+			if !ok {
+				return nil, "", errors.New("invalid type arguments")
+			}
+			rest = next
+			break
+		}
+	}
+	return &TypeTag{Kind: typeTagStruct, Struct: tag}, rest, nil
+}
+
+func decodeAddress(d *bcs.Decoder) Address {
+	var a Address
+	copy(a[:], d.FixedBytes(32))
+	return a
+}
+
+func appendTypeTag(buf []byte, t *TypeTag) []byte {
+	buf = bcs.AppendUleb128(buf, uint64(t.Kind))
+	switch t.Kind {
+	case typeTagVector:
+		return appendTypeTag(buf, t.Vector)
+	case typeTagStruct:
+		buf = append(buf, t.Struct.Address[:]...)
+		buf = bcs.AppendString(buf, t.Struct.Module)
+		buf = bcs.AppendString(buf, t.Struct.Name)
+		buf = bcs.AppendUleb128(buf, uint64(len(t.Struct.TypeArgs)))
+		for i := range t.Struct.TypeArgs {
+			buf = appendTypeTag(buf, &t.Struct.TypeArgs[i])
+		}
+	}
+	return buf
+}
+
+func decodeTypeTag(d *bcs.Decoder, depth int) *TypeTag {
+	if depth > maxTypeTagDepth {
+		return nil
+	}
+	t := &TypeTag{Kind: uint8(d.Uleb128())}
+	switch t.Kind {
+	case typeTagVector:
+		if t.Vector = decodeTypeTag(d, depth+1); t.Vector == nil {
+			return nil
+		}
+	case typeTagStruct:
+		tag := &StructTag{Address: decodeAddress(d), Module: d.Str(), Name: d.Str()}
+		for n := d.SeqLen(); n > 0 && d.Err() == nil; n-- {
+			arg := decodeTypeTag(d, depth+1)
+			if arg == nil {
+				return nil
+			}
+			tag.TypeArgs = append(tag.TypeArgs, *arg)
+		}
+		t.Struct = tag
+	case typeTagBool, typeTagU8, typeTagU16, typeTagU32, typeTagU64, typeTagU128, typeTagU256, typeTagAddress, typeTagSigner:
+	default:
+		return nil
+	}
+	if d.Err() != nil {
+		return nil
+	}
+	return t
+}
+
+// 入口函数调用，Args 为 BCS 编码后的参数
+type EntryFunction struct {
+	ModuleAddress Address
+	ModuleName    string
+	Function      string
+	TypeArgs      []TypeTag
+	Args          [][]byte
+}
+
+// 完整的函数名，如 0x1::aptos_account::transfer
+func (f *EntryFunction) FunctionId() string {
+	return f.ModuleAddress.String() + "::" + f.ModuleName + "::" + f.Function
+}
+
+// 未签名交易
+type RawTransaction struct {
+	Sender                  Address
+	SequenceNumber          uint64
+	Payload                 EntryFunction
+	MaxGasAmount            uint64
+	GasUnitPrice            uint64
+	ExpirationTimestampSecs uint64
+	ChainId                 uint8
+}
+
+func (tx *RawTransaction) Serialize() []byte {
+	buf := append([]byte{}, tx.Sender[:]...)
+	buf = bcs.AppendU64(buf, tx.SequenceNumber)
+	buf = bcs.AppendUleb128(buf, payloadEntryFunction)
+	buf = append(buf, tx.Payload.ModuleAddress[:]...)
+	buf = bcs.AppendString(buf, tx.Payload.ModuleName)
+	buf = bcs.AppendString(buf, tx.Payload.Function)
+	buf = bcs.AppendUleb128(buf, uint64(len(tx.Payload.TypeArgs)))
+	for i := range tx.Payload.TypeArgs {
+		buf = appendTypeTag(buf, &tx.Payload.TypeArgs[i])
+	}
+	buf = bcs.AppendUleb128(buf, uint64(len(tx.Payload.Args)))
+	for _, arg := range tx.Payload.Args {
+		buf = bcs.AppendBytes(buf, arg)
+	}
+	buf = bcs.AppendU64(buf, tx.MaxGasAmount)
+	buf = bcs.AppendU64(buf, tx.GasUnitPrice)
+	buf = bcs.AppendU64(buf, tx.ExpirationTimestampSecs)
+	return append(buf, tx.ChainId)
+}
+
+// 解码未签名交易，只支持入口函数调用
+func DeserializeRawTransaction(data []byte) (*RawTransaction, error) {
+	d := bcs.NewDecoder(data)
+	tx := &RawTransaction{Sender: decodeAddress(d), SequenceNumber: d.U64()}
+	if variant := d.Uleb128(); d.Err() == nil && variant != payloadEntryFunction {
+		return nil, fmt.Errorf("unsupported payload type %d", variant)
+	}
+	tx.Payload.ModuleAddress = decodeAddress(d)
+	tx.Payload.ModuleName = d.Str()
+	tx.Payload.Function = d.Str()
+	for n := d.SeqLen(); n > 0 && d.Err() == nil; n-- {
+		tag := decodeTypeTag(d, 0)
+		if tag == nil {
+			return nil, errors.New("invalid type argument")
+		}
+		tx.Payload.TypeArgs = append(tx.Payload.TypeArgs, *tag)
+	}
+	for n := d.SeqLen(); n > 0 && d.Err() == nil; n-- {
+		tx.Payload.Args = append(tx.Payload.Args, d.Bytes())
+	}
+	tx.MaxGasAmount = d.U64()
+	tx.GasUnitPrice = d.U64()
+	tx.ExpirationTimestampSecs = d.U64()
+	tx.ChainId = d.U8()
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	if d.Len() != 0 {
+		return nil, errors.New("unexpected trailing bytes")
+	}
+	return tx, nil
+}
+
+// 待签名消息：sha3-256("APTOS::RawTransaction") || BCS(RawTransaction)
+func (tx *RawTransaction) SigningMessage() []byte {
+	prefix := sha3.Sum256([]byte(rawTransactionSalt))
+	return append(prefix[:], tx.Serialize()...)
+}
+
+// 单签 ed25519 签名交易
+func (tx *RawTransaction) SignedTransaction(pubKey, signature []byte) []byte {
+	buf := tx.Serialize()
+	buf = bcs.AppendUleb128(buf, authenticatorEd25519)
+	buf = bcs.AppendBytes(buf, pubKey)
+	return bcs.AppendBytes(buf, signature)
+}
+
+// 交易哈希：sha3-256(sha3-256("APTOS::Transaction") || 0x00 || BCS(SignedTransaction))
+func TransactionHash(signedTx []byte) string {
+	prefix := sha3.Sum256([]byte(transactionHashSalt))
+	hasher := sha3.New256()
+	hasher.Write(prefix[:])
+	hasher.Write([]byte{transactionUserVariant})
+	hasher.Write(signedTx)
+	return "0x" + hex.EncodeToString(hasher.Sum(nil))
+}
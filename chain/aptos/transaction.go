@@ -0,0 +1,242 @@
+package aptos
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/bcs"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 交易的有效期，需要在过期前完成签名和广播
+const transactionTtl = 10 * time.Minute
+
+// 模拟执行时使用的 gas 上限，节点按账户余额估算实际上限
+const simulationMaxGasAmount = 200_000
+
+// gas 上限为模拟执行 gas 用量的倍数，且不低于 minMaxGasAmount
+const (
+	gasAmountMultiplier = 2
+	minMaxGasAmount     = 1000
+)
+
+// 构建未签名交易：un_sign_tx 为十六进制 BCS 编码的 RawTransaction，sign_hashes 为待签名消息（非哈希，ed25519 直接对其签名）
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx AptosTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	tx, err := c.buildTransfer(&transferTx)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   hex.EncodeToString(tx.Serialize()),
+		SignHashes: []string{hex.EncodeToString(tx.SigningMessage())},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 un_sign_tx，signature 为 64 字节 ed25519 签名，public_key 为发送方公钥。
+// 返回的 signed_tx 为十六进制 BCS 编码的 SignedTransaction，msg 为交易哈希。
+// 只支持认证密钥未轮换的单签账户
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	tx, err := decodeRawTransaction(req.Base64Tx)
+	if err != nil {
+		log.Error("decode raw transaction fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(req.PublicKey, "0x"))
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	if authKey(pubKey) != tx.Sender {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "public key does not match sender",
+		}, nil
+	}
+	if int64(tx.ExpirationTimestampSecs) < time.Now().Unix() {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "transaction expired",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	if !ed25519.Verify(pubKey, tx.SigningMessage(), signature) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "signature verification failed",
+		}, nil
+	}
+	signedTx := tx.SignedTransaction(pubKey, signature)
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      TransactionHash(signedTx),
+		SignedTx: hex.EncodeToString(signedTx),
+	}, nil
+}
+
+// 校验发送方地址，查询链 ID、sequence_number 和 gas 单价，通过模拟执行估算 gas 上限
+func (c *ChainAdaptor) buildTransfer(transferTx *AptosTransferTx) (*RawTransaction, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(transferTx.PublicKey, "0x"))
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key")
+	}
+	from, err := ParseAddress(transferTx.FromAddress)
+	if err != nil {
+		return nil, errors.New("invalid from address")
+	}
+	if from != authKey(pubKey) {
+		return nil, errors.New("from address does not match public key")
+	}
+	if !ValidateAddress(transferTx.ToAddress) {
+		return nil, errors.New("invalid to address")
+	}
+	to, _ := ParseAddress(transferTx.ToAddress)
+	amount, err := strconv.ParseUint(transferTx.Amount, 10, 64)
+	if err != nil || amount == 0 {
+		return nil, errors.New("invalid amount")
+	}
+	payload, err := transferPayload(to, amount, transferTx.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := c.AptosClient.GetLedgerInfo()
+	if err != nil {
+		return nil, fmt.Errorf("get ledger info fail: %w", err)
+	}
+	tx := &RawTransaction{
+		Sender:                  from,
+		Payload:                 *payload,
+		MaxGasAmount:            transferTx.MaxGasAmount,
+		GasUnitPrice:            transferTx.GasUnitPrice,
+		ExpirationTimestampSecs: uint64(time.Now().Add(transactionTtl).Unix()),
+		ChainId:                 info.ChainId,
+	}
+	if transferTx.SequenceNumber != nil {
+		tx.SequenceNumber = *transferTx.SequenceNumber
+	} else if tx.SequenceNumber, err = c.getSequenceNumber(from); err != nil {
+		return nil, fmt.Errorf("get sequence number fail: %w", err)
+	}
+	if tx.GasUnitPrice == 0 {
+		estimate, err := c.AptosClient.EstimateGasPrice()
+		if err != nil {
+			return nil, fmt.Errorf("estimate gas price fail: %w", err)
+		}
+		tx.GasUnitPrice = estimate.GasEstimate
+	}
+	if tx.MaxGasAmount == 0 {
+		if tx.MaxGasAmount, err = c.estimateMaxGasAmount(tx, pubKey); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// 使用全 0 签名模拟执行，gas 上限为 gas 用量的 gasAmountMultiplier 倍
+func (c *ChainAdaptor) estimateMaxGasAmount(tx *RawTransaction, pubKey []byte) (uint64, error) {
+	simulated := *tx
+	simulated.MaxGasAmount = simulationMaxGasAmount
+	result, err := c.AptosClient.SimulateTransaction(simulated.SignedTransaction(pubKey, make([]byte, ed25519.SignatureSize)))
+	if err != nil {
+		return 0, fmt.Errorf("simulate transaction fail: %w", err)
+	}
+	if !result.Success {
+		return 0, fmt.Errorf("simulate transaction fail: %s", result.VmStatus)
+	}
+	gasUsed, err := strconv.ParseUint(result.GasUsed, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid gas used %s", result.GasUsed)
+	}
+	return max(gasUsed*gasAmountMultiplier, minMaxGasAmount), nil
+}
+
+// contract 为空时调用 aptos_account::transfer 转账 APT；包含 :: 时调用 aptos_account::transfer_coins<T>；
+// 否则为 fungible asset 的 metadata 地址，调用 primary_fungible_store::transfer
+func transferPayload(to Address, amount uint64, contract string) (*EntryFunction, error) {
+	amountArg := bcs.AppendU64(nil, amount)
+	switch {
+	case contract == "":
+		return &EntryFunction{
+			ModuleAddress: Address{31: 1},
+			ModuleName:    "aptos_account",
+			Function:      "transfer",
+			Args:          [][]byte{to[:], amountArg},
+		}, nil
+	case strings.Contains(contract, "::"):
+		coinType, err := ParseTypeTag(contract)
+		if err != nil || coinType.Struct == nil {
+			return nil, errors.New("invalid coin type")
+		}
+		return &EntryFunction{
+			ModuleAddress: Address{31: 1},
+			ModuleName:    "aptos_account",
+			Function:      "transfer_coins",
+			TypeArgs:      []TypeTag{*coinType},
+			Args:          [][]byte{to[:], amountArg},
+		}, nil
+	default:
+		metadata, err := ParseAddress(contract)
+		if err != nil {
+			return nil, errors.New("invalid contract address")
+		}
+		metadataType, _ := ParseTypeTag("0x1::fungible_asset::Metadata")
+		return &EntryFunction{
+			ModuleAddress: Address{31: 1},
+			ModuleName:    "primary_fungible_store",
+			Function:      "transfer",
+			TypeArgs:      []TypeTag{*metadataType},
+			Args:          [][]byte{metadata[:], to[:], amountArg},
+		}, nil
+	}
+}
+
+// 解析 BuildUnSignTransaction 返回的 un_sign_tx
+func decodeRawTransaction(unSignTx string) (*RawTransaction, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(unSignTx, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	return DeserializeRawTransaction(data)
+}
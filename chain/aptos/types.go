@@ -0,0 +1,28 @@
+package aptos
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type AptosTransferTx struct {
+	// 发送方地址，需与 public_key 推导出的地址一致
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	// 单位为币种的最小单位，APT 为 octa
+	Amount string `json:"amount"`
+	// 为空时转账 APT；包含 :: 时为 coin 类型（如 0x1::aptos_coin::AptosCoin），否则为 fungible asset 的 metadata 地址
+	ContractAddress string `json:"contract_address"`
+	// 十六进制 ed25519 公钥
+	PublicKey string `json:"public_key"`
+	// 为空时通过节点获取
+	SequenceNumber *uint64 `json:"sequence_number"`
+	// 为 0 时通过模拟执行估算
+	MaxGasAmount uint64 `json:"max_gas_amount"`
+	// 为 0 时使用节点估算的 gas 单价
+	GasUnitPrice uint64 `json:"gas_unit_price"`
+}
+
+// 交易中解析出的 APT、coin 或 fungible asset 转账
+type Transfer struct {
+	ContractAddress string `json:"contract_address"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Amount          string `json:"amount"`
+}
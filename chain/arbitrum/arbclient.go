@@ -0,0 +1,130 @@
+package arbitrum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"chain-account/chain/ethereum"
+	"chain-account/config"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// Arbitrum 特有的节点接口，交易和收据按 Nitro 格式解析以支持 Arbitrum 交易类型
+type IArbitrum interface {
+	CallContract(msg geth.CallMsg) ([]byte, error)
+	BlockByNumber(*big.Int) (*RpcBlock, error)
+	BlockByHash(common.Hash) (*RpcBlock, error)
+	TxByHash(common.Hash) (*RpcTransaction, error)
+	TxReceiptByHash(common.Hash) (*RpcReceipt, error)
+	Close()
+}
+
+type ArbClient struct {
+	rpc     ethereum.IRpc
+	timeout time.Duration
+}
+
+// 与 EthClient 使用同样的 RPC 连接栈（重试、熔断、请求合并等）
+func NewArbClient(rpcUrl string, timeout time.Duration, conf config.Rpc) (IArbitrum, error) {
+	if rpcUrl == "" {
+		return nil, errors.New("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	client, err := ethereum.DialRpc(context.Background(), rpcUrl, conf)
+	if err != nil {
+		return nil, err
+	}
+	return &ArbClient{rpc: client, timeout: timeout}, nil
+}
+
+// eth_call 调用合约（latest 区块），NodeInterface 等虚拟合约也通过 eth_call 调用
+func (c *ArbClient) CallContract(msg geth.CallMsg) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	arg := map[string]interface{}{
+		"to":   msg.To,
+		"data": hexutil.Bytes(msg.Data),
+	}
+	if msg.From != (common.Address{}) {
+		arg["from"] = msg.From
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	var result hexutil.Bytes
+	if err := c.rpc.CallContext(ctx, &result, "eth_call", arg, "latest"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *ArbClient) BlockByNumber(number *big.Int) (*RpcBlock, error) {
+	arg := "latest"
+	if number != nil {
+		arg = hexutil.EncodeBig(number)
+	}
+	return c.getBlock("eth_getBlockByNumber", arg)
+}
+
+func (c *ArbClient) BlockByHash(hash common.Hash) (*RpcBlock, error) {
+	return c.getBlock("eth_getBlockByHash", hash)
+}
+
+func (c *ArbClient) getBlock(method string, arg interface{}) (*RpcBlock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var block *RpcBlock
+	if err := c.rpc.CallContext(ctx, &block, method, arg, true); err != nil {
+		return nil, err
+	} else if block == nil {
+		return nil, geth.NotFound
+	}
+	return block, nil
+}
+
+func (c *ArbClient) TxByHash(hash common.Hash) (*RpcTransaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var tx *RpcTransaction
+	if err := c.rpc.CallContext(ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		return nil, err
+	} else if tx == nil {
+		return nil, geth.NotFound
+	}
+	return tx, nil
+}
+
+func (c *ArbClient) TxReceiptByHash(hash common.Hash) (*RpcReceipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var receipt *RpcReceipt
+	if err := c.rpc.CallContext(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	} else if receipt == nil {
+		return nil, geth.NotFound
+	}
+	return receipt, nil
+}
+
+func (c *ArbClient) Close() {
+	c.rpc.Close()
+}
+
+// 合约调用被回滚，如 ticket 已兑付或过期时调用 getTimeout
+func isReverted(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "execution reverted")
+}
@@ -0,0 +1,321 @@
+package arbitrum
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/status-im/keycard-go/hexutils"
+
+	"chain-account/chain"
+	"chain-account/chain/ethereum"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const (
+	ChainName     = "Arbitrum"
+	NovaChainName = "ArbitrumNova"
+)
+
+// Arbitrum 链参数，Nova 没有测试网
+type ChainParams struct {
+	Name           string
+	ChainId        uint64
+	TestnetChainId uint64
+}
+
+var (
+	OneParams  = ChainParams{Name: ChainName, ChainId: global_const.ArbitrumChainId, TestnetChainId: global_const.ArbitrumSepoliaChainId}
+	NovaParams = ChainParams{Name: NovaChainName, ChainId: global_const.ArbitrumNovaChainId}
+)
+
+// 在以太坊适配器的基础上通过 NodeInterface 估算包含 L1 部分的 gas，并解析 Arbitrum 交易类型和 retryable ticket，
+// 其余接口沿用以太坊的实现
+type ChainAdaptor struct {
+	*ethereum.ChainAdaptor
+	ArbClient IArbitrum
+	chainId   *big.Int
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(OneParams, con.WalletNode.Arb, con)
+}
+
+func NewNovaAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(NovaParams, con.WalletNode.ArbNova, con)
+}
+
+func newChainAdaptor(params ChainParams, node config.Node, con *config.Config) (*ChainAdaptor, error) {
+	network := con.NetWork
+	if node.Network != "" {
+		network = node.Network
+	}
+	if node.ChainId == 0 {
+		node.ChainId = params.ChainId
+		if network == "testnet" {
+			node.ChainId = params.TestnetChainId
+		}
+	}
+	if node.ChainId == 0 {
+		return nil, errors.New(params.Name + " chain id is required")
+	}
+	evmAdaptor, err := ethereum.NewEvmChainAdaptor(params.Name, strings.ToLower(params.Name), node, con.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	arbClient, err := NewArbClient(node.RpcUrl, time.Duration(node.TimeOut)*time.Second, node.Rpc)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		ChainAdaptor: evmAdaptor,
+		ArbClient:    arbClient,
+		chainId:      new(big.Int).SetUint64(node.ChainId),
+	}, nil
+}
+
+// 在以太坊费用的基础上追加 gas limit 和 L1 部分的费用（wei），格式为 gasPrice|gasTipCap|倍数|gasLimit|l1Fee。
+// gasLimit 已包含 L1 部分的 gas；raw_tx 为十六进制的未签名交易，为空时按 ETH 转账估算，address 为发送方地址
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	resp, err := c.ChainAdaptor.GetFee(req)
+	if err != nil || resp.Code != global_const.ReturnCode_SUCCESS {
+		return resp, err
+	}
+	msg := geth.CallMsg{To: &common.Address{}}
+	if req.RawTx != "" {
+		rawTx, err := hexutil.Decode(req.RawTx)
+		var tx types.Transaction
+		if err == nil {
+			err = tx.UnmarshalBinary(rawTx)
+		}
+		if err != nil {
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		msg.To, msg.Value, msg.Data = tx.To(), tx.Value(), tx.Data()
+	}
+	if req.Address != "" {
+		msg.From = common.HexToAddress(req.Address)
+	}
+	components, err := c.GasEstimateComponents(msg)
+	if err != nil {
+		log.Error("gas estimate components fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "gas estimate components fail",
+		}, nil
+	}
+	l1Fee := new(big.Int).Mul(new(big.Int).SetUint64(components.GasEstimateForL1), components.BaseFee)
+	suffix := "|" + strconv.FormatUint(components.GasEstimate, 10) + "|" + l1Fee.String()
+	resp.SlowFee += suffix
+	resp.NormalFee += suffix
+	resp.FastFee += suffix
+	return resp, nil
+}
+
+// 通过 NodeInterface 估算交易的 gas，to 为空时按合约创建估算
+func (c *ChainAdaptor) GasEstimateComponents(msg geth.CallMsg) (*GasEstimateComponents, error) {
+	to := common.Address{}
+	if msg.To != nil {
+		to = *msg.To
+	}
+	result, err := c.ArbClient.CallContract(geth.CallMsg{
+		From:  msg.From,
+		To:    &NodeInterfaceAddress,
+		Value: msg.Value,
+		Data:  encodeGasEstimateComponents(to, msg.To == nil, msg.Data),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeGasEstimateComponents(result)
+}
+
+// 按Hash获取交易详情，手续费为 gasUsed*effectiveGasPrice（gasUsed 已包含 gasUsedForL1），type 为交易类型。
+// L1 消息产生的交易通过 l1_origin 标识来源，创建 retryable ticket 的交易返回 ticket 状态和兑付交易哈希
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	hash := common.HexToHash(req.Hash)
+	tx, err := c.ArbClient.TxByHash(hash)
+	if errors.Is(err, geth.NotFound) {
+		// 节点上查不到的交易（如已被替换或丢弃）以跟踪记录为准
+		if c.Outgoing != nil {
+			return c.ChainAdaptor.GetTxByHash(req)
+		}
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx: &account.TxMessage{
+				Hash:   req.Hash,
+				Status: account.TxStatus_NotFound,
+			},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get tx by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+
+	// 没有收据说明交易还在 mempool 中
+	receipt, err := c.ArbClient.TxReceiptByHash(hash)
+	if err != nil && !errors.Is(err, geth.NotFound) {
+		log.Error("get tx receipt by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get tx receipt by hash fail",
+		}, nil
+	}
+
+	txMessage := transferMessage(tx)
+	txMessage.Status = account.TxStatus_Pending
+	txMessage.Type = int32(tx.Type)
+	if receipt != nil {
+		txMessage.Index = uint32(receipt.TransactionIndex)
+		txMessage.Height = bigOrZero(receipt.BlockNumber.ToInt()).String()
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(uint64(receipt.GasUsed)), bigOrZero(receipt.EffectiveGasPrice.ToInt()))
+		txMessage.Fee = fee.String()
+		txMessage.Status = account.TxStatus_Failed
+		if uint64(receipt.Status) == types.ReceiptStatusSuccessful {
+			txMessage.Status = account.TxStatus_Success
+		}
+		if tx.To == nil && receipt.ContractAddress != nil {
+			txMessage.To = receipt.ContractAddress.Hex()
+		}
+	}
+	if tx.Type == SubmitRetryableTxType && receipt != nil {
+		status, err := c.RetryableTicketStatus(hash)
+		if err != nil {
+			log.Error("get retryable ticket status fail", "err", err)
+			return &account.TxHashResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get retryable ticket status fail",
+			}, nil
+		}
+		txMessage.RetryableStatus = string(status.State)
+		if status.State == RetryableRedeemed {
+			txMessage.RetryTxHash = status.RetryTxHash.Hex()
+		}
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   txMessage,
+	}, nil
+}
+
+// 通过区块号获取区块数据，跳过 ArbOS 内部交易和 retryable ticket 创建交易（金额在兑付交易中转出），
+// L1 消息产生的交易通过 l1_origin 标识来源
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	var number *big.Int
+	if req.Height != 0 {
+		number = big.NewInt(req.Height)
+	}
+	block, err := c.ArbClient.BlockByNumber(number)
+	if err != nil {
+		log.Error("get block by number fail", "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return blockResponse(block, "get block by number success"), nil
+}
+
+// 通过区块Hash获取区块数据
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	block, err := c.ArbClient.BlockByHash(common.HexToHash(req.Hash))
+	if err != nil {
+		log.Error("get block by hash fail", "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return blockResponse(block, "get block by hash success"), nil
+}
+
+func blockResponse(block *RpcBlock, msg string) *account.BlockResponse {
+	height := uint64(block.Number)
+	var blockTxList []*account.BlockInfoTransactionList
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		if tx.Type == InternalTxType || tx.Type == SubmitRetryableTxType {
+			continue
+		}
+		itemTx := &account.BlockInfoTransactionList{
+			From:   tx.From.Hex(),
+			Hash:   tx.Hash.Hex(),
+			Amount: bigOrZero(tx.Value.ToInt()).String(),
+			Height: height,
+		}
+		itemTx.L1Origin, itemTx.L1OriginId = l1Origin(tx)
+		if tx.To != nil {
+			itemTx.To = tx.To.Hex()
+		}
+		blockTxList = append(blockTxList, itemTx)
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          msg,
+		Height:       int64(height),
+		Hash:         block.Hash.Hex(),
+		BaseFee:      block.BaseFee,
+		Transactions: blockTxList,
+	}
+}
+
+// 解析 ETH 或 ERC20 转账，ERC20 转账的 to 和 value 取自调用数据
+func transferMessage(tx *RpcTransaction) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:            tx.Hash.Hex(),
+		From:            tx.From.Hex(),
+		Value:           bigOrZero(tx.Value.ToInt()).String(),
+		ContractAddress: global_const.ZeroAddress,
+		Data:            hexutils.BytesToHex(tx.Input),
+	}
+	txMessage.L1Origin, txMessage.L1OriginId = l1Origin(tx)
+	if tx.To == nil {
+		return txMessage
+	}
+	txMessage.To = tx.To.Hex()
+	if len(tx.Input) >= 68 && hexutil.Encode(tx.Input[:4]) == "0xa9059cbb" {
+		txMessage.To = common.BytesToAddress(tx.Input[16:36]).Hex()
+		txMessage.Value = new(big.Int).SetBytes(tx.Input[36:68]).String()
+		txMessage.ContractAddress = tx.To.Hex()
+	}
+	return txMessage
+}
+
+// L1 消息产生的交易的来源和标识：充值为 deposit 和 requestId，合约调用为 message 和 requestId，
+// retryable ticket 兑付为 retryable_redeem 和 ticketId
+func l1Origin(tx *RpcTransaction) (string, string) {
+	switch tx.Type {
+	case DepositTxType:
+		return global_const.L1OriginDeposit, hashOrEmpty(tx.RequestId)
+	case UnsignedTxType, ContractTxType:
+		return global_const.L1OriginMessage, hashOrEmpty(tx.RequestId)
+	case RetryTxType:
+		return global_const.L1OriginRetryableRedeem, hashOrEmpty(tx.TicketId)
+	}
+	return "", ""
+}
+
+func hashOrEmpty(hash *common.Hash) string {
+	if hash == nil {
+		return ""
+	}
+	return hash.Hex()
+}
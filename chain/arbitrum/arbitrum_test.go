@@ -0,0 +1,259 @@
+package arbitrum
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"chain-account/chain/ethereum"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+var (
+	testSender   = common.HexToAddress("0x62EccDa8bB2Ae5690E319F3eFde897dEAeD86631")
+	testReceiver = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testTicketId = common.HexToHash("0x69")
+	testRetryTx  = common.HexToHash("0x68")
+)
+
+// 模拟 Arbitrum 节点，timeout 为 nil 时 getTimeout 回滚
+type fakeArbitrum struct {
+	IArbitrum
+	calls    []geth.CallMsg
+	timeout  []byte
+	block    *RpcBlock
+	txs      map[common.Hash]*RpcTransaction
+	receipts map[common.Hash]*RpcReceipt
+}
+
+func (f *fakeArbitrum) CallContract(msg geth.CallMsg) ([]byte, error) {
+	f.calls = append(f.calls, msg)
+	switch *msg.To {
+	case NodeInterfaceAddress:
+		result := append(uint256(30000), uint256(9000)...)
+		result = append(result, uint256(10_000_000)...)
+		return append(result, uint256(20_000_000_000)...), nil
+	case ArbRetryableTxAddress:
+		if f.timeout == nil {
+			return nil, &revertError{}
+		}
+		return f.timeout, nil
+	}
+	return nil, &revertError{}
+}
+
+func (f *fakeArbitrum) BlockByNumber(number *big.Int) (*RpcBlock, error) {
+	return f.block, nil
+}
+
+func (f *fakeArbitrum) TxByHash(hash common.Hash) (*RpcTransaction, error) {
+	if tx, ok := f.txs[hash]; ok {
+		return tx, nil
+	}
+	return nil, geth.NotFound
+}
+
+func (f *fakeArbitrum) TxReceiptByHash(hash common.Hash) (*RpcReceipt, error) {
+	if receipt, ok := f.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, geth.NotFound
+}
+
+type revertError struct{}
+
+func (e *revertError) Error() string { return "execution reverted: NoTicketWithID" }
+
+// logs 为手动兑付的 RedeemScheduled 日志
+type fakeEth struct {
+	ethereum.IEth
+	logs []types.Log
+}
+
+func (f *fakeEth) SuggestGasPrice() (*big.Int, error) { return big.NewInt(10_000_000), nil }
+
+func (f *fakeEth) SuggestGasTipCap() (*big.Int, error) { return big.NewInt(0), nil }
+
+func (f *fakeEth) FilterLogs(query geth.FilterQuery) (ethereum.Logs, error) {
+	return ethereum.Logs{Logs: f.logs}, nil
+}
+
+func uint256(v int64) []byte {
+	return common.LeftPadBytes(big.NewInt(v).Bytes(), 32)
+}
+
+func newTestAdaptor(client *fakeArbitrum, eth *fakeEth) *ChainAdaptor {
+	return &ChainAdaptor{
+		ChainAdaptor: &ethereum.ChainAdaptor{EthClient: eth},
+		ArbClient:    client,
+		chainId:      big.NewInt(42161),
+	}
+}
+
+func redeemScheduledLog(retryTxHash common.Hash) *types.Log {
+	return &types.Log{
+		Address: ArbRetryableTxAddress,
+		Topics:  []common.Hash{redeemScheduledTopic, testTicketId, retryTxHash, common.BigToHash(big.NewInt(0))},
+	}
+}
+
+func Test_GetFee(t *testing.T) {
+	client := &fakeArbitrum{}
+	adaptor := newTestAdaptor(client, &fakeEth{})
+	resp, err := adaptor.GetFee(&account.FeeRequest{Address: testSender.Hex()})
+	if err != nil || resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("GetFee: %v %v", err, resp)
+	}
+	// gasLimit 为 gasEstimate，L1 部分费用为 gasEstimateForL1 * baseFee
+	if resp.SlowFee != "10000000|0|30000|90000000000" || resp.FastFee != "10000000|0|*3|30000|90000000000" {
+		t.Fatalf("unexpected fee %v", resp)
+	}
+	call := client.calls[0]
+	if call.From != testSender || !bytes.Equal(call.Data[:4], gasEstimateComponentsSelector) {
+		t.Fatalf("unexpected call %+v", call)
+	}
+
+	// 按指定交易估算，to 为 ERC20 合约
+	token := common.HexToAddress("0xFd086bC7CD5C481DCC9C85ebE478A1C0b69FCbb9")
+	data := ethereum.BuildErc20Data(testReceiver, big.NewInt(700))
+	rawTx, _ := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(42161), To: &token, Data: data, Value: new(big.Int)}).MarshalBinary()
+	resp, _ = adaptor.GetFee(&account.FeeRequest{RawTx: hexutil.Encode(rawTx)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("unexpected response %v", resp)
+	}
+	if expected := encodeGasEstimateComponents(token, false, data); !bytes.Equal(client.calls[1].Data, expected) {
+		t.Fatalf("unexpected call data %x", client.calls[1].Data)
+	}
+	resp, _ = adaptor.GetFee(&account.FeeRequest{RawTx: "0x1234"})
+	if resp.Code != global_const.ReturnCode_ERROR || resp.Msg != "invalid raw tx" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+}
+
+func Test_EncodeGasEstimateComponents(t *testing.T) {
+	input := encodeGasEstimateComponents(testReceiver, true, []byte{1, 2, 3})
+	expected := append([]byte{}, gasEstimateComponentsSelector...)
+	expected = append(expected, common.LeftPadBytes(testReceiver.Bytes(), 32)...)
+	expected = append(expected, uint256(1)...)
+	expected = append(expected, uint256(96)...)
+	expected = append(expected, uint256(3)...)
+	expected = append(expected, common.RightPadBytes([]byte{1, 2, 3}, 32)...)
+	if !bytes.Equal(input, expected) {
+		t.Fatalf("unexpected input %x", input)
+	}
+}
+
+func Test_GetBlockByNumber(t *testing.T) {
+	requestId := common.HexToHash("0x0a")
+	client := &fakeArbitrum{block: &RpcBlock{
+		Hash:   common.HexToHash("0x01"),
+		Number: 200,
+		Transactions: []RpcTransaction{
+			{Type: InternalTxType, Hash: common.HexToHash("0x02"), From: common.HexToAddress("0x00000000000000000000000000000000000A4B05")},
+			{Type: DepositTxType, Hash: common.HexToHash("0x03"), From: testSender, To: &testReceiver, Value: (*hexutil.Big)(big.NewInt(5)), RequestId: &requestId},
+			{Type: SubmitRetryableTxType, Hash: testTicketId, From: testSender, To: &ArbRetryableTxAddress, Value: (*hexutil.Big)(new(big.Int)), RequestId: &requestId},
+			{Type: RetryTxType, Hash: testRetryTx, From: testSender, To: &testReceiver, Value: (*hexutil.Big)(big.NewInt(7)), TicketId: &testTicketId},
+			{Type: types.DynamicFeeTxType, Hash: common.HexToHash("0x04"), From: testSender, To: &testReceiver, Value: (*hexutil.Big)(big.NewInt(6))},
+		},
+	}}
+	resp, _ := newTestAdaptor(client, &fakeEth{}).GetBlockByNumber(&account.BlockNumberRequest{Height: 200})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Height != 200 || len(resp.Transactions) != 3 {
+		t.Fatalf("unexpected block %v", resp)
+	}
+	deposit, redeem, transfer := resp.Transactions[0], resp.Transactions[1], resp.Transactions[2]
+	if deposit.L1Origin != global_const.L1OriginDeposit || deposit.L1OriginId != requestId.Hex() || deposit.Amount != "5" {
+		t.Fatalf("unexpected deposit %v", deposit)
+	}
+	if redeem.L1Origin != global_const.L1OriginRetryableRedeem || redeem.L1OriginId != testTicketId.Hex() || redeem.Amount != "7" {
+		t.Fatalf("unexpected redeem %v", redeem)
+	}
+	if transfer.L1Origin != "" || transfer.Memo != "" || transfer.Amount != "6" {
+		t.Fatalf("unexpected transfer %v", transfer)
+	}
+}
+
+func Test_GetTxByHash(t *testing.T) {
+	transferHash := common.HexToHash("0x05")
+	client := &fakeArbitrum{
+		txs: map[common.Hash]*RpcTransaction{
+			transferHash: {Type: types.DynamicFeeTxType, Hash: transferHash, From: testSender, To: &testReceiver, Value: (*hexutil.Big)(big.NewInt(8))},
+		},
+		receipts: map[common.Hash]*RpcReceipt{
+			transferHash: {Status: 1, BlockNumber: (*hexutil.Big)(big.NewInt(200)), GasUsed: 30000, GasUsedForL1: 9000,
+				EffectiveGasPrice: (*hexutil.Big)(big.NewInt(10_000_000)), L1BlockNumber: 19_000_000},
+		},
+	}
+	resp, _ := newTestAdaptor(client, &fakeEth{}).GetTxByHash(&account.TxHashRequest{Hash: transferHash.Hex()})
+	tx := resp.Tx
+	if resp.Code != global_const.ReturnCode_SUCCESS || tx.Fee != "300000000000" || tx.Height != "200" ||
+		tx.Status != account.TxStatus_Success || tx.Value != "8" || tx.Type != types.DynamicFeeTxType {
+		t.Fatalf("unexpected tx %v", tx)
+	}
+
+	resp, _ = newTestAdaptor(client, &fakeEth{}).GetTxByHash(&account.TxHashRequest{Hash: common.HexToHash("0x99").Hex()})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Tx.Status != account.TxStatus_NotFound {
+		t.Fatalf("unexpected response %v", resp)
+	}
+}
+
+func Test_RetryableTicketStatus(t *testing.T) {
+	newClient := func(logs []*types.Log) *fakeArbitrum {
+		return &fakeArbitrum{
+			txs: map[common.Hash]*RpcTransaction{
+				testTicketId: {Type: SubmitRetryableTxType, Hash: testTicketId, From: testSender, To: &ArbRetryableTxAddress},
+			},
+			receipts: map[common.Hash]*RpcReceipt{
+				testTicketId: {Status: 1, BlockNumber: (*hexutil.Big)(big.NewInt(200)), Logs: logs},
+			},
+		}
+	}
+
+	// 自动兑付成功
+	client := newClient([]*types.Log{redeemScheduledLog(testRetryTx)})
+	client.receipts[testRetryTx] = &RpcReceipt{Status: 1}
+	resp, _ := newTestAdaptor(client, &fakeEth{}).GetTxByHash(&account.TxHashRequest{Hash: testTicketId.Hex()})
+	if resp.Tx.Type != SubmitRetryableTxType || resp.Tx.RetryableStatus != string(RetryableRedeemed) ||
+		resp.Tx.RetryTxHash != testRetryTx.Hex() || resp.Tx.Memo != "" {
+		t.Fatalf("unexpected tx %v", resp.Tx)
+	}
+
+	// 自动兑付失败，ticket 仍在等待兑付
+	client = newClient([]*types.Log{redeemScheduledLog(testRetryTx)})
+	client.receipts[testRetryTx] = &RpcReceipt{Status: 0}
+	client.timeout = uint256(1_700_000_000)
+	status, err := newTestAdaptor(client, &fakeEth{}).RetryableTicketStatus(testTicketId)
+	if err != nil || status.State != RetryableFundsDeposited || status.Timeout != 1_700_000_000 {
+		t.Fatalf("unexpected status %+v %v", status, err)
+	}
+
+	// ticket 已删除，通过日志找到手动兑付
+	manualRetryTx := common.HexToHash("0x6801")
+	client.timeout = nil
+	client.receipts[manualRetryTx] = &RpcReceipt{Status: 1}
+	eth := &fakeEth{logs: []types.Log{*redeemScheduledLog(manualRetryTx)}}
+	status, err = newTestAdaptor(client, eth).RetryableTicketStatus(testTicketId)
+	if err != nil || status.State != RetryableRedeemed || status.RetryTxHash != manualRetryTx {
+		t.Fatalf("unexpected status %+v %v", status, err)
+	}
+
+	// ticket 已删除且没有成功的兑付
+	status, err = newTestAdaptor(client, &fakeEth{}).RetryableTicketStatus(testTicketId)
+	if err != nil || status.State != RetryableExpired {
+		t.Fatalf("unexpected status %+v %v", status, err)
+	}
+
+	// 创建失败和不存在的 ticket
+	client.receipts[testTicketId].Status = 0
+	if status, _ = newTestAdaptor(client, &fakeEth{}).RetryableTicketStatus(testTicketId); status.State != RetryableCreationFailed {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status, _ = newTestAdaptor(client, &fakeEth{}).RetryableTicketStatus(common.HexToHash("0x99")); status.State != RetryableNotFound {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
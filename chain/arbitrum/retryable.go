@@ -0,0 +1,156 @@
+package arbitrum
+
+import (
+	"errors"
+	"math/big"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ArbRetryableTx 预编译合约
+	ArbRetryableTxAddress = common.HexToAddress("0x000000000000000000000000000000000000006E")
+	// NodeInterface 虚拟合约，只能通过 eth_call 调用
+	NodeInterfaceAddress = common.HexToAddress("0x00000000000000000000000000000000000000C8")
+)
+
+var (
+	getTimeoutSelector            = methodId("getTimeout(bytes32)")
+	gasEstimateComponentsSelector = methodId("gasEstimateComponents(address,bool,bytes)")
+	// RedeemScheduled(ticketId, retryTxHash, sequenceNum, ...)，前三个参数为 indexed
+	redeemScheduledTopic = crypto.Keccak256Hash([]byte("RedeemScheduled(bytes32,bytes32,uint64,uint64,address,uint256,uint256)"))
+)
+
+// 查询 retryable ticket 状态，ticketId 为创建 ticket 的 0x69 交易哈希。
+// 先检查创建交易中的自动兑付，ticket 仍存在时为等待兑付，否则查找手动兑付的记录，都没有成功时视为过期
+func (c *ChainAdaptor) RetryableTicketStatus(ticketId common.Hash) (*RetryableStatus, error) {
+	receipt, err := c.ArbClient.TxReceiptByHash(ticketId)
+	if errors.Is(err, geth.NotFound) {
+		return &RetryableStatus{State: RetryableNotFound}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if uint64(receipt.Status) != types.ReceiptStatusSuccessful {
+		return &RetryableStatus{State: RetryableCreationFailed}, nil
+	}
+
+	// 自动兑付
+	retryTxHash, err := c.redeemedBy(ticketId, receipt.Logs)
+	if err != nil {
+		return nil, err
+	}
+	if retryTxHash != (common.Hash{}) {
+		return &RetryableStatus{State: RetryableRedeemed, RetryTxHash: retryTxHash}, nil
+	}
+
+	// ticket 兑付成功或过期后会被删除，getTimeout 回滚
+	result, err := c.ArbClient.CallContract(geth.CallMsg{
+		To:   &ArbRetryableTxAddress,
+		Data: append(append([]byte{}, getTimeoutSelector...), ticketId.Bytes()...),
+	})
+	if err == nil {
+		timeout, err := decodeUint256(result)
+		if err != nil {
+			return nil, err
+		}
+		return &RetryableStatus{State: RetryableFundsDeposited, Timeout: timeout.Uint64()}, nil
+	}
+	if !isReverted(err) {
+		return nil, err
+	}
+
+	// 手动兑付
+	logs, err := c.EthClient.FilterLogs(geth.FilterQuery{
+		FromBlock: receipt.BlockNumber.ToInt(),
+		Addresses: []common.Address{ArbRetryableTxAddress},
+		Topics:    [][]common.Hash{{redeemScheduledTopic}, {ticketId}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var redeemLogs []*types.Log
+	for i := range logs.Logs {
+		redeemLogs = append(redeemLogs, &logs.Logs[i])
+	}
+	retryTxHash, err = c.redeemedBy(ticketId, redeemLogs)
+	if err != nil {
+		return nil, err
+	}
+	if retryTxHash != (common.Hash{}) {
+		return &RetryableStatus{State: RetryableRedeemed, RetryTxHash: retryTxHash}, nil
+	}
+	return &RetryableStatus{State: RetryableExpired}, nil
+}
+
+// 从 RedeemScheduled 日志中找到执行成功的兑付交易，没有时返回空哈希
+func (c *ChainAdaptor) redeemedBy(ticketId common.Hash, logs []*types.Log) (common.Hash, error) {
+	for _, item := range logs {
+		if item.Address != ArbRetryableTxAddress || len(item.Topics) < 3 ||
+			item.Topics[0] != redeemScheduledTopic || item.Topics[1] != ticketId {
+			continue
+		}
+		retryTxHash := item.Topics[2]
+		retryReceipt, err := c.ArbClient.TxReceiptByHash(retryTxHash)
+		if errors.Is(err, geth.NotFound) {
+			continue
+		}
+		if err != nil {
+			return common.Hash{}, err
+		}
+		if uint64(retryReceipt.Status) == types.ReceiptStatusSuccessful {
+			return retryTxHash, nil
+		}
+	}
+	return common.Hash{}, nil
+}
+
+// 按 ABI 编码 gasEstimateComponents(address,bool,bytes) 的调用数据
+func encodeGasEstimateComponents(to common.Address, contractCreation bool, data []byte) []byte {
+	input := make([]byte, 0, 4+128+(len(data)+31)/32*32)
+	input = append(input, gasEstimateComponentsSelector...)
+	input = append(input, common.LeftPadBytes(to.Bytes(), 32)...)
+	creation := big.NewInt(0)
+	if contractCreation {
+		creation.SetInt64(1)
+	}
+	input = append(input, common.LeftPadBytes(creation.Bytes(), 32)...)
+	input = append(input, common.LeftPadBytes(big.NewInt(96).Bytes(), 32)...)
+	input = append(input, common.LeftPadBytes(big.NewInt(int64(len(data))).Bytes(), 32)...)
+	input = append(input, common.RightPadBytes(data, (len(data)+31)/32*32)...)
+	return input
+}
+
+// 解码 gasEstimateComponents 的返回值 (uint64, uint64, uint256, uint256)
+func decodeGasEstimateComponents(result []byte) (*GasEstimateComponents, error) {
+	if len(result) < 128 {
+		return nil, errors.New("invalid gas estimate components result")
+	}
+	return &GasEstimateComponents{
+		GasEstimate:       new(big.Int).SetBytes(result[:32]).Uint64(),
+		GasEstimateForL1:  new(big.Int).SetBytes(result[32:64]).Uint64(),
+		BaseFee:           new(big.Int).SetBytes(result[64:96]),
+		L1BaseFeeEstimate: new(big.Int).SetBytes(result[96:128]),
+	}, nil
+}
+
+func methodId(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+func decodeUint256(result []byte) (*big.Int, error) {
+	if len(result) < 32 {
+		return nil, errors.New("invalid uint256 result")
+	}
+	return new(big.Int).SetBytes(result[:32]), nil
+}
+
+func bigOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
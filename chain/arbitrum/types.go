@@ -0,0 +1,88 @@
+package arbitrum
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Arbitrum 特有的交易类型，均由 L1 消息或 ArbOS 产生，没有签名
+const (
+	DepositTxType         = 0x64 // L1 充值 ETH
+	UnsignedTxType        = 0x65 // L1 合约发起的调用
+	ContractTxType        = 0x66
+	RetryTxType           = 0x68 // retryable ticket 的兑付执行
+	SubmitRetryableTxType = 0x69 // 创建 retryable ticket，交易哈希即 ticket id
+	InternalTxType        = 0x6A // ArbOS 内部交易，每个区块第一笔
+)
+
+// 节点返回的交易，Arbitrum 交易类型额外带有 requestId、ticketId 等字段
+type RpcTransaction struct {
+	Type        hexutil.Uint64  `json:"type"`
+	Hash        common.Hash     `json:"hash"`
+	From        common.Address  `json:"from"`
+	To          *common.Address `json:"to"`
+	Value       *hexutil.Big    `json:"value"`
+	Input       hexutil.Bytes   `json:"input"`
+	Nonce       hexutil.Uint64  `json:"nonce"`
+	Gas         hexutil.Uint64  `json:"gas"`
+	BlockNumber *hexutil.Big    `json:"blockNumber"`
+	// L1 消息编号（0x64、0x65、0x66、0x69）
+	RequestId *common.Hash `json:"requestId"`
+	// 兑付的 ticket id（0x68）
+	TicketId *common.Hash `json:"ticketId"`
+	// ticket 参数（0x69）
+	RetryTo      *common.Address `json:"retryTo"`
+	RetryValue   *hexutil.Big    `json:"retryValue"`
+	DepositValue *hexutil.Big    `json:"depositValue"`
+}
+
+// 包含完整交易的区块
+type RpcBlock struct {
+	Hash         common.Hash      `json:"hash"`
+	Number       hexutil.Uint64   `json:"number"`
+	BaseFee      string           `json:"baseFeePerGas"`
+	Transactions []RpcTransaction `json:"transactions"`
+}
+
+// 交易收据，gasUsed 已包含 L1 数据所消耗的 gasUsedForL1，l1BlockNumber 为交易所在的 L1 区块高度
+type RpcReceipt struct {
+	Status            hexutil.Uint64  `json:"status"`
+	BlockNumber       *hexutil.Big    `json:"blockNumber"`
+	TransactionIndex  hexutil.Uint64  `json:"transactionIndex"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	GasUsedForL1      hexutil.Uint64  `json:"gasUsedForL1"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	L1BlockNumber     hexutil.Uint64  `json:"l1BlockNumber"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+	Logs              []*types.Log    `json:"logs"`
+}
+
+// NodeInterface.gasEstimateComponents 的返回值，gasEstimate 已包含 gasEstimateForL1
+type GasEstimateComponents struct {
+	GasEstimate       uint64
+	GasEstimateForL1  uint64
+	BaseFee           *big.Int
+	L1BaseFeeEstimate *big.Int
+}
+
+// retryable ticket 状态
+type RetryableState string
+
+const (
+	RetryableNotFound       RetryableState = "not_found"
+	RetryableCreationFailed RetryableState = "creation_failed"
+	RetryableFundsDeposited RetryableState = "funds_deposited" // 已创建，等待兑付
+	RetryableRedeemed       RetryableState = "redeemed"
+	RetryableExpired        RetryableState = "expired"
+)
+
+type RetryableStatus struct {
+	State RetryableState
+	// 兑付成功的交易
+	RetryTxHash common.Hash
+	// 未兑付时 ticket 的过期时间（unix 秒）
+	Timeout uint64
+}
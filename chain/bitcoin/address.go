@@ -0,0 +1,161 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// ConvertAddressRequest.Type 支持的地址类型
+const (
+	AddressTypeP2PKH      = "p2pkh"
+	AddressTypeP2SHP2WPKH = "p2sh-p2wpkh"
+	AddressTypeP2WPKH     = "p2wpkh"
+	AddressTypeP2TR       = "p2tr"
+)
+
+// 由公钥生成指定类型的地址，公钥支持压缩和非压缩格式（非压缩公钥仅支持 p2pkh）
+func PubKeyToAddress(pubKeyHex string, addressType string, params *chaincfg.Params) (btcutil.Address, error) {
+	pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(pubKeyHex, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := btcec.ParsePubKey(pubKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch addressType {
+	case AddressTypeP2PKH:
+		// 保持调用方传入的公钥格式，兼容旧的非压缩地址
+		return btcutil.NewAddressPubKeyHash(btcutil.Hash160(pubKeyBytes), params)
+	case AddressTypeP2SHP2WPKH:
+		witnessProg := btcutil.Hash160(pubKey.SerializeCompressed())
+		redeemScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(witnessProg).Script()
+		if err != nil {
+			return nil, err
+		}
+		return btcutil.NewAddressScriptHash(redeemScript, params)
+	case AddressTypeP2WPKH:
+		return btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pubKey.SerializeCompressed()), params)
+	case AddressTypeP2TR:
+		// BIP86：无脚本路径的 taproot 输出
+		tapKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+		return btcutil.NewAddressTaproot(schnorr.SerializePubKey(tapKey), params)
+	default:
+		return nil, fmt.Errorf("unsupported address type: %s", addressType)
+	}
+}
+
+// 由公钥生成当前链支持的地址，type 为空时使用链的默认类型
+func (c *ChainAdaptor) pubKeyToAddress(pubKeyHex string, addressType string) (string, error) {
+	addressType = strings.ToLower(addressType)
+	switch addressType {
+	case "":
+		addressType = c.Chain.AddressTypes[0]
+	case "p2sh":
+		addressType = AddressTypeP2SHP2WPKH
+	}
+	if !c.Chain.supportAddressType(addressType) {
+		return "", fmt.Errorf("%s does not support address type %s", c.Chain.Name, addressType)
+	}
+	addr, err := PubKeyToAddress(pubKeyHex, addressType, c.Params)
+	if err != nil {
+		return "", err
+	}
+	return c.encodeAddress(addr)
+}
+
+// 地址编码，BCH 输出 CashAddr 格式
+func (c *ChainAdaptor) encodeAddress(addr btcutil.Address) (string, error) {
+	if c.CashAddrPrefix == "" {
+		return addr.EncodeAddress(), nil
+	}
+	switch addr := addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return encodeCashAddr(c.CashAddrPrefix, cashAddrTypeP2PKH, addr.ScriptAddress())
+	case *btcutil.AddressScriptHash:
+		return encodeCashAddr(c.CashAddrPrefix, cashAddrTypeP2SH, addr.ScriptAddress())
+	default:
+		return "", fmt.Errorf("%s does not support address %s", c.Chain.Name, addr.EncodeAddress())
+	}
+}
+
+// 解析地址并校验所属网络及链是否支持该地址类型
+func (c *ChainAdaptor) decodeAddress(address string) (btcutil.Address, error) {
+	if c.CashAddrPrefix != "" {
+		if addrType, hash, err := decodeCashAddr(address, c.CashAddrPrefix); err == nil {
+			if addrType == cashAddrTypeP2SH {
+				return btcutil.NewAddressScriptHashFromHash(hash, c.Params)
+			}
+			return btcutil.NewAddressPubKeyHash(hash, c.Params)
+		}
+	}
+	addr, err := c.decodeSegWitAddress(address)
+	if addr == nil && err == nil {
+		addr, err = btcutil.DecodeAddress(address, c.Params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !addr.IsForNet(c.Params) {
+		return nil, fmt.Errorf("address %s is not for %s", address, c.Params.Name)
+	}
+	switch addr.(type) {
+	case *btcutil.AddressPubKeyHash, *btcutil.AddressScriptHash:
+	case *btcutil.AddressWitnessPubKeyHash, *btcutil.AddressWitnessScriptHash:
+		if !c.Chain.supportAddressType(AddressTypeP2WPKH) {
+			return nil, fmt.Errorf("%s does not support segwit address", c.Chain.Name)
+		}
+	case *btcutil.AddressTaproot:
+		if !c.Chain.supportAddressType(AddressTypeP2TR) {
+			return nil, fmt.Errorf("%s does not support taproot address", c.Chain.Name)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported address: %s", address)
+	}
+	return addr, nil
+}
+
+// btcutil 只识别 chaincfg 中已注册的 bech32 前缀，其他链的隔离见证地址在这里解析；
+// 不是当前链前缀的地址返回 nil 交给 btcutil 处理
+func (c *ChainAdaptor) decodeSegWitAddress(address string) (btcutil.Address, error) {
+	hrp := c.Params.Bech32HRPSegwit
+	if hrp == "" || !strings.HasPrefix(strings.ToLower(address), hrp+"1") {
+		return nil, nil
+	}
+	decodedHrp, data, version, err := bech32.DecodeGeneric(address)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || decodedHrp != hrp {
+		return nil, fmt.Errorf("invalid segwit address: %s", address)
+	}
+	program, err := bech32.ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case data[0] == 0 && version == bech32.Version0 && len(program) == 20:
+		return btcutil.NewAddressWitnessPubKeyHash(program, c.Params)
+	case data[0] == 0 && version == bech32.Version0 && len(program) == 32:
+		return btcutil.NewAddressWitnessScriptHash(program, c.Params)
+	case data[0] == 1 && version == bech32.VersionM && len(program) == 32:
+		return btcutil.NewAddressTaproot(program, c.Params)
+	default:
+		return nil, fmt.Errorf("unsupported segwit address: %s", address)
+	}
+}
+
+// 校验地址格式及所属网络
+func (c *ChainAdaptor) validateAddress(address string) bool {
+	_, err := c.decodeAddress(address)
+	return err == nil
+}
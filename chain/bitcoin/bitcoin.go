@@ -0,0 +1,488 @@
+package bitcoin
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Bitcoin"
+
+// 手续费估算的目标确认区块数：慢、普通、快
+var feeConfTargets = [3]int64{12, 6, 2}
+
+type ChainAdaptor struct {
+	BtcClient IBtc
+	Chain     *ChainParams
+	Params    *chaincfg.Params
+	// 非空时地址使用 CashAddr 格式
+	CashAddrPrefix string
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(Bitcoin, con.WalletNode.Btc, con.NetWork)
+}
+
+func NewLitecoinAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(Litecoin, con.WalletNode.Ltc, con.NetWork)
+}
+
+func NewDogecoinAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(Dogecoin, con.WalletNode.Doge, con.NetWork)
+}
+
+func NewBitcoinCashAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(BitcoinCash, con.WalletNode.Bch, con.NetWork)
+}
+
+// 按链参数创建 adaptor，节点未配置 network 时使用全局 network
+func newChainAdaptor(params *ChainParams, node config.Node, network string) (*ChainAdaptor, error) {
+	if node.Network != "" {
+		network = node.Network
+	}
+	net, err := params.Network(network)
+	if err != nil {
+		return nil, err
+	}
+	btcClient, err := NewBtcClient(node.RpcUrl, node.RpcUser, node.RpcPass, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		BtcClient:      btcClient,
+		Chain:          params,
+		Params:         net.Params,
+		CashAddrPrefix: net.CashAddrPrefix,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入公钥 转换成地址，type 可选 p2pkh、p2sh-p2wpkh、p2wpkh、p2tr（以链支持的类型为准）
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeSecp256k1); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	address, err := c.pubKeyToAddress(req.PublicKey, req.Type)
+	if err != nil {
+		log.Error("convert address fail", "type", req.Type, "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address,
+	}, nil
+}
+
+// 地址格式验证
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !c.validateAddress(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 通过区块号获取区块数据
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	hash, err := c.BtcClient.GetBlockHash(req.Height)
+	if err != nil {
+		log.Error("get block hash fail", "height", req.Height, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return c.getBlock(hash, "get block by number")
+}
+
+// 通过区块Hash获取区块数据
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return c.getBlock(req.Hash, "get block by hash")
+}
+
+func (c *ChainAdaptor) getBlock(hash string, action string) (*account.BlockResponse, error) {
+	block, err := c.BtcClient.GetBlock(hash)
+	if err != nil {
+		log.Error(action+" fail", "hash", hash, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  action + " fail",
+		}, nil
+	}
+	// UTXO 模型每个输出单独列出，from 需要查询前序交易，这里不展开
+	var blockTxList []*account.BlockInfoTransactionList
+	for _, tx := range block.Tx {
+		for _, vout := range tx.Vout {
+			amount, err := btcutil.NewAmount(vout.Value)
+			if err != nil {
+				continue
+			}
+			blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+				To:     voutAddress(&vout),
+				Hash:   tx.Txid,
+				Amount: strconv.FormatInt(int64(amount), 10),
+				Height: uint64(block.Height),
+			})
+		}
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          action + " success",
+		Height:       block.Height,
+		Hash:         block.Hash,
+		Transactions: blockTxList,
+	}, nil
+}
+
+// 通过区块号获取区块头信息，height 为 0 时返回最新区块
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	height := req.Height
+	if height == 0 {
+		latest, err := c.BtcClient.GetBlockCount()
+		if err != nil {
+			log.Error("get block count fail", "err", err)
+			return &account.BlockHeaderResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block header by number fail",
+			}, nil
+		}
+		height = latest
+	}
+	hash, err := c.BtcClient.GetBlockHash(height)
+	if err != nil {
+		log.Error("get block hash fail", "height", height, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	header, err := c.BtcClient.GetBlockHeader(hash)
+	if err != nil {
+		log.Error("get block header fail", "hash", hash, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(header),
+	}, nil
+}
+
+// 通过区块Hash获取区块头信息
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	header, err := c.BtcClient.GetBlockHeader(req.Hash)
+	if err != nil {
+		log.Error("get block header by hash fail", "hash", req.Hash, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by hash success",
+		BlockHeader: toBlockHeader(header),
+	}, nil
+}
+
+// 获取区间内的区块头
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseInt(req.Start, 10, 64)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseInt(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for height := start; height <= end; height++ {
+		hash, err := c.BtcClient.GetBlockHash(height)
+		if err != nil {
+			log.Error("get block hash fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		header, err := c.BtcClient.GetBlockHeader(hash)
+		if err != nil {
+			log.Error("get block header fail", "hash", hash, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		headers = append(headers, toBlockHeader(header))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取账户余额，通过扫描 UTXO 集合（Dogecoin 为节点钱包）统计，单位 satoshi
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if !c.validateAddress(req.Address) {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	utxos, err := c.listUnspent(req.Address, 0)
+	if err != nil {
+		log.Error("list unspent fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  unspentErrorMsg(err, "get account fail"),
+		}, nil
+	}
+	var balance int64
+	for _, utxo := range utxos {
+		balance += utxo.Amount
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Balance:       strconv.FormatInt(balance, 10),
+	}, nil
+}
+
+// 获取fee，单位 sat/vB
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	var fees [3]string
+	for i, target := range feeConfTargets {
+		feeRate, err := c.estimateFeeRate(target)
+		if err != nil {
+			log.Error("estimate smart fee fail", "target", target, "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "estimate fee fail",
+			}, nil
+		}
+		fees[i] = strconv.FormatInt(feeRate, 10)
+	}
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fees[0],
+		NormalFee: fees[1],
+		FastFee:   fees[2],
+	}, nil
+}
+
+// 估算费率并转换为 sat/vB，向上取整；链配置了固定费率时直接返回
+func (c *ChainAdaptor) estimateFeeRate(confTarget int64) (int64, error) {
+	if c.Chain.FixedFeeRate > 0 {
+		return c.Chain.FixedFeeRate, nil
+	}
+	result, err := c.BtcClient.EstimateSmartFee(confTarget)
+	if err != nil {
+		return 0, err
+	}
+	if result.FeeRate == nil {
+		return 0, fmt.Errorf("estimate smart fee unavailable: %v", result.Errors)
+	}
+	satPerKvB, err := btcutil.NewAmount(*result.FeeRate)
+	if err != nil {
+		return 0, err
+	}
+	return (int64(satPerKvB) + 999) / 1000, nil
+}
+
+// 广播交易
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	txid, err := c.BtcClient.SendRawTransaction(req.RawTx)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: txid,
+	}, nil
+}
+
+// 按地址查询交易需要地址索引，bitcoind 不直接支持
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get tx by address is not supported by bitcoind",
+	}, nil
+}
+
+// 通过交易哈希获取交易，需要节点开启 txindex
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	tx, err := c.BtcClient.GetRawTransaction(req.Hash)
+	if err != nil {
+		if IsNotFound(err) {
+			return &account.TxHashResponse{
+				Code: global_const.ReturnCode_SUCCESS,
+				Msg:  "transaction not found",
+				Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+			}, nil
+		}
+		log.Error("get raw transaction fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+
+	// 输入金额和地址来自前序交易的输出
+	var from string
+	var inputTotal, outputTotal int64
+	for _, vin := range tx.Vin {
+		if vin.IsCoinBase() {
+			continue
+		}
+		prev, err := c.BtcClient.GetRawTransaction(vin.Txid)
+		if err != nil || int(vin.Vout) >= len(prev.Vout) {
+			log.Warn("get previous output fail", "txid", vin.Txid, "vout", vin.Vout, "err", err)
+			inputTotal = -1
+			break
+		}
+		prevOut := prev.Vout[vin.Vout]
+		if from == "" {
+			from = voutAddress(&prevOut)
+		}
+		amount, _ := btcutil.NewAmount(prevOut.Value)
+		inputTotal += int64(amount)
+	}
+	var to string
+	for _, vout := range tx.Vout {
+		if to == "" {
+			to = voutAddress(&vout)
+		}
+		amount, _ := btcutil.NewAmount(vout.Value)
+		outputTotal += int64(amount)
+	}
+	fee := ""
+	if inputTotal > 0 {
+		fee = strconv.FormatInt(inputTotal-outputTotal, 10)
+	}
+
+	status := account.TxStatus_Pending
+	height := ""
+	if tx.Confirmations > 0 {
+		status = account.TxStatus_Success
+		if header, err := c.BtcClient.GetBlockHeader(tx.BlockHash); err == nil {
+			height = strconv.FormatInt(int64(header.Height), 10)
+		}
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx: &account.TxMessage{
+			Hash:     tx.Txid,
+			From:     from,
+			To:       to,
+			Value:    strconv.FormatInt(outputTotal, 10),
+			Fee:      fee,
+			Status:   status,
+			Height:   height,
+			Datetime: strconv.FormatInt(tx.Blocktime, 10),
+		},
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// 输出的接收地址，OP_RETURN 等无地址输出返回空
+func voutAddress(vout *btcjson.Vout) string {
+	if vout.ScriptPubKey.Address != "" {
+		return vout.ScriptPubKey.Address
+	}
+	if len(vout.ScriptPubKey.Addresses) > 0 {
+		return vout.ScriptPubKey.Addresses[0]
+	}
+	return ""
+}
+
+func toBlockHeader(header *btcjson.GetBlockHeaderVerboseResult) *account.BlockHeader {
+	return &account.BlockHeader{
+		Hash:       header.Hash,
+		ParentHash: header.PreviousHash,
+		Root:       header.MerkleRoot,
+		Difficulty: big.NewFloat(header.Difficulty).Text('f', -1),
+		Number:     strconv.FormatInt(int64(header.Height), 10),
+		Time:       uint64(header.Time),
+		Nonce:      strconv.FormatUint(header.Nonce, 10),
+		Extra:      header.Bits,
+	}
+}
@@ -0,0 +1,149 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// secp256k1 生成元 G 的压缩公钥
+const testPubKey = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+
+// 模拟 bitcoind JSON-RPC，按方法名返回固定结果
+func newRpcStandIn(t *testing.T, results map[string]string) *ChainAdaptor {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if user, pass, _ := r.BasicAuth(); user != "user" || pass != "pass" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("bad request %s", body)
+			return
+		}
+		result, ok := results[req.Method]
+		if !ok {
+			rw.WriteHeader(http.StatusInternalServerError)
+			_, _ = rw.Write([]byte(`{"result":null,"error":{"code":-5,"message":"not found"},"id":1}`))
+			return
+		}
+		_, _ = rw.Write([]byte(`{"result":` + result + `,"error":null,"id":1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewBtcClient(server.URL, "user", "pass", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ChainAdaptor{BtcClient: client, Chain: Bitcoin, Params: &chaincfg.MainNetParams}
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	adaptor := &ChainAdaptor{Chain: Bitcoin, Params: &chaincfg.MainNetParams}
+	cases := map[string]string{
+		AddressTypeP2PKH:      "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH",
+		AddressTypeP2SHP2WPKH: "3JvL6Ymt8MVWiCNHC7oWU6nLeHNJKLZGLN",
+		AddressTypeP2WPKH:     "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4",
+	}
+	for addressType, expected := range cases {
+		resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{Type: addressType, PublicKey: testPubKey})
+		if resp.Address != expected {
+			t.Fatalf("%s: expected %s, got %s", addressType, expected, resp.Address)
+		}
+		if valid, _ := adaptor.ValidAddress(&account.ValidAddressRequest{Address: resp.Address}); !valid.Valid {
+			t.Fatalf("%s: expected valid address", addressType)
+		}
+	}
+
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{Type: AddressTypeP2TR, PublicKey: testPubKey})
+	addr, err := btcutil.DecodeAddress(resp.Address, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := addr.(*btcutil.AddressTaproot); !ok || !strings.HasPrefix(resp.Address, "bc1p") {
+		t.Fatalf("expected taproot address, got %s", resp.Address)
+	}
+
+	// 测试网地址在主网无效
+	if valid, _ := adaptor.ValidAddress(&account.ValidAddressRequest{Address: "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"}); valid.Valid {
+		t.Fatal("expected testnet address to be invalid on mainnet")
+	}
+	if resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{Type: "p2wsh", PublicKey: testPubKey}); resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected unsupported address type to fail")
+	}
+}
+
+func Test_GetFeeAndHeader(t *testing.T) {
+	adaptor := newRpcStandIn(t, map[string]string{
+		"estimatesmartfee": `{"feerate":0.00012345,"blocks":2}`,
+		"getblockcount":    `800000`,
+		"getblockhash":     `"00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054"`,
+		"getblockheader": `{"hash":"00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054","height":800000,
+			"merkleroot":"70e94c1c8d40c1a9d5d4e8c4a6e5f3f9d1c1b1a191817161514131211100f0e0","time":1690168629,"nonce":106861918,
+			"bits":"17053894","difficulty":53911173001054.59,"previousblockhash":"00000000000000000001b2505c11119fcf29be733ec379f686518bf1090a522a"}`,
+	})
+
+	fee, _ := adaptor.GetFee(&account.FeeRequest{})
+	// 0.00012345 BTC/kvB = 12345 sat/kvB，向上取整为 13 sat/vB
+	if fee.Code != global_const.ReturnCode_SUCCESS || fee.FastFee != "13" {
+		t.Fatalf("unexpected fee %+v", fee)
+	}
+
+	header, _ := adaptor.GetBlockHeaderByNumber(&account.BlockHeaderNumberRequest{})
+	if header.Code != global_const.ReturnCode_SUCCESS || header.BlockHeader.Number != "800000" ||
+		header.BlockHeader.ParentHash != "00000000000000000001b2505c11119fcf29be733ec379f686518bf1090a522a" {
+		t.Fatalf("unexpected header %+v", header)
+	}
+
+	// 节点返回 RPC 错误
+	tx, _ := adaptor.GetTxByHash(&account.TxHashRequest{Hash: "00"})
+	if tx.Code != global_const.ReturnCode_SUCCESS || tx.Tx.Status != account.TxStatus_NotFound {
+		t.Fatalf("expected not found tx, got %+v", tx)
+	}
+}
+
+// Dogecoin 通过节点钱包的 listunspent 查询 UTXO
+func Test_DogecoinWalletUnspent(t *testing.T) {
+	results := map[string]string{
+		"validateaddress": `{"isvalid":true,"ismine":false,"iswatchonly":true}`,
+		"getblockcount":   `5000000`,
+		"listunspent": `[{"txid":"4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b","vout":1,"scriptPubKey":"76a914751e76e8199196d454941c45d1b3a323f1433bd688ac",
+			"amount":12.5,"confirmations":10,"spendable":false}]`,
+	}
+	adaptor := newRpcStandIn(t, results)
+	net, _ := Dogecoin.Network("mainnet")
+	adaptor.Chain, adaptor.Params = Dogecoin, net.Params
+	address, err := PubKeyToAddress(testPubKey, AddressTypeP2PKH, adaptor.Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unspent, _ := adaptor.GetUnspentOutputs(&account.UnspentOutputsRequest{Address: address.EncodeAddress()})
+	if unspent.Code != global_const.ReturnCode_SUCCESS || len(unspent.UnspentOutputs) != 1 {
+		t.Fatalf("unexpected unspent outputs %+v", unspent)
+	}
+	if utxo := unspent.UnspentOutputs[0]; utxo.Amount != "1250000000" || utxo.Height != 4999991 || utxo.Confirmations != 10 {
+		t.Fatalf("unexpected utxo %+v", utxo)
+	}
+	balance, _ := adaptor.GetAccount(&account.AccountRequest{Address: address.EncodeAddress()})
+	if balance.Code != global_const.ReturnCode_SUCCESS || balance.Balance != "1250000000" {
+		t.Fatalf("unexpected balance %+v", balance)
+	}
+
+	// 地址未导入钱包时返回明确的错误，而不是余额为 0
+	results["validateaddress"] = `{"isvalid":true,"ismine":false,"iswatchonly":false}`
+	balance, _ = adaptor.GetAccount(&account.AccountRequest{Address: address.EncodeAddress()})
+	if balance.Code != global_const.ReturnCode_ERROR || !strings.Contains(balance.Msg, "importaddress") {
+		t.Fatalf("expected wallet import error, got %+v", balance)
+	}
+}
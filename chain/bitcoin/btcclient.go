@@ -0,0 +1,247 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// bitcoind JSON-RPC 错误
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// 交易或区块不存在（RPC_INVALID_ADDRESS_OR_KEY）
+func IsNotFound(err error) bool {
+	rpcErr, ok := err.(*RpcError)
+	return ok && rpcErr.Code == int(btcjson.ErrRPCInvalidAddressOrKey)
+}
+
+// scantxoutset 返回的未花费输出
+type ScanUnspent struct {
+	TxId         string  `json:"txid"`
+	Vout         uint32  `json:"vout"`
+	ScriptPubKey string  `json:"scriptPubKey"`
+	Desc         string  `json:"desc"`
+	Amount       float64 `json:"amount"`
+	Coinbase     bool    `json:"coinbase"`
+	Height       int64   `json:"height"`
+}
+
+type ScanTxOutSetResult struct {
+	Success     bool          `json:"success"`
+	Height      int64         `json:"height"`
+	BestBlock   string        `json:"bestblock"`
+	Unspents    []ScanUnspent `json:"unspents"`
+	TotalAmount float64       `json:"total_amount"`
+}
+
+// validateaddress 返回的钱包归属信息
+type ValidateAddressResult struct {
+	IsValid     bool   `json:"isvalid"`
+	Address     string `json:"address"`
+	IsMine      bool   `json:"ismine"`
+	IsWatchOnly bool   `json:"iswatchonly"`
+}
+
+type rpcRequest struct {
+	JsonRpc string `json:"jsonrpc"`
+	Id      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RpcError       `json:"error"`
+	Id     uint64          `json:"id"`
+}
+
+// 定义 bitcoind 的接口
+type IBtc interface {
+	// 区块数据相关
+	GetBlockCount() (int64, error)
+	GetBlockHash(height int64) (string, error)
+	GetBlockHeader(hash string) (*btcjson.GetBlockHeaderVerboseResult, error)
+	GetBlock(hash string) (*btcjson.GetBlockVerboseTxResult, error)
+	// 交易
+	GetRawTransaction(txid string) (*btcjson.TxRawResult, error)
+	SendRawTransaction(rawTx string) (string, error)
+	// 手续费估算
+	EstimateSmartFee(confTarget int64) (*btcjson.EstimateSmartFeeResult, error)
+	// UTXO 查询
+	ScanTxOutSet(addresses []string) (*ScanTxOutSetResult, error)
+	ListUnspent(minConf, maxConf int64, addresses []string) ([]btcjson.ListUnspentResult, error)
+	ValidateAddress(address string) (*ValidateAddressResult, error)
+}
+
+// 定义 bitcoind 客户端，使用 rpc_user/rpc_pass 做 Basic 认证
+type BtcClient struct {
+	url    string
+	user   string
+	pass   string
+	client *http.Client
+	nextId atomic.Uint64
+}
+
+func NewBtcClient(rpcUrl, user, pass string, timeout time.Duration) (IBtc, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &BtcClient{
+		url:    rpcUrl,
+		user:   user,
+		pass:   pass,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// 调用 JSON-RPC 方法并解析结果
+func (b *BtcClient) call(result any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(&rpcRequest{
+		JsonRpc: "1.0",
+		Id:      b.nextId.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.user != "" || b.pass != "" {
+		req.SetBasicAuth(b.user, b.pass)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// bitcoind 出错时返回非 200 状态码，但响应体仍是 JSON-RPC 格式
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return fmt.Errorf("call %s fail, status %d: %s", method, resp.StatusCode, string(respBody))
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// 获取最新区块高度
+func (b *BtcClient) GetBlockCount() (int64, error) {
+	var count int64
+	err := b.call(&count, "getblockcount")
+	return count, err
+}
+
+// 通过区块高度获取区块哈希
+func (b *BtcClient) GetBlockHash(height int64) (string, error) {
+	var hash string
+	err := b.call(&hash, "getblockhash", height)
+	return hash, err
+}
+
+// 通过区块哈希获取区块头
+func (b *BtcClient) GetBlockHeader(hash string) (*btcjson.GetBlockHeaderVerboseResult, error) {
+	header := new(btcjson.GetBlockHeaderVerboseResult)
+	if err := b.call(header, "getblockheader", hash, true); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// 通过区块哈希获取区块及交易详情
+func (b *BtcClient) GetBlock(hash string) (*btcjson.GetBlockVerboseTxResult, error) {
+	block := new(btcjson.GetBlockVerboseTxResult)
+	if err := b.call(block, "getblock", hash, 2); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// 获取交易详情，非钱包交易需要节点开启 txindex；verbose 使用数字以兼容 dogecoind
+func (b *BtcClient) GetRawTransaction(txid string) (*btcjson.TxRawResult, error) {
+	tx := new(btcjson.TxRawResult)
+	if err := b.call(tx, "getrawtransaction", txid, 1); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// 广播签名交易，返回交易哈希
+func (b *BtcClient) SendRawTransaction(rawTx string) (string, error) {
+	var txid string
+	err := b.call(&txid, "sendrawtransaction", rawTx)
+	return txid, err
+}
+
+// 估算在 confTarget 个区块内确认所需的费率（BTC/kvB）
+func (b *BtcClient) EstimateSmartFee(confTarget int64) (*btcjson.EstimateSmartFeeResult, error) {
+	result := new(btcjson.EstimateSmartFeeResult)
+	if err := b.call(result, "estimatesmartfee", confTarget); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 扫描 UTXO 集合查询地址的未花费输出，不依赖节点钱包
+func (b *BtcClient) ScanTxOutSet(addresses []string) (*ScanTxOutSetResult, error) {
+	descriptors := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		descriptors = append(descriptors, "addr("+address+")")
+	}
+	result := new(ScanTxOutSetResult)
+	if err := b.call(result, "scantxoutset", "start", descriptors); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 通过节点钱包查询地址的未花费输出，地址需已导入钱包
+func (b *BtcClient) ListUnspent(minConf, maxConf int64, addresses []string) ([]btcjson.ListUnspentResult, error) {
+	var result []btcjson.ListUnspentResult
+	if err := b.call(&result, "listunspent", minConf, maxConf, addresses); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 校验地址并返回其是否属于节点钱包
+func (b *BtcClient) ValidateAddress(address string) (*ValidateAddressResult, error) {
+	result := new(ValidateAddressResult)
+	if err := b.call(result, "validateaddress", address); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,125 @@
+package bitcoin
+
+import (
+	"errors"
+	"strings"
+)
+
+// CashAddr 编码（BCH），格式为 prefix:payload，payload 为版本字节加哈希的 base32 编码和 40 位校验和
+const cashAddrCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// 版本字节中的地址类型，哈希长度固定 160 位
+const (
+	cashAddrTypeP2PKH byte = 0
+	cashAddrTypeP2SH  byte = 8
+)
+
+func cashAddrPolymod(values []byte) uint64 {
+	generators := [5]uint64{0x98f2bc8e61, 0x79b76d99e2, 0xf33e5fb3c4, 0xae2eabe2a8, 0x1e4f43e470}
+	c := uint64(1)
+	for _, d := range values {
+		c0 := byte(c >> 35)
+		c = ((c & 0x07ffffffff) << 5) ^ uint64(d)
+		for i, generator := range generators {
+			if c0&(1<<uint(i)) != 0 {
+				c ^= generator
+			}
+		}
+	}
+	return c ^ 1
+}
+
+func cashAddrPrefixData(prefix string) []byte {
+	data := make([]byte, 0, len(prefix)+1)
+	for i := 0; i < len(prefix); i++ {
+		data = append(data, prefix[i]&0x1f)
+	}
+	return append(data, 0)
+}
+
+// 按位重新分组，pad 为 true 时不足部分补 0
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc, bits uint
+	maxV := uint(1)<<toBits - 1
+	var out []byte
+	for _, value := range data {
+		if uint(value)>>fromBits != 0 {
+			return nil, errors.New("invalid data range")
+		}
+		acc = acc<<fromBits | uint(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxV))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxV))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxV != 0 {
+		return nil, errors.New("invalid padding")
+	}
+	return out, nil
+}
+
+func encodeCashAddr(prefix string, addrType byte, hash []byte) (string, error) {
+	if len(hash) != 20 {
+		return "", errors.New("cashaddr only supports 160 bit hash")
+	}
+	payload, err := convertBits(append([]byte{addrType}, hash...), 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	checksum := cashAddrPolymod(append(append(cashAddrPrefixData(prefix), payload...), make([]byte, 8)...))
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	sb.WriteByte(':')
+	for _, d := range payload {
+		sb.WriteByte(cashAddrCharset[d])
+	}
+	for i := 0; i < 8; i++ {
+		sb.WriteByte(cashAddrCharset[(checksum>>uint(5*(7-i)))&0x1f])
+	}
+	return sb.String(), nil
+}
+
+// 解析 CashAddr，地址可以省略前缀，但不允许大小写混用
+func decodeCashAddr(address string, prefix string) (byte, []byte, error) {
+	if strings.ToLower(address) != address && strings.ToUpper(address) != address {
+		return 0, nil, errors.New("mixed case cashaddr")
+	}
+	address = strings.ToLower(address)
+	if i := strings.LastIndexByte(address, ':'); i >= 0 {
+		if address[:i] != prefix {
+			return 0, nil, errors.New("cashaddr prefix mismatch")
+		}
+		address = address[i+1:]
+	}
+	if len(address) <= 8 {
+		return 0, nil, errors.New("cashaddr too short")
+	}
+	data := make([]byte, 0, len(address))
+	for i := 0; i < len(address); i++ {
+		d := strings.IndexByte(cashAddrCharset, address[i])
+		if d < 0 {
+			return 0, nil, errors.New("invalid cashaddr character")
+		}
+		data = append(data, byte(d))
+	}
+	if cashAddrPolymod(append(cashAddrPrefixData(prefix), data...)) != 0 {
+		return 0, nil, errors.New("invalid cashaddr checksum")
+	}
+	decoded, err := convertBits(data[:len(data)-8], 5, 8, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(decoded) != 21 || decoded[0]&0x07 != 0 {
+		return 0, nil, errors.New("unsupported cashaddr hash size")
+	}
+	addrType := decoded[0] & 0x78
+	if addrType != cashAddrTypeP2PKH && addrType != cashAddrTypeP2SH {
+		return 0, nil, errors.New("unsupported cashaddr type")
+	}
+	return addrType, decoded[1:], nil
+}
@@ -0,0 +1,337 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// 选币策略
+type SelectMode string
+
+const (
+	SelectBranchAndBound SelectMode = "bnb"           // 寻找无需找零的精确组合，找不到时退化为 largest-first
+	SelectLargestFirst   SelectMode = "largest-first" // 按金额从大到小选择
+	SelectConsolidate    SelectMode = "consolidate"   // 使用全部 UTXO，用于归集
+)
+
+const (
+	// 交易固定部分：version + locktime + 输入输出数量
+	txOverheadWeight = (4 + 4 + 1 + 1) * 4
+	// 隔离见证 marker 和 flag
+	witnessMarkerWeight = 2
+	// branch-and-bound 最大搜索次数
+	bnbMaxTries = 100000
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// 可用的未花费输出
+type Utxo struct {
+	TxId          string
+	Vout          uint32
+	Amount        int64 // 单位 satoshi
+	PkScript      []byte
+	Address       string
+	Height        uint64
+	Confirmations uint64
+}
+
+// 交易输出
+type TxOutput struct {
+	PkScript []byte
+	Amount   int64
+}
+
+// 选币结果，Change 为 0 表示没有找零输出
+type CoinSelection struct {
+	Inputs  []Utxo
+	Outputs []TxOutput
+	Fee     int64
+	Change  int64
+	VSize   int64
+}
+
+// 花费该类型输出的输入权重（按单签名估算）
+func inputWeight(pkScript []byte) (int64, error) {
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.PubKeyHashTy:
+		// outpoint 36 + scriptSig 长度 1 + scriptSig 107 + sequence 4
+		return (36 + 1 + 107 + 4) * 4, nil
+	case txscript.ScriptHashTy:
+		// 按 p2sh-p2wpkh 估算：scriptSig 为 23 字节赎回脚本，见证数据 108 字节
+		return (36+1+23+4)*4 + 108, nil
+	case txscript.WitnessV0PubKeyHashTy:
+		return (36+1+4)*4 + 108, nil
+	case txscript.WitnessV1TaprootTy:
+		// key path 花费：见证数据为 64 字节 schnorr 签名
+		return (36+1+4)*4 + 66, nil
+	default:
+		return 0, fmt.Errorf("unsupported script type: %s", txscript.GetScriptClass(pkScript))
+	}
+}
+
+func outputWeight(pkScript []byte) int64 {
+	return int64(8+1+len(pkScript)) * 4
+}
+
+func isWitnessScript(pkScript []byte) bool {
+	return txscript.IsWitnessProgram(pkScript) || txscript.GetScriptClass(pkScript) == txscript.ScriptHashTy
+}
+
+// 粉尘规则：输出金额低于按 RelayFeeRate 计算的花费成本，或低于固定的 MinAmount
+type DustPolicy struct {
+	RelayFeeRate int64
+	MinAmount    int64
+}
+
+// 与 bitcoind 的 dustrelayfee 默认值一致
+var bitcoinDust = DustPolicy{RelayFeeRate: 3}
+
+func (p DustPolicy) Threshold(pkScript []byte) int64 {
+	size := int64(8 + 1 + len(pkScript))
+	if txscript.IsWitnessProgram(pkScript) {
+		size += 32 + 4 + 1 + 107/4 + 4
+	} else {
+		size += 32 + 4 + 1 + 107 + 4
+	}
+	return max(size*p.RelayFeeRate, p.MinAmount)
+}
+
+// 比特币的粉尘阈值
+func DustThreshold(pkScript []byte) int64 {
+	return bitcoinDust.Threshold(pkScript)
+}
+
+func feeForWeight(weight, feeRate int64) int64 {
+	return (weight + 3) / 4 * feeRate
+}
+
+type coinSelector struct {
+	utxos        []Utxo
+	outputs      []TxOutput
+	changeScript []byte
+	feeRate      int64
+	dust         DustPolicy
+
+	outputTotal int64
+	baseWeight  int64
+	inputFees   []int64
+	sweep       bool
+}
+
+// 按策略选择输入，feeRate 单位 sat/vB。
+// consolidate 模式下如果只有一个金额为 0 的输出，则把全部余额扣除手续费后转入该输出
+func SelectCoins(utxos []Utxo, outputs []TxOutput, changeScript []byte, feeRate int64, mode SelectMode) (*CoinSelection, error) {
+	return SelectCoinsWithDust(utxos, outputs, changeScript, feeRate, mode, bitcoinDust)
+}
+
+// 按指定的粉尘规则选币，用于比特币以外的链
+func SelectCoinsWithDust(utxos []Utxo, outputs []TxOutput, changeScript []byte, feeRate int64, mode SelectMode, dust DustPolicy) (*CoinSelection, error) {
+	if len(outputs) == 0 {
+		return nil, errors.New("no outputs")
+	}
+	if feeRate <= 0 {
+		return nil, errors.New("invalid fee rate")
+	}
+	s := &coinSelector{
+		outputs:      outputs,
+		changeScript: changeScript,
+		feeRate:      feeRate,
+		dust:         dust,
+		baseWeight:   txOverheadWeight + witnessMarkerWeight,
+		sweep:        mode == SelectConsolidate && len(outputs) == 1 && outputs[0].Amount == 0,
+	}
+	for _, output := range outputs {
+		if s.sweep {
+			s.baseWeight += outputWeight(output.PkScript)
+			break
+		}
+		if output.Amount < s.dust.Threshold(output.PkScript) {
+			return nil, fmt.Errorf("output amount %d is below dust threshold", output.Amount)
+		}
+		s.outputTotal += output.Amount
+		s.baseWeight += outputWeight(output.PkScript)
+	}
+
+	// 只保留扣除自身手续费后仍有剩余价值的 UTXO
+	for _, utxo := range utxos {
+		weight, err := inputWeight(utxo.PkScript)
+		if err != nil {
+			continue
+		}
+		fee := feeForWeight(weight, feeRate)
+		if utxo.Amount <= fee {
+			continue
+		}
+		s.utxos = append(s.utxos, utxo)
+		s.inputFees = append(s.inputFees, fee)
+	}
+
+	switch mode {
+	case SelectConsolidate:
+		return s.finish(s.utxos)
+	case SelectLargestFirst:
+		return s.largestFirst()
+	case SelectBranchAndBound, "":
+		if selected := s.branchAndBound(); selected != nil {
+			return s.finish(selected)
+		}
+		return s.largestFirst()
+	default:
+		return nil, fmt.Errorf("unsupported select mode: %s", mode)
+	}
+}
+
+func (s *coinSelector) effectiveValue(i int) int64 {
+	return s.utxos[i].Amount - s.inputFees[i]
+}
+
+func (s *coinSelector) largestFirst() (*CoinSelection, error) {
+	order := make([]int, len(s.utxos))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return s.utxos[order[i]].Amount > s.utxos[order[j]].Amount
+	})
+	target := s.outputTotal + feeForWeight(s.baseWeight, s.feeRate)
+	var selected []Utxo
+	var total int64
+	for _, i := range order {
+		selected = append(selected, s.utxos[i])
+		total += s.effectiveValue(i)
+		if total >= target {
+			return s.finish(selected)
+		}
+	}
+	return nil, ErrInsufficientFunds
+}
+
+// 深度优先搜索有效金额之和落在 [target, target+costOfChange] 内的组合，多余部分计入手续费，选择浪费最少的
+func (s *coinSelector) branchAndBound() []Utxo {
+	if len(s.utxos) == 0 {
+		return nil
+	}
+	order := make([]int, len(s.utxos))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return s.effectiveValue(order[i]) > s.effectiveValue(order[j])
+	})
+	values := make([]int64, len(order))
+	var available int64
+	for i, idx := range order {
+		values[i] = s.effectiveValue(idx)
+		available += values[i]
+	}
+
+	target := s.outputTotal + feeForWeight(s.baseWeight, s.feeRate)
+	changeSpend, err := inputWeight(s.changeScript)
+	if err != nil {
+		changeSpend = 0
+	}
+	costOfChange := feeForWeight(outputWeight(s.changeScript)+changeSpend, s.feeRate)
+	if available < target {
+		return nil
+	}
+
+	var best []bool
+	bestWaste := int64(-1)
+	current := make([]bool, len(values))
+	var currentValue int64
+	remaining := available
+
+	var search func(depth int)
+	tries := 0
+	search = func(depth int) {
+		tries++
+		if tries > bnbMaxTries {
+			return
+		}
+		if currentValue > target+costOfChange || currentValue+remaining < target {
+			return
+		}
+		if currentValue >= target {
+			waste := currentValue - target
+			if bestWaste < 0 || waste < bestWaste {
+				bestWaste = waste
+				best = append(best[:0], current...)
+			}
+			return
+		}
+		if depth == len(values) {
+			return
+		}
+		remaining -= values[depth]
+		current[depth] = true
+		currentValue += values[depth]
+		search(depth + 1)
+		current[depth] = false
+		currentValue -= values[depth]
+		search(depth + 1)
+		remaining += values[depth]
+	}
+	search(0)
+
+	if best == nil {
+		return nil
+	}
+	var selected []Utxo
+	for i, ok := range best {
+		if ok {
+			selected = append(selected, s.utxos[order[i]])
+		}
+	}
+	return selected
+}
+
+// 计算最终手续费和找零，找零低于粉尘阈值时并入手续费
+func (s *coinSelector) finish(selected []Utxo) (*CoinSelection, error) {
+	if len(selected) == 0 {
+		return nil, ErrInsufficientFunds
+	}
+	weight := int64(txOverheadWeight)
+	hasWitness := false
+	var inputTotal int64
+	for _, utxo := range selected {
+		w, err := inputWeight(utxo.PkScript)
+		if err != nil {
+			return nil, err
+		}
+		weight += w
+		inputTotal += utxo.Amount
+		hasWitness = hasWitness || isWitnessScript(utxo.PkScript)
+	}
+	if hasWitness {
+		weight += witnessMarkerWeight
+	}
+	for _, output := range s.outputs {
+		weight += outputWeight(output.PkScript)
+	}
+
+	if s.sweep {
+		fee := feeForWeight(weight, s.feeRate)
+		output := TxOutput{PkScript: s.outputs[0].PkScript, Amount: inputTotal - fee}
+		if output.Amount < s.dust.Threshold(output.PkScript) {
+			return nil, ErrInsufficientFunds
+		}
+		return &CoinSelection{Inputs: selected, Outputs: []TxOutput{output}, Fee: fee, VSize: (weight + 3) / 4}, nil
+	}
+
+	withChange := weight + outputWeight(s.changeScript)
+	feeWithChange := feeForWeight(withChange, s.feeRate)
+	change := inputTotal - s.outputTotal - feeWithChange
+	if change >= s.dust.Threshold(s.changeScript) {
+		return &CoinSelection{Inputs: selected, Outputs: s.outputs, Fee: feeWithChange, Change: change, VSize: (withChange + 3) / 4}, nil
+	}
+
+	fee := inputTotal - s.outputTotal
+	if fee < feeForWeight(weight, s.feeRate) {
+		return nil, ErrInsufficientFunds
+	}
+	return &CoinSelection{Inputs: selected, Outputs: s.outputs, Fee: fee, VSize: (weight + 3) / 4}, nil
+}
@@ -0,0 +1,79 @@
+package bitcoin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+func testScript(t *testing.T, pubKey, addressType string) []byte {
+	addr, err := PubKeyToAddress(pubKey, addressType, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkScript
+}
+
+func Test_SelectCoins(t *testing.T) {
+	script := testScript(t, testPubKey, AddressTypeP2WPKH)
+	utxos := []Utxo{
+		{TxId: "a", Amount: 100000, PkScript: script},
+		{TxId: "b", Amount: 50000, PkScript: script},
+		{TxId: "c", Amount: 20000, PkScript: script},
+		{TxId: "d", Amount: 1000, PkScript: script},
+	}
+	// 1 sat/vB 下 p2wpkh 输入有效金额为 amount-68，基础部分（含一个输出）为 42 vB
+	outputs := []TxOutput{{PkScript: script, Amount: 50000 - 68 + 20000 - 68 - 42}}
+
+	// bnb 找到无找零的精确组合 b+c
+	selection, err := SelectCoins(utxos, outputs, script, 1, SelectBranchAndBound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selection.Inputs) != 2 || selection.Change != 0 || selection.Inputs[0].TxId != "b" || selection.Inputs[1].TxId != "c" {
+		t.Fatalf("unexpected bnb selection %+v", selection)
+	}
+
+	// largest-first 选最大的 a，剩余部分找零
+	selection, err = SelectCoins(utxos, outputs, script, 1, SelectLargestFirst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selection.Inputs) != 1 || selection.Inputs[0].TxId != "a" || selection.Change == 0 {
+		t.Fatalf("unexpected largest-first selection %+v", selection)
+	}
+	if 100000 != outputs[0].Amount+selection.Fee+selection.Change {
+		t.Fatalf("inputs do not balance outputs, fee and change: %+v", selection)
+	}
+
+	// consolidate 归集全部 UTXO，金额为 0 的输出扣除手续费后转入全部余额
+	selection, err = SelectCoins(utxos, []TxOutput{{PkScript: script}}, script, 1, SelectConsolidate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selection.Inputs) != 4 || selection.Outputs[0].Amount != 171000-selection.Fee {
+		t.Fatalf("unexpected consolidate selection %+v", selection)
+	}
+
+	// 找零低于粉尘阈值时并入手续费
+	selection, err = SelectCoins(utxos[2:3], []TxOutput{{PkScript: script, Amount: 19700}}, script, 1, SelectLargestFirst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selection.Change != 0 || selection.Fee != 300 {
+		t.Fatalf("expected dust change to be dropped, got %+v", selection)
+	}
+
+	if _, err := SelectCoins(utxos, []TxOutput{{PkScript: script, Amount: 200}}, script, 1, SelectLargestFirst); err == nil {
+		t.Fatal("expected dust output to be rejected")
+	}
+	if _, err := SelectCoins(utxos, []TxOutput{{PkScript: script, Amount: 200000}}, script, 1, SelectBranchAndBound); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected insufficient funds, got %v", err)
+	}
+}
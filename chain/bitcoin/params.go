@@ -0,0 +1,142 @@
+package bitcoin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// 比特币系链名称
+const (
+	LitecoinChainName    = "Litecoin"
+	DogecoinChainName    = "Dogecoin"
+	BitcoinCashChainName = "BitcoinCash"
+)
+
+// 地址 UTXO 的查询方式
+const (
+	// scantxoutset + addr() 描述符，不依赖节点钱包（Bitcoin Core 0.17 起支持）
+	UtxoSourceScan = "scantxoutset"
+	// 节点钱包的 listunspent，地址需要先以 watch-only 方式 importaddress
+	UtxoSourceWallet = "listunspent"
+)
+
+// BCH 的 SIGHASH_FORKID 标志，签名哈希使用 BIP143 算法
+const sigHashForkId txscript.SigHashType = 0x40
+
+// 某个网络的地址参数
+type NetworkParams struct {
+	Params *chaincfg.Params
+	// 非空时 p2pkh、p2sh 地址使用 CashAddr 格式，仍兼容解析旧格式
+	CashAddrPrefix string
+}
+
+// 比特币系链的参数集，各链共用同一套 adaptor 代码
+type ChainParams struct {
+	Name     string
+	Networks map[string]NetworkParams
+	// 支持的地址类型，第一个为默认类型
+	AddressTypes []string
+	// 签名时附加 SIGHASH_FORKID，所有输入使用 BIP143 签名哈希
+	ForkId bool
+	// 粉尘计算费率（最小单位/vB）及固定最低输出金额
+	DustRelayFeeRate int64
+	DustLimit        int64
+	// 大于 0 时使用固定费率（最小单位/vB），不调用 estimatesmartfee
+	FixedFeeRate int64
+	// UTXO 查询方式，为空时使用 scantxoutset
+	UtxoSource string
+}
+
+var Bitcoin = &ChainParams{
+	Name: ChainName,
+	Networks: map[string]NetworkParams{
+		"mainnet": {Params: &chaincfg.MainNetParams},
+		"testnet": {Params: &chaincfg.TestNet3Params},
+		"signet":  {Params: &chaincfg.SigNetParams},
+		"regtest": {Params: &chaincfg.RegressionNetParams},
+	},
+	AddressTypes:     []string{AddressTypeP2WPKH, AddressTypeP2PKH, AddressTypeP2SHP2WPKH, AddressTypeP2TR},
+	DustRelayFeeRate: 3,
+}
+
+var Litecoin = &ChainParams{
+	Name: LitecoinChainName,
+	Networks: map[string]NetworkParams{
+		"mainnet": {Params: &chaincfg.Params{Name: "litecoin", PubKeyHashAddrID: 0x30, ScriptHashAddrID: 0x32, Bech32HRPSegwit: "ltc", PrivateKeyID: 0xb0, HDCoinType: 2}},
+		"testnet": {Params: &chaincfg.Params{Name: "litecoin-testnet", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0x3a, Bech32HRPSegwit: "tltc", PrivateKeyID: 0xef, HDCoinType: 1}},
+		"regtest": {Params: &chaincfg.Params{Name: "litecoin-regtest", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0x3a, Bech32HRPSegwit: "rltc", PrivateKeyID: 0xef, HDCoinType: 1}},
+	},
+	AddressTypes: []string{AddressTypeP2WPKH, AddressTypeP2PKH, AddressTypeP2SHP2WPKH, AddressTypeP2TR},
+	// litecoind 的 DUST_RELAY_TX_FEE 为 30000 litoshi/kB
+	DustRelayFeeRate: 30,
+}
+
+var Dogecoin = &ChainParams{
+	Name: DogecoinChainName,
+	Networks: map[string]NetworkParams{
+		"mainnet": {Params: &chaincfg.Params{Name: "dogecoin", PubKeyHashAddrID: 0x1e, ScriptHashAddrID: 0x16, PrivateKeyID: 0x9e, HDCoinType: 3}},
+		"testnet": {Params: &chaincfg.Params{Name: "dogecoin-testnet", PubKeyHashAddrID: 0x71, ScriptHashAddrID: 0xc4, PrivateKeyID: 0xf1, HDCoinType: 1}},
+		"regtest": {Params: &chaincfg.Params{Name: "dogecoin-regtest", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0xc4, PrivateKeyID: 0xef, HDCoinType: 1}},
+	},
+	AddressTypes: []string{AddressTypeP2PKH},
+	// 推荐最低手续费 0.01 DOGE/kB，低于 0.01 DOGE 的输出视为粉尘
+	DustRelayFeeRate: 3,
+	DustLimit:        1000000,
+	FixedFeeRate:     1000,
+	// Dogecoin Core 1.14 没有 scantxoutset 和 addr() 描述符
+	UtxoSource: UtxoSourceWallet,
+}
+
+var BitcoinCash = &ChainParams{
+	Name: BitcoinCashChainName,
+	Networks: map[string]NetworkParams{
+		"mainnet": {Params: &chaincfg.Params{Name: "bitcoincash", PubKeyHashAddrID: 0x00, ScriptHashAddrID: 0x05, PrivateKeyID: 0x80, HDCoinType: 145}, CashAddrPrefix: "bitcoincash"},
+		"testnet": {Params: &chaincfg.Params{Name: "bitcoincash-testnet", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0xc4, PrivateKeyID: 0xef, HDCoinType: 1}, CashAddrPrefix: "bchtest"},
+		"regtest": {Params: &chaincfg.Params{Name: "bitcoincash-regtest", PubKeyHashAddrID: 0x6f, ScriptHashAddrID: 0xc4, PrivateKeyID: 0xef, HDCoinType: 1}, CashAddrPrefix: "bchreg"},
+	},
+	AddressTypes:     []string{AddressTypeP2PKH},
+	ForkId:           true,
+	DustRelayFeeRate: 3,
+	// bitcoind-abc/BCHN 已移除 estimatesmartfee，最低转发费率 1 sat/B
+	FixedFeeRate: 1,
+}
+
+// 按网络名称选择地址参数
+func (p *ChainParams) Network(network string) (NetworkParams, error) {
+	network = strings.ToLower(network)
+	switch network {
+	case "":
+		network = "mainnet"
+	case "testnet3":
+		network = "testnet"
+	}
+	net, ok := p.Networks[network]
+	if !ok {
+		return NetworkParams{}, fmt.Errorf("unsupported %s network: %s", p.Name, network)
+	}
+	return net, nil
+}
+
+func (p *ChainParams) supportAddressType(addressType string) bool {
+	for _, item := range p.AddressTypes {
+		if item == addressType {
+			return true
+		}
+	}
+	return false
+}
+
+// 传统输入（及 BCH 全部输入）使用的签名类型
+func (p *ChainParams) sigHashType() txscript.SigHashType {
+	if p.ForkId {
+		return txscript.SigHashAll | sigHashForkId
+	}
+	return txscript.SigHashAll
+}
+
+func (p *ChainParams) dustPolicy() DustPolicy {
+	return DustPolicy{RelayFeeRate: p.DustRelayFeeRate, MinAmount: p.DustLimit}
+}
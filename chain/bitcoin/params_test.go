@@ -0,0 +1,165 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+func testAdaptor(t *testing.T, params *ChainParams, network string) *ChainAdaptor {
+	net, err := params.Network(network)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ChainAdaptor{Chain: params, Params: net.Params, CashAddrPrefix: net.CashAddrPrefix}
+}
+
+// CashAddr 规范中的测试向量
+func Test_CashAddr(t *testing.T) {
+	hash, _ := hex.DecodeString("f5bf48b397dae70be82b3cca4793f8eb2b6cdac9")
+	cases := []struct {
+		prefix   string
+		addrType byte
+		expected string
+	}{
+		{"bitcoincash", cashAddrTypeP2PKH, "bitcoincash:qr6m7j9njldwwzlg9v7v53unlr4jkmx6eylep8ekg2"},
+		{"bchtest", cashAddrTypeP2SH, "bchtest:pr6m7j9njldwwzlg9v7v53unlr4jkmx6eyvwc0uz5t"},
+	}
+	for _, item := range cases {
+		address, err := encodeCashAddr(item.prefix, item.addrType, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if address != item.expected {
+			t.Fatalf("expected %s, got %s", item.expected, address)
+		}
+		addrType, decoded, err := decodeCashAddr(strings.ToUpper(address), item.prefix)
+		if err != nil || addrType != item.addrType || !bytes.Equal(decoded, hash) {
+			t.Fatalf("decode %s fail: %v", address, err)
+		}
+	}
+	if _, _, err := decodeCashAddr("bitcoincash:qr6m7j9njldwwzlg9v7v53unlr4jkmx6eylep8ekg3", "bitcoincash"); err == nil {
+		t.Fatal("expected checksum error")
+	}
+}
+
+func Test_ChainParamsAddress(t *testing.T) {
+	// 旧格式 BCH 地址仍可解析，转换结果为 CashAddr
+	bch := testAdaptor(t, BitcoinCash, "mainnet")
+	legacy, err := bch.decodeAddress("1BpEi6DfDAUFd7GtittLSdBeYJvcoaVggu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cashAddr, _ := bch.encodeAddress(legacy); cashAddr != "bitcoincash:qpm2qsznhks23z7629mms6s4cwef74vcwvy22gdx6a" || !bch.validateAddress(cashAddr) {
+		t.Fatalf("unexpected cashaddr %s", cashAddr)
+	}
+	resp, _ := bch.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPubKey})
+	if !strings.HasPrefix(resp.Address, "bitcoincash:q") || !bch.validateAddress(resp.Address) {
+		t.Fatalf("unexpected bch address %s", resp.Address)
+	}
+	if resp, _ := bch.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPubKey, Type: AddressTypeP2WPKH}); resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected segwit address to be unsupported on bch")
+	}
+
+	ltc := testAdaptor(t, Litecoin, "mainnet")
+	cases := map[string]string{AddressTypeP2PKH: "L", AddressTypeP2SHP2WPKH: "M", AddressTypeP2WPKH: "ltc1q", AddressTypeP2TR: "ltc1p"}
+	for addressType, prefix := range cases {
+		resp, _ := ltc.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPubKey, Type: addressType})
+		if !strings.HasPrefix(resp.Address, prefix) || !ltc.validateAddress(resp.Address) {
+			t.Fatalf("%s: unexpected ltc address %s", addressType, resp.Address)
+		}
+	}
+	if ltc.validateAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4") || ltc.validateAddress("1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH") {
+		t.Fatal("expected bitcoin address to be invalid on litecoin")
+	}
+
+	doge := testAdaptor(t, Dogecoin, "mainnet")
+	resp, _ = doge.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPubKey})
+	if !strings.HasPrefix(resp.Address, "D") || !doge.validateAddress(resp.Address) {
+		t.Fatalf("unexpected doge address %s", resp.Address)
+	}
+	if doge.validateAddress("bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4") {
+		t.Fatal("expected segwit address to be invalid on dogecoin")
+	}
+
+	if (DustPolicy{RelayFeeRate: Dogecoin.DustRelayFeeRate, MinAmount: Dogecoin.DustLimit}).Threshold(make([]byte, 25)) != 1000000 {
+		t.Fatal("expected dogecoin dust limit")
+	}
+	if DustThreshold(make([]byte, 25)) != 546 {
+		t.Fatal("expected bitcoin p2pkh dust threshold 546")
+	}
+}
+
+// BCH 使用 SIGHASH_ALL|SIGHASH_FORKID，签名哈希按 BIP143 计算
+func Test_BitcoinCashSignHash(t *testing.T) {
+	privKey, _ := btcec.NewPrivateKey()
+	pubKey := hex.EncodeToString(privKey.PubKey().SerializeCompressed())
+	addr, _ := btcutil.NewAddressPubKeyHash(btcutil.Hash160(privKey.PubKey().SerializeCompressed()), BitcoinCash.Networks["mainnet"].Params)
+	pkScript, _ := txscript.PayToAddrScript(addr)
+
+	prevTx := wire.NewMsgTx(wire.TxVersion)
+	prevTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	prevTx.AddTxOut(wire.NewTxOut(100000, pkScript))
+	var buf bytes.Buffer
+	_ = prevTx.Serialize(&buf)
+	prevJson, _ := json.Marshal(map[string]any{"txid": prevTx.TxHash().String(), "hex": hex.EncodeToString(buf.Bytes())})
+
+	adaptor := newRpcStandIn(t, map[string]string{"getrawtransaction": string(prevJson)})
+	adaptor.Chain = BitcoinCash
+	adaptor.Params = BitcoinCash.Networks["mainnet"].Params
+	adaptor.CashAddrPrefix = BitcoinCash.Networks["mainnet"].CashAddrPrefix
+
+	from, _ := adaptor.encodeAddress(addr)
+	txJson, _ := json.Marshal(&BtcTransferTx{
+		FromAddress: from,
+		Utxos:       []TransferUtxo{{TxId: prevTx.TxHash().String(), Vout: 0, Amount: 100000, ScriptPubKey: hex.EncodeToString(pkScript)}},
+		Outputs:     []TransferOutput{{Address: "bitcoincash:qr6m7j9njldwwzlg9v7v53unlr4jkmx6eylep8ekg2", Amount: 50000}},
+	})
+	unSigned, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if unSigned.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned tx fail: %s", unSigned.Msg)
+	}
+
+	hash, _ := hex.DecodeString(unSigned.SignHashes[0])
+	signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  unSigned.UnSignTx,
+		PublicKey: pubKey,
+		Signature: hex.EncodeToString(ecdsa.Sign(privKey, hash).Serialize()),
+	})
+	if signed.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed tx fail: %s", signed.Msg)
+	}
+	rawTx, _ := hex.DecodeString(signed.SignedTx)
+	tx := wire.NewMsgTx(2)
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		t.Fatal(err)
+	}
+	// 固定费率 1 sat/B，手续费按估算大小计算，不低于实际大小
+	if fee := 100000 - 50000 - tx.TxOut[1].Value; len(tx.TxOut) != 2 || fee < int64(len(rawTx)) || fee > int64(len(rawTx))+10 {
+		t.Fatalf("unexpected outputs %+v", tx.TxOut)
+	}
+
+	// 签名哈希与 BIP143 一致，scriptSig 中的签名类型为 0x41
+	sigHashes := txscript.NewTxSigHashes(tx, txscript.NewCannedPrevOutputFetcher(pkScript, 100000))
+	expected, _ := txscript.CalcWitnessSigHash(pkScript, sigHashes, txscript.SigHashAll|sigHashForkId, tx, 0, 100000)
+	if !bytes.Equal(hash, expected) {
+		t.Fatal("unexpected bch sign hash")
+	}
+	pushes, err := txscript.PushedData(tx.TxIn[0].SignatureScript)
+	if err != nil || len(pushes) != 2 || pushes[0][len(pushes[0])-1] != 0x41 {
+		t.Fatalf("unexpected signature script %x", tx.TxIn[0].SignatureScript)
+	}
+}
@@ -0,0 +1,413 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 构建未签名交易：选币后生成 PSBT，un_sign_tx 为 base64 编码的 PSBT，sign_hashes 为每个输入待签名的哈希
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx BtcTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+
+	packet, err := c.buildPsbt(&transferTx)
+	if err != nil {
+		log.Error("build psbt fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	hashes, err := signHashes(packet, c.Chain.sigHashType())
+	if err != nil {
+		log.Error("calc sign hash fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "calc sign hash fail",
+		}, nil
+	}
+	unSignTx, err := packet.B64Encode()
+	if err != nil {
+		log.Error("encode psbt fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "encode psbt fail",
+		}, nil
+	}
+	var signHashList []string
+	for _, hash := range hashes {
+		signHashList = append(signHashList, hex.EncodeToString(hash))
+	}
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   unSignTx,
+		SignHashes: signHashList,
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 PSBT，signatures 按输入顺序传入签名（单输入时也可使用 signature）
+// ECDSA 签名支持 64 字节 r||s（可带 1 字节 v）或 DER 格式，taproot 输入为 64 字节 schnorr 签名
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	packet, err := psbt.NewFromRawBytes(strings.NewReader(req.Base64Tx), true)
+	if err != nil {
+		log.Error("decode psbt fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode psbt fail",
+		}, nil
+	}
+	signatures := req.Signatures
+	if len(signatures) == 0 && req.Signature != "" {
+		signatures = []string{req.Signature}
+	}
+	if len(signatures) != len(packet.Inputs) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  fmt.Sprintf("expected %d signatures, got %d", len(packet.Inputs), len(signatures)),
+		}, nil
+	}
+
+	if err := addSignatures(packet, signatures, req.PublicKey, c.Chain.sigHashType()); err != nil {
+		log.Error("add signatures fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	if err := psbt.MaybeFinalizeAll(packet); err != nil {
+		log.Error("finalize psbt fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "finalize psbt fail",
+		}, nil
+	}
+	signedTx, err := psbt.Extract(packet)
+	if err != nil {
+		log.Error("extract signed tx fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "extract signed tx fail",
+		}, nil
+	}
+	var buf bytes.Buffer
+	if err := signedTx.Serialize(&buf); err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "serialize signed tx fail",
+		}, nil
+	}
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      signedTx.TxHash().String(),
+		SignedTx: hex.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+func (c *ChainAdaptor) buildPsbt(transferTx *BtcTransferTx) (*psbt.Packet, error) {
+	if !c.validateAddress(transferTx.FromAddress) {
+		return nil, errors.New("invalid from address")
+	}
+	changeAddress := transferTx.ChangeAddress
+	if changeAddress == "" {
+		changeAddress = transferTx.FromAddress
+	}
+	changeScript, err := c.addressScript(changeAddress)
+	if err != nil {
+		return nil, errors.New("invalid change address")
+	}
+
+	var outputs []TxOutput
+	for _, output := range transferTx.Outputs {
+		pkScript, err := c.addressScript(output.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output address: %s", output.Address)
+		}
+		outputs = append(outputs, TxOutput{PkScript: pkScript, Amount: output.Amount})
+	}
+
+	var utxos []Utxo
+	if len(transferTx.Utxos) > 0 {
+		for _, item := range transferTx.Utxos {
+			pkScript, err := hex.DecodeString(item.ScriptPubKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid script pub key: %s", item.ScriptPubKey)
+			}
+			utxos = append(utxos, Utxo{TxId: item.TxId, Vout: item.Vout, Amount: item.Amount, PkScript: pkScript})
+		}
+	} else {
+		utxos, err = c.listUnspent(transferTx.FromAddress, transferTx.MinConfirmations)
+		if err != nil {
+			return nil, fmt.Errorf("list unspent fail: %w", err)
+		}
+	}
+
+	feeRate := transferTx.FeeRate
+	if feeRate == 0 {
+		feeRate, err = c.estimateFeeRate(feeConfTargets[1])
+		if err != nil {
+			return nil, fmt.Errorf("estimate fee fail: %w", err)
+		}
+	}
+
+	selection, err := SelectCoinsWithDust(utxos, outputs, changeScript, feeRate, SelectMode(transferTx.Mode), c.Chain.dustPolicy())
+	if err != nil {
+		return nil, err
+	}
+	log.Info("coin selection", "chain", c.Chain.Name, "inputs", len(selection.Inputs), "fee", selection.Fee, "change", selection.Change, "vsize", selection.VSize)
+
+	tx := wire.NewMsgTx(wire.TxVersion + 1)
+	for _, utxo := range selection.Inputs {
+		hash, err := chainhash.NewHashFromStr(utxo.TxId)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tx id: %s", utxo.TxId)
+		}
+		txIn := wire.NewTxIn(wire.NewOutPoint(hash, utxo.Vout), nil, nil)
+		// 允许 RBF
+		txIn.Sequence = wire.MaxTxInSequenceNum - 2
+		tx.AddTxIn(txIn)
+	}
+	for _, output := range selection.Outputs {
+		tx.AddTxOut(wire.NewTxOut(output.Amount, output.PkScript))
+	}
+	if selection.Change > 0 {
+		tx.AddTxOut(wire.NewTxOut(selection.Change, changeScript))
+	}
+
+	packet, err := psbt.NewFromUnsignedTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	for i, utxo := range selection.Inputs {
+		if err := c.fillInput(&packet.Inputs[i], utxo, transferTx.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+	return packet, nil
+}
+
+// 补充 PSBT 输入签名所需的信息
+func (c *ChainAdaptor) fillInput(input *psbt.PInput, utxo Utxo, publicKey string) error {
+	scriptClass := txscript.GetScriptClass(utxo.PkScript)
+	switch scriptClass {
+	case txscript.ScriptHashTy:
+		if !c.Chain.supportAddressType(AddressTypeP2SHP2WPKH) {
+			return fmt.Errorf("%s does not support spending p2sh outputs", c.Chain.Name)
+		}
+	case txscript.WitnessV0PubKeyHashTy, txscript.WitnessV1TaprootTy:
+		if !c.Chain.supportAddressType(AddressTypeP2WPKH) {
+			return fmt.Errorf("%s does not support spending segwit outputs", c.Chain.Name)
+		}
+	}
+
+	switch scriptClass {
+	case txscript.PubKeyHashTy:
+		// 非隔离见证输入需要完整的前序交易
+		prev, err := c.BtcClient.GetRawTransaction(utxo.TxId)
+		if err != nil {
+			return fmt.Errorf("get previous tx %s fail: %w", utxo.TxId, err)
+		}
+		rawPrev, err := hex.DecodeString(prev.Hex)
+		if err != nil {
+			return err
+		}
+		prevTx := wire.NewMsgTx(wire.TxVersion)
+		if err := prevTx.Deserialize(bytes.NewReader(rawPrev)); err != nil {
+			return err
+		}
+		input.NonWitnessUtxo = prevTx
+		input.SighashType = c.Chain.sigHashType()
+	case txscript.ScriptHashTy:
+		pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(publicKey, "0x"))
+		if err != nil || len(pubKeyBytes) != btcec.PubKeyBytesLenCompressed {
+			return errors.New("compressed public key is required to spend p2sh-p2wpkh outputs")
+		}
+		redeemScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(btcutil.Hash160(pubKeyBytes)).Script()
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(utxo.PkScript[2:22], btcutil.Hash160(redeemScript)) {
+			return fmt.Errorf("public key does not match p2sh output %s:%d", utxo.TxId, utxo.Vout)
+		}
+		input.RedeemScript = redeemScript
+		input.WitnessUtxo = wire.NewTxOut(utxo.Amount, utxo.PkScript)
+		input.SighashType = txscript.SigHashAll
+	case txscript.WitnessV0PubKeyHashTy:
+		input.WitnessUtxo = wire.NewTxOut(utxo.Amount, utxo.PkScript)
+		input.SighashType = txscript.SigHashAll
+	case txscript.WitnessV1TaprootTy:
+		input.WitnessUtxo = wire.NewTxOut(utxo.Amount, utxo.PkScript)
+	default:
+		return fmt.Errorf("unsupported input script: %x", utxo.PkScript)
+	}
+	return nil
+}
+
+func (c *ChainAdaptor) addressScript(address string) ([]byte, error) {
+	addr, err := c.decodeAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %s", address)
+	}
+	return txscript.PayToAddrScript(addr)
+}
+
+// 输入花费的前序输出
+func prevOutput(packet *psbt.Packet, i int) (*wire.TxOut, error) {
+	input := packet.Inputs[i]
+	if input.WitnessUtxo != nil {
+		return input.WitnessUtxo, nil
+	}
+	if input.NonWitnessUtxo != nil {
+		index := packet.UnsignedTx.TxIn[i].PreviousOutPoint.Index
+		if int(index) < len(input.NonWitnessUtxo.TxOut) {
+			return input.NonWitnessUtxo.TxOut[index], nil
+		}
+	}
+	return nil, fmt.Errorf("missing previous output for input %d", i)
+}
+
+// 计算每个输入待签名的哈希，hashType 用于 p2pkh 输入；带 SIGHASH_FORKID 时按 BIP143 计算（BCH）
+func signHashes(packet *psbt.Packet, hashType txscript.SigHashType) ([][]byte, error) {
+	tx := packet.UnsignedTx
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	prevOuts := make([]*wire.TxOut, len(tx.TxIn))
+	for i, txIn := range tx.TxIn {
+		prevOut, err := prevOutput(packet, i)
+		if err != nil {
+			return nil, err
+		}
+		prevOuts[i] = prevOut
+		fetcher.AddPrevOut(txIn.PreviousOutPoint, prevOut)
+	}
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+
+	hashes := make([][]byte, len(tx.TxIn))
+	for i, prevOut := range prevOuts {
+		var hash []byte
+		var err error
+		switch txscript.GetScriptClass(prevOut.PkScript) {
+		case txscript.PubKeyHashTy:
+			if hashType&sigHashForkId != 0 {
+				hash, err = txscript.CalcWitnessSigHash(prevOut.PkScript, sigHashes, hashType, tx, i, prevOut.Value)
+			} else {
+				hash, err = txscript.CalcSignatureHash(prevOut.PkScript, hashType, tx, i)
+			}
+		case txscript.ScriptHashTy:
+			hash, err = txscript.CalcWitnessSigHash(packet.Inputs[i].RedeemScript, sigHashes, txscript.SigHashAll, tx, i, prevOut.Value)
+		case txscript.WitnessV0PubKeyHashTy:
+			hash, err = txscript.CalcWitnessSigHash(prevOut.PkScript, sigHashes, txscript.SigHashAll, tx, i, prevOut.Value)
+		case txscript.WitnessV1TaprootTy:
+			hash, err = txscript.CalcTaprootSignatureHash(sigHashes, txscript.SigHashDefault, tx, i, fetcher)
+		default:
+			err = fmt.Errorf("unsupported input script: %x", prevOut.PkScript)
+		}
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hash
+	}
+	return hashes, nil
+}
+
+// 校验签名并写入 PSBT
+func addSignatures(packet *psbt.Packet, signatures []string, publicKey string, hashType txscript.SigHashType) error {
+	hashes, err := signHashes(packet, hashType)
+	if err != nil {
+		return err
+	}
+	var pubKeyBytes []byte
+	var pubKey *btcec.PublicKey
+	if publicKey != "" {
+		pubKeyBytes, err = hex.DecodeString(strings.TrimPrefix(publicKey, "0x"))
+		if err != nil {
+			return errors.New("invalid public key")
+		}
+		if pubKey, err = btcec.ParsePubKey(pubKeyBytes); err != nil {
+			return errors.New("invalid public key")
+		}
+	}
+
+	for i, signature := range signatures {
+		sigBytes, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid signature at input %d", i)
+		}
+		prevOut, _ := prevOutput(packet, i)
+		if txscript.GetScriptClass(prevOut.PkScript) == txscript.WitnessV1TaprootTy {
+			outputKey, err := schnorr.ParsePubKey(prevOut.PkScript[2:])
+			if err != nil {
+				return err
+			}
+			sig, err := schnorr.ParseSignature(sigBytes)
+			if err != nil || !sig.Verify(hashes[i], outputKey) {
+				return fmt.Errorf("invalid schnorr signature at input %d", i)
+			}
+			packet.Inputs[i].TaprootKeySpendSig = sig.Serialize()
+			continue
+		}
+
+		if pubKey == nil {
+			return errors.New("public key is required for ecdsa inputs")
+		}
+		sig, err := parseEcdsaSignature(sigBytes)
+		if err != nil || !sig.Verify(hashes[i], pubKey) {
+			return fmt.Errorf("invalid ecdsa signature at input %d", i)
+		}
+		sigHashType := txscript.SigHashAll
+		if txscript.GetScriptClass(prevOut.PkScript) == txscript.PubKeyHashTy {
+			sigHashType = hashType
+		}
+		packet.Inputs[i].PartialSigs = []*psbt.PartialSig{{
+			PubKey:    pubKeyBytes,
+			Signature: append(sig.Serialize(), byte(sigHashType)),
+		}}
+	}
+	return nil
+}
+
+// 解析 64 字节 r||s（可带 1 字节 v）或 DER 格式的 ECDSA 签名
+func parseEcdsaSignature(sigBytes []byte) (*ecdsa.Signature, error) {
+	if len(sigBytes) == 64 || len(sigBytes) == 65 {
+		var r, s btcec.ModNScalar
+		if overflow := r.SetByteSlice(sigBytes[:32]); overflow {
+			return nil, errors.New("invalid signature r")
+		}
+		if overflow := s.SetByteSlice(sigBytes[32:64]); overflow {
+			return nil, errors.New("invalid signature s")
+		}
+		return ecdsa.NewSignature(&r, &s), nil
+	}
+	return ecdsa.ParseDERSignature(sigBytes)
+}
@@ -0,0 +1,118 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 构建 p2wpkh、p2sh-p2wpkh、p2tr 三种输入的交易，外部签名后执行脚本校验
+func Test_BuildAndSignTransaction(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := hex.EncodeToString(privKey.PubKey().SerializeCompressed())
+	adaptor := &ChainAdaptor{Chain: Bitcoin, Params: &chaincfg.MainNetParams}
+
+	var utxos []TransferUtxo
+	for i, addressType := range []string{AddressTypeP2WPKH, AddressTypeP2SHP2WPKH, AddressTypeP2TR} {
+		address, err := PubKeyToAddress(pubKey, addressType, adaptor.Params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkScript, _ := txscript.PayToAddrScript(address)
+		utxos = append(utxos, TransferUtxo{
+			TxId:         hex.EncodeToString(bytes.Repeat([]byte{byte(i + 1)}, 32)),
+			Vout:         uint32(i),
+			Amount:       30000,
+			ScriptPubKey: hex.EncodeToString(pkScript),
+		})
+	}
+	from, _ := PubKeyToAddress(pubKey, AddressTypeP2WPKH, adaptor.Params)
+	txJson, _ := json.Marshal(&BtcTransferTx{
+		FromAddress: from.EncodeAddress(),
+		Utxos:       utxos,
+		Outputs:     []TransferOutput{{Address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", Amount: 80000}},
+		FeeRate:     5,
+		Mode:        string(SelectConsolidate),
+		PublicKey:   pubKey,
+	})
+
+	unSigned, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if unSigned.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned tx fail: %s", unSigned.Msg)
+	}
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader([]byte(unSigned.UnSignTx)), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unSigned.SignHashes) != len(packet.UnsignedTx.TxIn) {
+		t.Fatalf("expected %d sign hashes, got %d", len(packet.UnsignedTx.TxIn), len(unSigned.SignHashes))
+	}
+
+	// ECDSA 输入使用 DER 签名，taproot 输入使用调整后的私钥做 schnorr 签名
+	fetcher := txscript.NewMultiPrevOutFetcher(nil)
+	signatures := make([]string, len(unSigned.SignHashes))
+	for i, signHash := range unSigned.SignHashes {
+		prevOut, err := prevOutput(packet, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fetcher.AddPrevOut(packet.UnsignedTx.TxIn[i].PreviousOutPoint, prevOut)
+		hash, _ := hex.DecodeString(signHash)
+		if txscript.GetScriptClass(prevOut.PkScript) == txscript.WitnessV1TaprootTy {
+			sig, err := schnorr.Sign(txscript.TweakTaprootPrivKey(*privKey, nil), hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signatures[i] = hex.EncodeToString(sig.Serialize())
+		} else {
+			signatures[i] = hex.EncodeToString(ecdsa.Sign(privKey, hash).Serialize())
+		}
+	}
+
+	// 签名与输入不匹配时拒绝
+	swapped := append([]string{signatures[1], signatures[0]}, signatures[2:]...)
+	rejected, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, PublicKey: pubKey, Signatures: swapped})
+	if rejected.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected mismatched signatures to be rejected")
+	}
+
+	signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, PublicKey: pubKey, Signatures: signatures})
+	if signed.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed tx fail: %s", signed.Msg)
+	}
+	rawTx, _ := hex.DecodeString(signed.SignedTx)
+	tx := wire.NewMsgTx(2)
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		t.Fatal(err)
+	}
+	if signed.Msg != tx.TxHash().String() {
+		t.Fatalf("expected txid %s, got %s", tx.TxHash(), signed.Msg)
+	}
+	sigHashes := txscript.NewTxSigHashes(tx, fetcher)
+	for i, txIn := range tx.TxIn {
+		prevOut := fetcher.FetchPrevOutput(txIn.PreviousOutPoint)
+		vm, err := txscript.NewEngine(prevOut.PkScript, tx, i, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, fetcher)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Fatalf("input %d script verify fail: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package bitcoin
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type BtcTransferTx struct {
+	FromAddress   string `json:"from_address"`
+	ChangeAddress string `json:"change_address"` // 为空时找零到 from_address
+	// 指定可用的 UTXO，为空时通过节点查询 from_address 的 UTXO
+	Utxos            []TransferUtxo   `json:"utxos"`
+	Outputs          []TransferOutput `json:"outputs"`
+	FeeRate          int64            `json:"fee_rate"` // sat/vB，为 0 时使用节点估算的普通费率
+	Mode             string           `json:"mode"`     // 选币策略：bnb、largest-first、consolidate
+	MinConfirmations uint64           `json:"min_confirmations"`
+	// 压缩公钥，花费 p2sh-p2wpkh 输出时用于生成赎回脚本
+	PublicKey string `json:"public_key"`
+}
+
+type TransferUtxo struct {
+	TxId         string `json:"tx_id"`
+	Vout         uint32 `json:"vout"`
+	Amount       int64  `json:"amount"` // 单位 satoshi
+	ScriptPubKey string `json:"script_pub_key"`
+}
+
+type TransferOutput struct {
+	Address string `json:"address"`
+	Amount  int64  `json:"amount"` // 单位 satoshi
+}
@@ -0,0 +1,145 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+const maxListUnspentConf = 9999999
+
+var ErrUtxoSourceUnavailable = errors.New("utxo source unavailable")
+
+// UTXO 来源不可用时把原因返回给调用方，其余错误使用通用提示
+func unspentErrorMsg(err error, msg string) string {
+	if errors.Is(err, ErrUtxoSourceUnavailable) {
+		return err.Error()
+	}
+	return msg
+}
+
+// 查询地址的未花费输出，minConfirmations 为 0 时包含全部已上链的 UTXO
+func (c *ChainAdaptor) listUnspent(address string, minConfirmations uint64) ([]Utxo, error) {
+	if c.Chain.UtxoSource == UtxoSourceWallet {
+		return c.listWalletUnspent(address, minConfirmations)
+	}
+	result, err := c.BtcClient.ScanTxOutSet([]string{address})
+	if err != nil {
+		return nil, err
+	}
+	var utxos []Utxo
+	for _, unspent := range result.Unspents {
+		amount, err := btcutil.NewAmount(unspent.Amount)
+		if err != nil {
+			return nil, err
+		}
+		pkScript, err := hex.DecodeString(unspent.ScriptPubKey)
+		if err != nil {
+			return nil, err
+		}
+		var confirmations uint64
+		if unspent.Height > 0 && result.Height >= unspent.Height {
+			confirmations = uint64(result.Height-unspent.Height) + 1
+		}
+		if confirmations < minConfirmations {
+			continue
+		}
+		utxos = append(utxos, Utxo{
+			TxId:          unspent.TxId,
+			Vout:          unspent.Vout,
+			Amount:        int64(amount),
+			PkScript:      pkScript,
+			Address:       address,
+			Height:        uint64(unspent.Height),
+			Confirmations: confirmations,
+		})
+	}
+	return utxos, nil
+}
+
+// 通过节点钱包的 listunspent 查询，地址未以 watch-only 方式导入时返回错误，避免把空结果当作余额为 0
+func (c *ChainAdaptor) listWalletUnspent(address string, minConfirmations uint64) ([]Utxo, error) {
+	info, err := c.BtcClient.ValidateAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsMine && !info.IsWatchOnly {
+		return nil, fmt.Errorf("%w: %s address %s is not in the node wallet, import it with importaddress", ErrUtxoSourceUnavailable, c.Chain.Name, address)
+	}
+	count, err := c.BtcClient.GetBlockCount()
+	if err != nil {
+		return nil, err
+	}
+	// 与 scantxoutset 一致，只返回已上链的 UTXO
+	unspents, err := c.BtcClient.ListUnspent(int64(max(minConfirmations, 1)), maxListUnspentConf, []string{address})
+	if err != nil {
+		return nil, err
+	}
+	var utxos []Utxo
+	for _, unspent := range unspents {
+		amount, err := btcutil.NewAmount(unspent.Amount)
+		if err != nil {
+			return nil, err
+		}
+		pkScript, err := hex.DecodeString(unspent.ScriptPubKey)
+		if err != nil {
+			return nil, err
+		}
+		var height uint64
+		if unspent.Confirmations > 0 && count >= unspent.Confirmations-1 {
+			height = uint64(count - unspent.Confirmations + 1)
+		}
+		utxos = append(utxos, Utxo{
+			TxId:          unspent.TxID,
+			Vout:          unspent.Vout,
+			Amount:        int64(amount),
+			PkScript:      pkScript,
+			Address:       address,
+			Height:        height,
+			Confirmations: uint64(unspent.Confirmations),
+		})
+	}
+	return utxos, nil
+}
+
+// 查询地址的未花费输出
+func (c *ChainAdaptor) GetUnspentOutputs(req *account.UnspentOutputsRequest) (*account.UnspentOutputsResponse, error) {
+	if !c.validateAddress(req.Address) {
+		return &account.UnspentOutputsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	utxos, err := c.listUnspent(req.Address, req.MinConfirmations)
+	if err != nil {
+		log.Error("list unspent fail", "address", req.Address, "err", err)
+		return &account.UnspentOutputsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  unspentErrorMsg(err, "get unspent outputs fail"),
+		}, nil
+	}
+	var unspentOutputs []*account.UnspentOutput
+	for _, utxo := range utxos {
+		unspentOutputs = append(unspentOutputs, &account.UnspentOutput{
+			TxId:          utxo.TxId,
+			Vout:          utxo.Vout,
+			Amount:        strconv.FormatInt(utxo.Amount, 10),
+			Address:       utxo.Address,
+			ScriptPubKey:  hex.EncodeToString(utxo.PkScript),
+			Height:        utxo.Height,
+			Confirmations: utxo.Confirmations,
+		})
+	}
+	return &account.UnspentOutputsResponse{
+		Code:           global_const.ReturnCode_SUCCESS,
+		Msg:            "get unspent outputs success",
+		UnspentOutputs: unspentOutputs,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package cardano
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"golang.org/x/crypto/blake2b"
+)
+
+// 网络标识，写在地址头部的低 4 位
+const (
+	NetworkTestnet byte = 0
+	NetworkMainnet byte = 1
+)
+
+// Shelley 地址类型，写在地址头部的高 4 位
+const (
+	addressTypeBase       byte = 0 // 支付凭证 + 质押凭证
+	addressTypeEnterprise byte = 6 // 仅支付凭证
+)
+
+const keyHashLength = 28
+
+// 支付（或质押）公钥的 blake2b-224 哈希
+type KeyHash [keyHashLength]byte
+
+func HashKey(pubKey []byte) KeyHash {
+	h, _ := blake2b.New(keyHashLength, nil)
+	h.Write(pubKey)
+	var hash KeyHash
+	copy(hash[:], h.Sum(nil))
+	return hash
+}
+
+// Shelley 地址的二进制形式：1 字节头部 + 凭证
+type Address []byte
+
+// 由支付公钥哈希生成地址，stake 为空时为 enterprise 地址，否则为 base 地址
+func NewAddress(network byte, payment KeyHash, stake *KeyHash) Address {
+	if stake == nil {
+		return append(Address{addressTypeEnterprise<<4 | network}, payment[:]...)
+	}
+	address := append(Address{addressTypeBase<<4 | network}, payment[:]...)
+	return append(address, stake[:]...)
+}
+
+func (a Address) Network() byte {
+	return a[0] & 0x0f
+}
+
+func (a Address) String() string {
+	hrp := "addr"
+	if a.Network() == NetworkTestnet {
+		hrp = "addr_test"
+	}
+	encoded, _ := bech32.EncodeFromBase256(hrp, a)
+	return encoded
+}
+
+// 解析 bech32 编码的 Shelley 地址，支持 base（类型 0~3）和 enterprise（类型 6、7），
+// 不支持 Byron 地址和已弃用的 pointer 地址
+func ParseAddress(address string, network byte) (Address, error) {
+	hrp, data, err := bech32.DecodeNoLimit(address)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("empty address")
+	}
+	addr := Address(raw)
+	expectedHrp := "addr"
+	if network == NetworkTestnet {
+		expectedHrp = "addr_test"
+	}
+	if hrp != expectedHrp || addr.Network() != network {
+		return nil, errors.New("address network mismatch")
+	}
+	switch addressType := raw[0] >> 4; {
+	case addressType <= 3:
+		if len(raw) != 1+2*keyHashLength {
+			return nil, errors.New("invalid base address length")
+		}
+	case addressType == 6 || addressType == 7:
+		if len(raw) != 1+keyHashLength {
+			return nil, errors.New("invalid enterprise address length")
+		}
+	default:
+		return nil, errors.New("unsupported address type")
+	}
+	return addr, nil
+}
+
+func ValidateAddress(address string, network byte) bool {
+	_, err := ParseAddress(address, network)
+	return err == nil
+}
+
+// 解析 hex 公钥：32 字节为支付公钥，生成 enterprise 地址；
+// 64 字节为支付公钥和质押公钥拼接，生成 base 地址
+func ParsePublicKeys(publicKey string) (payment []byte, stake []byte, err error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(publicKey, "0x"))
+	if err != nil {
+		return nil, nil, err
+	}
+	switch len(pubKey) {
+	case 32:
+		return pubKey, nil, nil
+	case 64:
+		return pubKey[:32], pubKey[32:], nil
+	default:
+		return nil, nil, errors.New("invalid public key length")
+	}
+}
@@ -0,0 +1,286 @@
+package cardano
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// Blockfrost 单页最多返回的记录数
+const maxPageCount = 100
+
+// Blockfrost 返回的错误，地址、交易不存在时 HTTP 状态为 404
+type ApiError struct {
+	StatusCode int    `json:"status_code"`
+	ErrorName  string `json:"error"`
+	Message    string `json:"message"`
+}
+
+func (e *ApiError) Error() string {
+	return fmt.Sprintf("api error %d %s: %s", e.StatusCode, e.ErrorName, e.Message)
+}
+
+func IsNotFound(err error) bool {
+	var apiErr *ApiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// unit 为 lovelace 或 policy id + 资产名（hex），quantity 为最小单位的整数
+type Amount struct {
+	Unit     string `json:"unit"`
+	Quantity string `json:"quantity"`
+}
+
+type AddressInfo struct {
+	Address string   `json:"address"`
+	Amount  []Amount `json:"amount"`
+}
+
+type AddressUtxo struct {
+	TxHash      string   `json:"tx_hash"`
+	OutputIndex uint32   `json:"output_index"`
+	Amount      []Amount `json:"amount"`
+	Block       string   `json:"block"`
+	// 带 datum 或引用脚本的输出通常属于合约，选币时跳过
+	DataHash            *string `json:"data_hash"`
+	InlineDatum         *string `json:"inline_datum"`
+	ReferenceScriptHash *string `json:"reference_script_hash"`
+}
+
+// 协议参数，数值类参数为字符串
+type ProtocolParams struct {
+	Epoch            uint64 `json:"epoch"`
+	MinFeeA          uint64 `json:"min_fee_a"`
+	MinFeeB          uint64 `json:"min_fee_b"`
+	MaxTxSize        uint64 `json:"max_tx_size"`
+	CoinsPerUtxoSize string `json:"coins_per_utxo_size"`
+}
+
+type Block struct {
+	Hash          string  `json:"hash"`
+	Height        *int64  `json:"height"`
+	Slot          *uint64 `json:"slot"`
+	Time          int64   `json:"time"`
+	PreviousBlock string  `json:"previous_block"`
+	TxCount       int     `json:"tx_count"`
+	Confirmations uint64  `json:"confirmations"`
+}
+
+type Transaction struct {
+	Hash          string `json:"hash"`
+	Block         string `json:"block"`
+	BlockHeight   int64  `json:"block_height"`
+	BlockTime     int64  `json:"block_time"`
+	Slot          uint64 `json:"slot"`
+	Fees          string `json:"fees"`
+	ValidContract bool   `json:"valid_contract"`
+}
+
+type TxUtxo struct {
+	Address     string   `json:"address"`
+	Amount      []Amount `json:"amount"`
+	TxHash      string   `json:"tx_hash"`
+	OutputIndex uint32   `json:"output_index"`
+	Collateral  bool     `json:"collateral"`
+	Reference   bool     `json:"reference"`
+}
+
+type TxUtxos struct {
+	Hash    string   `json:"hash"`
+	Inputs  []TxUtxo `json:"inputs"`
+	Outputs []TxUtxo `json:"outputs"`
+}
+
+type AddressTransaction struct {
+	TxHash      string `json:"tx_hash"`
+	BlockHeight int64  `json:"block_height"`
+	BlockTime   int64  `json:"block_time"`
+}
+
+// 定义 Blockfrost 接口
+type IBlockfrost interface {
+	GetLatestBlock() (*Block, error)
+	// hashOrNumber 为区块哈希或高度
+	GetBlock(hashOrNumber string) (*Block, error)
+	GetBlockTxs(hashOrNumber string) ([]string, error)
+	// 地址从未上链时返回 404
+	GetAddress(address string) (*AddressInfo, error)
+	GetAddressUtxos(address string) ([]AddressUtxo, error)
+	// 按时间倒序分页查询地址相关的交易，page 从 1 开始
+	GetAddressTransactions(address string, page uint32, count uint32) ([]AddressTransaction, error)
+	GetProtocolParams() (*ProtocolParams, error)
+	GetTransaction(hash string) (*Transaction, error)
+	GetTransactionUtxos(hash string) (*TxUtxos, error)
+	// 提交 CBOR 编码的签名交易，返回交易哈希
+	SubmitTransaction(tx []byte) (string, error)
+}
+
+// 定义 Blockfrost REST 客户端
+type BlockfrostClient struct {
+	url       string
+	projectId string
+	client    *http.Client
+}
+
+func NewBlockfrostClient(rpcUrl string, projectId string, timeout time.Duration) (IBlockfrost, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &BlockfrostClient{
+		url:       strings.TrimSuffix(rpcUrl, "/"),
+		projectId: projectId,
+		client:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (b *BlockfrostClient) do(req *http.Request, result any) error {
+	req.Header.Set("Accept", "application/json")
+	if b.projectId != "" {
+		req.Header.Set("project_id", b.projectId)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &ApiError{}
+		if json.Unmarshal(respBody, apiErr) != nil || apiErr.ErrorName == "" {
+			apiErr.Message = string(respBody)
+		}
+		apiErr.StatusCode = resp.StatusCode
+		return apiErr
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+func (b *BlockfrostClient) get(path string, query url.Values, result any) error {
+	target := b.url + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	return b.do(req, result)
+}
+
+// 逐页获取全部记录
+func getAll[T any](b *BlockfrostClient, path string) ([]T, error) {
+	var records []T
+	for page := 1; ; page++ {
+		var result []T
+		query := url.Values{"count": {strconv.Itoa(maxPageCount)}, "page": {strconv.Itoa(page)}}
+		if err := b.get(path, query, &result); err != nil {
+			return nil, err
+		}
+		records = append(records, result...)
+		if len(result) < maxPageCount {
+			return records, nil
+		}
+	}
+}
+
+func (b *BlockfrostClient) GetLatestBlock() (*Block, error) {
+	block := new(Block)
+	if err := b.get("/blocks/latest", nil, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (b *BlockfrostClient) GetBlock(hashOrNumber string) (*Block, error) {
+	block := new(Block)
+	if err := b.get("/blocks/"+url.PathEscape(hashOrNumber), nil, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (b *BlockfrostClient) GetBlockTxs(hashOrNumber string) ([]string, error) {
+	return getAll[string](b, "/blocks/"+url.PathEscape(hashOrNumber)+"/txs")
+}
+
+func (b *BlockfrostClient) GetAddress(address string) (*AddressInfo, error) {
+	info := new(AddressInfo)
+	if err := b.get("/addresses/"+url.PathEscape(address), nil, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// 地址没有 UTXO 时返回空列表
+func (b *BlockfrostClient) GetAddressUtxos(address string) ([]AddressUtxo, error) {
+	utxos, err := getAll[AddressUtxo](b, "/addresses/"+url.PathEscape(address)+"/utxos")
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	return utxos, err
+}
+
+func (b *BlockfrostClient) GetAddressTransactions(address string, page uint32, count uint32) ([]AddressTransaction, error) {
+	query := url.Values{
+		"order": {"desc"},
+		"count": {strconv.FormatUint(uint64(min(count, maxPageCount)), 10)},
+		"page":  {strconv.FormatUint(uint64(max(page, 1)), 10)},
+	}
+	var txs []AddressTransaction
+	if err := b.get("/addresses/"+url.PathEscape(address)+"/transactions", query, &txs); err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+func (b *BlockfrostClient) GetProtocolParams() (*ProtocolParams, error) {
+	params := new(ProtocolParams)
+	if err := b.get("/epochs/latest/parameters", nil, params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+func (b *BlockfrostClient) GetTransaction(hash string) (*Transaction, error) {
+	tx := new(Transaction)
+	if err := b.get("/txs/"+url.PathEscape(hash), nil, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (b *BlockfrostClient) GetTransactionUtxos(hash string) (*TxUtxos, error) {
+	utxos := new(TxUtxos)
+	if err := b.get("/txs/"+url.PathEscape(hash)+"/utxos", nil, utxos); err != nil {
+		return nil, err
+	}
+	return utxos, nil
+}
+
+func (b *BlockfrostClient) SubmitTransaction(tx []byte) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, b.url+"/tx/submit", bytes.NewReader(tx))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/cbor")
+	var hash string
+	if err := b.do(req, &hash); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
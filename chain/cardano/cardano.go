@@ -0,0 +1,628 @@
+package cardano
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Cardano"
+
+// Blockfrost 中 ADA 的 unit
+const lovelaceUnit = "lovelace"
+
+// 单次 GetBlockByRange 最多查询的区块数，每个区块需要一次请求
+const blockRangeLimit = 100
+
+// GetTxByAddress 默认每页数量
+const defaultPageSize = 20
+
+type ChainAdaptor struct {
+	BlockfrostClient IBlockfrost
+	Network          byte
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.Ada
+	network := con.NetWork
+	if node.Network != "" {
+		network = node.Network
+	}
+	blockfrostClient, err := NewBlockfrostClient(node.RpcUrl, node.DataApiKey, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	networkId := NetworkMainnet
+	if network == "testnet" {
+		networkId = NetworkTestnet
+	}
+	return &ChainAdaptor{
+		BlockfrostClient: blockfrostClient,
+		Network:          networkId,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入 ed25519 公钥转换成 Shelley 地址：32 字节支付公钥生成 enterprise 地址（addr1v...），
+// 64 字节支付公钥和质押公钥拼接生成 base 地址（addr1q...）
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeEd25519); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	payment, stake, err := ParsePublicKeys(req.PublicKey)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	var stakeHash *KeyHash
+	if stake != nil {
+		hash := HashKey(stake)
+		stakeHash = &hash
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: NewAddress(c.Network, HashKey(payment), stakeHash).String(),
+	}, nil
+}
+
+// 地址格式验证，只支持当前网络的 Shelley 地址
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ValidateAddress(req.Address, c.Network) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 通过高度获取区块，height 为 0 时返回最新区块
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	block, err := c.getBlock(strconv.FormatInt(req.Height, 10))
+	if err != nil {
+		log.Error("get block fail", "height", req.Height, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return c.blockResponse(block, "get block by number")
+}
+
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	block, err := c.BlockfrostClient.GetBlock(req.Hash)
+	if err != nil {
+		log.Error("get block fail", "hash", req.Hash, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return c.blockResponse(block, "get block by hash")
+}
+
+// UTXO 模型每个输出单独列出，跳过脚本验证失败的交易
+func (c *ChainAdaptor) blockResponse(block *Block, action string) (*account.BlockResponse, error) {
+	hashes, err := c.BlockfrostClient.GetBlockTxs(block.Hash)
+	if err != nil {
+		log.Error("get block txs fail", "hash", block.Hash, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  action + " fail",
+		}, nil
+	}
+	var blockTxList []*account.BlockInfoTransactionList
+	for _, hash := range hashes {
+		tx, err := c.BlockfrostClient.GetTransaction(hash)
+		if err == nil && !tx.ValidContract {
+			continue
+		}
+		var utxos *TxUtxos
+		if err == nil {
+			utxos, err = c.BlockfrostClient.GetTransactionUtxos(hash)
+		}
+		if err != nil {
+			log.Error("get transaction fail", "hash", hash, "err", err)
+			return &account.BlockResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  action + " fail",
+			}, nil
+		}
+		for _, transfer := range parseTransfers(utxos, false) {
+			blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+				From:         transfer.From,
+				To:           transfer.To,
+				TokenAddress: transfer.ContractAddress,
+				Hash:         hash,
+				Height:       uint64(blockHeight(block)),
+				Amount:       transfer.Amount,
+			})
+		}
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          action + " success",
+		Height:       blockHeight(block),
+		Hash:         block.Hash,
+		Transactions: blockTxList,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	block, err := c.BlockfrostClient.GetBlock(req.Hash)
+	if err != nil {
+		log.Error("get block fail", "hash", req.Hash, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by hash success",
+		BlockHeader: toBlockHeader(block),
+	}, nil
+}
+
+// 通过高度获取区块头信息，height 为 0 时返回最新区块
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	block, err := c.getBlock(strconv.FormatInt(req.Height, 10))
+	if err != nil {
+		log.Error("get block fail", "height", req.Height, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(block),
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseUint(req.Start, 10, 64)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseUint(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	if end-start >= blockRangeLimit {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "block range too large",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for height := start; height <= end; height++ {
+		block, err := c.BlockfrostClient.GetBlock(strconv.FormatUint(height, 10))
+		if err != nil {
+			log.Error("get block fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		headers = append(headers, toBlockHeader(block))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取地址余额（lovelace），contract_address 为资产 unit 时返回该资产数量，未上链的地址返回 0
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if !ValidateAddress(req.Address, c.Network) {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	unit := lovelaceUnit
+	if req.ContractAddress != "" {
+		policyId, assetName, err := ParseUnit(req.ContractAddress)
+		if err != nil {
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid contract address",
+			}, nil
+		}
+		unit = policyId + assetName
+	}
+	info, err := c.BlockfrostClient.GetAddress(req.Address)
+	if err != nil && !IsNotFound(err) {
+		log.Error("get address fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	balance := "0"
+	if info != nil {
+		for _, amount := range info.Amount {
+			if amount.Unit == unit {
+				balance = amount.Quantity
+				break
+			}
+		}
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Balance:       balance,
+	}, nil
+}
+
+// 获取fee，单位 lovelace。Cardano 手续费由协议参数和交易大小确定，三档相同。
+// 传入 rawTx（BuildUnSignTransaction 返回的 un_sign_tx）时返回该交易的手续费；
+// 否则返回单输入、两个 base 地址输出的转账手续费
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	var fee uint64
+	if req.RawTx != "" {
+		rawBody, _, err := decodeRawTx(req.RawTx)
+		var body *TxBody
+		if err == nil {
+			body, err = DecodeTxBody(rawBody)
+		}
+		if err != nil {
+			log.Error("decode transaction fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		fee = body.Fee
+	} else {
+		protocolParams, err := c.BlockfrostClient.GetProtocolParams()
+		var params FeeParams
+		if err == nil {
+			params, err = toFeeParams(protocolParams)
+		}
+		if err != nil {
+			log.Error("get protocol params fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get protocol params fail",
+			}, nil
+		}
+		fee = typicalFee(params)
+	}
+	value := strconv.FormatUint(fee, 10)
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   value,
+		NormalFee: value,
+		FastFee:   value,
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的 signed_tx
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	rawTx, err := hex.DecodeString(strings.TrimPrefix(req.RawTx, "0x"))
+	var witnesses []VKeyWitness
+	if err == nil {
+		_, witnesses, err = DecodeTransaction(rawTx)
+	}
+	if err != nil || len(witnesses) == 0 {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	hash, err := c.BlockfrostClient.SubmitTransaction(rawTx)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: hash,
+	}, nil
+}
+
+// 按地址分页查询交易，按时间倒序，page 从 1 开始
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	if !ValidateAddress(req.Address, c.Network) {
+		return &account.TxAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	addressTxs, err := c.BlockfrostClient.GetAddressTransactions(req.Address, req.Page, pageSize)
+	if IsNotFound(err) {
+		addressTxs, err = nil, nil
+	}
+	if err != nil {
+		log.Error("get address transactions fail", "address", req.Address, "err", err)
+		return &account.TxAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get tx by address fail",
+		}, nil
+	}
+	var txs []*account.TxMessage
+	for _, item := range addressTxs {
+		txMessage, err := c.getTxMessage(item.TxHash)
+		if err != nil {
+			log.Error("get transaction fail", "hash", item.TxHash, "err", err)
+			return &account.TxAddressResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get tx by address fail",
+			}, nil
+		}
+		txs = append(txs, txMessage)
+	}
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txs,
+	}, nil
+}
+
+// 通过交易哈希获取交易，转账不包含找零到发送方的输出
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	txMessage, err := c.getTxMessage(req.Hash)
+	if IsNotFound(err) {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get transaction fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   txMessage,
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// 查询地址的未花费输出，amount 为 lovelace，原生资产通过 GetAccount 按 unit 查询
+func (c *ChainAdaptor) GetUnspentOutputs(req *account.UnspentOutputsRequest) (*account.UnspentOutputsResponse, error) {
+	if !ValidateAddress(req.Address, c.Network) {
+		return &account.UnspentOutputsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	utxos, err := c.BlockfrostClient.GetAddressUtxos(req.Address)
+	if err != nil {
+		log.Error("get address utxos fail", "address", req.Address, "err", err)
+		return &account.UnspentOutputsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get unspent outputs fail",
+		}, nil
+	}
+	// 同一区块的 UTXO 只查询一次区块
+	blocks := make(map[string]*Block)
+	var unspentOutputs []*account.UnspentOutput
+	for _, utxo := range utxos {
+		block, ok := blocks[utxo.Block]
+		if !ok {
+			if block, err = c.BlockfrostClient.GetBlock(utxo.Block); err != nil {
+				log.Error("get block fail", "hash", utxo.Block, "err", err)
+				return &account.UnspentOutputsResponse{
+					Code: global_const.ReturnCode_ERROR,
+					Msg:  "get unspent outputs fail",
+				}, nil
+			}
+			blocks[utxo.Block] = block
+		}
+		// Blockfrost 的 confirmations 不含区块自身
+		confirmations := block.Confirmations + 1
+		if confirmations < req.MinConfirmations {
+			continue
+		}
+		value, err := toValue(utxo.Amount)
+		if err != nil {
+			continue
+		}
+		unspentOutputs = append(unspentOutputs, &account.UnspentOutput{
+			TxId:          utxo.TxHash,
+			Vout:          utxo.OutputIndex,
+			Amount:        strconv.FormatUint(value.Coin, 10),
+			Address:       req.Address,
+			Height:        uint64(blockHeight(block)),
+			Confirmations: confirmations,
+		})
+	}
+	return &account.UnspentOutputsResponse{
+		Code:           global_const.ReturnCode_SUCCESS,
+		Msg:            "get unspent outputs success",
+		UnspentOutputs: unspentOutputs,
+	}, nil
+}
+
+// height 为 0 时获取最新区块
+func (c *ChainAdaptor) getBlock(height string) (*Block, error) {
+	if height == "0" {
+		return c.BlockfrostClient.GetLatestBlock()
+	}
+	return c.BlockfrostClient.GetBlock(height)
+}
+
+func (c *ChainAdaptor) getTxMessage(hash string) (*account.TxMessage, error) {
+	tx, err := c.BlockfrostClient.GetTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err := c.BlockfrostClient.GetTransactionUtxos(hash)
+	if err != nil {
+		return nil, err
+	}
+	txMessage := &account.TxMessage{
+		Hash:     tx.Hash,
+		Fee:      tx.Fees,
+		Status:   account.TxStatus_Success,
+		Height:   strconv.FormatInt(tx.BlockHeight, 10),
+		Datetime: strconv.FormatInt(tx.BlockTime, 10),
+	}
+	if !tx.ValidContract {
+		txMessage.Status = account.TxStatus_Failed
+		return txMessage, nil
+	}
+	transfers := parseTransfers(utxos, true)
+	if len(transfers) > 0 {
+		transfer := transfers[0]
+		txMessage.From = transfer.From
+		txMessage.To = transfer.To
+		txMessage.Value = transfer.Amount
+		txMessage.ContractAddress = transfer.ContractAddress
+		if transfer.ContractAddress != "" {
+			txMessage.Type = 1
+		}
+		data, _ := json.Marshal(transfers)
+		txMessage.Data = string(data)
+	}
+	return txMessage, nil
+}
+
+// 按输出列出 ADA 和原生资产转账，from 为第一个非抵押输入的地址，跳过抵押和引用输入输出。
+// skipChange 为 true 时跳过找零到 from 的输出，全部输出都是找零时（自转账）保留
+func parseTransfers(utxos *TxUtxos, skipChange bool) []Transfer {
+	var from string
+	for _, input := range utxos.Inputs {
+		if !input.Collateral && !input.Reference {
+			from = input.Address
+			break
+		}
+	}
+	var transfers, changes []Transfer
+	for _, output := range utxos.Outputs {
+		if output.Collateral {
+			continue
+		}
+		for _, amount := range output.Amount {
+			transfer := Transfer{From: from, To: output.Address, Amount: amount.Quantity}
+			if amount.Unit != lovelaceUnit {
+				transfer.ContractAddress = amount.Unit
+			}
+			if skipChange && output.Address == from {
+				changes = append(changes, transfer)
+				continue
+			}
+			transfers = append(transfers, transfer)
+		}
+	}
+	if len(transfers) == 0 {
+		return changes
+	}
+	return transfers
+}
+
+// 单输入、两个 base 地址输出（收款和找零）的转账手续费
+func typicalFee(params FeeParams) uint64 {
+	address := NewAddress(NetworkMainnet, KeyHash{}, &KeyHash{})
+	body := &TxBody{
+		Inputs: []TxInput{{}},
+		Outputs: []TxOutput{
+			{Address: address, Value: Value{Coin: feePlaceholder}},
+			{Address: address, Value: Value{Coin: feePlaceholder}},
+		},
+		Ttl: feePlaceholder,
+	}
+	s := &coinSelector{params: params}
+	fee, _ := s.estimateFee(body)
+	return fee
+}
+
+// 创世区块和 EBB 没有高度，按 0 处理
+func blockHeight(block *Block) int64 {
+	if block.Height == nil {
+		return 0
+	}
+	return *block.Height
+}
+
+func toBlockHeader(block *Block) *account.BlockHeader {
+	return &account.BlockHeader{
+		Hash:       block.Hash,
+		ParentHash: block.PreviousBlock,
+		Number:     strconv.FormatInt(blockHeight(block), 10),
+		Time:       uint64(block.Time),
+	}
+}
@@ -0,0 +1,422 @@
+package cardano
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// CIP-19 的测试公钥
+const (
+	testPaymentKey = "73fea80d424276ad0978d4fe5310e8bc2d485f5f6bb3bf87612989f112ad5a7d"
+	testStakeKey   = "09ab278d49b7b86a055185c474c4942281ddfa05a54684c7e8a6f230625aee57"
+)
+
+// 主网参数
+var testParams = FeeParams{MinFeeA: 44, MinFeeB: 155381, CoinsPerUtxoByte: 4310}
+
+const testPolicyId = "a0028f350aaabe0545fdcb56b039bfb08e4bb4d8c4d7c3c7d481c235"
+
+// 模拟 Blockfrost，utxos 为发送方的 UTXO
+type fakeBlockfrost struct {
+	IBlockfrost
+	utxos     []AddressUtxo
+	txs       map[string]*Transaction
+	txUtxos   map[string]*TxUtxos
+	submitted []byte
+}
+
+func (f *fakeBlockfrost) GetLatestBlock() (*Block, error) {
+	height, slot := int64(10000000), uint64(120000000)
+	return &Block{Hash: "latest", Height: &height, Slot: &slot}, nil
+}
+
+func (f *fakeBlockfrost) GetBlock(hashOrNumber string) (*Block, error) {
+	height := int64(100)
+	return &Block{Hash: "b" + hashOrNumber, Height: &height, PreviousBlock: "parent", Time: 1700000000, Confirmations: 5}, nil
+}
+
+func (f *fakeBlockfrost) GetBlockTxs(hashOrNumber string) ([]string, error) {
+	var hashes []string
+	for hash := range f.txs {
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+func (f *fakeBlockfrost) GetAddress(address string) (*AddressInfo, error) {
+	if len(f.utxos) == 0 {
+		return nil, &ApiError{StatusCode: http.StatusNotFound, ErrorName: "Not Found"}
+	}
+	return &AddressInfo{Address: address, Amount: []Amount{
+		{Unit: lovelaceUnit, Quantity: "5000000"},
+		{Unit: testPolicyId + "4d494e", Quantity: "42"},
+	}}, nil
+}
+
+func (f *fakeBlockfrost) GetAddressUtxos(address string) ([]AddressUtxo, error) {
+	return f.utxos, nil
+}
+
+func (f *fakeBlockfrost) GetProtocolParams() (*ProtocolParams, error) {
+	return &ProtocolParams{MinFeeA: testParams.MinFeeA, MinFeeB: testParams.MinFeeB, MaxTxSize: 16384, CoinsPerUtxoSize: "4310"}, nil
+}
+
+func (f *fakeBlockfrost) GetTransaction(hash string) (*Transaction, error) {
+	tx, ok := f.txs[hash]
+	if !ok {
+		return nil, &ApiError{StatusCode: http.StatusNotFound, ErrorName: "Not Found"}
+	}
+	return tx, nil
+}
+
+func (f *fakeBlockfrost) GetTransactionUtxos(hash string) (*TxUtxos, error) {
+	return f.txUtxos[hash], nil
+}
+
+func (f *fakeBlockfrost) SubmitTransaction(tx []byte) (string, error) {
+	f.submitted = tx
+	body, _, err := DecodeTransaction(tx)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(BodyHash(body)), nil
+}
+
+func testAddress(t *testing.T, network byte, seed byte) Address {
+	key := ed25519.NewKeyFromSeed(make([]byte, 32))
+	if seed != 0 {
+		key = ed25519.NewKeyFromSeed(append(make([]byte, 31), seed))
+	}
+	return NewAddress(network, HashKey(key.Public().(ed25519.PublicKey)), nil)
+}
+
+func txHash(b byte) string {
+	return strings.Repeat(hex.EncodeToString([]byte{b}), 32)
+}
+
+func sumOutputs(body *TxBody) uint64 {
+	var total uint64
+	for _, output := range body.Outputs {
+		total += output.Value.Coin
+	}
+	return total
+}
+
+func Test_Address(t *testing.T) {
+	adaptor := &ChainAdaptor{Network: NetworkMainnet}
+	tests := []struct {
+		network   byte
+		publicKey string
+		address   string
+	}{
+		{NetworkMainnet, testPaymentKey, "addr1vx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzers66hrl8"},
+		{NetworkTestnet, testPaymentKey, "addr_test1vz2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzerspjrlsz"},
+		{NetworkMainnet, testPaymentKey + testStakeKey, "addr1qx2fxv2umyhttkxyxp8x0dlpdt3k6cwng5pxj3jhsydzer3n0d3vllmyqwsx5wktcd8cc3sq835lu7drv2xwl2wywfgse35a3x"},
+	}
+	for _, tt := range tests {
+		adaptor.Network = tt.network
+		resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: tt.publicKey, KeyType: chain.KeyTypeEd25519})
+		if resp.Code != global_const.ReturnCode_SUCCESS || resp.Address != tt.address {
+			t.Fatalf("expected %s, got %s (%s)", tt.address, resp.Address, resp.Msg)
+		}
+		if !ValidateAddress(tt.address, tt.network) {
+			t.Fatalf("expected %s to be valid", tt.address)
+		}
+		if ValidateAddress(tt.address, tt.network^1) {
+			t.Fatalf("expected %s to be invalid on the other network", tt.address)
+		}
+	}
+	if resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPaymentKey, KeyType: chain.KeyTypeSecp256k1}); resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected secp256k1 to be rejected")
+	}
+	// Byron 地址
+	if ValidateAddress("Ae2tdPwUPEZFRbyhz3cpfC2CumGzNkFBN2L42rcUc2yjQpEkxDbkPodpMAi", NetworkMainnet) {
+		t.Fatal("expected byron address to be rejected")
+	}
+}
+
+func Test_MinAda(t *testing.T) {
+	base := NewAddress(NetworkMainnet, KeyHash{}, &KeyHash{})
+	// (160 + 65) * 4310
+	minAda, err := testParams.MinAda(TxOutput{Address: base, Value: Value{Coin: 1}})
+	if err != nil || minAda != 969750 {
+		t.Fatalf("unexpected min ada %d: %v", minAda, err)
+	}
+	assets := make(MultiAsset)
+	assets.Add(testPolicyId, "4d494e", 1000)
+	withAsset, err := testParams.MinAda(TxOutput{Address: base, Value: Value{Assets: assets}})
+	if err != nil || withAsset <= minAda {
+		t.Fatalf("expected min ada with asset above %d, got %d", minAda, withAsset)
+	}
+}
+
+func Test_CborRoundTrip(t *testing.T) {
+	assets := make(MultiAsset)
+	assets.Add(testPolicyId, "", 7)
+	assets.Add(testPolicyId, "4d494e", 1<<40)
+	body := &TxBody{
+		Inputs:  []TxInput{{Index: 3}},
+		Outputs: []TxOutput{{Address: testAddress(t, NetworkMainnet, 1), Value: Value{Coin: 2000000, Assets: assets}}},
+		Fee:     170000,
+		Ttl:     120007200,
+	}
+	raw, err := body.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeTxBody(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := decoded.MarshalCBOR()
+	if hex.EncodeToString(raw) != hex.EncodeToString(again) {
+		t.Fatal("body changed after round trip")
+	}
+	if decoded.Outputs[0].Value.Assets[testPolicyId]["4d494e"] != 1<<40 {
+		t.Fatal("asset quantity mismatch")
+	}
+
+	witness := VKeyWitness{VKey: make([]byte, 32), Signature: make([]byte, 64)}
+	tx := EncodeTransaction(raw, []VKeyWitness{witness})
+	decodedBody, witnesses, err := DecodeTransaction(tx)
+	if err != nil || hex.EncodeToString(decodedBody) != hex.EncodeToString(raw) || len(witnesses) != 1 {
+		t.Fatalf("decode transaction fail: %v", err)
+	}
+	if _, _, err := DecodeTransaction(tx[:len(tx)-1]); err == nil {
+		t.Fatal("expected truncated transaction to fail")
+	}
+}
+
+func Test_SelectCoins(t *testing.T) {
+	from := testAddress(t, NetworkMainnet, 1)
+	to := testAddress(t, NetworkMainnet, 2)
+	utxos := []Utxo{
+		{Input: TxInput{TxHash: [32]byte{1}}, Value: Value{Coin: 3000000}},
+		{Input: TxInput{TxHash: [32]byte{2}}, Value: Value{Coin: 10000000}},
+	}
+
+	body, err := SelectCoins(utxos, []TxOutput{{Address: to, Value: Value{Coin: 5000000}}}, from, testParams, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Inputs) != 1 || body.Inputs[0].TxHash != [32]byte{2} || len(body.Outputs) != 2 {
+		t.Fatalf("unexpected selection: %+v", body)
+	}
+	if body.Fee+sumOutputs(body) != 10000000 {
+		t.Fatal("inputs and outputs not balanced")
+	}
+	raw, _ := body.MarshalCBOR()
+	if size := len(EncodeTransaction(raw, []VKeyWitness{dummyWitness})); body.Fee < testParams.MinFee(size) {
+		t.Fatalf("fee %d below min fee %d", body.Fee, testParams.MinFee(size))
+	}
+
+	// 找零不足最小 ADA 时并入手续费
+	body, err = SelectCoins(utxos[:1], []TxOutput{{Address: to, Value: Value{Coin: 2500000}}}, from, testParams, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Outputs) != 1 || body.Fee != 500000 {
+		t.Fatalf("expected change folded into fee, got %+v", body)
+	}
+
+	if _, err := SelectCoins(utxos, []TxOutput{{Address: to, Value: Value{Coin: 13000000}}}, from, testParams, 100); err != ErrInsufficientFunds {
+		t.Fatalf("expected insufficient funds, got %v", err)
+	}
+	if _, err := SelectCoins(utxos, []TxOutput{{Address: to, Value: Value{Coin: 100}}}, from, testParams, 100); err == nil {
+		t.Fatal("expected output below min ada to fail")
+	}
+}
+
+func Test_SelectCoinsAsset(t *testing.T) {
+	from := testAddress(t, NetworkMainnet, 1)
+	to := testAddress(t, NetworkMainnet, 2)
+	held := make(MultiAsset)
+	held.Add(testPolicyId, "4d494e", 100)
+	held.Add(testPolicyId, "", 1)
+	utxos := []Utxo{
+		{Input: TxInput{TxHash: [32]byte{1}}, Value: Value{Coin: 1500000, Assets: held}},
+		{Input: TxInput{TxHash: [32]byte{2}}, Value: Value{Coin: 5000000}},
+	}
+	send := make(MultiAsset)
+	send.Add(testPolicyId, "4d494e", 40)
+	body, err := SelectCoins(utxos, []TxOutput{{Address: to, Value: Value{Assets: send}}}, from, testParams, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Inputs) != 2 || len(body.Outputs) != 2 {
+		t.Fatalf("unexpected selection: %+v", body)
+	}
+	minAda, _ := testParams.MinAda(body.Outputs[0])
+	if body.Outputs[0].Value.Coin != minAda {
+		t.Fatalf("expected output to carry min ada %d, got %d", minAda, body.Outputs[0].Value.Coin)
+	}
+	change := body.Outputs[1].Value.Assets
+	if change[testPolicyId]["4d494e"] != 60 || change[testPolicyId][""] != 1 {
+		t.Fatalf("unexpected change assets: %v", change)
+	}
+	if body.Fee+sumOutputs(body) != 6500000 {
+		t.Fatal("inputs and outputs not balanced")
+	}
+
+	send.Add(testPolicyId, "4d494e", 100)
+	if _, err := SelectCoins(utxos, []TxOutput{{Address: to, Value: Value{Assets: send}}}, from, testParams, 100); err != ErrInsufficientFunds {
+		t.Fatalf("expected insufficient funds, got %v", err)
+	}
+}
+
+func Test_BuildTransaction(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(make([]byte, 32))
+	pubKey := key.Public().(ed25519.PublicKey)
+	from := NewAddress(NetworkTestnet, HashKey(pubKey), nil)
+	to := testAddress(t, NetworkTestnet, 2)
+	datum := "d87980"
+	client := &fakeBlockfrost{utxos: []AddressUtxo{
+		{TxHash: txHash(1), OutputIndex: 0, Amount: []Amount{{Unit: lovelaceUnit, Quantity: "8000000"}}},
+		// 带 datum 的输出不参与选币
+		{TxHash: txHash(2), OutputIndex: 1, Amount: []Amount{{Unit: lovelaceUnit, Quantity: "90000000"}}, InlineDatum: &datum},
+	}}
+	adaptor := &ChainAdaptor{BlockfrostClient: client, Network: NetworkTestnet}
+
+	txJson, _ := json.Marshal(CardanoTransferTx{
+		FromAddress: from.String(),
+		Outputs:     []TransferOutput{{Address: to.String(), Amount: "3000000"}},
+	})
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	rawBody, witnesses, err := decodeRawTx(resp.UnSignTx)
+	if err != nil || len(witnesses) != 0 {
+		t.Fatalf("decode unsigned tx fail: %v", err)
+	}
+	body, _ := DecodeTxBody(rawBody)
+	if len(body.Inputs) != 1 || body.Ttl != 120000000+ttlOffset || body.Fee+sumOutputs(body) != 8000000 {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+	if resp.SignHashes[0] != hex.EncodeToString(BodyHash(rawBody)) {
+		t.Fatal("sign hash mismatch")
+	}
+
+	feeResp, _ := adaptor.GetFee(&account.FeeRequest{RawTx: resp.UnSignTx})
+	if feeResp.NormalFee == "" || feeResp.NormalFee == "0" {
+		t.Fatalf("unexpected fee: %s", feeResp.NormalFee)
+	}
+
+	hash, _ := hex.DecodeString(resp.SignHashes[0])
+	signature := ed25519.Sign(key, hash)
+	bad, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  resp.UnSignTx,
+		PublicKey: testPaymentKey,
+		Signature: hex.EncodeToString(signature),
+	})
+	if bad.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected signature from another key to fail")
+	}
+	signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  resp.UnSignTx,
+		PublicKey: hex.EncodeToString(pubKey),
+		Signature: hex.EncodeToString(signature),
+	})
+	if signed.Code != global_const.ReturnCode_SUCCESS || signed.Msg != resp.SignHashes[0] {
+		t.Fatalf("build signed transaction fail: %s", signed.Msg)
+	}
+	signedBytes, _ := hex.DecodeString(signed.SignedTx)
+	if fee := testParams.MinFee(len(signedBytes)); body.Fee < fee {
+		t.Fatalf("fee %d below min fee %d", body.Fee, fee)
+	}
+
+	if sendResp, _ := adaptor.SendTx(&account.SendTxRequest{RawTx: resp.UnSignTx}); sendResp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected unsigned tx to be rejected")
+	}
+	sendResp, _ := adaptor.SendTx(&account.SendTxRequest{RawTx: signed.SignedTx})
+	if sendResp.Code != global_const.ReturnCode_SUCCESS || sendResp.TxHash != signed.Msg {
+		t.Fatalf("send tx fail: %s", sendResp.Msg)
+	}
+}
+
+func Test_GetAccount(t *testing.T) {
+	address := testAddress(t, NetworkMainnet, 1).String()
+	client := &fakeBlockfrost{}
+	adaptor := &ChainAdaptor{BlockfrostClient: client, Network: NetworkMainnet}
+	resp, _ := adaptor.GetAccount(&account.AccountRequest{Address: address})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Balance != "0" {
+		t.Fatalf("expected empty balance for unused address, got %s (%s)", resp.Balance, resp.Msg)
+	}
+
+	client.utxos = []AddressUtxo{{}}
+	resp, _ = adaptor.GetAccount(&account.AccountRequest{Address: address})
+	if resp.Balance != "5000000" {
+		t.Fatalf("unexpected balance %s", resp.Balance)
+	}
+	resp, _ = adaptor.GetAccount(&account.AccountRequest{Address: address, ContractAddress: testPolicyId + "4d494e"})
+	if resp.Balance != "42" {
+		t.Fatalf("unexpected asset balance %s", resp.Balance)
+	}
+}
+
+func Test_GetBlockByNumber(t *testing.T) {
+	client := &fakeBlockfrost{
+		txs: map[string]*Transaction{
+			"ok":      {Hash: "ok", ValidContract: true},
+			"invalid": {Hash: "invalid", ValidContract: false},
+		},
+		txUtxos: map[string]*TxUtxos{
+			"ok": {
+				Inputs: []TxUtxo{{Address: "collateral", Collateral: true}, {Address: "sender"}},
+				Outputs: []TxUtxo{
+					{Address: "receiver", Amount: []Amount{{Unit: lovelaceUnit, Quantity: "1500000"}, {Unit: testPolicyId, Quantity: "1"}}},
+					{Address: "sender", Amount: []Amount{{Unit: lovelaceUnit, Quantity: "700000"}}},
+					{Address: "collateral", Amount: []Amount{{Unit: lovelaceUnit, Quantity: "5000000"}}, Collateral: true},
+				},
+			},
+		},
+	}
+	adaptor := &ChainAdaptor{BlockfrostClient: client, Network: NetworkMainnet}
+	resp, _ := adaptor.GetBlockByNumber(&account.BlockNumberRequest{Height: 100})
+	if resp.Code != global_const.ReturnCode_SUCCESS || len(resp.Transactions) != 3 {
+		t.Fatalf("unexpected block transactions: %+v", resp.Transactions)
+	}
+	for _, tx := range resp.Transactions {
+		if tx.Hash != "ok" || tx.From != "sender" || tx.To == "collateral" {
+			t.Fatalf("unexpected transaction: %+v", tx)
+		}
+	}
+	if resp.Transactions[1].TokenAddress != testPolicyId {
+		t.Fatalf("expected native asset transfer, got %+v", resp.Transactions[1])
+	}
+
+	txResp, _ := adaptor.GetTxByHash(&account.TxHashRequest{Hash: "ok"})
+	if txResp.Tx.To != "receiver" || txResp.Tx.Value != "1500000" || strings.Contains(txResp.Tx.Data, `"to":"sender"`) {
+		t.Fatalf("unexpected transaction: %+v", txResp.Tx)
+	}
+	txResp, _ = adaptor.GetTxByHash(&account.TxHashRequest{Hash: "invalid"})
+	if txResp.Tx.Status != account.TxStatus_Failed {
+		t.Fatal("expected invalid transaction to be failed")
+	}
+	txResp, _ = adaptor.GetTxByHash(&account.TxHashRequest{Hash: "missing"})
+	if txResp.Code != global_const.ReturnCode_SUCCESS || txResp.Tx.Status != account.TxStatus_NotFound {
+		t.Fatal("expected transaction not found")
+	}
+}
+
+func Test_GetFee(t *testing.T) {
+	adaptor := &ChainAdaptor{BlockfrostClient: &fakeBlockfrost{}, Network: NetworkMainnet}
+	resp, _ := adaptor.GetFee(&account.FeeRequest{})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.SlowFee != resp.FastFee {
+		t.Fatalf("unexpected fee response: %+v", resp)
+	}
+	// 普通转账约 0.17 ADA
+	if fee, _ := strconv.ParseUint(resp.NormalFee, 10, 64); fee < 160000 || fee > 180000 {
+		t.Fatalf("unexpected typical fee %s", resp.NormalFee)
+	}
+}
@@ -0,0 +1,218 @@
+package cardano
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// CBOR 主类型
+const (
+	cborUint   byte = 0
+	cborBytes  byte = 2
+	cborText   byte = 3
+	cborArray  byte = 4
+	cborMap    byte = 5
+	cborTag    byte = 6
+	cborSimple byte = 7
+)
+
+// 集合类型的标签，Conway 之后的交易输入和见证可能带有该标签
+const cborTagSet = 258
+
+const (
+	cborFalse = 20
+	cborTrue  = 21
+	cborNull  = 22
+)
+
+var errCborTruncated = errors.New("cbor: unexpected end of data")
+
+// 按最短编码写入定长的 CBOR 数据，不支持不定长编码
+type cborWriter struct {
+	buf []byte
+}
+
+func (w *cborWriter) head(major byte, n uint64) {
+	major <<= 5
+	switch {
+	case n < 24:
+		w.buf = append(w.buf, major|byte(n))
+	case n <= 0xff:
+		w.buf = append(w.buf, major|24, byte(n))
+	case n <= 0xffff:
+		w.buf = binary.BigEndian.AppendUint16(append(w.buf, major|25), uint16(n))
+	case n <= 0xffffffff:
+		w.buf = binary.BigEndian.AppendUint32(append(w.buf, major|26), uint32(n))
+	default:
+		w.buf = binary.BigEndian.AppendUint64(append(w.buf, major|27), n)
+	}
+}
+
+func (w *cborWriter) uint(n uint64) {
+	w.head(cborUint, n)
+}
+
+func (w *cborWriter) bytes(b []byte) {
+	w.head(cborBytes, uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *cborWriter) array(n int) {
+	w.head(cborArray, uint64(n))
+}
+
+func (w *cborWriter) mapHeader(n int) {
+	w.head(cborMap, uint64(n))
+}
+
+func (w *cborWriter) bool(v bool) {
+	if v {
+		w.head(cborSimple, cborTrue)
+	} else {
+		w.head(cborSimple, cborFalse)
+	}
+}
+
+func (w *cborWriter) null() {
+	w.head(cborSimple, cborNull)
+}
+
+// 写入已编码的数据项
+func (w *cborWriter) raw(b []byte) {
+	w.buf = append(w.buf, b...)
+}
+
+// 顺序读取 CBOR 数据，出错后后续读取均返回零值，由调用方最后检查 err
+type cborReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *cborReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *cborReader) next(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.data)-r.pos) < n {
+		r.fail(errCborTruncated)
+		return nil
+	}
+	b := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b
+}
+
+func (r *cborReader) peekMajor() byte {
+	if r.err != nil || r.pos >= len(r.data) {
+		r.fail(errCborTruncated)
+		return 0xff
+	}
+	return r.data[r.pos] >> 5
+}
+
+func (r *cborReader) head() (byte, uint64) {
+	b := r.next(1)
+	if b == nil {
+		return 0xff, 0
+	}
+	major, info := b[0]>>5, b[0]&0x1f
+	switch {
+	case info < 24:
+		return major, uint64(info)
+	case info == 24:
+		if v := r.next(1); v != nil {
+			return major, uint64(v[0])
+		}
+	case info == 25:
+		if v := r.next(2); v != nil {
+			return major, uint64(binary.BigEndian.Uint16(v))
+		}
+	case info == 26:
+		if v := r.next(4); v != nil {
+			return major, uint64(binary.BigEndian.Uint32(v))
+		}
+	case info == 27:
+		if v := r.next(8); v != nil {
+			return major, binary.BigEndian.Uint64(v)
+		}
+	default:
+		r.fail(fmt.Errorf("cbor: unsupported additional info %d", info))
+	}
+	return 0xff, 0
+}
+
+func (r *cborReader) expect(expected byte) uint64 {
+	major, arg := r.head()
+	if r.err == nil && major != expected {
+		r.fail(fmt.Errorf("cbor: expected major type %d, got %d", expected, major))
+	}
+	return arg
+}
+
+func (r *cborReader) uint() uint64 {
+	return r.expect(cborUint)
+}
+
+func (r *cborReader) bytes() []byte {
+	return r.next(r.expect(cborBytes))
+}
+
+func (r *cborReader) mapLen() int {
+	return r.length(r.expect(cborMap))
+}
+
+// 数组长度，跳过集合标签
+func (r *cborReader) arrayLen() int {
+	if r.peekMajor() == cborTag {
+		if tag := r.expect(cborTag); tag != cborTagSet {
+			r.fail(fmt.Errorf("cbor: unexpected tag %d", tag))
+		}
+	}
+	return r.length(r.expect(cborArray))
+}
+
+// 防止恶意的长度导致预分配过多内存
+func (r *cborReader) length(n uint64) int {
+	if n > uint64(len(r.data)-r.pos) {
+		r.fail(errCborTruncated)
+		return 0
+	}
+	return int(n)
+}
+
+// 跳过一个完整的数据项
+func (r *cborReader) skip() {
+	major, arg := r.head()
+	switch major {
+	case cborBytes, cborText:
+		r.next(arg)
+	case cborArray:
+		for i := r.length(arg); i > 0 && r.err == nil; i-- {
+			r.skip()
+		}
+	case cborMap:
+		for i := r.length(arg); i > 0 && r.err == nil; i-- {
+			r.skip()
+			r.skip()
+		}
+	case cborTag:
+		r.skip()
+	}
+}
+
+// 跳过一个数据项并返回其原始编码
+func (r *cborReader) raw() []byte {
+	start := r.pos
+	r.skip()
+	if r.err != nil {
+		return nil
+	}
+	return r.data[start:r.pos]
+}
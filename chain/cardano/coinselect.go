@@ -0,0 +1,199 @@
+package cardano
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// 最小 ADA 计算中每个输出额外计入的字节数（Babbage 之后的规则）
+const utxoEntryOverhead = 160
+
+// 估算手续费时使用的单个 vkey 见证
+var dummyWitness = VKeyWitness{VKey: make([]byte, 32), Signature: make([]byte, 64)}
+
+// 估算交易大小时的手续费占位，按 5 字节编码，可覆盖 4294 ADA 以内的手续费
+const feePlaceholder = 0xffffffff
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// 协议参数中与手续费、最小 ADA 相关的部分
+type FeeParams struct {
+	MinFeeA          uint64 // 每字节手续费
+	MinFeeB          uint64 // 固定手续费
+	CoinsPerUtxoByte uint64
+}
+
+func (p FeeParams) MinFee(txSize int) uint64 {
+	return p.MinFeeA*uint64(txSize) + p.MinFeeB
+}
+
+// 输出需要的最小 lovelace：(160 + 输出编码长度) * coinsPerUTxOByte。
+// coin 自身的编码长度会影响结果，按当前 coin 和结果中的较大者迭代到稳定
+func (p FeeParams) MinAda(output TxOutput) (uint64, error) {
+	output.Value.Coin = max(output.Value.Coin, 1)
+	for {
+		size, err := output.size()
+		if err != nil {
+			return 0, err
+		}
+		minAda := (utxoEntryOverhead + uint64(size)) * p.CoinsPerUtxoByte
+		if minAda <= output.Value.Coin {
+			return minAda, nil
+		}
+		output.Value.Coin = minAda
+	}
+}
+
+// 可花费的 UTXO
+type Utxo struct {
+	Input TxInput
+	Value Value
+}
+
+// 选币：比特币的选币按脚本权重和粉尘阈值计算，不适用于带原生资产和最小 ADA 的输出，这里单独实现。
+// 先按数量从大到小选择包含所需资产的 UTXO，再按 lovelace 从大到小补足金额和手续费；
+// 剩余的资产和 lovelace 找零到 changeAddress，无资产且不足最小 ADA 的找零并入手续费。
+// 只估算一个签名见证，要求全部 UTXO 属于同一支付公钥
+func SelectCoins(utxos []Utxo, outputs []TxOutput, changeAddress Address, params FeeParams, ttl uint64) (*TxBody, error) {
+	if len(outputs) == 0 {
+		return nil, errors.New("no outputs")
+	}
+	outputs = append([]TxOutput(nil), outputs...)
+	outputTotal := Value{Assets: make(MultiAsset)}
+	for i, output := range outputs {
+		minAda, err := params.MinAda(output)
+		if err != nil {
+			return nil, err
+		}
+		if output.Value.Coin < minAda {
+			if len(output.Value.Assets) == 0 {
+				return nil, fmt.Errorf("output amount %d is below min ada %d", output.Value.Coin, minAda)
+			}
+			// 只转原生资产时自动附带最小 ADA
+			outputs[i].Value.Coin = minAda
+		}
+		outputTotal.Coin += outputs[i].Value.Coin
+		for policyId, assets := range output.Value.Assets {
+			for assetName, quantity := range assets {
+				outputTotal.Assets.Add(policyId, assetName, quantity)
+			}
+		}
+	}
+
+	s := &coinSelector{outputs: outputs, outputTotal: outputTotal, changeAddress: changeAddress, params: params, ttl: ttl}
+	remaining := append([]Utxo(nil), utxos...)
+	var selected []Utxo
+	for policyId, assets := range outputTotal.Assets {
+		for assetName, quantity := range assets {
+			var covered uint64
+			for _, utxo := range selected {
+				covered += utxo.Value.Assets[policyId][assetName]
+			}
+			sort.SliceStable(remaining, func(i, j int) bool {
+				return remaining[i].Value.Assets[policyId][assetName] > remaining[j].Value.Assets[policyId][assetName]
+			})
+			for covered < quantity && len(remaining) > 0 && remaining[0].Value.Assets[policyId][assetName] > 0 {
+				covered += remaining[0].Value.Assets[policyId][assetName]
+				selected = append(selected, remaining[0])
+				remaining = remaining[1:]
+			}
+			if covered < quantity {
+				return nil, ErrInsufficientFunds
+			}
+		}
+	}
+
+	sort.SliceStable(remaining, func(i, j int) bool {
+		return remaining[i].Value.Coin > remaining[j].Value.Coin
+	})
+	if len(selected) > 0 {
+		if body, err := s.finish(selected); !errors.Is(err, ErrInsufficientFunds) {
+			return body, err
+		}
+	}
+	for _, utxo := range remaining {
+		selected = append(selected, utxo)
+		if body, err := s.finish(selected); !errors.Is(err, ErrInsufficientFunds) {
+			return body, err
+		}
+	}
+	return nil, ErrInsufficientFunds
+}
+
+type coinSelector struct {
+	outputs       []TxOutput
+	outputTotal   Value
+	changeAddress Address
+	params        FeeParams
+	ttl           uint64
+}
+
+// 按占位手续费和最大找零估算交易大小，得到的手续费不低于最终交易所需
+func (s *coinSelector) estimateFee(body *TxBody) (uint64, error) {
+	body.Fee = feePlaceholder
+	raw, err := body.MarshalCBOR()
+	if err != nil {
+		return 0, err
+	}
+	return s.params.MinFee(len(EncodeTransaction(raw, []VKeyWitness{dummyWitness}))), nil
+}
+
+// 计算手续费和找零
+func (s *coinSelector) finish(selected []Utxo) (*TxBody, error) {
+	inputTotal := Value{Assets: make(MultiAsset)}
+	inputs := make([]TxInput, 0, len(selected))
+	for _, utxo := range selected {
+		inputs = append(inputs, utxo.Input)
+		inputTotal.Coin += utxo.Value.Coin
+		for policyId, assets := range utxo.Value.Assets {
+			for assetName, quantity := range assets {
+				inputTotal.Assets.Add(policyId, assetName, quantity)
+			}
+		}
+	}
+	sort.Slice(inputs, func(i, j int) bool {
+		if c := bytes.Compare(inputs[i].TxHash[:], inputs[j].TxHash[:]); c != 0 {
+			return c < 0
+		}
+		return inputs[i].Index < inputs[j].Index
+	})
+	changeAssets := inputTotal.Assets.Clone()
+	if !changeAssets.Sub(s.outputTotal.Assets) || inputTotal.Coin < s.outputTotal.Coin {
+		return nil, ErrInsufficientFunds
+	}
+	surplus := inputTotal.Coin - s.outputTotal.Coin
+
+	change := TxOutput{Address: s.changeAddress, Value: Value{Coin: surplus, Assets: changeAssets}}
+	body := &TxBody{Inputs: inputs, Outputs: append(append([]TxOutput(nil), s.outputs...), change), Ttl: s.ttl}
+	fee, err := s.estimateFee(body)
+	if err != nil {
+		return nil, err
+	}
+	if surplus >= fee {
+		change.Value.Coin = surplus - fee
+		minAda, err := s.params.MinAda(change)
+		if err != nil {
+			return nil, err
+		}
+		if change.Value.Coin >= minAda {
+			body.Outputs[len(body.Outputs)-1] = change
+			body.Fee = fee
+			return body, nil
+		}
+	}
+	if len(changeAssets) > 0 {
+		return nil, ErrInsufficientFunds
+	}
+
+	body.Outputs = body.Outputs[:len(body.Outputs)-1]
+	if fee, err = s.estimateFee(body); err != nil {
+		return nil, err
+	}
+	if surplus < fee {
+		return nil, ErrInsufficientFunds
+	}
+	body.Fee = surplus
+	return body, nil
+}
@@ -0,0 +1,271 @@
+package cardano
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 未指定 ttl 时交易的有效 slot 数（约 2 小时）
+const ttlOffset = 7200
+
+// policy id 为 28 字节，资产名最多 32 字节
+const policyIdHexLength = 2 * keyHashLength
+
+// 构建未签名交易：un_sign_tx 为 hex 编码的交易（见证集为空），sign_hashes 为交易体哈希（ed25519 对哈希签名）
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx CardanoTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	body, err := c.buildTxBody(&transferTx)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   hex.EncodeToString(EncodeTransaction(body, nil)),
+		SignHashes: []string{hex.EncodeToString(BodyHash(body))},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 un_sign_tx，public_key 为 32 字节 ed25519 支付公钥，
+// signature 为对交易体哈希的签名。输入来自多个支付公钥时，将返回的 signed_tx 作为 base64_tx 依次添加签名。
+// 返回的 signed_tx 为 hex 编码的交易，msg 为交易哈希
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	body, witnesses, err := decodeRawTx(req.Base64Tx)
+	if err != nil {
+		log.Error("decode transaction fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	pubKey, _, err := ParsePublicKeys(req.PublicKey)
+	signature, sigErr := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || sigErr != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	witness, err := NewVKeyWitness(pubKey, body, signature)
+	if err != nil {
+		log.Error("verify signature fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "signature verification failed",
+		}, nil
+	}
+	for _, existing := range witnesses {
+		if bytes.Equal(existing.VKey, witness.VKey) {
+			return &account.SignedTransactionResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "duplicate signature",
+			}, nil
+		}
+	}
+	witnesses = append(witnesses, witness)
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      hex.EncodeToString(BodyHash(body)),
+		SignedTx: hex.EncodeToString(EncodeTransaction(body, witnesses)),
+	}, nil
+}
+
+// 选币并构建交易体，未指定 UTXO 时查询 from_address 的 UTXO，手续费和最小 ADA 按当前协议参数计算
+func (c *ChainAdaptor) buildTxBody(transferTx *CardanoTransferTx) ([]byte, error) {
+	from, err := ParseAddress(transferTx.FromAddress, c.Network)
+	if err != nil {
+		return nil, errors.New("invalid from address")
+	}
+	changeAddress := from
+	if transferTx.ChangeAddress != "" {
+		if changeAddress, err = ParseAddress(transferTx.ChangeAddress, c.Network); err != nil {
+			return nil, errors.New("invalid change address")
+		}
+	}
+	var outputs []TxOutput
+	for _, item := range transferTx.Outputs {
+		address, err := ParseAddress(item.Address, c.Network)
+		if err != nil {
+			return nil, fmt.Errorf("invalid output address: %s", item.Address)
+		}
+		value, err := parseTransferValue(item.Amount, item.Assets)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, TxOutput{Address: address, Value: value})
+	}
+
+	var utxos []Utxo
+	for _, item := range transferTx.Utxos {
+		input, err := parseInput(item.TxHash, item.OutputIndex)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseTransferValue(item.Amount, item.Assets)
+		if err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, Utxo{Input: input, Value: value})
+	}
+	if len(utxos) == 0 {
+		if utxos, err = c.spendableUtxos(transferTx.FromAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	protocolParams, err := c.BlockfrostClient.GetProtocolParams()
+	if err != nil {
+		return nil, err
+	}
+	params, err := toFeeParams(protocolParams)
+	if err != nil {
+		return nil, err
+	}
+	ttl := transferTx.Ttl
+	if ttl == 0 {
+		block, err := c.BlockfrostClient.GetLatestBlock()
+		if err != nil {
+			return nil, err
+		}
+		if block.Slot == nil {
+			return nil, errors.New("latest block has no slot")
+		}
+		ttl = *block.Slot + ttlOffset
+	}
+	body, err := SelectCoins(utxos, outputs, changeAddress, params, ttl)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := body.MarshalCBOR()
+	if err != nil {
+		return nil, err
+	}
+	if protocolParams.MaxTxSize > 0 && uint64(len(EncodeTransaction(raw, []VKeyWitness{dummyWitness}))) > protocolParams.MaxTxSize {
+		return nil, errors.New("transaction too large")
+	}
+	return raw, nil
+}
+
+// 查询地址的 UTXO，跳过带 datum 或引用脚本的输出
+func (c *ChainAdaptor) spendableUtxos(address string) ([]Utxo, error) {
+	addressUtxos, err := c.BlockfrostClient.GetAddressUtxos(address)
+	if err != nil {
+		return nil, err
+	}
+	var utxos []Utxo
+	for _, item := range addressUtxos {
+		if item.DataHash != nil || item.InlineDatum != nil || item.ReferenceScriptHash != nil {
+			continue
+		}
+		input, err := parseInput(item.TxHash, item.OutputIndex)
+		if err != nil {
+			return nil, err
+		}
+		value, err := toValue(item.Amount)
+		if err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, Utxo{Input: input, Value: value})
+	}
+	return utxos, nil
+}
+
+func toFeeParams(params *ProtocolParams) (FeeParams, error) {
+	coinsPerUtxoByte, err := strconv.ParseUint(params.CoinsPerUtxoSize, 10, 64)
+	if err != nil || coinsPerUtxoByte == 0 {
+		return FeeParams{}, errors.New("invalid coins_per_utxo_size")
+	}
+	return FeeParams{MinFeeA: params.MinFeeA, MinFeeB: params.MinFeeB, CoinsPerUtxoByte: coinsPerUtxoByte}, nil
+}
+
+func parseInput(txHash string, index uint32) (TxInput, error) {
+	input := TxInput{Index: index}
+	raw, err := hex.DecodeString(txHash)
+	if err != nil || len(raw) != len(input.TxHash) {
+		return TxInput{}, fmt.Errorf("invalid utxo tx hash: %s", txHash)
+	}
+	copy(input.TxHash[:], raw)
+	return input, nil
+}
+
+func parseTransferValue(amount string, assets []TransferAsset) (Value, error) {
+	amounts := []Amount{{Unit: lovelaceUnit, Quantity: amount}}
+	if amount == "" {
+		amounts[0].Quantity = "0"
+	}
+	for _, asset := range assets {
+		amounts = append(amounts, Amount(asset))
+	}
+	return toValue(amounts)
+}
+
+// 转换 Blockfrost 格式的金额列表
+func toValue(amounts []Amount) (Value, error) {
+	value := Value{Assets: make(MultiAsset)}
+	for _, amount := range amounts {
+		quantity, err := strconv.ParseUint(amount.Quantity, 10, 64)
+		if err != nil {
+			return Value{}, fmt.Errorf("invalid quantity: %s", amount.Quantity)
+		}
+		if amount.Unit == lovelaceUnit {
+			value.Coin += quantity
+			continue
+		}
+		policyId, assetName, err := ParseUnit(amount.Unit)
+		if err != nil {
+			return Value{}, err
+		}
+		value.Assets.Add(policyId, assetName, quantity)
+	}
+	return value, nil
+}
+
+// 拆分资产 unit 为 policy id 和资产名（均为小写 hex）
+func ParseUnit(unit string) (string, string, error) {
+	unit = strings.ToLower(unit)
+	if len(unit) < policyIdHexLength || len(unit) > policyIdHexLength+64 || len(unit)%2 != 0 {
+		return "", "", fmt.Errorf("invalid asset unit: %s", unit)
+	}
+	if _, err := hex.DecodeString(unit); err != nil {
+		return "", "", fmt.Errorf("invalid asset unit: %s", unit)
+	}
+	return unit[:policyIdHexLength], unit[policyIdHexLength:], nil
+}
+
+func decodeRawTx(rawTx string) ([]byte, []VKeyWitness, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(rawTx, "0x"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return DecodeTransaction(data)
+}
@@ -0,0 +1,347 @@
+package cardano
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// 交易体的字段编号
+const (
+	bodyKeyInputs  = 0
+	bodyKeyOutputs = 1
+	bodyKeyFee     = 2
+	bodyKeyTtl     = 3
+)
+
+// 后 Alonzo 格式输出的字段编号
+const (
+	outputKeyAddress = 0
+	outputKeyValue   = 1
+)
+
+// 见证集中 vkey 见证的字段编号
+const witnessKeyVKey = 0
+
+// 原生资产数量：policy id（hex） -> 资产名（hex） -> 数量
+type MultiAsset map[string]map[string]uint64
+
+func (m MultiAsset) Add(policyId, assetName string, quantity uint64) {
+	if quantity == 0 {
+		return
+	}
+	if m[policyId] == nil {
+		m[policyId] = make(map[string]uint64)
+	}
+	m[policyId][assetName] += quantity
+}
+
+// 减去 other，不足时返回 false
+func (m MultiAsset) Sub(other MultiAsset) bool {
+	for policyId, assets := range other {
+		for assetName, quantity := range assets {
+			if m[policyId][assetName] < quantity {
+				return false
+			}
+			m[policyId][assetName] -= quantity
+			if m[policyId][assetName] == 0 {
+				delete(m[policyId], assetName)
+			}
+		}
+		if len(m[policyId]) == 0 {
+			delete(m, policyId)
+		}
+	}
+	return true
+}
+
+func (m MultiAsset) Clone() MultiAsset {
+	clone := make(MultiAsset, len(m))
+	for policyId, assets := range m {
+		for assetName, quantity := range assets {
+			clone.Add(policyId, assetName, quantity)
+		}
+	}
+	return clone
+}
+
+// 输出金额：lovelace 和原生资产
+type Value struct {
+	Coin   uint64
+	Assets MultiAsset
+}
+
+type TxInput struct {
+	TxHash [32]byte
+	Index  uint32
+}
+
+type TxOutput struct {
+	Address Address
+	Value   Value
+}
+
+type TxBody struct {
+	Inputs  []TxInput
+	Outputs []TxOutput
+	Fee     uint64
+	Ttl     uint64
+}
+
+type VKeyWitness struct {
+	VKey      []byte
+	Signature []byte
+}
+
+// CBOR 规范顺序：长度短的在前，长度相同时按字典序
+func sortCanonical(keys [][]byte) {
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return bytes.Compare(keys[i], keys[j]) < 0
+	})
+}
+
+func (m MultiAsset) encode(w *cborWriter) error {
+	policyIds := make([][]byte, 0, len(m))
+	for policyId := range m {
+		raw, err := hex.DecodeString(policyId)
+		if err != nil || len(raw) != keyHashLength {
+			return fmt.Errorf("invalid policy id: %s", policyId)
+		}
+		policyIds = append(policyIds, raw)
+	}
+	sortCanonical(policyIds)
+	w.mapHeader(len(policyIds))
+	for _, policyId := range policyIds {
+		assets := m[hex.EncodeToString(policyId)]
+		names := make([][]byte, 0, len(assets))
+		for assetName := range assets {
+			raw, err := hex.DecodeString(assetName)
+			if err != nil || len(raw) > 32 {
+				return fmt.Errorf("invalid asset name: %s", assetName)
+			}
+			names = append(names, raw)
+		}
+		sortCanonical(names)
+		w.bytes(policyId)
+		w.mapHeader(len(names))
+		for _, name := range names {
+			w.bytes(name)
+			w.uint(assets[hex.EncodeToString(name)])
+		}
+	}
+	return nil
+}
+
+// 只有 lovelace 时编码为整数，否则为 [coin, multiasset]
+func (v *Value) encode(w *cborWriter) error {
+	if len(v.Assets) == 0 {
+		w.uint(v.Coin)
+		return nil
+	}
+	w.array(2)
+	w.uint(v.Coin)
+	return v.Assets.encode(w)
+}
+
+// 使用 [address, value] 的旧格式，不带 datum
+func (o *TxOutput) encode(w *cborWriter) error {
+	w.array(2)
+	w.bytes(o.Address)
+	return o.Value.encode(w)
+}
+
+// 输出的编码长度，用于计算最小 ADA
+func (o *TxOutput) size() (int, error) {
+	w := &cborWriter{}
+	if err := o.encode(w); err != nil {
+		return 0, err
+	}
+	return len(w.buf), nil
+}
+
+func (b *TxBody) MarshalCBOR() ([]byte, error) {
+	w := &cborWriter{}
+	fields := 3
+	if b.Ttl > 0 {
+		fields++
+	}
+	w.mapHeader(fields)
+	w.uint(bodyKeyInputs)
+	w.array(len(b.Inputs))
+	for _, input := range b.Inputs {
+		w.array(2)
+		w.bytes(input.TxHash[:])
+		w.uint(uint64(input.Index))
+	}
+	w.uint(bodyKeyOutputs)
+	w.array(len(b.Outputs))
+	for i := range b.Outputs {
+		if err := b.Outputs[i].encode(w); err != nil {
+			return nil, err
+		}
+	}
+	w.uint(bodyKeyFee)
+	w.uint(b.Fee)
+	if b.Ttl > 0 {
+		w.uint(bodyKeyTtl)
+		w.uint(b.Ttl)
+	}
+	return w.buf, nil
+}
+
+// 交易哈希为交易体的 blake2b-256，也是签名的消息
+func BodyHash(body []byte) []byte {
+	hash := blake2b.Sum256(body)
+	return hash[:]
+}
+
+// 完整交易：[body, witness_set, is_valid, auxiliary_data]
+func EncodeTransaction(body []byte, witnesses []VKeyWitness) []byte {
+	w := &cborWriter{}
+	w.array(4)
+	w.raw(body)
+	if len(witnesses) == 0 {
+		w.mapHeader(0)
+	} else {
+		w.mapHeader(1)
+		w.uint(witnessKeyVKey)
+		w.array(len(witnesses))
+		for _, witness := range witnesses {
+			w.array(2)
+			w.bytes(witness.VKey)
+			w.bytes(witness.Signature)
+		}
+	}
+	w.bool(true)
+	w.null()
+	return w.buf
+}
+
+// 拆分交易，返回交易体的原始编码和 vkey 见证，不支持含脚本等其他见证的交易
+func DecodeTransaction(data []byte) ([]byte, []VKeyWitness, error) {
+	r := &cborReader{data: data}
+	if n := r.arrayLen(); r.err == nil && n != 4 {
+		return nil, nil, errors.New("invalid transaction")
+	}
+	body := r.raw()
+	var witnesses []VKeyWitness
+	for i := r.mapLen(); i > 0 && r.err == nil; i-- {
+		if key := r.uint(); key != witnessKeyVKey {
+			return nil, nil, fmt.Errorf("unsupported witness type %d", key)
+		}
+		for j := r.arrayLen(); j > 0 && r.err == nil; j-- {
+			if r.arrayLen() != 2 {
+				r.fail(errors.New("invalid vkey witness"))
+			}
+			witnesses = append(witnesses, VKeyWitness{VKey: r.bytes(), Signature: r.bytes()})
+		}
+	}
+	r.skip()
+	r.skip()
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	if r.pos != len(data) {
+		return nil, nil, errors.New("trailing data after transaction")
+	}
+	return body, witnesses, nil
+}
+
+// 解析交易体的输入、输出、手续费和 ttl，忽略其他字段
+func DecodeTxBody(data []byte) (*TxBody, error) {
+	r := &cborReader{data: data}
+	body := &TxBody{}
+	for i := r.mapLen(); i > 0 && r.err == nil; i-- {
+		switch r.uint() {
+		case bodyKeyInputs:
+			for j := r.arrayLen(); j > 0 && r.err == nil; j-- {
+				var input TxInput
+				r.arrayLen()
+				copy(input.TxHash[:], r.bytes())
+				input.Index = uint32(r.uint())
+				body.Inputs = append(body.Inputs, input)
+			}
+		case bodyKeyOutputs:
+			for j := r.arrayLen(); j > 0 && r.err == nil; j-- {
+				body.Outputs = append(body.Outputs, decodeOutput(r))
+			}
+		case bodyKeyFee:
+			body.Fee = r.uint()
+		case bodyKeyTtl:
+			body.Ttl = r.uint()
+		default:
+			r.skip()
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return body, nil
+}
+
+// 支持 [address, value, ...] 的旧格式和 {0: address, 1: value, ...} 的新格式
+func decodeOutput(r *cborReader) TxOutput {
+	var output TxOutput
+	if r.peekMajor() == cborMap {
+		for i := r.mapLen(); i > 0 && r.err == nil; i-- {
+			switch r.uint() {
+			case outputKeyAddress:
+				output.Address = r.bytes()
+			case outputKeyValue:
+				output.Value = decodeValue(r)
+			default:
+				r.skip()
+			}
+		}
+		return output
+	}
+	n := r.arrayLen()
+	if n < 2 {
+		r.fail(errors.New("invalid transaction output"))
+		return output
+	}
+	output.Address = r.bytes()
+	output.Value = decodeValue(r)
+	for i := 2; i < n; i++ {
+		r.skip()
+	}
+	return output
+}
+
+func decodeValue(r *cborReader) Value {
+	if r.peekMajor() == cborUint {
+		return Value{Coin: r.uint()}
+	}
+	if r.arrayLen() != 2 {
+		r.fail(errors.New("invalid value"))
+		return Value{}
+	}
+	value := Value{Coin: r.uint(), Assets: make(MultiAsset)}
+	for i := r.mapLen(); i > 0 && r.err == nil; i-- {
+		policyId := hex.EncodeToString(r.bytes())
+		for j := r.mapLen(); j > 0 && r.err == nil; j-- {
+			assetName := hex.EncodeToString(r.bytes())
+			value.Assets.Add(policyId, assetName, r.uint())
+		}
+	}
+	return value
+}
+
+// 验证 ed25519 签名并生成 vkey 见证
+func NewVKeyWitness(pubKey []byte, body []byte, signature []byte) (VKeyWitness, error) {
+	if len(pubKey) != ed25519.PublicKeySize || len(signature) != ed25519.SignatureSize {
+		return VKeyWitness{}, errors.New("invalid public key or signature length")
+	}
+	if !ed25519.Verify(pubKey, BodyHash(body), signature) {
+		return VKeyWitness{}, errors.New("signature verification failed")
+	}
+	return VKeyWitness{VKey: pubKey, Signature: signature}, nil
+}
@@ -0,0 +1,40 @@
+package cardano
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type CardanoTransferTx struct {
+	FromAddress   string `json:"from_address"`
+	ChangeAddress string `json:"change_address"` // 为空时找零到 from_address
+	// 指定可用的 UTXO，为空时查询 from_address 的 UTXO
+	Utxos   []TransferUtxo   `json:"utxos"`
+	Outputs []TransferOutput `json:"outputs"`
+	// 交易有效期的最大 slot，为 0 时为最新区块的 slot 加 2 小时
+	Ttl uint64 `json:"ttl"`
+}
+
+type TransferUtxo struct {
+	TxHash      string          `json:"tx_hash"`
+	OutputIndex uint32          `json:"output_index"`
+	Amount      string          `json:"amount"` // 单位 lovelace
+	Assets      []TransferAsset `json:"assets"`
+}
+
+type TransferOutput struct {
+	Address string `json:"address"`
+	// 单位 lovelace，只转原生资产时可为 0，自动附带最小 ADA
+	Amount string          `json:"amount"`
+	Assets []TransferAsset `json:"assets"`
+}
+
+// unit 为 policy id 和资产名的 hex 拼接，与 Blockfrost 一致
+type TransferAsset struct {
+	Unit     string `json:"unit"`
+	Quantity string `json:"quantity"`
+}
+
+// 交易中解析出的 ADA 或原生资产转账
+type Transfer struct {
+	ContractAddress string `json:"contract_address"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Amount          string `json:"amount"`
+}
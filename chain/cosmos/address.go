@@ -0,0 +1,53 @@
+package cosmos
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+)
+
+// 由 secp256k1 公钥生成地址：bech32(prefix, ripemd160(sha256(压缩公钥)))
+func PubKeyToAddress(pubKeyHex string, prefix string) (string, error) {
+	pubKey, err := parsePubKey(pubKeyHex)
+	if err != nil {
+		return "", err
+	}
+	return bech32.EncodeFromBase256(prefix, btcutil.Hash160(pubKey))
+}
+
+// 解析公钥，支持压缩和非压缩格式，返回压缩公钥
+func parsePubKey(pubKeyHex string) ([]byte, error) {
+	pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(pubKeyHex, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := btcec.ParsePubKey(pubKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	return pubKey.SerializeCompressed(), nil
+}
+
+// 解析地址，账户地址为 20 字节，模块账户和合约地址为 32 字节
+func decodeAddress(address string, prefix string) ([]byte, error) {
+	hrp, data, err := bech32.DecodeToBase256(address)
+	if err != nil {
+		return nil, err
+	}
+	if hrp != prefix {
+		return nil, fmt.Errorf("address %s does not have prefix %s", address, prefix)
+	}
+	if len(data) != 20 && len(data) != 32 {
+		return nil, fmt.Errorf("invalid address length %d", len(data))
+	}
+	return data, nil
+}
+
+func ValidateAddress(address string, prefix string) bool {
+	_, err := decodeAddress(address, prefix)
+	return err == nil
+}
@@ -0,0 +1,520 @@
+package cosmos
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const (
+	// 未配置 gas_price 时的默认值
+	defaultGasPrice = "0.025"
+	// 单个 MsgSend 的典型 gas 消耗，用于没有交易时的手续费估算
+	defaultSendGas = 100000
+	// 模拟结果乘以该系数作为 gas_limit
+	gasAdjustment = 1.3
+)
+
+type ChainAdaptor struct {
+	CosmosClient ICosmos
+	Name         string
+	ChainId      string
+	Bech32Prefix string
+	Denom        string
+	GasPrice     *big.Rat
+}
+
+// 按配置创建 Cosmos SDK 链的适配器，每条链使用各自的地址前缀和原生币种
+func NewChainAdaptor(node config.CosmosNode) (chain.IChainAdaptor, error) {
+	if node.ChainId == "" || node.Bech32Prefix == "" || node.Denom == "" {
+		return nil, fmt.Errorf("cosmos chain %s requires chain_id, bech32_prefix and denom", node.Name)
+	}
+	gasPriceStr := node.GasPrice
+	if gasPriceStr == "" {
+		gasPriceStr = defaultGasPrice
+	}
+	gasPrice, ok := new(big.Rat).SetString(gasPriceStr)
+	if !ok || gasPrice.Sign() < 0 {
+		return nil, fmt.Errorf("invalid gas price %s", node.GasPrice)
+	}
+	cosmosClient, err := NewCosmosClient(node.RpcUrl, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		CosmosClient: cosmosClient,
+		Name:         node.Name,
+		ChainId:      node.ChainId,
+		Bech32Prefix: node.Bech32Prefix,
+		Denom:        node.Denom,
+		GasPrice:     gasPrice,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入公钥 转换成地址
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeSecp256k1); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	address, err := PubKeyToAddress(req.PublicKey, c.Bech32Prefix)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address,
+	}, nil
+}
+
+// 地址格式验证，需要匹配链的地址前缀
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ValidateAddress(req.Address, c.Bech32Prefix) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 通过区块号获取区块数据，解析区块内的 MsgSend 转账（不包含执行结果）
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	block, err := c.getBlock(req.Height)
+	if err != nil {
+		log.Error("get block by number fail", "height", req.Height, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	height, _ := strconv.ParseInt(block.Block.Header.Height, 10, 64)
+	var blockTxList []*account.BlockInfoTransactionList
+	for _, txBase64 := range block.Block.Data.Txs {
+		txRaw, err := base64.StdEncoding.DecodeString(txBase64)
+		if err != nil {
+			continue
+		}
+		bodyBytes, _, err := decodeTxRaw(txRaw)
+		if err != nil {
+			continue
+		}
+		msgs, memo, err := decodeTxBody(bodyBytes)
+		if err != nil {
+			continue
+		}
+		hash := txHash(txRaw)
+		for _, transfer := range c.toTransfers(msgs) {
+			blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+				From:         transfer.From,
+				To:           transfer.To,
+				TokenAddress: c.tokenAddress(transfer.Denom),
+				Hash:         hash,
+				Height:       uint64(height),
+				Amount:       transfer.Amount,
+				Memo:         memo,
+			})
+		}
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          "get block by number success",
+		Height:       height,
+		Hash:         base64ToHex(block.BlockId.Hash),
+		Transactions: blockTxList,
+	}, nil
+}
+
+// LCD 不支持按区块哈希查询
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return &account.BlockResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block by hash is not supported",
+	}, nil
+}
+
+// 通过区块号获取区块头信息，height 为 0 时返回最新区块
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	block, err := c.getBlock(req.Height)
+	if err != nil {
+		log.Error("get block header by number fail", "height", req.Height, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(block),
+	}, nil
+}
+
+// LCD 不支持按区块哈希查询
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	return &account.BlockHeaderResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block header by hash is not supported",
+	}, nil
+}
+
+// 获取区间内的区块头
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseInt(req.Start, 10, 64)
+	if err != nil || start <= 0 {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseInt(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for height := start; height <= end; height++ {
+		block, err := c.CosmosClient.GetBlockByHeight(height)
+		if err != nil {
+			log.Error("get block fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		headers = append(headers, toBlockHeader(block))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取账户余额、账户编号和序列号，contract_address 为币种（denom），为空时查询原生币种。
+// 账户未在链上创建时编号和序列号为 0
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if !ValidateAddress(req.Address, c.Bech32Prefix) {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	acc, err := c.CosmosClient.GetAccount(req.Address)
+	if err != nil {
+		log.Error("get account fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	denom := req.ContractAddress
+	if denom == "" {
+		denom = c.Denom
+	}
+	balance, err := c.CosmosClient.GetBalance(req.Address, denom)
+	if err != nil {
+		log.Error("get balance fail", "address", req.Address, "denom", denom, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	resp := &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Balance:       balance,
+	}
+	if acc != nil {
+		resp.AccountNumber = strconv.FormatUint(acc.AccountNumber, 10)
+		resp.Sequence = strconv.FormatUint(acc.Sequence, 10)
+	}
+	return resp, nil
+}
+
+// 获取fee，单位为原生币种最小单位，按 gas_price × gas 计算，三档返回相同结果。
+// 传入 rawTx（BuildUnSignTransaction 返回的 SignDoc）时模拟执行该交易估算 gas，否则按单个 MsgSend 的典型消耗计算
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	gas := uint64(defaultSendGas)
+	if req.RawTx != "" {
+		signDocBytes, err := base64.StdEncoding.DecodeString(req.RawTx)
+		if err != nil {
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		signDoc, err := decodeSignDoc(signDocBytes)
+		if err != nil {
+			log.Error("decode sign doc fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "decode raw tx fail",
+			}, nil
+		}
+		if gas, err = c.simulate(signDoc.BodyBytes, signDoc.AuthInfoBytes); err != nil {
+			log.Error("simulate tx fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "simulate tx fail",
+			}, nil
+		}
+	}
+	fee := c.feeAmount(gas).String()
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee,
+		NormalFee: fee,
+		FastFee:   fee,
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的 base64 TxRaw
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	txBytes, err := base64.StdEncoding.DecodeString(req.RawTx)
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	resp, err := c.CosmosClient.BroadcastTx(txBytes)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	// CheckTx 失败（序列号错误、余额不足等）
+	if resp.Code != 0 {
+		log.Error("send tx fail", "codespace", resp.Codespace, "code", resp.Code, "log", resp.RawLog)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail: " + resp.RawLog,
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: resp.TxHash,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get tx by address is not supported",
+	}, nil
+}
+
+// 通过交易哈希获取交易，解析 MsgSend 转账，全部转账以 JSON 写入 data
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	result, err := c.CosmosClient.GetTx(strings.ToUpper(strings.TrimPrefix(req.Hash, "0x")))
+	if err != nil {
+		log.Error("get transaction fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	if result == nil {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+
+	txResponse := result.TxResponse
+	txMessage := &account.TxMessage{
+		Hash:   txResponse.TxHash,
+		Value:  "0",
+		Status: account.TxStatus_Success,
+		Height: txResponse.Height,
+		Fee:    "0",
+		Memo:   result.Tx.Body.Memo,
+	}
+	if txResponse.Code != 0 {
+		txMessage.Status = account.TxStatus_Failed
+	}
+	if timestamp, err := time.Parse(time.RFC3339, txResponse.Timestamp); err == nil {
+		txMessage.Datetime = strconv.FormatInt(timestamp.Unix(), 10)
+	}
+	for _, coin := range result.Tx.AuthInfo.Fee.Amount {
+		if coin.Denom == c.Denom {
+			txMessage.Fee = coin.Amount
+		}
+	}
+
+	var msgs []MsgSend
+	for _, raw := range result.Tx.Body.Messages {
+		var msg struct {
+			Type string `json:"@type"`
+			MsgSend
+		}
+		if err := json.Unmarshal(raw, &msg); err == nil && msg.Type == msgSendTypeUrl {
+			msgs = append(msgs, msg.MsgSend)
+		}
+	}
+	transfers := c.toTransfers(msgs)
+	if len(transfers) > 0 {
+		txMessage.From = transfers[0].From
+		txMessage.To = transfers[0].To
+		txMessage.Value = transfers[0].Amount
+		txMessage.ContractAddress = c.tokenAddress(transfers[0].Denom)
+		if txMessage.ContractAddress != "" {
+			txMessage.Type = 1
+		}
+		data, _ := json.Marshal(transfers)
+		txMessage.Data = string(data)
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   txMessage,
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// height 为 0 时获取最新区块
+func (c *ChainAdaptor) getBlock(height int64) (*Block, error) {
+	if height == 0 {
+		return c.CosmosClient.GetLatestBlock()
+	}
+	return c.CosmosClient.GetBlockByHeight(height)
+}
+
+// 模拟执行交易估算 gas_limit，模拟不校验签名，使用空签名占位
+func (c *ChainAdaptor) simulate(bodyBytes, authInfoBytes []byte) (uint64, error) {
+	gasUsed, err := c.CosmosClient.Simulate(encodeTxRaw(bodyBytes, authInfoBytes, []byte{}))
+	if err != nil {
+		return 0, err
+	}
+	if gasUsed == 0 {
+		return 0, errors.New("simulate returned zero gas")
+	}
+	return uint64(float64(gasUsed) * gasAdjustment), nil
+}
+
+// 手续费 = ceil(gas × gas_price)
+func (c *ChainAdaptor) feeAmount(gas uint64) *big.Int {
+	fee := new(big.Rat).Mul(new(big.Rat).SetUint64(gas), c.GasPrice)
+	amount, remainder := new(big.Int).QuoRem(fee.Num(), fee.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		amount.Add(amount, big.NewInt(1))
+	}
+	return amount
+}
+
+// 原生币种返回空，其他币种（IBC 资产、tokenfactory 代币）返回 denom
+func (c *ChainAdaptor) tokenAddress(denom string) string {
+	if denom == c.Denom {
+		return ""
+	}
+	return denom
+}
+
+// 每个 MsgSend 的每个币种展开为一条转账
+func (c *ChainAdaptor) toTransfers(msgs []MsgSend) []Transfer {
+	var transfers []Transfer
+	for _, msg := range msgs {
+		for _, coin := range msg.Amount {
+			transfers = append(transfers, Transfer{
+				Denom:  coin.Denom,
+				From:   msg.FromAddress,
+				To:     msg.ToAddress,
+				Amount: coin.Amount,
+			})
+		}
+	}
+	return transfers
+}
+
+// 区块哈希为 base64，转换为浏览器使用的大写十六进制
+func base64ToHex(value string) string {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return value
+	}
+	return strings.ToUpper(hex.EncodeToString(decoded))
+}
+
+func toBlockHeader(block *Block) *account.BlockHeader {
+	header := block.Block.Header
+	return &account.BlockHeader{
+		Hash:       base64ToHex(block.BlockId.Hash),
+		ParentHash: base64ToHex(header.LastBlockId.Hash),
+		Root:       base64ToHex(header.AppHash),
+		TxHash:     base64ToHex(header.DataHash),
+		CoinBase:   base64ToHex(header.ProposerAddress),
+		Number:     header.Height,
+		Time:       uint64(header.Time.Unix()),
+	}
+}
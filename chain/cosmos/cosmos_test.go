@@ -0,0 +1,220 @@
+package cosmos
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 模拟 LCD，记录最后一次模拟执行的交易
+type fakeCosmosNode struct {
+	ICosmos
+	account   *Account
+	simulated []byte
+	msgs      []MsgSend
+	memo      string
+}
+
+func (f *fakeCosmosNode) GetAccount(address string) (*Account, error) {
+	return f.account, nil
+}
+
+func (f *fakeCosmosNode) Simulate(txBytes []byte) (uint64, error) {
+	f.simulated = txBytes
+	return 80000, nil
+}
+
+func (f *fakeCosmosNode) GetBlockByHeight(height int64) (*Block, error) {
+	block := new(Block)
+	block.Block.Header.Height = "100"
+	for _, msg := range f.msgs {
+		body := encodeTxBody([]MsgSend{msg}, f.memo)
+		block.Block.Data.Txs = append(block.Block.Data.Txs, base64.StdEncoding.EncodeToString(encodeTxRaw(body, nil, []byte{})))
+	}
+	return block, nil
+}
+
+func (f *fakeCosmosNode) GetTx(hash string) (*TxResult, error) {
+	result := new(TxResult)
+	result.TxResponse.TxHash = hash
+	result.Tx.Body.Memo = f.memo
+	for _, msg := range f.msgs {
+		raw, _ := json.Marshal(struct {
+			Type string `json:"@type"`
+			MsgSend
+		}{msgSendTypeUrl, msg})
+		result.Tx.Body.Messages = append(result.Tx.Body.Messages, raw)
+	}
+	return result, nil
+}
+
+func newTestAdaptor(node ICosmos) *ChainAdaptor {
+	return &ChainAdaptor{
+		CosmosClient: node,
+		ChainId:      "cosmoshub-4",
+		Bech32Prefix: "cosmos",
+		Denom:        "uatom",
+		GasPrice:     big.NewRat(25, 1000),
+	}
+}
+
+func buildUnSigned(t *testing.T, adaptor *ChainAdaptor, transferTx CosmosTransferTx) *account.UnSignTransactionResponse {
+	txJson, _ := json.Marshal(transferTx)
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	return resp
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	adaptor := newTestAdaptor(nil)
+	// secp256k1 生成元 G 对应私钥 1，hash160 为 751e76e8199196d454941c45d1b3a323f1433bd6
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"})
+	if resp.Code != global_const.ReturnCode_SUCCESS || !strings.HasPrefix(resp.Address, "cosmos1w508d6qejxtdg4y5r3zarvary0c5xw7k") {
+		t.Fatalf("unexpected address %s", resp.Address)
+	}
+	hash, err := decodeAddress(resp.Address, "cosmos")
+	if err != nil || hex.EncodeToString(hash) != "751e76e8199196d454941c45d1b3a323f1433bd6" {
+		t.Fatalf("unexpected address hash %x", hash)
+	}
+	osmo, _ := PubKeyToAddress("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", "osmo")
+	if ValidateAddress(osmo, "cosmos") || !ValidateAddress(osmo, "osmo") {
+		t.Fatal("expected address to be validated against chain prefix")
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", KeyType: "ed25519"})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected ed25519 key type to be rejected")
+	}
+}
+
+func Test_BuildTransaction(t *testing.T) {
+	key := mustKey(1)
+	pubKey := crypto.CompressPubkey(&key.PublicKey)
+	from, _ := PubKeyToAddress(hex.EncodeToString(pubKey), "cosmos")
+	to, _ := PubKeyToAddress(hex.EncodeToString(crypto.CompressPubkey(&mustKey(2).PublicKey)), "cosmos")
+	node := &fakeCosmosNode{account: &Account{Address: from, AccountNumber: 42, Sequence: 7}}
+	adaptor := newTestAdaptor(node)
+
+	// 链上没有公钥时必须传入
+	txJson, _ := json.Marshal(CosmosTransferTx{FromAddress: from, ToAddress: to, Amount: "1000000"})
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected missing public key to be rejected")
+	}
+	// 公钥与发送地址不一致
+	txJson, _ = json.Marshal(CosmosTransferTx{FromAddress: from, ToAddress: to, Amount: "1000000", PublicKey: hex.EncodeToString(crypto.CompressPubkey(&mustKey(2).PublicKey))})
+	resp, _ = adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected mismatched public key to be rejected")
+	}
+
+	node.account.PubKey = pubKey
+	unSigned := buildUnSigned(t, adaptor, CosmosTransferTx{FromAddress: from, ToAddress: to, Amount: "1000000", Memo: "104935"})
+	signDocBytes, _ := base64.StdEncoding.DecodeString(unSigned.UnSignTx)
+	signDoc, err := decodeSignDoc(signDocBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signDoc.ChainId != "cosmoshub-4" || signDoc.AccountNumber != 42 || hex.EncodeToString(signDoc.Hash()) != unSigned.SignHashes[0] {
+		t.Fatal("unexpected sign doc")
+	}
+	msgs, memo, err := decodeTxBody(signDoc.BodyBytes)
+	if err != nil || len(msgs) != 1 || memo != "104935" {
+		t.Fatalf("unexpected tx body %v %s", msgs, memo)
+	}
+	if msgs[0].FromAddress != from || msgs[0].ToAddress != to || msgs[0].Amount[0] != (Coin{Denom: "uatom", Amount: "1000000"}) {
+		t.Fatalf("unexpected msg send %+v", msgs[0])
+	}
+	if signerPubKey, err := decodeSignerPubKey(signDoc.AuthInfoBytes); err != nil || hex.EncodeToString(signerPubKey) != hex.EncodeToString(pubKey) {
+		t.Fatal("unexpected signer public key")
+	}
+	// 模拟消耗 80000，gas_limit 104000，手续费 ceil(104000 × 0.025) = 2600uatom
+	wantAuthInfo := encodeAuthInfo(pubKey, 7, []Coin{{Denom: "uatom", Amount: "2600"}}, 104000)
+	if hex.EncodeToString(signDoc.AuthInfoBytes) != hex.EncodeToString(wantAuthInfo) {
+		t.Fatal("unexpected auth info")
+	}
+	if node.simulated == nil {
+		t.Fatal("expected gas to be simulated")
+	}
+
+	// 其他私钥的签名拒绝
+	wrong, _ := crypto.Sign(signDoc.Hash(), mustKey(2))
+	rejected, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signature: hex.EncodeToString(wrong)})
+	if rejected.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected signature from other key to be rejected")
+	}
+
+	// 高位 S 签名转换为 low-S 后组装
+	signature, _ := crypto.Sign(signDoc.Hash(), key)
+	var s btcec.ModNScalar
+	s.SetByteSlice(signature[32:64])
+	s.Negate()
+	highS := append([]byte{}, signature[:64]...)
+	sBytes := s.Bytes()
+	copy(highS[32:], sBytes[:])
+	signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signature: hex.EncodeToString(highS)})
+	if signed.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", signed.Msg)
+	}
+	txRaw, _ := base64.StdEncoding.DecodeString(signed.SignedTx)
+	if hex.EncodeToString(txRaw) != hex.EncodeToString(encodeTxRaw(signDoc.BodyBytes, signDoc.AuthInfoBytes, signature[:64])) {
+		t.Fatal("unexpected tx raw")
+	}
+	if signed.Msg != txHash(txRaw) || len(signed.Msg) != 64 {
+		t.Fatalf("unexpected tx hash %s", signed.Msg)
+	}
+}
+
+// 交易所按 memo 归属充值，区块和交易查询都需要返回 memo
+func Test_TxMemo(t *testing.T) {
+	from, to := "cosmos1from", "cosmos1to"
+	node := &fakeCosmosNode{
+		msgs: []MsgSend{{FromAddress: from, ToAddress: to, Amount: []Coin{{Denom: "uatom", Amount: "1000000"}}}},
+		memo: "104935",
+	}
+	adaptor := newTestAdaptor(node)
+
+	block, _ := adaptor.GetBlockByNumber(&account.BlockNumberRequest{Height: 100})
+	if block.Code != global_const.ReturnCode_SUCCESS || len(block.Transactions) != 1 || block.Transactions[0].Memo != "104935" {
+		t.Fatalf("unexpected block %+v", block)
+	}
+	tx, _ := adaptor.GetTxByHash(&account.TxHashRequest{Hash: "ABCD"})
+	if tx.Code != global_const.ReturnCode_SUCCESS || tx.Tx.Memo != "104935" || tx.Tx.To != to {
+		t.Fatalf("unexpected tx %+v", tx.Tx)
+	}
+}
+
+func Test_FeeAmount(t *testing.T) {
+	adaptor := newTestAdaptor(nil)
+	if fee := adaptor.feeAmount(100000); fee.String() != "2500" {
+		t.Fatalf("unexpected fee %s", fee)
+	}
+	if fee := adaptor.feeAmount(100001); fee.String() != "2501" {
+		t.Fatalf("expected fee to round up, got %s", fee)
+	}
+}
+
+func privateKeyBytes(n byte) []byte {
+	key := make([]byte, 32)
+	key[31] = n
+	return key
+}
+
+func mustKey(n byte) *ecdsa.PrivateKey {
+	key, err := crypto.ToECDSA(privateKeyBytes(n))
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
@@ -0,0 +1,282 @@
+package cosmos
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// gRPC NotFound
+const grpcCodeNotFound = 5
+
+// LCD 返回的 gRPC 错误
+type RpcError struct {
+	Status  int    `json:"-"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// 账户或交易不存在
+func IsNotFound(err error) bool {
+	var rpcErr *RpcError
+	return errors.As(err, &rpcErr) && (rpcErr.Code == grpcCodeNotFound || rpcErr.Status == http.StatusNotFound)
+}
+
+type BlockHeader struct {
+	ChainId     string    `json:"chain_id"`
+	Height      string    `json:"height"`
+	Time        time.Time `json:"time"`
+	LastBlockId struct {
+		Hash string `json:"hash"` // base64
+	} `json:"last_block_id"`
+	DataHash        string `json:"data_hash"`
+	AppHash         string `json:"app_hash"`
+	ProposerAddress string `json:"proposer_address"`
+}
+
+type Block struct {
+	BlockId struct {
+		Hash string `json:"hash"` // base64
+	} `json:"block_id"`
+	Block struct {
+		Header BlockHeader `json:"header"`
+		Data   struct {
+			Txs []string `json:"txs"` // base64 编码的 TxRaw
+		} `json:"data"`
+	} `json:"block"`
+}
+
+// 账户编号和序列号，账户收到第一笔转账后才在链上创建
+type Account struct {
+	Address       string
+	PubKey        []byte // 账户发出过交易后才有公钥
+	AccountNumber uint64
+	Sequence      uint64
+}
+
+type TxResponse struct {
+	Height    string `json:"height"`
+	TxHash    string `json:"txhash"`
+	Codespace string `json:"codespace"`
+	Code      uint32 `json:"code"`
+	RawLog    string `json:"raw_log"`
+	GasWanted string `json:"gas_wanted"`
+	GasUsed   string `json:"gas_used"`
+	Timestamp string `json:"timestamp"`
+}
+
+// 交易查询结果，tx 为 JSON 格式
+type TxResult struct {
+	Tx struct {
+		Body struct {
+			Messages []json.RawMessage `json:"messages"`
+			Memo     string            `json:"memo"`
+		} `json:"body"`
+		AuthInfo struct {
+			Fee struct {
+				Amount   []Coin `json:"amount"`
+				GasLimit string `json:"gas_limit"`
+			} `json:"fee"`
+		} `json:"auth_info"`
+	} `json:"tx"`
+	TxResponse TxResponse `json:"tx_response"`
+}
+
+// 定义 Cosmos SDK LCD（gRPC gateway）接口
+type ICosmos interface {
+	// 区块数据相关
+	GetLatestBlock() (*Block, error)
+	GetBlockByHeight(height int64) (*Block, error)
+	// 账户
+	GetAccount(address string) (*Account, error)
+	GetBalance(address, denom string) (string, error)
+	// 交易
+	Simulate(txBytes []byte) (uint64, error)
+	BroadcastTx(txBytes []byte) (*TxResponse, error)
+	GetTx(hash string) (*TxResult, error)
+}
+
+type CosmosClient struct {
+	url    string
+	client *http.Client
+}
+
+func NewCosmosClient(rpcUrl string, timeout time.Duration) (ICosmos, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &CosmosClient{
+		url:    strings.TrimSuffix(rpcUrl, "/"),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// 调用 LCD 接口，params 为空时使用 GET
+func (c *CosmosClient) request(path string, params any, result any) error {
+	method, body := http.MethodGet, io.Reader(nil)
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		method, body = http.MethodPost, bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.url+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		rpcErr := &RpcError{Status: resp.StatusCode}
+		if err := json.Unmarshal(respBody, rpcErr); err != nil || rpcErr.Message == "" {
+			rpcErr.Message = string(respBody)
+		}
+		return rpcErr
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// 获取最新区块
+func (c *CosmosClient) GetLatestBlock() (*Block, error) {
+	block := new(Block)
+	if err := c.request("/cosmos/base/tendermint/v1beta1/blocks/latest", nil, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// 通过区块高度获取区块
+func (c *CosmosClient) GetBlockByHeight(height int64) (*Block, error) {
+	block := new(Block)
+	if err := c.request("/cosmos/base/tendermint/v1beta1/blocks/"+strconv.FormatInt(height, 10), nil, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+type baseAccount struct {
+	Address string `json:"address"`
+	PubKey  *struct {
+		Key string `json:"key"`
+	} `json:"pub_key"`
+	AccountNumber string `json:"account_number"`
+	Sequence      string `json:"sequence"`
+}
+
+// 获取账户编号和序列号，账户不存在时返回 nil；锁仓账户取 base_account
+func (c *CosmosClient) GetAccount(address string) (*Account, error) {
+	var result struct {
+		Account struct {
+			baseAccount
+			BaseAccount        *baseAccount `json:"base_account"`
+			BaseVestingAccount *struct {
+				BaseAccount *baseAccount `json:"base_account"`
+			} `json:"base_vesting_account"`
+		} `json:"account"`
+	}
+	if err := c.request("/cosmos/auth/v1beta1/accounts/"+url.PathEscape(address), nil, &result); err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	base := &result.Account.baseAccount
+	if result.Account.BaseAccount != nil {
+		base = result.Account.BaseAccount
+	} else if result.Account.BaseVestingAccount != nil && result.Account.BaseVestingAccount.BaseAccount != nil {
+		base = result.Account.BaseVestingAccount.BaseAccount
+	}
+	accountNumber, err := strconv.ParseUint(base.AccountNumber, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account number %q", base.AccountNumber)
+	}
+	sequence, err := strconv.ParseUint(base.Sequence, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sequence %q", base.Sequence)
+	}
+	account := &Account{Address: base.Address, AccountNumber: accountNumber, Sequence: sequence}
+	if base.PubKey != nil && base.PubKey.Key != "" {
+		if account.PubKey, err = base64.StdEncoding.DecodeString(base.PubKey.Key); err != nil {
+			return nil, err
+		}
+	}
+	return account, nil
+}
+
+// 获取指定币种余额
+func (c *CosmosClient) GetBalance(address, denom string) (string, error) {
+	var result struct {
+		Balance Coin `json:"balance"`
+	}
+	path := "/cosmos/bank/v1beta1/balances/" + url.PathEscape(address) + "/by_denom?denom=" + url.QueryEscape(denom)
+	if err := c.request(path, nil, &result); err != nil {
+		return "", err
+	}
+	if result.Balance.Amount == "" {
+		return "0", nil
+	}
+	return result.Balance.Amount, nil
+}
+
+// 模拟执行交易，返回消耗的 gas
+func (c *CosmosClient) Simulate(txBytes []byte) (uint64, error) {
+	var result struct {
+		GasInfo struct {
+			GasUsed string `json:"gas_used"`
+		} `json:"gas_info"`
+	}
+	if err := c.request("/cosmos/tx/v1beta1/simulate", map[string]any{"tx_bytes": txBytes}, &result); err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(result.GasInfo.GasUsed, 10, 64)
+}
+
+// 同步广播交易，返回 CheckTx 的结果
+func (c *CosmosClient) BroadcastTx(txBytes []byte) (*TxResponse, error) {
+	var result struct {
+		TxResponse TxResponse `json:"tx_response"`
+	}
+	params := map[string]any{"tx_bytes": txBytes, "mode": "BROADCAST_MODE_SYNC"}
+	if err := c.request("/cosmos/tx/v1beta1/txs", params, &result); err != nil {
+		return nil, err
+	}
+	return &result.TxResponse, nil
+}
+
+// 通过交易哈希获取交易，交易不存在时返回 nil
+func (c *CosmosClient) GetTx(hash string) (*TxResult, error) {
+	result := new(TxResult)
+	if err := c.request("/cosmos/tx/v1beta1/txs/"+url.PathEscape(hash), nil, result); err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return result, nil
+}
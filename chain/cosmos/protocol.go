@@ -0,0 +1,350 @@
+package cosmos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	msgSendTypeUrl   = "/cosmos.bank.v1beta1.MsgSend"
+	secp256k1TypeUrl = "/cosmos.crypto.secp256k1.PubKey"
+	// SIGN_MODE_DIRECT
+	signModeDirect = 1
+)
+
+type Coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+type MsgSend struct {
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	Amount      []Coin `json:"amount"`
+}
+
+// cosmos.tx.v1beta1.SignDoc，SIGN_MODE_DIRECT 下签名 sha256(SignDoc)
+type SignDoc struct {
+	BodyBytes     []byte
+	AuthInfoBytes []byte
+	ChainId       string
+	AccountNumber uint64
+}
+
+func (d *SignDoc) Hash() []byte {
+	hash := sha256.Sum256(d.Marshal())
+	return hash[:]
+}
+
+func (d *SignDoc) Marshal() []byte {
+	var buf []byte
+	buf = appendBytes(buf, 1, d.BodyBytes)
+	buf = appendBytes(buf, 2, d.AuthInfoBytes)
+	buf = appendString(buf, 3, d.ChainId)
+	buf = appendVarint(buf, 4, d.AccountNumber)
+	return buf
+}
+
+func decodeSignDoc(b []byte) (*SignDoc, error) {
+	doc := &SignDoc{}
+	err := rangeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			doc.BodyBytes = value
+		case num == 2 && typ == protowire.BytesType:
+			doc.AuthInfoBytes = value
+		case num == 3 && typ == protowire.BytesType:
+			doc.ChainId = string(value)
+		case num == 4 && typ == protowire.VarintType:
+			doc.AccountNumber = varint
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if doc.BodyBytes == nil || doc.AuthInfoBytes == nil || doc.ChainId == "" {
+		return nil, errors.New("invalid sign doc")
+	}
+	return doc, nil
+}
+
+// cosmos.tx.v1beta1.TxRaw，交易哈希为其 sha256 的大写十六进制
+func encodeTxRaw(bodyBytes, authInfoBytes []byte, signatures ...[]byte) []byte {
+	var buf []byte
+	buf = appendBytes(buf, 1, bodyBytes)
+	buf = appendBytes(buf, 2, authInfoBytes)
+	for _, signature := range signatures {
+		buf = protowire.AppendTag(buf, 3, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, signature)
+	}
+	return buf
+}
+
+func decodeTxRaw(b []byte) (bodyBytes []byte, authInfoBytes []byte, err error) {
+	err = rangeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			bodyBytes = value
+		case num == 2 && typ == protowire.BytesType:
+			authInfoBytes = value
+		}
+		return nil
+	})
+	if err == nil && bodyBytes == nil {
+		err = errors.New("missing tx body")
+	}
+	return bodyBytes, authInfoBytes, err
+}
+
+func txHash(txRaw []byte) string {
+	hash := sha256.Sum256(txRaw)
+	return strings.ToUpper(hex.EncodeToString(hash[:]))
+}
+
+// TxBody：messages 为 Any 列表
+func encodeTxBody(msgs []MsgSend, memo string) []byte {
+	var buf []byte
+	for _, msg := range msgs {
+		buf = appendBytes(buf, 1, encodeAny(msgSendTypeUrl, encodeMsgSend(&msg)))
+	}
+	buf = appendString(buf, 2, memo)
+	return buf
+}
+
+// 解析 TxBody 中的 MsgSend，其他类型的消息忽略
+func decodeTxBody(b []byte) ([]MsgSend, string, error) {
+	var msgs []MsgSend
+	var memo string
+	err := rangeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			typeUrl, msgBytes, err := decodeAny(value)
+			if err != nil || typeUrl != msgSendTypeUrl {
+				return err
+			}
+			msg, err := decodeMsgSend(msgBytes)
+			if err != nil {
+				return err
+			}
+			msgs = append(msgs, *msg)
+		case 2:
+			memo = string(value)
+		}
+		return nil
+	})
+	return msgs, memo, err
+}
+
+func encodeMsgSend(msg *MsgSend) []byte {
+	var buf []byte
+	buf = appendString(buf, 1, msg.FromAddress)
+	buf = appendString(buf, 2, msg.ToAddress)
+	for _, coin := range msg.Amount {
+		buf = appendBytes(buf, 3, encodeCoin(coin))
+	}
+	return buf
+}
+
+func decodeMsgSend(b []byte) (*MsgSend, error) {
+	msg := &MsgSend{}
+	err := rangeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			msg.FromAddress = string(value)
+		case 2:
+			msg.ToAddress = string(value)
+		case 3:
+			coin, err := decodeCoin(value)
+			if err != nil {
+				return err
+			}
+			msg.Amount = append(msg.Amount, coin)
+		}
+		return nil
+	})
+	return msg, err
+}
+
+func encodeCoin(coin Coin) []byte {
+	var buf []byte
+	buf = appendString(buf, 1, coin.Denom)
+	buf = appendString(buf, 2, coin.Amount)
+	return buf
+}
+
+func decodeCoin(b []byte) (Coin, error) {
+	var coin Coin
+	err := rangeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ == protowire.BytesType && num == 1 {
+			coin.Denom = string(value)
+		}
+		if typ == protowire.BytesType && num == 2 {
+			coin.Amount = string(value)
+		}
+		return nil
+	})
+	return coin, err
+}
+
+// AuthInfo：单个签名者（SIGN_MODE_DIRECT）和手续费
+func encodeAuthInfo(pubKey []byte, sequence uint64, fee []Coin, gasLimit uint64) []byte {
+	var pubKeyBuf []byte
+	pubKeyBuf = appendBytes(pubKeyBuf, 1, pubKey)
+
+	var single []byte
+	single = appendVarint(single, 1, signModeDirect)
+	var modeInfo []byte
+	modeInfo = appendBytes(modeInfo, 1, single)
+
+	var signerInfo []byte
+	signerInfo = appendBytes(signerInfo, 1, encodeAny(secp256k1TypeUrl, pubKeyBuf))
+	signerInfo = appendBytes(signerInfo, 2, modeInfo)
+	signerInfo = appendVarint(signerInfo, 3, sequence)
+
+	var feeBuf []byte
+	for _, coin := range fee {
+		feeBuf = appendBytes(feeBuf, 1, encodeCoin(coin))
+	}
+	feeBuf = appendVarint(feeBuf, 2, gasLimit)
+
+	var buf []byte
+	buf = appendBytes(buf, 1, signerInfo)
+	buf = appendBytes(buf, 2, feeBuf)
+	return buf
+}
+
+// 解析 AuthInfo 中签名者的 secp256k1 公钥，只支持单个签名者
+func decodeSignerPubKey(authInfo []byte) ([]byte, error) {
+	var signerInfos [][]byte
+	err := rangeFields(authInfo, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num == 1 && typ == protowire.BytesType {
+			signerInfos = append(signerInfos, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(signerInfos) != 1 {
+		return nil, fmt.Errorf("expected 1 signer, got %d", len(signerInfos))
+	}
+	var pubKeyAny []byte
+	err = rangeFields(signerInfos[0], func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num == 1 && typ == protowire.BytesType {
+			pubKeyAny = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	typeUrl, value, err := decodeAny(pubKeyAny)
+	if err != nil {
+		return nil, err
+	}
+	if typeUrl != secp256k1TypeUrl {
+		return nil, fmt.Errorf("unsupported public key type %s", typeUrl)
+	}
+	var pubKey []byte
+	err = rangeFields(value, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num == 1 && typ == protowire.BytesType {
+			pubKey = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pubKey) != 33 {
+		return nil, errors.New("invalid public key")
+	}
+	return pubKey, nil
+}
+
+func encodeAny(typeUrl string, value []byte) []byte {
+	var buf []byte
+	buf = appendString(buf, 1, typeUrl)
+	buf = appendBytes(buf, 2, value)
+	return buf
+}
+
+func decodeAny(b []byte) (string, []byte, error) {
+	var typeUrl string
+	var value []byte
+	err := rangeFields(b, func(num protowire.Number, typ protowire.Type, field []byte, _ uint64) error {
+		if num == 1 && typ == protowire.BytesType {
+			typeUrl = string(field)
+		}
+		if num == 2 && typ == protowire.BytesType {
+			value = field
+		}
+		return nil
+	})
+	return typeUrl, value, err
+}
+
+// 按 protobuf 规范编码，默认值（空字符串、0）不写入
+func appendString(buf []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return buf
+	}
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendString(buf, value)
+}
+
+func appendBytes(buf []byte, num protowire.Number, value []byte) []byte {
+	if len(value) == 0 {
+		return buf
+	}
+	buf = protowire.AppendTag(buf, num, protowire.BytesType)
+	return protowire.AppendBytes(buf, value)
+}
+
+func appendVarint(buf []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return buf
+	}
+	buf = protowire.AppendTag(buf, num, protowire.VarintType)
+	return protowire.AppendVarint(buf, value)
+}
+
+// 遍历 protobuf 字段，bytes 类型通过 value 返回，varint 类型通过 varint 返回
+func rangeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := fn(num, typ, value, varint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package cosmos
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 构建未签名交易：un_sign_tx 为 base64 编码的 SignDoc（SIGN_MODE_DIRECT），sign_hashes 为 sha256(SignDoc)
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx CosmosTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	signDoc, err := c.buildSignDoc(&transferTx)
+	if err != nil {
+		log.Error("build sign doc fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   base64.StdEncoding.EncodeToString(signDoc.Marshal()),
+		SignHashes: []string{hex.EncodeToString(signDoc.Hash())},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 SignDoc，signature 为 64 字节 r||s（65 字节时忽略 v）。
+// 返回的 signed_tx 为 base64 编码的 TxRaw，msg 为交易哈希
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	signDocBytes, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode sign doc fail",
+		}, nil
+	}
+	signDoc, err := decodeSignDoc(signDocBytes)
+	if err != nil {
+		log.Error("decode sign doc fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode sign doc fail",
+		}, nil
+	}
+	if signDoc.ChainId != c.ChainId {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "chain id mismatch",
+		}, nil
+	}
+	pubKey, err := decodeSignerPubKey(signDoc.AuthInfoBytes)
+	if err != nil {
+		log.Error("decode signer public key fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode auth info fail",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || (len(signature) != 64 && len(signature) != crypto.SignatureLength) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	signature = normalizeSignature(signature[:64])
+	if !crypto.VerifySignature(pubKey, signDoc.Hash(), signature) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	txRaw := encodeTxRaw(signDoc.BodyBytes, signDoc.AuthInfoBytes, signature)
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      txHash(txRaw),
+		SignedTx: base64.StdEncoding.EncodeToString(txRaw),
+	}, nil
+}
+
+// 链上只接受 low-S 签名，高位 S 转换为 n - S
+func normalizeSignature(signature []byte) []byte {
+	var s btcec.ModNScalar
+	s.SetByteSlice(signature[32:64])
+	if !s.IsOverHalfOrder() {
+		return signature
+	}
+	s.Negate()
+	normalized := bytes.Clone(signature)
+	sBytes := s.Bytes()
+	copy(normalized[32:], sBytes[:])
+	return normalized
+}
+
+// 构建 MsgSend 的 SignDoc，账户编号和序列号从节点查询
+func (c *ChainAdaptor) buildSignDoc(transferTx *CosmosTransferTx) (*SignDoc, error) {
+	from, err := decodeAddress(transferTx.FromAddress, c.Bech32Prefix)
+	if err != nil {
+		return nil, errors.New("invalid from address")
+	}
+	if !ValidateAddress(transferTx.ToAddress, c.Bech32Prefix) {
+		return nil, errors.New("invalid to address")
+	}
+	amount, ok := new(big.Int).SetString(transferTx.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+	denom := transferTx.Denom
+	if denom == "" {
+		denom = c.Denom
+	}
+
+	acc, err := c.CosmosClient.GetAccount(transferTx.FromAddress)
+	if err != nil {
+		return nil, fmt.Errorf("get account fail: %w", err)
+	}
+	if acc == nil {
+		return nil, errors.New("account not found on chain")
+	}
+	// 公钥必须与发送地址一致
+	var pubKey []byte
+	if transferTx.PublicKey != "" {
+		if pubKey, err = parsePubKey(transferTx.PublicKey); err != nil {
+			return nil, errors.New("invalid public key")
+		}
+	} else if pubKey = acc.PubKey; pubKey == nil {
+		return nil, errors.New("public key is required for account without on-chain public key")
+	}
+	if !bytes.Equal(btcutil.Hash160(pubKey), from) {
+		return nil, errors.New("public key does not match from address")
+	}
+
+	msg := MsgSend{
+		FromAddress: transferTx.FromAddress,
+		ToAddress:   transferTx.ToAddress,
+		Amount:      []Coin{{Denom: denom, Amount: amount.String()}},
+	}
+	bodyBytes := encodeTxBody([]MsgSend{msg}, transferTx.Memo)
+	gasLimit := transferTx.GasLimit
+	if gasLimit == 0 {
+		if gasLimit, err = c.simulate(bodyBytes, encodeAuthInfo(pubKey, acc.Sequence, nil, 0)); err != nil {
+			return nil, fmt.Errorf("simulate tx fail: %w", err)
+		}
+	}
+	var fee []Coin
+	feeAmount := c.feeAmount(gasLimit)
+	if transferTx.FeeAmount != "" {
+		if feeAmount, ok = new(big.Int).SetString(transferTx.FeeAmount, 10); !ok || feeAmount.Sign() < 0 {
+			return nil, errors.New("invalid fee amount")
+		}
+	}
+	if feeAmount.Sign() > 0 {
+		fee = []Coin{{Denom: c.Denom, Amount: feeAmount.String()}}
+	}
+	return &SignDoc{
+		BodyBytes:     bodyBytes,
+		AuthInfoBytes: encodeAuthInfo(pubKey, acc.Sequence, fee, gasLimit),
+		ChainId:       c.ChainId,
+		AccountNumber: acc.AccountNumber,
+	}, nil
+}
@@ -0,0 +1,25 @@
+package cosmos
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type CosmosTransferTx struct {
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	Amount      string `json:"amount"` // denom 的最小单位
+	// 转账币种，为空时使用链的原生币种，IBC 资产为 ibc/<hash>
+	Denom string `json:"denom"`
+	Memo  string `json:"memo"`
+	// 压缩公钥，为空时使用链上账户的公钥（账户发出过交易后才有）
+	PublicKey string `json:"public_key"`
+	// 为 0 时通过模拟估算
+	GasLimit uint64 `json:"gas_limit"`
+	// 手续费（原生币种最小单位），为空时按 gas_limit × gas_price 计算
+	FeeAmount string `json:"fee_amount"`
+}
+
+// 交易中的 MsgSend 转账，每个币种一条
+type Transfer struct {
+	Denom  string `json:"denom"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount string `json:"amount"`
+}
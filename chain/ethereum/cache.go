@@ -0,0 +1,562 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/store"
+	"chain-account/config"
+)
+
+const (
+	defaultCacheSize          = 10000
+	defaultCacheDiskSize      = 50000
+	defaultCacheConfirmations = 64
+	cacheRefreshInterval      = 12 * time.Second
+	defaultCacheStatsInterval = 300 * time.Second
+	cacheOrderPrefix          = "order/" // 磁盘缓存的写入顺序索引，key 为 order/<写入序号>
+)
+
+// 缓存的数据类型，用于统计命中率
+const (
+	CacheKindHeader   = "header"
+	CacheKindBlock    = "block"
+	CacheKindReceipt  = "receipt"
+	CacheKindReceipts = "receipts"
+	CacheKindTx       = "tx"
+	CacheKindCode     = "code"
+)
+
+var cacheKinds = []string{CacheKindHeader, CacheKindBlock, CacheKindReceipt, CacheKindReceipts, CacheKindTx, CacheKindCode}
+
+// 缓存条目，Canonical 为 true 的条目依赖 Height 所在区块仍在主链上，回滚时失效
+type cacheEntry struct {
+	Height    uint64          `json:"height"`
+	Canonical bool            `json:"canonical"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// 磁盘缓存条目的索引，保存在 order/<Seq> 下，重启时不读取条目数据即可恢复淘汰顺序和回滚失效所需的高度
+type diskSlot struct {
+	Key       string `json:"key"`
+	Seq       uint64 `json:"seq"`
+	Height    uint64 `json:"height"`
+	Canonical bool   `json:"canonical"`
+}
+
+// 命中统计
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+type cacheCounter struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// 不可变数据缓存：在 IEth 前缓存按 hash 查询的区块、已最终确认区块的区块头、区块、收据和交易，以及合约代码；
+// latest/pending 等会变化的数据不缓存。内存中按 LRU 淘汰，开启持久化时同时写入磁盘
+type CachedEth struct {
+	IEth
+	mem           *lru.Cache[string, cacheEntry]
+	disk          store.Store
+	diskSize      int
+	confirmations uint64
+
+	dmu       sync.Mutex
+	diskSeq   uint64
+	diskKeys  []string            // 磁盘缓存的写入顺序，超过 diskSize 时先删除最早写入的
+	diskSlots map[string]diskSlot // 磁盘中已有的条目，数据不可变，重复写入时跳过
+
+	fmu          sync.Mutex
+	finalized    uint64
+	refreshed    time.Time
+	hasFinalized bool
+
+	counters      map[string]*cacheCounter
+	statsInterval time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// 初始化缓存，disk 为 nil 时只缓存在内存中；disk 需要支持按 key 增量写入（如 store.LevelStore）
+func NewCachedEth(client IEth, conf config.Cache, disk store.Store) (*CachedEth, error) {
+	if conf.Size <= 0 {
+		conf.Size = defaultCacheSize
+	}
+	if conf.DiskSize <= 0 {
+		conf.DiskSize = defaultCacheDiskSize
+	}
+	if conf.Confirmations == 0 {
+		conf.Confirmations = defaultCacheConfirmations
+	}
+	statsInterval := time.Duration(conf.StatsInterval) * time.Second
+	if statsInterval == 0 {
+		statsInterval = defaultCacheStatsInterval
+	}
+	c := &CachedEth{
+		IEth:          client,
+		mem:           lru.NewCache[string, cacheEntry](conf.Size),
+		disk:          disk,
+		diskSize:      conf.DiskSize,
+		confirmations: conf.Confirmations,
+		counters:      make(map[string]*cacheCounter),
+		statsInterval: statsInterval,
+		stop:          make(chan struct{}),
+	}
+	for _, kind := range cacheKinds {
+		c.counters[kind] = &cacheCounter{}
+	}
+	if disk != nil {
+		if err := c.loadDisk(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// 从写入顺序索引恢复磁盘缓存的条目，删除没有索引的条目（旧版本写入或写入索引前退出）
+func (c *CachedEth) loadDisk() error {
+	c.diskSlots = make(map[string]diskSlot)
+	orders, err := c.disk.Keys(cacheOrderPrefix)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		var slot diskSlot
+		ok, err := c.disk.Get(order, &slot)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		c.diskKeys = append(c.diskKeys, slot.Key)
+		c.diskSlots[slot.Key] = slot
+		c.diskSeq = max(c.diskSeq, slot.Seq)
+	}
+	keys, err := c.disk.Keys("")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, ok := c.diskSlots[key]; ok || strings.HasPrefix(key, cacheOrderPrefix) {
+			continue
+		}
+		if err := c.disk.Delete(key); err != nil {
+			return err
+		}
+	}
+	c.evict()
+	return nil
+}
+
+func orderKey(seq uint64) string {
+	return fmt.Sprintf("%s%020d", cacheOrderPrefix, seq)
+}
+
+// 按数据类型返回命中统计
+func (c *CachedEth) Stats() map[string]CacheStats {
+	stats := make(map[string]CacheStats, len(c.counters))
+	for kind, counter := range c.counters {
+		stats[kind] = CacheStats{Hits: counter.hits.Load(), Misses: counter.misses.Load()}
+	}
+	return stats
+}
+
+// 启动后台协程，每隔 stats_interval 输出一次命中统计日志
+func (c *CachedEth) Start() {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.statsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				c.logStats()
+			}
+		}
+	}()
+}
+
+// 停止后台协程
+func (c *CachedEth) Stop() {
+	close(c.stop)
+	c.wg.Wait()
+}
+
+// 按数据类型输出命中数/未命中数
+func (c *CachedEth) logStats() {
+	stats := c.Stats()
+	ctx := make([]interface{}, 0, len(cacheKinds)*2+2)
+	for _, kind := range cacheKinds {
+		ctx = append(ctx, kind, fmt.Sprintf("%d/%d", stats[kind].Hits, stats[kind].Misses))
+	}
+	c.dmu.Lock()
+	ctx = append(ctx, "disk", len(c.diskKeys))
+	c.dmu.Unlock()
+	log.Info("cache stats (hits/misses)", ctx...)
+}
+
+// 被包装的客户端的节点能力
+func (c *CachedEth) Capabilities() Capabilities {
+	return capabilities(c.IEth)
+}
+
+// 通过区块Hash获取区块头
+func (c *CachedEth) BlockHeaderByHash(hash common.Hash) (*types.Header, error) {
+	key := "header/h/" + hash.Hex()
+	var header *types.Header
+	if c.get(CacheKindHeader, key, &header) {
+		return header, nil
+	}
+	header, err := c.IEth.BlockHeaderByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, header, 0, false)
+	return header, nil
+}
+
+// 通过区块号获取区块头，只缓存已最终确认的区块
+func (c *CachedEth) BlockHeaderByNumber(number *big.Int) (*types.Header, error) {
+	height, ok := c.finalizedHeight(number)
+	if !ok {
+		return c.IEth.BlockHeaderByNumber(number)
+	}
+	key := "header/n/" + strconv.FormatUint(height, 10)
+	var header *types.Header
+	if c.get(CacheKindHeader, key, &header) {
+		return header, nil
+	}
+	header, err := c.IEth.BlockHeaderByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	c.checkParent(height, header.ParentHash)
+	c.put(key, header, height, true)
+	c.put("header/h/"+header.Hash().Hex(), header, 0, false)
+	return header, nil
+}
+
+// 通过区块Hash获取区块数据
+func (c *CachedEth) BlockByHash(hash common.Hash) (*RpcBlock, error) {
+	key := "block/h/" + hash.Hex()
+	var block *RpcBlock
+	if c.get(CacheKindBlock, key, &block) {
+		return block, nil
+	}
+	block, err := c.IEth.BlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, block, 0, false)
+	return block, nil
+}
+
+// 通过区块号获取区块数据，只缓存已最终确认的区块
+func (c *CachedEth) BlockByNumber(number *big.Int) (*RpcBlock, error) {
+	height, ok := c.finalizedHeight(number)
+	if !ok {
+		return c.IEth.BlockByNumber(number)
+	}
+	key := "block/n/" + strconv.FormatUint(height, 10)
+	var block *RpcBlock
+	if c.get(CacheKindBlock, key, &block) {
+		return block, nil
+	}
+	block, err := c.IEth.BlockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, block, height, true)
+	c.put("block/h/"+block.Hash.Hex(), block, 0, false)
+	return block, nil
+}
+
+// 获取交易收据，只缓存已最终确认区块中的收据
+func (c *CachedEth) TxReceiptByHash(hash common.Hash) (*types.Receipt, error) {
+	key := "receipt/" + hash.Hex()
+	var receipt *types.Receipt
+	if c.get(CacheKindReceipt, key, &receipt) {
+		return receipt, nil
+	}
+	receipt, err := c.IEth.TxReceiptByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if height, ok := c.finalizedHeight(receipt.BlockNumber); ok {
+		c.put(key, receipt, height, true)
+	}
+	return receipt, nil
+}
+
+// 获取区块内全部交易的收据，只缓存已最终确认的区块
+func (c *CachedEth) BlockReceipts(number *big.Int) ([]*types.Receipt, error) {
+	height, ok := c.finalizedHeight(number)
+	if !ok {
+		return c.IEth.BlockReceipts(number)
+	}
+	key := "receipts/n/" + strconv.FormatUint(height, 10)
+	var receipts []*types.Receipt
+	if c.get(CacheKindReceipts, key, &receipts) {
+		return receipts, nil
+	}
+	receipts, err := c.IEth.BlockReceipts(number)
+	if err != nil {
+		return nil, err
+	}
+	c.put(key, receipts, height, true)
+	return receipts, nil
+}
+
+// 按Hash获取交易详情，交易本身不带区块信息，只在其收据已缓存（已最终确认）时缓存，避免缓存 pending 交易
+func (c *CachedEth) TxByHash(hash common.Hash) (*types.Transaction, error) {
+	key := "tx/" + hash.Hex()
+	var tx *types.Transaction
+	if c.get(CacheKindTx, key, &tx) {
+		return tx, nil
+	}
+	tx, err := c.IEth.TxByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if receipt, ok := c.peek("receipt/" + hash.Hex()); ok {
+		c.put(key, tx, receipt.Height, true)
+	}
+	return tx, nil
+}
+
+// 获取合约字节码，只缓存合约账户（普通账户之后可能部署合约）
+func (c *CachedEth) EthGetCode(account common.Address) (string, error) {
+	key := "code/" + account.Hex()
+	var code string
+	if c.get(CacheKindCode, key, &code) {
+		return code, nil
+	}
+	code, err := c.IEth.EthGetCode(account)
+	if err != nil {
+		return "", err
+	}
+	if code == "contract" {
+		c.put(key, code, 0, false)
+	}
+	return code, nil
+}
+
+// 使 from 及以上高度的区块相关缓存失效，按 hash 缓存的区块和合约代码不受影响
+func (c *CachedEth) Invalidate(from uint64) {
+	c.fmu.Lock()
+	defer c.fmu.Unlock()
+	c.invalidate(from)
+	// 下次查询时重新获取最终确认高度
+	if c.finalized >= from {
+		c.hasFinalized, c.refreshed = false, time.Time{}
+	}
+}
+
+func (c *CachedEth) invalidate(from uint64) {
+	removed := 0
+	for _, key := range c.mem.Keys() {
+		if entry, ok := c.mem.Peek(key); ok && entry.Canonical && entry.Height >= from {
+			c.mem.Remove(key)
+			removed++
+		}
+	}
+	if c.disk != nil {
+		c.dmu.Lock()
+		keys := c.diskKeys[:0]
+		for _, key := range c.diskKeys {
+			if slot := c.diskSlots[key]; slot.Canonical && slot.Height >= from {
+				c.deleteDisk(slot)
+				removed++
+				continue
+			}
+			keys = append(keys, key)
+		}
+		c.diskKeys = keys
+		c.dmu.Unlock()
+	}
+	log.Info("cache invalidated", "from", from, "removed", removed)
+}
+
+// 区块号已最终确认时返回高度，latest/pending 等标签和未确认的区块不缓存
+func (c *CachedEth) finalizedHeight(number *big.Int) (uint64, bool) {
+	if number == nil || number.Sign() < 0 || !number.IsUint64() {
+		return 0, false
+	}
+	finalized, ok := c.finalizedNumber()
+	return number.Uint64(), ok && number.Uint64() <= finalized
+}
+
+// 最终确认高度，每 cacheRefreshInterval 从节点刷新一次；节点不支持 finalized 标签时按确认数计算
+func (c *CachedEth) finalizedNumber() (uint64, bool) {
+	c.fmu.Lock()
+	defer c.fmu.Unlock()
+	if time.Since(c.refreshed) < cacheRefreshInterval {
+		return c.finalized, c.hasFinalized
+	}
+	c.refreshed = time.Now()
+
+	header, err := c.IEth.LatestFinalizedBlockHeader()
+	if errors.Is(err, ethereum.NotFound) {
+		latest, err := c.IEth.BlockHeaderByNumber(nil)
+		if err != nil {
+			log.Warn("get latest header for cache fail", "err", err)
+			return c.finalized, c.hasFinalized
+		}
+		if latest.Number.Uint64() < c.confirmations {
+			return c.finalized, c.hasFinalized
+		}
+		c.setFinalized(latest.Number.Uint64() - c.confirmations)
+		return c.finalized, c.hasFinalized
+	}
+	if err != nil {
+		log.Warn("get finalized header for cache fail", "err", err)
+		return c.finalized, c.hasFinalized
+	}
+	height := header.Number.Uint64()
+	// 缓存中同高度区块的 hash 对不上说明发生了回滚
+	if cached, ok := c.peekHeader(height); ok && cached.Hash() != header.Hash() {
+		log.Warn("cache detected reorg at finalized block", "height", height, "cached", cached.Hash(), "hash", header.Hash())
+		c.invalidate(0)
+	}
+	c.setFinalized(height)
+	return c.finalized, c.hasFinalized
+}
+
+// 更新最终确认高度，高度回退时使回退部分的缓存失效
+func (c *CachedEth) setFinalized(height uint64) {
+	if c.hasFinalized && height < c.finalized {
+		log.Warn("cache detected finalized height rollback", "from", c.finalized, "to", height)
+		c.invalidate(height + 1)
+	}
+	c.finalized, c.hasFinalized, c.refreshed = height, true, time.Now()
+}
+
+// 新缓存的区块与缓存中父区块的 hash 对不上说明发生了回滚
+func (c *CachedEth) checkParent(height uint64, parentHash common.Hash) {
+	if height == 0 {
+		return
+	}
+	if parent, ok := c.peekHeader(height - 1); ok && parent.Hash() != parentHash {
+		log.Warn("cache detected reorg", "height", height-1, "cached", parent.Hash(), "parent", parentHash)
+		c.Invalidate(0)
+	}
+}
+
+func (c *CachedEth) peekHeader(height uint64) (*types.Header, bool) {
+	entry, ok := c.peek("header/n/" + strconv.FormatUint(height, 10))
+	if !ok {
+		return nil, false
+	}
+	var header *types.Header
+	if err := json.Unmarshal(entry.Data, &header); err != nil || header == nil {
+		return nil, false
+	}
+	return header, true
+}
+
+// 查询缓存条目，不计入命中统计
+func (c *CachedEth) peek(key string) (cacheEntry, bool) {
+	if entry, ok := c.mem.Peek(key); ok {
+		return entry, true
+	}
+	if c.disk == nil {
+		return cacheEntry{}, false
+	}
+	var entry cacheEntry
+	ok, err := c.disk.Get(key, &entry)
+	if err != nil || !ok {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// 查询缓存并解码到 value，磁盘命中时放入内存
+func (c *CachedEth) get(kind, key string, value any) bool {
+	counter := c.counters[kind]
+	entry, ok := c.mem.Get(key)
+	if !ok && c.disk != nil {
+		if found, err := c.disk.Get(key, &entry); err == nil && found {
+			c.mem.Add(key, entry)
+			ok = true
+		}
+	}
+	if ok && json.Unmarshal(entry.Data, value) == nil {
+		counter.hits.Add(1)
+		return true
+	}
+	counter.misses.Add(1)
+	return false
+}
+
+func (c *CachedEth) put(key string, value any, height uint64, canonical bool) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Warn("marshal cache entry fail", "key", key, "err", err)
+		return
+	}
+	entry := cacheEntry{Height: height, Canonical: canonical, Data: data}
+	c.mem.Add(key, entry)
+	if c.disk == nil {
+		return
+	}
+
+	c.dmu.Lock()
+	defer c.dmu.Unlock()
+	if _, ok := c.diskSlots[key]; ok {
+		return
+	}
+	// 先写条目再写索引，中途退出时留下的无索引条目在重启时删除
+	if err := c.disk.Put(key, entry); err != nil {
+		log.Warn("write cache entry fail", "key", key, "err", err)
+		return
+	}
+	c.diskSeq++
+	slot := diskSlot{Key: key, Seq: c.diskSeq, Height: height, Canonical: canonical}
+	if err := c.disk.Put(orderKey(slot.Seq), slot); err != nil {
+		log.Warn("write cache order fail", "key", key, "err", err)
+		return
+	}
+	c.diskKeys = append(c.diskKeys, key)
+	c.diskSlots[key] = slot
+	c.evict()
+}
+
+// 调用方需持有 dmu，超过 diskSize 时删除最早写入的条目
+func (c *CachedEth) evict() {
+	for len(c.diskKeys) > c.diskSize {
+		c.deleteDisk(c.diskSlots[c.diskKeys[0]])
+		c.diskKeys = c.diskKeys[1:]
+	}
+}
+
+// 调用方需持有 dmu，删除条目及其索引，不修改 diskKeys
+func (c *CachedEth) deleteDisk(slot diskSlot) {
+	if err := c.disk.Delete(slot.Key); err != nil {
+		log.Warn("delete cache entry fail", "key", slot.Key, "err", err)
+	}
+	if err := c.disk.Delete(orderKey(slot.Seq)); err != nil {
+		log.Warn("delete cache order fail", "key", slot.Key, "err", err)
+	}
+	delete(c.diskSlots, slot.Key)
+}
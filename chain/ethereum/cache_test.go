@@ -0,0 +1,212 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"chain-account/common/store"
+	"chain-account/config"
+)
+
+// 在 fakeChain 基础上统计请求次数，并补充收据和合约代码
+type countingChain struct {
+	*fakeChain
+	calls    map[string]int
+	receipts map[common.Hash]uint64
+	codes    map[common.Address]string
+}
+
+func newCountingChain(length int, finalized uint64) *countingChain {
+	chain := newFakeChain(length)
+	chain.finalized = finalized
+	return &countingChain{
+		fakeChain: chain,
+		calls:     make(map[string]int),
+		receipts:  make(map[common.Hash]uint64),
+		codes:     make(map[common.Address]string),
+	}
+}
+
+func (f *countingChain) BlockHeaderByNumber(number *big.Int) (*types.Header, error) {
+	if number != nil {
+		f.calls["header"]++
+	}
+	return f.fakeChain.BlockHeaderByNumber(number)
+}
+
+func (f *countingChain) BlockHeaderByHash(hash common.Hash) (*types.Header, error) {
+	f.calls["headerByHash"]++
+	for _, header := range f.headers {
+		if header.Hash() == hash {
+			return header, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *countingChain) TxReceiptByHash(hash common.Hash) (*types.Receipt, error) {
+	f.calls["receipt"]++
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful, Logs: []*types.Log{}, BlockNumber: new(big.Int).SetUint64(f.receipts[hash])}, nil
+}
+
+func (f *countingChain) TxByHash(hash common.Hash) (*types.Transaction, error) {
+	f.calls["tx"]++
+	return types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 21000, GasPrice: big.NewInt(1), Value: big.NewInt(1)}), nil
+}
+
+func (f *countingChain) EthGetCode(account common.Address) (string, error) {
+	f.calls["code"]++
+	return f.codes[account], nil
+}
+
+func newTestCache(t *testing.T, chain IEth, disk store.Store, diskSize int) *CachedEth {
+	cache, err := NewCachedEth(chain, config.Cache{DiskSize: diskSize}, disk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cache
+}
+
+func Test_CachedEthFinalizedOnly(t *testing.T) {
+	chain := newCountingChain(10, 5)
+	cache := newTestCache(t, chain, nil, 0)
+
+	// 已最终确认的区块只请求一次
+	for i := 0; i < 3; i++ {
+		if header, err := cache.BlockHeaderByNumber(big.NewInt(3)); err != nil || header.Number.Uint64() != 3 {
+			t.Fatalf("BlockHeaderByNumber: %v %v", header, err)
+		}
+	}
+	if chain.calls["header"] != 1 {
+		t.Fatalf("expected 1 header call, got %d", chain.calls["header"])
+	}
+	// 未最终确认的区块和 latest 不缓存
+	cache.BlockHeaderByNumber(big.NewInt(8))
+	cache.BlockHeaderByNumber(big.NewInt(8))
+	cache.BlockHeaderByNumber(nil)
+	if chain.calls["header"] != 3 {
+		t.Fatalf("expected 3 header calls, got %d", chain.calls["header"])
+	}
+	// 按区块号获取时同时缓存了按 hash 的查询
+	if _, err := cache.BlockHeaderByHash(chain.headers[3].Hash()); err != nil || chain.calls["headerByHash"] != 0 {
+		t.Fatalf("BlockHeaderByHash: %v, calls %d", err, chain.calls["headerByHash"])
+	}
+	if stats := cache.Stats()[CacheKindHeader]; stats.Hits != 3 || stats.Misses != 1 {
+		t.Fatalf("unexpected header stats %+v", stats)
+	}
+
+	// 收据所在区块已最终确认时才缓存，交易在收据缓存后才缓存
+	finalizedTx, pendingTx := common.HexToHash("0x01"), common.HexToHash("0x02")
+	chain.receipts[finalizedTx], chain.receipts[pendingTx] = 4, 9
+	for i := 0; i < 2; i++ {
+		cache.TxReceiptByHash(finalizedTx)
+		cache.TxReceiptByHash(pendingTx)
+		cache.TxByHash(finalizedTx)
+		cache.TxByHash(pendingTx)
+	}
+	if chain.calls["receipt"] != 3 || chain.calls["tx"] != 3 {
+		t.Fatalf("unexpected receipt/tx calls %v", chain.calls)
+	}
+
+	// 只缓存合约账户
+	contract, eoa := common.HexToAddress("0xc0"), common.HexToAddress("0xe0")
+	chain.codes[contract], chain.codes[eoa] = "contract", "eoa"
+	for i := 0; i < 2; i++ {
+		cache.EthGetCode(contract)
+		cache.EthGetCode(eoa)
+	}
+	if chain.calls["code"] != 3 {
+		t.Fatalf("expected 3 code calls, got %d", chain.calls["code"])
+	}
+}
+
+func Test_CachedEthReorg(t *testing.T) {
+	chain := newCountingChain(10, 5)
+	cache := newTestCache(t, chain, nil, 0)
+	cache.BlockHeaderByNumber(big.NewInt(3))
+	cache.BlockHeaderByNumber(big.NewInt(5))
+	hashAt3 := chain.headers[3].Hash()
+
+	// 最终确认区块的 hash 变化时清空区块相关缓存，按 hash 的缓存保留
+	chain.headers = chain.headers[:2]
+	chain.extend(8, 1)
+	cache.refreshed = time.Time{}
+	header, err := cache.BlockHeaderByNumber(big.NewInt(3))
+	if err != nil || header.Hash() != chain.headers[3].Hash() || header.Hash() == hashAt3 {
+		t.Fatalf("expected refetched header after reorg, got %v %v", header, err)
+	}
+	if _, err := cache.BlockHeaderByHash(hashAt3); err != nil || chain.calls["headerByHash"] != 0 {
+		t.Fatalf("hash lookup should stay cached: %v, calls %d", err, chain.calls["headerByHash"])
+	}
+
+	// 按高度失效
+	cache.BlockHeaderByNumber(big.NewInt(4))
+	calls := chain.calls["header"]
+	cache.Invalidate(4)
+	cache.BlockHeaderByNumber(big.NewInt(3))
+	cache.BlockHeaderByNumber(big.NewInt(4))
+	if chain.calls["header"] != calls+1 {
+		t.Fatalf("expected only height 4 to be refetched, got %d calls", chain.calls["header"]-calls)
+	}
+}
+
+func Test_CachedEthDisk(t *testing.T) {
+	chain := newCountingChain(10, 8)
+	disk := store.NewMemoryStore()
+	cache := newTestCache(t, chain, disk, 3)
+	// 按 hash 缓存后再按高度查询，同一个 key 不重复写入
+	cache.BlockHeaderByHash(chain.headers[1].Hash())
+	cache.BlockHeaderByNumber(big.NewInt(1))
+	// 每个区块写入按高度和按 hash 两条，超过 disk_size 时删除最早写入的
+	cache.BlockHeaderByNumber(big.NewInt(2))
+	want := []string{"header/h/" + chain.headers[2].Hash().Hex(), "header/n/1", "header/n/2"}
+	if keys := cacheDataKeys(disk); fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Fatalf("unexpected disk keys %v", keys)
+	}
+	if orders, _ := disk.Keys(cacheOrderPrefix); len(orders) != 3 {
+		t.Fatalf("unexpected order keys %v", orders)
+	}
+
+	// 重启后从磁盘读取，按写入顺序而不是 key 的字典序淘汰；没有索引的旧条目被删除
+	disk.Put("header/n/9", cacheEntry{Height: 9, Canonical: true})
+	restarted := newTestCache(t, chain, disk, 3)
+	calls := chain.calls["header"]
+	if header, err := restarted.BlockHeaderByNumber(big.NewInt(2)); err != nil || header.Number.Uint64() != 2 {
+		t.Fatalf("BlockHeaderByNumber: %v %v", header, err)
+	}
+	if chain.calls["header"] != calls {
+		t.Fatalf("expected disk hit")
+	}
+	restarted.BlockHeaderByNumber(big.NewInt(3))
+	want = []string{"header/h/" + chain.headers[2].Hash().Hex(), "header/h/" + chain.headers[3].Hash().Hex(), "header/n/3"}
+	if keys := cacheDataKeys(disk); fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Fatalf("unexpected disk keys %v", keys)
+	}
+
+	// 回滚失效只删除对应高度的条目和索引
+	restarted.Invalidate(3)
+	if keys := cacheDataKeys(disk); len(keys) != 2 {
+		t.Fatalf("unexpected disk keys %v", keys)
+	}
+	if orders, _ := disk.Keys(cacheOrderPrefix); len(orders) != 2 {
+		t.Fatalf("unexpected order keys %v", orders)
+	}
+}
+
+// 磁盘中的缓存条目，不含写入顺序索引
+func cacheDataKeys(disk store.Store) []string {
+	keys, _ := disk.Keys("")
+	var data []string
+	for _, key := range keys {
+		if !strings.HasPrefix(key, cacheOrderPrefix) {
+			data = append(data, key)
+		}
+	}
+	return data
+}
@@ -0,0 +1,337 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"chain-account/common/retry"
+	"chain-account/config"
+)
+
+const (
+	defaultMaxBatchSize = 100
+	logsRangeProbeStart = 100_000 // 探测 eth_getLogs 范围时首次查询的区块数，可以查询时不限制
+)
+
+// JSON-RPC 错误码：-32600 无效请求，-32601 方法不存在，-32602 参数无效
+const (
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// 可探测的单项能力，按位组合
+type Capability uint
+
+const (
+	CapBatch Capability = 1 << iota
+	CapBatchSize
+	CapLogsRange
+	CapBlockReceipts
+	CapFinalizedTag
+	CapEip1559
+)
+
+var capabilityNames = map[Capability]string{
+	CapBatch:         "batch",
+	CapBatchSize:     "maxBatchSize",
+	CapLogsRange:     "maxLogsRange",
+	CapBlockReceipts: "blockReceipts",
+	CapFinalizedTag:  "finalizedTag",
+	CapEip1559:       "eip1559",
+}
+
+func (c Capability) String() string {
+	var names []string
+	for item := CapBatch; item <= CapEip1559; item <<= 1 {
+		if c&item != 0 {
+			names = append(names, capabilityNames[item])
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// 节点 RPC 能力，EthClient 据此选择批量或逐个请求、拆分 eth_getLogs 查询范围和使用的区块标签
+type Capabilities struct {
+	Batch         bool   // 支持 JSON-RPC 批量请求
+	MaxBatchSize  int    // 单次批量请求的最大条数，不支持批量时为并发请求的分组大小
+	MaxLogsRange  uint64 // eth_getLogs 单次查询的最大区块数，为 0 时不限制
+	BlockReceipts bool   // 支持 eth_getBlockReceipts
+	FinalizedTag  bool   // 支持 finalized/safe 区块标签
+	Eip1559       bool   // 区块头包含 baseFeePerGas
+	// 探测时遇到超时、连接失败等临时错误、尚未确定的项，保持之前的值（首次探测时为默认值），稍后重新探测
+	Unknown Capability
+}
+
+// 能提供节点 RPC 能力的客户端
+type CapabilityProvider interface {
+	Capabilities() Capabilities
+}
+
+// 客户端的节点能力，不提供能力信息的客户端（如测试替身）按支持 EIP-1559 处理
+func capabilities(client IEth) Capabilities {
+	if provider, ok := client.(CapabilityProvider); ok {
+		return provider.Capabilities()
+	}
+	return Capabilities{Eip1559: true}
+}
+
+// 探测节点 RPC 能力，配置中已设置的项不再探测。节点明确返回方法不存在或参数无效时视为不支持，
+// 其他错误时该项记入 Unknown 并保持默认值：EIP-1559 默认支持，其余默认不支持
+func DetectCapabilities(ctx context.Context, client IRpc, conf config.Rpc) Capabilities {
+	caps := Capabilities{
+		MaxBatchSize: conf.MaxBatchSize,
+		MaxLogsRange: conf.MaxLogsRange,
+		Eip1559:      true,
+	}
+	if caps.MaxBatchSize <= 0 {
+		caps.MaxBatchSize = defaultMaxBatchSize
+	}
+	all := CapBatch | CapBatchSize | CapLogsRange | CapBlockReceipts | CapFinalizedTag | CapEip1559
+	return caps.detect(ctx, client, conf, all)
+}
+
+// 重新探测 Unknown 中的项，其余项保持不变
+func (c Capabilities) Redetect(ctx context.Context, client IRpc, conf config.Rpc) Capabilities {
+	return c.detect(ctx, client, conf, c.Unknown)
+}
+
+func (c Capabilities) detect(ctx context.Context, client IRpc, conf config.Rpc, which Capability) Capabilities {
+	c.Unknown &^= which
+	if which&CapBatch != 0 {
+		c.Batch = c.probe(ctx, CapBatch, c.Batch, conf.Batch, func(ctx context.Context) (bool, error) { return probeBatch(ctx, client) })
+	}
+	if which&CapBatchSize != 0 && conf.MaxBatchSize <= 0 {
+		// 批量请求是否支持尚未确定时，批量条数随其一起重新探测
+		switch {
+		case c.Unknown&CapBatch != 0:
+			c.Unknown |= CapBatchSize
+		case c.Batch:
+			size, err := probeWithTimeout(ctx, func(ctx context.Context) (int, error) { return probeBatchSize(ctx, client, defaultMaxBatchSize) })
+			if err != nil {
+				c.unknown(CapBatchSize, err)
+			} else {
+				c.MaxBatchSize = size
+			}
+		}
+	}
+	if which&CapLogsRange != 0 && conf.MaxLogsRange == 0 {
+		maxRange, err := probeWithTimeout(ctx, func(ctx context.Context) (uint64, error) { return probeLogsRange(ctx, client) })
+		if err != nil {
+			c.unknown(CapLogsRange, err)
+		} else {
+			c.MaxLogsRange = maxRange
+		}
+	}
+	if which&CapBlockReceipts != 0 {
+		c.BlockReceipts = c.probe(ctx, CapBlockReceipts, c.BlockReceipts, conf.BlockReceipts, func(ctx context.Context) (bool, error) { return probeBlockReceipts(ctx, client) })
+	}
+	if which&CapFinalizedTag != 0 {
+		c.FinalizedTag = c.probe(ctx, CapFinalizedTag, c.FinalizedTag, conf.FinalizedTag, func(ctx context.Context) (bool, error) { return probeFinalizedTag(ctx, client) })
+	}
+	if which&CapEip1559 != 0 {
+		c.Eip1559 = c.probe(ctx, CapEip1559, c.Eip1559, conf.Eip1559, func(ctx context.Context) (bool, error) { return probeEip1559(ctx, client) })
+	}
+	log.Info("rpc capabilities", "batch", c.Batch, "maxBatchSize", c.MaxBatchSize, "maxLogsRange", c.MaxLogsRange,
+		"blockReceipts", c.BlockReceipts, "finalizedTag", c.FinalizedTag, "eip1559", c.Eip1559, "unknown", c.Unknown)
+	return c
+}
+
+// 配置中已设置时直接使用配置；探测出错时记入 Unknown 并保持当前值，只有节点明确拒绝时才降级
+func (c *Capabilities) probe(ctx context.Context, item Capability, current bool, override *bool, probe func(context.Context) (bool, error)) bool {
+	if override != nil {
+		return *override
+	}
+	supported, err := probeWithTimeout(ctx, probe)
+	if err != nil {
+		c.unknown(item, err)
+		return current
+	}
+	return supported
+}
+
+func (c *Capabilities) unknown(item Capability, err error) {
+	log.Warn("rpc capability probe fail, retry later", "capability", item, "err", err)
+	c.Unknown |= item
+}
+
+// 每项探测单独计算超时，避免前面的探测耗尽后面的时间
+func probeWithTimeout[T any](ctx context.Context, probe func(context.Context) (T, error)) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
+	defer cancel()
+	return probe(ctx)
+}
+
+// 节点明确表示不支持：JSON-RPC 方法不存在（-32601）或参数无效（-32602）
+func isUnsupported(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.ErrorCode() == rpcMethodNotFound || rpcErr.ErrorCode() == rpcInvalidParams
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "method not found") || strings.Contains(message, "does not exist/is not available")
+}
+
+// 节点明确拒绝批量请求：除方法不存在和参数无效外，返回无效请求（-32600）或 429、408 以外的 4xx 状态码
+func isBatchRejected(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == rpcInvalidRequest {
+		return true
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusBadRequest && httpErr.StatusCode < http.StatusInternalServerError &&
+			!retry.IsRetryable(err)
+	}
+	return isUnsupported(err)
+}
+
+// 限流类错误，不是批量条数或查询范围超限
+var rateLimitMessages = []string{"rate limit", "too many requests", "request count", "per second", "capacity"}
+
+// 批量条数或日志查询范围、结果条数超过节点限制
+var limitMessages = []string{"too many", "too large", "exceed", "limit", "range", "more than", "max"}
+
+func isLimitError(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	message := strings.ToLower(err.Error())
+	for _, rateLimit := range rateLimitMessages {
+		if strings.Contains(message, rateLimit) {
+			return false
+		}
+	}
+	for _, limit := range limitMessages {
+		if strings.Contains(message, limit) {
+			return true
+		}
+	}
+	return false
+}
+
+// 批量请求两次 eth_chainId，部分节点直接拒绝批量请求或只返回第一条结果
+func probeBatch(ctx context.Context, client IRpc) (bool, error) {
+	var first, second hexutil.Big
+	batchElems := []rpc.BatchElem{
+		{Method: "eth_chainId", Result: &first},
+		{Method: "eth_chainId", Result: &second},
+	}
+	if err := client.BatchCallContext(ctx, batchElems); err != nil {
+		if isBatchRejected(err) {
+			log.Warn("rpc batch not supported", "err", err)
+			return false, nil
+		}
+		return false, err
+	}
+	for _, batchElem := range batchElems {
+		if batchElem.Error != nil && !isBatchRejected(batchElem.Error) {
+			return false, batchElem.Error
+		}
+	}
+	return batchElems[0].Error == nil && batchElems[1].Error == nil && first.ToInt().Cmp(second.ToInt()) == 0, nil
+}
+
+// 从 size 条 eth_chainId 开始批量请求，节点返回条数超限的错误时减半重试
+func probeBatchSize(ctx context.Context, client IRpc, size int) (int, error) {
+	for ; size > 1; size /= 2 {
+		results := make([]hexutil.Big, size)
+		batchElems := make([]rpc.BatchElem, size)
+		for i := range batchElems {
+			batchElems[i] = rpc.BatchElem{Method: "eth_chainId", Result: &results[i]}
+		}
+		err := client.BatchCallContext(ctx, batchElems)
+		for i := 0; err == nil && i < len(batchElems); i++ {
+			err = batchElems[i].Error
+		}
+		switch {
+		case err == nil:
+			return size, nil
+		case !isLimitError(err):
+			return 0, err
+		}
+	}
+	return 1, nil
+}
+
+// 从 logsRangeProbeStart 个区块开始查询零地址的日志，节点返回范围或结果条数超限的错误时减半重试；
+// 首次查询成功时不限制，节点不支持 eth_getLogs 时也不限制
+func probeLogsRange(ctx context.Context, client IRpc) (uint64, error) {
+	var latest hexutil.Uint64
+	if err := client.CallContext(ctx, &latest, "eth_blockNumber"); err != nil {
+		if isUnsupported(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	for span := uint64(logsRangeProbeStart); span > 0; span /= 2 {
+		from := uint64(0)
+		if uint64(latest)+1 > span {
+			from = uint64(latest) + 1 - span
+		}
+		var logs []json.RawMessage
+		err := client.CallContext(ctx, &logs, "eth_getLogs", map[string]interface{}{
+			"fromBlock": hexutil.EncodeUint64(from),
+			"toBlock":   hexutil.EncodeUint64(uint64(latest)),
+			"address":   common.Address{},
+		})
+		switch {
+		case err == nil && span == logsRangeProbeStart:
+			return 0, nil
+		case err == nil:
+			return span, nil
+		case isLimitError(err):
+			continue
+		case isUnsupported(err):
+			return 0, nil
+		default:
+			return 0, err
+		}
+	}
+	return 1, nil
+}
+
+func probeBlockReceipts(ctx context.Context, client IRpc) (bool, error) {
+	var receipts []json.RawMessage
+	if err := client.CallContext(ctx, &receipts, "eth_getBlockReceipts", "latest"); err != nil {
+		return false, unsupportedOrError(err)
+	}
+	return receipts != nil, nil
+}
+
+func probeFinalizedTag(ctx context.Context, client IRpc) (bool, error) {
+	var header *struct {
+		Number *hexutil.Big `json:"number"`
+	}
+	if err := client.CallContext(ctx, &header, "eth_getBlockByNumber", "finalized", false); err != nil {
+		return false, unsupportedOrError(err)
+	}
+	return header != nil && header.Number != nil, nil
+}
+
+func probeEip1559(ctx context.Context, client IRpc) (bool, error) {
+	var header *struct {
+		BaseFee *hexutil.Big `json:"baseFeePerGas"`
+	}
+	if err := client.CallContext(ctx, &header, "eth_getBlockByNumber", "latest", false); err != nil {
+		return false, unsupportedOrError(err)
+	}
+	return header != nil && header.BaseFee != nil, nil
+}
+
+// 节点明确表示不支持时返回 nil，探测结果为不支持；其他错误原样返回，该项稍后重新探测
+func unsupportedOrError(err error) error {
+	if isUnsupported(err) {
+		return nil
+	}
+	return err
+}
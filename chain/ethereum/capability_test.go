@@ -0,0 +1,355 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+// 模拟节点 RPC，按方法名返回 JSON 结果或 errs 中的错误，batch 为 false 时拒绝批量请求；
+// failures 为各高度 eth_getBlockByNumber 剩余的失败次数，delay 为每次请求的耗时，
+// maxBatch、maxLogsRange 不为 0 时超过的批量请求和 eth_getLogs 查询返回超限错误
+type fakeRpc struct {
+	batch        bool
+	latest       uint64
+	responses    map[string]string
+	errs         map[string]error
+	failures     map[uint64]int
+	delay        time.Duration
+	maxBatch     int
+	maxLogsRange uint64
+
+	mu          sync.Mutex
+	batches     []int
+	calls       []string
+	inflight    int
+	maxInflight int
+}
+
+func (f *fakeRpc) Close() {}
+
+func (f *fakeRpc) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	f.mu.Lock()
+	f.calls = append(f.calls, method)
+	f.inflight++
+	f.maxInflight = max(f.maxInflight, f.inflight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inflight--
+		f.mu.Unlock()
+	}()
+	time.Sleep(f.delay)
+
+	f.mu.Lock()
+	err := f.errs[method]
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	response, ok := f.responses[method]
+	switch method {
+	case "eth_getBlockByNumber":
+		number, err := hexutil.DecodeUint64(fmt.Sprint(args[0]))
+		if args[0] == "latest" {
+			number, err = f.latest, nil
+		}
+		if err != nil {
+			break
+		}
+		if number > f.latest {
+			return json.Unmarshal([]byte("null"), result)
+		}
+		f.mu.Lock()
+		failed := f.failures[number] > 0
+		if failed {
+			f.failures[number]--
+		}
+		f.mu.Unlock()
+		if failed {
+			return errors.New("request timeout")
+		}
+		response, ok = testHeaderJson(number), true
+	case "eth_getLogs":
+		query := args[0].(map[string]interface{})
+		if f.maxLogsRange > 0 {
+			from, _ := hexutil.DecodeUint64(fmt.Sprint(query["fromBlock"]))
+			to, _ := hexutil.DecodeUint64(fmt.Sprint(query["toBlock"]))
+			if to-from+1 > f.maxLogsRange {
+				return &testRpcError{-32005, fmt.Sprintf("block range is too large, max %d", f.maxLogsRange)}
+			}
+		}
+		response, ok = fmt.Sprintf(`[{"address":"0x0000000000000000000000000000000000000001","topics":[],"data":"0x","blockNumber":%q,"transactionHash":"0x%064x","transactionIndex":"0x0","blockHash":"0x%064x","logIndex":"0x0","removed":false}]`, query["fromBlock"], 1, 1), true
+	}
+	if !ok {
+		return errors.New("the method " + method + " does not exist/is not available")
+	}
+	return json.Unmarshal([]byte(response), result)
+}
+
+func (f *fakeRpc) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	if !f.batch {
+		return &testRpcError{-32600, "batch requests are not supported"}
+	}
+	if f.maxBatch > 0 && len(b) > f.maxBatch {
+		return &testRpcError{-32600, "batch too large"}
+	}
+	f.mu.Lock()
+	f.batches = append(f.batches, len(b))
+	f.mu.Unlock()
+	for i := range b {
+		b[i].Error = f.CallContext(ctx, b[i].Result, b[i].Method, b[i].Args...)
+	}
+	return nil
+}
+
+type testRpcError struct {
+	code    int
+	message string
+}
+
+func (e *testRpcError) Error() string  { return e.message }
+func (e *testRpcError) ErrorCode() int { return e.code }
+
+func testHeaderJson(number uint64) string {
+	return fmt.Sprintf(`{"parentHash":"0x0000000000000000000000000000000000000000000000000000000000000000","sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","miner":"0x0000000000000000000000000000000000000000","stateRoot":"0x0000000000000000000000000000000000000000000000000000000000000000","transactionsRoot":"0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421","receiptsRoot":"0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421","logsBloom":"0x%0512x","difficulty":"0x0","number":"%s","gasLimit":"0x1c9c380","gasUsed":"0x0","timestamp":"0x0","extraData":"0x","mixHash":"0x0000000000000000000000000000000000000000000000000000000000000000","nonce":"0x0000000000000000","baseFeePerGas":"0x7"}`,
+		0, hexutil.EncodeUint64(number))
+}
+
+func Test_DetectCapabilities(t *testing.T) {
+	node := &fakeRpc{
+		batch:  true,
+		latest: 10,
+		responses: map[string]string{
+			"eth_chainId":          `"0x1"`,
+			"eth_getBlockReceipts": `[]`,
+		},
+	}
+	caps := DetectCapabilities(context.Background(), node, config.Rpc{MaxLogsRange: 500})
+	want := Capabilities{Batch: true, MaxBatchSize: defaultMaxBatchSize, MaxLogsRange: 500, BlockReceipts: true, FinalizedTag: false, Eip1559: true}
+	if caps != want {
+		t.Fatalf("unexpected capabilities %+v", caps)
+	}
+
+	// 配置中设置的项不再探测
+	disabled := false
+	node = &fakeRpc{responses: map[string]string{}}
+	caps = DetectCapabilities(context.Background(), node, config.Rpc{Batch: &disabled, MaxBatchSize: 20, MaxLogsRange: 1000,
+		BlockReceipts: &disabled, FinalizedTag: &disabled, Eip1559: &disabled})
+	if caps.Batch || caps.MaxBatchSize != 20 || len(node.calls) != 0 {
+		t.Fatalf("unexpected capabilities %+v, calls %v", caps, node.calls)
+	}
+}
+
+func Test_DetectCapabilityLimits(t *testing.T) {
+	node := &fakeRpc{
+		batch:        true,
+		latest:       200_000,
+		maxBatch:     30,
+		maxLogsRange: 5000,
+		responses: map[string]string{
+			"eth_chainId":     `"0x1"`,
+			"eth_blockNumber": `"0x30d40"`,
+		},
+	}
+	caps := DetectCapabilities(context.Background(), node, config.Rpc{})
+	// 批量条数 100 -> 50 -> 25，日志范围 100000 减半到 3125
+	if caps.MaxBatchSize != 25 || caps.MaxLogsRange != 3125 || caps.Unknown != 0 {
+		t.Fatalf("unexpected capabilities %+v", caps)
+	}
+
+	// 不支持批量请求时不探测批量条数
+	node = &fakeRpc{latest: 10, responses: map[string]string{"eth_blockNumber": `"0xa"`}}
+	caps = DetectCapabilities(context.Background(), node, config.Rpc{})
+	if caps.Batch || caps.MaxBatchSize != defaultMaxBatchSize || caps.MaxLogsRange != 0 || caps.Unknown != 0 {
+		t.Fatalf("unexpected capabilities %+v", caps)
+	}
+}
+
+func Test_DetectCapabilitiesTransient(t *testing.T) {
+	// 超时、连接失败等临时错误不视为不支持，记入 Unknown 等待重新探测
+	timeout := errors.New("request timeout")
+	node := &fakeRpc{
+		batch:  true,
+		latest: 10,
+		responses: map[string]string{
+			"eth_chainId":          `"0x1"`,
+			"eth_blockNumber":      `"0xa"`,
+			"eth_getBlockReceipts": `[]`,
+		},
+		errs: map[string]error{
+			"eth_chainId":          timeout,
+			"eth_getBlockReceipts": errors.New("connection refused"),
+			"eth_getBlockByNumber": timeout,
+		},
+	}
+	caps := DetectCapabilities(context.Background(), node, config.Rpc{})
+	want := CapBatch | CapBatchSize | CapBlockReceipts | CapFinalizedTag | CapEip1559
+	if caps.Unknown != want || caps.Batch || caps.BlockReceipts || !caps.Eip1559 {
+		t.Fatalf("unexpected capabilities %+v", caps)
+	}
+
+	// 再次探测仍失败时保持之前的值，节点明确拒绝之前不降级
+	caps.Eip1559 = false
+	caps = caps.Redetect(context.Background(), node, config.Rpc{})
+	if caps.Unknown != want || caps.Eip1559 {
+		t.Fatalf("unexpected capabilities %+v", caps)
+	}
+	caps.Eip1559 = true
+
+	// 节点恢复后重新探测，finalized 标签返回方法不存在时才视为不支持
+	node.mu.Lock()
+	node.errs = map[string]error{}
+	node.mu.Unlock()
+	caps = caps.Redetect(context.Background(), node, config.Rpc{})
+	if caps.Unknown != 0 || !caps.Batch || caps.MaxBatchSize != defaultMaxBatchSize || !caps.BlockReceipts || caps.FinalizedTag || !caps.Eip1559 {
+		t.Fatalf("unexpected capabilities %+v", caps)
+	}
+
+	// EthClient 按间隔在后台重新探测
+	node.errs = map[string]error{"eth_getBlockByNumber": timeout}
+	client := &EthClient{rpc: node, caps: DetectCapabilities(context.Background(), node, config.Rpc{})}
+	if client.Capabilities().Unknown != CapFinalizedTag|CapEip1559 {
+		t.Fatalf("unexpected capabilities %+v", client.Capabilities())
+	}
+	node.mu.Lock()
+	node.errs = map[string]error{}
+	node.mu.Unlock()
+	deadline := time.Now().Add(time.Second)
+	for client.Capabilities().Unknown != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if caps := client.Capabilities(); caps.Unknown != 0 || !caps.Eip1559 {
+		t.Fatalf("expected capabilities reprobed, got %+v", caps)
+	}
+}
+
+func Test_BlockHeadersByRangeCapabilities(t *testing.T) {
+	// 支持批量请求时按 MaxBatchSize 分批
+	node := &fakeRpc{batch: true, latest: 1000}
+	client := &EthClient{rpc: node, caps: Capabilities{Batch: true, MaxBatchSize: 4}}
+	headers, err := client.BlockHeadersByRange(big.NewInt(1), big.NewInt(10))
+	if err != nil || len(headers) != 10 || headers[9].Number.Uint64() != 10 {
+		t.Fatalf("BlockHeadersByRange: %v %d", err, len(headers))
+	}
+	sort.Ints(node.batches)
+	if fmt.Sprint(node.batches) != "[2 4 4]" {
+		t.Fatalf("unexpected batches %v", node.batches)
+	}
+
+	// 不支持批量请求时逐个请求，超过最新高度的部分被截断
+	node = &fakeRpc{latest: 7}
+	client = &EthClient{rpc: node, caps: Capabilities{MaxBatchSize: 3}}
+	headers, err = client.BlockHeadersByRange(big.NewInt(1), big.NewInt(10))
+	if err != nil || len(headers) != 7 || len(node.batches) != 0 || len(node.calls) != 10 {
+		t.Fatalf("BlockHeadersByRange: %v %d, calls %d", err, len(headers), len(node.calls))
+	}
+	for i, header := range headers {
+		if header.Number.Uint64() != uint64(i+1) {
+			t.Fatalf("unexpected header order at %d: %d", i, header.Number.Uint64())
+		}
+	}
+}
+
+func Test_FilterLogsCapabilities(t *testing.T) {
+	node := &fakeRpc{latest: 1000}
+	client := &EthClient{rpc: node, caps: Capabilities{MaxBatchSize: defaultMaxBatchSize, MaxLogsRange: 100}}
+	logs, err := client.FilterLogs(ethereum.FilterQuery{
+		FromBlock: big.NewInt(1),
+		ToBlock:   big.NewInt(250),
+		Addresses: []common.Address{common.HexToAddress("0x01")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 拆分为 [1,100] [101,200] [201,250] 三次查询
+	if len(logs.Logs) != 3 || logs.Logs[1].BlockNumber != 101 || logs.Logs[2].BlockNumber != 201 {
+		t.Fatalf("unexpected logs %+v", logs.Logs)
+	}
+	if logs.ToBlockHeader.Number.Uint64() != 250 {
+		t.Fatalf("unexpected to block header %v", logs.ToBlockHeader.Number)
+	}
+
+	// 未指定结束区块时不拆分
+	queries := client.splitLogsQuery(ethereum.FilterQuery{FromBlock: big.NewInt(1)})
+	if len(queries) != 1 {
+		t.Fatalf("unexpected queries %d", len(queries))
+	}
+}
+
+func Test_CapabilityFallbacks(t *testing.T) {
+	node := &fakeRpc{responses: map[string]string{"eth_gasPrice": `"0x64"`}}
+	client := &EthClient{rpc: node, caps: Capabilities{}}
+	// 不支持 EIP-1559 时优先费即为 gasPrice
+	tip, err := client.SuggestGasTipCap()
+	if err != nil || tip.Int64() != 100 {
+		t.Fatalf("SuggestGasTipCap: %v %v", tip, err)
+	}
+	// 不支持 finalized 标签时不请求节点
+	if _, err := client.LatestFinalizedBlockHeader(); !errors.Is(err, ethereum.NotFound) || len(node.calls) != 1 {
+		t.Fatalf("LatestFinalizedBlockHeader: %v, calls %v", err, node.calls)
+	}
+}
+
+// 能力可切换的节点，eip1559 为 false 时不支持 EIP-1559
+type legacyNode struct {
+	IEth
+	eip1559 bool
+}
+
+func (n *legacyNode) Capabilities() Capabilities {
+	return Capabilities{Eip1559: n.eip1559}
+}
+
+func Test_BuildLegacyTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	txJson, _ := json.Marshal(Eip1559DynamicFeeTx{
+		ChainId:              "56",
+		Nonce:                3,
+		FromAddress:          from.Hex(),
+		ToAddress:            "0x0000000000000000000000000000000000000002",
+		GasLimit:             21000,
+		MaxFeePerGas:         "5000000000",
+		MaxPriorityFeePerGas: "5000000000",
+		Amount:               "7",
+	})
+	base64Tx := base64.StdEncoding.EncodeToString(txJson)
+	node := &legacyNode{}
+	adaptor := &ChainAdaptor{EthClient: &CachedEth{IEth: node}}
+
+	unSigned, err := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64Tx})
+	if err != nil || unSigned.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("BuildUnSignTransaction: %v %v", unSigned, err)
+	}
+	signature, _ := crypto.Sign(common.FromHex(unSigned.UnSignTx), key)
+	// 构建未签名交易后重新探测到支持 EIP-1559，签名交易仍按 base64_tx 中记录的类型构建
+	node.eip1559 = true
+	signed, err := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.Base64Tx, Signature: hex.EncodeToString(signature)})
+	if err != nil || signed.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("BuildSignedTransaction: %v %v", signed, err)
+	}
+	tx, err := decodeRawTx(signed.SignedTx)
+	if err != nil || tx.Type() != types.LegacyTxType || tx.GasPrice().Int64() != 5000000000 || tx.Nonce() != 3 || tx.ChainId().Int64() != 56 {
+		t.Fatalf("expected legacy tx, got %v %v", tx, err)
+	}
+}
@@ -0,0 +1,314 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/config"
+)
+
+const (
+	defaultHedgePercentile = 95
+	hedgeWindowSize        = 128                   // 统计主节点耗时的样本数
+	hedgeMinSamples        = 20                    // 样本数不足时不发送对冲请求
+	hedgeMinDelay          = 20 * time.Millisecond // 对冲请求的最小等待时间
+	recentPruneSize        = 1024                  // 短时缓存超过该条数时清理过期条目
+)
+
+// 可合并、可对冲的只读方法，写操作（如 eth_sendRawTransaction）直接透传
+var readMethods = map[string]bool{
+	"eth_chainId":                          true,
+	"eth_blockNumber":                      true,
+	"eth_gasPrice":                         true,
+	"eth_maxPriorityFeePerGas":             true,
+	"eth_feeHistory":                       true,
+	"eth_getBlockByNumber":                 true,
+	"eth_getBlockByHash":                   true,
+	"eth_getBlockReceipts":                 true,
+	"eth_getTransactionByHash":             true,
+	"eth_getTransactionReceipt":            true,
+	"eth_getTransactionCount":              true,
+	"eth_getBalance":                       true,
+	"eth_getCode":                          true,
+	"eth_getStorageAt":                     true,
+	"eth_getProof":                         true,
+	"eth_getLogs":                          true,
+	"eth_call":                             true,
+	"eth_estimateGas":                      true,
+	"eth_getBlockTransactionCountByNumber": true,
+}
+
+// 不带区块参数、结果随最新区块变化的方法，和 latest 参数一样短时缓存
+var latestMethods = map[string]bool{
+	"eth_blockNumber":          true,
+	"eth_gasPrice":             true,
+	"eth_maxPriorityFeePerGas": true,
+}
+
+// 进行中的请求，结果以原始 JSON 共享给所有等待者
+type rpcCall struct {
+	done   chan struct{}
+	result json.RawMessage
+	err    error
+}
+
+type recentResult struct {
+	result  json.RawMessage
+	expires time.Time
+}
+
+// 包装 IRpc：合并相同的并发读请求，短时缓存 latest 结果，主节点较慢时向备用节点发送对冲请求
+type CoalescingRpc struct {
+	IRpc
+	hedge      IRpc
+	coalesce   bool
+	ttl        time.Duration
+	percentile float64
+
+	mu       sync.Mutex
+	inflight map[string]*rpcCall
+	recent   map[string]recentResult
+	latency  []time.Duration
+	next     int
+}
+
+// hedge 为 nil 时不发送对冲请求；配置中不需要合并、缓存和对冲时直接返回 client
+func NewCoalescingRpc(client, hedge IRpc, conf config.Rpc) IRpc {
+	coalesce := conf.Coalesce == nil || *conf.Coalesce
+	if !coalesce && conf.LatestTtl == 0 && hedge == nil {
+		return client
+	}
+	percentile := conf.HedgePercentile
+	if percentile <= 0 || percentile > 100 {
+		percentile = defaultHedgePercentile
+	}
+	return &CoalescingRpc{
+		IRpc:       client,
+		hedge:      hedge,
+		coalesce:   coalesce,
+		ttl:        time.Duration(conf.LatestTtl) * time.Millisecond,
+		percentile: percentile,
+		inflight:   make(map[string]*rpcCall),
+		recent:     make(map[string]recentResult),
+	}
+}
+
+func (c *CoalescingRpc) Close() {
+	c.IRpc.Close()
+	if c.hedge != nil {
+		c.hedge.Close()
+	}
+}
+
+func (c *CoalescingRpc) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	if !readMethods[method] {
+		return c.IRpc.CallContext(ctx, result, method, args...)
+	}
+	params, err := json.Marshal(args)
+	if err != nil {
+		return c.IRpc.CallContext(ctx, result, method, args...)
+	}
+	key := method + string(params)
+	cacheable := c.ttl > 0 && isLatestCall(method, args)
+	if cacheable {
+		if raw, ok := c.cached(key); ok {
+			return json.Unmarshal(raw, result)
+		}
+	}
+	if !c.coalesce {
+		raw, err := c.read(ctx, method, args)
+		if err != nil {
+			return err
+		}
+		if cacheable {
+			c.store(key, raw)
+		}
+		return json.Unmarshal(raw, result)
+	}
+
+	c.mu.Lock()
+	call, ok := c.inflight[key]
+	if !ok {
+		call = &rpcCall{done: make(chan struct{})}
+		c.inflight[key] = call
+		go c.run(ctx, key, call, cacheable, method, args)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if call.err != nil {
+		return call.err
+	}
+	return json.Unmarshal(call.result, result)
+}
+
+// 共享请求不随发起者取消，只继承其截止时间
+func (c *CoalescingRpc) run(ctx context.Context, key string, call *rpcCall, cacheable bool, method string, args []any) {
+	var cancel context.CancelFunc
+	if deadline, ok := ctx.Deadline(); ok {
+		ctx, cancel = context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	} else {
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), defaultRequestTimeout)
+	}
+	defer cancel()
+	call.result, call.err = c.read(ctx, method, args)
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if call.err == nil && cacheable {
+		c.remember(key, call.result)
+	}
+	c.mu.Unlock()
+	close(call.done)
+}
+
+func (c *CoalescingRpc) cached(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	recent, ok := c.recent[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(recent.expires) {
+		delete(c.recent, key)
+		return nil, false
+	}
+	return recent.result, true
+}
+
+func (c *CoalescingRpc) store(key string, raw json.RawMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remember(key, raw)
+}
+
+// 调用方需持有锁，缓存条目较多时先清理过期的
+func (c *CoalescingRpc) remember(key string, raw json.RawMessage) {
+	now := time.Now()
+	if len(c.recent) >= recentPruneSize {
+		for k, recent := range c.recent {
+			if now.After(recent.expires) {
+				delete(c.recent, k)
+			}
+		}
+	}
+	c.recent[key] = recentResult{result: raw, expires: now.Add(c.ttl)}
+}
+
+// 请求主节点，超过耗时分位数仍未返回时同时请求备用节点，取先成功的结果
+func (c *CoalescingRpc) read(ctx context.Context, method string, args []any) (json.RawMessage, error) {
+	delay, hedged := c.hedgeDelay()
+	if !hedged {
+		return c.timedCall(ctx, method, args)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type response struct {
+		result json.RawMessage
+		err    error
+	}
+	responses := make(chan response, 2)
+	go func() {
+		result, err := c.timedCall(ctx, method, args)
+		responses <- response{result, err}
+	}()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	hedge := timer.C
+	pending := 1
+	for {
+		select {
+		case <-hedge:
+			hedge = nil
+			pending++
+			go func() {
+				var result json.RawMessage
+				err := c.hedge.CallContext(ctx, &result, method, args...)
+				responses <- response{result, err}
+			}()
+		case resp := <-responses:
+			pending--
+			if resp.err == nil || pending == 0 {
+				return resp.result, resp.err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// 请求主节点并记录成功请求的耗时
+func (c *CoalescingRpc) timedCall(ctx context.Context, method string, args []any) (json.RawMessage, error) {
+	var result json.RawMessage
+	start := time.Now()
+	if err := c.IRpc.CallContext(ctx, &result, method, args...); err != nil {
+		return nil, err
+	}
+	if c.hedge != nil {
+		c.observe(time.Since(start))
+	}
+	return result, nil
+}
+
+func (c *CoalescingRpc) observe(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.latency) < hedgeWindowSize {
+		c.latency = append(c.latency, d)
+		return
+	}
+	c.latency[c.next] = d
+	c.next = (c.next + 1) % hedgeWindowSize
+}
+
+// 对冲请求的等待时间，为主节点最近耗时的分位数
+func (c *CoalescingRpc) hedgeDelay() (time.Duration, bool) {
+	if c.hedge == nil {
+		return 0, false
+	}
+	c.mu.Lock()
+	samples := slices.Clone(c.latency)
+	c.mu.Unlock()
+	if len(samples) < hedgeMinSamples {
+		return 0, false
+	}
+	slices.Sort(samples)
+	index := int(float64(len(samples)-1) * c.percentile / 100)
+	return max(samples[index], hedgeMinDelay), true
+}
+
+// 参数中带 latest 标签，或结果只随最新区块变化的方法；pending 结果不缓存
+func isLatestCall(method string, args []any) bool {
+	if latestMethods[method] {
+		return true
+	}
+	for _, arg := range args {
+		if tag, ok := arg.(string); ok && tag == "latest" {
+			return true
+		}
+	}
+	return false
+}
+
+// 连接备用节点，失败时只记录日志，不影响主节点
+func dialHedge(ctx context.Context, conf config.Rpc) IRpc {
+	if conf.HedgeUrl == "" {
+		return nil
+	}
+	client, err := dialRpc(ctx, conf.HedgeUrl)
+	if err != nil {
+		log.Error("dial hedge rpc fail", "err", err)
+		return nil
+	}
+	return NewRetryRpc(NewRPC(client), conf)
+}
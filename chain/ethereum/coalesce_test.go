@@ -0,0 +1,132 @@
+package ethereum
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"chain-account/config"
+)
+
+func countCalls(node *fakeRpc, method string) int {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	count := 0
+	for _, call := range node.calls {
+		if call == method {
+			count++
+		}
+	}
+	return count
+}
+
+func Test_CoalescingRpcSharedRead(t *testing.T) {
+	node := &fakeRpc{latest: 100, delay: 20 * time.Millisecond, responses: map[string]string{"eth_sendRawTransaction": `"0x01"`}}
+	client := NewCoalescingRpc(node, nil, config.Rpc{})
+
+	// 相同的并发读请求只请求一次节点
+	var wg sync.WaitGroup
+	numbers := make([]uint64, 50)
+	for i := range numbers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var header *types.Header
+			if err := client.CallContext(context.Background(), &header, "eth_getBlockByNumber", "latest", false); err != nil {
+				t.Error(err)
+				return
+			}
+			numbers[i] = header.Number.Uint64()
+		}(i)
+	}
+	wg.Wait()
+	if calls := countCalls(node, "eth_getBlockByNumber"); calls != 1 {
+		t.Fatalf("expected 1 node call, got %d", calls)
+	}
+	for _, number := range numbers {
+		if number != 100 {
+			t.Fatalf("unexpected header number %d", number)
+		}
+	}
+
+	// 写操作不合并
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var hash string
+			client.CallContext(context.Background(), &hash, "eth_sendRawTransaction", "0x00")
+		}()
+	}
+	wg.Wait()
+	if calls := countCalls(node, "eth_sendRawTransaction"); calls != 2 {
+		t.Fatalf("expected 2 send calls, got %d", calls)
+	}
+
+	// 等待者取消时立即返回，不影响进行中的请求
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	var header *types.Header
+	if err := client.CallContext(ctx, &header, "eth_getBlockByNumber", hexutil.EncodeUint64(1), false); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func Test_CoalescingRpcLatestTtl(t *testing.T) {
+	node := &fakeRpc{latest: 100, responses: map[string]string{"eth_gasPrice": `"0x64"`, "eth_getTransactionCount": `"0x1"`}}
+	client := NewCoalescingRpc(node, nil, config.Rpc{LatestTtl: 50})
+
+	var price, nonce hexutil.Big
+	for i := 0; i < 3; i++ {
+		client.CallContext(context.Background(), &price, "eth_gasPrice")
+		client.CallContext(context.Background(), &nonce, "eth_getTransactionCount", "0x01", "pending")
+	}
+	// latest 类结果在 TTL 内复用，pending 结果不缓存
+	if countCalls(node, "eth_gasPrice") != 1 || countCalls(node, "eth_getTransactionCount") != 3 {
+		t.Fatalf("unexpected calls %v", node.calls)
+	}
+	if price.ToInt().Int64() != 100 {
+		t.Fatalf("unexpected gas price %v", price.ToInt())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	client.CallContext(context.Background(), &price, "eth_gasPrice")
+	if calls := countCalls(node, "eth_gasPrice"); calls != 2 {
+		t.Fatalf("expected refetch after ttl, got %d calls", calls)
+	}
+}
+
+func Test_CoalescingRpcHedge(t *testing.T) {
+	primary := &fakeRpc{latest: 100}
+	hedge := &fakeRpc{latest: 200}
+	disabled := false
+	client := NewCoalescingRpc(primary, hedge, config.Rpc{Coalesce: &disabled}).(*CoalescingRpc)
+
+	// 样本不足时不发送对冲请求
+	var header *types.Header
+	for i := 0; i < hedgeMinSamples; i++ {
+		if err := client.CallContext(context.Background(), &header, "eth_getBlockByNumber", "latest", false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(hedge.calls) != 0 || header.Number.Uint64() != 100 {
+		t.Fatalf("unexpected hedge calls %v", hedge.calls)
+	}
+
+	// 主节点超过耗时分位数未返回时取备用节点的结果
+	primary.delay = 500 * time.Millisecond
+	start := time.Now()
+	if err := client.CallContext(context.Background(), &header, "eth_getBlockByNumber", "latest", false); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= primary.delay || header.Number.Uint64() != 200 {
+		t.Fatalf("expected hedged result, got %d after %v", header.Number.Uint64(), elapsed)
+	}
+	if len(hedge.calls) != 1 {
+		t.Fatalf("expected 1 hedge call, got %d", len(hedge.calls))
+	}
+}
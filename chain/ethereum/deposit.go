@@ -0,0 +1,904 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/common/store"
+	"chain-account/common/util"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const (
+	defaultScanInterval  = 5 * time.Second
+	defaultConfirmations = 12
+	depositReorgDepth    = 64 // 保留最近扫描区块的 hash 数量，用于回滚检测
+
+	// 未配置 finalized_confirmations 且节点不支持 finalized 标签时，超过回滚检测深度的区块视为最终确认
+	defaultFinalizedConfirmations = depositReorgDepth
+
+	depositPruneInterval = 1000 // 每推进多少个区块清理一次过期充值
+
+	// 充值记录按 deposit/<to>/<height>/<id> 存储，便于按地址查询和按高度排序；
+	// id/ 记录 id 到存储 key 的映射，open/ 为未最终确认的充值，closed/<height>/<id> 用于按高度清理
+	depositKeyPrefix = "deposit/"
+	idKeyPrefix      = "id/"
+	openKeyPrefix    = "open/"
+	closedKeyPrefix  = "closed/"
+	notifyKeyPrefix  = "notify/"
+	outboxKeyPrefix  = "outbox/" // 已落盘但还没有成功交给订阅方的通知，重启后重新投递
+	watchKey         = "watch"
+	cursorKey        = "cursor"
+)
+
+// ERC20 Transfer(address,address,uint256) 事件签名
+var erc20TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// 充值记录
+type Deposit struct {
+	Id              string                `json:"id"`
+	Hash            string                `json:"hash"`
+	LogIndex        uint32                `json:"log_index"`
+	From            string                `json:"from"`
+	To              string                `json:"to"`
+	Value           string                `json:"value"`
+	ContractAddress string                `json:"contract_address"`
+	Height          uint64                `json:"height"`
+	BlockHash       string                `json:"block_hash"`
+	Confirmations   uint64                `json:"confirmations"`
+	Status          account.DepositStatus `json:"status"`
+}
+
+// 充值通知，被 ack 之前会一直保留（至少投递一次）
+type DepositNotify struct {
+	Id      string  `json:"id"`
+	Deposit Deposit `json:"deposit"`
+}
+
+type depositWatch struct {
+	Addresses []string `json:"addresses"`
+	Contracts []string `json:"contracts"`
+}
+
+type depositCursor struct {
+	Next   uint64            `json:"next"`
+	Hashes map[uint64]string `json:"hashes"`
+	Seq    uint64            `json:"seq"`
+}
+
+// 充值监控：逐块扫描监控地址的 ETH 转入和 ERC20 Transfer 事件，并跟踪确认数
+type DepositMonitor struct {
+	client IEth
+	conf   config.Deposit
+	store  store.Store
+
+	mu        sync.RWMutex
+	addresses map[common.Address]struct{}
+	contracts map[common.Address]struct{}
+	open      map[string]*Deposit // 未最终确认的充值
+	cursor    depositCursor
+	latest    uint64
+	pruned    uint64
+	noFinal   bool // 节点没有 finalized 区块，已回退为按确认数判断最终确认
+	listeners []func(*DepositNotify) error
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// 初始化充值监控，并从存储中恢复监控地址、扫块进度和未完成的充值
+func NewDepositMonitor(client IEth, conf config.Deposit, db store.Store) (*DepositMonitor, error) {
+	if conf.Confirmations == 0 {
+		conf.Confirmations = defaultConfirmations
+	}
+	m := &DepositMonitor{
+		client:    client,
+		conf:      conf,
+		store:     db,
+		addresses: make(map[common.Address]struct{}),
+		contracts: make(map[common.Address]struct{}),
+		open:      make(map[string]*Deposit),
+		cursor:    depositCursor{Hashes: make(map[uint64]string)},
+		stop:      make(chan struct{}),
+	}
+
+	var watch depositWatch
+	if _, err := db.Get(watchKey, &watch); err != nil {
+		return nil, err
+	}
+	for _, addr := range watch.Addresses {
+		m.addresses[common.HexToAddress(addr)] = struct{}{}
+	}
+	for _, addr := range watch.Contracts {
+		m.contracts[common.HexToAddress(addr)] = struct{}{}
+	}
+	if _, err := db.Get(cursorKey, &m.cursor); err != nil {
+		return nil, err
+	}
+	if m.cursor.Hashes == nil {
+		m.cursor.Hashes = make(map[uint64]string)
+	}
+
+	if err := m.migrate(); err != nil {
+		return nil, err
+	}
+	keys, err := db.Keys(openKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		deposit, err := m.get(strings.TrimPrefix(key, openKeyPrefix))
+		if err != nil {
+			return nil, err
+		}
+		if deposit != nil {
+			m.open[deposit.Id] = deposit
+		}
+	}
+	return m, nil
+}
+
+// 把旧版按 deposit/<id> 存储的记录迁移为新的 key 格式
+func (m *DepositMonitor) migrate() error {
+	keys, err := m.store.Keys(depositKeyPrefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if strings.Contains(strings.TrimPrefix(key, depositKeyPrefix), "/") {
+			continue
+		}
+		deposit := new(Deposit)
+		if _, err := m.store.Get(key, deposit); err != nil {
+			return err
+		}
+		if err := m.put(deposit); err != nil {
+			return err
+		}
+		if err := m.store.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 启动后台扫块，需要在订阅之后调用，否则启动后第一轮扫描的通知不会交给订阅方
+func (m *DepositMonitor) Start() {
+	interval := time.Duration(m.conf.ScanInterval) * time.Second
+	if interval == 0 {
+		interval = defaultScanInterval
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := m.redeliver(); err != nil {
+				log.Error("redeliver deposit notify fail", "err", err)
+			}
+			if err := m.Scan(); err != nil {
+				log.Error("deposit scan fail", "err", err)
+			}
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// 停止后台扫块
+func (m *DepositMonitor) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+// 订阅充值状态变化，回调在扫块协程中同步执行；回调返回错误时通知保留在 outbox 中，之后重新投递
+func (m *DepositMonitor) Subscribe(fn func(*DepositNotify) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// 添加或移除监控地址和代币合约
+func (m *DepositMonitor) Watch(addresses, contracts []string, remove bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, addr := range addresses {
+		if remove {
+			delete(m.addresses, common.HexToAddress(addr))
+		} else {
+			m.addresses[common.HexToAddress(addr)] = struct{}{}
+		}
+	}
+	for _, addr := range contracts {
+		if remove {
+			delete(m.contracts, common.HexToAddress(addr))
+		} else {
+			m.contracts[common.HexToAddress(addr)] = struct{}{}
+		}
+	}
+	return m.store.Put(watchKey, m.watchList())
+}
+
+// 当前的监控地址和代币合约
+func (m *DepositMonitor) Watched() ([]string, []string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	watch := m.watchList()
+	return watch.Addresses, watch.Contracts
+}
+
+func (m *DepositMonitor) watchList() depositWatch {
+	var watch depositWatch
+	for addr := range m.addresses {
+		watch.Addresses = append(watch.Addresses, addr.Hex())
+	}
+	for addr := range m.contracts {
+		watch.Contracts = append(watch.Contracts, addr.Hex())
+	}
+	sort.Strings(watch.Addresses)
+	sort.Strings(watch.Contracts)
+	return watch
+}
+
+// 扫描到最新区块，并更新未完成充值的确认状态
+func (m *DepositMonitor) Scan() error {
+	latest, err := m.client.BlockHeaderByNumber(nil)
+	if err != nil {
+		return err
+	}
+	latestHeight := latest.Number.Uint64()
+	if m.cursor.Next == 0 {
+		m.cursor.Next = latestHeight
+		if m.conf.StartHeight > 0 {
+			m.cursor.Next = m.conf.StartHeight
+		}
+	}
+
+	for m.cursor.Next <= latestHeight {
+		height := m.cursor.Next
+		header, err := m.client.BlockHeaderByNumber(new(big.Int).SetUint64(height))
+		if err != nil {
+			return err
+		}
+		// 父区块 hash 对不上说明发生了回滚
+		if parent, ok := m.cursor.Hashes[height-1]; ok && parent != header.ParentHash.Hex() {
+			log.Warn("deposit scan detected reorg", "height", height-1, "hash", parent)
+			if err := m.rollback(height - 1); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := m.scanBlock(header); err != nil {
+			return err
+		}
+		m.cursor.Hashes[height] = header.Hash().Hex()
+		delete(m.cursor.Hashes, height-depositReorgDepth)
+		m.cursor.Next = height + 1
+		if err := m.store.Put(cursorKey, m.cursor); err != nil {
+			return err
+		}
+	}
+	return m.updateConfirmations(latestHeight)
+}
+
+// 回滚指定高度及以上的区块，未最终确认的充值标记为已回滚
+func (m *DepositMonitor) rollback(height uint64) error {
+	m.mu.Lock()
+	var reorged []*Deposit
+	for id, deposit := range m.open {
+		if deposit.Height >= height {
+			deposit.Status = account.DepositStatus_DepositReorged
+			reorged = append(reorged, deposit)
+			delete(m.open, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, deposit := range reorged {
+		if err := m.save(deposit, true); err != nil {
+			return err
+		}
+	}
+	for h := range m.cursor.Hashes {
+		if h >= height {
+			delete(m.cursor.Hashes, h)
+		}
+	}
+	m.cursor.Next = height
+	// 同时使缓存中回滚区块的数据失效
+	if cache, ok := m.client.(*CachedEth); ok {
+		cache.Invalidate(height)
+	}
+	return m.store.Put(cursorKey, m.cursor)
+}
+
+// 扫描单个区块中转入监控地址的 ETH 和 ERC20
+func (m *DepositMonitor) scanBlock(header *types.Header) error {
+	m.mu.RLock()
+	addresses := make(map[common.Address]struct{}, len(m.addresses))
+	for addr := range m.addresses {
+		addresses[addr] = struct{}{}
+	}
+	var contracts []common.Address
+	for addr := range m.contracts {
+		contracts = append(contracts, addr)
+	}
+	m.mu.RUnlock()
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	block, err := m.client.BlockByNumber(header.Number)
+	if err != nil {
+		return err
+	}
+	if block.Hash != header.Hash() {
+		return fmt.Errorf("block %d changed during scan", header.Number.Uint64())
+	}
+	height := header.Number.Uint64()
+	blockHash := header.Hash().Hex()
+
+	for _, tx := range block.Transactions {
+		if tx.To == "" {
+			continue
+		}
+		to := common.HexToAddress(tx.To)
+		if _, ok := addresses[to]; !ok {
+			continue
+		}
+		value, err := hexutil.DecodeBig(tx.Value)
+		if err != nil || value.Sign() == 0 {
+			continue
+		}
+		receipt, err := m.client.TxReceiptByHash(common.HexToHash(tx.Hash))
+		if err != nil {
+			return err
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		deposit := &Deposit{
+			Id:              tx.Hash,
+			Hash:            tx.Hash,
+			From:            common.HexToAddress(tx.From).Hex(),
+			To:              to.Hex(),
+			Value:           value.String(),
+			ContractAddress: global_const.ZeroAddress,
+			Height:          height,
+			BlockHash:       blockHash,
+		}
+		if err := m.found(deposit); err != nil {
+			return err
+		}
+	}
+
+	if len(contracts) == 0 {
+		return nil
+	}
+	var toTopics []common.Hash
+	for addr := range addresses {
+		toTopics = append(toTopics, common.BytesToHash(addr.Bytes()))
+	}
+	logs, err := m.client.FilterLogs(ethereum.FilterQuery{
+		FromBlock: header.Number,
+		ToBlock:   header.Number,
+		Addresses: contracts,
+		Topics:    [][]common.Hash{{erc20TransferTopic}, nil, toTopics},
+	})
+	if err != nil {
+		return err
+	}
+	for _, item := range logs.Logs {
+		if item.Removed || len(item.Topics) != 3 || len(item.Data) != 32 {
+			continue
+		}
+		if item.BlockHash != header.Hash() {
+			return fmt.Errorf("logs of block %d changed during scan", height)
+		}
+		value := new(big.Int).SetBytes(item.Data)
+		if value.Sign() == 0 {
+			continue
+		}
+		deposit := &Deposit{
+			Id:              fmt.Sprintf("%s-%d", item.TxHash.Hex(), item.Index),
+			Hash:            item.TxHash.Hex(),
+			LogIndex:        uint32(item.Index),
+			From:            common.BytesToAddress(item.Topics[1].Bytes()).Hex(),
+			To:              common.BytesToAddress(item.Topics[2].Bytes()).Hex(),
+			Value:           value.String(),
+			ContractAddress: item.Address.Hex(),
+			Height:          height,
+			BlockHash:       blockHash,
+		}
+		if err := m.found(deposit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 记录新发现的充值
+func (m *DepositMonitor) found(deposit *Deposit) error {
+	deposit.Status = account.DepositStatus_DepositPending
+	m.mu.Lock()
+	m.open[deposit.Id] = deposit
+	m.mu.Unlock()
+	log.Info("deposit found", "id", deposit.Id, "to", deposit.To, "value", deposit.Value, "contract", deposit.ContractAddress)
+	return m.save(deposit, true)
+}
+
+// 按最新高度更新确认数和状态
+func (m *DepositMonitor) updateConfirmations(latest uint64) error {
+	var finalized uint64
+	if m.conf.FinalizedConfirmations > 0 {
+		finalized = finalizedAt(latest, m.conf.FinalizedConfirmations)
+	} else {
+		header, err := m.client.LatestFinalizedBlockHeader()
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return err
+		}
+		if header != nil {
+			finalized = header.Number.Uint64()
+		} else {
+			// 节点不支持 finalized 标签，否则充值永远不会最终确认，open 和存储会无限增长
+			if !m.noFinal {
+				log.Warn("node has no finalized block, falling back to confirmation count", "finalized_confirmations", defaultFinalizedConfirmations)
+				m.noFinal = true
+			}
+			finalized = finalizedAt(latest, defaultFinalizedConfirmations)
+		}
+	}
+
+	m.mu.Lock()
+	m.latest = latest
+	var changed []*Deposit
+	for id, deposit := range m.open {
+		status := account.DepositStatus_DepositPending
+		confirmations := confirmationsAt(deposit.Height, latest)
+		if confirmations >= m.conf.Confirmations {
+			status = account.DepositStatus_DepositConfirmed
+		}
+		if deposit.Height <= finalized {
+			status = account.DepositStatus_DepositFinalized
+			delete(m.open, id)
+		}
+		if status != deposit.Status {
+			deposit.Status = status
+			deposit.Confirmations = confirmations
+			changed = append(changed, deposit)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, deposit := range changed {
+		if err := m.save(deposit, true); err != nil {
+			return err
+		}
+	}
+	return m.prune(latest)
+}
+
+// 保存充值记录，notify 为 true 时生成通知
+func (m *DepositMonitor) save(deposit *Deposit, notify bool) error {
+	m.mu.Lock()
+	snapshot := *deposit
+	snapshot.Confirmations = confirmationsAt(deposit.Height, m.latest)
+	if snapshot.Status == account.DepositStatus_DepositReorged {
+		snapshot.Confirmations = 0
+	}
+	m.mu.Unlock()
+
+	if err := m.put(&snapshot); err != nil {
+		return err
+	}
+	if !notify {
+		return nil
+	}
+
+	m.mu.Lock()
+	m.cursor.Seq++
+	item := &DepositNotify{
+		Id:      fmt.Sprintf("%020d", m.cursor.Seq),
+		Deposit: snapshot,
+	}
+	listeners := m.listeners
+	m.mu.Unlock()
+
+	// 通知和序号一起落盘，重启后序号不会回退而覆盖未 ack 的通知；
+	// 有订阅方时同时写入 outbox，投递前进程退出也能在重启后补发
+	batch := map[string]any{notifyKeyPrefix + item.Id: item, cursorKey: m.cursor}
+	if len(listeners) > 0 {
+		batch[outboxKeyPrefix+item.Id] = item
+	}
+	if err := m.store.PutBatch(batch); err != nil {
+		return err
+	}
+	return m.publish(item, listeners)
+}
+
+// 把通知交给订阅方，全部成功后从 outbox 中删除
+func (m *DepositMonitor) publish(item *DepositNotify, listeners []func(*DepositNotify) error) error {
+	if len(listeners) == 0 {
+		return nil
+	}
+	for _, fn := range listeners {
+		if err := fn(item); err != nil {
+			return nil
+		}
+	}
+	return m.store.Delete(outboxKeyPrefix + item.Id)
+}
+
+// 重新投递 outbox 中未成功交给订阅方的通知
+func (m *DepositMonitor) redeliver() error {
+	m.mu.RLock()
+	listeners := m.listeners
+	m.mu.RUnlock()
+	if len(listeners) == 0 {
+		return nil
+	}
+	keys, err := m.store.Keys(outboxKeyPrefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		item := new(DepositNotify)
+		if _, err := m.store.Get(key, item); err != nil {
+			return err
+		}
+		if err := m.publish(item, listeners); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 写入充值记录和索引，记录的 key 变化（回滚后在其他高度重新打包）时删除旧记录
+func (m *DepositMonitor) put(deposit *Deposit) error {
+	key := depositKey(deposit)
+	var oldKey string
+	if _, err := m.store.Get(idKeyPrefix+deposit.Id, &oldKey); err != nil {
+		return err
+	}
+	if oldKey != "" && oldKey != key {
+		if err := m.store.Delete(oldKey); err != nil {
+			return err
+		}
+		if err := m.store.Delete(closedKey(keyHeight(oldKey), deposit.Id)); err != nil {
+			return err
+		}
+	}
+	if err := m.store.Put(key, deposit); err != nil {
+		return err
+	}
+	if err := m.store.Put(idKeyPrefix+deposit.Id, key); err != nil {
+		return err
+	}
+	if isOpen(deposit.Status) {
+		return m.store.Put(openKeyPrefix+deposit.Id, key)
+	}
+	if err := m.store.Delete(openKeyPrefix + deposit.Id); err != nil {
+		return err
+	}
+	return m.store.Put(closedKey(deposit.Height, deposit.Id), key)
+}
+
+// 按 id 读取充值记录，不存在时返回 nil
+func (m *DepositMonitor) get(id string) (*Deposit, error) {
+	var key string
+	if _, err := m.store.Get(idKeyPrefix+id, &key); err != nil || key == "" {
+		return nil, err
+	}
+	deposit := new(Deposit)
+	if ok, err := m.store.Get(key, deposit); err != nil || !ok {
+		return nil, err
+	}
+	return deposit, nil
+}
+
+// 删除最终确认或已回滚超过 retention 个区块的充值记录
+func (m *DepositMonitor) prune(latest uint64) error {
+	if m.conf.Retention == 0 || (m.pruned > 0 && latest < m.pruned+depositPruneInterval) {
+		return nil
+	}
+	m.pruned = latest
+	keys, err := m.store.Keys(closedKeyPrefix)
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, key := range keys {
+		if keyHeight(key)+m.conf.Retention > latest {
+			break
+		}
+		var depositKey string
+		if _, err := m.store.Get(key, &depositKey); err != nil {
+			return err
+		}
+		id := key[strings.LastIndex(key, "/")+1:]
+		var current string
+		if _, err := m.store.Get(idKeyPrefix+id, &current); err != nil {
+			return err
+		}
+		// 同一笔充值可能已在更高的区块重新打包
+		if current == depositKey {
+			if err := m.store.Delete(idKeyPrefix + id); err != nil {
+				return err
+			}
+		}
+		if err := m.store.Delete(depositKey); err != nil {
+			return err
+		}
+		if err := m.store.Delete(key); err != nil {
+			return err
+		}
+		removed++
+	}
+	if removed > 0 {
+		log.Info("pruned finalized deposits", "count", removed, "latest", latest)
+	}
+	return nil
+}
+
+// 查询充值记录，address 为空时返回全部；指定地址且不按状态过滤时只读取当前页
+func (m *DepositMonitor) List(address string, status []account.DepositStatus, page, pageSize uint32) ([]*Deposit, error) {
+	prefix := depositKeyPrefix
+	if address != "" {
+		prefix += strings.ToLower(address) + "/"
+	}
+	keys, err := m.store.Keys(prefix)
+	if err != nil {
+		return nil, err
+	}
+	// 同一地址的 key 按高度升序排列
+	slices.Reverse(keys)
+	if address != "" && len(status) == 0 {
+		keys = util.Paginate(keys, page, pageSize)
+	}
+	var deposits []*Deposit
+	for _, key := range keys {
+		deposit := new(Deposit)
+		if _, err := m.store.Get(key, deposit); err != nil {
+			return nil, err
+		}
+		if len(status) > 0 && !containsStatus(status, deposit.Status) {
+			continue
+		}
+		m.mu.RLock()
+		if _, ok := m.open[deposit.Id]; ok {
+			deposit.Confirmations = confirmationsAt(deposit.Height, m.latest)
+		}
+		m.mu.RUnlock()
+		deposits = append(deposits, deposit)
+	}
+	if address != "" && len(status) == 0 {
+		return deposits, nil
+	}
+	sort.SliceStable(deposits, func(i, j int) bool {
+		return deposits[i].Height > deposits[j].Height
+	})
+	return util.Paginate(deposits, page, pageSize), nil
+}
+
+// 拉取未 ack 的通知，按产生顺序返回
+func (m *DepositMonitor) Notifications(limit uint32) ([]*DepositNotify, error) {
+	keys, err := m.store.Keys(notifyKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(keys) > int(limit) {
+		keys = keys[:limit]
+	}
+	notifications := make([]*DepositNotify, 0, len(keys))
+	for _, key := range keys {
+		item := new(DepositNotify)
+		if _, err := m.store.Get(key, item); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, item)
+	}
+	return notifications, nil
+}
+
+// 确认通知已处理
+func (m *DepositMonitor) Ack(ids []string) error {
+	for _, id := range ids {
+		if err := m.store.Delete(notifyKeyPrefix + id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func depositKey(deposit *Deposit) string {
+	return fmt.Sprintf("%s%s/%020d/%s", depositKeyPrefix, strings.ToLower(deposit.To), deposit.Height, deposit.Id)
+}
+
+func closedKey(height uint64, id string) string {
+	return fmt.Sprintf("%s%020d/%s", closedKeyPrefix, height, id)
+}
+
+// 从 deposit/ 或 closed/ key 中解析区块高度
+func keyHeight(key string) uint64 {
+	parts := strings.Split(key, "/")
+	for i := len(parts) - 2; i >= 0; i-- {
+		if height, err := strconv.ParseUint(parts[i], 10, 64); err == nil && len(parts[i]) == 20 {
+			return height
+		}
+	}
+	return 0
+}
+
+func isOpen(status account.DepositStatus) bool {
+	return status == account.DepositStatus_DepositPending || status == account.DepositStatus_DepositConfirmed
+}
+
+// 按确认数计算的最终确认高度
+func finalizedAt(latest, confirmations uint64) uint64 {
+	if latest+1 < confirmations {
+		return 0
+	}
+	return latest + 1 - confirmations
+}
+
+func confirmationsAt(height, latest uint64) uint64 {
+	if latest < height {
+		return 0
+	}
+	return latest - height + 1
+}
+
+func containsStatus(list []account.DepositStatus, status account.DepositStatus) bool {
+	for _, item := range list {
+		if item == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Deposit) toMessage() *account.DepositMessage {
+	return &account.DepositMessage{
+		Id:              d.Id,
+		Hash:            d.Hash,
+		LogIndex:        d.LogIndex,
+		From:            d.From,
+		To:              d.To,
+		Value:           d.Value,
+		ContractAddress: d.ContractAddress,
+		Height:          d.Height,
+		BlockHash:       d.BlockHash,
+		Confirmations:   d.Confirmations,
+		Status:          d.Status,
+	}
+}
+
+// 注册/移除充值监控地址
+func (c *ChainAdaptor) WatchAddress(req *account.WatchAddressRequest) (*account.WatchAddressResponse, error) {
+	if c.Deposits == nil {
+		return &account.WatchAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "deposit monitor not enabled",
+		}, nil
+	}
+	for _, addr := range append(req.Addresses, req.ContractAddresses...) {
+		if !common.IsHexAddress(addr) {
+			return &account.WatchAddressResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid address: " + addr,
+			}, nil
+		}
+	}
+	if err := c.Deposits.Watch(req.Addresses, req.ContractAddresses, req.Remove); err != nil {
+		log.Error("watch address fail", "err", err)
+		return &account.WatchAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "watch address fail",
+		}, nil
+	}
+	addresses, contracts := c.Deposits.Watched()
+	return &account.WatchAddressResponse{
+		Code:              global_const.ReturnCode_SUCCESS,
+		Msg:               "watch address success",
+		Addresses:         addresses,
+		ContractAddresses: contracts,
+	}, nil
+}
+
+// 查询充值记录
+func (c *ChainAdaptor) GetDepositList(req *account.DepositListRequest) (*account.DepositListResponse, error) {
+	if c.Deposits == nil {
+		return &account.DepositListResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "deposit monitor not enabled",
+		}, nil
+	}
+	deposits, err := c.Deposits.List(req.Address, req.Status, req.Page, req.Pagesize)
+	if err != nil {
+		log.Error("get deposit list fail", "err", err)
+		return &account.DepositListResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get deposit list fail",
+		}, nil
+	}
+	var depositList []*account.DepositMessage
+	for _, deposit := range deposits {
+		depositList = append(depositList, deposit.toMessage())
+	}
+	return &account.DepositListResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      "get deposit list success",
+		Deposits: depositList,
+	}, nil
+}
+
+// 拉取充值通知
+func (c *ChainAdaptor) GetDepositNotify(req *account.DepositNotifyRequest) (*account.DepositNotifyResponse, error) {
+	if c.Deposits == nil {
+		return &account.DepositNotifyResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "deposit monitor not enabled",
+		}, nil
+	}
+	notifications, err := c.Deposits.Notifications(req.Limit)
+	if err != nil {
+		log.Error("get deposit notify fail", "err", err)
+		return &account.DepositNotifyResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get deposit notify fail",
+		}, nil
+	}
+	var notifyList []*account.DepositNotification
+	for _, item := range notifications {
+		notifyList = append(notifyList, &account.DepositNotification{
+			Id:      item.Id,
+			Deposit: item.Deposit.toMessage(),
+		})
+	}
+	return &account.DepositNotifyResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get deposit notify success",
+		Notifications: notifyList,
+	}, nil
+}
+
+// 确认充值通知
+func (c *ChainAdaptor) AckDepositNotify(req *account.AckDepositNotifyRequest) (*account.AckDepositNotifyResponse, error) {
+	if c.Deposits == nil {
+		return &account.AckDepositNotifyResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "deposit monitor not enabled",
+		}, nil
+	}
+	if err := c.Deposits.Ack(req.Ids); err != nil {
+		log.Error("ack deposit notify fail", "err", err)
+		return &account.AckDepositNotifyResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "ack deposit notify fail",
+		}, nil
+	}
+	return &account.AckDepositNotifyResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "ack deposit notify success",
+	}, nil
+}
@@ -0,0 +1,371 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"chain-account/common/store"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+// 内存模拟链，只实现充值监控用到的方法
+type fakeChain struct {
+	IEth
+	headers   []*types.Header
+	txs       map[uint64][]TransactionList
+	logs      map[uint64][]types.Log
+	finalized uint64
+	noFinal   bool // 模拟不支持 finalized 标签的节点
+}
+
+func newFakeChain(length int) *fakeChain {
+	f := &fakeChain{
+		txs:  make(map[uint64][]TransactionList),
+		logs: make(map[uint64][]types.Log),
+	}
+	f.extend(length, 0)
+	return f
+}
+
+// 追加区块，extra 用于在回滚时产生不同的区块 hash
+func (f *fakeChain) extend(n int, extra byte) {
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			Number:     big.NewInt(int64(len(f.headers))),
+			Difficulty: big.NewInt(0),
+			Extra:      []byte{extra},
+		}
+		if len(f.headers) > 0 {
+			header.ParentHash = f.headers[len(f.headers)-1].Hash()
+		}
+		f.headers = append(f.headers, header)
+	}
+}
+
+func (f *fakeChain) BlockHeaderByNumber(number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return f.headers[len(f.headers)-1], nil
+	}
+	if number.Uint64() >= uint64(len(f.headers)) {
+		return nil, ethereum.NotFound
+	}
+	return f.headers[number.Uint64()], nil
+}
+
+func (f *fakeChain) BlockByNumber(number *big.Int) (*RpcBlock, error) {
+	header := f.headers[number.Uint64()]
+	return &RpcBlock{
+		Hash:         header.Hash(),
+		Number:       hexutil.EncodeBig(number),
+		Transactions: f.txs[number.Uint64()],
+	}, nil
+}
+
+func (f *fakeChain) TxReceiptByHash(common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{Status: types.ReceiptStatusSuccessful}, nil
+}
+
+func (f *fakeChain) FilterLogs(query ethereum.FilterQuery) (Logs, error) {
+	height := query.FromBlock.Uint64()
+	var logs []types.Log
+	for _, item := range f.logs[height] {
+		item.BlockHash = f.headers[height].Hash()
+		logs = append(logs, item)
+	}
+	return Logs{Logs: logs, ToBlockHeader: f.headers[height]}, nil
+}
+
+func (f *fakeChain) LatestFinalizedBlockHeader() (*types.Header, error) {
+	if f.noFinal {
+		return nil, ethereum.NotFound
+	}
+	return f.headers[f.finalized], nil
+}
+
+func newTestMonitor(t *testing.T, chain *fakeChain, start uint64) *DepositMonitor {
+	m, err := NewDepositMonitor(chain, config.Deposit{
+		StartHeight:   start,
+		Confirmations: 3,
+	}, store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func Test_DepositMonitorConfirmations(t *testing.T) {
+	chain := newFakeChain(10)
+	watched := common.HexToAddress("0x62EccDa8bB2Ae5690E319F3eFde897dEAeD86631")
+	token := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	chain.txs[5] = []TransactionList{
+		{From: "0x35096AD62E57e86032a3Bb35aDaCF2240d55421D", To: watched.Hex(), Hash: common.HexToHash("0x01").Hex(), Value: "0xde0b6b3a7640000"},
+		{From: "0x35096AD62E57e86032a3Bb35aDaCF2240d55421D", To: token.Hex(), Hash: common.HexToHash("0x02").Hex(), Value: "0x0"},
+	}
+	chain.logs[6] = []types.Log{{
+		Address: token,
+		Topics: []common.Hash{
+			erc20TransferTopic,
+			common.BytesToHash(common.HexToAddress("0x35096AD62E57e86032a3Bb35aDaCF2240d55421D").Bytes()),
+			common.BytesToHash(watched.Bytes()),
+		},
+		Data:   common.LeftPadBytes(big.NewInt(1000000).Bytes(), 32),
+		TxHash: common.HexToHash("0x03"),
+		Index:  2,
+	}}
+
+	m := newTestMonitor(t, chain, 5)
+	if err := m.Watch([]string{watched.Hex()}, []string{token.Hex()}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	deposits, err := m.List(watched.Hex(), nil, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deposits) != 2 {
+		t.Fatalf("expected 2 deposits, got %d", len(deposits))
+	}
+	for _, deposit := range deposits {
+		switch deposit.ContractAddress {
+		case token.Hex():
+			if deposit.Value != "1000000" || deposit.Status != account.DepositStatus_DepositConfirmed {
+				t.Errorf("unexpected token deposit %+v", deposit)
+			}
+		default:
+			if deposit.Value != "1000000000000000000" || deposit.Confirmations != 5 {
+				t.Errorf("unexpected native deposit %+v", deposit)
+			}
+		}
+	}
+
+	// 节点 finalized 区块推进后，充值进入最终确认状态
+	chain.finalized = 5
+	if err := m.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	finalized, _ := m.List("", []account.DepositStatus{account.DepositStatus_DepositFinalized}, 1, 10)
+	if len(finalized) != 1 || finalized[0].Height != 5 {
+		t.Fatalf("expected the block 5 deposit to be finalized, got %+v", finalized)
+	}
+
+	// 每次状态变化都会产生通知，ack 之后不再返回
+	notifications, _ := m.Notifications(0)
+	if len(notifications) != 5 {
+		t.Fatalf("expected 5 notifications, got %d", len(notifications))
+	}
+	if err := m.Ack([]string{notifications[0].Id}); err != nil {
+		t.Fatal(err)
+	}
+	notifications, _ = m.Notifications(0)
+	if len(notifications) != 4 {
+		t.Fatalf("expected 4 notifications after ack, got %d", len(notifications))
+	}
+}
+
+func Test_DepositMonitorReorg(t *testing.T) {
+	chain := newFakeChain(8)
+	watched := common.HexToAddress("0x62EccDa8bB2Ae5690E319F3eFde897dEAeD86631")
+	chain.txs[7] = []TransactionList{
+		{From: "0x35096AD62E57e86032a3Bb35aDaCF2240d55421D", To: watched.Hex(), Hash: common.HexToHash("0x01").Hex(), Value: "0x1"},
+	}
+
+	m := newTestMonitor(t, chain, 6)
+	_ = m.Watch([]string{watched.Hex()}, nil, false)
+	if err := m.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 区块 7 被替换，充值从新链上消失
+	chain.headers = chain.headers[:7]
+	delete(chain.txs, 7)
+	chain.extend(2, 1)
+	if err := m.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	deposits, _ := m.List(watched.Hex(), nil, 1, 10)
+	if len(deposits) != 1 || deposits[0].Status != account.DepositStatus_DepositReorged {
+		t.Fatalf("expected deposit to be reorged, got %+v", deposits)
+	}
+	if m.cursor.Next != 9 {
+		t.Fatalf("expected scan to resume at 9, got %d", m.cursor.Next)
+	}
+}
+
+func Test_DepositMonitorStorage(t *testing.T) {
+	chain := newFakeChain(10)
+	watched := common.HexToAddress("0x62EccDa8bB2Ae5690E319F3eFde897dEAeD86631")
+	for _, height := range []uint64{2, 8} {
+		chain.txs[height] = []TransactionList{
+			{From: "0x35096AD62E57e86032a3Bb35aDaCF2240d55421D", To: watched.Hex(), Hash: common.BigToHash(new(big.Int).SetUint64(height)).Hex(), Value: "0x1"},
+		}
+	}
+	chain.finalized = 5
+	db := store.NewMemoryStore()
+	// 旧版按 deposit/<id> 存储的记录启动时迁移
+	legacy := &Deposit{Id: "legacy", To: watched.Hex(), Height: 1, Status: account.DepositStatus_DepositPending}
+	_ = db.Put(depositKeyPrefix+legacy.Id, legacy)
+
+	conf := config.Deposit{StartHeight: 2, Confirmations: 3, Retention: 5}
+	m, err := NewDepositMonitor(chain, conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.open["legacy"]; !ok {
+		t.Fatalf("expected legacy deposit to be migrated")
+	}
+	_ = m.Watch([]string{watched.Hex()}, nil, false)
+	if err := m.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 最终确认超过 retention 个区块的充值被删除，未最终确认的保留
+	deposits, _ := m.List(watched.Hex(), nil, 1, 10)
+	if len(deposits) != 1 || deposits[0].Height != 8 {
+		t.Fatalf("expected only the block 8 deposit, got %+v", deposits)
+	}
+	if keys, _ := db.Keys(closedKeyPrefix); len(keys) != 0 {
+		t.Fatalf("unexpected closed index %v", keys)
+	}
+
+	// 重启后只从 open/ 恢复未最终确认的充值
+	restarted, err := NewDepositMonitor(chain, conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restarted.open) != 1 || restarted.open[deposits[0].Id] == nil {
+		t.Fatalf("unexpected open deposits %v", restarted.open)
+	}
+}
+
+func Test_DepositMonitorRestartNotify(t *testing.T) {
+	chain := newFakeChain(10)
+	watched := common.HexToAddress("0x62EccDa8bB2Ae5690E319F3eFde897dEAeD86631")
+	chain.txs[9] = []TransactionList{
+		{From: "0x35096AD62E57e86032a3Bb35aDaCF2240d55421D", To: watched.Hex(), Hash: common.HexToHash("0x01").Hex(), Value: "0x1"},
+	}
+	db := store.NewMemoryStore()
+	conf := config.Deposit{StartHeight: 9, Confirmations: 1}
+	m, err := NewDepositMonitor(chain, conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = m.Watch([]string{watched.Hex()}, nil, false)
+	if err := m.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	// 没有新区块，只有确认状态变化产生的通知
+	chain.finalized = 9
+	if err := m.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重启后的通知序号接着之前的序号，不覆盖未 ack 的通知
+	restarted, err := NewDepositMonitor(chain, conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.extend(1, 0)
+	chain.txs[10] = []TransactionList{
+		{From: "0x35096AD62E57e86032a3Bb35aDaCF2240d55421D", To: watched.Hex(), Hash: common.HexToHash("0x02").Hex(), Value: "0x1"},
+	}
+	if err := restarted.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	notifications, _ := restarted.Notifications(0)
+	if len(notifications) != 5 {
+		t.Fatalf("expected 5 notifications, got %d", len(notifications))
+	}
+	for i, item := range notifications[:3] {
+		if item.Deposit.Height != 9 || item.Id != fmt.Sprintf("%020d", i+1) {
+			t.Fatalf("unexpected notification %d: %+v", i, item)
+		}
+	}
+	if notifications[2].Deposit.Status != account.DepositStatus_DepositFinalized {
+		t.Fatalf("expected finalized notification to be kept, got %+v", notifications[2])
+	}
+}
+
+func Test_DepositMonitorRedeliver(t *testing.T) {
+	chain := newFakeChain(10)
+	watched := common.HexToAddress("0x62EccDa8bB2Ae5690E319F3eFde897dEAeD86631")
+	chain.txs[9] = []TransactionList{
+		{From: "0x35096AD62E57e86032a3Bb35aDaCF2240d55421D", To: watched.Hex(), Hash: common.HexToHash("0x01").Hex(), Value: "0x1"},
+	}
+	db := store.NewMemoryStore()
+	conf := config.Deposit{StartHeight: 9, Confirmations: 1}
+	m, err := NewDepositMonitor(chain, conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 订阅方投递失败（或投递前进程退出），通知留在 outbox 中
+	m.Subscribe(func(*DepositNotify) error { return fmt.Errorf("notifier unavailable") })
+	_ = m.Watch([]string{watched.Hex()}, nil, false)
+	if err := m.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := NewDepositMonitor(chain, conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var delivered []*DepositNotify
+	restarted.Subscribe(func(item *DepositNotify) error {
+		delivered = append(delivered, item)
+		return nil
+	})
+	if err := restarted.redeliver(); err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 2 || delivered[0].Id != fmt.Sprintf("%020d", 1) {
+		t.Fatalf("expected 2 redelivered notifications, got %+v", delivered)
+	}
+	// 投递成功后从 outbox 删除，不再重复投递
+	if err := restarted.redeliver(); err != nil {
+		t.Fatal(err)
+	}
+	if len(delivered) != 2 {
+		t.Fatalf("expected no further redelivery, got %d", len(delivered))
+	}
+}
+
+func Test_DepositMonitorFinalizedFallback(t *testing.T) {
+	chain := newFakeChain(10)
+	chain.noFinal = true
+	watched := common.HexToAddress("0x62EccDa8bB2Ae5690E319F3eFde897dEAeD86631")
+	chain.txs[5] = []TransactionList{
+		{From: "0x35096AD62E57e86032a3Bb35aDaCF2240d55421D", To: watched.Hex(), Hash: common.HexToHash("0x01").Hex(), Value: "0x1"},
+	}
+	m := newTestMonitor(t, chain, 5)
+	_ = m.Watch([]string{watched.Hex()}, nil, false)
+	if err := m.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	deposits, _ := m.List(watched.Hex(), nil, 1, 10)
+	if len(deposits) != 1 || deposits[0].Status != account.DepositStatus_DepositConfirmed {
+		t.Fatalf("expected confirmed deposit, got %+v", deposits)
+	}
+
+	// 没有 finalized 区块时按回滚检测深度的确认数判断最终确认
+	chain.extend(defaultFinalizedConfirmations, 0)
+	if err := m.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	deposits, _ = m.List(watched.Hex(), nil, 1, 10)
+	if len(deposits) != 1 || deposits[0].Status != account.DepositStatus_DepositFinalized {
+		t.Fatalf("expected finalized deposit, got %+v", deposits)
+	}
+	if len(m.open) != 0 {
+		t.Fatalf("expected no open deposits, got %d", len(m.open))
+	}
+}
@@ -1,11 +1,11 @@
 package ethereum
 
 import (
-	"chain-account/common/util"
 	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 	"sync"
 	"time"
 
@@ -16,15 +16,18 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 
-	"chain-account/common/global_const"
 	"chain-account/common/helpers"
 	"chain-account/common/retry"
+	"chain-account/config"
 )
 
 const (
-	defaultDialTimeout    = 5 * time.Second
-	defaultDialAttempts   = 5
-	defaultRequestTimeout = 10 * time.Second
+	defaultDialTimeout      = 5 * time.Second
+	defaultDialAttempts     = 5
+	defaultRequestTimeout   = 10 * time.Second
+	defaultRangeConcurrency = 4
+	defaultRangeAttempts    = 3
+	capabilityReprobeDelay  = time.Minute // 有未确定的节点能力时重新探测的间隔
 )
 
 // 定义交易列表
@@ -66,16 +69,18 @@ type IEth interface {
 	// 区块数据相关
 	BlockHeaderByNumber(*big.Int) (*types.Header, error)
 	BlockHeaderByHash(common.Hash) (*types.Header, error)
-	BlockHeadersByRange(*big.Int, *big.Int, uint) ([]types.Header, error)
+	BlockHeadersByRange(*big.Int, *big.Int) ([]types.Header, error)
 	BlockByNumber(*big.Int) (*RpcBlock, error)
 	BlockByHash(common.Hash) (*RpcBlock, error)
 	LatestSafeBlockHeader() (*types.Header, error)
 	LatestFinalizedBlockHeader() (*types.Header, error)
 	// 账户与交易
 	TxCountByAddress(common.Address) (hexutil.Uint64, error)
+	PendingTxCountByAddress(common.Address) (hexutil.Uint64, error)
 	SendRawTransaction(rawTx string) (*common.Hash, error)
 	TxByHash(common.Hash) (*types.Transaction, error)
 	TxReceiptByHash(common.Hash) (*types.Receipt, error)
+	BlockReceipts(*big.Int) ([]*types.Receipt, error)
 	StorageHash(common.Address, *big.Int) (common.Hash, error)
 	EthGetCode(common.Address) (string, error)
 	GetBalance(common.Address) (*big.Int, error)
@@ -83,41 +88,110 @@ type IEth interface {
 	SuggestGasPrice() (*big.Int, error)
 	SuggestGasTipCap() (*big.Int, error)
 	// 智能合约交互
-	FilterLogs(ethereum.FilterQuery, uint) (Logs, error)
+	FilterLogs(ethereum.FilterQuery) (Logs, error)
 	Close()
 }
 
+// 批量获取区块头只获取到部分区块，Next 为第一个未获取到的区块号
+type PartialRangeError struct {
+	Next *big.Int
+	Err  error
+}
+
+func (e *PartialRangeError) Error() string {
+	return fmt.Sprintf("block headers fetched partially, next block %s: %v", e.Next, e.Err)
+}
+
+func (e *PartialRangeError) Unwrap() error {
+	return e.Err
+}
+
 // 定义Eth客户端
 type EthClient struct {
-	rpc IRpc
+	rpc    IRpc
+	conf   config.Rpc
+	ranges rangeOptions
+
+	capsMu    sync.Mutex
+	caps      Capabilities
+	probing   bool
+	reprobeAt time.Time
+}
+
+// 批量获取区块头的分段配置，为 0 的项使用默认值
+type rangeOptions struct {
+	chunkSize   int
+	concurrency int
+	attempts    int
+	strategy    retry.Strategy
+}
+
+// 初始化Eth客户端 需要全部实现IEth的接口，conf 中未设置的 RPC 能力在连接后探测
+func NewEthClient(ctx context.Context, rpcUrl string, conf config.Rpc) (IEth, error) {
+	client, err := DialRpc(ctx, rpcUrl, conf) // 初始化rpc客户端
+	if err != nil {
+		return nil, err
+	}
+	return &EthClient{
+		rpc:  client,
+		conf: conf,
+		ranges: rangeOptions{
+			chunkSize:   conf.RangeChunkSize,
+			concurrency: conf.RangeConcurrency,
+			attempts:    conf.RangeAttempts,
+		},
+		caps:      DetectCapabilities(context.Background(), client, conf),
+		reprobeAt: time.Now().Add(capabilityReprobeDelay),
+	}, nil
 }
 
-// 初始化Eth客户端 需要全部实现IEth的接口
-func NewEthClient(ctx context.Context, rpcUrl string) (IEth, error) {
+// 连接节点并按配置套上重试、Retry-After、熔断、请求合并和对冲，L2 链的专有接口客户端也使用同样的连接
+func DialRpc(ctx context.Context, rpcUrl string, conf config.Rpc) (IRpc, error) {
 	ctx, cancel := context.WithTimeout(ctx, defaultDialTimeout) // 超时设置
 	defer cancel()
 
+	rpcClient, err := dialRpc(ctx, rpcUrl)
+	if err != nil {
+		return nil, err
+	}
+	return NewCoalescingRpc(NewRetryRpc(NewRPC(rpcClient), conf), dialHedge(ctx, conf), conf), nil
+}
+
+func dialRpc(ctx context.Context, rpcUrl string) (*rpc.Client, error) {
 	bOff := retry.Exponential()
 	// 尝试 重试链接 5次
-	rpcClient, err := retry.Do(ctx, defaultDialAttempts, bOff, func() (*rpc.Client, error) {
+	return retry.Do(ctx, defaultDialAttempts, bOff, func() (*rpc.Client, error) {
 		if !helpers.IsURLAvailable(rpcUrl) {
 			return nil, fmt.Errorf("address unavailable (%s)", rpcUrl)
 		}
 
-		client, err := rpc.DialContext(ctx, rpcUrl)
+		client, err := rpc.DialOptions(ctx, rpcUrl, rpc.WithHTTPClient(&http.Client{Transport: retryAfterTransport{http.DefaultTransport}}))
 		if err != nil {
 			return nil, fmt.Errorf("failed to dial address (%s): %w", rpcUrl, err)
 		}
 
 		return client, nil
 	})
-	if err != nil {
-		return nil, err
+}
+
+// 节点 RPC 能力；启动时有未确定的项（探测遇到临时错误）时，每隔 capabilityReprobeDelay 在后台重新探测
+func (e *EthClient) Capabilities() Capabilities {
+	e.capsMu.Lock()
+	defer e.capsMu.Unlock()
+	if e.caps.Unknown != 0 && !e.probing && time.Now().After(e.reprobeAt) {
+		e.probing = true
+		go e.reprobe(e.caps)
 	}
+	return e.caps
+}
 
-	return &EthClient{
-		rpc: NewRPC(rpcClient), // 初始化rpc客户端
-	}, nil
+func (e *EthClient) reprobe(caps Capabilities) {
+	caps = caps.Redetect(context.Background(), e.rpc, e.conf)
+	e.capsMu.Lock()
+	defer e.capsMu.Unlock()
+	e.caps = caps
+	e.probing = false
+	e.reprobeAt = time.Now().Add(capabilityReprobeDelay)
 }
 
 // 通过区块号获取区块头  number: 目标区块号	验证区块高度
@@ -134,7 +208,6 @@ func (e *EthClient) BlockHeaderByNumber(number *big.Int) (*types.Header, error)
 		log.Warn("header not found")
 		return nil, ethereum.NotFound
 	}
-	fmt.Println("************", util.ToPrettyJSON(header))
 	return header, nil
 }
 
@@ -159,12 +232,16 @@ func (e *EthClient) BlockHeaderByHash(hash common.Hash) (*types.Header, error) {
 }
 
 // 批量获取区块头范围 start, end: 起始/结束区块号	区块数据同步
-func (e *EthClient) BlockHeadersByRange(startHeight, endHeight *big.Int, chainId uint) ([]types.Header, error) {
+// 按段并发请求，每段失败后重试；遇到节点上不存在的区块时截断，某段最终失败时返回之前连续获取到的区块头和 PartialRangeError
+func (e *EthClient) BlockHeadersByRange(startHeight, endHeight *big.Int) ([]types.Header, error) {
 	/*
 		1：左侧值 (startHeight) > 右侧值 (endHeight)
 		0：两侧值相等
 		-1：左侧值 < 右侧值
 	*/
+	if startHeight.Cmp(endHeight) > 0 {
+		return nil, errors.New("start height greater than end height")
+	}
 	// 判断是否请求单个区块
 	if startHeight.Cmp(endHeight) == 0 {
 		header, err := e.BlockHeaderByNumber(startHeight)
@@ -173,73 +250,100 @@ func (e *EthClient) BlockHeadersByRange(startHeight, endHeight *big.Int, chainId
 		}
 		return []types.Header{*header}, nil
 	}
+
+	opts := e.rangeOptions()
 	// 计算两个区块之间的区块总数 区块数 = (end - start) + 1
 	count := new(big.Int).Sub(endHeight, startHeight).Uint64() + 1
-	headers := make([]types.Header, count)
-	batchElems := make([]rpc.BatchElem, count)
-	ctx, cancelFunc := context.WithTimeout(context.Background(), defaultRequestTimeout)
-	defer cancelFunc()
-
-	if chainId == uint(global_const.ZkFairSepoliaChainId) || chainId == uint(global_const.ZkFairChainId) {
-		groupSize := 100
-		var wg sync.WaitGroup
-		numGroups := (int(count)-1)/groupSize + 1
-		wg.Add(numGroups)
-
-		for i := 0; i < int(count); i += groupSize {
-			start := i
-			end := i + groupSize - 1
-			if end > int(count) {
-				end = int(count) - 1
-			}
-			go func(start, end int) {
-				defer wg.Done()
-				for j := start; j <= end; j++ {
-					height := new(big.Int).Add(startHeight, new(big.Int).SetUint64(uint64(j)))
-					// 创建 BatchElem 请求
-					batchElems[j] = rpc.BatchElem{
-						Method: "eth_getBlockByNumber",
-						Result: new(types.Header),
-						Error:  nil,
-					}
-					header := new(types.Header)
-					batchElems[j].Error = e.rpc.CallContext(ctx, header, batchElems[j].Method, toBlockNumArg(height), false)
-					batchElems[j].Result = header
-				}
-			}(start, end)
+	chunkSize := uint64(opts.chunkSize)
+	numChunks := (count-1)/chunkSize + 1
+	chunks := make([][]types.Header, numChunks)
+	errs := make([]error, numChunks)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, opts.concurrency)
+	for i := uint64(0); i < numChunks; i++ {
+		from := new(big.Int).Add(startHeight, new(big.Int).SetUint64(i*chunkSize))
+		size := min(chunkSize, count-i*chunkSize)
+		wg.Add(1)
+		go func(i uint64) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			chunks[i], errs[i] = retry.Do(context.Background(), opts.attempts, opts.strategy, func() ([]types.Header, error) {
+				return e.headersChunk(from, size)
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	var headers []types.Header
+	for i, chunk := range chunks {
+		if errs[i] != nil {
+			next := new(big.Int).Add(startHeight, new(big.Int).SetUint64(uint64(i)*chunkSize))
+			log.Error("get block headers chunk fail", "next", next, "err", errs[i])
+			return headers, &PartialRangeError{Next: next, Err: errs[i]}
 		}
-		wg.Wait()
-	} else {
-		for i := uint64(0); i < count; i++ {
-			height := new(big.Int).Add(startHeight, new(big.Int).SetUint64(i))
-			// 创建 BatchElem 请求
-			batchElems[i] = rpc.BatchElem{
-				Method: "eth_getBlockByNumber",
-				Args: []interface{}{
-					toBlockNumArg(height),
-					false,
-				},
-				Result: &headers[i],
-			}
+		headers = append(headers, chunk...)
+		if uint64(len(chunk)) < chunkSize && uint64(i) != numChunks-1 {
+			break
 		}
-		err := e.rpc.BatchCallContext(ctx, batchElems)
-		if err != nil {
-			return nil, err
+	}
+	return headers, nil
+}
+
+// 获取从 from 开始的 size 个区块头，遇到节点上不存在的区块时截断
+func (e *EthClient) headersChunk(from *big.Int, size uint64) ([]types.Header, error) {
+	headers := make([]*types.Header, size)
+	batchElems := make([]rpc.BatchElem, size)
+	for i := uint64(0); i < size; i++ {
+		height := new(big.Int).Add(from, new(big.Int).SetUint64(i))
+		// 创建 BatchElem 请求
+		batchElems[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args: []interface{}{
+				toBlockNumArg(height),
+				false,
+			},
+			Result: &headers[i],
 		}
 	}
+	ctx, cancelFunc := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancelFunc()
+	if err := e.batchCall(ctx, batchElems); err != nil {
+		return nil, err
+	}
 
-	fmt.Println("batchElems", batchElems)
-	size := 0
+	result := make([]types.Header, 0, size)
 	for i, batchElem := range batchElems {
-		header, ok := batchElem.Result.(*types.Header)
-		if !ok {
-			return nil, fmt.Errorf("unable to transform rpc response %v into types.Header", batchElem.Result)
+		if batchElem.Error != nil {
+			return nil, batchElem.Error
 		}
-		headers[i] = *header
-		size = size + 1
+		if headers[i] == nil {
+			break
+		}
+		result = append(result, *headers[i])
 	}
-	headers = headers[:size]
-	return headers, nil
+	return result, nil
+}
+
+func (e *EthClient) rangeOptions() rangeOptions {
+	opts := e.ranges
+	if opts.chunkSize <= 0 {
+		opts.chunkSize = e.Capabilities().MaxBatchSize
+	}
+	if opts.chunkSize <= 0 {
+		opts.chunkSize = defaultMaxBatchSize
+	}
+	if opts.concurrency <= 0 {
+		opts.concurrency = defaultRangeConcurrency
+	}
+	if opts.attempts <= 0 {
+		opts.attempts = defaultRangeAttempts
+	}
+	if opts.strategy == nil {
+		opts.strategy = retry.Exponential()
+	}
+	return opts
 }
 
 // 通过区块号获取区块数据  number: 目标区块号	分析区块内容
@@ -276,11 +380,14 @@ func (e *EthClient) BlockByHash(hash common.Hash) (*RpcBlock, error) {
 	return block, nil
 }
 
-// 获取最新安全区块头 PoS 链状态监控
+// 获取最新安全区块头 PoS 链状态监控，节点不支持 safe 标签时返回 NotFound
 func (e *EthClient) LatestSafeBlockHeader() (*types.Header, error) {
 	ctx, cancelFunc := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancelFunc()
 
+	if !e.Capabilities().FinalizedTag {
+		return nil, ethereum.NotFound
+	}
 	var header *types.Header
 	err := e.rpc.CallContext(ctx, &header, "eth_getBlockByNumber", "safe", false)
 	if err != nil {
@@ -291,11 +398,14 @@ func (e *EthClient) LatestSafeBlockHeader() (*types.Header, error) {
 	return header, nil
 }
 
-// 获取最终确认区块头 PoS 链状态监控
+// 获取最终确认区块头 PoS 链状态监控，节点不支持 finalized 标签时返回 NotFound
 func (e *EthClient) LatestFinalizedBlockHeader() (*types.Header, error) {
 	ctx, cancelFunc := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancelFunc()
 
+	if !e.Capabilities().FinalizedTag {
+		return nil, ethereum.NotFound
+	}
 	var header *types.Header
 	err := e.rpc.CallContext(ctx, &header, "eth_getBlockByNumber", "finalized", false)
 	if err != nil {
@@ -321,6 +431,20 @@ func (e *EthClient) TxCountByAddress(address common.Address) (hexutil.Uint64, er
 	return nonce, err
 }
 
+// 获取地址交易次数(Nonce)，包含交易池中待打包的交易
+func (e *EthClient) PendingTxCountByAddress(address common.Address) (hexutil.Uint64, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancelFunc()
+
+	var nonce hexutil.Uint64
+	err := e.rpc.CallContext(ctx, &nonce, "eth_getTransactionCount", address, "pending")
+	if err != nil {
+		log.Error("Call eth_getTransactionCount method fail", "err", err)
+		return 0, err
+	}
+	return nonce, err
+}
+
 // 广播签名交易 rawTx: 16进制签名 交易提交 返回交易哈希
 func (e *EthClient) SendRawTransaction(rawTx string) (*common.Hash, error) {
 	ctx, cancelFunc := context.WithTimeout(context.Background(), defaultRequestTimeout)
@@ -341,7 +465,6 @@ func (e *EthClient) TxByHash(hash common.Hash) (*types.Transaction, error) {
 
 	var tx *types.Transaction
 	err := e.rpc.CallContext(ctx, &tx, "eth_getTransactionByHash", hash)
-	fmt.Println("**********0", err)
 	if err != nil {
 		return nil, err
 	} else if tx == nil {
@@ -367,6 +490,48 @@ func (e *EthClient) TxReceiptByHash(hash common.Hash) (*types.Receipt, error) {
 	return txReceipt, nil
 }
 
+// 获取区块内全部交易的收据，节点不支持 eth_getBlockReceipts 时按区块交易逐个查询
+func (e *EthClient) BlockReceipts(number *big.Int) ([]*types.Receipt, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), defaultRequestTimeout)
+	defer cancelFunc()
+
+	if e.Capabilities().BlockReceipts {
+		var receipts []*types.Receipt
+		err := e.rpc.CallContext(ctx, &receipts, "eth_getBlockReceipts", toBlockNumArg(number))
+		if err != nil {
+			return nil, err
+		} else if receipts == nil {
+			return nil, ethereum.NotFound
+		}
+		return receipts, nil
+	}
+
+	block, err := e.BlockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	receipts := make([]*types.Receipt, len(block.Transactions))
+	batchElems := make([]rpc.BatchElem, len(block.Transactions))
+	for i, tx := range block.Transactions {
+		batchElems[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{common.HexToHash(tx.Hash)},
+			Result: &receipts[i],
+		}
+	}
+	if err := e.batchCall(ctx, batchElems); err != nil {
+		return nil, err
+	}
+	for i, batchElem := range batchElems {
+		if batchElem.Error != nil {
+			return nil, batchElem.Error
+		} else if receipts[i] == nil {
+			return nil, ethereum.NotFound
+		}
+	}
+	return receipts, nil
+}
+
 // 获取合约存储哈希 address: 合约地址 blockNumber: 区块号 状态验证
 func (e *EthClient) StorageHash(address common.Address, blockNumber *big.Int) (common.Hash, error) {
 	ctx, cancelFunc := context.WithTimeout(context.Background(), defaultRequestTimeout)
@@ -426,8 +591,11 @@ func (e *EthClient) SuggestGasPrice() (*big.Int, error) {
 	return (*big.Int)(&hex), nil
 }
 
-// 获取优先费建议 EIP-1559 交易优化
+// 获取优先费建议 EIP-1559 交易优化，不支持 EIP-1559 的链上优先费即为 gasPrice
 func (e *EthClient) SuggestGasTipCap() (*big.Int, error) {
+	if !e.Capabilities().Eip1559 {
+		return e.SuggestGasPrice()
+	}
 	ctx, cancelFunc := context.WithTimeout(context.Background(), defaultRequestTimeout)
 	defer cancelFunc()
 
@@ -438,16 +606,13 @@ func (e *EthClient) SuggestGasTipCap() (*big.Int, error) {
 	return (*big.Int)(&hex), nil
 }
 
-// 过滤事件日志 filterQuery: 过滤条件 chainId: 链ID 监听合约事件
-func (e *EthClient) FilterLogs(query ethereum.FilterQuery, chainId uint) (Logs, error) {
-	arg, err := toFilterArg(query)
-	if err != nil {
-		return Logs{}, err
-	}
-	var logs []types.Log
+// 过滤事件日志 filterQuery: 过滤条件 监听合约事件，区块范围超过节点限制时拆分查询
+func (e *EthClient) FilterLogs(query ethereum.FilterQuery) (Logs, error) {
+	queries := e.splitLogsQuery(query)
 	var header types.Header
+	parts := make([][]types.Log, len(queries))
 
-	batchElems := make([]rpc.BatchElem, 2)
+	batchElems := make([]rpc.BatchElem, len(queries)+1)
 	batchElems[0] = rpc.BatchElem{
 		Method: "eth_getBlockByNumber",
 		Args: []interface{}{
@@ -456,32 +621,35 @@ func (e *EthClient) FilterLogs(query ethereum.FilterQuery, chainId uint) (Logs,
 		},
 		Result: &header,
 	}
-	batchElems[1] = rpc.BatchElem{
-		Method: "eth_getLogs",
-		Args: []interface{}{
-			arg,
-		},
-		Result: &logs,
+	for i, item := range queries {
+		arg, err := toFilterArg(item)
+		if err != nil {
+			return Logs{}, err
+		}
+		batchElems[i+1] = rpc.BatchElem{
+			Method: "eth_getLogs",
+			Args: []interface{}{
+				arg,
+			},
+			Result: &parts[i],
+		}
 	}
 
 	ctx, cancelFunc := context.WithTimeout(context.Background(), defaultRequestTimeout*10)
 	defer cancelFunc()
-
-	if chainId == uint(global_const.ZkFairSepoliaChainId) || chainId == uint(global_const.ZkFairChainId) {
-		batchElems[0].Error = e.rpc.CallContext(ctx, &header, batchElems[0].Method, toBlockNumArg(query.ToBlock), false)
-		batchElems[1].Error = e.rpc.CallContext(ctx, &logs, batchElems[1].Method, arg)
-	} else {
-		err = e.rpc.BatchCallContext(ctx, batchElems)
-		if err != nil {
-			return Logs{}, err
-		}
+	if err := e.batchCall(ctx, batchElems); err != nil {
+		return Logs{}, err
 	}
 
 	if batchElems[0].Error != nil {
 		return Logs{}, fmt.Errorf("unable to query for the `FilterQuery#ToBlock` header: %w", batchElems[0].Error)
 	}
-	if batchElems[1].Error != nil {
-		return Logs{}, fmt.Errorf("unable to query logs: %w", batchElems[1].Error)
+	var logs []types.Log
+	for i, part := range parts {
+		if batchElems[i+1].Error != nil {
+			return Logs{}, fmt.Errorf("unable to query logs: %w", batchElems[i+1].Error)
+		}
+		logs = append(logs, part...)
 	}
 	return Logs{
 		Logs:          logs,
@@ -489,6 +657,67 @@ func (e *EthClient) FilterLogs(query ethereum.FilterQuery, chainId uint) (Logs,
 	}, nil
 }
 
+// 按 MaxLogsRange 拆分区块范围，按区块 Hash 查询或未指定结束区块时不拆分
+func (e *EthClient) splitLogsQuery(query ethereum.FilterQuery) []ethereum.FilterQuery {
+	maxRange := e.Capabilities().MaxLogsRange
+	if maxRange == 0 || query.BlockHash != nil || query.ToBlock == nil || query.ToBlock.Sign() < 0 ||
+		(query.FromBlock != nil && query.FromBlock.Sign() < 0) {
+		return []ethereum.FilterQuery{query}
+	}
+	from := new(big.Int)
+	if query.FromBlock != nil {
+		from.Set(query.FromBlock)
+	}
+	var queries []ethereum.FilterQuery
+	for from.Cmp(query.ToBlock) <= 0 {
+		to := new(big.Int).Add(from, new(big.Int).SetUint64(maxRange-1))
+		if to.Cmp(query.ToBlock) > 0 {
+			to.Set(query.ToBlock)
+		}
+		item := query
+		item.FromBlock, item.ToBlock = new(big.Int).Set(from), to
+		queries = append(queries, item)
+		from = new(big.Int).Add(to, big.NewInt(1))
+	}
+	if len(queries) == 0 {
+		return []ethereum.FilterQuery{query}
+	}
+	return queries
+}
+
+// 按节点能力发送请求：支持批量时按 MaxBatchSize 分批，否则按 MaxBatchSize 分组并发逐个请求，
+// 单个请求的错误记录在 BatchElem.Error 中
+func (e *EthClient) batchCall(ctx context.Context, batchElems []rpc.BatchElem) error {
+	caps := e.Capabilities()
+	groupSize := caps.MaxBatchSize
+	if groupSize <= 0 {
+		groupSize = defaultMaxBatchSize
+	}
+	if caps.Batch {
+		for start := 0; start < len(batchElems); start += groupSize {
+			end := min(start+groupSize, len(batchElems))
+			if err := e.rpc.BatchCallContext(ctx, batchElems[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for start := 0; start < len(batchElems); start += groupSize {
+		end := min(start+groupSize, len(batchElems))
+		wg.Add(1)
+		go func(group []rpc.BatchElem) {
+			defer wg.Done()
+			for j := range group {
+				group[j].Error = e.rpc.CallContext(ctx, group[j].Result, group[j].Method, group[j].Args...)
+			}
+		}(batchElems[start:end])
+	}
+	wg.Wait()
+	return nil
+}
+
 // 关闭eth客户端连接
 func (e *EthClient) Close() {
 	e.rpc.Close()
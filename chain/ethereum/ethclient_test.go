@@ -0,0 +1,89 @@
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"chain-account/common/global_const"
+	"chain-account/common/retry"
+	"chain-account/rpc/account"
+)
+
+func newRangeClient(node *fakeRpc, chunkSize, concurrency int) *EthClient {
+	return &EthClient{
+		rpc:  node,
+		caps: Capabilities{MaxBatchSize: defaultMaxBatchSize},
+		ranges: rangeOptions{
+			chunkSize:   chunkSize,
+			concurrency: concurrency,
+			attempts:    3,
+			strategy:    retry.Fixed(0),
+		},
+	}
+}
+
+func Test_BlockHeadersByRangeConcurrency(t *testing.T) {
+	node := &fakeRpc{latest: 1000, delay: 2 * time.Millisecond}
+	client := newRangeClient(node, 5, 2)
+	headers, err := client.BlockHeadersByRange(big.NewInt(1), big.NewInt(40))
+	if err != nil || len(headers) != 40 {
+		t.Fatalf("BlockHeadersByRange: %v %d", err, len(headers))
+	}
+	for i, header := range headers {
+		if header.Number.Uint64() != uint64(i+1) {
+			t.Fatalf("unexpected header order at %d: %d", i, header.Number.Uint64())
+		}
+	}
+	// 每段逐个请求，最多同时请求 2 段
+	if node.maxInflight != 2 {
+		t.Fatalf("unexpected concurrency %d", node.maxInflight)
+	}
+}
+
+func Test_BlockHeadersByRangeRetry(t *testing.T) {
+	// 失败次数少于最大尝试次数时重试整段
+	node := &fakeRpc{batch: true, latest: 1000, failures: map[uint64]int{12: 2}}
+	client := newRangeClient(node, 5, 4)
+	client.caps.Batch = true
+	headers, err := client.BlockHeadersByRange(big.NewInt(1), big.NewInt(20))
+	if err != nil || len(headers) != 20 {
+		t.Fatalf("BlockHeadersByRange: %v %d", err, len(headers))
+	}
+
+	// 某段最终失败时返回之前连续获取到的区块头
+	node = &fakeRpc{batch: true, latest: 1000, failures: map[uint64]int{12: 3}}
+	client = newRangeClient(node, 5, 4)
+	client.caps.Batch = true
+	headers, err = client.BlockHeadersByRange(big.NewInt(1), big.NewInt(20))
+	var partialErr *PartialRangeError
+	if !errors.As(err, &partialErr) || partialErr.Next.Uint64() != 11 || len(headers) != 10 {
+		t.Fatalf("expected partial result, got %v %d", err, len(headers))
+	}
+}
+
+func Test_GetBlockByRangeLimit(t *testing.T) {
+	node := &fakeRpc{batch: true, latest: 1000, failures: map[uint64]int{8: 3}}
+	client := newRangeClient(node, 5, 4)
+	client.caps.Batch = true
+	adaptor := &ChainAdaptor{EthClient: client, MaxBlockRange: 50}
+
+	resp, err := adaptor.GetBlockByRange(&account.BlockByRangeRequest{Start: "1", End: "51"})
+	if err != nil || resp.Code != global_const.ReturnCode_ERROR || len(node.calls) != 0 {
+		t.Fatalf("expected range limit error, got %v %v", err, resp)
+	}
+	resp, _ = adaptor.GetBlockByRange(&account.BlockByRangeRequest{Start: "10", End: "1"})
+	if resp.Code != global_const.ReturnCode_ERROR || resp.Msg != "invalid block range" {
+		t.Fatalf("expected invalid range, got %v", resp)
+	}
+
+	resp, err = adaptor.GetBlockByRange(&account.BlockByRangeRequest{Start: "1", End: "50"})
+	if err != nil || resp.Code != global_const.ReturnCode_SUCCESS || len(resp.BlockHeader) != 5 {
+		t.Fatalf("GetBlockByRange: %v %v", err, resp)
+	}
+	if !strings.HasSuffix(resp.Msg, "next start 6") {
+		t.Fatalf("unexpected msg %s", resp.Msg)
+	}
+}
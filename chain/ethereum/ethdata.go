@@ -1,6 +1,7 @@
 package ethereum
 
 import (
+	"context"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -8,10 +9,16 @@ import (
 	"github.com/dapplink-labs/chain-explorer-api/common/account"
 	"github.com/dapplink-labs/chain-explorer-api/common/chain"
 	"github.com/dapplink-labs/chain-explorer-api/explorer/etherscan"
+
+	"chain-account/common/retry"
 )
 
+const defaultDataAttempts = 3
+
 type EthData struct {
 	EthScanCli *etherscan.ChainExplorerAdaptor
+	breaker    *retry.Breaker
+	strategy   retry.Strategy
 }
 
 // 初始化EthData
@@ -23,9 +30,24 @@ func NewEthData(baseUrl, apiKey string, timeout time.Duration) (*EthData, error)
 	}
 	return &EthData{
 		EthScanCli: ethScanCli,
+		breaker:    retry.NewBreaker(0, 0),
+		strategy:   retry.Exponential(),
 	}, err
 }
 
+// 经过熔断器请求浏览器接口，可重试的错误（限流、超时等）退避重试
+func dataCall[T any](ed *EthData, op func() (T, error)) (T, error) {
+	result, err := retry.Do(context.Background(), defaultDataAttempts, ed.strategy, func() (T, error) {
+		var result T
+		err := ed.breaker.Do(func() (err error) {
+			result, err = op()
+			return err
+		})
+		return result, err
+	})
+	return result, lastError(err)
+}
+
 // 通过地址获取交易记录
 func (ed *EthData) GetTxByAddress(pageNum, pageSize uint64, address string, action account.ActionType) (*account.TransactionResponse[account.AccountTxResponse], error) {
 	request := &account.AccountTxRequest{
@@ -36,7 +58,9 @@ func (ed *EthData) GetTxByAddress(pageNum, pageSize uint64, address string, acti
 		Action:  action,
 		Address: address,
 	}
-	txData, err := ed.EthScanCli.GetTxByAddress(request)
+	txData, err := dataCall(ed, func() (*account.TransactionResponse[account.AccountTxResponse], error) {
+		return ed.EthScanCli.GetTxByAddress(request)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +85,9 @@ func (ed *EthData) GetBalanceByAddress(contractAddr, address string) (*account.A
 		Page:            page,
 		Limit:           limit,
 	}
-	etherscanResp, err := ed.EthScanCli.GetAccountBalance(acbr)
+	etherscanResp, err := dataCall(ed, func() (*account.AccountBalanceResponse, error) {
+		return ed.EthScanCli.GetAccountBalance(acbr)
+	})
 	if err != nil {
 		log.Error("get account balance error", "err", err)
 		return nil, err
@@ -14,7 +14,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/btcsuite/btcd/btcec/v2"
 	account2 "github.com/dapplink-labs/chain-explorer-api/common/account"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -27,6 +26,7 @@ import (
 
 	"chain-account/chain"
 	"chain-account/common/global_const"
+	"chain-account/common/store"
 	"chain-account/common/util"
 	"chain-account/config"
 	"chain-account/rpc/account"
@@ -35,27 +35,102 @@ import (
 const ChainName = "Ethereum"
 
 type ChainAdaptor struct {
+	// 链名称，用于通知事件等对外标识，L2 适配器复用时为各自的链名
+	ChainName string
 	EthClient IEth
 	EthData   *EthData
+	Deposits  *DepositMonitor
+	Outgoing  *OutgoingTracker
+	Nonces    *NonceManager
+	Cache     *CachedEth // 未开启缓存时为 nil
+	// GetBlockByRange 单次请求的最大区块数，为 0 时使用 global_const.BlocksLimit
+	MaxBlockRange uint64
 }
 
 func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
-	rpcUrl := con.WalletNode.Eth.RpcUrl
-	dataApiUrl := con.WalletNode.Eth.DataApiUrl
-	dataApiKey := con.WalletNode.Eth.DataApiKey
+	return NewEvmChainAdaptor(ChainName, "ethereum", con.WalletNode.Eth, con.DataDir)
+}
 
-	ethClient, err := NewEthClient(context.Background(), rpcUrl)
+// 按节点配置创建 EVM 链适配器，chainName 为链名称，storeName 为持久化文件名前缀，供其他 EVM 链复用
+func NewEvmChainAdaptor(chainName, storeName string, node config.Node, dataDir string) (*ChainAdaptor, error) {
+	ethClient, err := NewEthClient(context.Background(), node.RpcUrl, node.Rpc)
 	if err != nil {
 		return nil, err
 	}
 
-	ethData, err2 := NewEthData(dataApiUrl, dataApiKey, time.Second*35)
+	// 不可变数据缓存，充值监控、交易跟踪等共用同一个缓存
+	var cache *CachedEth
+	if node.Cache.Enable {
+		// 磁盘缓存使用 LevelDB，每次写入和淘汰只涉及对应的 key
+		var diskStore store.Store
+		if node.Cache.Persist && dataDir != "" {
+			diskStore, err = store.NewLevelStore(store.Path(dataDir, storeName+"_cache"))
+			if err != nil {
+				return nil, err
+			}
+		}
+		cache, err = NewCachedEth(ethClient, node.Cache, diskStore)
+		if err != nil {
+			return nil, err
+		}
+		cache.Start()
+		ethClient = cache
+	}
+
+	ethData, err2 := NewEthData(node.DataApiUrl, node.DataApiKey, time.Second*35)
 	if err2 != nil {
 		return nil, err2
 	}
+
+	// 充值监控
+	var deposits *DepositMonitor
+	if node.Deposit.Enable {
+		depositStore, err := store.NewStore(store.Path(dataDir, storeName+"_deposit"))
+		if err != nil {
+			return nil, err
+		}
+		deposits, err = NewDepositMonitor(ethClient, node.Deposit, depositStore)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 发出交易跟踪
+	var outgoing *OutgoingTracker
+	if node.Outgoing.Enable {
+		outgoingStore, err := store.NewStore(store.Path(dataDir, storeName+"_outgoing"))
+		if err != nil {
+			return nil, err
+		}
+		outgoing, err = NewOutgoingTracker(ethClient, node.Outgoing, outgoingStore)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// nonce 分配，未开启持久化时仅保存在内存中
+	var nonces *NonceManager
+	if node.Nonce.Enable {
+		noncePath := ""
+		if node.Nonce.Persist {
+			noncePath = store.Path(dataDir, storeName+"_nonce")
+		}
+		nonceStore, err := store.NewStore(noncePath)
+		if err != nil {
+			return nil, err
+		}
+		nonces = NewNonceManager(ethClient, node.Nonce, nonceStore)
+	}
 	return &ChainAdaptor{
+		ChainName: chainName,
 		EthClient: ethClient,
 		EthData:   ethData,
+		Deposits:  deposits,
+		Outgoing:  outgoing,
+		Nonces:    nonces,
+		Cache:     cache,
+
+		MaxBlockRange: node.Rpc.MaxBlockRange,
 	}, nil
 }
 
@@ -71,6 +146,12 @@ func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*acc
 
 // 传入公钥 转换成地址
 func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeSecp256k1); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
 	// 1. 处理 0x 前缀
 	publicKeyStr := strings.TrimPrefix(req.PublicKey, "0x")
 
@@ -85,7 +166,7 @@ func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*acco
 	}
 
 	// 2. 解压公钥
-	pubKey, err := decompressPublicKey(publicKeyBytes)
+	pubKey, err := util.DecompressPublicKey(publicKeyBytes)
 	if err != nil {
 		return &account.ConvertAddressResponse{
 			Code:    global_const.ReturnCode_ERROR,
@@ -353,6 +434,15 @@ func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxRespon
 			Msg:  "send tx fail",
 		}, nil
 	}
+	// 记录已广播的交易，后台跟踪直到上链
+	if c.Outgoing != nil || c.Nonces != nil {
+		tx, err := decodeRawTx(req.RawTx)
+		if err != nil {
+			log.Warn("decode raw tx fail, skip tracking", "hash", transaction.String(), "err", err)
+		} else {
+			c.trackSentTx(req.RawTx, tx)
+		}
+	}
 	return &account.SendTxResponse{
 		Code:   global_const.ReturnCode_SUCCESS,
 		Msg:    "send tx success",
@@ -360,6 +450,23 @@ func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxRespon
 	}, nil
 }
 
+func (c *ChainAdaptor) trackSentTx(rawTx string, tx *types.Transaction) {
+	if c.Outgoing != nil {
+		if err := c.Outgoing.Track(rawTx, tx); err != nil {
+			log.Warn("track outgoing tx fail", "hash", tx.Hash().Hex(), "err", err)
+		}
+	}
+	if c.Nonces != nil {
+		from, err := types.Sender(txSigner(tx), tx)
+		if err != nil {
+			return
+		}
+		if err := c.Nonces.Commit(from, tx.Nonce(), tx.Hash()); err != nil {
+			log.Warn("commit nonce fail", "hash", tx.Hash().Hex(), "err", err)
+		}
+	}
+}
+
 // 通过地址获取交易记录
 func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
 	var resp *account2.TransactionResponse[account2.AccountTxResponse]
@@ -402,24 +509,34 @@ func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.T
 func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
 	// 按Hash 获取交易详情
 	transaction, err := c.EthClient.TxByHash(common.HexToHash(req.Hash))
-	if err != nil {
-		log.Error("get tx by hash fail:", err)
+	if errors.Is(err, ethereum.NotFound) {
+		// 节点上查不到的交易（如已被替换或丢弃）以跟踪记录为准
+		if c.Outgoing != nil {
+			if tracked, ok := c.Outgoing.Get(common.HexToHash(req.Hash)); ok {
+				return &account.TxHashResponse{
+					Code: global_const.ReturnCode_SUCCESS,
+					Msg:  "get transaction success",
+					Tx:   tracked.toTxMessage(),
+				}, nil
+			}
+		}
+		log.Error("Ethereum Tx NotFound:", err)
 		return &account.TxHashResponse{
 			Code: global_const.ReturnCode_ERROR,
-			Msg:  "get tx by hash fail",
+			Msg:  "Ethereum Tx NotFound",
 		}, nil
 	}
-	if errors.Is(err, ethereum.NotFound) {
-		log.Error("Ethereum Tx NotFound:", err)
+	if err != nil {
+		log.Error("get tx by hash fail:", err)
 		return &account.TxHashResponse{
 			Code: global_const.ReturnCode_ERROR,
-			Msg:  "Ethereum Tx NotFound",
+			Msg:  "get tx by hash fail",
 		}, nil
 	}
 
-	// 按Hash 获取交易收据
+	// 按Hash 获取交易收据，没有收据说明交易还在 mempool 中
 	receipt, err := c.EthClient.TxReceiptByHash(common.HexToHash(req.Hash))
-	if err != nil {
+	if err != nil && !errors.Is(err, ethereum.NotFound) {
 		log.Error("get tx receipt by hash fail:", err)
 		return &account.TxHashResponse{
 			Code: global_const.ReturnCode_ERROR,
@@ -427,6 +544,29 @@ func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashR
 		}, nil
 	}
 
+	// 合约创建交易没有接收地址
+	if transaction.To() == nil {
+		tx := &account.TxMessage{
+			Hash:            transaction.Hash().Hex(),
+			Value:           transaction.Value().String(),
+			Fee:             transaction.GasFeeCap().String(),
+			Status:          account.TxStatus_Pending,
+			ContractAddress: common.Address{}.String(),
+			Data:            hexutils.BytesToHex(transaction.Data()),
+		}
+		if receipt != nil {
+			tx.Index = uint32(receipt.TransactionIndex)
+			tx.To = receipt.ContractAddress.String()
+			tx.Status = receiptStatus(receipt)
+			tx.Height = receipt.BlockNumber.String()
+		}
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "get transaction success",
+			Tx:   tx,
+		}, nil
+	}
+
 	// 按地址获取合约字节码
 	var beforeToAddress string
 	var beforeTokenAddress string
@@ -458,39 +598,71 @@ func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashR
 		beforeValue = transaction.Value()
 	}
 
-	var txStatus account.TxStatus
-	if receipt.Status == 1 {
-		txStatus = account.TxStatus_Success
-	} else {
-		txStatus = account.TxStatus_Failed
+	if beforeValue == nil {
+		beforeValue = big.NewInt(0)
+	}
+
+	txStatus := account.TxStatus_Pending
+	var txIndex uint32
+	var height string
+	if receipt != nil {
+		txStatus = receiptStatus(receipt)
+		txIndex = uint32(receipt.TransactionIndex)
+		height = receipt.BlockNumber.String()
 	}
 	return &account.TxHashResponse{
 		Code: global_const.ReturnCode_SUCCESS,
 		Msg:  "get transaction success",
 		Tx: &account.TxMessage{
 			Hash:            transaction.Hash().Hex(),
-			Index:           uint32(receipt.TransactionIndex),
+			Index:           txIndex,
 			From:            beforeTokenAddress, // 代币合约地址
 			To:              beforeToAddress,    // 实际接收地址
 			Value:           beforeValue.String(),
 			Fee:             transaction.GasFeeCap().String(),
 			Status:          txStatus,
 			Type:            0,
-			Height:          receipt.BlockNumber.String(),
+			Height:          height,
 			ContractAddress: beforeTokenAddress,
 			Data:            hexutils.BytesToHex(transaction.Data()),
 		},
 	}, nil
 }
 
-// 批量获取区块头信息
+// 根据收据判断交易状态
+func receiptStatus(receipt *types.Receipt) account.TxStatus {
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return account.TxStatus_Success
+	}
+	return account.TxStatus_Failed
+}
+
+// 批量获取区块头信息，单次最多 max_block_range 个区块；只获取到部分区块时返回已获取的部分，msg 中带下一个起始高度
 func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
-	startBlock := new(big.Int)
-	endBlock := new(big.Int)
-	startBlock.SetString(req.Start, 10)
-	endBlock.SetString(req.End, 10)
-	blockRange, err := c.EthClient.BlockHeadersByRange(startBlock, endBlock, 1)
-	if err != nil {
+	startBlock, ok1 := new(big.Int).SetString(req.Start, 10)
+	endBlock, ok2 := new(big.Int).SetString(req.End, 10)
+	if !ok1 || !ok2 || startBlock.Sign() < 0 || startBlock.Cmp(endBlock) > 0 {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid block range",
+		}, nil
+	}
+	limit := c.MaxBlockRange
+	if limit == 0 {
+		limit = global_const.BlocksLimit
+	}
+	if count := new(big.Int).Sub(endBlock, startBlock); !count.IsUint64() || count.Uint64() >= limit {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  fmt.Sprintf("block range exceeds limit %d", limit),
+		}, nil
+	}
+	msg := "get block range success"
+	blockRange, err := c.EthClient.BlockHeadersByRange(startBlock, endBlock)
+	var partialErr *PartialRangeError
+	if errors.As(err, &partialErr) && len(blockRange) > 0 {
+		msg = "get block range partial success, next start " + partialErr.Next.String()
+	} else if err != nil {
 		log.Error("get block range fail", "err", err)
 		return &account.BlockByRangeResponse{
 			Code: global_const.ReturnCode_ERROR,
@@ -506,7 +678,7 @@ func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*accou
 			Root:             block.Root.String(),
 			TxHash:           block.TxHash.String(),
 			ReceiptHash:      block.ReceiptHash.String(),
-			ParentBeaconRoot: block.ParentBeaconRoot.String(),
+			ParentBeaconRoot: common.Hash{}.String(),
 			Difficulty:       block.Difficulty.String(),
 			Number:           block.Number.String(),
 			GasLimit:         block.GasLimit,
@@ -516,24 +688,37 @@ func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*accou
 			MixDigest:        block.MixDigest.String(),
 			Nonce:            strconv.FormatUint(block.Nonce.Uint64(), 10),
 			BaseFee:          block.BaseFee.String(),
-			WithdrawalsHash:  block.WithdrawalsHash.String(),
-			BlobGasUsed:      *block.BlobGasUsed,
-			ExcessBlobGas:    *block.ExcessBlobGas,
+			WithdrawalsHash:  common.Hash{}.String(),
+		}
+		// 升级前的区块和部分 L2 的区块头没有以下字段
+		if block.ParentBeaconRoot != nil {
+			blockItem.ParentBeaconRoot = block.ParentBeaconRoot.String()
+		}
+		if block.WithdrawalsHash != nil {
+			blockItem.WithdrawalsHash = block.WithdrawalsHash.String()
+		}
+		if block.BlobGasUsed != nil {
+			blockItem.BlobGasUsed = *block.BlobGasUsed
+		}
+		if block.ExcessBlobGas != nil {
+			blockItem.ExcessBlobGas = *block.ExcessBlobGas
 		}
 		headerList = append(headerList, blockItem)
 	}
 	return &account.BlockByRangeResponse{
 		Code:        global_const.ReturnCode_SUCCESS,
-		Msg:         "get block range success",
+		Msg:         msg,
 		BlockHeader: headerList,
 	}, nil
 }
 
-// 构建符合 EIP-1559 标准的未签名的交易
+// 构建未签名的交易，节点不支持 EIP-1559 时构建传统交易，gasPrice 取 max_fee_per_gas；
+// 返回的 base64_tx 中记录了选择的交易类型，BuildSignedTransaction 按此构建，不受期间节点能力变化的影响
 func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
-	dFeeTx, _, err := buildDynamicFeeTx(req.Base64Tx)
+	dFeeTx, dynamicFeeTx, err := buildDynamicFeeTx(req.Base64Tx)
 	if err != nil {
 		log.Error("build dynamic fee tx fail", "err", err)
+		c.releaseTxNonce(req.Base64Tx)
 		return &account.UnSignTransactionResponse{
 			Code: global_const.ReturnCode_ERROR,
 			Msg:  "build dynamic fee tx fail",
@@ -541,23 +726,30 @@ func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequ
 	}
 	log.Info("ethereum BuildUnSignTransaction", "dFeeTx", util.ToJSONString(dFeeTx))
 
-	rawTx, err := CreateEip1559UnSignTx(dFeeTx, dFeeTx.ChainID)
+	txType := c.txType(dynamicFeeTx)
+	rawTx, err := createUnSignTx(dFeeTx, txType)
 	if err != nil {
-		log.Error("create eip1559 unSign tx fail", "err", err)
+		log.Error("create unSign tx fail", "err", err)
+		c.releaseTxNonce(req.Base64Tx)
 		return &account.UnSignTransactionResponse{
 			Code: global_const.ReturnCode_ERROR,
-			Msg:  "create eip1559 unSign tx fail",
+			Msg:  "create unSign tx fail",
 		}, err
 	}
+	dynamicFeeTx.TxType = &txType
+	txJson, _ := json.Marshal(dynamicFeeTx)
 
 	return &account.UnSignTransactionResponse{
 		Code:     global_const.ReturnCode_SUCCESS,
 		Msg:      "build dynamic fee tx success",
 		UnSignTx: rawTx,
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
 	}, nil
 }
 
-// 构建并验证 EIP-1559 标准签名交易,将签名后的交易数据序列化，并验证签名地址的合法性
+// 构建并验证签名交易,将签名后的交易数据序列化，并验证签名地址的合法性；
+// base64_tx 应使用 BuildUnSignTransaction 返回的 base64_tx，按其中的交易类型构建。
+// 未签名交易已交给调用方，失败时不归还 nonce，由调用方通过 ReleaseNonce 归还或等待分配超时
 func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
 	dFeeTx, dynamicFeeTx, err := buildDynamicFeeTx(req.Base64Tx)
 	if err != nil {
@@ -580,12 +772,12 @@ func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequ
 		}, err
 	}
 
-	signer, signedTx, rawTx, txHash, err := CreateEip1559SignedTx(dFeeTx, inputSignatureByteList, dFeeTx.ChainID)
+	signer, signedTx, rawTx, txHash, err := createSignedTx(dFeeTx, c.txType(dynamicFeeTx), inputSignatureByteList)
 	if err != nil {
-		log.Error("create eip1559 signed tx fail", "err", err)
+		log.Error("create signed tx fail", "err", err)
 		return &account.SignedTransactionResponse{
 			Code: global_const.ReturnCode_ERROR,
-			Msg:  "create eip1559 signed tx fail",
+			Msg:  "create signed tx fail",
 		}, err
 	}
 	log.Info("ethereum BuildSignedTransaction", "rawTx", rawTx)
@@ -687,7 +879,15 @@ func buildDynamicFeeTx(base64Tx string) (*types.DynamicFeeTx, *Eip1559DynamicFee
 		"isEthTransfer", isEthTransfer(&dynamicFeeTx),
 	)
 
-	if isEthTransfer(&dynamicFeeTx) {
+	if dynamicFeeTx.Data != "" {
+		// 原始调用数据，按原样发送（用于替换交易等场景）
+		buildData, err = hexutil.Decode(dynamicFeeTx.Data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid data: %s", dynamicFeeTx.Data)
+		}
+		finalToAddress = toAddress
+		finalAmount = amount
+	} else if isEthTransfer(&dynamicFeeTx) {
 		// ETH 转账
 		finalToAddress = toAddress
 		finalAmount = amount
@@ -715,6 +915,49 @@ func buildDynamicFeeTx(base64Tx string) (*types.DynamicFeeTx, *Eip1559DynamicFee
 	return dFeeTx, &dynamicFeeTx, nil
 }
 
+// 交易类型：请求中已指定时使用指定的类型，否则节点支持 EIP-1559 时使用动态费用交易，不支持时使用传统交易
+func (c *ChainAdaptor) txType(tx *Eip1559DynamicFeeTx) uint8 {
+	if tx.TxType != nil {
+		return *tx.TxType
+	}
+	if capabilities(c.EthClient).Eip1559 {
+		return types.DynamicFeeTxType
+	}
+	return types.LegacyTxType
+}
+
+// 传统交易的 gasPrice 取 maxFeePerGas
+func legacyTx(dFeeTx *types.DynamicFeeTx) *types.LegacyTx {
+	return &types.LegacyTx{
+		Nonce:    dFeeTx.Nonce,
+		GasPrice: dFeeTx.GasFeeCap,
+		Gas:      dFeeTx.Gas,
+		To:       dFeeTx.To,
+		Value:    dFeeTx.Value,
+		Data:     dFeeTx.Data,
+	}
+}
+
+func createUnSignTx(dFeeTx *types.DynamicFeeTx, txType uint8) (string, error) {
+	switch txType {
+	case types.LegacyTxType:
+		return CreateLegacyUnSignTx(legacyTx(dFeeTx), dFeeTx.ChainID), nil
+	case types.DynamicFeeTxType:
+		return CreateEip1559UnSignTx(dFeeTx, dFeeTx.ChainID)
+	}
+	return "", fmt.Errorf("unsupported tx type: %d", txType)
+}
+
+func createSignedTx(dFeeTx *types.DynamicFeeTx, txType uint8, signature []byte) (types.Signer, *types.Transaction, string, string, error) {
+	switch txType {
+	case types.LegacyTxType:
+		return CreateLegacySignedTx(legacyTx(dFeeTx), signature, dFeeTx.ChainID)
+	case types.DynamicFeeTxType:
+		return CreateEip1559SignedTx(dFeeTx, signature, dFeeTx.ChainID)
+	}
+	return nil, nil, "", "", fmt.Errorf("unsupported tx type: %d", txType)
+}
+
 // 判断是否为 ETH 转账
 func isEthTransfer(tx *Eip1559DynamicFeeTx) bool {
 	// 检查合约地址是否为空或零地址
@@ -725,16 +968,3 @@ func isEthTransfer(tx *Eip1559DynamicFeeTx) bool {
 	}
 	return false
 }
-
-// 解压公钥（兼容压缩和非压缩格式）
-func decompressPublicKey(pubKeyBytes []byte) (*btcec.PublicKey, error) {
-	if len(pubKeyBytes) == 33 {
-		// 压缩公钥（0x02/0x03 开头）
-		return btcec.ParsePubKey(pubKeyBytes)
-	} else if len(pubKeyBytes) == 65 && pubKeyBytes[0] == 0x04 {
-		// 非压缩公钥（0x04 开头）
-		return btcec.ParsePubKey(pubKeyBytes)
-	} else {
-		return nil, fmt.Errorf("invalid public key format")
-	}
-}
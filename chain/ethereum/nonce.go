@@ -0,0 +1,364 @@
+package ethereum
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/common/store"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const (
+	defaultReserveTimeout = 10 * time.Minute
+
+	nonceKeyPrefix = "nonce/"
+)
+
+var errNonceNotReserved = errors.New("nonce not reserved")
+
+// 单个地址的 nonce 分配状态
+type nonceState struct {
+	Next     uint64            `json:"next"`     // 本地下一个可分配的 nonce
+	Reserved map[uint64]int64  `json:"reserved"` // 已分配、尚未广播的 nonce 及分配时间
+	Released []uint64          `json:"released"` // 构建失败归还、优先重新分配的 nonce
+	Sent     map[uint64]string `json:"sent"`     // 已广播、尚未上链的 nonce 及交易哈希
+}
+
+// nonce 管理：按地址分配 nonce，保证并发构建交易时不会重复，并识别因交易丢失产生的空洞
+type NonceManager struct {
+	client  IEth
+	store   store.Store
+	timeout time.Duration
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func NewNonceManager(client IEth, conf config.Nonce, db store.Store) *NonceManager {
+	timeout := time.Duration(conf.ReserveTimeout) * time.Second
+	if timeout == 0 {
+		timeout = defaultReserveTimeout
+	}
+	return &NonceManager{
+		client:  client,
+		store:   db,
+		timeout: timeout,
+		locks:   make(map[string]*sync.Mutex),
+	}
+}
+
+// 同一地址的操作串行执行，不同地址互不影响
+func (m *NonceManager) lock(key string) func() {
+	m.mu.Lock()
+	l, ok := m.locks[key]
+	if !ok {
+		l = new(sync.Mutex)
+		m.locks[key] = l
+	}
+	m.mu.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+func (m *NonceManager) load(key string) (*nonceState, error) {
+	state := &nonceState{}
+	if _, err := m.store.Get(key, state); err != nil {
+		return nil, err
+	}
+	if state.Reserved == nil {
+		state.Reserved = make(map[uint64]int64)
+	}
+	if state.Sent == nil {
+		state.Sent = make(map[uint64]string)
+	}
+	return state, nil
+}
+
+// 结合链上 nonce 整理本地状态：清理已上链的记录，超时未广播的分配视为归还
+func (m *NonceManager) refresh(state *nonceState, latest, pending uint64) {
+	now := time.Now().Unix()
+	for nonce, reservedAt := range state.Reserved {
+		if nonce < latest {
+			delete(state.Reserved, nonce)
+		} else if now-reservedAt >= int64(m.timeout/time.Second) {
+			delete(state.Reserved, nonce)
+			state.Released = append(state.Released, nonce)
+		}
+	}
+	for nonce := range state.Sent {
+		if nonce < latest {
+			delete(state.Sent, nonce)
+		}
+	}
+	// 交易池中已有的 nonce 不能再分配
+	released := state.Released[:0]
+	for _, nonce := range state.Released {
+		if nonce >= pending && nonce < state.Next {
+			released = append(released, nonce)
+		}
+	}
+	state.Released = released
+	sort.Slice(state.Released, func(i, j int) bool { return state.Released[i] < state.Released[j] })
+	if state.Next < pending {
+		state.Next = pending
+	}
+}
+
+func (m *NonceManager) chainNonce(address common.Address) (uint64, uint64, error) {
+	latest, err := m.client.TxCountByAddress(address)
+	if err != nil {
+		return 0, 0, err
+	}
+	pending, err := m.client.PendingTxCountByAddress(address)
+	if err != nil {
+		return 0, 0, err
+	}
+	return uint64(latest), uint64(pending), nil
+}
+
+// 分配下一个 nonce，返回分配结果和节点上的 pending nonce
+func (m *NonceManager) Reserve(address common.Address) (uint64, uint64, error) {
+	key := nonceKeyPrefix + strings.ToLower(address.Hex())
+	defer m.lock(key)()
+
+	latest, pending, err := m.chainNonce(address)
+	if err != nil {
+		return 0, 0, err
+	}
+	state, err := m.load(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	m.refresh(state, latest, pending)
+
+	var nonce uint64
+	if len(state.Released) > 0 {
+		nonce = state.Released[0]
+		state.Released = state.Released[1:]
+	} else {
+		nonce = state.Next
+		state.Next++
+	}
+	state.Reserved[nonce] = time.Now().Unix()
+	if err := m.store.Put(key, state); err != nil {
+		return 0, 0, err
+	}
+	log.Info("reserve nonce", "address", address.Hex(), "nonce", nonce, "pending", pending)
+	return nonce, pending, nil
+}
+
+// 归还构建失败的 nonce
+func (m *NonceManager) Release(address common.Address, nonce uint64) error {
+	key := nonceKeyPrefix + strings.ToLower(address.Hex())
+	defer m.lock(key)()
+
+	state, err := m.load(key)
+	if err != nil {
+		return err
+	}
+	if _, ok := state.Reserved[nonce]; !ok {
+		return errNonceNotReserved
+	}
+	delete(state.Reserved, nonce)
+	state.Released = append(state.Released, nonce)
+	sort.Slice(state.Released, func(i, j int) bool { return state.Released[i] < state.Released[j] })
+	// 归还的是末尾的 nonce 时直接回退，避免留下空洞
+	for len(state.Released) > 0 && state.Released[len(state.Released)-1] == state.Next-1 {
+		state.Released = state.Released[:len(state.Released)-1]
+		state.Next--
+	}
+	log.Info("release nonce", "address", address.Hex(), "nonce", nonce)
+	return m.store.Put(key, state)
+}
+
+// 交易广播成功后记录 nonce 对应的交易
+func (m *NonceManager) Commit(address common.Address, nonce uint64, hash common.Hash) error {
+	key := nonceKeyPrefix + strings.ToLower(address.Hex())
+	defer m.lock(key)()
+
+	state, err := m.load(key)
+	if err != nil {
+		return err
+	}
+	delete(state.Reserved, nonce)
+	for i, released := range state.Released {
+		if released == nonce {
+			state.Released = append(state.Released[:i], state.Released[i+1:]...)
+			break
+		}
+	}
+	state.Sent[nonce] = hash.Hex()
+	if nonce >= state.Next {
+		state.Next = nonce + 1
+	}
+	return m.store.Put(key, state)
+}
+
+// nonce 使用情况
+type NonceGaps struct {
+	Latest   uint64
+	Pending  uint64
+	Next     uint64
+	Reserved []uint64
+	Gaps     []uint64
+}
+
+// 查询 nonce 空洞：pending nonce 到本地已分配的最大 nonce 之间，既未在构建中、交易池中也查不到交易的 nonce
+func (m *NonceManager) Gaps(address common.Address) (*NonceGaps, error) {
+	key := nonceKeyPrefix + strings.ToLower(address.Hex())
+	defer m.lock(key)()
+
+	latest, pending, err := m.chainNonce(address)
+	if err != nil {
+		return nil, err
+	}
+	state, err := m.load(key)
+	if err != nil {
+		return nil, err
+	}
+	m.refresh(state, latest, pending)
+	if err := m.store.Put(key, state); err != nil {
+		return nil, err
+	}
+
+	result := &NonceGaps{Latest: latest, Pending: pending, Next: state.Next}
+	for nonce := range state.Reserved {
+		result.Reserved = append(result.Reserved, nonce)
+	}
+	sort.Slice(result.Reserved, func(i, j int) bool { return result.Reserved[i] < result.Reserved[j] })
+
+	for nonce := pending; nonce < state.Next; nonce++ {
+		if _, ok := state.Reserved[nonce]; ok {
+			continue
+		}
+		// 已广播的交易仍在交易池中（排在空洞之后）则不算空洞
+		if hash, ok := state.Sent[nonce]; ok {
+			if _, err := m.client.TxByHash(common.HexToHash(hash)); err == nil {
+				continue
+			} else if !errors.Is(err, ethereum.NotFound) {
+				return nil, err
+			}
+		}
+		result.Gaps = append(result.Gaps, nonce)
+	}
+	return result, nil
+}
+
+// 分配下一个可用的 nonce
+func (c *ChainAdaptor) GetNextNonce(req *account.NextNonceRequest) (*account.NextNonceResponse, error) {
+	if c.Nonces == nil {
+		return &account.NextNonceResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "nonce manager not enabled",
+		}, nil
+	}
+	if !common.IsHexAddress(req.Address) {
+		return &account.NextNonceResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	nonce, pending, err := c.Nonces.Reserve(common.HexToAddress(req.Address))
+	if err != nil {
+		log.Error("reserve nonce fail", "address", req.Address, "err", err)
+		return &account.NextNonceResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "reserve nonce fail",
+		}, nil
+	}
+	return &account.NextNonceResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          "get next nonce success",
+		Nonce:        nonce,
+		PendingNonce: pending,
+	}, nil
+}
+
+// 归还未使用的 nonce
+func (c *ChainAdaptor) ReleaseNonce(req *account.ReleaseNonceRequest) (*account.ReleaseNonceResponse, error) {
+	if c.Nonces == nil {
+		return &account.ReleaseNonceResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "nonce manager not enabled",
+		}, nil
+	}
+	if !common.IsHexAddress(req.Address) {
+		return &account.ReleaseNonceResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	if err := c.Nonces.Release(common.HexToAddress(req.Address), req.Nonce); err != nil {
+		log.Error("release nonce fail", "address", req.Address, "nonce", req.Nonce, "err", err)
+		return &account.ReleaseNonceResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	return &account.ReleaseNonceResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "release nonce success",
+	}, nil
+}
+
+// 查询地址的 nonce 空洞
+func (c *ChainAdaptor) GetNonceGaps(req *account.NonceGapsRequest) (*account.NonceGapsResponse, error) {
+	if c.Nonces == nil {
+		return &account.NonceGapsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "nonce manager not enabled",
+		}, nil
+	}
+	if !common.IsHexAddress(req.Address) {
+		return &account.NonceGapsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	gaps, err := c.Nonces.Gaps(common.HexToAddress(req.Address))
+	if err != nil {
+		log.Error("get nonce gaps fail", "address", req.Address, "err", err)
+		return &account.NonceGapsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get nonce gaps fail",
+		}, nil
+	}
+	return &account.NonceGapsResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          "get nonce gaps success",
+		LatestNonce:  gaps.Latest,
+		PendingNonce: gaps.Pending,
+		NextNonce:    gaps.Next,
+		Reserved:     gaps.Reserved,
+		Gaps:         gaps.Gaps,
+	}, nil
+}
+
+// 构建未签名交易失败时归还已分配的 nonce，此时交易还未交给调用方
+func (c *ChainAdaptor) releaseTxNonce(base64Tx string) {
+	if c.Nonces == nil {
+		return
+	}
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return
+	}
+	var tx Eip1559DynamicFeeTx
+	if err := json.Unmarshal(txJson, &tx); err != nil || !common.IsHexAddress(tx.FromAddress) {
+		return
+	}
+	if err := c.Nonces.Release(common.HexToAddress(tx.FromAddress), tx.Nonce); err != nil && !errors.Is(err, errNonceNotReserved) {
+		log.Warn("release nonce fail", "address", tx.FromAddress, "nonce", tx.Nonce, "err", err)
+	}
+}
@@ -0,0 +1,174 @@
+package ethereum
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"chain-account/common/global_const"
+	"chain-account/common/store"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+// 在 fakeTxNode 基础上补充交易池中的 pending nonce
+type fakeNonceNode struct {
+	*fakeTxNode
+	pending map[common.Address]uint64
+}
+
+func (f *fakeNonceNode) PendingTxCountByAddress(address common.Address) (hexutil.Uint64, error) {
+	return hexutil.Uint64(f.pending[address]), nil
+}
+
+func newFakeNonceNode() *fakeNonceNode {
+	return &fakeNonceNode{fakeTxNode: newFakeTxNode(), pending: make(map[common.Address]uint64)}
+}
+
+var nonceTestAddress = common.HexToAddress("0x72fFaA289993bcaDa2E01612995E5c75dD81cdBC")
+
+func Test_NonceManagerConcurrentReserve(t *testing.T) {
+	node := newFakeNonceNode()
+	node.nonces[nonceTestAddress] = 5
+	node.pending[nonceTestAddress] = 7
+	manager := NewNonceManager(node, config.Nonce{}, store.NewMemoryStore())
+
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, _, err := manager.Reserve(nonceTestAddress)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[nonce] {
+				t.Errorf("nonce %d allocated twice", nonce)
+			}
+			seen[nonce] = true
+		}()
+	}
+	wg.Wait()
+	// 从 pending nonce 开始连续分配
+	for nonce := uint64(7); nonce < 27; nonce++ {
+		if !seen[nonce] {
+			t.Fatalf("expected nonce %d to be allocated", nonce)
+		}
+	}
+}
+
+func Test_NonceManagerReleaseAndGaps(t *testing.T) {
+	node := newFakeNonceNode()
+	db := store.NewMemoryStore()
+	manager := NewNonceManager(node, config.Nonce{}, db)
+
+	for i := 0; i < 4; i++ {
+		if _, _, err := manager.Reserve(nonceTestAddress); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 末尾的 nonce 归还后直接回退
+	if err := manager.Release(nonceTestAddress, 3); err != nil {
+		t.Fatal(err)
+	}
+	// 中间的 nonce 归还后优先重新分配
+	if err := manager.Release(nonceTestAddress, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Release(nonceTestAddress, 1); err != errNonceNotReserved {
+		t.Fatalf("expected not reserved error, got %v", err)
+	}
+
+	// 重启后从持久化状态恢复
+	manager = NewNonceManager(node, config.Nonce{}, db)
+	if nonce, _, _ := manager.Reserve(nonceTestAddress); nonce != 1 {
+		t.Fatalf("expected released nonce 1, got %d", nonce)
+	}
+	if nonce, _, _ := manager.Reserve(nonceTestAddress); nonce != 3 {
+		t.Fatalf("expected nonce 3, got %d", nonce)
+	}
+
+	// nonce 0 上链，1 广播后丢失，2 仍在交易池，3 构建中
+	_, tx1, _ := signedTestTx(t, 1)
+	_, tx2, _ := signedTestTx(t, 2)
+	_ = manager.Commit(nonceTestAddress, 0, common.Hash{})
+	_ = manager.Commit(nonceTestAddress, 1, tx1.Hash())
+	_ = manager.Commit(nonceTestAddress, 2, tx2.Hash())
+	node.mempool[tx2.Hash()] = true
+	node.nonces[nonceTestAddress] = 1
+	node.pending[nonceTestAddress] = 1
+
+	gaps, err := manager.Gaps(nonceTestAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gaps.Next != 4 || !reflect.DeepEqual(gaps.Reserved, []uint64{3}) || !reflect.DeepEqual(gaps.Gaps, []uint64{1}) {
+		t.Fatalf("unexpected gaps %+v", gaps)
+	}
+}
+
+func Test_NonceKeptOnSignedBuildFailure(t *testing.T) {
+	node := newFakeNonceNode()
+	manager := NewNonceManager(node, config.Nonce{}, store.NewMemoryStore())
+	adaptor := &ChainAdaptor{EthClient: node, Nonces: manager}
+
+	nonce, _, _ := manager.Reserve(nonceTestAddress)
+	txJson, _ := json.Marshal(Eip1559DynamicFeeTx{
+		ChainId:              "1",
+		Nonce:                nonce,
+		FromAddress:          nonceTestAddress.Hex(),
+		ToAddress:            "0x0000000000000000000000000000000000000002",
+		GasLimit:             21000,
+		MaxFeePerGas:         "3000000000",
+		MaxPriorityFeePerGas: "2000000000",
+		Amount:               "1",
+	})
+	base64Tx := base64.StdEncoding.EncodeToString(txJson)
+	// 未签名交易已交给调用方，签名无效或签名地址不符时 nonce 仍保持分配，不会分给其他交易
+	key, _ := crypto.GenerateKey()
+	signature, _ := crypto.Sign(crypto.Keccak256([]byte("other")), key)
+	for _, sig := range []string{"zz", hex.EncodeToString(signature)} {
+		if _, err := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: base64Tx, Signature: sig}); err == nil {
+			t.Fatalf("expected signed build with signature %q to fail", sig)
+		}
+	}
+	if next, _, _ := manager.Reserve(nonceTestAddress); next == nonce {
+		t.Fatalf("nonce %d allocated twice", nonce)
+	}
+
+	// 构建未签名交易失败时归还
+	invalid, _ := json.Marshal(Eip1559DynamicFeeTx{ChainId: "x", Nonce: nonce + 1, FromAddress: nonceTestAddress.Hex()})
+	if _, err := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(invalid)}); err == nil {
+		t.Fatal("expected unsigned build to fail")
+	}
+	if next, _, _ := manager.Reserve(nonceTestAddress); next != nonce+1 {
+		t.Fatalf("expected released nonce %d, got %d", nonce+1, next)
+	}
+}
+
+func Test_ReleaseNonceInvalidAddress(t *testing.T) {
+	node := newFakeNonceNode()
+	manager := NewNonceManager(node, config.Nonce{}, store.NewMemoryStore())
+	adaptor := &ChainAdaptor{EthClient: node, Nonces: manager}
+	// 非法地址不能被解析为零地址后归还其 nonce
+	nonce, _, _ := manager.Reserve(common.Address{})
+	resp, _ := adaptor.ReleaseNonce(&account.ReleaseNonceRequest{Address: "garbage", Nonce: nonce})
+	if resp.Code != global_const.ReturnCode_ERROR || resp.Msg != "invalid address" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if next, _, _ := manager.Reserve(common.Address{}); next == nonce {
+		t.Fatalf("nonce %d released through an invalid address", nonce)
+	}
+}
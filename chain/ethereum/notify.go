@@ -0,0 +1,66 @@
+package ethereum
+
+import (
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/notifier"
+	"chain-account/rpc/account"
+)
+
+// 充值状态对应的通知事件
+var depositEventTypes = map[account.DepositStatus]notifier.EventType{
+	account.DepositStatus_DepositPending:   notifier.EventDepositDetected,
+	account.DepositStatus_DepositConfirmed: notifier.EventDepositConfirmed,
+	account.DepositStatus_DepositFinalized: notifier.EventDepositFinalized,
+	account.DepositStatus_DepositReorged:   notifier.EventDepositReorged,
+}
+
+// 发出交易最终状态对应的通知事件
+var outgoingEventTypes = map[account.TxStatus]notifier.EventType{
+	account.TxStatus_Success: notifier.EventTxMined,
+	account.TxStatus_Failed:  notifier.EventTxFailed,
+	account.TxStatus_Other:   notifier.EventTxDropped,
+}
+
+// 将充值和发出交易的状态变化转发给通知模块，需要在 Start 之前调用
+func (c *ChainAdaptor) SetNotifier(n notifier.Notifier) {
+	if c.Outgoing != nil {
+		c.Outgoing.Subscribe(func(tx *OutgoingTx) error {
+			event, err := notifier.NewEvent(outgoingEventTypes[tx.Status], c.ChainName, tx)
+			if err != nil {
+				log.Error("build outgoing tx event fail", "err", err)
+				return err
+			}
+			if err := n.Notify(event); err != nil {
+				log.Error("notify outgoing tx event fail", "hash", tx.Hash, "err", err)
+				return err
+			}
+			return nil
+		})
+	}
+	if c.Deposits == nil {
+		return
+	}
+	c.Deposits.Subscribe(func(item *DepositNotify) error {
+		event, err := notifier.NewEvent(depositEventTypes[item.Deposit.Status], c.ChainName, item.Deposit)
+		if err != nil {
+			log.Error("build deposit event fail", "err", err)
+			return err
+		}
+		if err := n.Notify(event); err != nil {
+			log.Error("notify deposit event fail", "id", item.Id, "err", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// 启动充值扫描和发出交易轮询，放在 SetNotifier 之后，保证第一轮扫描的状态变化也能发出通知
+func (c *ChainAdaptor) Start() {
+	if c.Deposits != nil {
+		c.Deposits.Start()
+	}
+	if c.Outgoing != nil {
+		c.Outgoing.Start()
+	}
+}
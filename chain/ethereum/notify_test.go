@@ -0,0 +1,40 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"chain-account/notifier"
+)
+
+type captureNotifier struct {
+	events []*notifier.Event
+}
+
+func (c *captureNotifier) Notify(event *notifier.Event) error {
+	c.events = append(c.events, event)
+	return nil
+}
+
+func Test_NotifierUsesAdaptorChainName(t *testing.T) {
+	node := newFakeTxNode()
+	adaptor := &ChainAdaptor{ChainName: "Base", Outgoing: newTestTracker(t, node)}
+	n := &captureNotifier{}
+	adaptor.SetNotifier(n)
+
+	rawTx, tx, _ := signedTestTx(t, 1)
+	if err := adaptor.Outgoing.Track(rawTx, tx); err != nil {
+		t.Fatal(err)
+	}
+	node.receipts[tx.Hash()] = &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(100)}
+	_ = adaptor.Outgoing.Poll()
+
+	if len(n.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(n.events))
+	}
+	if n.events[0].Chain != "Base" || n.events[0].Type != notifier.EventTxMined {
+		t.Fatalf("expected tx.mined event for Base, got %s/%s", n.events[0].Type, n.events[0].Chain)
+	}
+}
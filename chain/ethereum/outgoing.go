@@ -0,0 +1,606 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/common/store"
+	"chain-account/common/util"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const (
+	defaultPollInterval   = 12 * time.Second
+	defaultDropBlocks     = 5
+	defaultMaxRebroadcast = 3
+
+	outgoingPruneInterval = 1000 // 每推进多少个区块清理一次过期交易
+
+	// 交易按 tx/<hash> 存储；pending/<hash> 为未完成的交易，from/<from>/<nonce>/<hash> 和 to/<to>/<hash>
+	// 用于按地址和 nonce 查询，closed/<height>/<hash> 用于按完成时的高度清理
+	outgoingKeyPrefix = "tx/"
+	pendingKeyPrefix  = "pending/"
+	fromKeyPrefix     = "from/"
+	toKeyPrefix       = "to/"
+	txOutboxKeyPrefix = "outbox/" // 已落盘但还没有成功交给订阅方的最终状态，重启后重新投递
+	txIndexKey        = "index"   // 索引版本，旧版本只有 tx/ 记录，启动时补建索引
+	txIndexVersion    = 1
+)
+
+// 已广播的交易
+type OutgoingTx struct {
+	Hash         string           `json:"hash"`
+	From         string           `json:"from"`
+	To           string           `json:"to"`
+	Value        string           `json:"value"`
+	Nonce        uint64           `json:"nonce"`
+	RawTx        string           `json:"raw_tx"`
+	Status       account.TxStatus `json:"status"`
+	Reason       string           `json:"reason"`
+	Height       uint64           `json:"height"`
+	LastSeen     uint64           `json:"last_seen"` // 最后一次在 mempool 中查到该交易时的区块高度
+	Rebroadcasts int              `json:"rebroadcasts"`
+	CreatedAt    int64            `json:"created_at"`
+	UpdatedAt    int64            `json:"updated_at"`
+}
+
+// 发出交易跟踪：持久化广播过的交易，轮询直到上链，识别被替换或丢失的交易并在合适时重新广播
+type OutgoingTracker struct {
+	client IEth
+	conf   config.Outgoing
+	store  store.Store
+
+	mu        sync.RWMutex
+	pending   map[common.Hash]*OutgoingTx
+	latest    uint64
+	pruned    uint64
+	listeners []func(*OutgoingTx) error
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewOutgoingTracker(client IEth, conf config.Outgoing, db store.Store) (*OutgoingTracker, error) {
+	if conf.DropBlocks == 0 {
+		conf.DropBlocks = defaultDropBlocks
+	}
+	if conf.MaxRebroadcast == 0 {
+		conf.MaxRebroadcast = defaultMaxRebroadcast
+	}
+	t := &OutgoingTracker{
+		client:  client,
+		conf:    conf,
+		store:   db,
+		pending: make(map[common.Hash]*OutgoingTx),
+		stop:    make(chan struct{}),
+	}
+	if err := t.migrate(); err != nil {
+		return nil, err
+	}
+	keys, err := db.Keys(pendingKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		tx := new(OutgoingTx)
+		if ok, err := db.Get(outgoingKeyPrefix+strings.TrimPrefix(key, pendingKeyPrefix), tx); err != nil {
+			return nil, err
+		} else if !ok || tx.Status != account.TxStatus_Pending {
+			// 完成时写入记录后、删除 pending 索引前退出
+			if err := db.Delete(key); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		t.pending[common.HexToHash(tx.Hash)] = tx
+	}
+	return t, nil
+}
+
+// 为旧版本只有 tx/ 记录的存储补建索引
+func (t *OutgoingTracker) migrate() error {
+	var version int
+	if _, err := t.store.Get(txIndexKey, &version); err != nil {
+		return err
+	}
+	if version >= txIndexVersion {
+		return nil
+	}
+	keys, err := t.store.Keys(outgoingKeyPrefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		tx := new(OutgoingTx)
+		if _, err := t.store.Get(key, tx); err != nil {
+			return err
+		}
+		batch := t.indexes(tx)
+		if tx.Status == account.TxStatus_Pending {
+			batch[pendingKeyPrefix+tx.Hash] = tx.Hash
+		} else {
+			batch[closedKey(tx.Height, tx.Hash)] = tx.Hash
+		}
+		if err := t.store.PutBatch(batch); err != nil {
+			return err
+		}
+	}
+	return t.store.Put(txIndexKey, txIndexVersion)
+}
+
+// 启动后台轮询，需要在订阅之后调用
+func (t *OutgoingTracker) Start() {
+	interval := time.Duration(t.conf.PollInterval) * time.Second
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := t.redeliver(); err != nil {
+				log.Error("redeliver outgoing tx fail", "err", err)
+			}
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+				if err := t.Poll(); err != nil {
+					log.Error("poll outgoing tx fail", "err", err)
+				}
+			}
+		}
+	}()
+}
+
+// 停止后台轮询
+func (t *OutgoingTracker) Stop() {
+	close(t.stop)
+	t.wg.Wait()
+}
+
+// 订阅交易状态变化（上链、失败、丢失），回调返回错误时之后重新投递
+func (t *OutgoingTracker) Subscribe(fn func(*OutgoingTx) error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.listeners = append(t.listeners, fn)
+}
+
+// 记录一笔刚广播的交易
+func (t *OutgoingTracker) Track(rawTx string, tx *types.Transaction) error {
+	from, err := types.Sender(txSigner(tx), tx)
+	if err != nil {
+		return err
+	}
+	var height uint64
+	if header, err := t.client.BlockHeaderByNumber(nil); err == nil {
+		height = header.Number.Uint64()
+	}
+	to := ""
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	now := time.Now().Unix()
+	item := &OutgoingTx{
+		Hash:      tx.Hash().Hex(),
+		From:      from.Hex(),
+		To:        to,
+		Value:     tx.Value().String(),
+		Nonce:     tx.Nonce(),
+		RawTx:     rawTx,
+		Status:    account.TxStatus_Pending,
+		LastSeen:  height,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	t.mu.Lock()
+	t.pending[tx.Hash()] = item
+	t.mu.Unlock()
+	batch := t.indexes(item)
+	batch[outgoingKeyPrefix+item.Hash] = item
+	batch[pendingKeyPrefix+item.Hash] = item.Hash
+	return t.store.PutBatch(batch)
+}
+
+// 按地址和 nonce 查询用的索引
+func (t *OutgoingTracker) indexes(tx *OutgoingTx) map[string]any {
+	batch := map[string]any{fromNonceKey(tx.From, tx.Nonce) + tx.Hash: tx.Hash}
+	if tx.To != "" {
+		batch[toKeyPrefix+strings.ToLower(tx.To)+"/"+tx.Hash] = tx.Hash
+	}
+	return batch
+}
+
+// 查询跟踪中的交易
+func (t *OutgoingTracker) Get(hash common.Hash) (*OutgoingTx, bool) {
+	tx := new(OutgoingTx)
+	ok, err := t.store.Get(outgoingKeyPrefix+hash.Hex(), tx)
+	if err != nil || !ok {
+		return nil, false
+	}
+	return tx, true
+}
+
+// 检查所有未完成的交易
+func (t *OutgoingTracker) Poll() error {
+	header, err := t.client.BlockHeaderByNumber(nil)
+	if err != nil {
+		return err
+	}
+	latest := header.Number.Uint64()
+
+	t.mu.Lock()
+	t.latest = latest
+	pending := make([]*OutgoingTx, 0, len(t.pending))
+	for _, tx := range t.pending {
+		pending = append(pending, tx)
+	}
+	t.mu.Unlock()
+
+	for _, tx := range pending {
+		if err := t.check(tx, latest); err != nil {
+			log.Warn("check outgoing tx fail", "hash", tx.Hash, "err", err)
+		}
+	}
+	return t.prune(latest)
+}
+
+func (t *OutgoingTracker) check(tx *OutgoingTx, latest uint64) error {
+	hash := common.HexToHash(tx.Hash)
+
+	// 1. 已上链
+	receipt, err := t.client.TxReceiptByHash(hash)
+	if err == nil {
+		return t.mined(tx, receipt)
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return err
+	}
+
+	// 2. 仍在 mempool 中
+	if _, err := t.client.TxByHash(hash); err == nil {
+		tx.LastSeen = latest
+		return nil
+	} else if !errors.Is(err, ethereum.NotFound) {
+		return err
+	}
+
+	// 3. nonce 已被其他交易占用，说明被替换或丢弃
+	nonce, err := t.client.TxCountByAddress(common.HexToAddress(tx.From))
+	if err != nil {
+		return err
+	}
+	if uint64(nonce) > tx.Nonce {
+		return t.nonceConsumed(tx)
+	}
+
+	// 4. 连续多个区块查不到，重新广播，次数用尽后视为丢失
+	if latest < tx.LastSeen+t.conf.DropBlocks {
+		return nil
+	}
+	if tx.Rebroadcasts >= t.conf.MaxRebroadcast {
+		return t.finish(tx, account.TxStatus_Other, "absent from mempool")
+	}
+	tx.Rebroadcasts++
+	tx.LastSeen = latest
+	tx.UpdatedAt = time.Now().Unix()
+	if _, err := t.client.SendRawTransaction(tx.RawTx); err != nil {
+		log.Warn("rebroadcast tx fail", "hash", tx.Hash, "err", err)
+	} else {
+		log.Info("rebroadcast tx", "hash", tx.Hash, "times", tx.Rebroadcasts)
+	}
+	return t.store.Put(outgoingKeyPrefix+tx.Hash, tx)
+}
+
+func (t *OutgoingTracker) mined(tx *OutgoingTx, receipt *types.Receipt) error {
+	tx.Height = receipt.BlockNumber.Uint64()
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return t.finish(tx, account.TxStatus_Success, "")
+	}
+	return t.finish(tx, account.TxStatus_Failed, "execution reverted")
+}
+
+// nonce 已被占用时，先查询本交易和同 nonce 的其他跟踪交易（替换交易）的收据：
+// 本交易可能在第 1 步之后才上链，或节点短暂落后；都没有收据时才视为被其他交易占用
+func (t *OutgoingTracker) nonceConsumed(tx *OutgoingTx) error {
+	hashes, err := t.sameNonce(tx)
+	if err != nil {
+		return err
+	}
+	for _, hash := range hashes {
+		receipt, err := t.client.TxReceiptByHash(hash)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if hash.Hex() == tx.Hash {
+			return t.mined(tx, receipt)
+		}
+		return t.finish(tx, account.TxStatus_Other, "replaced by "+hash.Hex())
+	}
+	return t.finish(tx, account.TxStatus_Other, "nonce consumed by another transaction")
+}
+
+// 本交易及同一发送方、同一 nonce 的其他跟踪交易的哈希，本交易排在最前
+func (t *OutgoingTracker) sameNonce(tx *OutgoingTx) ([]common.Hash, error) {
+	hashes := []common.Hash{common.HexToHash(tx.Hash)}
+	prefix := fromNonceKey(tx.From, tx.Nonce)
+	keys, err := t.store.Keys(prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if hash := strings.TrimPrefix(key, prefix); hash != tx.Hash {
+			hashes = append(hashes, common.HexToHash(hash))
+		}
+	}
+	return hashes, nil
+}
+
+func (t *OutgoingTracker) finish(tx *OutgoingTx, status account.TxStatus, reason string) error {
+	tx.Status = status
+	tx.Reason = reason
+	tx.UpdatedAt = time.Now().Unix()
+
+	t.mu.Lock()
+	delete(t.pending, common.HexToHash(tx.Hash))
+	listeners := t.listeners
+	height := max(t.latest, tx.Height)
+	t.mu.Unlock()
+
+	log.Info("outgoing tx finished", "hash", tx.Hash, "status", status, "reason", reason)
+	batch := map[string]any{
+		outgoingKeyPrefix + tx.Hash: tx,
+		closedKey(height, tx.Hash):  tx.Hash,
+	}
+	if len(listeners) > 0 {
+		batch[txOutboxKeyPrefix+tx.Hash] = tx
+	}
+	if err := t.store.PutBatch(batch); err != nil {
+		return err
+	}
+	if err := t.store.Delete(pendingKeyPrefix + tx.Hash); err != nil {
+		return err
+	}
+	return t.publish(tx, listeners)
+}
+
+// 删除完成超过 retention 个区块的交易记录和索引
+func (t *OutgoingTracker) prune(latest uint64) error {
+	if t.conf.Retention == 0 || (t.pruned > 0 && latest < t.pruned+outgoingPruneInterval) {
+		return nil
+	}
+	t.pruned = latest
+	keys, err := t.store.Keys(closedKeyPrefix)
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, key := range keys {
+		if keyHeight(key)+t.conf.Retention > latest {
+			break
+		}
+		hash := key[strings.LastIndex(key, "/")+1:]
+		tx := new(OutgoingTx)
+		ok, err := t.store.Get(outgoingKeyPrefix+hash, tx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			for indexKey := range t.indexes(tx) {
+				if err := t.store.Delete(indexKey); err != nil {
+					return err
+				}
+			}
+			if err := t.store.Delete(outgoingKeyPrefix + hash); err != nil {
+				return err
+			}
+		}
+		if err := t.store.Delete(key); err != nil {
+			return err
+		}
+		removed++
+	}
+	if removed > 0 {
+		log.Info("pruned finished outgoing txs", "count", removed, "latest", latest)
+	}
+	return nil
+}
+
+// 把最终状态交给订阅方，全部成功后从 outbox 中删除
+func (t *OutgoingTracker) publish(tx *OutgoingTx, listeners []func(*OutgoingTx) error) error {
+	if len(listeners) == 0 {
+		return nil
+	}
+	for _, fn := range listeners {
+		if err := fn(tx); err != nil {
+			return nil
+		}
+	}
+	return t.store.Delete(txOutboxKeyPrefix + tx.Hash)
+}
+
+// 重新投递 outbox 中未成功交给订阅方的交易
+func (t *OutgoingTracker) redeliver() error {
+	t.mu.RLock()
+	listeners := t.listeners
+	t.mu.RUnlock()
+	if len(listeners) == 0 {
+		return nil
+	}
+	keys, err := t.store.Keys(txOutboxKeyPrefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		tx := new(OutgoingTx)
+		if _, err := t.store.Get(key, tx); err != nil {
+			return err
+		}
+		if err := t.publish(tx, listeners); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 按地址和状态查询，address 同时匹配发送方和接收方
+func (t *OutgoingTracker) List(address string, status []account.TxStatus, page, pageSize uint32) ([]*OutgoingTx, error) {
+	keys, err := t.listKeys(address)
+	if err != nil {
+		return nil, err
+	}
+	var txs []*OutgoingTx
+	for _, key := range keys {
+		tx := new(OutgoingTx)
+		if ok, err := t.store.Get(key, tx); err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+		if len(status) > 0 && !containsTxStatus(status, tx.Status) {
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].CreatedAt > txs[j].CreatedAt
+	})
+	return util.Paginate(txs, page, pageSize), nil
+}
+
+// 需要读取的交易记录 key，指定地址时只读取该地址发出和收到的交易
+func (t *OutgoingTracker) listKeys(address string) ([]string, error) {
+	if address == "" {
+		return t.store.Keys(outgoingKeyPrefix)
+	}
+	address = strings.ToLower(address)
+	var keys []string
+	seen := make(map[string]struct{})
+	for _, prefix := range []string{fromKeyPrefix + address + "/", toKeyPrefix + address + "/"} {
+		indexKeys, err := t.store.Keys(prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, indexKey := range indexKeys {
+			hash := indexKey[strings.LastIndex(indexKey, "/")+1:]
+			if _, ok := seen[hash]; ok {
+				continue
+			}
+			seen[hash] = struct{}{}
+			keys = append(keys, outgoingKeyPrefix+hash)
+		}
+	}
+	return keys, nil
+}
+
+// 同一发送方、同一 nonce 的交易索引前缀
+func fromNonceKey(from string, nonce uint64) string {
+	return fmt.Sprintf("%s%s/%020d/", fromKeyPrefix, strings.ToLower(from), nonce)
+}
+
+func containsTxStatus(list []account.TxStatus, status account.TxStatus) bool {
+	for _, item := range list {
+		if item == status {
+			return true
+		}
+	}
+	return false
+}
+
+// 解码 0x 开头的签名交易
+func decodeRawTx(rawTx string) (*types.Transaction, error) {
+	raw, err := hexutil.Decode(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func txSigner(tx *types.Transaction) types.Signer {
+	if tx.ChainId() == nil || tx.ChainId().Sign() == 0 {
+		return types.HomesteadSigner{}
+	}
+	return types.LatestSignerForChainID(tx.ChainId())
+}
+
+func (o *OutgoingTx) toMessage() *account.OutgoingTxMessage {
+	return &account.OutgoingTxMessage{
+		Hash:         o.Hash,
+		From:         o.From,
+		To:           o.To,
+		Value:        o.Value,
+		Nonce:        o.Nonce,
+		Status:       o.Status,
+		Reason:       o.Reason,
+		Height:       o.Height,
+		Rebroadcasts: uint32(o.Rebroadcasts),
+		CreatedAt:    o.CreatedAt,
+		UpdatedAt:    o.UpdatedAt,
+	}
+}
+
+// 跟踪记录转换为 GetTxByHash 的返回
+func (o *OutgoingTx) toTxMessage() *account.TxMessage {
+	height := ""
+	if o.Height > 0 {
+		height = new(big.Int).SetUint64(o.Height).String()
+	}
+	return &account.TxMessage{
+		Hash:            o.Hash,
+		From:            o.From,
+		To:              o.To,
+		Value:           o.Value,
+		Status:          o.Status,
+		Height:          height,
+		ContractAddress: global_const.ZeroAddress,
+	}
+}
+
+// 按地址和状态查询已广播的交易
+func (c *ChainAdaptor) ListOutgoingTx(req *account.OutgoingTxListRequest) (*account.OutgoingTxListResponse, error) {
+	if c.Outgoing == nil {
+		return &account.OutgoingTxListResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "outgoing tx tracker not enabled",
+		}, nil
+	}
+	txs, err := c.Outgoing.List(req.Address, req.Status, req.Page, req.Pagesize)
+	if err != nil {
+		log.Error("list outgoing tx fail", "err", err)
+		return &account.OutgoingTxListResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "list outgoing tx fail",
+		}, nil
+	}
+	var txList []*account.OutgoingTxMessage
+	for _, tx := range txs {
+		txList = append(txList, tx.toMessage())
+	}
+	return &account.OutgoingTxListResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "list outgoing tx success",
+		Txs:  txList,
+	}, nil
+}
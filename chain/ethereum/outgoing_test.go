@@ -0,0 +1,285 @@
+package ethereum
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"chain-account/common/store"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+// 模拟节点的交易池和收据
+type fakeTxNode struct {
+	IEth
+	height   uint64
+	receipts map[common.Hash]*types.Receipt
+	mempool  map[common.Hash]bool
+	nonces   map[common.Address]uint64
+	sent     []string
+}
+
+func newFakeTxNode() *fakeTxNode {
+	return &fakeTxNode{
+		height:   100,
+		receipts: make(map[common.Hash]*types.Receipt),
+		mempool:  make(map[common.Hash]bool),
+		nonces:   make(map[common.Address]uint64),
+	}
+}
+
+func (f *fakeTxNode) BlockHeaderByNumber(*big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(f.height)}, nil
+}
+
+func (f *fakeTxNode) TxReceiptByHash(hash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := f.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (f *fakeTxNode) TxByHash(hash common.Hash) (*types.Transaction, error) {
+	if f.mempool[hash] {
+		return new(types.Transaction), nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (f *fakeTxNode) TxCountByAddress(address common.Address) (hexutil.Uint64, error) {
+	return hexutil.Uint64(f.nonces[address]), nil
+}
+
+func (f *fakeTxNode) SendRawTransaction(rawTx string) (*common.Hash, error) {
+	f.sent = append(f.sent, rawTx)
+	hash := common.Hash{}
+	return &hash, nil
+}
+
+func mustOutgoingTestKey() *ecdsa.PrivateKey {
+	key, _ := crypto.HexToECDSA("4f40b69b64cdc6751e2377578cea8443410d0d54cd0449718a0d8bd964b9656e")
+	return key
+}
+
+func signedTestTx(t *testing.T, nonce uint64) (string, *types.Transaction, common.Address) {
+	key := mustOutgoingTestKey()
+	to := common.HexToAddress("0xf63948D0c77d161A491CD787403ac4222F4d9E55")
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(2000000000),
+		GasFeeCap: big.NewInt(3000000000),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := tx.MarshalBinary()
+	return hexutil.Encode(raw), tx, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func newTestTracker(t *testing.T, node *fakeTxNode) *OutgoingTracker {
+	tracker, err := NewOutgoingTracker(node, config.Outgoing{DropBlocks: 2, MaxRebroadcast: 1}, store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tracker
+}
+
+func Test_OutgoingTrackerMined(t *testing.T) {
+	node := newFakeTxNode()
+	tracker := newTestTracker(t, node)
+	var finished []*OutgoingTx
+	tracker.Subscribe(func(tx *OutgoingTx) error { finished = append(finished, tx); return nil })
+
+	rawTx, tx, from := signedTestTx(t, 7)
+	if err := tracker.Track(rawTx, tx); err != nil {
+		t.Fatal(err)
+	}
+	node.mempool[tx.Hash()] = true
+	_ = tracker.Poll()
+	if item, _ := tracker.Get(tx.Hash()); item.Status != account.TxStatus_Pending || item.From != from.Hex() {
+		t.Fatalf("expected pending tx from %s, got %+v", from.Hex(), item)
+	}
+
+	node.receipts[tx.Hash()] = &types.Receipt{Status: types.ReceiptStatusFailed, BlockNumber: big.NewInt(101)}
+	_ = tracker.Poll()
+	item, _ := tracker.Get(tx.Hash())
+	if item.Status != account.TxStatus_Failed || item.Height != 101 {
+		t.Fatalf("expected failed tx at 101, got %+v", item)
+	}
+	if len(finished) != 1 {
+		t.Fatalf("expected 1 finished notification, got %d", len(finished))
+	}
+	list, _ := tracker.List(from.Hex(), []account.TxStatus{account.TxStatus_Failed}, 1, 10)
+	if len(list) != 1 {
+		t.Fatalf("expected 1 failed tx in list, got %d", len(list))
+	}
+}
+
+func Test_OutgoingTrackerReplaced(t *testing.T) {
+	node := newFakeTxNode()
+	tracker := newTestTracker(t, node)
+	rawTx, tx, from := signedTestTx(t, 3)
+	_ = tracker.Track(rawTx, tx)
+
+	// 同一 nonce 的另一笔交易已上链
+	node.nonces[from] = 4
+	_ = tracker.Poll()
+	item, _ := tracker.Get(tx.Hash())
+	if item.Status != account.TxStatus_Other || item.Reason != "nonce consumed by another transaction" {
+		t.Fatalf("expected replaced tx, got %+v", item)
+	}
+}
+
+// 查询 nonce 时交易刚好上链
+type minedDuringCheckNode struct {
+	*fakeTxNode
+	hash common.Hash
+}
+
+func (n *minedDuringCheckNode) TxCountByAddress(address common.Address) (hexutil.Uint64, error) {
+	n.receipts[n.hash] = &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(100)}
+	return n.fakeTxNode.TxCountByAddress(address)
+}
+
+func Test_OutgoingTrackerNonceConsumedWithReceipt(t *testing.T) {
+	node := newFakeTxNode()
+	rawTx, tx, from := signedTestTx(t, 3)
+	raced := &minedDuringCheckNode{fakeTxNode: node, hash: tx.Hash()}
+	tracker, err := NewOutgoingTracker(raced, config.Outgoing{DropBlocks: 2, MaxRebroadcast: 1}, store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = tracker.Track(rawTx, tx)
+
+	// 第 1 步查不到收据，查询 nonce 前上链，不能报告为被占用
+	node.nonces[from] = 4
+	_ = tracker.Poll()
+	item, _ := tracker.Get(tx.Hash())
+	if item.Status != account.TxStatus_Success || item.Height != 100 {
+		t.Fatalf("expected mined tx, got %+v", item)
+	}
+
+	// 加速后的替换交易上链
+	rawTx, tx, _ = signedTestTx(t, 5)
+	replacement := types.MustSignNewTx(mustOutgoingTestKey(), types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID: big.NewInt(1), Nonce: 5, GasTipCap: big.NewInt(3000000000), GasFeeCap: big.NewInt(4000000000), Gas: 21000, To: tx.To(), Value: tx.Value(),
+	})
+	replacementRaw, _ := replacement.MarshalBinary()
+	tracker = newTestTracker(t, node)
+	_ = tracker.Track(rawTx, tx)
+	_ = tracker.Track(hexutil.Encode(replacementRaw), replacement)
+	node.nonces[from] = 6
+	node.receipts[replacement.Hash()] = &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(100)}
+	_ = tracker.Poll()
+	item, _ = tracker.Get(tx.Hash())
+	if item.Status != account.TxStatus_Other || item.Reason != "replaced by "+replacement.Hash().Hex() {
+		t.Fatalf("expected tx replaced by %s, got %+v", replacement.Hash().Hex(), item)
+	}
+}
+
+func Test_OutgoingTrackerRebroadcastThenDrop(t *testing.T) {
+	node := newFakeTxNode()
+	tracker := newTestTracker(t, node)
+	rawTx, tx, _ := signedTestTx(t, 0)
+	_ = tracker.Track(rawTx, tx)
+
+	// 未超过 drop_blocks 时只等待
+	node.height = 101
+	_ = tracker.Poll()
+	if len(node.sent) != 0 {
+		t.Fatalf("expected no rebroadcast yet, got %d", len(node.sent))
+	}
+
+	node.height = 102
+	_ = tracker.Poll()
+	if len(node.sent) != 1 || node.sent[0] != rawTx {
+		t.Fatalf("expected one rebroadcast of the raw tx, got %v", node.sent)
+	}
+
+	node.height = 104
+	_ = tracker.Poll()
+	item, _ := tracker.Get(tx.Hash())
+	if item.Status != account.TxStatus_Other || item.Reason != "absent from mempool" || item.Rebroadcasts != 1 {
+		t.Fatalf("expected dropped tx, got %+v", item)
+	}
+}
+
+func Test_OutgoingTrackerPrune(t *testing.T) {
+	node := newFakeTxNode()
+	db := store.NewMemoryStore()
+	conf := config.Outgoing{DropBlocks: 2, MaxRebroadcast: 1, Retention: 10}
+	tracker, err := NewOutgoingTracker(node, conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawTx, tx, from := signedTestTx(t, 3)
+	if err := tracker.Track(rawTx, tx); err != nil {
+		t.Fatal(err)
+	}
+	pendingTx, pendingSigned, _ := signedTestTx(t, 4)
+	if err := tracker.Track(pendingTx, pendingSigned); err != nil {
+		t.Fatal(err)
+	}
+	node.mempool[pendingSigned.Hash()] = true
+	node.receipts[tx.Hash()] = &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(100)}
+	_ = tracker.Poll()
+
+	// 重启后只从 pending 索引恢复未完成的交易
+	restarted, err := NewOutgoingTracker(node, conf, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restarted.pending) != 1 || restarted.pending[pendingSigned.Hash()] == nil {
+		t.Fatalf("expected only the pending tx to be restored, got %d", len(restarted.pending))
+	}
+	if list, _ := restarted.List(from.Hex(), nil, 1, 10); len(list) != 2 {
+		t.Fatalf("expected 2 txs for sender, got %d", len(list))
+	}
+
+	node.height = 100 + conf.Retention + outgoingPruneInterval
+	_ = restarted.Poll()
+	if _, ok := restarted.Get(tx.Hash()); ok {
+		t.Fatal("expected finished tx to be pruned")
+	}
+	if _, ok := restarted.Get(pendingSigned.Hash()); !ok {
+		t.Fatal("expected pending tx to be kept")
+	}
+	if list, _ := restarted.List(from.Hex(), nil, 1, 10); len(list) != 1 {
+		t.Fatalf("expected 1 tx after prune, got %d", len(list))
+	}
+	if keys, _ := db.Keys(fromNonceKey(from.Hex(), 3)); len(keys) != 0 {
+		t.Fatalf("expected nonce index to be pruned, got %v", keys)
+	}
+}
+
+func Test_OutgoingTrackerMigrateIndexes(t *testing.T) {
+	node := newFakeTxNode()
+	db := store.NewMemoryStore()
+	// 旧版本只有 tx/ 记录
+	rawTx, tx, from := signedTestTx(t, 5)
+	legacy := &OutgoingTx{Hash: tx.Hash().Hex(), From: from.Hex(), Nonce: 5, RawTx: rawTx, Status: account.TxStatus_Pending}
+	if err := db.Put(outgoingKeyPrefix+legacy.Hash, legacy); err != nil {
+		t.Fatal(err)
+	}
+	tracker, err := NewOutgoingTracker(node, config.Outgoing{}, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tracker.pending[tx.Hash()] == nil {
+		t.Fatal("expected legacy pending tx to be restored")
+	}
+	if list, _ := tracker.List(from.Hex(), nil, 1, 10); len(list) != 1 {
+		t.Fatalf("expected legacy tx to be indexed by sender, got %d", len(list))
+	}
+}
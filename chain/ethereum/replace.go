@@ -0,0 +1,179 @@
+package ethereum
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+const (
+	// 节点接受替换交易的最低加价比例（geth 默认 10%）
+	minReplaceBumpPercent = 10
+	cancelGasLimit        = 21000
+)
+
+// 构建加速交易：相同 nonce、相同内容，提高 gas 费用
+func (c *ChainAdaptor) BuildSpeedUpTransaction(req *account.ReplaceTransactionRequest) (*account.ReplaceTransactionResponse, error) {
+	return c.buildReplaceTransaction(req, false)
+}
+
+// 构建取消交易：相同 nonce 的 0 金额自转账，提高 gas 费用
+func (c *ChainAdaptor) BuildCancelTransaction(req *account.ReplaceTransactionRequest) (*account.ReplaceTransactionResponse, error) {
+	return c.buildReplaceTransaction(req, true)
+}
+
+func (c *ChainAdaptor) buildReplaceTransaction(req *account.ReplaceTransactionRequest, cancel bool) (*account.ReplaceTransactionResponse, error) {
+	origin, err := c.pendingTx(common.HexToHash(req.Hash))
+	if err != nil {
+		log.Error("get pending tx fail", "hash", req.Hash, "err", err)
+		return &account.ReplaceTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	from, err := types.Sender(txSigner(origin), origin)
+	if err != nil {
+		log.Error("get sender fail", "err", err)
+		return &account.ReplaceTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get sender fail",
+		}, nil
+	}
+
+	gasTipCap, gasFeeCap, err := c.replacementFees(origin, req.BumpPercent)
+	if err != nil {
+		log.Error("get replacement fee fail", "err", err)
+		return &account.ReplaceTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get replacement fee fail",
+		}, nil
+	}
+
+	replaceTx := &Eip1559DynamicFeeTx{
+		ChainId:              origin.ChainId().String(),
+		Nonce:                origin.Nonce(),
+		FromAddress:          from.Hex(),
+		MaxFeePerGas:         gasFeeCap.String(),
+		MaxPriorityFeePerGas: gasTipCap.String(),
+	}
+	if cancel {
+		replaceTx.ToAddress = from.Hex()
+		replaceTx.GasLimit = cancelGasLimit
+		replaceTx.Amount = "0"
+	} else {
+		if origin.To() == nil {
+			return &account.ReplaceTransactionResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "speed up contract creation is not supported",
+			}, nil
+		}
+		replaceTx.ToAddress = origin.To().Hex()
+		replaceTx.GasLimit = origin.Gas()
+		replaceTx.Amount = origin.Value().String()
+		if len(origin.Data()) > 0 {
+			replaceTx.Data = hexutil.Encode(origin.Data())
+		}
+	}
+
+	// 复用 BuildUnSignTransaction 的流程，保证返回的 base64_tx 可以直接用于 BuildSignedTransaction
+	txType := c.txType(replaceTx)
+	replaceTx.TxType = &txType
+	txJson, _ := json.Marshal(replaceTx)
+	base64Tx := base64.StdEncoding.EncodeToString(txJson)
+	dFeeTx, _, err := buildDynamicFeeTx(base64Tx)
+	if err != nil {
+		log.Error("build dynamic fee tx fail", "err", err)
+		return &account.ReplaceTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "build dynamic fee tx fail",
+		}, nil
+	}
+	unSignTx, err := createUnSignTx(dFeeTx, txType)
+	if err != nil {
+		log.Error("create unSign tx fail", "err", err)
+		return &account.ReplaceTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "create unSign tx fail",
+		}, nil
+	}
+	return &account.ReplaceTransactionResponse{
+		Code:                 global_const.ReturnCode_SUCCESS,
+		Msg:                  "build replace transaction success",
+		UnSignTx:             unSignTx,
+		Base64Tx:             base64Tx,
+		Nonce:                origin.Nonce(),
+		MaxFeePerGas:         gasFeeCap.String(),
+		MaxPriorityFeePerGas: gasTipCap.String(),
+	}, nil
+}
+
+// 查询待替换的交易，节点查不到时使用跟踪记录中的原始交易
+func (c *ChainAdaptor) pendingTx(hash common.Hash) (*types.Transaction, error) {
+	if _, err := c.EthClient.TxReceiptByHash(hash); err == nil {
+		return nil, errors.New("transaction already mined")
+	} else if !errors.Is(err, ethereum.NotFound) {
+		return nil, err
+	}
+	tx, err := c.EthClient.TxByHash(hash)
+	if err == nil {
+		return tx, nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return nil, err
+	}
+	if c.Outgoing != nil {
+		if tracked, ok := c.Outgoing.Get(hash); ok && tracked.Status == account.TxStatus_Pending {
+			return decodeRawTx(tracked.RawTx)
+		}
+	}
+	return nil, errors.New("transaction not found")
+}
+
+// 计算替换交易的费用：在原交易基础上至少加价 bumpPercent，同时不低于当前建议值
+func (c *ChainAdaptor) replacementFees(origin *types.Transaction, bumpPercent uint32) (*big.Int, *big.Int, error) {
+	if bumpPercent < minReplaceBumpPercent {
+		bumpPercent = minReplaceBumpPercent
+	}
+	gasTipCap := bumpFee(origin.GasTipCap(), bumpPercent)
+	gasFeeCap := bumpFee(origin.GasFeeCap(), bumpPercent)
+
+	suggestTip, err := c.EthClient.SuggestGasTipCap()
+	if err != nil {
+		return nil, nil, err
+	}
+	if suggestTip.Cmp(gasTipCap) > 0 {
+		gasTipCap = suggestTip
+	}
+	header, err := c.EthClient.BlockHeaderByNumber(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.BaseFee != nil {
+		// 预留两倍 baseFee，保证后续几个区块 baseFee 上涨时仍可打包
+		minFeeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), gasTipCap)
+		if minFeeCap.Cmp(gasFeeCap) > 0 {
+			gasFeeCap = minFeeCap
+		}
+	}
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasFeeCap = new(big.Int).Set(gasTipCap)
+	}
+	return gasTipCap, gasFeeCap, nil
+}
+
+// fee * (100 + percent) / 100，向上取整
+func bumpFee(fee *big.Int, percent uint32) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(int64(100+percent)))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
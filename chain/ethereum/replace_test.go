@@ -0,0 +1,123 @@
+package ethereum
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 在 fakeTxNode 基础上补充费用查询和交易池中的原始交易
+type fakeFeeNode struct {
+	*fakeTxNode
+	baseFee *big.Int
+	tip     *big.Int
+	txs     map[common.Hash]*types.Transaction
+}
+
+func (f *fakeFeeNode) BlockHeaderByNumber(*big.Int) (*types.Header, error) {
+	return &types.Header{Number: new(big.Int).SetUint64(f.height), BaseFee: f.baseFee}, nil
+}
+
+func (f *fakeFeeNode) SuggestGasTipCap() (*big.Int, error) {
+	return f.tip, nil
+}
+
+func (f *fakeFeeNode) TxByHash(hash common.Hash) (*types.Transaction, error) {
+	if tx, ok := f.txs[hash]; ok {
+		return tx, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func newFakeFeeNode() *fakeFeeNode {
+	return &fakeFeeNode{
+		fakeTxNode: newFakeTxNode(),
+		baseFee:    big.NewInt(500000000),
+		tip:        big.NewInt(1000000000),
+		txs:        make(map[common.Hash]*types.Transaction),
+	}
+}
+
+func decodeReplaceTx(t *testing.T, base64Tx string) *Eip1559DynamicFeeTx {
+	raw, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := new(Eip1559DynamicFeeTx)
+	if err := json.Unmarshal(raw, tx); err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func Test_BuildSpeedUpTransaction(t *testing.T) {
+	node := newFakeFeeNode()
+	_, origin, from := signedTestTx(t, 5)
+	node.txs[origin.Hash()] = origin
+	adaptor := &ChainAdaptor{EthClient: node}
+
+	resp, err := adaptor.BuildSpeedUpTransaction(&account.ReplaceTransactionRequest{Hash: origin.Hash().Hex()})
+	if err != nil || resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build speed up fail: %v %s", err, resp.Msg)
+	}
+	if resp.Nonce != 5 {
+		t.Fatalf("expected nonce 5, got %d", resp.Nonce)
+	}
+	// 原交易 tip 2 gwei、feeCap 3 gwei，加价 10% 后分别为 2.2 gwei、3.3 gwei
+	if resp.MaxPriorityFeePerGas != "2200000000" || resp.MaxFeePerGas != "3300000000" {
+		t.Fatalf("unexpected fees tip=%s feeCap=%s", resp.MaxPriorityFeePerGas, resp.MaxFeePerGas)
+	}
+	tx := decodeReplaceTx(t, resp.Base64Tx)
+	if tx.FromAddress != from.Hex() || tx.ToAddress != origin.To().Hex() || tx.Amount != "1" || tx.GasLimit != 21000 {
+		t.Fatalf("unexpected speed up tx %+v", tx)
+	}
+	dFeeTx, _, err := buildDynamicFeeTx(resp.Base64Tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unSignTx, _ := CreateEip1559UnSignTx(dFeeTx, dFeeTx.ChainID)
+	if unSignTx != resp.UnSignTx {
+		t.Fatalf("base64 tx does not match unsigned tx")
+	}
+}
+
+func Test_BuildCancelTransaction(t *testing.T) {
+	node := newFakeFeeNode()
+	// baseFee 较高时 feeCap 至少为 2 * baseFee + tip
+	node.baseFee = big.NewInt(5000000000)
+	_, origin, from := signedTestTx(t, 9)
+	node.txs[origin.Hash()] = origin
+	adaptor := &ChainAdaptor{EthClient: node}
+
+	resp, _ := adaptor.BuildCancelTransaction(&account.ReplaceTransactionRequest{Hash: origin.Hash().Hex(), BumpPercent: 50})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build cancel fail: %s", resp.Msg)
+	}
+	if resp.MaxPriorityFeePerGas != "3000000000" || resp.MaxFeePerGas != "13000000000" {
+		t.Fatalf("unexpected fees tip=%s feeCap=%s", resp.MaxPriorityFeePerGas, resp.MaxFeePerGas)
+	}
+	tx := decodeReplaceTx(t, resp.Base64Tx)
+	if tx.Nonce != 9 || tx.ToAddress != from.Hex() || tx.Amount != "0" || tx.GasLimit != cancelGasLimit || tx.Data != "" {
+		t.Fatalf("unexpected cancel tx %+v", tx)
+	}
+}
+
+func Test_BuildReplaceTransactionMined(t *testing.T) {
+	node := newFakeFeeNode()
+	_, origin, _ := signedTestTx(t, 1)
+	node.receipts[origin.Hash()] = &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(99)}
+	adaptor := &ChainAdaptor{EthClient: node}
+
+	resp, _ := adaptor.BuildSpeedUpTransaction(&account.ReplaceTransactionRequest{Hash: origin.Hash().Hex()})
+	if resp.Code != global_const.ReturnCode_ERROR || resp.Msg != "transaction already mined" {
+		t.Fatalf("expected mined error, got %s", resp.Msg)
+	}
+}
@@ -0,0 +1,98 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"chain-account/common/retry"
+	"chain-account/config"
+)
+
+const (
+	defaultRetryAttempts = 3
+	maxErrorBodySize     = 4096
+)
+
+// 包装单个节点的 IRpc：请求经过该节点的熔断器，读请求遇到可重试的错误时退避重试，写请求不重试
+type RetryRpc struct {
+	IRpc
+	breaker  *retry.Breaker
+	attempts int
+	strategy retry.Strategy
+}
+
+func NewRetryRpc(client IRpc, conf config.Rpc) *RetryRpc {
+	attempts := conf.RetryAttempts
+	if attempts <= 0 {
+		attempts = defaultRetryAttempts
+	}
+	return &RetryRpc{
+		IRpc:     client,
+		breaker:  retry.NewBreaker(conf.BreakerThreshold, time.Duration(conf.BreakerCooldown)*time.Second),
+		attempts: attempts,
+		strategy: &retry.ExponentialStrategy{
+			Min:       100 * time.Millisecond,
+			Max:       2 * time.Second,
+			MaxJitter: 50 * time.Millisecond,
+		},
+	}
+}
+
+// 节点熔断器
+func (r *RetryRpc) Breaker() *retry.Breaker {
+	return r.breaker
+}
+
+func (r *RetryRpc) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	call := func() (struct{}, error) {
+		return struct{}{}, r.breaker.Do(func() error {
+			return r.IRpc.CallContext(ctx, result, method, args...)
+		})
+	}
+	if !readMethods[method] {
+		_, err := call()
+		return err
+	}
+	_, err := retry.Do(ctx, r.attempts, r.strategy, call)
+	return lastError(err)
+}
+
+// 批量请求由调用方按段重试，这里只经过熔断器
+func (r *RetryRpc) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	return r.breaker.Do(func() error {
+		return r.IRpc.BatchCallContext(ctx, b)
+	})
+}
+
+// 返回最后一次尝试的原始错误，调用方据此判断错误类型
+func lastError(err error) error {
+	var failed *retry.ErrFailedPermanently
+	if errors.As(err, &failed) {
+		return failed.LastErr
+	}
+	return err
+}
+
+// 节点返回 429/503 且带 Retry-After 头时，把等待时间附在错误中交给 retry.Do
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return resp, err
+	}
+	after, ok := retry.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return resp, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	resp.Body.Close()
+	return nil, retry.RetryAfter(rpc.HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Body: body}, after)
+}
@@ -0,0 +1,101 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"chain-account/common/retry"
+	"chain-account/config"
+)
+
+// 每次请求都返回 err 的节点
+type failingRpc struct {
+	*fakeRpc
+	err   error
+	calls int
+}
+
+func (f *failingRpc) CallContext(ctx context.Context, result any, method string, args ...any) error {
+	f.calls++
+	return f.err
+}
+
+func newTestRetryRpc(client IRpc, threshold int) *RetryRpc {
+	r := NewRetryRpc(client, config.Rpc{BreakerThreshold: threshold})
+	r.strategy = retry.Fixed(0)
+	return r
+}
+
+func Test_RetryRpcClassification(t *testing.T) {
+	// 读请求的可重试错误退避重试
+	node := &fakeRpc{latest: 100, failures: map[uint64]int{5: 2}}
+	client := newTestRetryRpc(node, 10)
+	var header *types.Header
+	if err := client.CallContext(context.Background(), &header, "eth_getBlockByNumber", hexutil.EncodeUint64(5), false); err != nil || header.Number.Uint64() != 5 {
+		t.Fatalf("CallContext: %v %v", header, err)
+	}
+	if len(node.calls) != 3 {
+		t.Fatalf("expected 3 calls, got %d", len(node.calls))
+	}
+
+	// 不可重试的错误原样返回，写请求不重试
+	permanent := &failingRpc{err: errors.New("nonce too low")}
+	client = newTestRetryRpc(permanent, 10)
+	if err := client.CallContext(context.Background(), nil, "eth_getBalance", "0x01", "latest"); err != permanent.err || permanent.calls != 1 {
+		t.Fatalf("expected single permanent failure, got %v, calls %d", err, permanent.calls)
+	}
+	transient := &failingRpc{err: errors.New("connection reset by peer")}
+	client = newTestRetryRpc(transient, 10)
+	if err := client.CallContext(context.Background(), nil, "eth_sendRawTransaction", "0x00"); err != transient.err || transient.calls != 1 {
+		t.Fatalf("expected write without retry, got %v, calls %d", err, transient.calls)
+	}
+}
+
+func Test_RetryRpcBreaker(t *testing.T) {
+	node := &failingRpc{err: errors.New("connection refused")}
+	client := newTestRetryRpc(node, 3)
+	client.CallContext(context.Background(), nil, "eth_blockNumber")
+	if node.calls != 3 || client.Breaker().State() != retry.BreakerOpen {
+		t.Fatalf("expected open breaker after 3 failures, got %v, calls %d", client.Breaker().State(), node.calls)
+	}
+	// 熔断后不再请求节点
+	if err := client.CallContext(context.Background(), nil, "eth_blockNumber"); !errors.Is(err, retry.ErrCircuitOpen) || node.calls != 3 {
+		t.Fatalf("expected open circuit, got %v, calls %d", err, node.calls)
+	}
+}
+
+func Test_RetryAfterTransport(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+	}))
+	defer server.Close()
+
+	rpcClient, err := dialRpc(context.Background(), server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestRetryRpc(NewRPC(rpcClient), 10)
+	var number hexutil.Uint64
+	start := time.Now()
+	if err := client.CallContext(context.Background(), &number, "eth_blockNumber"); err != nil || number != 16 {
+		t.Fatalf("CallContext: %v %v", number, err)
+	}
+	if elapsed := time.Since(start); requests.Load() != 2 || elapsed < time.Second {
+		t.Fatalf("expected retry after 1s, got %d requests after %v", requests.Load(), elapsed)
+	}
+}
@@ -75,19 +75,19 @@ func CreateEip1559UnSignTx(txData *types.DynamicFeeTx, chainId *big.Int) (string
 // txData 传统交易数据
 // signature 原始 ECDSA 签名（65 字节，R|S|V）
 // chainId 区块链网络 ID（如以太坊主网为 1）。
-func CreateLegacySignedTx(txData *types.LegacyTx, signature []byte, chainId *big.Int) (string, string, error) {
+func CreateLegacySignedTx(txData *types.LegacyTx, signature []byte, chainId *big.Int) (types.Signer, *types.Transaction, string, string, error) {
 	tx := types.NewTx(txData)
 	signer := types.LatestSignerForChainID(chainId)
 	signedTx, err := tx.WithSignature(signer, signature)
 	if err != nil {
-		return "", "", errors.New("tx with signature fail")
+		return nil, nil, "", "", errors.New("tx with signature fail")
 	}
 	signedTxData, err := rlp.EncodeToBytes(signedTx)
 	if err != nil {
-		return "", "", errors.New("encode tx to byte fail")
+		return nil, nil, "", "", errors.New("encode tx to byte fail")
 	}
-	// 编码后的交易数据、交易哈希（交易唯一标识）、err
-	return "0x" + hex.EncodeToString(signedTxData), signedTx.Hash().String(), nil
+	// 用于验证签名的签名器实例、包含完整签名数据的交易对象、编码后的交易数据、交易哈希（交易唯一标识）、err
+	return signer, signedTx, "0x" + hex.EncodeToString(signedTxData), signedTx.Hash().String(), nil
 }
 
 // 对符合 EIP-1559 标准的动态费用交易进行签名并序列化
@@ -15,4 +15,8 @@ type Eip1559DynamicFeeTx struct {
 	Amount string `json:"amount"`
 	// erc20 erc721 erc1155 contract_address
 	ContractAddress string `json:"contract_address"`
+	// 原始调用数据（0x 开头），设置后按原样发送到 to_address，忽略 contract_address
+	Data string `json:"data,omitempty"`
+	// 交易类型：0 为传统交易，2 为 EIP-1559 交易；为空时按节点能力选择，BuildUnSignTransaction 返回的 base64_tx 中已填写
+	TxType *uint8 `json:"tx_type,omitempty"`
 }
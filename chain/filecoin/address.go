@@ -0,0 +1,194 @@
+package filecoin
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/blake2b"
+
+	"chain-account/common/util"
+)
+
+// 地址协议：0 为 ID，1 为 secp256k1，2 为 actor，3 为 BLS，4 为 delegated（f410 为 EVM 地址）
+const (
+	ProtocolId        byte = 0
+	ProtocolSecp256k1 byte = 1
+	ProtocolActor     byte = 2
+	ProtocolBls       byte = 3
+	ProtocolDelegated byte = 4
+)
+
+// EAM（以太坊地址管理器）的 actor id，f4 地址在该命名空间下为 20 字节以太坊地址
+const eamNamespace = 10
+
+// 地址网络前缀
+const (
+	mainnetPrefix = "f"
+	testnetPrefix = "t"
+)
+
+const (
+	payloadHashLength   = 20
+	blsPublicKeyLength  = 48
+	checksumLength      = 4
+	maxSubAddressLength = 54
+)
+
+// 小写 RFC 4648 base32，无填充
+var addressEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// Address 的字节形式为 protocol || payload，ID 地址的 payload 为 uvarint 编码的 actor id，
+// delegated 地址的 payload 为 uvarint 编码的命名空间 || 子地址
+type Address []byte
+
+func newAddress(protocol byte, payload []byte) Address {
+	return append([]byte{protocol}, payload...)
+}
+
+func (a Address) Protocol() byte {
+	return a[0]
+}
+
+func (a Address) Payload() []byte {
+	return a[1:]
+}
+
+// checksum 为地址字节的 4 字节 blake2b 摘要
+func checksum(data []byte) []byte {
+	hash, _ := blake2b.New(checksumLength, nil)
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+func addressHash(data []byte) []byte {
+	hash, _ := blake2b.New(payloadHashLength, nil)
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+// String 返回地址的字符串形式，testnet 为 true 时使用 t 前缀
+func (a Address) String(testnet bool) string {
+	prefix := mainnetPrefix
+	if testnet {
+		prefix = testnetPrefix
+	}
+	switch a.Protocol() {
+	case ProtocolId:
+		id, _ := binary.Uvarint(a.Payload())
+		return prefix + "0" + strconv.FormatUint(id, 10)
+	case ProtocolDelegated:
+		namespace, n := binary.Uvarint(a.Payload())
+		subAddress := a.Payload()[n:]
+		return prefix + "4" + strconv.FormatUint(namespace, 10) + "f" + addressEncoding.EncodeToString(append(bytes.Clone(subAddress), checksum(a)...))
+	default:
+		return prefix + strconv.Itoa(int(a.Protocol())) + addressEncoding.EncodeToString(append(bytes.Clone(a.Payload()), checksum(a)...))
+	}
+}
+
+// 由 secp256k1 公钥（压缩或非压缩的 hex）生成 f1 地址，payload 为非压缩公钥的 blake2b-160
+func NewSecp256k1Address(pubKey []byte) (Address, error) {
+	key, err := util.DecompressPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return newAddress(ProtocolSecp256k1, addressHash(key.SerializeUncompressed())), nil
+}
+
+// 由 secp256k1 公钥生成 f410 地址，子地址为公钥对应的以太坊地址
+func NewDelegatedAddress(pubKey []byte) (Address, error) {
+	key, err := util.DecompressPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	ethAddress := crypto.Keccak256(key.SerializeUncompressed()[1:])[12:]
+	return newAddress(ProtocolDelegated, append(binary.AppendUvarint(nil, eamNamespace), ethAddress...)), nil
+}
+
+// 解析地址字符串，返回地址和是否为测试网地址。base32 部分必须为规范编码，末尾填充位不为 0 的视为无效
+func ParseAddress(address string) (Address, bool, error) {
+	if len(address) < 3 {
+		return nil, false, errors.New("address too short")
+	}
+	var testnet bool
+	switch address[:1] {
+	case mainnetPrefix:
+	case testnetPrefix:
+		testnet = true
+	default:
+		return nil, false, errors.New("unknown network prefix")
+	}
+	protocol := address[1] - '0'
+	raw := address[2:]
+	switch protocol {
+	case ProtocolId:
+		id, err := strconv.ParseUint(raw, 10, 63)
+		if err != nil || strconv.FormatUint(id, 10) != raw {
+			return nil, false, errors.New("invalid actor id")
+		}
+		return newAddress(ProtocolId, binary.AppendUvarint(nil, id)), testnet, nil
+	case ProtocolSecp256k1, ProtocolActor, ProtocolBls:
+		decoded, err := addressEncoding.DecodeString(raw)
+		if err != nil || len(decoded) < checksumLength || addressEncoding.EncodeToString(decoded) != raw {
+			return nil, false, errors.New("invalid address encoding")
+		}
+		payload := decoded[:len(decoded)-checksumLength]
+		expectedLength := payloadHashLength
+		if protocol == ProtocolBls {
+			expectedLength = blsPublicKeyLength
+		}
+		if len(payload) != expectedLength {
+			return nil, false, errors.New("invalid payload length")
+		}
+		addr := newAddress(protocol, payload)
+		if !bytes.Equal(checksum(addr), decoded[len(payload):]) {
+			return nil, false, errors.New("invalid checksum")
+		}
+		return addr, testnet, nil
+	case ProtocolDelegated:
+		namespaceStr, encoded, ok := strings.Cut(raw, "f")
+		if !ok {
+			return nil, false, errors.New("missing namespace separator")
+		}
+		namespace, err := strconv.ParseUint(namespaceStr, 10, 63)
+		if err != nil || strconv.FormatUint(namespace, 10) != namespaceStr {
+			return nil, false, errors.New("invalid namespace")
+		}
+		decoded, err := addressEncoding.DecodeString(encoded)
+		if err != nil || len(decoded) < checksumLength || len(decoded)-checksumLength > maxSubAddressLength ||
+			addressEncoding.EncodeToString(decoded) != encoded {
+			return nil, false, errors.New("invalid address encoding")
+		}
+		subAddress := decoded[:len(decoded)-checksumLength]
+		if namespace == eamNamespace && len(subAddress) != payloadHashLength {
+			return nil, false, errors.New("invalid eth address length")
+		}
+		addr := newAddress(ProtocolDelegated, append(binary.AppendUvarint(nil, namespace), subAddress...))
+		if !bytes.Equal(checksum(addr), decoded[len(subAddress):]) {
+			return nil, false, errors.New("invalid checksum")
+		}
+		return addr, testnet, nil
+	default:
+		return nil, false, errors.New("unknown address protocol")
+	}
+}
+
+// 解析 CBOR 中的地址字节
+func addressFromBytes(data []byte) (Address, error) {
+	if len(data) < 2 {
+		return nil, errors.New("address too short")
+	}
+	addr := Address(data)
+	parsed, _, err := ParseAddress(addr.String(false))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(parsed, addr) {
+		return nil, errors.New("non-canonical address bytes")
+	}
+	return parsed, nil
+}
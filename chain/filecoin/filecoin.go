@@ -0,0 +1,525 @@
+package filecoin
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Filecoin"
+
+// 单次 GetBlockByRange 最多查询的高度数
+const blockRangeLimit = 100
+
+// 估算 gas premium 的档位：期望在多少个高度内上链
+const (
+	slowFeeBlocks   = 10
+	normalFeeBlocks = 5
+	fastFeeBlocks   = 1
+)
+
+// 一次 FIL 转账的 gas 上限，仅用于估算 gas premium
+const transferGasLimit = 2_000_000
+
+type ChainAdaptor struct {
+	FilClient ILotus
+	// 测试网地址使用 t 前缀
+	testnet bool
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.Fil
+	network := con.NetWork
+	if node.Network != "" {
+		network = node.Network
+	}
+	filClient, err := NewLotusClient(node.RpcUrl, node.RpcPass, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		FilClient: filClient,
+		testnet:   network == "testnet",
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入 secp256k1 公钥 转换成 f1 地址，type 为 f4 时转换成 f410 地址（对应 FEVM 的以太坊地址）
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeSecp256k1); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(req.PublicKey, "0x"))
+	var address Address
+	if err == nil {
+		switch req.Type {
+		case "", "f1":
+			address, err = NewSecp256k1Address(pubKey)
+		case "f4":
+			address, err = NewDelegatedAddress(pubKey)
+		default:
+			return &account.ConvertAddressResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "unsupported address type " + req.Type,
+			}, nil
+		}
+	}
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address.String(c.testnet),
+	}, nil
+}
+
+// 地址格式验证，支持 f0 到 f4 全部协议，网络前缀必须与节点一致
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if _, err := c.parseAddress(req.Address); err != nil {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 通过高度获取 tipset，height 为 0 时返回最新 tipset。区块哈希为 tipset 第一个区块的 CID，
+// 交易列表为 tipset 中各区块去重后的 FIL 转账消息，消息在下一个 tipset 执行
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	tipSet, err := c.tipSetByHeight(req.Height)
+	if err != nil {
+		log.Error("get tipset fail", "height", req.Height, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return c.blockResponse(tipSet, "get block by number")
+}
+
+// 通过区块 CID 获取所在的 tipset
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	tipSet, err := c.tipSetByBlock(req.Hash)
+	if err != nil {
+		log.Error("get tipset fail", "hash", req.Hash, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return c.blockResponse(tipSet, "get block by hash")
+}
+
+func (c *ChainAdaptor) blockResponse(tipSet *TipSet, action string) (*account.BlockResponse, error) {
+	var blockTxList []*account.BlockInfoTransactionList
+	seen := make(map[string]bool)
+	for _, blockCid := range tipSet.Cids {
+		messages, err := c.FilClient.ChainGetBlockMessages(blockCid.Root)
+		if err != nil {
+			log.Error("get block messages fail", "cid", blockCid.Root, "err", err)
+			return &account.BlockResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  action + " fail",
+			}, nil
+		}
+		msgs := messages.BlsMessages
+		for _, signed := range messages.SecpkMessages {
+			msgs = append(msgs, signed.Message)
+		}
+		if len(msgs) != len(messages.Cids) {
+			log.Error("block messages mismatch", "cid", blockCid.Root)
+			return &account.BlockResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  action + " fail",
+			}, nil
+		}
+		for i, msg := range msgs {
+			hash := messages.Cids[i].Root
+			if seen[hash] || !isTransfer(&msg) {
+				continue
+			}
+			seen[hash] = true
+			blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+				From:   msg.From,
+				To:     msg.To,
+				Hash:   hash,
+				Height: uint64(tipSet.Height),
+				Amount: msg.Value,
+			})
+		}
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          action + " success",
+		Height:       tipSet.Height,
+		Hash:         tipSet.Cids[0].Root,
+		Transactions: blockTxList,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	tipSet, err := c.tipSetByBlock(req.Hash)
+	if err != nil {
+		log.Error("get tipset fail", "hash", req.Hash, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by hash success",
+		BlockHeader: toBlockHeader(tipSet),
+	}, nil
+}
+
+// 通过高度获取区块头信息，height 为 0 时返回最新 tipset
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	tipSet, err := c.tipSetByHeight(req.Height)
+	if err != nil {
+		log.Error("get tipset fail", "height", req.Height, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(tipSet),
+	}, nil
+}
+
+// 获取区间内的 tipset 头，空轮次的高度不返回
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseInt(req.Start, 10, 64)
+	if err != nil || start < 0 {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseInt(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	if end-start >= blockRangeLimit {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "block range too large",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for height := start; height <= end; height++ {
+		tipSet, err := c.FilClient.ChainGetTipSetByHeight(height)
+		if err != nil {
+			log.Error("get tipset fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		if tipSet.Height != height {
+			continue
+		}
+		headers = append(headers, toBlockHeader(tipSet))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取余额（attoFIL）和消息池中的下一个 nonce，不存在的账户返回 0
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if _, err := c.parseAddress(req.Address); err != nil {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	balance, err := c.FilClient.WalletBalance(req.Address)
+	if err != nil {
+		log.Error("get balance fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get balance fail",
+		}, nil
+	}
+	nonce, err := c.FilClient.MpoolGetNonce(req.Address)
+	if err != nil && !IsNotFound(err) {
+		log.Error("get nonce fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get nonce fail",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      strconv.FormatUint(nonce, 10),
+		Balance:       balance,
+	}, nil
+}
+
+// 获取 gas 单价（attoFIL/gas），为最新 base fee 加各档位估算的 gas premium，可作为 gas_fee_cap
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	head, err := c.FilClient.ChainHead()
+	if err != nil || len(head.Blocks) == 0 {
+		log.Error("get chain head fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get base fee fail",
+		}, nil
+	}
+	baseFee, ok := new(big.Int).SetString(head.Blocks[0].ParentBaseFee, 10)
+	if !ok {
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get base fee fail",
+		}, nil
+	}
+	var fees [3]string
+	for i, nblocks := range []uint64{slowFeeBlocks, normalFeeBlocks, fastFeeBlocks} {
+		premium, err := c.FilClient.GasEstimateGasPremium(nblocks, transferGasLimit)
+		gasPremium, ok := new(big.Int).SetString(premium, 10)
+		if err != nil || !ok {
+			log.Error("estimate gas premium fail", "nblocks", nblocks, "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "estimate gas premium fail",
+			}, nil
+		}
+		fees[i] = new(big.Int).Add(baseFee, gasPremium).String()
+	}
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fees[0],
+		NormalFee: fees[1],
+		FastFee:   fees[2],
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的 signed_tx
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	signedBytes, err := base64.StdEncoding.DecodeString(req.RawTx)
+	var signedMsg *SignedMessage
+	if err == nil {
+		signedMsg, err = UnmarshalSignedMessage(signedBytes)
+	}
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	cid, err := c.FilClient.MpoolPush(&SignedMessageJson{
+		Message:   *c.messageJson(&signedMsg.Message),
+		Signature: Signature{Type: sigTypeSecp256k1, Data: signedMsg.Signature},
+	})
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: cid,
+	}, nil
+}
+
+// Lotus 节点不按地址建立消息索引
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get tx by address is not supported",
+	}, nil
+}
+
+// 通过消息 CID 获取交易，消息被同 nonce 的消息替换时返回替换后的消息。
+// 已广播未上链的消息为 pending，fee 为重放消息得到的全部花费
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	lookup, err := c.FilClient.StateSearchMsg(req.Hash)
+	if err != nil && !IsNotFound(err) {
+		log.Error("search message fail", "cid", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	hash := req.Hash
+	if lookup != nil {
+		hash = lookup.Message.Root
+	}
+	msg, err := c.FilClient.ChainGetMessage(hash)
+	if IsNotFound(err) {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get message fail", "cid", hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	txMessage := &account.TxMessage{
+		Hash:   hash,
+		From:   msg.From,
+		To:     msg.To,
+		Value:  msg.Value,
+		Status: account.TxStatus_Pending,
+	}
+	if lookup != nil {
+		txMessage.Status = account.TxStatus_Success
+		if lookup.Receipt.ExitCode != 0 {
+			txMessage.Status = account.TxStatus_Failed
+		}
+		txMessage.Height = strconv.FormatInt(lookup.Height, 10)
+		if tipSet, err := c.FilClient.ChainGetTipSetByHeight(lookup.Height); err == nil && len(tipSet.Blocks) > 0 {
+			txMessage.Datetime = strconv.FormatUint(tipSet.Blocks[0].Timestamp, 10)
+		}
+		if result, err := c.FilClient.StateReplay(lookup.TipSet, hash); err == nil {
+			txMessage.Fee = result.GasCost.TotalCost
+		} else {
+			log.Warn("replay message fail", "cid", hash, "err", err)
+		}
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   txMessage,
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// 空轮次没有 tipset，节点会返回更低高度的 tipset，视为不存在
+func (c *ChainAdaptor) tipSetByHeight(height int64) (*TipSet, error) {
+	var tipSet *TipSet
+	var err error
+	if height == 0 {
+		tipSet, err = c.FilClient.ChainHead()
+	} else {
+		tipSet, err = c.FilClient.ChainGetTipSetByHeight(height)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if (height != 0 && tipSet.Height != height) || len(tipSet.Cids) == 0 {
+		return nil, &RpcError{Message: "tipset not found at height " + strconv.FormatInt(height, 10)}
+	}
+	return tipSet, nil
+}
+
+func (c *ChainAdaptor) tipSetByBlock(blockCid string) (*TipSet, error) {
+	header, err := c.FilClient.ChainGetBlock(blockCid)
+	if err != nil {
+		return nil, err
+	}
+	tipSet, err := c.tipSetByHeight(header.Height)
+	if err != nil {
+		return nil, err
+	}
+	for _, cid := range tipSet.Cids {
+		if cid.Root == blockCid {
+			return tipSet, nil
+		}
+	}
+	// 区块不在主链上
+	return nil, &RpcError{Message: "block not found in canonical tipset"}
+}
+
+// 只统计调用 Send 方法且金额大于 0 的消息
+func isTransfer(msg *MessageJson) bool {
+	return msg.Method == methodSend && msg.Value != "" && msg.Value != "0"
+}
+
+func toBlockHeader(tipSet *TipSet) *account.BlockHeader {
+	header := &account.BlockHeader{
+		Number: strconv.FormatInt(tipSet.Height, 10),
+	}
+	if len(tipSet.Cids) > 0 {
+		header.Hash = tipSet.Cids[0].Root
+	}
+	if len(tipSet.Blocks) > 0 {
+		block := tipSet.Blocks[0]
+		header.CoinBase = block.Miner
+		header.Time = block.Timestamp
+		header.BaseFee = block.ParentBaseFee
+		if len(block.Parents) > 0 {
+			header.ParentHash = block.Parents[0].Root
+		}
+	}
+	return header
+}
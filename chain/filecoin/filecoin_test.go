@@ -0,0 +1,329 @@
+package filecoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+const testReceiver = "f1abjxfbp274xpdqcpuaykwkfb43omjotacm2p3za"
+
+// 模拟 Lotus 节点
+type fakeLotus struct {
+	ILotus
+	estimated *MessageJson
+	pushed    *SignedMessageJson
+	lookups   map[string]*MsgLookup
+	messages  map[string]*MessageJson
+}
+
+func (f *fakeLotus) MpoolGetNonce(address string) (uint64, error) {
+	if address == testReceiver {
+		return 0, &RpcError{Code: 1, Message: "resolution lookup failed: actor not found"}
+	}
+	return 9, nil
+}
+
+func (f *fakeLotus) WalletBalance(address string) (string, error) {
+	return "1000000000000000000", nil
+}
+
+func (f *fakeLotus) GasEstimateMessageGas(msg *MessageJson) (*MessageJson, error) {
+	f.estimated = msg
+	estimated := *msg
+	estimated.GasLimit, estimated.GasFeeCap, estimated.GasPremium = 1500000, "200000", "100000"
+	return &estimated, nil
+}
+
+func (f *fakeLotus) GasEstimateGasPremium(nblocks uint64, gasLimit int64) (string, error) {
+	return new(big.Int).SetUint64(1000 / nblocks).String(), nil
+}
+
+func (f *fakeLotus) MpoolPush(msg *SignedMessageJson) (string, error) {
+	f.pushed = msg
+	return "bafy2bzacepushed", nil
+}
+
+func (f *fakeLotus) StateSearchMsg(cid string) (*MsgLookup, error) {
+	return f.lookups[cid], nil
+}
+
+func (f *fakeLotus) StateReplay(tipSet []Cid, cid string) (*InvocResult, error) {
+	result := new(InvocResult)
+	result.GasCost.TotalCost = "123456"
+	return result, nil
+}
+
+func (f *fakeLotus) ChainGetMessage(cid string) (*MessageJson, error) {
+	msg, ok := f.messages[cid]
+	if !ok {
+		return nil, &RpcError{Code: 1, Message: "blockstore: block not found"}
+	}
+	return msg, nil
+}
+
+func (f *fakeLotus) ChainHead() (*TipSet, error) {
+	return f.ChainGetTipSetByHeight(100)
+}
+
+// 高度 50 为空轮次
+func (f *fakeLotus) ChainGetTipSetByHeight(height int64) (*TipSet, error) {
+	if height == 50 {
+		height = 49
+	}
+	return &TipSet{
+		Cids:   []Cid{{Root: "block-a"}, {Root: "block-b"}},
+		Blocks: []BlockHeader{{Miner: "f01000", Parents: []Cid{{Root: "parent"}}, Height: height, Timestamp: 1700000000, ParentBaseFee: "100"}},
+		Height: height,
+	}, nil
+}
+
+func (f *fakeLotus) ChainGetBlock(cid string) (*BlockHeader, error) {
+	return &BlockHeader{Height: 100}, nil
+}
+
+// 两个区块包含同一条消息
+func (f *fakeLotus) ChainGetBlockMessages(cid string) (*BlockMessages, error) {
+	transfer := SignedMessageJson{Message: MessageJson{From: "f1from", To: testReceiver, Value: "500", Method: methodSend}}
+	messages := &BlockMessages{SecpkMessages: []SignedMessageJson{transfer}, Cids: []Cid{{Root: "shared"}}}
+	if cid == "block-a" {
+		messages.BlsMessages = []MessageJson{
+			{From: "f3from", To: testReceiver, Value: "700", Method: methodSend},
+			{From: "f3from", To: "f01234", Value: "0", Method: 2},
+		}
+		messages.Cids = []Cid{{Root: "bls"}, {Root: "invoke"}, {Root: "shared"}}
+	}
+	return messages, nil
+}
+
+func testKey(t *testing.T) ([]byte, func(hash []byte) []byte) {
+	key, err := crypto.ToECDSA(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(hash []byte) []byte {
+		signature, err := crypto.Sign(hash, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+	return crypto.CompressPubkey(&key.PublicKey), sign
+}
+
+func Test_Address(t *testing.T) {
+	vectors := map[string]string{
+		"f01024":     "008008",
+		testReceiver: "0100537285faff2ef1c04fa030ab28a1e6dcc4ba60",
+		"f3vvmn62lofvhjd2ugzca6sof2j2ubwok6cj4xxbfzz4yuxfkgobpihhd2thlanmsh3w2ptld2gqkn2jvlss4a": "03ad58df696e2d4e91ea86c881e938ba4ea81b395e12797b84b9cf314b9546705e839c7a99d606b247ddb4f9ac7a3414dd",
+		"f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxa":                                           "040a52963ef50e27e06d72d59fcb4f3c2a687be3cfef",
+	}
+	for address, expected := range vectors {
+		addr, testnet, err := ParseAddress(address)
+		if err != nil || testnet || hex.EncodeToString(addr) != expected {
+			t.Fatalf("parse %s: %x %v", address, addr, err)
+		}
+		if addr.String(false) != address || addr.String(true) != "t"+address[1:] {
+			t.Fatalf("unexpected string %s", addr.String(false))
+		}
+		if _, err := addressFromBytes(addr); err != nil {
+			t.Fatalf("address bytes of %s: %v", address, err)
+		}
+	}
+	invalid := []string{"f1abjxfbp274xpdqcpuaykwkfb43omjotacm2p3zb", "f1bbjxfbp274xpdqcpuaykwkfb43omjotacm2p3za", "x01024", "f0", "f01024a", "f5abc", "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxb", "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamya"}
+	for _, address := range invalid {
+		if _, _, err := ParseAddress(address); err == nil {
+			t.Errorf("%s should be invalid", address)
+		}
+	}
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	pubKey, _ := testKey(t)
+	adaptor := &ChainAdaptor{testnet: true}
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: hex.EncodeToString(pubKey)})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Address[:2] != "t1" {
+		t.Fatalf("unexpected address %s: %s", resp.Address, resp.Msg)
+	}
+	valid, _ := adaptor.ValidAddress(&account.ValidAddressRequest{Address: resp.Address})
+	if !valid.Valid {
+		t.Fatal("converted address should be valid")
+	}
+	mainnet, _ := (&ChainAdaptor{}).ValidAddress(&account.ValidAddressRequest{Address: resp.Address})
+	if mainnet.Valid {
+		t.Fatal("testnet address should be invalid on mainnet")
+	}
+
+	// f410 地址的子地址为以太坊地址
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: hex.EncodeToString(pubKey), Type: "f4"})
+	addr, _, err := ParseAddress(resp.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.DecompressPubkey(pubKey)
+	if !bytes.Equal(addr.Payload()[1:], crypto.PubkeyToAddress(*key).Bytes()) {
+		t.Fatalf("unexpected f4 address %s", resp.Address)
+	}
+}
+
+func Test_MessageRoundTrip(t *testing.T) {
+	to, _, _ := ParseAddress("f01024")
+	from, _, _ := ParseAddress(testReceiver)
+	msg := &Message{
+		To:         to,
+		From:       from,
+		Nonce:      1 << 40,
+		Value:      new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil),
+		GasLimit:   1500000,
+		GasFeeCap:  big.NewInt(200000),
+		GasPremium: new(big.Int),
+		Params:     []byte{},
+	}
+	decoded, err := UnmarshalMessage(msg.MarshalCBOR())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.MarshalCBOR(), msg.MarshalCBOR()) || decoded.Value.Cmp(msg.Value) != 0 {
+		t.Fatal("round trip mismatch")
+	}
+	if _, err := UnmarshalMessage(msg.MarshalCBOR()[:20]); err == nil {
+		t.Fatal("truncated message should be rejected")
+	}
+	if cid := EncodeCid(msg.Cid()); cid[:6] != "bafy2b" {
+		t.Fatalf("unexpected cid %s", cid)
+	}
+}
+
+func Test_BuildTransfer(t *testing.T) {
+	fake := &fakeLotus{}
+	adaptor := &ChainAdaptor{FilClient: fake}
+	pubKey, sign := testKey(t)
+	fromAddr, _ := NewSecp256k1Address(pubKey)
+	from := fromAddr.String(false)
+
+	txJson, _ := json.Marshal(FilTransferTx{FromAddress: from, ToAddress: "f410fkkld55ioe7qg24wvt7fu6pbknb56ht7pt4zamxa", Amount: "1000"})
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	if fake.estimated == nil || fake.estimated.Nonce != 9 || fake.estimated.From != from {
+		t.Fatalf("unexpected estimate request %+v", fake.estimated)
+	}
+	msgBytes, _ := base64.StdEncoding.DecodeString(resp.UnSignTx)
+	msg, err := UnmarshalMessage(msgBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Nonce != 9 || msg.GasLimit != 1500000 || msg.GasFeeCap.String() != "200000" || msg.Value.String() != "1000" {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	if resp.SignHashes[0] != hex.EncodeToString(msg.SigningHash()) {
+		t.Fatal("sign hash mismatch")
+	}
+
+	// 签名并广播
+	signature := sign(msg.SigningHash())
+	signedResp, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: resp.UnSignTx, Signature: hex.EncodeToString(signature)})
+	if signedResp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", signedResp.Msg)
+	}
+	sendResp, _ := adaptor.SendTx(&account.SendTxRequest{RawTx: signedResp.SignedTx})
+	if sendResp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("send tx fail: %s", sendResp.Msg)
+	}
+	if fake.pushed.Message.GasPremium != "100000" || !bytes.Equal(fake.pushed.Signature.Data, signature) {
+		t.Fatalf("unexpected pushed message %+v", fake.pushed)
+	}
+
+	// 其他私钥的签名
+	otherKey, _ := crypto.ToECDSA(bytes.Repeat([]byte{2}, 32))
+	otherSignature, _ := crypto.Sign(msg.SigningHash(), otherKey)
+	signedResp, _ = adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: resp.UnSignTx, Signature: hex.EncodeToString(otherSignature)})
+	if signedResp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("signature of other key should be rejected")
+	}
+
+	// 指定 nonce 和 gas 参数时不请求节点，nonce 可以为 0
+	fake.estimated = nil
+	nonce := uint64(0)
+	txJson, _ = json.Marshal(FilTransferTx{FromAddress: from, ToAddress: testReceiver, Amount: "1", Nonce: &nonce, GasLimit: 1000000, GasFeeCap: "300", GasPremium: "100"})
+	resp, _ = adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS || fake.estimated != nil {
+		t.Fatalf("build with explicit params fail: %s", resp.Msg)
+	}
+
+	// 发送方必须为 f1 地址
+	txJson, _ = json.Marshal(FilTransferTx{FromAddress: "f01024", ToAddress: testReceiver, Amount: "1"})
+	resp, _ = adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("non f1 sender should be rejected")
+	}
+}
+
+func Test_GetBlockByNumber(t *testing.T) {
+	adaptor := &ChainAdaptor{FilClient: &fakeLotus{}}
+	resp, _ := adaptor.GetBlockByNumber(&account.BlockNumberRequest{Height: 100})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Hash != "block-a" || len(resp.Transactions) != 2 {
+		t.Fatalf("unexpected block %+v", resp)
+	}
+	if resp.Transactions[0].Hash != "bls" || resp.Transactions[1].Hash != "shared" || resp.Transactions[1].Amount != "500" {
+		t.Fatalf("unexpected transactions %+v", resp.Transactions)
+	}
+
+	resp, _ = adaptor.GetBlockByNumber(&account.BlockNumberRequest{Height: 50})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("null round should not return tipset")
+	}
+	rangeResp, _ := adaptor.GetBlockByRange(&account.BlockByRangeRequest{Start: "48", End: "51"})
+	if rangeResp.Code != global_const.ReturnCode_SUCCESS || len(rangeResp.BlockHeader) != 3 {
+		t.Fatalf("unexpected range %+v", rangeResp)
+	}
+	header, _ := adaptor.GetBlockHeaderByHash(&account.BlockHeaderHashRequest{Hash: "block-b"})
+	if header.Code != global_const.ReturnCode_SUCCESS || header.BlockHeader.ParentHash != "parent" || header.BlockHeader.Time != 1700000000 {
+		t.Fatalf("unexpected header %+v", header)
+	}
+}
+
+func Test_GetTxByHash(t *testing.T) {
+	lookup := &MsgLookup{Message: Cid{Root: "replaced"}, Height: 101}
+	lookup.Receipt.ExitCode = 0
+	adaptor := &ChainAdaptor{FilClient: &fakeLotus{
+		lookups: map[string]*MsgLookup{"original": lookup},
+		messages: map[string]*MessageJson{
+			"replaced": {From: "f1from", To: testReceiver, Value: "500"},
+			"pending":  {From: "f1from", To: testReceiver, Value: "600"},
+		},
+	}}
+	resp, _ := adaptor.GetTxByHash(&account.TxHashRequest{Hash: "original"})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Tx.Status != account.TxStatus_Success || resp.Tx.Hash != "replaced" || resp.Tx.Fee != "123456" || resp.Tx.Height != "101" {
+		t.Fatalf("unexpected tx %+v", resp.Tx)
+	}
+	resp, _ = adaptor.GetTxByHash(&account.TxHashRequest{Hash: "pending"})
+	if resp.Tx.Status != account.TxStatus_Pending || resp.Tx.Value != "600" {
+		t.Fatalf("unexpected pending tx %+v", resp.Tx)
+	}
+	resp, _ = adaptor.GetTxByHash(&account.TxHashRequest{Hash: "missing"})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Tx.Status != account.TxStatus_NotFound {
+		t.Fatalf("unexpected missing tx %+v", resp.Tx)
+	}
+}
+
+func Test_GetFee(t *testing.T) {
+	adaptor := &ChainAdaptor{FilClient: &fakeLotus{}}
+	resp, _ := adaptor.GetFee(&account.FeeRequest{})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.SlowFee != "200" || resp.NormalFee != "300" || resp.FastFee != "1100" {
+		t.Fatalf("unexpected fee %+v", resp)
+	}
+	accountResp, _ := adaptor.GetAccount(&account.AccountRequest{Address: testReceiver})
+	if accountResp.Code != global_const.ReturnCode_SUCCESS || accountResp.Sequence != "0" {
+		t.Fatalf("unexpected account %+v", accountResp)
+	}
+}
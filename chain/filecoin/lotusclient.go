@@ -0,0 +1,291 @@
+package filecoin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// Lotus JSON-RPC 错误
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// actor、消息或区块不存在
+func IsNotFound(err error) bool {
+	var rpcErr *RpcError
+	return errors.As(err, &rpcErr) && (strings.Contains(rpcErr.Message, "not found") || strings.Contains(rpcErr.Message, "could not find"))
+}
+
+// Lotus 的 CID JSON 格式 {"/": "bafy..."}
+type Cid struct {
+	Root string `json:"/"`
+}
+
+// 金额为十进制字符串，params 为 base64
+type MessageJson struct {
+	Version    uint64 `json:"Version"`
+	To         string `json:"To"`
+	From       string `json:"From"`
+	Nonce      uint64 `json:"Nonce"`
+	Value      string `json:"Value"`
+	GasLimit   int64  `json:"GasLimit"`
+	GasFeeCap  string `json:"GasFeeCap"`
+	GasPremium string `json:"GasPremium"`
+	Method     uint64 `json:"Method"`
+	Params     []byte `json:"Params"`
+	CID        *Cid   `json:"CID,omitempty"`
+}
+
+type Signature struct {
+	Type byte   `json:"Type"`
+	Data []byte `json:"Data"`
+}
+
+type SignedMessageJson struct {
+	Message   MessageJson `json:"Message"`
+	Signature Signature   `json:"Signature"`
+	CID       *Cid        `json:"CID,omitempty"`
+}
+
+type BlockHeader struct {
+	Miner         string `json:"Miner"`
+	Parents       []Cid  `json:"Parents"`
+	Height        int64  `json:"Height"`
+	Timestamp     uint64 `json:"Timestamp"`
+	ParentBaseFee string `json:"ParentBaseFee"`
+}
+
+// 同一高度的区块组成 tipset，高度无区块（空轮次）时节点返回更低高度的 tipset
+type TipSet struct {
+	Cids   []Cid         `json:"Cids"`
+	Blocks []BlockHeader `json:"Blocks"`
+	Height int64         `json:"Height"`
+}
+
+// cids 依次为 BLS 消息和 secp256k1 消息的 CID
+type BlockMessages struct {
+	BlsMessages   []MessageJson       `json:"BlsMessages"`
+	SecpkMessages []SignedMessageJson `json:"SecpkMessages"`
+	Cids          []Cid               `json:"Cids"`
+}
+
+// 消息执行结果，height 为回执所在 tipset 的高度
+type MsgLookup struct {
+	Message Cid `json:"Message"`
+	Receipt struct {
+		ExitCode int64  `json:"ExitCode"`
+		Return   []byte `json:"Return"`
+		GasUsed  int64  `json:"GasUsed"`
+	} `json:"Receipt"`
+	TipSet []Cid `json:"TipSet"`
+	Height int64 `json:"Height"`
+}
+
+// 重放消息的结果，total_cost 为消息消耗的全部 attoFIL
+type InvocResult struct {
+	GasCost struct {
+		TotalCost string `json:"TotalCost"`
+	} `json:"GasCost"`
+}
+
+type rpcRequest struct {
+	JsonRpc string `json:"jsonrpc"`
+	Id      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RpcError       `json:"error"`
+}
+
+// 定义 Lotus 节点接口
+type ILotus interface {
+	// 账户
+	MpoolGetNonce(address string) (uint64, error)
+	WalletBalance(address string) (string, error)
+	// 交易
+	GasEstimateMessageGas(msg *MessageJson) (*MessageJson, error)
+	GasEstimateGasPremium(nblocks uint64, gasLimit int64) (string, error)
+	MpoolPush(msg *SignedMessageJson) (string, error)
+	// 消息未上链时返回 nil
+	StateSearchMsg(cid string) (*MsgLookup, error)
+	StateReplay(tipSet []Cid, cid string) (*InvocResult, error)
+	ChainGetMessage(cid string) (*MessageJson, error)
+	// 区块
+	ChainHead() (*TipSet, error)
+	ChainGetTipSetByHeight(height int64) (*TipSet, error)
+	ChainGetBlock(cid string) (*BlockHeader, error)
+	ChainGetBlockMessages(cid string) (*BlockMessages, error)
+}
+
+// 定义 Lotus JSON-RPC 客户端，token 不为空时通过 Authorization 头认证
+type LotusClient struct {
+	url    string
+	token  string
+	client *http.Client
+	nextId atomic.Uint64
+}
+
+func NewLotusClient(rpcUrl string, token string, timeout time.Duration) (ILotus, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &LotusClient{
+		url:    rpcUrl,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// 调用 Filecoin.* 方法并解析结果
+func (l *LotusClient) call(result any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(&rpcRequest{
+		JsonRpc: "2.0",
+		Id:      l.nextId.Add(1),
+		Method:  "Filecoin." + method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, l.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if l.token != "" {
+		req.Header.Set("Authorization", "Bearer "+l.token)
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return fmt.Errorf("call %s fail, status %d: %s", method, resp.StatusCode, string(respBody))
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+func (l *LotusClient) MpoolGetNonce(address string) (uint64, error) {
+	var nonce uint64
+	err := l.call(&nonce, "MpoolGetNonce", address)
+	return nonce, err
+}
+
+func (l *LotusClient) WalletBalance(address string) (string, error) {
+	var balance string
+	err := l.call(&balance, "WalletBalance", address)
+	return balance, err
+}
+
+// max_fee 为 0 时使用节点默认的费用上限
+func (l *LotusClient) GasEstimateMessageGas(msg *MessageJson) (*MessageJson, error) {
+	estimated := new(MessageJson)
+	if err := l.call(estimated, "GasEstimateMessageGas", msg, map[string]string{"MaxFee": "0"}, nil); err != nil {
+		return nil, err
+	}
+	return estimated, nil
+}
+
+// 估算在 nblocks 个高度内上链所需的 gas premium，节点不使用发送方地址
+func (l *LotusClient) GasEstimateGasPremium(nblocks uint64, gasLimit int64) (string, error) {
+	var premium string
+	err := l.call(&premium, "GasEstimateGasPremium", nblocks, "f00", gasLimit, nil)
+	return premium, err
+}
+
+func (l *LotusClient) MpoolPush(msg *SignedMessageJson) (string, error) {
+	var cid Cid
+	if err := l.call(&cid, "MpoolPush", msg); err != nil {
+		return "", err
+	}
+	return cid.Root, nil
+}
+
+// 从最新 tipset 向前查找，允许查找被替换（同 nonce 提高费用）的消息
+func (l *LotusClient) StateSearchMsg(cid string) (*MsgLookup, error) {
+	var lookup *MsgLookup
+	if err := l.call(&lookup, "StateSearchMsg", []Cid{}, Cid{Root: cid}, -1, true); err != nil {
+		return nil, err
+	}
+	return lookup, nil
+}
+
+func (l *LotusClient) StateReplay(tipSet []Cid, cid string) (*InvocResult, error) {
+	result := new(InvocResult)
+	if err := l.call(result, "StateReplay", tipSet, Cid{Root: cid}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (l *LotusClient) ChainGetMessage(cid string) (*MessageJson, error) {
+	msg := new(MessageJson)
+	if err := l.call(msg, "ChainGetMessage", Cid{Root: cid}); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (l *LotusClient) ChainHead() (*TipSet, error) {
+	tipSet := new(TipSet)
+	if err := l.call(tipSet, "ChainHead"); err != nil {
+		return nil, err
+	}
+	return tipSet, nil
+}
+
+func (l *LotusClient) ChainGetTipSetByHeight(height int64) (*TipSet, error) {
+	tipSet := new(TipSet)
+	if err := l.call(tipSet, "ChainGetTipSetByHeight", height, nil); err != nil {
+		return nil, err
+	}
+	return tipSet, nil
+}
+
+func (l *LotusClient) ChainGetBlock(cid string) (*BlockHeader, error) {
+	header := new(BlockHeader)
+	if err := l.call(header, "ChainGetBlock", Cid{Root: cid}); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func (l *LotusClient) ChainGetBlockMessages(cid string) (*BlockMessages, error) {
+	messages := new(BlockMessages)
+	if err := l.call(messages, "ChainGetBlockMessages", Cid{Root: cid}); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
@@ -0,0 +1,284 @@
+package filecoin
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// 签名类型，secp256k1 签名为 65 字节 r || s || v
+const sigTypeSecp256k1 byte = 1
+
+// CIDv1 dag-cbor 的前缀：版本 1、编码 0x71、blake2b-256 多哈希（0xb220）和长度 32
+var cidPrefix = []byte{0x01, 0x71, 0xa0, 0xe4, 0x02, 0x20}
+
+// 链上消息，金额单位 attoFIL（1 FIL = 10^18 attoFIL）
+type Message struct {
+	Version    uint64
+	To         Address
+	From       Address
+	Nonce      uint64
+	Value      *big.Int
+	GasLimit   int64
+	GasFeeCap  *big.Int
+	GasPremium *big.Int
+	Method     uint64
+	Params     []byte
+}
+
+type SignedMessage struct {
+	Message   Message
+	Signature []byte
+}
+
+// CBOR 主类型
+const (
+	majorUint  byte = 0
+	majorNeg   byte = 1
+	majorBytes byte = 2
+	majorArray byte = 4
+)
+
+func appendHeader(buf []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= 0xff:
+		return append(buf, major|24, byte(n))
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major|27), n)
+	}
+}
+
+func appendBytes(buf []byte, data []byte) []byte {
+	return append(appendHeader(buf, majorBytes, uint64(len(data))), data...)
+}
+
+func appendInt(buf []byte, v int64) []byte {
+	if v < 0 {
+		return appendHeader(buf, majorNeg, uint64(-1-v))
+	}
+	return appendHeader(buf, majorUint, uint64(v))
+}
+
+// 大整数编码为字节串：0 为空，否则为符号字节（0 正 1 负）加大端绝对值
+func bigIntBytes(v *big.Int) []byte {
+	if v == nil || v.Sign() == 0 {
+		return []byte{}
+	}
+	sign := byte(0)
+	if v.Sign() < 0 {
+		sign = 1
+	}
+	return append([]byte{sign}, v.Bytes()...)
+}
+
+func bigIntFromBytes(data []byte) (*big.Int, error) {
+	if len(data) == 0 {
+		return new(big.Int), nil
+	}
+	v := new(big.Int).SetBytes(data[1:])
+	switch data[0] {
+	case 0:
+	case 1:
+		v.Neg(v)
+	default:
+		return nil, errors.New("invalid big int sign")
+	}
+	return v, nil
+}
+
+func (m *Message) MarshalCBOR() []byte {
+	buf := appendHeader(nil, majorArray, 10)
+	buf = appendHeader(buf, majorUint, m.Version)
+	buf = appendBytes(buf, m.To)
+	buf = appendBytes(buf, m.From)
+	buf = appendHeader(buf, majorUint, m.Nonce)
+	buf = appendBytes(buf, bigIntBytes(m.Value))
+	buf = appendInt(buf, m.GasLimit)
+	buf = appendBytes(buf, bigIntBytes(m.GasFeeCap))
+	buf = appendBytes(buf, bigIntBytes(m.GasPremium))
+	buf = appendHeader(buf, majorUint, m.Method)
+	return appendBytes(buf, m.Params)
+}
+
+// 消息 CID 的字节形式
+func (m *Message) Cid() []byte {
+	return cidOf(m.MarshalCBOR())
+}
+
+// 签名的消息为 CID 字节的 blake2b-256
+func (m *Message) SigningHash() []byte {
+	hash := blake2b.Sum256(m.Cid())
+	return hash[:]
+}
+
+// 签名字段为签名类型加签名数据
+func (m *SignedMessage) MarshalCBOR() []byte {
+	buf := appendHeader(nil, majorArray, 2)
+	buf = append(buf, m.Message.MarshalCBOR()...)
+	return appendBytes(buf, append([]byte{sigTypeSecp256k1}, m.Signature...))
+}
+
+// secp256k1 签名消息上链后的 CID 为签名消息的 CID
+func (m *SignedMessage) Cid() []byte {
+	return cidOf(m.MarshalCBOR())
+}
+
+func cidOf(data []byte) []byte {
+	hash := blake2b.Sum256(data)
+	return append(append([]byte{}, cidPrefix...), hash[:]...)
+}
+
+// CID 字符串为 multibase 前缀 b 加小写 base32
+func EncodeCid(cid []byte) string {
+	return "b" + addressEncoding.EncodeToString(cid)
+}
+
+type cborReader struct {
+	data []byte
+	err  error
+}
+
+var errUnexpectedEnd = errors.New("unexpected end of cbor data")
+
+func (r *cborReader) header(major byte) uint64 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data) == 0 {
+		r.err = errUnexpectedEnd
+		return 0
+	}
+	first := r.data[0]
+	if first>>5 != major {
+		r.err = errors.New("unexpected cbor type")
+		return 0
+	}
+	info := first & 0x1f
+	r.data = r.data[1:]
+	if info < 24 {
+		return uint64(info)
+	}
+	if info > 27 {
+		r.err = errors.New("unsupported cbor length")
+		return 0
+	}
+	size := 1 << (info - 24)
+	if len(r.data) < size {
+		r.err = errUnexpectedEnd
+		return 0
+	}
+	var n uint64
+	for _, b := range r.data[:size] {
+		n = n<<8 | uint64(b)
+	}
+	r.data = r.data[size:]
+	return n
+}
+
+func (r *cborReader) uint() uint64 {
+	return r.header(majorUint)
+}
+
+func (r *cborReader) int() int64 {
+	if r.err == nil && len(r.data) > 0 && r.data[0]>>5 == majorNeg {
+		return -1 - int64(r.header(majorNeg))
+	}
+	return int64(r.header(majorUint))
+}
+
+func (r *cborReader) bytes() []byte {
+	n := r.header(majorBytes)
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.data)) < n {
+		r.err = errUnexpectedEnd
+		return nil
+	}
+	data := r.data[:n]
+	r.data = r.data[n:]
+	return data
+}
+
+func (r *cborReader) bigInt() *big.Int {
+	data := r.bytes()
+	if r.err != nil {
+		return nil
+	}
+	v, err := bigIntFromBytes(data)
+	if err != nil {
+		r.err = err
+	}
+	return v
+}
+
+func (r *cborReader) address() Address {
+	data := r.bytes()
+	if r.err != nil {
+		return nil
+	}
+	addr, err := addressFromBytes(data)
+	if err != nil {
+		r.err = err
+	}
+	return addr
+}
+
+func (r *cborReader) message() Message {
+	var m Message
+	if n := r.header(majorArray); r.err == nil && n != 10 {
+		r.err = errors.New("invalid message length")
+	}
+	m.Version = r.uint()
+	m.To = r.address()
+	m.From = r.address()
+	m.Nonce = r.uint()
+	m.Value = r.bigInt()
+	m.GasLimit = r.int()
+	m.GasFeeCap = r.bigInt()
+	m.GasPremium = r.bigInt()
+	m.Method = r.uint()
+	m.Params = r.bytes()
+	return m
+}
+
+func UnmarshalMessage(data []byte) (*Message, error) {
+	r := &cborReader{data: data}
+	m := r.message()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.data) != 0 {
+		return nil, errors.New("trailing data after message")
+	}
+	return &m, nil
+}
+
+func UnmarshalSignedMessage(data []byte) (*SignedMessage, error) {
+	r := &cborReader{data: data}
+	if n := r.header(majorArray); r.err == nil && n != 2 {
+		r.err = errors.New("invalid signed message length")
+	}
+	m := &SignedMessage{Message: r.message()}
+	signature := r.bytes()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(signature) != 66 || signature[0] != sigTypeSecp256k1 {
+		return nil, errors.New("unsupported signature")
+	}
+	if len(r.data) != 0 {
+		return nil, errors.New("trailing data after message")
+	}
+	m.Signature = signature[1:]
+	return m, nil
+}
@@ -0,0 +1,193 @@
+package filecoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 转账使用的方法号 Send
+const methodSend = 0
+
+// 构建未签名交易：un_sign_tx 为 base64 编码的 CBOR 消息，sign_hashes 为消息 CID 的 blake2b-256
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx FilTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	msg, err := c.buildMessage(&transferTx)
+	if err != nil {
+		log.Error("build message fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   base64.StdEncoding.EncodeToString(msg.MarshalCBOR()),
+		SignHashes: []string{hex.EncodeToString(msg.SigningHash())},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 un_sign_tx，signature 为 65 字节 r || s || v。
+// 返回的 signed_tx 为 base64 编码的 CBOR 签名消息，msg 为消息 CID
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	msgBytes, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	var msg *Message
+	if err == nil {
+		msg, err = UnmarshalMessage(msgBytes)
+	}
+	if err != nil {
+		log.Error("decode message fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	signature = bytes.Clone(signature)
+	if signature[64] >= 27 {
+		signature[64] -= 27
+	}
+
+	// 校验签名地址
+	pubKey, err := crypto.SigToPub(msg.SigningHash(), signature)
+	var signer Address
+	if err == nil {
+		signer, err = NewSecp256k1Address(crypto.FromECDSAPub(pubKey))
+	}
+	if err != nil {
+		log.Error("recover public key fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	if !bytes.Equal(signer, msg.From) {
+		log.Error("sender mismatch", "expected", msg.From.String(c.testnet), "got", signer.String(c.testnet))
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "sender address mismatch",
+		}, nil
+	}
+	signedMsg := &SignedMessage{Message: *msg, Signature: signature}
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      EncodeCid(signedMsg.Cid()),
+		SignedTx: base64.StdEncoding.EncodeToString(signedMsg.MarshalCBOR()),
+	}, nil
+}
+
+// 构建 FIL 转账消息，未指定 nonce 和 gas 参数时通过节点获取
+func (c *ChainAdaptor) buildMessage(transferTx *FilTransferTx) (*Message, error) {
+	from, err := c.parseAddress(transferTx.FromAddress)
+	if err != nil {
+		return nil, errors.New("invalid from address")
+	}
+	if from.Protocol() != ProtocolSecp256k1 {
+		return nil, errors.New("from address must be f1 address")
+	}
+	to, err := c.parseAddress(transferTx.ToAddress)
+	if err != nil {
+		return nil, errors.New("invalid to address")
+	}
+	amount, ok := new(big.Int).SetString(transferTx.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+
+	msg := &Message{
+		To:     to,
+		From:   from,
+		Value:  amount,
+		Method: methodSend,
+	}
+	if transferTx.Nonce != nil {
+		msg.Nonce = *transferTx.Nonce
+	} else if msg.Nonce, err = c.FilClient.MpoolGetNonce(transferTx.FromAddress); err != nil {
+		return nil, err
+	}
+
+	if transferTx.GasLimit == 0 && transferTx.GasFeeCap == "" && transferTx.GasPremium == "" {
+		estimated, err := c.FilClient.GasEstimateMessageGas(c.messageJson(msg))
+		if err != nil {
+			return nil, err
+		}
+		transferTx.GasLimit, transferTx.GasFeeCap, transferTx.GasPremium = estimated.GasLimit, estimated.GasFeeCap, estimated.GasPremium
+	}
+	msg.GasLimit = transferTx.GasLimit
+	msg.GasFeeCap, ok = new(big.Int).SetString(transferTx.GasFeeCap, 10)
+	if !ok || msg.GasLimit <= 0 || msg.GasFeeCap.Sign() < 0 {
+		return nil, errors.New("invalid gas params")
+	}
+	msg.GasPremium, ok = new(big.Int).SetString(transferTx.GasPremium, 10)
+	if !ok || msg.GasPremium.Sign() < 0 || msg.GasPremium.Cmp(msg.GasFeeCap) > 0 {
+		return nil, errors.New("invalid gas premium")
+	}
+	return msg, nil
+}
+
+// 解析地址，网络前缀必须与节点一致
+func (c *ChainAdaptor) parseAddress(address string) (Address, error) {
+	addr, testnet, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if testnet != c.testnet {
+		return nil, errors.New("address network mismatch")
+	}
+	return addr, nil
+}
+
+// 转换为节点接口的消息格式
+func (c *ChainAdaptor) messageJson(msg *Message) *MessageJson {
+	return &MessageJson{
+		Version:    msg.Version,
+		To:         msg.To.String(c.testnet),
+		From:       msg.From.String(c.testnet),
+		Nonce:      msg.Nonce,
+		Value:      msg.Value.String(),
+		GasLimit:   msg.GasLimit,
+		GasFeeCap:  bigIntString(msg.GasFeeCap),
+		GasPremium: bigIntString(msg.GasPremium),
+		Method:     msg.Method,
+		Params:     msg.Params,
+	}
+}
+
+func bigIntString(v *big.Int) string {
+	if v == nil {
+		return "0"
+	}
+	return v.String()
+}
@@ -0,0 +1,16 @@
+package filecoin
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type FilTransferTx struct {
+	// 发送方必须为 f1 地址，f410 地址需通过 FEVM 发送以太坊交易
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	// 单位 attoFIL（10^18）
+	Amount string `json:"amount"`
+	// 为空时查询节点消息池中的下一个 nonce
+	Nonce *uint64 `json:"nonce"`
+	// gas 参数全部为空时通过 GasEstimateMessageGas 估算，否则必须全部指定
+	GasLimit   int64  `json:"gas_limit"`
+	GasFeeCap  string `json:"gas_fee_cap"`
+	GasPremium string `json:"gas_premium"`
+}
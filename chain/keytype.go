@@ -0,0 +1,23 @@
+package chain
+
+import "fmt"
+
+// ConvertAddressRequest.KeyType 支持的公钥类型
+const (
+	KeyTypeSecp256k1 = "secp256k1"
+	KeyTypeEd25519   = "ed25519"
+	KeyTypeSr25519   = "sr25519"
+)
+
+// 校验请求的公钥类型，为空时使用链的默认类型（supported 的第一个）
+func CheckKeyType(keyType string, supported ...string) (string, error) {
+	if keyType == "" {
+		return supported[0], nil
+	}
+	for _, item := range supported {
+		if item == keyType {
+			return keyType, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported key type: %s", keyType)
+}
@@ -0,0 +1,64 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+)
+
+// 账户名规则：2~64 个字符，由 . 分隔的小写字母数字段，段内可用 - 或 _ 连接
+var accountIdPattern = regexp.MustCompile(`^(([a-z\d]+[-_])*[a-z\d]+\.)*([a-z\d]+[-_])*[a-z\d]+$`)
+
+const (
+	minAccountIdLength = 2
+	maxAccountIdLength = 64
+)
+
+// 公钥的字符串前缀，borsh 编码中 ed25519 的类型为 0
+const (
+	ed25519Prefix  = "ed25519:"
+	keyTypeEd25519 = 0
+)
+
+func ValidateAccountId(accountId string) bool {
+	return len(accountId) >= minAccountIdLength && len(accountId) <= maxAccountIdLength && accountIdPattern.MatchString(accountId)
+}
+
+// 隐式账户为 ed25519 公钥的 64 位小写 hex，无需注册即可接收转账
+func IsImplicitAccount(accountId string) bool {
+	if len(accountId) != 2*ed25519.PublicKeySize {
+		return false
+	}
+	_, err := hex.DecodeString(accountId)
+	return err == nil && strings.ToLower(accountId) == accountId
+}
+
+func ImplicitAccountId(pubKey ed25519.PublicKey) string {
+	return hex.EncodeToString(pubKey)
+}
+
+// 解析公钥，支持 hex 和 ed25519:base58 两种格式
+func ParsePublicKey(publicKey string) (ed25519.PublicKey, error) {
+	var pubKey []byte
+	if encoded, ok := strings.CutPrefix(publicKey, ed25519Prefix); ok {
+		pubKey = base58.Decode(encoded)
+	} else {
+		var err error
+		if pubKey, err = hex.DecodeString(strings.TrimPrefix(publicKey, "0x")); err != nil {
+			return nil, err
+		}
+	}
+	if len(pubKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key length")
+	}
+	return pubKey, nil
+}
+
+// 节点接口使用的公钥格式 ed25519:base58
+func FormatPublicKey(pubKey ed25519.PublicKey) string {
+	return ed25519Prefix + base58.Encode(pubKey)
+}
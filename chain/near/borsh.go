@@ -0,0 +1,160 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"chain-account/common/bcs"
+)
+
+// borsh 的定长整数与 BCS 相同（小端），变长序列的长度为 u32 小端，枚举序号为 u8
+
+// 交易动作类型
+const (
+	actionFunctionCall byte = 2
+	actionTransfer     byte = 3
+)
+
+// 只支持 FunctionCall 和 Transfer，Deposit 单位 yoctoNEAR
+type Action struct {
+	Type       byte
+	MethodName string
+	Args       []byte
+	Gas        uint64
+	Deposit    *big.Int
+}
+
+type Transaction struct {
+	SignerId   string
+	PublicKey  ed25519.PublicKey
+	Nonce      uint64
+	ReceiverId string
+	BlockHash  [32]byte
+	Actions    []Action
+}
+
+type SignedTransaction struct {
+	Transaction Transaction
+	Signature   []byte
+}
+
+func appendU32(buf []byte, v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(buf, v)
+}
+
+func appendBytes(buf []byte, data []byte) []byte {
+	return append(appendU32(buf, uint32(len(data))), data...)
+}
+
+func appendString(buf []byte, s string) []byte {
+	return appendBytes(buf, []byte(s))
+}
+
+func (tx *Transaction) MarshalBorsh() []byte {
+	var buf []byte
+	buf = appendString(buf, tx.SignerId)
+	buf = append(buf, keyTypeEd25519)
+	buf = append(buf, tx.PublicKey...)
+	buf = bcs.AppendU64(buf, tx.Nonce)
+	buf = appendString(buf, tx.ReceiverId)
+	buf = append(buf, tx.BlockHash[:]...)
+	buf = appendU32(buf, uint32(len(tx.Actions)))
+	for _, action := range tx.Actions {
+		buf = append(buf, action.Type)
+		if action.Type == actionFunctionCall {
+			buf = appendString(buf, action.MethodName)
+			buf = appendBytes(buf, action.Args)
+			buf = bcs.AppendU64(buf, action.Gas)
+		}
+		buf = bcs.AppendU128(buf, action.Deposit)
+	}
+	return buf
+}
+
+// 交易哈希为 borsh 编码的 sha256，也是签名的消息
+func (tx *Transaction) Hash() []byte {
+	hash := sha256.Sum256(tx.MarshalBorsh())
+	return hash[:]
+}
+
+func (tx *SignedTransaction) MarshalBorsh() []byte {
+	buf := tx.Transaction.MarshalBorsh()
+	buf = append(buf, keyTypeEd25519)
+	return append(buf, tx.Signature...)
+}
+
+func readLen(d *bcs.Decoder) int {
+	b := d.FixedBytes(4)
+	if b == nil {
+		return 0
+	}
+	n := binary.LittleEndian.Uint32(b)
+	if uint64(n) > uint64(d.Len()) {
+		d.SetErr(bcs.ErrUnexpectedEnd)
+		return 0
+	}
+	return int(n)
+}
+
+func readString(d *bcs.Decoder) string {
+	return string(d.FixedBytes(readLen(d)))
+}
+
+func readTransaction(d *bcs.Decoder) Transaction {
+	var tx Transaction
+	tx.SignerId = readString(d)
+	if keyType := d.U8(); keyType != keyTypeEd25519 {
+		d.SetErr(fmt.Errorf("unsupported key type %d", keyType))
+	}
+	tx.PublicKey = d.FixedBytes(ed25519.PublicKeySize)
+	tx.Nonce = d.U64()
+	tx.ReceiverId = readString(d)
+	copy(tx.BlockHash[:], d.FixedBytes(32))
+	for i := readLen(d); i > 0 && d.Err() == nil; i-- {
+		action := Action{Type: d.U8()}
+		switch action.Type {
+		case actionFunctionCall:
+			action.MethodName = readString(d)
+			action.Args = d.FixedBytes(readLen(d))
+			action.Gas = d.U64()
+		case actionTransfer:
+		default:
+			d.SetErr(fmt.Errorf("unsupported action type %d", action.Type))
+		}
+		action.Deposit = d.U128()
+		tx.Actions = append(tx.Actions, action)
+	}
+	return tx
+}
+
+func UnmarshalTransaction(data []byte) (*Transaction, error) {
+	d := bcs.NewDecoder(data)
+	tx := readTransaction(d)
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	if d.Len() != 0 {
+		return nil, errors.New("trailing data after transaction")
+	}
+	return &tx, nil
+}
+
+func UnmarshalSignedTransaction(data []byte) (*SignedTransaction, error) {
+	d := bcs.NewDecoder(data)
+	tx := &SignedTransaction{Transaction: readTransaction(d)}
+	if keyType := d.U8(); keyType != keyTypeEd25519 {
+		d.SetErr(fmt.Errorf("unsupported signature type %d", keyType))
+	}
+	tx.Signature = d.FixedBytes(ed25519.SignatureSize)
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	if d.Len() != 0 {
+		return nil, errors.New("trailing data after transaction")
+	}
+	return tx, nil
+}
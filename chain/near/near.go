@@ -0,0 +1,527 @@
+package near
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Near"
+
+// 单次 GetBlockByRange 最多查询的区块数，每个区块需要一次请求
+const blockRangeLimit = 100
+
+// 一次 Transfer 消耗的 gas：action receipt 创建和 transfer 动作的发送、执行费用之和
+const transferGas = 2 * (108_059_500_000 + 115_123_062_500)
+
+type ChainAdaptor struct {
+	NearClient INear
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.Near
+	nearClient, err := NewNearClient(node.RpcUrl, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		NearClient: nearClient,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入 ed25519 公钥 转换成隐式账户（公钥的 hex），命名账户需要链上注册，不能由公钥生成
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeEd25519); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	pubKey, err := ParsePublicKey(req.PublicKey)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: ImplicitAccountId(pubKey),
+	}, nil
+}
+
+// 地址格式验证，支持隐式账户和命名账户
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ValidateAccountId(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 通过高度获取区块，height 为 0 时返回最新的最终确认区块。
+// 交易列表为区块 chunk 中的交易，执行结果在之后的区块产生，需要通过 GetTxByHash 确认
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	var blockId any
+	if req.Height > 0 {
+		blockId = uint64(req.Height)
+	}
+	block, err := c.NearClient.GetBlock(blockId)
+	if err != nil {
+		log.Error("get block fail", "height", req.Height, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return c.blockResponse(block, "get block by number")
+}
+
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	block, err := c.NearClient.GetBlock(req.Hash)
+	if err != nil {
+		log.Error("get block fail", "hash", req.Hash, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return c.blockResponse(block, "get block by hash")
+}
+
+// 只读取本区块产生的 chunk，分片缺块时沿用的旧 chunk 已在之前的区块统计
+func (c *ChainAdaptor) blockResponse(block *BlockView, action string) (*account.BlockResponse, error) {
+	var blockTxList []*account.BlockInfoTransactionList
+	for _, chunkHeader := range block.Chunks {
+		if chunkHeader.HeightIncluded != block.Header.Height {
+			continue
+		}
+		chunk, err := c.NearClient.GetChunk(chunkHeader.ChunkHash)
+		if err != nil {
+			log.Error("get chunk fail", "hash", chunkHeader.ChunkHash, "err", err)
+			return &account.BlockResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  action + " fail",
+			}, nil
+		}
+		for i := range chunk.Transactions {
+			for _, transfer := range parseTransfers(&chunk.Transactions[i]) {
+				blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+					From:         transfer.From,
+					To:           transfer.To,
+					TokenAddress: transfer.ContractAddress,
+					Hash:         chunk.Transactions[i].Hash,
+					Height:       block.Header.Height,
+					Amount:       transfer.Amount,
+				})
+			}
+		}
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          action + " success",
+		Height:       int64(block.Header.Height),
+		Hash:         block.Header.Hash,
+		Transactions: blockTxList,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	block, err := c.NearClient.GetBlock(req.Hash)
+	if err != nil {
+		log.Error("get block fail", "hash", req.Hash, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by hash success",
+		BlockHeader: toBlockHeader(block),
+	}, nil
+}
+
+// 通过高度获取区块头信息，height 为 0 时返回最新的最终确认区块
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	var blockId any
+	if req.Height > 0 {
+		blockId = uint64(req.Height)
+	}
+	block, err := c.NearClient.GetBlock(blockId)
+	if err != nil {
+		log.Error("get block fail", "height", req.Height, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(block),
+	}, nil
+}
+
+// 获取区间内的区块头，NEAR 的高度不连续，跳过的高度不返回
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseUint(req.Start, 10, 64)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseUint(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	if end-start >= blockRangeLimit {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "block range too large",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for height := start; height <= end; height++ {
+		block, err := c.NearClient.GetBlock(height)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			log.Error("get block fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		headers = append(headers, toBlockHeader(block))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取余额（yoctoNEAR，不含质押锁定部分），contract_address 为 NEP-141 合约时查询代币余额，
+// 不存在的账户返回 0
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if !ValidateAccountId(req.Address) {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	var balance string
+	var err error
+	if req.ContractAddress != "" {
+		balance, err = c.ftBalanceOf(req.ContractAddress, req.Address)
+	} else {
+		var view *AccountView
+		if view, err = c.NearClient.ViewAccount(req.Address); err == nil {
+			balance = view.Amount
+		}
+	}
+	if IsNotFound(err) {
+		balance, err = "0", nil
+	}
+	if err != nil {
+		log.Error("get account fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Balance:       balance,
+	}, nil
+}
+
+// 获取fee，单位 yoctoNEAR。
+// 传入 rawTx（BuildUnSignTransaction 返回的 un_sign_tx）时返回按当前 gas 价格计算的 gas 上限费用；
+// 否则返回一次 NEAR 转账的费用。NEAR 没有优先费，三档相同
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	gas := new(big.Int).SetUint64(transferGas)
+	if req.RawTx != "" {
+		txBytes, err := base64.StdEncoding.DecodeString(req.RawTx)
+		var tx *Transaction
+		if err == nil {
+			tx, err = UnmarshalTransaction(txBytes)
+		}
+		if err != nil {
+			log.Error("decode transaction fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		gas.SetUint64(0)
+		for _, action := range tx.Actions {
+			if action.Type == actionFunctionCall {
+				gas.Add(gas, new(big.Int).SetUint64(action.Gas))
+			} else {
+				gas.Add(gas, new(big.Int).SetUint64(transferGas))
+			}
+		}
+	}
+	gasPrice, err := c.NearClient.GasPrice()
+	price, ok := new(big.Int).SetString(gasPrice, 10)
+	if err != nil || !ok {
+		log.Error("get gas price fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get gas price fail",
+		}, nil
+	}
+	fee := new(big.Int).Mul(gas, price).String()
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee,
+		NormalFee: fee,
+		FastFee:   fee,
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的 signed_tx，不等待执行结果
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	signedTx, err := base64.StdEncoding.DecodeString(req.RawTx)
+	if err == nil {
+		_, err = UnmarshalSignedTransaction(signedTx)
+	}
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	hash, err := c.NearClient.BroadcastTxAsync(signedTx)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: hash,
+	}, nil
+}
+
+// NEAR 节点不按账户建立交易索引
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get tx by address is not supported",
+	}, nil
+}
+
+// 通过交易哈希获取交易，节点需要发送方账户确定分片，hash 格式为 <交易哈希>:<发送方账户>。
+// 交易及其全部 receipt 执行成功时为成功，fee 为全部 receipt 燃烧的 NEAR
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	hash, senderId, ok := strings.Cut(req.Hash, ":")
+	if !ok || !ValidateAccountId(senderId) {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "hash must be in the form <tx_hash>:<sender_id>",
+		}, nil
+	}
+	status, err := c.NearClient.GetTransaction(hash, senderId)
+	if IsNotFound(err) {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get transaction fail", "hash", hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	txMessage := &account.TxMessage{
+		Hash:   hash,
+		From:   status.Transaction.SignerId,
+		To:     status.Transaction.ReceiverId,
+		Status: txStatus(status),
+	}
+	fee := new(big.Int)
+	for _, outcome := range append([]ExecutionOutcome{status.TransactionOutcome}, status.ReceiptsOutcome...) {
+		if burnt, ok := new(big.Int).SetString(outcome.Outcome.TokensBurnt, 10); ok {
+			fee.Add(fee, burnt)
+		}
+	}
+	txMessage.Fee = fee.String()
+	if block, err := c.NearClient.GetBlock(status.TransactionOutcome.BlockHash); err == nil {
+		txMessage.Height = strconv.FormatUint(block.Header.Height, 10)
+		txMessage.Datetime = strconv.FormatUint(block.Header.Timestamp/uint64(time.Second), 10)
+	}
+	transfers := parseTransfers(&status.Transaction)
+	if len(transfers) > 0 {
+		transfer := transfers[0]
+		txMessage.To = transfer.To
+		txMessage.Value = transfer.Amount
+		txMessage.ContractAddress = transfer.ContractAddress
+		if transfer.ContractAddress != "" {
+			txMessage.Type = 1
+		}
+		data, _ := json.Marshal(transfers)
+		txMessage.Data = string(data)
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   txMessage,
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// ft_balance_of 返回 JSON 字符串形式的余额
+func (c *ChainAdaptor) ftBalanceOf(contractId string, accountId string) (string, error) {
+	args, _ := json.Marshal(map[string]string{"account_id": accountId})
+	result, err := c.NearClient.CallFunction(contractId, "ft_balance_of", args)
+	if err != nil {
+		return "", err
+	}
+	var balance string
+	if err := json.Unmarshal(result, &balance); err != nil {
+		return "", err
+	}
+	return balance, nil
+}
+
+// 解析交易中的 Transfer 动作和 NEP-141 的 ft_transfer、ft_transfer_call 调用
+func parseTransfers(tx *TransactionView) []Transfer {
+	var transfers []Transfer
+	for _, raw := range tx.Actions {
+		var action struct {
+			Transfer *struct {
+				Deposit string `json:"deposit"`
+			} `json:"Transfer"`
+			FunctionCall *struct {
+				MethodName string `json:"method_name"`
+				Args       string `json:"args"`
+			} `json:"FunctionCall"`
+		}
+		// 无参数的动作为字符串，如 "CreateAccount"
+		if json.Unmarshal(raw, &action) != nil {
+			continue
+		}
+		switch {
+		case action.Transfer != nil:
+			transfers = append(transfers, Transfer{From: tx.SignerId, To: tx.ReceiverId, Amount: action.Transfer.Deposit})
+		case action.FunctionCall != nil:
+			if action.FunctionCall.MethodName != "ft_transfer" && action.FunctionCall.MethodName != "ft_transfer_call" {
+				continue
+			}
+			argsJson, err := base64.StdEncoding.DecodeString(action.FunctionCall.Args)
+			if err != nil {
+				continue
+			}
+			var args struct {
+				ReceiverId string `json:"receiver_id"`
+				Amount     string `json:"amount"`
+			}
+			if json.Unmarshal(argsJson, &args) != nil || args.ReceiverId == "" {
+				continue
+			}
+			transfers = append(transfers, Transfer{ContractAddress: tx.ReceiverId, From: tx.SignerId, To: args.ReceiverId, Amount: args.Amount})
+		}
+	}
+	return transfers
+}
+
+// 最终状态为 SuccessValue 时成功，Failure 时失败，其他为执行中
+func txStatus(status *TxStatus) account.TxStatus {
+	var finalStatus struct {
+		SuccessValue *string        `json:"SuccessValue"`
+		Failure      map[string]any `json:"Failure"`
+	}
+	if json.Unmarshal(status.Status, &finalStatus) != nil {
+		return account.TxStatus_Pending
+	}
+	switch {
+	case finalStatus.Failure != nil:
+		return account.TxStatus_Failed
+	case finalStatus.SuccessValue != nil:
+		return account.TxStatus_Success
+	default:
+		return account.TxStatus_Pending
+	}
+}
+
+func toBlockHeader(block *BlockView) *account.BlockHeader {
+	return &account.BlockHeader{
+		Hash:       block.Header.Hash,
+		ParentHash: block.Header.PrevHash,
+		Number:     strconv.FormatUint(block.Header.Height, 10),
+		Time:       block.Header.Timestamp / uint64(time.Second),
+	}
+}
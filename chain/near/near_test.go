@@ -0,0 +1,317 @@
+package near
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+const (
+	testContract = "usdt.tether-token.near"
+	testReceiver = "bob.near"
+)
+
+var testBlockHash = base58.Encode(bytes.Repeat([]byte{7}, 32))
+
+// 模拟 NEAR 节点，registered 为已在代币合约注册的账户
+type fakeNear struct {
+	INear
+	registered map[string]bool
+	chunks     map[string]*ChunkView
+	broadcast  []byte
+}
+
+func (f *fakeNear) ViewAccount(accountId string) (*AccountView, error) {
+	if accountId == testReceiver {
+		return &AccountView{Amount: "1000000000000000000000000"}, nil
+	}
+	return nil, &RpcError{Code: -32000, Cause: struct {
+		Name string `json:"name"`
+	}{Name: "UNKNOWN_ACCOUNT"}}
+}
+
+func (f *fakeNear) ViewAccessKey(accountId string, publicKey string) (*AccessKeyView, error) {
+	return &AccessKeyView{Nonce: 41, Permission: json.RawMessage(`"FullAccess"`)}, nil
+}
+
+func (f *fakeNear) CallFunction(contractId string, methodName string, args []byte) ([]byte, error) {
+	var params struct {
+		AccountId string `json:"account_id"`
+	}
+	_ = json.Unmarshal(args, &params)
+	switch methodName {
+	case "storage_balance_of":
+		if f.registered[params.AccountId] {
+			return []byte(`{"total":"1250000000000000000000","available":"0"}`), nil
+		}
+		return []byte("null"), nil
+	case "storage_balance_bounds":
+		return []byte(`{"min":"1250000000000000000000","max":"1250000000000000000000"}`), nil
+	case "ft_balance_of":
+		return []byte(`"123456"`), nil
+	}
+	return nil, &RpcError{Message: "method not found"}
+}
+
+func (f *fakeNear) GetBlock(blockId any) (*BlockView, error) {
+	block := new(BlockView)
+	block.Header.Height = 100
+	block.Header.Hash = testBlockHash
+	block.Header.PrevHash = "parent"
+	block.Header.Timestamp = 1700000000123456789
+	block.Chunks = append(block.Chunks, struct {
+		ChunkHash      string `json:"chunk_hash"`
+		HeightIncluded uint64 `json:"height_included"`
+		ShardId        uint64 `json:"shard_id"`
+	}{ChunkHash: "c0", HeightIncluded: 100}, struct {
+		ChunkHash      string `json:"chunk_hash"`
+		HeightIncluded uint64 `json:"height_included"`
+		ShardId        uint64 `json:"shard_id"`
+	}{ChunkHash: "old", HeightIncluded: 99, ShardId: 1})
+	return block, nil
+}
+
+func (f *fakeNear) GetChunk(chunkHash string) (*ChunkView, error) {
+	return f.chunks[chunkHash], nil
+}
+
+func (f *fakeNear) GasPrice() (string, error) {
+	return "100000000", nil
+}
+
+func (f *fakeNear) BroadcastTxAsync(signedTx []byte) (string, error) {
+	f.broadcast = signedTx
+	return "hash", nil
+}
+
+func testKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+}
+
+func buildUnSign(t *testing.T, adaptor *ChainAdaptor, transferTx NearTransferTx) *account.UnSignTransactionResponse {
+	txJson, _ := json.Marshal(transferTx)
+	resp, err := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	return resp
+}
+
+func decodeUnSign(t *testing.T, resp *account.UnSignTransactionResponse) *Transaction {
+	txBytes, _ := base64.StdEncoding.DecodeString(resp.UnSignTx)
+	tx, err := UnmarshalTransaction(txBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func Test_AccountId(t *testing.T) {
+	valid := []string{"bob.near", "a-b_c.testnet", "aa", "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}
+	for _, accountId := range valid {
+		if !ValidateAccountId(accountId) {
+			t.Errorf("%s should be valid", accountId)
+		}
+	}
+	invalid := []string{"a", "Bob.near", "bob..near", "-bob.near", "bob_.near", "bob.near.", "a@b"}
+	for _, accountId := range invalid {
+		if ValidateAccountId(accountId) {
+			t.Errorf("%s should be invalid", accountId)
+		}
+	}
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	adaptor := &ChainAdaptor{}
+	pubKey := testKey().Public().(ed25519.PublicKey)
+	for _, publicKey := range []string{hex.EncodeToString(pubKey), FormatPublicKey(pubKey)} {
+		resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: publicKey})
+		if resp.Code != global_const.ReturnCode_SUCCESS || resp.Address != hex.EncodeToString(pubKey) {
+			t.Fatalf("unexpected address %s: %s", resp.Address, resp.Msg)
+		}
+		if !IsImplicitAccount(resp.Address) {
+			t.Fatal("address should be implicit account")
+		}
+	}
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{KeyType: chain.KeyTypeSecp256k1, PublicKey: hex.EncodeToString(pubKey)})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("secp256k1 key should be rejected")
+	}
+}
+
+func Test_BorshRoundTrip(t *testing.T) {
+	tx := &Transaction{
+		SignerId:   "alice.near",
+		PublicKey:  testKey().Public().(ed25519.PublicKey),
+		Nonce:      42,
+		ReceiverId: testContract,
+		Actions: []Action{
+			{Type: actionFunctionCall, MethodName: "ft_transfer", Args: []byte(`{}`), Gas: ftTransferGas, Deposit: oneYocto},
+			{Type: actionTransfer, Deposit: big.NewInt(1000)},
+		},
+	}
+	tx.BlockHash[0] = 9
+	decoded, err := UnmarshalTransaction(tx.MarshalBorsh())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.MarshalBorsh(), tx.MarshalBorsh()) {
+		t.Fatal("round trip mismatch")
+	}
+	if _, err := UnmarshalTransaction(append(tx.MarshalBorsh(), 0)); err == nil {
+		t.Fatal("trailing data should be rejected")
+	}
+	if _, err := UnmarshalTransaction(tx.MarshalBorsh()[:40]); err == nil {
+		t.Fatal("truncated data should be rejected")
+	}
+}
+
+func Test_BuildTransfer(t *testing.T) {
+	fake := &fakeNear{}
+	adaptor := &ChainAdaptor{NearClient: fake}
+	key := testKey()
+	from := ImplicitAccountId(key.Public().(ed25519.PublicKey))
+
+	resp := buildUnSign(t, adaptor, NearTransferTx{FromAddress: from, ToAddress: testReceiver, Amount: "1000"})
+	tx := decodeUnSign(t, resp)
+	if tx.Nonce != 42 || tx.ReceiverId != testReceiver || base58.Encode(tx.BlockHash[:]) != testBlockHash {
+		t.Fatalf("unexpected transaction %+v", tx)
+	}
+	if len(tx.Actions) != 1 || tx.Actions[0].Type != actionTransfer || tx.Actions[0].Deposit.String() != "1000" {
+		t.Fatalf("unexpected actions %+v", tx.Actions)
+	}
+	if resp.SignHashes[0] != hex.EncodeToString(tx.Hash()) {
+		t.Fatal("sign hash mismatch")
+	}
+
+	// 签名并广播
+	signature := ed25519.Sign(key, tx.Hash())
+	signedResp, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  resp.UnSignTx,
+		Signature: hex.EncodeToString(signature),
+	})
+	if signedResp.Code != global_const.ReturnCode_SUCCESS || signedResp.Msg != base58.Encode(tx.Hash()) {
+		t.Fatalf("build signed transaction fail: %s", signedResp.Msg)
+	}
+	sendResp, _ := adaptor.SendTx(&account.SendTxRequest{RawTx: signedResp.SignedTx})
+	if sendResp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("send tx fail: %s", sendResp.Msg)
+	}
+	signedTx, err := UnmarshalSignedTransaction(fake.broadcast)
+	if err != nil || !bytes.Equal(signedTx.Signature, signature) {
+		t.Fatal("broadcast transaction mismatch")
+	}
+
+	signature[0] ^= 0xff
+	signedResp, _ = adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  resp.UnSignTx,
+		Signature: hex.EncodeToString(signature),
+	})
+	if signedResp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("invalid signature should be rejected")
+	}
+}
+
+func Test_BuildTokenTransfer(t *testing.T) {
+	adaptor := &ChainAdaptor{NearClient: &fakeNear{registered: map[string]bool{"carol.near": true}}}
+	pubKey := FormatPublicKey(testKey().Public().(ed25519.PublicKey))
+
+	// 未注册的收款方先 storage_deposit
+	tx := decodeUnSign(t, buildUnSign(t, adaptor, NearTransferTx{
+		FromAddress: "alice.near", ToAddress: testReceiver, Amount: "500", ContractAddress: testContract,
+		PublicKey: pubKey, Nonce: 7, BlockHash: testBlockHash,
+	}))
+	if tx.ReceiverId != testContract || tx.Nonce != 7 || len(tx.Actions) != 2 {
+		t.Fatalf("unexpected transaction %+v", tx)
+	}
+	if tx.Actions[0].MethodName != "storage_deposit" || tx.Actions[0].Deposit.String() != "1250000000000000000000" {
+		t.Fatalf("unexpected storage deposit %+v", tx.Actions[0])
+	}
+	transfer := tx.Actions[1]
+	if transfer.MethodName != "ft_transfer" || transfer.Deposit.Cmp(oneYocto) != 0 || string(transfer.Args) != `{"amount":"500","receiver_id":"bob.near"}` {
+		t.Fatalf("unexpected ft_transfer %+v", transfer)
+	}
+
+	tx = decodeUnSign(t, buildUnSign(t, adaptor, NearTransferTx{
+		FromAddress: "alice.near", ToAddress: "carol.near", Amount: "500", ContractAddress: testContract, PublicKey: pubKey,
+	}))
+	if len(tx.Actions) != 1 || tx.Actions[0].MethodName != "ft_transfer" {
+		t.Fatalf("registered receiver should not deposit storage %+v", tx.Actions)
+	}
+
+	// 命名账户必须提供公钥
+	txJson, _ := json.Marshal(NearTransferTx{FromAddress: "alice.near", ToAddress: testReceiver, Amount: "1"})
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("missing public key should be rejected")
+	}
+}
+
+func Test_GetAccount(t *testing.T) {
+	adaptor := &ChainAdaptor{NearClient: &fakeNear{}}
+	resp, _ := adaptor.GetAccount(&account.AccountRequest{Address: testReceiver})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Balance != "1000000000000000000000000" {
+		t.Fatalf("unexpected balance %s: %s", resp.Balance, resp.Msg)
+	}
+	resp, _ = adaptor.GetAccount(&account.AccountRequest{Address: "nobody.near"})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Balance != "0" {
+		t.Fatalf("unknown account should have zero balance, got %s", resp.Balance)
+	}
+	resp, _ = adaptor.GetAccount(&account.AccountRequest{Address: testReceiver, ContractAddress: testContract})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Balance != "123456" {
+		t.Fatalf("unexpected token balance %s", resp.Balance)
+	}
+}
+
+func Test_GetBlockByNumber(t *testing.T) {
+	ftArgs := base64.StdEncoding.EncodeToString([]byte(`{"receiver_id":"bob.near","amount":"500"}`))
+	adaptor := &ChainAdaptor{NearClient: &fakeNear{chunks: map[string]*ChunkView{
+		"c0": {Transactions: []TransactionView{
+			{Hash: "t1", SignerId: "alice.near", ReceiverId: testReceiver, Actions: []json.RawMessage{
+				json.RawMessage(`"CreateAccount"`),
+				json.RawMessage(`{"Transfer":{"deposit":"1000"}}`),
+			}},
+			{Hash: "t2", SignerId: "alice.near", ReceiverId: testContract, Actions: []json.RawMessage{
+				json.RawMessage(`{"FunctionCall":{"method_name":"ft_transfer","args":"` + ftArgs + `","gas":30000000000000,"deposit":"1"}}`),
+				json.RawMessage(`{"FunctionCall":{"method_name":"ft_metadata","args":"e30=","gas":1,"deposit":"0"}}`),
+			}},
+		}},
+	}}}
+	resp, _ := adaptor.GetBlockByNumber(&account.BlockNumberRequest{Height: 100})
+	if resp.Code != global_const.ReturnCode_SUCCESS || len(resp.Transactions) != 2 {
+		t.Fatalf("unexpected block %+v", resp)
+	}
+	if tx := resp.Transactions[0]; tx.To != testReceiver || tx.Amount != "1000" || tx.TokenAddress != "" {
+		t.Fatalf("unexpected transfer %+v", tx)
+	}
+	if tx := resp.Transactions[1]; tx.To != testReceiver || tx.Amount != "500" || tx.TokenAddress != testContract {
+		t.Fatalf("unexpected token transfer %+v", tx)
+	}
+
+	header, _ := adaptor.GetBlockHeaderByNumber(&account.BlockHeaderNumberRequest{Height: 100})
+	if header.BlockHeader.Time != 1700000000 || header.BlockHeader.Number != "100" {
+		t.Fatalf("unexpected header %+v", header.BlockHeader)
+	}
+}
+
+func Test_GetFee(t *testing.T) {
+	adaptor := &ChainAdaptor{NearClient: &fakeNear{}}
+	resp, _ := adaptor.GetFee(&account.FeeRequest{})
+	expected := new(big.Int).Mul(big.NewInt(transferGas), big.NewInt(100000000)).String()
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.NormalFee != expected {
+		t.Fatalf("unexpected fee %s", resp.NormalFee)
+	}
+}
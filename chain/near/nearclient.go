@@ -0,0 +1,299 @@
+package near
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// NEAR JSON-RPC 错误，cause.name 为具体原因，如 UNKNOWN_ACCOUNT
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Name    string `json:"name"`
+	Cause   struct {
+		Name string `json:"name"`
+	} `json:"cause"`
+	Data json.RawMessage `json:"data"`
+}
+
+func (e *RpcError) Error() string {
+	if e.Cause.Name != "" {
+		return fmt.Sprintf("rpc error %d: %s %s", e.Code, e.Cause.Name, string(e.Data))
+	}
+	return fmt.Sprintf("rpc error %d: %s %s", e.Code, e.Message, string(e.Data))
+}
+
+// 账户、访问密钥、交易、区块或 chunk 不存在，旧版本节点只返回 does not exist 的错误信息
+func IsNotFound(err error) bool {
+	var rpcErr *RpcError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	switch rpcErr.Cause.Name {
+	case "UNKNOWN_ACCOUNT", "UNKNOWN_ACCESS_KEY", "UNKNOWN_TRANSACTION", "UNKNOWN_BLOCK", "UNKNOWN_CHUNK":
+		return true
+	}
+	return strings.Contains(rpcErr.Message, "does not exist")
+}
+
+// 金额单位 yoctoNEAR（1 NEAR = 10^24 yoctoNEAR）
+type AccountView struct {
+	Amount       string `json:"amount"`
+	Locked       string `json:"locked"`
+	StorageUsage uint64 `json:"storage_usage"`
+	BlockHeight  uint64 `json:"block_height"`
+	BlockHash    string `json:"block_hash"`
+}
+
+// permission 为 "FullAccess" 或 {"FunctionCall": {...}}
+type AccessKeyView struct {
+	Nonce       uint64          `json:"nonce"`
+	Permission  json.RawMessage `json:"permission"`
+	BlockHeight uint64          `json:"block_height"`
+	BlockHash   string          `json:"block_hash"`
+}
+
+type BlockView struct {
+	Author string `json:"author"`
+	Header struct {
+		Height    uint64 `json:"height"`
+		Hash      string `json:"hash"`
+		PrevHash  string `json:"prev_hash"`
+		Timestamp uint64 `json:"timestamp"` // 纳秒
+		GasPrice  string `json:"gas_price"`
+	} `json:"header"`
+	Chunks []struct {
+		ChunkHash      string `json:"chunk_hash"`
+		HeightIncluded uint64 `json:"height_included"`
+		ShardId        uint64 `json:"shard_id"`
+	} `json:"chunks"`
+}
+
+// actions 的元素为 "CreateAccount" 或 {"Transfer": {...}}、{"FunctionCall": {...}} 等
+type TransactionView struct {
+	SignerId   string            `json:"signer_id"`
+	PublicKey  string            `json:"public_key"`
+	Nonce      uint64            `json:"nonce"`
+	ReceiverId string            `json:"receiver_id"`
+	Actions    []json.RawMessage `json:"actions"`
+	Hash       string            `json:"hash"`
+}
+
+type ChunkView struct {
+	Header struct {
+		ChunkHash string `json:"chunk_hash"`
+		ShardId   uint64 `json:"shard_id"`
+	} `json:"header"`
+	Transactions []TransactionView `json:"transactions"`
+}
+
+type ExecutionOutcome struct {
+	BlockHash string `json:"block_hash"`
+	Outcome   struct {
+		TokensBurnt string          `json:"tokens_burnt"`
+		Status      json.RawMessage `json:"status"`
+	} `json:"outcome"`
+}
+
+// status 为 {"SuccessValue": ...}、{"Failure": ...}、"NotStarted" 或 "Started"
+type TxStatus struct {
+	Status             json.RawMessage    `json:"status"`
+	Transaction        TransactionView    `json:"transaction"`
+	TransactionOutcome ExecutionOutcome   `json:"transaction_outcome"`
+	ReceiptsOutcome    []ExecutionOutcome `json:"receipts_outcome"`
+}
+
+type rpcRequest struct {
+	JsonRpc string `json:"jsonrpc"`
+	Id      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RpcError       `json:"error"`
+}
+
+// 定义 NEAR 节点接口
+type INear interface {
+	ViewAccount(accountId string) (*AccountView, error)
+	// publicKey 为 ed25519:base58 格式
+	ViewAccessKey(accountId string, publicKey string) (*AccessKeyView, error)
+	// 调用合约的只读方法，返回方法的原始返回值
+	CallFunction(contractId string, methodName string, args []byte) ([]byte, error)
+	// blockId 为高度（uint64）或区块哈希，为 nil 时返回最新的最终确认区块
+	GetBlock(blockId any) (*BlockView, error)
+	GetChunk(chunkHash string) (*ChunkView, error)
+	// 查询交易需要发送方账户确定所在分片
+	GetTransaction(hash string, senderId string) (*TxStatus, error)
+	// 最新区块的 gas 价格，单位 yoctoNEAR
+	GasPrice() (string, error)
+	// 异步广播签名交易，返回交易哈希
+	BroadcastTxAsync(signedTx []byte) (string, error)
+}
+
+// 定义 NEAR JSON-RPC 客户端
+type NearClient struct {
+	url    string
+	client *http.Client
+	nextId atomic.Uint64
+}
+
+func NewNearClient(rpcUrl string, timeout time.Duration) (INear, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &NearClient{
+		url:    rpcUrl,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// 调用 JSON-RPC 方法并解析结果，params 为数组或对象
+func (n *NearClient) call(result any, method string, params any) error {
+	body, err := json.Marshal(&rpcRequest{
+		JsonRpc: "2.0",
+		Id:      n.nextId.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return fmt.Errorf("call %s fail, status %d: %s", method, resp.StatusCode, string(respBody))
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// query 请求，使用最终确认的状态。旧版本节点在 result.error 中返回错误
+func (n *NearClient) query(result any, params map[string]any) error {
+	params["finality"] = "final"
+	var raw json.RawMessage
+	if err := n.call(&raw, "query", params); err != nil {
+		return err
+	}
+	var queryErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(raw, &queryErr) == nil && queryErr.Error != "" {
+		return &RpcError{Message: queryErr.Error}
+	}
+	return json.Unmarshal(raw, result)
+}
+
+func (n *NearClient) ViewAccount(accountId string) (*AccountView, error) {
+	account := new(AccountView)
+	if err := n.query(account, map[string]any{"request_type": "view_account", "account_id": accountId}); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (n *NearClient) ViewAccessKey(accountId string, publicKey string) (*AccessKeyView, error) {
+	accessKey := new(AccessKeyView)
+	params := map[string]any{"request_type": "view_access_key", "account_id": accountId, "public_key": publicKey}
+	if err := n.query(accessKey, params); err != nil {
+		return nil, err
+	}
+	return accessKey, nil
+}
+
+func (n *NearClient) CallFunction(contractId string, methodName string, args []byte) ([]byte, error) {
+	params := map[string]any{
+		"request_type": "call_function",
+		"account_id":   contractId,
+		"method_name":  methodName,
+		"args_base64":  base64.StdEncoding.EncodeToString(args),
+	}
+	// result 为字节数组，不是 base64
+	var result struct {
+		Result []int `json:"result"`
+	}
+	if err := n.query(&result, params); err != nil {
+		return nil, err
+	}
+	data := make([]byte, len(result.Result))
+	for i, b := range result.Result {
+		data[i] = byte(b)
+	}
+	return data, nil
+}
+
+func (n *NearClient) GetBlock(blockId any) (*BlockView, error) {
+	params := map[string]any{"finality": "final"}
+	if blockId != nil {
+		params = map[string]any{"block_id": blockId}
+	}
+	block := new(BlockView)
+	if err := n.call(block, "block", params); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (n *NearClient) GetChunk(chunkHash string) (*ChunkView, error) {
+	chunk := new(ChunkView)
+	if err := n.call(chunk, "chunk", map[string]any{"chunk_id": chunkHash}); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+func (n *NearClient) GetTransaction(hash string, senderId string) (*TxStatus, error) {
+	status := new(TxStatus)
+	if err := n.call(status, "tx", []any{hash, senderId}); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (n *NearClient) GasPrice() (string, error) {
+	var result struct {
+		GasPrice string `json:"gas_price"`
+	}
+	if err := n.call(&result, "gas_price", []any{nil}); err != nil {
+		return "", err
+	}
+	return result.GasPrice, nil
+}
+
+func (n *NearClient) BroadcastTxAsync(signedTx []byte) (string, error) {
+	var hash string
+	if err := n.call(&hash, "broadcast_tx_async", []any{base64.StdEncoding.EncodeToString(signedTx)}); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
@@ -0,0 +1,195 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// NEP-141 调用的 gas 上限，未使用的部分会退回
+const (
+	ftTransferGas     = 30_000_000_000_000
+	storageDepositGas = 30_000_000_000_000
+)
+
+// ft_transfer 要求附带 1 yoctoNEAR，防止受限访问密钥调用
+var oneYocto = big.NewInt(1)
+
+// 构建未签名交易：un_sign_tx 为 base64 编码的 borsh 交易，sign_hashes 为交易哈希（ed25519 对哈希签名）
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx NearTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	tx, err := c.buildTransfer(&transferTx)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   base64.StdEncoding.EncodeToString(tx.MarshalBorsh()),
+		SignHashes: []string{hex.EncodeToString(tx.Hash())},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 un_sign_tx，signature 为对交易哈希的 ed25519 签名。
+// 返回的 signed_tx 为 base64 编码的签名交易，msg 为 base58 交易哈希
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	txBytes, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	var tx *Transaction
+	if err == nil {
+		tx, err = UnmarshalTransaction(txBytes)
+	}
+	if err != nil {
+		log.Error("decode transaction fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	hash := tx.Hash()
+	if !ed25519.Verify(tx.PublicKey, hash, signature) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "signature verification failed",
+		}, nil
+	}
+	signedTx := &SignedTransaction{Transaction: *tx, Signature: signature}
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      base58.Encode(hash),
+		SignedTx: base64.StdEncoding.EncodeToString(signedTx.MarshalBorsh()),
+	}, nil
+}
+
+// 构建转账：NEAR 使用 Transfer，代币调用合约的 ft_transfer，收款方未在代币合约注册时先调用 storage_deposit 注册
+func (c *ChainAdaptor) buildTransfer(transferTx *NearTransferTx) (*Transaction, error) {
+	if !ValidateAccountId(transferTx.FromAddress) {
+		return nil, errors.New("invalid from address")
+	}
+	if !ValidateAccountId(transferTx.ToAddress) {
+		return nil, errors.New("invalid to address")
+	}
+	amount, ok := new(big.Int).SetString(transferTx.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+	publicKey := transferTx.PublicKey
+	if publicKey == "" && IsImplicitAccount(transferTx.FromAddress) {
+		publicKey = transferTx.FromAddress
+	}
+	pubKey, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, errors.New("invalid public key")
+	}
+
+	tx := &Transaction{
+		SignerId:   transferTx.FromAddress,
+		PublicKey:  pubKey,
+		Nonce:      transferTx.Nonce,
+		ReceiverId: transferTx.ToAddress,
+	}
+	if transferTx.ContractAddress == "" {
+		tx.Actions = []Action{{Type: actionTransfer, Deposit: amount}}
+	} else {
+		if !ValidateAccountId(transferTx.ContractAddress) {
+			return nil, errors.New("invalid contract address")
+		}
+		tx.ReceiverId = transferTx.ContractAddress
+		if tx.Actions, err = c.ftTransferActions(transferTx.ContractAddress, transferTx.ToAddress, amount); err != nil {
+			return nil, err
+		}
+	}
+
+	if tx.Nonce == 0 {
+		accessKey, err := c.NearClient.ViewAccessKey(transferTx.FromAddress, FormatPublicKey(pubKey))
+		if err != nil {
+			return nil, err
+		}
+		var permission string
+		if json.Unmarshal(accessKey.Permission, &permission) != nil || permission != "FullAccess" {
+			return nil, errors.New("access key is not full access")
+		}
+		tx.Nonce = accessKey.Nonce + 1
+	}
+	blockHash := transferTx.BlockHash
+	if blockHash == "" {
+		block, err := c.NearClient.GetBlock(nil)
+		if err != nil {
+			return nil, err
+		}
+		blockHash = block.Header.Hash
+	}
+	hash := base58.Decode(blockHash)
+	if len(hash) != len(tx.BlockHash) {
+		return nil, errors.New("invalid block hash")
+	}
+	copy(tx.BlockHash[:], hash)
+	return tx, nil
+}
+
+// 收款方未注册时返回 storage_deposit 和 ft_transfer 两个调用
+func (c *ChainAdaptor) ftTransferActions(contractId string, receiverId string, amount *big.Int) ([]Action, error) {
+	args, _ := json.Marshal(map[string]string{"receiver_id": receiverId, "amount": amount.String()})
+	transfer := Action{Type: actionFunctionCall, MethodName: "ft_transfer", Args: args, Gas: ftTransferGas, Deposit: oneYocto}
+
+	accountArgs, _ := json.Marshal(map[string]string{"account_id": receiverId})
+	result, err := c.NearClient.CallFunction(contractId, "storage_balance_of", accountArgs)
+	if err != nil {
+		return nil, err
+	}
+	if string(result) != "null" {
+		return []Action{transfer}, nil
+	}
+	result, err = c.NearClient.CallFunction(contractId, "storage_balance_bounds", []byte("{}"))
+	if err != nil {
+		return nil, err
+	}
+	var bounds struct {
+		Min string `json:"min"`
+	}
+	if err := json.Unmarshal(result, &bounds); err != nil {
+		return nil, err
+	}
+	deposit, ok := new(big.Int).SetString(bounds.Min, 10)
+	if !ok {
+		return nil, errors.New("invalid storage balance bounds")
+	}
+	registerArgs, _ := json.Marshal(map[string]any{"account_id": receiverId, "registration_only": true})
+	register := Action{Type: actionFunctionCall, MethodName: "storage_deposit", Args: registerArgs, Gas: storageDepositGas, Deposit: deposit}
+	return []Action{register, transfer}, nil
+}
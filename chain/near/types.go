@@ -0,0 +1,26 @@
+package near
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type NearTransferTx struct {
+	// 发送方账户，隐式账户时可不传 public_key
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	// NEAR 单位为 yoctoNEAR（10^24），代币为合约的最小单位
+	Amount string `json:"amount"`
+	// NEP-141 代币合约账户，为空时转账 NEAR
+	ContractAddress string `json:"contract_address"`
+	// 签名公钥，hex 或 ed25519:base58，必须为完全访问密钥
+	PublicKey string `json:"public_key"`
+	// 为 0 时查询访问密钥当前 nonce 后加 1
+	Nonce uint64 `json:"nonce"`
+	// 交易引用的区块哈希（base58），为空时使用最新的最终确认区块，约 24 小时内有效
+	BlockHash string `json:"block_hash"`
+}
+
+// 交易中解析出的 NEAR 或代币转账
+type Transfer struct {
+	ContractAddress string `json:"contract_address"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Amount          string `json:"amount"`
+}
@@ -0,0 +1,106 @@
+package opstack
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// L1→L2 存款交易，编码为 0x7E || RLP([sourceHash, from, to, mint, value, gas, isSystemTx, data])，没有签名
+type DepositTx struct {
+	SourceHash common.Hash
+	From       common.Address
+	To         *common.Address `rlp:"nil"`
+	Mint       *big.Int        `rlp:"nil"`
+	Value      *big.Int
+	Gas        uint64
+	IsSystemTx bool
+	Data       []byte
+}
+
+func (tx *DepositTx) MarshalBinary() ([]byte, error) {
+	payload, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{DepositTxType}, payload...), nil
+}
+
+// 解码存款交易，交易哈希为完整编码的 keccak256
+func UnmarshalDepositTx(raw []byte) (*DepositTx, common.Hash, error) {
+	if len(raw) == 0 || raw[0] != DepositTxType {
+		return nil, common.Hash{}, errors.New("not a deposit transaction")
+	}
+	var tx DepositTx
+	if err := rlp.DecodeBytes(raw[1:], &tx); err != nil {
+		return nil, common.Hash{}, err
+	}
+	return &tx, crypto.Keccak256Hash(raw), nil
+}
+
+// 解码原始交易，支持存款交易和以太坊标准交易，已签名时恢复发送方地址
+func decodeRawTx(raw []byte) (*DecodedTx, error) {
+	if len(raw) > 0 && raw[0] == DepositTxType {
+		tx, hash, err := UnmarshalDepositTx(raw)
+		if err != nil {
+			return nil, err
+		}
+		decoded := &DecodedTx{
+			Type:       DepositTxType,
+			Hash:       hash.Hex(),
+			From:       tx.From.Hex(),
+			Value:      bigOrZero(tx.Value).String(),
+			Gas:        tx.Gas,
+			SourceHash: tx.SourceHash.Hex(),
+			Mint:       bigOrZero(tx.Mint).String(),
+			IsSystemTx: tx.IsSystemTx,
+		}
+		if tx.To != nil {
+			decoded.To = tx.To.Hex()
+		}
+		if len(tx.Data) > 0 {
+			decoded.Data = hexutil.Encode(tx.Data)
+		}
+		return decoded, nil
+	}
+
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	decoded := &DecodedTx{
+		Type:  tx.Type(),
+		Hash:  tx.Hash().Hex(),
+		Nonce: tx.Nonce(),
+		Value: tx.Value().String(),
+		Gas:   tx.Gas(),
+	}
+	if tx.Type() == types.LegacyTxType {
+		decoded.GasPrice = tx.GasPrice().String()
+	} else {
+		decoded.GasFeeCap = tx.GasFeeCap().String()
+		decoded.GasTipCap = tx.GasTipCap().String()
+	}
+	if tx.ChainId().Sign() > 0 {
+		decoded.ChainId = tx.ChainId().String()
+	}
+	if tx.To() != nil {
+		decoded.To = tx.To().Hex()
+	}
+	if len(tx.Data()) > 0 {
+		decoded.Data = hexutil.Encode(tx.Data())
+	}
+	if _, r, s := tx.RawSignatureValues(); r.Sign() != 0 && s.Sign() != 0 {
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), &tx)
+		if err != nil {
+			return nil, err
+		}
+		decoded.From = from.Hex()
+	}
+	return decoded, nil
+}
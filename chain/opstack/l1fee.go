@@ -0,0 +1,185 @@
+package opstack
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// GasPriceOracle 预置合约
+	GasPriceOracleAddress = common.HexToAddress("0x420000000000000000000000000000000000000F")
+	// 每个区块第一笔 L1 属性存款交易的发送方
+	L1InfoDepositorAddress = common.HexToAddress("0xDeaDDEaDDeAdDeAdDEAdDEaddeAddEAdDEAd0001")
+)
+
+// GasPriceOracle 方法选择器
+var (
+	isEcotoneSelector         = methodId("isEcotone()")
+	isFjordSelector           = methodId("isFjord()")
+	l1BaseFeeSelector         = methodId("l1BaseFee()")
+	blobBaseFeeSelector       = methodId("blobBaseFee()")
+	baseFeeScalarSelector     = methodId("baseFeeScalar()")
+	blobBaseFeeScalarSelector = methodId("blobBaseFeeScalar()")
+	getL1FeeSelector          = methodId("getL1Fee(bytes)")
+)
+
+const (
+	// 未签名交易按 68 字节补齐签名等字段
+	txSignatureOverhead = 68
+	// 费率参数精度
+	scalarDecimals = 1_000_000
+	// Fjord 压缩长度线性回归参数
+	fjordCostIntercept      = -42_585_600
+	fjordFastLzCoef         = 836_500
+	fjordMinTransactionSize = 100 * scalarDecimals
+)
+
+// GasPriceOracle 的 L1 费用参数
+type L1FeeParams struct {
+	Ecotone           bool
+	Fjord             bool
+	L1BaseFee         *big.Int
+	BlobBaseFee       *big.Int
+	BaseFeeScalar     uint64
+	BlobBaseFeeScalar uint64
+}
+
+// 按 Ecotone 或 Fjord 公式计算未签名交易的 L1 数据费（wei），与预言机 getL1Fee 结果一致
+func (p *L1FeeParams) L1Fee(unsignedTx []byte) *big.Int {
+	if p.Fjord {
+		return p.fjordL1Fee(unsignedTx)
+	}
+	return p.ecotoneL1Fee(unsignedTx)
+}
+
+// (zeros*4 + nonzeros*16 + 68*16) * (16*baseFeeScalar*l1BaseFee + blobBaseFeeScalar*blobBaseFee) / (16*1e6)
+func (p *L1FeeParams) ecotoneL1Fee(data []byte) *big.Int {
+	var gas uint64
+	for _, b := range data {
+		if b == 0 {
+			gas += 4
+		} else {
+			gas += 16
+		}
+	}
+	gas += txSignatureOverhead * 16
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gas), p.feeScaled())
+	return fee.Div(fee, big.NewInt(16*scalarDecimals))
+}
+
+// max(100e6, -42585600 + 836500*(flzLen+68)) * (16*baseFeeScalar*l1BaseFee + blobBaseFeeScalar*blobBaseFee) / 1e12
+func (p *L1FeeParams) fjordL1Fee(data []byte) *big.Int {
+	size := int64(FlzCompressLen(data)) + txSignatureOverhead
+	estimated := fjordCostIntercept + fjordFastLzCoef*size
+	if estimated < fjordMinTransactionSize {
+		estimated = fjordMinTransactionSize
+	}
+	fee := new(big.Int).Mul(big.NewInt(estimated), p.feeScaled())
+	return fee.Div(fee, big.NewInt(scalarDecimals*scalarDecimals))
+}
+
+func (p *L1FeeParams) feeScaled() *big.Int {
+	baseFee := new(big.Int).Mul(bigOrZero(p.L1BaseFee), new(big.Int).SetUint64(16*p.BaseFeeScalar))
+	blobFee := new(big.Int).Mul(bigOrZero(p.BlobBaseFee), new(big.Int).SetUint64(p.BlobBaseFeeScalar))
+	return baseFee.Add(baseFee, blobFee)
+}
+
+// 计算 FastLZ（LZ77 level 1）压缩后的长度，与 GasPriceOracle 中 LibZip.flzCompress 一致
+func FlzCompressLen(ib []byte) uint32 {
+	n := uint32(0)
+	ht := make([]uint32, 8192)
+	u24 := func(i uint32) uint32 {
+		return uint32(ib[i]) | uint32(ib[i+1])<<8 | uint32(ib[i+2])<<16
+	}
+	cmp := func(p uint32, q uint32, e uint32) uint32 {
+		l := uint32(0)
+		for e -= q; l < e; l++ {
+			if ib[p+l] != ib[q+l] {
+				e = 0
+			}
+		}
+		return l
+	}
+	literals := func(r uint32) {
+		n += 0x21 * (r / 0x20)
+		r %= 0x20
+		if r != 0 {
+			n += r + 1
+		}
+	}
+	match := func(l uint32) {
+		l--
+		n += 3 * (l / 262)
+		if l%262 >= 6 {
+			n += 3
+		} else {
+			n += 2
+		}
+	}
+	hash := func(v uint32) uint32 {
+		return ((2654435769 * v) >> 19) & 0x1fff
+	}
+	setNextHash := func(ip uint32) uint32 {
+		ht[hash(u24(ip))] = ip
+		return ip + 1
+	}
+
+	a := uint32(0)
+	ipLimit := uint32(0)
+	if len(ib) > 13 {
+		ipLimit = uint32(len(ib)) - 13
+	}
+	for ip := a + 2; ip < ipLimit; {
+		var r, d uint32
+		for {
+			s := u24(ip)
+			h := hash(s)
+			r = ht[h]
+			ht[h] = ip
+			d = ip - r
+			if ip >= ipLimit {
+				break
+			}
+			ip++
+			if d <= 0x1fff && s == u24(r) {
+				break
+			}
+		}
+		if ip >= ipLimit {
+			break
+		}
+		ip--
+		if ip > a {
+			literals(ip - a)
+		}
+		l := cmp(r+3, ip+3, ipLimit+9)
+		match(l)
+		ip = setNextHash(setNextHash(ip + l))
+		a = ip
+	}
+	literals(uint32(len(ib)) - a)
+	return n
+}
+
+// 按 ABI 编码 getL1Fee(bytes) 的调用数据
+func encodeGetL1Fee(data []byte) []byte {
+	input := make([]byte, 0, 4+64+(len(data)+31)/32*32)
+	input = append(input, getL1FeeSelector...)
+	input = append(input, common.LeftPadBytes(big.NewInt(32).Bytes(), 32)...)
+	input = append(input, common.LeftPadBytes(big.NewInt(int64(len(data))).Bytes(), 32)...)
+	input = append(input, common.RightPadBytes(data, (len(data)+31)/32*32)...)
+	return input
+}
+
+func methodId(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
+
+func bigOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
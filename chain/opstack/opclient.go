@@ -0,0 +1,124 @@
+package opstack
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"chain-account/chain/ethereum"
+	"chain-account/config"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// OP Stack 特有的节点接口，交易和区块按 OP 格式解析以支持存款交易
+type IOpStack interface {
+	CallContract(to common.Address, data []byte) ([]byte, error)
+	BlockByNumber(*big.Int) (*RpcBlock, error)
+	BlockByHash(common.Hash) (*RpcBlock, error)
+	TxByHash(common.Hash) (*RpcTransaction, error)
+	TxReceiptByHash(common.Hash) (*RpcReceipt, error)
+	Close()
+}
+
+type OpClient struct {
+	rpc     ethereum.IRpc
+	timeout time.Duration
+}
+
+// 与 EthClient 使用同样的 RPC 连接栈（重试、熔断、请求合并等）
+func NewOpClient(rpcUrl string, timeout time.Duration, conf config.Rpc) (IOpStack, error) {
+	if rpcUrl == "" {
+		return nil, errors.New("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	client, err := ethereum.DialRpc(context.Background(), rpcUrl, conf)
+	if err != nil {
+		return nil, err
+	}
+	return &OpClient{rpc: client, timeout: timeout}, nil
+}
+
+// eth_call 调用合约（latest 区块）
+func (c *OpClient) CallContract(to common.Address, data []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var result hexutil.Bytes
+	msg := map[string]interface{}{
+		"to":   to,
+		"data": hexutil.Bytes(data),
+	}
+	if err := c.rpc.CallContext(ctx, &result, "eth_call", msg, "latest"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *OpClient) BlockByNumber(number *big.Int) (*RpcBlock, error) {
+	arg := "latest"
+	if number != nil {
+		arg = hexutil.EncodeBig(number)
+	}
+	return c.getBlock("eth_getBlockByNumber", arg)
+}
+
+func (c *OpClient) BlockByHash(hash common.Hash) (*RpcBlock, error) {
+	return c.getBlock("eth_getBlockByHash", hash)
+}
+
+func (c *OpClient) getBlock(method string, arg interface{}) (*RpcBlock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var block *RpcBlock
+	if err := c.rpc.CallContext(ctx, &block, method, arg, true); err != nil {
+		return nil, err
+	} else if block == nil {
+		return nil, geth.NotFound
+	}
+	return block, nil
+}
+
+func (c *OpClient) TxByHash(hash common.Hash) (*RpcTransaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var tx *RpcTransaction
+	if err := c.rpc.CallContext(ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		return nil, err
+	} else if tx == nil {
+		return nil, geth.NotFound
+	}
+	return tx, nil
+}
+
+func (c *OpClient) TxReceiptByHash(hash common.Hash) (*RpcReceipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var receipt *RpcReceipt
+	if err := c.rpc.CallContext(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	} else if receipt == nil {
+		return nil, geth.NotFound
+	}
+	return receipt, nil
+}
+
+func (c *OpClient) Close() {
+	c.rpc.Close()
+}
+
+// 合约调用被回滚，如旧版本预言机没有 isEcotone 等方法
+func isReverted(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "execution reverted")
+}
@@ -0,0 +1,415 @@
+package opstack
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/status-im/keycard-go/hexutils"
+
+	"chain-account/chain"
+	"chain-account/chain/ethereum"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const (
+	ChainName       = "Optimism"
+	BaseChainName   = "Base"
+	MantleChainName = "Mantle"
+)
+
+// OP Stack 链参数
+type ChainParams struct {
+	Name           string
+	ChainId        uint64
+	TestnetChainId uint64
+	// 预言机只提供 getL1Fee（如 Mantle），不按 Ecotone/Fjord 公式本地计算
+	LegacyOracle bool
+}
+
+var (
+	OptimismParams = ChainParams{Name: ChainName, ChainId: global_const.OpChinId, TestnetChainId: global_const.OpTestChinId}
+	BaseParams     = ChainParams{Name: BaseChainName, ChainId: global_const.BaseChainId, TestnetChainId: global_const.BaseSepoliaChainId}
+	MantleParams   = ChainParams{Name: MantleChainName, ChainId: global_const.MantleChainId, TestnetChainId: global_const.MantleSepoliaChainId, LegacyOracle: true}
+)
+
+// 在以太坊适配器的基础上计算 L1 数据费，并解析存款交易，其余接口沿用以太坊的实现
+type ChainAdaptor struct {
+	*ethereum.ChainAdaptor
+	OpClient     IOpStack
+	chainId      *big.Int
+	legacyOracle bool
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(OptimismParams, con.WalletNode.Op, con)
+}
+
+func NewBaseAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(BaseParams, con.WalletNode.Base, con)
+}
+
+func NewMantleAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(MantleParams, con.WalletNode.Mantle, con)
+}
+
+func newChainAdaptor(params ChainParams, node config.Node, con *config.Config) (*ChainAdaptor, error) {
+	network := con.NetWork
+	if node.Network != "" {
+		network = node.Network
+	}
+	chainId := node.ChainId
+	if chainId == 0 {
+		chainId = params.ChainId
+		if network == "testnet" {
+			chainId = params.TestnetChainId
+		}
+		node.ChainId = chainId
+	}
+	evmAdaptor, err := ethereum.NewEvmChainAdaptor(params.Name, strings.ToLower(params.Name), node, con.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	opClient, err := NewOpClient(node.RpcUrl, time.Duration(node.TimeOut)*time.Second, node.Rpc)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		ChainAdaptor: evmAdaptor,
+		OpClient:     opClient,
+		chainId:      new(big.Int).SetUint64(chainId),
+		legacyOracle: params.LegacyOracle,
+	}, nil
+}
+
+// 在以太坊费用的基础上追加 L1 数据费（wei），格式为 gasPrice|gasTipCap|倍数|l1Fee。
+// raw_tx 为十六进制的未签名交易，为空时按 ETH 转账估算
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	resp, err := c.ChainAdaptor.GetFee(req)
+	if err != nil || resp.Code != global_const.ReturnCode_SUCCESS {
+		return resp, err
+	}
+	var unsignedTx []byte
+	if req.RawTx != "" {
+		unsignedTx, err = hexutil.Decode(req.RawTx)
+		if err != nil {
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+	} else {
+		unsignedTx, err = c.transferTx(resp.SlowFee)
+		if err != nil {
+			log.Error("build transfer tx fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get l1 fee fail",
+			}, nil
+		}
+	}
+	l1Fee, err := c.L1Fee(unsignedTx)
+	if err != nil {
+		log.Error("get l1 fee fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get l1 fee fail",
+		}, nil
+	}
+	suffix := "|" + l1Fee.String()
+	resp.SlowFee += suffix
+	resp.NormalFee += suffix
+	resp.FastFee += suffix
+	return resp, nil
+}
+
+// 计算未签名交易的 L1 数据费
+func (c *ChainAdaptor) L1Fee(unsignedTx []byte) (*big.Int, error) {
+	if !c.legacyOracle {
+		params, err := c.l1FeeParams()
+		if err != nil {
+			return nil, err
+		}
+		if params != nil {
+			return params.L1Fee(unsignedTx), nil
+		}
+	}
+	// Ecotone 之前的预言机和 Mantle 直接调用 getL1Fee
+	result, err := c.OpClient.CallContract(GasPriceOracleAddress, encodeGetL1Fee(unsignedTx))
+	if err != nil {
+		return nil, err
+	}
+	return decodeUint256(result)
+}
+
+// 读取预言机的费用参数，未升级到 Ecotone 时返回 nil
+func (c *ChainAdaptor) l1FeeParams() (*L1FeeParams, error) {
+	ecotone, err := c.callBool(isEcotoneSelector)
+	if err != nil || !ecotone {
+		return nil, err
+	}
+	fjord, err := c.callBool(isFjordSelector)
+	if err != nil {
+		return nil, err
+	}
+	params := &L1FeeParams{Ecotone: true, Fjord: fjord}
+	if params.L1BaseFee, err = c.callUint(l1BaseFeeSelector); err != nil {
+		return nil, err
+	}
+	if params.BlobBaseFee, err = c.callUint(blobBaseFeeSelector); err != nil {
+		return nil, err
+	}
+	baseFeeScalar, err := c.callUint(baseFeeScalarSelector)
+	if err != nil {
+		return nil, err
+	}
+	blobBaseFeeScalar, err := c.callUint(blobBaseFeeScalarSelector)
+	if err != nil {
+		return nil, err
+	}
+	params.BaseFeeScalar, params.BlobBaseFeeScalar = baseFeeScalar.Uint64(), blobBaseFeeScalar.Uint64()
+	return params, nil
+}
+
+// 调用返回 bool 的预言机方法，方法不存在时视为 false
+func (c *ChainAdaptor) callBool(selector []byte) (bool, error) {
+	result, err := c.OpClient.CallContract(GasPriceOracleAddress, selector)
+	if isReverted(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	value, err := decodeUint256(result)
+	if err != nil {
+		return false, err
+	}
+	return value.Sign() != 0, nil
+}
+
+func (c *ChainAdaptor) callUint(selector []byte) (*big.Int, error) {
+	result, err := c.OpClient.CallContract(GasPriceOracleAddress, selector)
+	if err != nil {
+		return nil, err
+	}
+	return decodeUint256(result)
+}
+
+// 用于估算 L1 数据费的 ETH 转账交易，fee 为 gasPrice|gasTipCap
+func (c *ChainAdaptor) transferTx(fee string) ([]byte, error) {
+	parts := strings.Split(fee, "|")
+	gasPrice, _ := new(big.Int).SetString(parts[0], 10)
+	gasTipCap := new(big.Int)
+	if len(parts) > 1 {
+		gasTipCap.SetString(parts[1], 10)
+	}
+	to := common.HexToAddress("0xffffffffffffffffffffffffffffffffffffffff")
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   c.chainId,
+		Nonce:     1,
+		GasTipCap: gasTipCap,
+		GasFeeCap: bigOrZero(gasPrice),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1e18),
+	}).MarshalBinary()
+}
+
+// 按Hash获取交易详情，手续费包含 L1 数据费，存款交易的 type 为 126，l1_origin 为 deposit，l1_origin_id 为 sourceHash
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	hash := common.HexToHash(req.Hash)
+	tx, err := c.OpClient.TxByHash(hash)
+	if errors.Is(err, geth.NotFound) {
+		// 节点上查不到的交易（如已被替换或丢弃）以跟踪记录为准
+		if c.Outgoing != nil {
+			return c.ChainAdaptor.GetTxByHash(req)
+		}
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx: &account.TxMessage{
+				Hash:   req.Hash,
+				Status: account.TxStatus_NotFound,
+			},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get tx by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+
+	// 没有收据说明交易还在 mempool 中
+	receipt, err := c.OpClient.TxReceiptByHash(hash)
+	if err != nil && !errors.Is(err, geth.NotFound) {
+		log.Error("get tx receipt by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get tx receipt by hash fail",
+		}, nil
+	}
+
+	txMessage := transferMessage(tx)
+	txMessage.Status = account.TxStatus_Pending
+	txMessage.Type = int32(tx.Type)
+	if receipt != nil {
+		txMessage.Index = uint32(receipt.TransactionIndex)
+		txMessage.Height = bigOrZero(receipt.BlockNumber.ToInt()).String()
+		txMessage.Fee = receiptFee(receipt).String()
+		txMessage.Status = account.TxStatus_Failed
+		if receipt.Status == hexutil.Uint64(types.ReceiptStatusSuccessful) {
+			txMessage.Status = account.TxStatus_Success
+		}
+		if tx.To == nil && receipt.ContractAddress != nil {
+			txMessage.To = receipt.ContractAddress.Hex()
+		}
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   txMessage,
+	}, nil
+}
+
+// 通过区块号获取区块数据，跳过 L1 属性系统交易，存款交易的 l1_origin 为 deposit，l1_origin_id 为 sourceHash
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	var number *big.Int
+	if req.Height != 0 {
+		number = big.NewInt(req.Height)
+	}
+	block, err := c.OpClient.BlockByNumber(number)
+	if err != nil {
+		log.Error("get block by number fail", "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return blockResponse(block, "get block by number success"), nil
+}
+
+// 通过区块Hash获取区块数据
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	block, err := c.OpClient.BlockByHash(common.HexToHash(req.Hash))
+	if err != nil {
+		log.Error("get block by hash fail", "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return blockResponse(block, "get block by hash success"), nil
+}
+
+// 解码十六进制原始交易（包括存款交易），base64_tx 为 base64 编码的 DecodedTx
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	raw, err := hexutil.Decode(req.RawTx)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	decoded, err := decodeRawTx(raw)
+	if err != nil {
+		log.Error("decode transaction fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode transaction fail",
+		}, nil
+	}
+	txJson, _ := json.Marshal(decoded)
+	return &account.DecodeTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+func blockResponse(block *RpcBlock, msg string) *account.BlockResponse {
+	height := uint64(block.Number)
+	var blockTxList []*account.BlockInfoTransactionList
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		if tx.IsDeposit() && tx.From == L1InfoDepositorAddress {
+			continue
+		}
+		itemTx := &account.BlockInfoTransactionList{
+			From:   tx.From.Hex(),
+			Hash:   tx.Hash.Hex(),
+			Amount: bigOrZero(tx.Value.ToInt()).String(),
+			Height: height,
+		}
+		itemTx.L1Origin, itemTx.L1OriginId = l1Origin(tx)
+		if tx.To != nil {
+			itemTx.To = tx.To.Hex()
+		}
+		blockTxList = append(blockTxList, itemTx)
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          msg,
+		Height:       int64(height),
+		Hash:         block.Hash.Hex(),
+		BaseFee:      block.BaseFee,
+		Transactions: blockTxList,
+	}
+}
+
+// 解析 ETH 或 ERC20 转账，ERC20 转账的 to 和 value 取自调用数据
+func transferMessage(tx *RpcTransaction) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:            tx.Hash.Hex(),
+		From:            tx.From.Hex(),
+		Value:           bigOrZero(tx.Value.ToInt()).String(),
+		ContractAddress: global_const.ZeroAddress,
+		Data:            hexutils.BytesToHex(tx.Input),
+	}
+	txMessage.L1Origin, txMessage.L1OriginId = l1Origin(tx)
+	if tx.To == nil {
+		return txMessage
+	}
+	txMessage.To = tx.To.Hex()
+	if len(tx.Input) >= 68 && hexutil.Encode(tx.Input[:4]) == "0xa9059cbb" {
+		txMessage.To = common.BytesToAddress(tx.Input[16:36]).Hex()
+		txMessage.Value = new(big.Int).SetBytes(tx.Input[36:68]).String()
+		txMessage.ContractAddress = tx.To.Hex()
+	}
+	return txMessage
+}
+
+// 存款交易的 L1 来源和 sourceHash
+func l1Origin(tx *RpcTransaction) (string, string) {
+	if !tx.IsDeposit() {
+		return "", ""
+	}
+	if tx.SourceHash == nil {
+		return global_const.L1OriginDeposit, ""
+	}
+	return global_const.L1OriginDeposit, tx.SourceHash.Hex()
+}
+
+// L2 执行费 gasUsed*effectiveGasPrice 加 L1 数据费
+func receiptFee(receipt *RpcReceipt) *big.Int {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(uint64(receipt.GasUsed)), bigOrZero(receipt.EffectiveGasPrice.ToInt()))
+	return fee.Add(fee, bigOrZero(receipt.L1Fee.ToInt()))
+}
+
+func decodeUint256(result []byte) (*big.Int, error) {
+	if len(result) < 32 {
+		return nil, errors.New("invalid uint256 result")
+	}
+	return new(big.Int).SetBytes(result[:32]), nil
+}
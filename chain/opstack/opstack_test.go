@@ -0,0 +1,356 @@
+package opstack
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"chain-account/chain/ethereum"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+var (
+	testSender     = common.HexToAddress("0x62EccDa8bB2Ae5690E319F3eFde897dEAeD86631")
+	testReceiver   = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testSourceHash = common.HexToHash("0xabababababababababababababababababababababababababababababababab")
+)
+
+// 模拟 OP Stack 节点，oracle 为预言机方法选择器到返回值的映射，缺失的方法按回滚处理
+type fakeOpStack struct {
+	IOpStack
+	oracle   map[string][]byte
+	calls    [][]byte
+	block    *RpcBlock
+	txs      map[common.Hash]*RpcTransaction
+	receipts map[common.Hash]*RpcReceipt
+}
+
+func (f *fakeOpStack) CallContract(to common.Address, data []byte) ([]byte, error) {
+	f.calls = append(f.calls, data)
+	if result, ok := f.oracle[string(data[:4])]; ok {
+		return result, nil
+	}
+	return nil, &revertError{}
+}
+
+func (f *fakeOpStack) BlockByNumber(number *big.Int) (*RpcBlock, error) {
+	return f.block, nil
+}
+
+func (f *fakeOpStack) TxByHash(hash common.Hash) (*RpcTransaction, error) {
+	return f.txs[hash], nil
+}
+
+func (f *fakeOpStack) TxReceiptByHash(hash common.Hash) (*RpcReceipt, error) {
+	return f.receipts[hash], nil
+}
+
+type revertError struct{}
+
+func (e *revertError) Error() string { return "execution reverted" }
+
+type fakeEth struct {
+	ethereum.IEth
+}
+
+func (f *fakeEth) SuggestGasPrice() (*big.Int, error) { return big.NewInt(1000), nil }
+
+func (f *fakeEth) SuggestGasTipCap() (*big.Int, error) { return big.NewInt(10), nil }
+
+func uint256(v int64) []byte {
+	return common.LeftPadBytes(big.NewInt(v).Bytes(), 32)
+}
+
+func newTestAdaptor(client *fakeOpStack, legacyOracle bool) *ChainAdaptor {
+	return &ChainAdaptor{
+		ChainAdaptor: &ethereum.ChainAdaptor{EthClient: &fakeEth{}},
+		OpClient:     client,
+		chainId:      big.NewInt(10),
+		legacyOracle: legacyOracle,
+	}
+}
+
+// 参数与 op-geth 的测试用例一致：l1BaseFee=1000e6，blobBaseFee=10e6，baseFeeScalar=2，blobBaseFeeScalar=3
+func testFeeParams(fjord bool) *L1FeeParams {
+	return &L1FeeParams{
+		Ecotone:           true,
+		Fjord:             fjord,
+		L1BaseFee:         big.NewInt(1000 * 1e6),
+		BlobBaseFee:       big.NewInt(10 * 1e6),
+		BaseFeeScalar:     2,
+		BlobBaseFeeScalar: 3,
+	}
+}
+
+func Test_L1Fee(t *testing.T) {
+	// Ecotone：(0 + 68*16) * (2*16*1000e6 + 3*10e6) / 16e6
+	if fee := testFeeParams(false).L1Fee(nil); fee.Int64() != 2178040 {
+		t.Fatalf("ecotone fee = %s", fee)
+	}
+	// Fjord：长度低于下限时按 100e6 计算，100e6 * (2*16*1000e6 + 3*10e6) / 1e12
+	if fee := testFeeParams(true).L1Fee(nil); fee.Int64() != 3203000 {
+		t.Fatalf("fjord fee = %s", fee)
+	}
+	// 超过下限后按压缩长度线性增长
+	data := make([]byte, 2000)
+	for i := range data {
+		data[i] = byte(i*7 + i/3)
+	}
+	size := int64(FlzCompressLen(data)) + 68
+	expected := new(big.Int).Mul(big.NewInt(-42_585_600+836_500*size), big.NewInt(32030*1e6))
+	expected.Div(expected, big.NewInt(1e12))
+	if fee := testFeeParams(true).L1Fee(data); fee.Cmp(expected) != 0 {
+		t.Fatalf("fjord fee = %s, expected %s", fee, expected)
+	}
+}
+
+func Test_FlzCompressLen(t *testing.T) {
+	for _, tc := range []struct {
+		input    []byte
+		expected uint32
+	}{
+		{nil, 0},
+		{[]byte{1}, 2},
+		// 不超过 13 字节时全部按字面量输出
+		{[]byte("hello world!!"), 14},
+		// 2 字节字面量 + 一次匹配 + 末尾 5 字节字面量
+		{bytes.Repeat([]byte{0xaa}, 40), 12},
+	} {
+		if got := FlzCompressLen(tc.input); got != tc.expected {
+			t.Fatalf("FlzCompressLen(%x) = %d, expected %d", tc.input, got, tc.expected)
+		}
+	}
+	// 重复数据压缩后远小于原长度
+	if got := FlzCompressLen(make([]byte, 1000)); got == 0 || got > 50 {
+		t.Fatalf("FlzCompressLen(zeros) = %d", got)
+	}
+	random := crypto.Keccak256(nil)
+	for len(random) < 1024 {
+		random = append(random, crypto.Keccak256(random[len(random)-32:])...)
+	}
+	if got := FlzCompressLen(random); got < 1024 {
+		t.Fatalf("FlzCompressLen(random) = %d", got)
+	}
+}
+
+func Test_GetFee(t *testing.T) {
+	client := &fakeOpStack{oracle: map[string][]byte{
+		string(isEcotoneSelector):         uint256(1),
+		string(isFjordSelector):           uint256(0),
+		string(l1BaseFeeSelector):         uint256(1000 * 1e6),
+		string(blobBaseFeeSelector):       uint256(10 * 1e6),
+		string(baseFeeScalarSelector):     uint256(2),
+		string(blobBaseFeeScalarSelector): uint256(3),
+	}}
+	adaptor := newTestAdaptor(client, false)
+	resp, err := adaptor.GetFee(&account.FeeRequest{})
+	if err != nil || resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("GetFee: %v %v", err, resp)
+	}
+	unsignedTx, _ := adaptor.transferTx("1000|10")
+	l1Fee := testFeeParams(false).L1Fee(unsignedTx).String()
+	if resp.SlowFee != "1000|10|"+l1Fee || resp.NormalFee != "1000|10|*2|"+l1Fee || resp.FastFee != "1000|10|*3|"+l1Fee {
+		t.Fatalf("unexpected fee %v", resp)
+	}
+
+	// 指定交易时按该交易计算
+	resp, _ = adaptor.GetFee(&account.FeeRequest{RawTx: "0x00"})
+	if !strings.HasSuffix(resp.SlowFee, "|"+testFeeParams(false).L1Fee([]byte{0}).String()) {
+		t.Fatalf("unexpected fee %v", resp)
+	}
+	resp, _ = adaptor.GetFee(&account.FeeRequest{RawTx: "zz"})
+	if resp.Code != global_const.ReturnCode_ERROR || resp.Msg != "invalid raw tx" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+}
+
+func Test_GetFeeLegacyOracle(t *testing.T) {
+	client := &fakeOpStack{oracle: map[string][]byte{
+		string(getL1FeeSelector): uint256(123456),
+	}}
+	// Ecotone 之前的链和 Mantle 都调用 getL1Fee
+	for _, legacyOracle := range []bool{false, true} {
+		client.calls = nil
+		resp, _ := newTestAdaptor(client, legacyOracle).GetFee(&account.FeeRequest{RawTx: "0x010203"})
+		if resp.SlowFee != "1000|10|123456" {
+			t.Fatalf("unexpected fee %v", resp)
+		}
+		call := client.calls[len(client.calls)-1]
+		expected := append(append(append([]byte{}, getL1FeeSelector...), uint256(32)...), uint256(3)...)
+		expected = append(expected, common.RightPadBytes([]byte{1, 2, 3}, 32)...)
+		if !bytes.Equal(call, expected) {
+			t.Fatalf("unexpected call data %x", call)
+		}
+	}
+}
+
+func Test_DecodeDepositTransaction(t *testing.T) {
+	deposit := &DepositTx{
+		SourceHash: testSourceHash,
+		From:       testSender,
+		To:         &testReceiver,
+		Mint:       big.NewInt(1e18),
+		Value:      big.NewInt(5e17),
+		Gas:        100000,
+		Data:       []byte{0xde, 0xad},
+	}
+	raw, err := deposit.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodedDeposit, hash, err := UnmarshalDepositTx(raw)
+	if err != nil || hash != crypto.Keccak256Hash(raw) || decodedDeposit.Mint.Cmp(deposit.Mint) != 0 || *decodedDeposit.To != testReceiver {
+		t.Fatalf("unexpected deposit %v %v", decodedDeposit, err)
+	}
+
+	adaptor := newTestAdaptor(&fakeOpStack{}, false)
+	resp, _ := adaptor.DecodeTransaction(&account.DecodeTransactionRequest{RawTx: hexutil.Encode(raw)})
+	decoded := decodeResponse(t, resp)
+	if decoded.Type != DepositTxType || decoded.Hash != hash.Hex() || decoded.From != testSender.Hex() ||
+		decoded.To != testReceiver.Hex() || decoded.Mint != "1000000000000000000" || decoded.Value != "500000000000000000" ||
+		decoded.SourceHash != testSourceHash.Hex() || decoded.Data != "0xdead" {
+		t.Fatalf("unexpected decoded tx %+v", decoded)
+	}
+
+	// 合约创建的存款交易没有 to，mint 为空
+	deposit.To, deposit.Mint = nil, nil
+	raw, _ = deposit.MarshalBinary()
+	resp, _ = adaptor.DecodeTransaction(&account.DecodeTransactionRequest{RawTx: hexutil.Encode(raw)})
+	if decoded = decodeResponse(t, resp); decoded.To != "" || decoded.Mint != "0" {
+		t.Fatalf("unexpected decoded tx %+v", decoded)
+	}
+
+	resp, _ = adaptor.DecodeTransaction(&account.DecodeTransactionRequest{RawTx: "0x7e01"})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatalf("unexpected response %v", resp)
+	}
+}
+
+func Test_DecodeSignedTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(10)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(10),
+		Nonce:     7,
+		GasTipCap: big.NewInt(10),
+		GasFeeCap: big.NewInt(1000),
+		Gas:       21000,
+		To:        &testReceiver,
+		Value:     big.NewInt(42),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := tx.MarshalBinary()
+	resp, _ := newTestAdaptor(&fakeOpStack{}, false).DecodeTransaction(&account.DecodeTransactionRequest{RawTx: hexutil.Encode(raw)})
+	decoded := decodeResponse(t, resp)
+	if decoded.Type != types.DynamicFeeTxType || decoded.Hash != tx.Hash().Hex() || decoded.ChainId != "10" || decoded.Nonce != 7 ||
+		decoded.From != crypto.PubkeyToAddress(key.PublicKey).Hex() || decoded.Value != "42" || decoded.GasFeeCap != "1000" {
+		t.Fatalf("unexpected decoded tx %+v", decoded)
+	}
+}
+
+func decodeResponse(t *testing.T, resp *account.DecodeTransactionResponse) *DecodedTx {
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("unexpected response %v", resp)
+	}
+	txJson, _ := base64.StdEncoding.DecodeString(resp.Base64Tx)
+	var decoded DecodedTx
+	if err := json.Unmarshal(txJson, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return &decoded
+}
+
+func Test_GetBlockByNumber(t *testing.T) {
+	client := &fakeOpStack{block: &RpcBlock{
+		Hash:   common.HexToHash("0x01"),
+		Number: 100,
+		Transactions: []RpcTransaction{
+			{Type: DepositTxType, Hash: common.HexToHash("0x02"), From: L1InfoDepositorAddress, To: &GasPriceOracleAddress, SourceHash: &common.Hash{}},
+			{Type: DepositTxType, Hash: common.HexToHash("0x03"), From: testSender, To: &testReceiver, Value: (*hexutil.Big)(big.NewInt(5)), SourceHash: &testSourceHash},
+			{Type: types.DynamicFeeTxType, Hash: common.HexToHash("0x04"), From: testSender, To: &testReceiver, Value: (*hexutil.Big)(big.NewInt(6))},
+		},
+	}}
+	resp, _ := newTestAdaptor(client, false).GetBlockByNumber(&account.BlockNumberRequest{Height: 100})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Height != 100 || len(resp.Transactions) != 2 {
+		t.Fatalf("unexpected block %v", resp)
+	}
+	deposit, transfer := resp.Transactions[0], resp.Transactions[1]
+	if deposit.Memo != "" || deposit.L1Origin != global_const.L1OriginDeposit || deposit.L1OriginId != testSourceHash.Hex() || deposit.Amount != "5" || deposit.To != testReceiver.Hex() {
+		t.Fatalf("unexpected deposit %v", deposit)
+	}
+	if transfer.L1Origin != "" || transfer.Amount != "6" || transfer.Height != 100 {
+		t.Fatalf("unexpected transfer %v", transfer)
+	}
+}
+
+func Test_GetTxByHash(t *testing.T) {
+	token := common.HexToAddress("0x94b008aA00579c1307B0EF2c499aD98a8ce58e58")
+	transferHash, depositHash := common.HexToHash("0x05"), common.HexToHash("0x06")
+	client := &fakeOpStack{
+		txs: map[common.Hash]*RpcTransaction{
+			transferHash: {Type: types.DynamicFeeTxType, Hash: transferHash, From: testSender, To: &token, Value: (*hexutil.Big)(new(big.Int)),
+				Input: ethereum.BuildErc20Data(testReceiver, big.NewInt(700))},
+			depositHash: {Type: DepositTxType, Hash: depositHash, From: testSender, To: &testReceiver, Value: (*hexutil.Big)(big.NewInt(9)), SourceHash: &testSourceHash},
+		},
+		receipts: map[common.Hash]*RpcReceipt{
+			transferHash: {Status: 1, BlockNumber: (*hexutil.Big)(big.NewInt(100)), GasUsed: 50000, EffectiveGasPrice: (*hexutil.Big)(big.NewInt(1000)), L1Fee: (*hexutil.Big)(big.NewInt(123))},
+			depositHash:  {Status: 1, BlockNumber: (*hexutil.Big)(big.NewInt(101)), GasUsed: 50000, EffectiveGasPrice: (*hexutil.Big)(new(big.Int))},
+		},
+	}
+	adaptor := newTestAdaptor(client, false)
+
+	resp, _ := adaptor.GetTxByHash(&account.TxHashRequest{Hash: transferHash.Hex()})
+	tx := resp.Tx
+	if resp.Code != global_const.ReturnCode_SUCCESS || tx.Fee != "50000123" || tx.Status != account.TxStatus_Success ||
+		tx.To != testReceiver.Hex() || tx.Value != "700" || tx.ContractAddress != token.Hex() || tx.Height != "100" || tx.L1Origin != "" {
+		t.Fatalf("unexpected tx %v", tx)
+	}
+
+	resp, _ = adaptor.GetTxByHash(&account.TxHashRequest{Hash: depositHash.Hex()})
+	tx = resp.Tx
+	if tx.Type != DepositTxType || tx.Memo != "" || tx.L1Origin != global_const.L1OriginDeposit || tx.L1OriginId != testSourceHash.Hex() || tx.Fee != "0" || tx.Value != "9" ||
+		tx.ContractAddress != global_const.ZeroAddress {
+		t.Fatalf("unexpected deposit %v", tx)
+	}
+}
+
+func Test_OpClientRetry(t *testing.T) {
+	// OP 专有接口的请求和 EthClient 一样经过重试
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x01"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewOpClient(server.URL, 0, config.Rpc{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	result, err := client.CallContract(testReceiver, nil)
+	if err != nil || !bytes.Equal(result, []byte{1}) {
+		t.Fatalf("CallContract: %x %v", result, err)
+	}
+	if requests.Load() != 2 {
+		t.Fatalf("expected 1 retry, got %d requests", requests.Load())
+	}
+}
@@ -0,0 +1,70 @@
+package opstack
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// OP Stack 存款交易类型（L1→L2）
+const DepositTxType = 0x7E
+
+// 节点返回的交易，存款交易额外带有 sourceHash、mint、isSystemTx
+type RpcTransaction struct {
+	Type             hexutil.Uint64  `json:"type"`
+	Hash             common.Hash     `json:"hash"`
+	From             common.Address  `json:"from"`
+	To               *common.Address `json:"to"`
+	Value            *hexutil.Big    `json:"value"`
+	Input            hexutil.Bytes   `json:"input"`
+	Nonce            hexutil.Uint64  `json:"nonce"`
+	Gas              hexutil.Uint64  `json:"gas"`
+	BlockNumber      *hexutil.Big    `json:"blockNumber"`
+	TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
+	SourceHash       *common.Hash    `json:"sourceHash"`
+	Mint             *hexutil.Big    `json:"mint"`
+	IsSystemTx       bool            `json:"isSystemTx"`
+}
+
+// 是否为 L1→L2 存款交易
+func (tx *RpcTransaction) IsDeposit() bool {
+	return tx.Type == DepositTxType
+}
+
+// 包含完整交易的区块
+type RpcBlock struct {
+	Hash         common.Hash      `json:"hash"`
+	Number       hexutil.Uint64   `json:"number"`
+	BaseFee      string           `json:"baseFeePerGas"`
+	Transactions []RpcTransaction `json:"transactions"`
+}
+
+// 交易收据，l1Fee 为 L1 数据费，存款交易没有该字段
+type RpcReceipt struct {
+	Status            hexutil.Uint64  `json:"status"`
+	BlockNumber       *hexutil.Big    `json:"blockNumber"`
+	TransactionIndex  hexutil.Uint64  `json:"transactionIndex"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+	L1Fee             *hexutil.Big    `json:"l1Fee"`
+}
+
+// DecodeTransaction 返回的 base64_tx 解码后的结构
+type DecodedTx struct {
+	Type      uint8  `json:"type"`
+	Hash      string `json:"hash"`
+	ChainId   string `json:"chain_id,omitempty"`
+	Nonce     uint64 `json:"nonce"`
+	From      string `json:"from,omitempty"` // 未签名交易为空
+	To        string `json:"to,omitempty"`   // 合约创建交易为空
+	Value     string `json:"value"`
+	Gas       uint64 `json:"gas"`
+	GasPrice  string `json:"gas_price,omitempty"`
+	GasFeeCap string `json:"gas_fee_cap,omitempty"`
+	GasTipCap string `json:"gas_tip_cap,omitempty"`
+	Data      string `json:"data,omitempty"`
+	// 以下为存款交易字段
+	SourceHash string `json:"source_hash,omitempty"`
+	Mint       string `json:"mint,omitempty"`
+	IsSystemTx bool   `json:"is_system_tx,omitempty"`
+}
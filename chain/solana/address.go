@@ -0,0 +1,94 @@
+package solana
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"filippo.io/edwards25519"
+	"github.com/btcsuite/btcd/btcutil/base58"
+)
+
+// 系统程序地址
+const (
+	SystemProgram                 = "11111111111111111111111111111111"
+	TokenProgram                  = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	Token2022Program              = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
+	AssociatedTokenAccountProgram = "ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL"
+)
+
+type PublicKey [ed25519.PublicKeySize]byte
+
+func (p PublicKey) String() string {
+	return base58.Encode(p[:])
+}
+
+// 解析 base58 编码的地址
+func ParsePublicKey(address string) (PublicKey, error) {
+	var key PublicKey
+	decoded := base58.Decode(address)
+	if len(decoded) != len(key) {
+		return key, fmt.Errorf("invalid solana address: %s", address)
+	}
+	copy(key[:], decoded)
+	return key, nil
+}
+
+func mustPublicKey(address string) PublicKey {
+	key, err := ParsePublicKey(address)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// ed25519 公钥即地址，base58 编码
+func PubKeyToAddress(pubKeyHex string) (string, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(pubKeyHex, "0x"))
+	if err != nil {
+		return "", err
+	}
+	if len(pubKey) != ed25519.PublicKeySize {
+		return "", errors.New("invalid ed25519 public key")
+	}
+	return base58.Encode(pubKey), nil
+}
+
+// 地址为 32 字节的 base58 字符串，程序派生地址（PDA）不在曲线上也视为有效
+func ValidateAddress(address string) bool {
+	_, err := ParsePublicKey(address)
+	return err == nil
+}
+
+func isOnCurve(key []byte) bool {
+	_, err := new(edwards25519.Point).SetBytes(key)
+	return err == nil
+}
+
+// 计算程序派生地址，从 bump 255 开始寻找第一个不在曲线上的哈希
+func FindProgramAddress(seeds [][]byte, programId PublicKey) (PublicKey, uint8, error) {
+	for bump := 255; bump >= 0; bump-- {
+		var buf bytes.Buffer
+		for _, seed := range seeds {
+			buf.Write(seed)
+		}
+		buf.WriteByte(byte(bump))
+		buf.Write(programId[:])
+		buf.WriteString("ProgramDerivedAddress")
+		hash := sha256.Sum256(buf.Bytes())
+		if !isOnCurve(hash[:]) {
+			return hash, uint8(bump), nil
+		}
+	}
+	return PublicKey{}, 0, errors.New("unable to find program address")
+}
+
+// 关联代币账户（ATA）地址
+func AssociatedTokenAddress(owner, mint, tokenProgram PublicKey) (PublicKey, error) {
+	address, _, err := FindProgramAddress([][]byte{owner[:], tokenProgram[:], mint[:]}, mustPublicKey(AssociatedTokenAccountProgram))
+	return address, err
+}
@@ -0,0 +1,266 @@
+package solana
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+type AccountMeta struct {
+	PublicKey  PublicKey
+	IsSigner   bool
+	IsWritable bool
+}
+
+type Instruction struct {
+	ProgramId PublicKey
+	Accounts  []AccountMeta
+	Data      []byte
+}
+
+// legacy 消息，账户按 可写签名者、只读签名者、可写非签名者、只读非签名者 排序，第一个为手续费支付者
+type Message struct {
+	NumRequiredSignatures       uint8
+	NumReadonlySignedAccounts   uint8
+	NumReadonlyUnsignedAccounts uint8
+	AccountKeys                 []PublicKey
+	RecentBlockhash             PublicKey
+	Instructions                []compiledInstruction
+}
+
+type compiledInstruction struct {
+	ProgramIdIndex uint8
+	Accounts       []uint8
+	Data           []byte
+}
+
+// 编译指令为消息
+func NewMessage(feePayer PublicKey, instructions []Instruction, recentBlockhash PublicKey) (*Message, error) {
+	metas := []AccountMeta{{PublicKey: feePayer, IsSigner: true, IsWritable: true}}
+	index := map[PublicKey]int{feePayer: 0}
+	addMeta := func(meta AccountMeta) {
+		if i, ok := index[meta.PublicKey]; ok {
+			metas[i].IsSigner = metas[i].IsSigner || meta.IsSigner
+			metas[i].IsWritable = metas[i].IsWritable || meta.IsWritable
+			return
+		}
+		index[meta.PublicKey] = len(metas)
+		metas = append(metas, meta)
+	}
+	for _, instruction := range instructions {
+		for _, meta := range instruction.Accounts {
+			addMeta(meta)
+		}
+		addMeta(AccountMeta{PublicKey: instruction.ProgramId})
+	}
+
+	// 按权限分组，组内保持出现顺序
+	msg := &Message{RecentBlockhash: recentBlockhash}
+	for _, group := range [4][2]bool{{true, true}, {true, false}, {false, true}, {false, false}} {
+		for _, meta := range metas {
+			if meta.IsSigner != group[0] || meta.IsWritable != group[1] {
+				continue
+			}
+			msg.AccountKeys = append(msg.AccountKeys, meta.PublicKey)
+			switch {
+			case meta.IsSigner:
+				msg.NumRequiredSignatures++
+				if !meta.IsWritable {
+					msg.NumReadonlySignedAccounts++
+				}
+			case !meta.IsWritable:
+				msg.NumReadonlyUnsignedAccounts++
+			}
+		}
+	}
+	if len(msg.AccountKeys) > 256 {
+		return nil, errors.New("too many accounts")
+	}
+	for i, key := range msg.AccountKeys {
+		index[key] = i
+	}
+
+	for _, instruction := range instructions {
+		compiled := compiledInstruction{ProgramIdIndex: uint8(index[instruction.ProgramId]), Data: instruction.Data}
+		for _, meta := range instruction.Accounts {
+			compiled.Accounts = append(compiled.Accounts, uint8(index[meta.PublicKey]))
+		}
+		msg.Instructions = append(msg.Instructions, compiled)
+	}
+	return msg, nil
+}
+
+// 需要签名的账户
+func (m *Message) Signers() []PublicKey {
+	return m.AccountKeys[:m.NumRequiredSignatures]
+}
+
+func (m *Message) Serialize() []byte {
+	buf := []byte{m.NumRequiredSignatures, m.NumReadonlySignedAccounts, m.NumReadonlyUnsignedAccounts}
+	buf = appendCompactU16(buf, len(m.AccountKeys))
+	for _, key := range m.AccountKeys {
+		buf = append(buf, key[:]...)
+	}
+	buf = append(buf, m.RecentBlockhash[:]...)
+	buf = appendCompactU16(buf, len(m.Instructions))
+	for _, instruction := range m.Instructions {
+		buf = append(buf, instruction.ProgramIdIndex)
+		buf = appendCompactU16(buf, len(instruction.Accounts))
+		buf = append(buf, instruction.Accounts...)
+		buf = appendCompactU16(buf, len(instruction.Data))
+		buf = append(buf, instruction.Data...)
+	}
+	return buf
+}
+
+// 解析 legacy 消息，不支持 v0 版本消息
+func DeserializeMessage(b []byte) (*Message, error) {
+	if len(b) < 3 {
+		return nil, errors.New("message too short")
+	}
+	if b[0]&0x80 != 0 {
+		return nil, errors.New("versioned message is not supported")
+	}
+	msg := &Message{NumRequiredSignatures: b[0], NumReadonlySignedAccounts: b[1], NumReadonlyUnsignedAccounts: b[2]}
+	r := &reader{buf: b[3:]}
+	numKeys := r.compactU16()
+	for i := 0; i < numKeys; i++ {
+		var key PublicKey
+		copy(key[:], r.bytes(len(key)))
+		msg.AccountKeys = append(msg.AccountKeys, key)
+	}
+	copy(msg.RecentBlockhash[:], r.bytes(len(msg.RecentBlockhash)))
+	numInstructions := r.compactU16()
+	for i := 0; i < numInstructions; i++ {
+		var instruction compiledInstruction
+		if programIdIndex := r.bytes(1); len(programIdIndex) == 1 {
+			instruction.ProgramIdIndex = programIdIndex[0]
+		}
+		instruction.Accounts = r.bytes(r.compactU16())
+		instruction.Data = r.bytes(r.compactU16())
+		msg.Instructions = append(msg.Instructions, instruction)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if len(r.buf) != 0 {
+		return nil, errors.New("unexpected trailing bytes")
+	}
+	if int(msg.NumRequiredSignatures) > len(msg.AccountKeys) || msg.NumRequiredSignatures == 0 {
+		return nil, errors.New("invalid message header")
+	}
+	for _, instruction := range msg.Instructions {
+		if int(instruction.ProgramIdIndex) >= len(msg.AccountKeys) {
+			return nil, errors.New("invalid program id index")
+		}
+		for _, account := range instruction.Accounts {
+			if int(account) >= len(msg.AccountKeys) {
+				return nil, errors.New("invalid account index")
+			}
+		}
+	}
+	return msg, nil
+}
+
+// 签名交易：签名数量 + 签名 + 消息
+func SerializeTransaction(signatures [][]byte, message []byte) []byte {
+	buf := appendCompactU16(nil, len(signatures))
+	for _, signature := range signatures {
+		buf = append(buf, signature...)
+	}
+	return append(buf, message...)
+}
+
+func appendCompactU16(buf []byte, n int) []byte {
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(buf, b)
+		}
+		buf = append(buf, b|0x80)
+	}
+}
+
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.buf) {
+		r.err = fmt.Errorf("unexpected end of message")
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *reader) compactU16() int {
+	var n int
+	for i := 0; i < 3; i++ {
+		b := r.bytes(1)
+		if len(b) == 0 {
+			return 0
+		}
+		n |= int(b[0]&0x7f) << (7 * i)
+		if b[0]&0x80 == 0 {
+			return n
+		}
+	}
+	r.err = errors.New("invalid compact-u16")
+	return 0
+}
+
+// 系统程序转账指令
+func systemTransfer(from, to PublicKey, lamports uint64) Instruction {
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data, 2)
+	binary.LittleEndian.PutUint64(data[4:], lamports)
+	return Instruction{
+		ProgramId: mustPublicKey(SystemProgram),
+		Accounts: []AccountMeta{
+			{PublicKey: from, IsSigner: true, IsWritable: true},
+			{PublicKey: to, IsWritable: true},
+		},
+		Data: data,
+	}
+}
+
+// 创建关联代币账户，已存在时不报错（CreateIdempotent）
+func createAssociatedTokenAccountIdempotent(payer, ata, owner, mint, tokenProgram PublicKey) Instruction {
+	return Instruction{
+		ProgramId: mustPublicKey(AssociatedTokenAccountProgram),
+		Accounts: []AccountMeta{
+			{PublicKey: payer, IsSigner: true, IsWritable: true},
+			{PublicKey: ata, IsWritable: true},
+			{PublicKey: owner},
+			{PublicKey: mint},
+			{PublicKey: mustPublicKey(SystemProgram)},
+			{PublicKey: tokenProgram},
+		},
+		Data: []byte{1},
+	}
+}
+
+// 代币 TransferChecked 指令，同时校验精度和 mint
+func tokenTransferChecked(source, mint, destination, owner, tokenProgram PublicKey, amount uint64, decimals uint8) Instruction {
+	data := make([]byte, 10)
+	data[0] = 12
+	binary.LittleEndian.PutUint64(data[1:], amount)
+	data[9] = decimals
+	return Instruction{
+		ProgramId: tokenProgram,
+		Accounts: []AccountMeta{
+			{PublicKey: source, IsWritable: true},
+			{PublicKey: mint},
+			{PublicKey: destination, IsWritable: true},
+			{PublicKey: owner, IsSigner: true},
+		},
+		Data: data,
+	}
+}
@@ -0,0 +1,559 @@
+package solana
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Solana"
+
+// 每次 getBlocks 查询的最大槽位数
+const blockRangeLimit = 1000
+
+type ChainAdaptor struct {
+	SolClient ISol
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.Sol
+	solClient, err := NewSolClient(node.RpcUrl, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		SolClient: solClient,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入 ed25519 公钥 转换成地址
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeEd25519); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	address, err := PubKeyToAddress(req.PublicKey)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address,
+	}, nil
+}
+
+// 地址格式验证
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 通过槽位获取区块数据，height 为槽位号
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	block, err := c.SolClient.GetBlock(uint64(req.Height), true)
+	if err != nil {
+		log.Error("get block by number fail", "slot", req.Height, "err", err)
+		msg := "get block by number fail"
+		if IsSlotSkipped(err) {
+			msg = "slot skipped"
+		}
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  msg,
+		}, nil
+	}
+	var blockTxList []*account.BlockInfoTransactionList
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		if tx.Meta == nil || tx.Meta.Err != nil || len(tx.Transaction.Signatures) == 0 {
+			continue
+		}
+		for _, transfer := range parseTransfers(tx) {
+			blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+				From:         transfer.From,
+				To:           transfer.To,
+				TokenAddress: transfer.ContractAddress,
+				Hash:         tx.Transaction.Signatures[0],
+				Height:       uint64(req.Height),
+				Amount:       transfer.Amount,
+			})
+		}
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          "get block by number success",
+		Height:       req.Height,
+		Hash:         block.Blockhash,
+		Transactions: blockTxList,
+	}, nil
+}
+
+// Solana 节点不支持按区块哈希查询
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return &account.BlockResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block by hash is not supported",
+	}, nil
+}
+
+// 通过槽位获取区块头信息，height 为 0 时返回最新槽位
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	slot := uint64(req.Height)
+	if slot == 0 {
+		latest, err := c.SolClient.GetSlot()
+		if err != nil {
+			log.Error("get slot fail", "err", err)
+			return &account.BlockHeaderResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block header by number fail",
+			}, nil
+		}
+		slot = latest
+	}
+	block, err := c.SolClient.GetBlock(slot, false)
+	if err != nil {
+		log.Error("get block header by number fail", "slot", slot, "err", err)
+		msg := "get block header by number fail"
+		if IsSlotSkipped(err) {
+			msg = "slot skipped"
+		}
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  msg,
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(slot, block),
+	}, nil
+}
+
+// Solana 节点不支持按区块哈希查询
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	return &account.BlockHeaderResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block header by hash is not supported",
+	}, nil
+}
+
+// 获取区间内的区块头，跳过没有出块的槽位
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseUint(req.Start, 10, 64)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseUint(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for from := start; from <= end; from += blockRangeLimit {
+		to := min(from+blockRangeLimit-1, end)
+		slots, err := c.SolClient.GetBlocks(from, to)
+		if err != nil {
+			log.Error("get blocks fail", "start", from, "end", to, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		for _, slot := range slots {
+			block, err := c.SolClient.GetBlock(slot, false)
+			if err != nil {
+				log.Error("get block fail", "slot", slot, "err", err)
+				return &account.BlockByRangeResponse{
+					Code: global_const.ReturnCode_ERROR,
+					Msg:  "get block range fail",
+				}, nil
+			}
+			headers = append(headers, toBlockHeader(slot, block))
+		}
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取账户余额，contract_address 不为空时查询该 mint 在关联代币账户中的余额
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	owner, err := ParsePublicKey(req.Address)
+	if err != nil {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	var balance string
+	if req.ContractAddress != "" {
+		mint, err := ParsePublicKey(req.ContractAddress)
+		if err != nil {
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid contract address",
+			}, nil
+		}
+		balance, err = c.tokenBalance(owner, mint)
+		if err != nil {
+			log.Error("get token balance fail", "address", req.Address, "mint", req.ContractAddress, "err", err)
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get account fail",
+			}, nil
+		}
+	} else {
+		lamports, err := c.SolClient.GetBalance(req.Address)
+		if err != nil {
+			log.Error("get balance fail", "address", req.Address, "err", err)
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get account fail",
+			}, nil
+		}
+		balance = formatUint(lamports)
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Balance:       balance,
+	}, nil
+}
+
+// 关联代币账户不存在时余额为 0
+func (c *ChainAdaptor) tokenBalance(owner, mint PublicKey) (string, error) {
+	tokenProgram, _, err := c.mintInfo(mint)
+	if err != nil {
+		return "", err
+	}
+	ata, err := AssociatedTokenAddress(owner, mint, tokenProgram)
+	if err != nil {
+		return "", err
+	}
+	info, err := c.SolClient.GetAccountInfo(ata.String())
+	if err != nil {
+		return "", err
+	}
+	if info == nil {
+		return "0", nil
+	}
+	amount, err := c.SolClient.GetTokenAccountBalance(ata.String())
+	if err != nil {
+		return "", err
+	}
+	return amount.Amount, nil
+}
+
+// 获取fee，单位 lamports，通过 getFeeForMessage 计算基础手续费（按签名数收取），不包含优先费。
+// 传入 rawTx（BuildUnSignTransaction 返回的 base64 消息）时计算该消息的手续费，否则按一笔 SOL 转账计算，三档返回相同结果
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	message := req.RawTx
+	if message == "" {
+		payer := mustPublicKey(SystemProgram)
+		if req.Address != "" {
+			var err error
+			if payer, err = ParsePublicKey(req.Address); err != nil {
+				return &account.FeeResponse{
+					Code: global_const.ReturnCode_ERROR,
+					Msg:  "invalid address",
+				}, nil
+			}
+		}
+		blockhashStr, err := c.SolClient.GetLatestBlockhash()
+		if err != nil {
+			log.Error("get latest blockhash fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get fee fail",
+			}, nil
+		}
+		blockhash, err := ParsePublicKey(blockhashStr)
+		if err != nil {
+			log.Error("invalid blockhash from node", "blockhash", blockhashStr)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get fee fail",
+			}, nil
+		}
+		msg, err := NewMessage(payer, []Instruction{systemTransfer(payer, payer, 1)}, blockhash)
+		if err != nil {
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get fee fail",
+			}, nil
+		}
+		message = base64.StdEncoding.EncodeToString(msg.Serialize())
+	}
+	fee, err := c.SolClient.GetFeeForMessage(message)
+	if err != nil {
+		log.Error("get fee for message fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get fee fail",
+		}, nil
+	}
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   formatUint(fee),
+		NormalFee: formatUint(fee),
+		FastFee:   formatUint(fee),
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的 base64 交易
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	txHash, err := c.SolClient.SendTransaction(req.RawTx)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: txHash,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get tx by address is not supported",
+	}, nil
+}
+
+// 通过交易签名获取交易，解析 SOL 和 SPL 代币转账（包含内部指令），全部转账以 JSON 写入 data
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	tx, err := c.SolClient.GetTransaction(req.Hash)
+	if err != nil {
+		log.Error("get transaction fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	if tx == nil {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+
+	txMessage := &account.TxMessage{
+		Hash:   req.Hash,
+		Value:  "0",
+		Status: account.TxStatus_Success,
+		Height: formatUint(tx.Slot),
+	}
+	if accountKeys := tx.Transaction.Message.AccountKeys; len(accountKeys) > 0 {
+		txMessage.From = accountKeys[0].Pubkey
+	}
+	if tx.BlockTime != nil {
+		txMessage.Datetime = strconv.FormatInt(*tx.BlockTime, 10)
+	}
+	if tx.Meta != nil {
+		txMessage.Fee = formatUint(tx.Meta.Fee)
+		if tx.Meta.Err != nil {
+			txMessage.Status = account.TxStatus_Failed
+		}
+	}
+	transfers := parseTransfers(&tx.TransactionWithMeta)
+	if len(transfers) > 0 {
+		txMessage.From = transfers[0].From
+		txMessage.To = transfers[0].To
+		txMessage.Value = transfers[0].Amount
+		txMessage.ContractAddress = transfers[0].ContractAddress
+		if transfers[0].ContractAddress != "" {
+			txMessage.Type = 1
+		}
+		data, _ := json.Marshal(transfers)
+		txMessage.Data = string(data)
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   txMessage,
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+func formatUint(n uint64) string {
+	return strconv.FormatUint(n, 10)
+}
+
+// 交易签名即交易哈希，base58 编码
+func encodeSignature(signature []byte) string {
+	return base58.Encode(signature)
+}
+
+func toBlockHeader(slot uint64, block *Block) *account.BlockHeader {
+	header := &account.BlockHeader{
+		Hash:       block.Blockhash,
+		ParentHash: block.PreviousBlockhash,
+		Number:     formatUint(slot),
+	}
+	if block.BlockTime != nil {
+		header.Time = uint64(*block.BlockTime)
+	}
+	return header
+}
+
+// jsonParsed 指令中的转账参数
+type transferInfo struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Lamports    uint64 `json:"lamports"`
+	Amount      string `json:"amount"`
+	Mint        string `json:"mint"`
+	Authority   string `json:"authority"`
+	TokenAmount struct {
+		Amount string `json:"amount"`
+	} `json:"tokenAmount"`
+}
+
+type tokenAccount struct {
+	owner string
+	mint  string
+}
+
+// 按执行顺序解析交易中的 SOL 和 SPL 代币转账，代币账户通过交易前后的代币余额映射到持有者
+func parseTransfers(tx *TransactionWithMeta) []Transfer {
+	accountKeys := tx.Transaction.Message.AccountKeys
+	tokenAccounts := map[string]tokenAccount{}
+	instructions := [][]ParsedInstruction{}
+	for _, instruction := range tx.Transaction.Message.Instructions {
+		instructions = append(instructions, []ParsedInstruction{instruction})
+	}
+	if tx.Meta != nil {
+		for _, balances := range [][]TokenBalance{tx.Meta.PreTokenBalances, tx.Meta.PostTokenBalances} {
+			for _, balance := range balances {
+				if balance.AccountIndex < len(accountKeys) {
+					tokenAccounts[accountKeys[balance.AccountIndex].Pubkey] = tokenAccount{owner: balance.Owner, mint: balance.Mint}
+				}
+			}
+		}
+		for _, inner := range tx.Meta.InnerInstructions {
+			if inner.Index < len(instructions) {
+				instructions[inner.Index] = append(instructions[inner.Index], inner.Instructions...)
+			}
+		}
+	}
+
+	var transfers []Transfer
+	for _, group := range instructions {
+		for _, instruction := range group {
+			if instruction.Parsed == nil {
+				continue
+			}
+			var info transferInfo
+			if err := json.Unmarshal(instruction.Parsed.Info, &info); err != nil {
+				continue
+			}
+			switch {
+			case instruction.Program == "system" && (instruction.Parsed.Type == "transfer" || instruction.Parsed.Type == "transferWithSeed"):
+				transfers = append(transfers, Transfer{
+					From:   info.Source,
+					To:     info.Destination,
+					Amount: formatUint(info.Lamports),
+				})
+			case instruction.Program == "spl-token" && (instruction.Parsed.Type == "transfer" || instruction.Parsed.Type == "transferChecked"):
+				source, destination := tokenAccounts[info.Source], tokenAccounts[info.Destination]
+				transfer := Transfer{
+					ContractAddress: info.Mint,
+					From:            source.owner,
+					To:              destination.owner,
+					Amount:          info.Amount,
+				}
+				if instruction.Parsed.Type == "transferChecked" {
+					transfer.Amount = info.TokenAmount.Amount
+				}
+				if transfer.ContractAddress == "" {
+					transfer.ContractAddress = source.mint
+				}
+				if transfer.From == "" {
+					transfer.From = info.Authority
+				}
+				if transfer.To == "" {
+					transfer.To = info.Destination
+				}
+				transfers = append(transfers, transfer)
+			}
+		}
+	}
+	return transfers
+}
@@ -0,0 +1,245 @@
+package solana
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// USDC mint 地址
+const usdcMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+const testBlockhash = "EkSnNWid2cvwEVnVx9aBqawnmiCNiDgp3gUdkDPTKN1N"
+
+// 模拟节点，accounts 中不存在的账户视为未创建
+type fakeSolNode struct {
+	ISol
+	accounts map[string]*AccountInfo
+}
+
+func (f *fakeSolNode) GetLatestBlockhash() (string, error) {
+	return testBlockhash, nil
+}
+
+func (f *fakeSolNode) GetAccountInfo(address string) (*AccountInfo, error) {
+	return f.accounts[address], nil
+}
+
+func newMintAccount(decimals uint8) *AccountInfo {
+	data := make([]byte, 82)
+	data[mintDecimalsOffset] = decimals
+	return &AccountInfo{Owner: TokenProgram, Data: data}
+}
+
+func newKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+}
+
+func publicKeyOf(key ed25519.PrivateKey) PublicKey {
+	var pubKey PublicKey
+	copy(pubKey[:], key.Public().(ed25519.PublicKey))
+	return pubKey
+}
+
+func buildUnSigned(t *testing.T, adaptor *ChainAdaptor, transferTx SolTransferTx) *account.UnSignTransactionResponse {
+	txJson, _ := json.Marshal(transferTx)
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	return resp
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	adaptor := &ChainAdaptor{}
+	// 全 0 公钥即系统程序地址
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: hex.EncodeToString(make([]byte, 32))})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Address != SystemProgram {
+		t.Fatalf("unexpected address %s", resp.Address)
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: hex.EncodeToString(make([]byte, 32)), KeyType: chain.KeyTypeSecp256k1})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected secp256k1 key type to be rejected")
+	}
+	if !ValidateAddress(usdcMint) || ValidateAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf") {
+		t.Fatal("unexpected address validation result")
+	}
+}
+
+func Test_AssociatedTokenAddress(t *testing.T) {
+	owner := publicKeyOf(newKey(1))
+	if !isOnCurve(owner[:]) {
+		t.Fatal("expected wallet address to be on curve")
+	}
+	ata, err := AssociatedTokenAddress(owner, mustPublicKey(usdcMint), mustPublicKey(TokenProgram))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isOnCurve(ata[:]) {
+		t.Fatal("expected associated token address to be off curve")
+	}
+	ata2022, _ := AssociatedTokenAddress(owner, mustPublicKey(usdcMint), mustPublicKey(Token2022Program))
+	if ata == ata2022 {
+		t.Fatal("expected different address for token-2022 program")
+	}
+}
+
+func Test_BuildSolTransfer(t *testing.T) {
+	key := newKey(1)
+	from, to := publicKeyOf(key), publicKeyOf(newKey(2))
+	adaptor := &ChainAdaptor{SolClient: &fakeSolNode{}}
+	unSigned := buildUnSigned(t, adaptor, SolTransferTx{FromAddress: from.String(), ToAddress: to.String(), Amount: "1000000"})
+
+	serialized, _ := base64.StdEncoding.DecodeString(unSigned.UnSignTx)
+	msg, err := DeserializeMessage(serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.NumRequiredSignatures != 1 || msg.NumReadonlySignedAccounts != 0 || msg.NumReadonlyUnsignedAccounts != 1 {
+		t.Fatalf("unexpected header %d %d %d", msg.NumRequiredSignatures, msg.NumReadonlySignedAccounts, msg.NumReadonlyUnsignedAccounts)
+	}
+	if len(msg.AccountKeys) != 3 || msg.AccountKeys[0] != from || msg.AccountKeys[1] != to || msg.AccountKeys[2].String() != SystemProgram {
+		t.Fatal("unexpected account keys")
+	}
+	if msg.RecentBlockhash.String() != testBlockhash || !bytes.Equal(msg.Serialize(), serialized) {
+		t.Fatal("expected message to round trip")
+	}
+	// transfer 指令：u32 2 + u64 lamports
+	if hex.EncodeToString(msg.Instructions[0].Data) != "0200000040420f0000000000" {
+		t.Fatalf("unexpected instruction data %x", msg.Instructions[0].Data)
+	}
+
+	// 签名与签名者不匹配时拒绝
+	wrong := hex.EncodeToString(ed25519.Sign(newKey(2), serialized))
+	rejected, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signature: wrong})
+	if rejected.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected signature from other key to be rejected")
+	}
+
+	signHash, _ := hex.DecodeString(unSigned.SignHashes[0])
+	signature := ed25519.Sign(key, signHash)
+	signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signature: hex.EncodeToString(signature)})
+	if signed.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", signed.Msg)
+	}
+	rawTx, _ := base64.StdEncoding.DecodeString(signed.SignedTx)
+	if rawTx[0] != 1 || !bytes.Equal(rawTx[1:65], signature) || !bytes.Equal(rawTx[65:], serialized) {
+		t.Fatal("unexpected signed transaction")
+	}
+	if signed.Msg != encodeSignature(signature) {
+		t.Fatalf("unexpected tx hash %s", signed.Msg)
+	}
+}
+
+func Test_BuildSplTransfer(t *testing.T) {
+	key, feePayerKey := newKey(1), newKey(3)
+	from, to, feePayer := publicKeyOf(key), publicKeyOf(newKey(2)), publicKeyOf(feePayerKey)
+	mint := mustPublicKey(usdcMint)
+	tokenProgram := mustPublicKey(TokenProgram)
+	destination, _ := AssociatedTokenAddress(to, mint, tokenProgram)
+	node := &fakeSolNode{accounts: map[string]*AccountInfo{usdcMint: newMintAccount(6)}}
+	adaptor := &ChainAdaptor{SolClient: node}
+	transferTx := SolTransferTx{FromAddress: from.String(), ToAddress: to.String(), Amount: "2500000", ContractAddress: usdcMint, FeePayer: feePayer.String()}
+
+	// 接收方没有关联代币账户时先创建
+	unSigned := buildUnSigned(t, adaptor, transferTx)
+	serialized, _ := base64.StdEncoding.DecodeString(unSigned.UnSignTx)
+	msg, err := DeserializeMessage(serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Instructions) != 2 || len(unSigned.SignHashes) != 2 {
+		t.Fatalf("expected create account and transfer instructions, got %d", len(msg.Instructions))
+	}
+	if msg.AccountKeys[0] != feePayer || msg.AccountKeys[1] != from || msg.NumReadonlySignedAccounts != 1 {
+		t.Fatal("expected fee payer first and owner as readonly signer")
+	}
+	if msg.AccountKeys[msg.Instructions[0].ProgramIdIndex].String() != AssociatedTokenAccountProgram ||
+		msg.AccountKeys[msg.Instructions[0].Accounts[1]] != destination {
+		t.Fatal("unexpected create associated token account instruction")
+	}
+	transfer := msg.Instructions[1]
+	if msg.AccountKeys[transfer.ProgramIdIndex] != tokenProgram || hex.EncodeToString(transfer.Data) != "0ca025260000000000"+"06" {
+		t.Fatalf("unexpected transfer instruction %x", transfer.Data)
+	}
+
+	// 按签名者顺序提供签名
+	signatures := []string{
+		hex.EncodeToString(ed25519.Sign(feePayerKey, serialized)),
+		hex.EncodeToString(ed25519.Sign(key, serialized)),
+	}
+	swapped, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signatures: []string{signatures[1], signatures[0]}})
+	if swapped.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected signatures in wrong order to be rejected")
+	}
+	signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signatures: signatures})
+	if signed.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", signed.Msg)
+	}
+
+	// 关联代币账户已存在时只转账
+	node.accounts[destination.String()] = &AccountInfo{Owner: TokenProgram}
+	unSigned = buildUnSigned(t, adaptor, transferTx)
+	serialized, _ = base64.StdEncoding.DecodeString(unSigned.UnSignTx)
+	msg, _ = DeserializeMessage(serialized)
+	if len(msg.Instructions) != 1 {
+		t.Fatalf("expected single transfer instruction, got %d", len(msg.Instructions))
+	}
+
+	// 非代币 mint 拒绝
+	node.accounts[usdcMint] = &AccountInfo{Owner: SystemProgram}
+	txJson, _ := json.Marshal(transferTx)
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected non token mint to be rejected")
+	}
+}
+
+func Test_ParseTransfers(t *testing.T) {
+	// 一笔 SPL transferChecked，接收方代币账户在同一交易中创建
+	raw := `{
+		"meta": {
+			"err": null,
+			"fee": 5000,
+			"preTokenBalances": [{"accountIndex": 1, "mint": "` + usdcMint + `", "owner": "owner1", "uiTokenAmount": {"amount": "100", "decimals": 6}}],
+			"postTokenBalances": [
+				{"accountIndex": 1, "mint": "` + usdcMint + `", "owner": "owner1", "uiTokenAmount": {"amount": "40", "decimals": 6}},
+				{"accountIndex": 2, "mint": "` + usdcMint + `", "owner": "owner2", "uiTokenAmount": {"amount": "60", "decimals": 6}}
+			],
+			"innerInstructions": [{"index": 0, "instructions": [
+				{"program": "system", "programId": "11111111111111111111111111111111", "parsed": {"type": "transfer", "info": {"source": "payer", "destination": "ata2", "lamports": 2039280}}}
+			]}]
+		},
+		"transaction": {
+			"signatures": ["sig"],
+			"message": {
+				"accountKeys": [{"pubkey": "payer"}, {"pubkey": "ata1"}, {"pubkey": "ata2"}],
+				"instructions": [
+					{"program": "spl-associated-token-account", "programId": "ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL", "parsed": {"type": "createIdempotent", "info": {}}},
+					{"program": "spl-token", "programId": "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA", "parsed": {"type": "transferChecked", "info": {"source": "ata1", "destination": "ata2", "mint": "` + usdcMint + `", "authority": "owner1", "tokenAmount": {"amount": "60"}}}}
+				]
+			}
+		}
+	}`
+	var tx TransactionWithMeta
+	if err := json.Unmarshal([]byte(raw), &tx); err != nil {
+		t.Fatal(err)
+	}
+	transfers := parseTransfers(&tx)
+	if len(transfers) != 2 {
+		t.Fatalf("expected 2 transfers, got %d", len(transfers))
+	}
+	if transfers[0].From != "payer" || transfers[0].To != "ata2" || transfers[0].Amount != "2039280" || transfers[0].ContractAddress != "" {
+		t.Fatalf("unexpected sol transfer %+v", transfers[0])
+	}
+	if transfers[1].From != "owner1" || transfers[1].To != "owner2" || transfers[1].Amount != "60" || transfers[1].ContractAddress != usdcMint {
+		t.Fatalf("unexpected token transfer %+v", transfers[1])
+	}
+}
@@ -0,0 +1,360 @@
+package solana
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// 槽位被跳过或区块不可用时节点返回的错误码
+const (
+	errCodeBlockCleanedUp         = -32001
+	errCodeSlotSkipped            = -32007
+	errCodeLongTermStorageSkipped = -32009
+	errCodeBlockNotAvailable      = -32004
+)
+
+// Solana JSON-RPC 错误
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// 槽位没有出块（被跳过或已被清理）
+func IsSlotSkipped(err error) bool {
+	var rpcErr *RpcError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	switch rpcErr.Code {
+	case errCodeBlockCleanedUp, errCodeSlotSkipped, errCodeLongTermStorageSkipped, errCodeBlockNotAvailable:
+		return true
+	}
+	return false
+}
+
+type Block struct {
+	Blockhash         string                `json:"blockhash"`
+	PreviousBlockhash string                `json:"previousBlockhash"`
+	ParentSlot        uint64                `json:"parentSlot"`
+	BlockHeight       *uint64               `json:"blockHeight"`
+	BlockTime         *int64                `json:"blockTime"`
+	Transactions      []TransactionWithMeta `json:"transactions"`
+}
+
+type TransactionMeta struct {
+	Err               any            `json:"err"`
+	Fee               uint64         `json:"fee"`
+	PreTokenBalances  []TokenBalance `json:"preTokenBalances"`
+	PostTokenBalances []TokenBalance `json:"postTokenBalances"`
+	InnerInstructions []struct {
+		Index        int                 `json:"index"`
+		Instructions []ParsedInstruction `json:"instructions"`
+	} `json:"innerInstructions"`
+}
+
+// jsonParsed 编码的交易
+type TransactionWithMeta struct {
+	Meta        *TransactionMeta `json:"meta"`
+	Transaction struct {
+		Signatures []string `json:"signatures"`
+		Message    struct {
+			AccountKeys []struct {
+				Pubkey   string `json:"pubkey"`
+				Signer   bool   `json:"signer"`
+				Writable bool   `json:"writable"`
+			} `json:"accountKeys"`
+			Instructions []ParsedInstruction `json:"instructions"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
+
+type Transaction struct {
+	Slot      uint64 `json:"slot"`
+	BlockTime *int64 `json:"blockTime"`
+	TransactionWithMeta
+}
+
+// 代币账户余额，accountIndex 为账户在 accountKeys 中的位置
+type TokenBalance struct {
+	AccountIndex  int    `json:"accountIndex"`
+	Mint          string `json:"mint"`
+	Owner         string `json:"owner"`
+	UiTokenAmount struct {
+		Amount   string `json:"amount"`
+		Decimals uint8  `json:"decimals"`
+	} `json:"uiTokenAmount"`
+}
+
+// 节点能解析的指令 parsed 为 {type, info}，否则为空
+type ParsedInstruction struct {
+	Program   string `json:"program"`
+	ProgramId string `json:"programId"`
+	Parsed    *struct {
+		Type string          `json:"type"`
+		Info json.RawMessage `json:"info"`
+	} `json:"parsed"`
+}
+
+// 账户信息，data 为原始字节
+type AccountInfo struct {
+	Lamports   uint64
+	Owner      string
+	Executable bool
+	Data       []byte
+}
+
+type TokenAmount struct {
+	Amount   string `json:"amount"`
+	Decimals uint8  `json:"decimals"`
+}
+
+type rpcRequest struct {
+	JsonRpc string `json:"jsonrpc"`
+	Id      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RpcError       `json:"error"`
+	Id     uint64          `json:"id"`
+}
+
+// 定义 Solana 节点接口
+type ISol interface {
+	// 区块数据相关
+	GetSlot() (uint64, error)
+	GetBlock(slot uint64, withTransactions bool) (*Block, error)
+	GetBlocks(start, end uint64) ([]uint64, error)
+	// 账户
+	GetBalance(address string) (uint64, error)
+	GetAccountInfo(address string) (*AccountInfo, error)
+	GetTokenAccountBalance(address string) (*TokenAmount, error)
+	// 交易
+	GetLatestBlockhash() (string, error)
+	GetFeeForMessage(message string) (uint64, error)
+	SendTransaction(rawTx string) (string, error)
+	GetTransaction(signature string) (*Transaction, error)
+}
+
+// 定义 Solana JSON-RPC 客户端，承诺级别统一使用 confirmed
+type SolClient struct {
+	url    string
+	client *http.Client
+	nextId atomic.Uint64
+}
+
+func NewSolClient(rpcUrl string, timeout time.Duration) (ISol, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &SolClient{
+		url:    rpcUrl,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+var commitment = map[string]any{"commitment": "confirmed"}
+
+// 调用 JSON-RPC 方法并解析结果
+func (s *SolClient) call(result any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(&rpcRequest{
+		JsonRpc: "2.0",
+		Id:      s.nextId.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("call %s fail, status %d: %s", method, resp.StatusCode, string(respBody))
+	}
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return err
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// 获取最新槽位
+func (s *SolClient) GetSlot() (uint64, error) {
+	var slot uint64
+	err := s.call(&slot, "getSlot", commitment)
+	return slot, err
+}
+
+// 获取区块，withTransactions 为 false 时只返回区块头
+func (s *SolClient) GetBlock(slot uint64, withTransactions bool) (*Block, error) {
+	details := "full"
+	if !withTransactions {
+		details = "none"
+	}
+	block := new(Block)
+	err := s.call(block, "getBlock", slot, map[string]any{
+		"commitment":                     "confirmed",
+		"encoding":                       "jsonParsed",
+		"transactionDetails":             details,
+		"rewards":                        false,
+		"maxSupportedTransactionVersion": 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// 获取 [start, end] 区间内已出块的槽位
+func (s *SolClient) GetBlocks(start, end uint64) ([]uint64, error) {
+	var slots []uint64
+	err := s.call(&slots, "getBlocks", start, end, commitment)
+	return slots, err
+}
+
+// 获取 SOL 余额，单位 lamports
+func (s *SolClient) GetBalance(address string) (uint64, error) {
+	var result struct {
+		Value uint64 `json:"value"`
+	}
+	err := s.call(&result, "getBalance", address, commitment)
+	return result.Value, err
+}
+
+// 获取账户信息，账户不存在时返回 nil
+func (s *SolClient) GetAccountInfo(address string) (*AccountInfo, error) {
+	var result struct {
+		Value *struct {
+			Lamports   uint64    `json:"lamports"`
+			Owner      string    `json:"owner"`
+			Executable bool      `json:"executable"`
+			Data       [2]string `json:"data"`
+		} `json:"value"`
+	}
+	err := s.call(&result, "getAccountInfo", address, map[string]any{"commitment": "confirmed", "encoding": "base64"})
+	if err != nil {
+		return nil, err
+	}
+	if result.Value == nil {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(result.Value.Data[0])
+	if err != nil {
+		return nil, err
+	}
+	return &AccountInfo{
+		Lamports:   result.Value.Lamports,
+		Owner:      result.Value.Owner,
+		Executable: result.Value.Executable,
+		Data:       data,
+	}, nil
+}
+
+// 获取代币账户余额
+func (s *SolClient) GetTokenAccountBalance(address string) (*TokenAmount, error) {
+	var result struct {
+		Value TokenAmount `json:"value"`
+	}
+	if err := s.call(&result, "getTokenAccountBalance", address, commitment); err != nil {
+		return nil, err
+	}
+	return &result.Value, nil
+}
+
+// 获取最近的区块哈希，用于构建交易，约 150 个区块后失效
+func (s *SolClient) GetLatestBlockhash() (string, error) {
+	var result struct {
+		Value struct {
+			Blockhash string `json:"blockhash"`
+		} `json:"value"`
+	}
+	if err := s.call(&result, "getLatestBlockhash", commitment); err != nil {
+		return "", err
+	}
+	return result.Value.Blockhash, nil
+}
+
+// 计算消息的手续费，message 为 base64 编码；区块哈希失效时节点返回 null
+func (s *SolClient) GetFeeForMessage(message string) (uint64, error) {
+	var result struct {
+		Value *uint64 `json:"value"`
+	}
+	if err := s.call(&result, "getFeeForMessage", message, commitment); err != nil {
+		return 0, err
+	}
+	if result.Value == nil {
+		return 0, errors.New("blockhash expired")
+	}
+	return *result.Value, nil
+}
+
+// 广播 base64 编码的签名交易，返回交易签名
+func (s *SolClient) SendTransaction(rawTx string) (string, error) {
+	var signature string
+	err := s.call(&signature, "sendTransaction", rawTx, map[string]any{"encoding": "base64", "preflightCommitment": "confirmed"})
+	return signature, err
+}
+
+// 获取交易，交易不存在时返回 nil
+func (s *SolClient) GetTransaction(signature string) (*Transaction, error) {
+	var tx *Transaction
+	err := s.call(&tx, "getTransaction", signature, map[string]any{
+		"commitment":                     "confirmed",
+		"encoding":                       "jsonParsed",
+		"maxSupportedTransactionVersion": 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// mint 账户数据中 decimals 的位置
+const mintDecimalsOffset = 44
+
+// 解析 mint 账户的精度
+func mintDecimals(info *AccountInfo) (uint8, error) {
+	if info == nil || len(info.Data) <= mintDecimalsOffset {
+		return 0, errors.New("invalid mint account")
+	}
+	return info.Data[mintDecimalsOffset], nil
+}
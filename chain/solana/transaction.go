@@ -0,0 +1,189 @@
+package solana
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 构建未签名交易：un_sign_tx 为 base64 编码的消息，sign_hashes 按签名者顺序给出待签名的消息（十六进制），
+// ed25519 对消息原文签名。区块哈希约 60 秒后失效，需要在失效前完成签名和广播
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx SolTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	msg, err := c.buildMessage(&transferTx)
+	if err != nil {
+		log.Error("build message fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	serialized := msg.Serialize()
+	signHashes := make([]string, len(msg.Signers()))
+	for i := range signHashes {
+		signHashes[i] = hex.EncodeToString(serialized)
+	}
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   base64.StdEncoding.EncodeToString(serialized),
+		SignHashes: signHashes,
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的消息，signatures 按签名者顺序给出 64 字节签名，
+// 只有一个签名者时也可以使用 signature。返回的 signed_tx 为 base64 编码的交易，msg 为交易签名（交易哈希）
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	serialized, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode message fail",
+		}, nil
+	}
+	msg, err := DeserializeMessage(serialized)
+	if err != nil {
+		log.Error("decode message fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode message fail",
+		}, nil
+	}
+	signatures := req.Signatures
+	if len(signatures) == 0 && req.Signature != "" {
+		signatures = []string{req.Signature}
+	}
+	signers := msg.Signers()
+	if len(signatures) != len(signers) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  fmt.Sprintf("expected %d signatures, got %d", len(signers), len(signatures)),
+		}, nil
+	}
+
+	// 逐个校验签名与签名者公钥匹配
+	rawSignatures := make([][]byte, len(signatures))
+	for i, signature := range signatures {
+		rawSignature, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+		if err != nil || len(rawSignature) != ed25519.SignatureSize || !ed25519.Verify(signers[i][:], serialized, rawSignature) {
+			log.Error("verify signature fail", "signer", signers[i].String())
+			return &account.SignedTransactionResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid signature for " + signers[i].String(),
+			}, nil
+		}
+		rawSignatures[i] = rawSignature
+	}
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      encodeSignature(rawSignatures[0]),
+		SignedTx: base64.StdEncoding.EncodeToString(SerializeTransaction(rawSignatures, serialized)),
+	}, nil
+}
+
+// 构建 SOL 或 SPL 代币转账消息，接收方的关联代币账户不存在时先创建
+func (c *ChainAdaptor) buildMessage(transferTx *SolTransferTx) (*Message, error) {
+	from, err := ParsePublicKey(transferTx.FromAddress)
+	if err != nil {
+		return nil, errors.New("invalid from address")
+	}
+	to, err := ParsePublicKey(transferTx.ToAddress)
+	if err != nil {
+		return nil, errors.New("invalid to address")
+	}
+	amount, err := strconv.ParseUint(transferTx.Amount, 10, 64)
+	if err != nil || amount == 0 {
+		return nil, errors.New("invalid amount")
+	}
+	feePayer := from
+	if transferTx.FeePayer != "" {
+		if feePayer, err = ParsePublicKey(transferTx.FeePayer); err != nil {
+			return nil, errors.New("invalid fee payer")
+		}
+	}
+	blockhashStr := transferTx.RecentBlockhash
+	if blockhashStr == "" {
+		if blockhashStr, err = c.SolClient.GetLatestBlockhash(); err != nil {
+			return nil, fmt.Errorf("get latest blockhash fail: %w", err)
+		}
+	}
+	blockhash, err := ParsePublicKey(blockhashStr)
+	if err != nil {
+		return nil, errors.New("invalid recent blockhash")
+	}
+
+	if transferTx.ContractAddress == "" {
+		return NewMessage(feePayer, []Instruction{systemTransfer(from, to, amount)}, blockhash)
+	}
+
+	mint, err := ParsePublicKey(transferTx.ContractAddress)
+	if err != nil {
+		return nil, errors.New("invalid contract address")
+	}
+	tokenProgram, decimals, err := c.mintInfo(mint)
+	if err != nil {
+		return nil, err
+	}
+	source, err := AssociatedTokenAddress(from, mint, tokenProgram)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := AssociatedTokenAddress(to, mint, tokenProgram)
+	if err != nil {
+		return nil, err
+	}
+	destinationInfo, err := c.SolClient.GetAccountInfo(destination.String())
+	if err != nil {
+		return nil, fmt.Errorf("get token account fail: %w", err)
+	}
+	var instructions []Instruction
+	if destinationInfo == nil {
+		instructions = append(instructions, createAssociatedTokenAccountIdempotent(feePayer, destination, to, mint, tokenProgram))
+	}
+	instructions = append(instructions, tokenTransferChecked(source, mint, destination, from, tokenProgram, amount, decimals))
+	return NewMessage(feePayer, instructions, blockhash)
+}
+
+// 查询 mint 所属的代币程序（Token 或 Token-2022）和精度
+func (c *ChainAdaptor) mintInfo(mint PublicKey) (PublicKey, uint8, error) {
+	info, err := c.SolClient.GetAccountInfo(mint.String())
+	if err != nil {
+		return PublicKey{}, 0, fmt.Errorf("get mint account fail: %w", err)
+	}
+	if info == nil {
+		return PublicKey{}, 0, errors.New("mint account not found")
+	}
+	if info.Owner != TokenProgram && info.Owner != Token2022Program {
+		return PublicKey{}, 0, errors.New("contract address is not a token mint")
+	}
+	decimals, err := mintDecimals(info)
+	if err != nil {
+		return PublicKey{}, 0, err
+	}
+	return mustPublicKey(info.Owner), decimals, nil
+}
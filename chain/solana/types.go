@@ -0,0 +1,24 @@
+package solana
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type SolTransferTx struct {
+	FromAddress string `json:"from_address"`
+	// 接收方钱包地址，SPL 代币转入其关联代币账户
+	ToAddress string `json:"to_address"`
+	// SOL 单位 lamports，SPL 代币为最小单位
+	Amount string `json:"amount"`
+	// SPL 代币的 mint 地址，为空时转账 SOL
+	ContractAddress string `json:"contract_address"`
+	// 手续费支付者，为空时由 from_address 支付
+	FeePayer string `json:"fee_payer"`
+	// 为空时通过节点获取最新的区块哈希
+	RecentBlockhash string `json:"recent_blockhash"`
+}
+
+// 交易中解析出的 SOL 或 SPL 代币转账，SPL 转账的地址为代币账户的持有者
+type Transfer struct {
+	ContractAddress string `json:"contract_address"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Amount          string `json:"amount"`
+}
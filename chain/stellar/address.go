@@ -0,0 +1,74 @@
+package stellar
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// 账户地址（G 开头）的版本字节
+const versionByteAccountId = 6 << 3
+
+// strkey 使用无填充的标准 base32
+var strkeyEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ed25519 公钥，即账户 ID
+type PublicKey [32]byte
+
+// strkey 格式：base32(版本字节 || 公钥 || crc16 校验和，小端序)
+func (p PublicKey) Address() string {
+	payload := append([]byte{versionByteAccountId}, p[:]...)
+	payload = binary.LittleEndian.AppendUint16(payload, crc16(payload))
+	return strkeyEncoding.EncodeToString(payload)
+}
+
+// 解析 G 开头的账户地址，不支持 M 开头的复用账户
+func ParseAddress(address string) (PublicKey, error) {
+	var p PublicKey
+	if len(address) != 56 || !strings.HasPrefix(address, "G") {
+		return p, errors.New("invalid address")
+	}
+	data, err := strkeyEncoding.DecodeString(address)
+	if err != nil || len(data) != 1+len(p)+2 || data[0] != versionByteAccountId {
+		return p, errors.New("invalid address")
+	}
+	if crc16(data[:len(data)-2]) != binary.LittleEndian.Uint16(data[len(data)-2:]) {
+		return p, errors.New("invalid address")
+	}
+	copy(p[:], data[1:len(data)-2])
+	return p, nil
+}
+
+func ValidateAddress(address string) bool {
+	_, err := ParseAddress(address)
+	return err == nil
+}
+
+// 解析十六进制 ed25519 公钥
+func ParsePublicKey(publicKey string) (PublicKey, error) {
+	var p PublicKey
+	data, err := hex.DecodeString(strings.TrimPrefix(publicKey, "0x"))
+	if err != nil || len(data) != len(p) {
+		return p, errors.New("invalid public key")
+	}
+	copy(p[:], data)
+	return p, nil
+}
+
+// CRC16-XModem
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,284 @@
+package stellar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// Horizon 单页最多返回的记录数
+const maxPageLimit = 200
+
+// Horizon 返回的错误，账户、交易不存在时 HTTP 状态为 404，提交失败时 result_codes 为交易和操作的结果码
+type ApiError struct {
+	StatusCode int
+	Title      string `json:"title"`
+	Detail     string `json:"detail"`
+	Extras     struct {
+		ResultCodes struct {
+			Transaction string   `json:"transaction"`
+			Operations  []string `json:"operations"`
+		} `json:"result_codes"`
+	} `json:"extras"`
+}
+
+func (e *ApiError) Error() string {
+	codes := e.Extras.ResultCodes
+	if codes.Transaction != "" {
+		return fmt.Sprintf("api error %d %s: %s %v", e.StatusCode, e.Title, codes.Transaction, codes.Operations)
+	}
+	return fmt.Sprintf("api error %d %s: %s", e.StatusCode, e.Title, e.Detail)
+}
+
+func IsNotFound(err error) bool {
+	var apiErr *ApiError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// 余额为 7 位小数的字符串
+type Balance struct {
+	Balance     string `json:"balance"`
+	AssetType   string `json:"asset_type"`
+	AssetCode   string `json:"asset_code"`
+	AssetIssuer string `json:"asset_issuer"`
+}
+
+type Account struct {
+	AccountId string    `json:"account_id"`
+	Sequence  string    `json:"sequence"`
+	Balances  []Balance `json:"balances"`
+}
+
+// 手续费统计，单位 stroop
+type FeeStats struct {
+	LastLedgerBaseFee string `json:"last_ledger_base_fee"`
+	FeeCharged        struct {
+		Min  string `json:"min"`
+		Mode string `json:"mode"`
+		P10  string `json:"p10"`
+		P50  string `json:"p50"`
+		P90  string `json:"p90"`
+	} `json:"fee_charged"`
+}
+
+type Ledger struct {
+	Sequence uint32 `json:"sequence"`
+	Hash     string `json:"hash"`
+	PrevHash string `json:"prev_hash"`
+	ClosedAt string `json:"closed_at"`
+}
+
+type TransactionRecord struct {
+	Hash          string `json:"hash"`
+	Ledger        uint32 `json:"ledger"`
+	CreatedAt     string `json:"created_at"`
+	SourceAccount string `json:"source_account"`
+	FeeCharged    string `json:"fee_charged"`
+	Successful    bool   `json:"successful"`
+	MemoType      string `json:"memo_type"`
+	Memo          string `json:"memo"`
+}
+
+// payments 接口返回的操作：payment、path_payment_*、create_account、account_merge，
+// 金额为 7 位小数的字符串
+type PaymentRecord struct {
+	PagingToken           string `json:"paging_token"`
+	Type                  string `json:"type"`
+	TransactionHash       string `json:"transaction_hash"`
+	TransactionSuccessful bool   `json:"transaction_successful"`
+	From                  string `json:"from"`
+	To                    string `json:"to"`
+	Amount                string `json:"amount"`
+	AssetType             string `json:"asset_type"`
+	AssetCode             string `json:"asset_code"`
+	AssetIssuer           string `json:"asset_issuer"`
+	// create_account
+	Funder          string `json:"funder"`
+	Account         string `json:"account"`
+	StartingBalance string `json:"starting_balance"`
+	// join=transactions 时返回所属交易
+	Transaction *TransactionRecord `json:"transaction"`
+}
+
+type page[T any] struct {
+	Embedded struct {
+		Records []T `json:"records"`
+	} `json:"_embedded"`
+}
+
+// 定义 Horizon 接口
+type IHorizon interface {
+	GetAccount(address string) (*Account, error)
+	GetFeeStats() (*FeeStats, error)
+	GetLatestLedger() (*Ledger, error)
+	GetLedger(sequence uint32) (*Ledger, error)
+	// 账本中成功交易的全部转账
+	GetLedgerPayments(sequence uint32) ([]PaymentRecord, error)
+	GetTransaction(hash string) (*TransactionRecord, error)
+	GetTransactionPayments(hash string) ([]PaymentRecord, error)
+	// 按时间倒序分页查询账户相关的转账，cursor 为上一页最后一条的 paging_token
+	GetAccountPayments(address string, cursor string, limit uint32) ([]PaymentRecord, error)
+	// 提交 base64 编码的交易信封
+	SubmitTransaction(envelope string) (*TransactionRecord, error)
+}
+
+// 定义 Horizon REST 客户端
+type HorizonClient struct {
+	url    string
+	client *http.Client
+}
+
+func NewHorizonClient(rpcUrl string, timeout time.Duration) (IHorizon, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &HorizonClient{
+		url:    strings.TrimSuffix(rpcUrl, "/"),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (h *HorizonClient) do(req *http.Request, result any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &ApiError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(respBody, apiErr) != nil || apiErr.Title == "" {
+			apiErr.Detail = string(respBody)
+		}
+		return apiErr
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+func (h *HorizonClient) get(path string, query url.Values, result any) error {
+	target := h.url + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	return h.do(req, result)
+}
+
+// 按 paging_token 翻页获取全部记录
+func (h *HorizonClient) getAllPayments(path string) ([]PaymentRecord, error) {
+	var payments []PaymentRecord
+	query := url.Values{"limit": {strconv.Itoa(maxPageLimit)}, "join": {"transactions"}}
+	for {
+		var result page[PaymentRecord]
+		if err := h.get(path, query, &result); err != nil {
+			return nil, err
+		}
+		records := result.Embedded.Records
+		payments = append(payments, records...)
+		if len(records) < maxPageLimit {
+			return payments, nil
+		}
+		query.Set("cursor", records[len(records)-1].PagingToken)
+	}
+}
+
+// 账户不存在（未激活）时返回 404
+func (h *HorizonClient) GetAccount(address string) (*Account, error) {
+	account := new(Account)
+	if err := h.get("/accounts/"+url.PathEscape(address), nil, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (h *HorizonClient) GetFeeStats() (*FeeStats, error) {
+	stats := new(FeeStats)
+	if err := h.get("/fee_stats", nil, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (h *HorizonClient) GetLatestLedger() (*Ledger, error) {
+	var result page[Ledger]
+	if err := h.get("/ledgers", url.Values{"order": {"desc"}, "limit": {"1"}}, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Embedded.Records) == 0 {
+		return nil, errors.New("no ledger found")
+	}
+	return &result.Embedded.Records[0], nil
+}
+
+func (h *HorizonClient) GetLedger(sequence uint32) (*Ledger, error) {
+	ledger := new(Ledger)
+	if err := h.get(fmt.Sprintf("/ledgers/%d", sequence), nil, ledger); err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
+
+func (h *HorizonClient) GetLedgerPayments(sequence uint32) ([]PaymentRecord, error) {
+	return h.getAllPayments(fmt.Sprintf("/ledgers/%d/payments", sequence))
+}
+
+func (h *HorizonClient) GetTransaction(hash string) (*TransactionRecord, error) {
+	tx := new(TransactionRecord)
+	if err := h.get("/transactions/"+url.PathEscape(hash), nil, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (h *HorizonClient) GetTransactionPayments(hash string) ([]PaymentRecord, error) {
+	return h.getAllPayments("/transactions/" + url.PathEscape(hash) + "/payments")
+}
+
+func (h *HorizonClient) GetAccountPayments(address string, cursor string, limit uint32) ([]PaymentRecord, error) {
+	query := url.Values{
+		"order": {"desc"},
+		"limit": {strconv.FormatUint(uint64(min(limit, maxPageLimit)), 10)},
+		"join":  {"transactions"},
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	var result page[PaymentRecord]
+	if err := h.get("/accounts/"+url.PathEscape(address)+"/payments", query, &result); err != nil {
+		return nil, err
+	}
+	return result.Embedded.Records, nil
+}
+
+// 同步提交，交易进入账本或失败后返回
+func (h *HorizonClient) SubmitTransaction(envelope string) (*TransactionRecord, error) {
+	form := url.Values{"tx": {envelope}}
+	req, err := http.NewRequest(http.MethodPost, h.url+"/transactions", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	tx := new(TransactionRecord)
+	if err := h.do(req, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,557 @@
+package stellar
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/shopspring/decimal"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Stellar"
+
+// 网络 passphrase，用于计算交易哈希
+const (
+	PublicNetworkPassphrase  = "Public Global Stellar Network ; September 2015"
+	TestnetNetworkPassphrase = "Test SDF Network ; September 2015"
+)
+
+// XLM 和代币金额均为 7 位小数
+const amountDecimals = 7
+
+// 单次 GetBlockByRange 最多查询的账本数，每个账本需要一次请求
+const blockRangeLimit = 100
+
+// GetTxByAddress 默认每页数量
+const defaultPageSize = 20
+
+type ChainAdaptor struct {
+	HorizonClient IHorizon
+	Passphrase    string
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.Xlm
+	network := con.NetWork
+	if node.Network != "" {
+		network = node.Network
+	}
+	horizonClient, err := NewHorizonClient(node.RpcUrl, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	passphrase := PublicNetworkPassphrase
+	if network == "testnet" {
+		passphrase = TestnetNetworkPassphrase
+	}
+	return &ChainAdaptor{
+		HorizonClient: horizonClient,
+		Passphrase:    passphrase,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入 ed25519 公钥 转换成 G 开头的账户地址
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeEd25519); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	pubKey, err := ParsePublicKey(req.PublicKey)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: pubKey.Address(),
+	}, nil
+}
+
+// 地址格式验证
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 以账本作为区块，height 为账本序号，为 0 时返回最新账本
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	ledger, err := c.getLedger(req.Height)
+	if err != nil {
+		log.Error("get ledger fail", "height", req.Height, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	payments, err := c.HorizonClient.GetLedgerPayments(ledger.Sequence)
+	if err != nil {
+		log.Error("get ledger payments fail", "sequence", ledger.Sequence, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	var blockTxList []*account.BlockInfoTransactionList
+	for i := range payments {
+		transfer, ok := parseTransfer(&payments[i])
+		if !ok || !payments[i].TransactionSuccessful {
+			continue
+		}
+		blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+			From:         transfer.From,
+			To:           transfer.To,
+			TokenAddress: transfer.ContractAddress,
+			Hash:         payments[i].TransactionHash,
+			Height:       uint64(ledger.Sequence),
+			Amount:       transfer.Amount,
+			Memo:         memo(payments[i].Transaction),
+		})
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          "get block by number success",
+		Height:       int64(ledger.Sequence),
+		Hash:         ledger.Hash,
+		Transactions: blockTxList,
+	}, nil
+}
+
+// Horizon 不支持按账本哈希查询
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return &account.BlockResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block by hash is not supported",
+	}, nil
+}
+
+// 通过账本序号获取区块头信息，height 为 0 时返回最新账本
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	ledger, err := c.getLedger(req.Height)
+	if err != nil {
+		log.Error("get ledger fail", "height", req.Height, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(ledger),
+	}, nil
+}
+
+// Horizon 不支持按账本哈希查询
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	return &account.BlockHeaderResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block header by hash is not supported",
+	}, nil
+}
+
+// 获取区间内的账本头
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseUint(req.Start, 10, 32)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseUint(req.End, 10, 32)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	if end-start >= blockRangeLimit {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "block range too large",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for sequence := start; sequence <= end; sequence++ {
+		ledger, err := c.HorizonClient.GetLedger(uint32(sequence))
+		if err != nil {
+			log.Error("get ledger fail", "sequence", sequence, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		headers = append(headers, toBlockHeader(ledger))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取余额（stroop）和当前 sequence，contract_address 为 CODE:ISSUER 时查询代币，未激活的账户返回 0
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	if req.ContractAddress != "" {
+		if _, err := ParseAsset(req.ContractAddress); err != nil {
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid contract address",
+			}, nil
+		}
+	}
+	info, err := c.HorizonClient.GetAccount(req.Address)
+	if IsNotFound(err) {
+		return &account.AccountResponse{
+			Code:          global_const.ReturnCode_SUCCESS,
+			Msg:           "get account response success",
+			AccountNumber: "0",
+			Sequence:      "0",
+			Balance:       "0",
+		}, nil
+	}
+	if err != nil {
+		log.Error("get account fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	balance := "0"
+	for _, item := range info.Balances {
+		if assetId(item.AssetType, item.AssetCode, item.AssetIssuer) == req.ContractAddress {
+			balance = toStroops(item.Balance)
+			break
+		}
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      info.Sequence,
+		Balance:       balance,
+	}, nil
+}
+
+// 获取fee，单位 stroop。
+// 传入 rawTx（BuildUnSignTransaction 返回的 un_sign_tx）时返回该交易的手续费上限；
+// 否则按近期账本实际收取的手续费 p10、p50、p90 返回单个操作的手续费，不低于基础费用
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	if req.RawTx != "" {
+		env, err := decodeEnvelope(req.RawTx)
+		if err != nil {
+			log.Error("decode envelope fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		fee := strconv.FormatUint(uint64(env.Tx.Fee), 10)
+		return &account.FeeResponse{
+			Code:      global_const.ReturnCode_SUCCESS,
+			Msg:       "get fee success",
+			SlowFee:   fee,
+			NormalFee: fee,
+			FastFee:   fee,
+		}, nil
+	}
+	stats, err := c.HorizonClient.GetFeeStats()
+	if err != nil {
+		log.Error("get fee stats fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get fee stats fail",
+		}, nil
+	}
+	levels := feeLevels(stats)
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   strconv.FormatUint(uint64(levels[0]), 10),
+		NormalFee: strconv.FormatUint(uint64(levels[1]), 10),
+		FastFee:   strconv.FormatUint(uint64(levels[2]), 10),
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的 signed_tx，交易进入账本后返回
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	if _, err := decodeEnvelope(req.RawTx); err != nil {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	tx, err := c.HorizonClient.SubmitTransaction(req.RawTx)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: tx.Hash,
+	}, nil
+}
+
+// 按地址分页查询转入转出，按时间倒序，page 从 1 开始，翻页需要逐页请求
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.TxAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	var cursor string
+	var payments []PaymentRecord
+	for i := uint32(1); i <= max(req.Page, 1); i++ {
+		var err error
+		if payments, err = c.HorizonClient.GetAccountPayments(req.Address, cursor, pageSize); err != nil {
+			log.Error("get account payments fail", "address", req.Address, "err", err)
+			return &account.TxAddressResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get tx by address fail",
+			}, nil
+		}
+		if len(payments) == 0 {
+			break
+		}
+		cursor = payments[len(payments)-1].PagingToken
+	}
+	var txs []*account.TxMessage
+	for i := range payments {
+		transfer, ok := parseTransfer(&payments[i])
+		if !ok {
+			continue
+		}
+		txs = append(txs, toTxMessage(payments[i].Transaction, payments[i].TransactionHash, []Transfer{transfer}))
+	}
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txs,
+	}, nil
+}
+
+// 通过交易哈希获取交易，memo 为交易的 memo
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	tx, err := c.HorizonClient.GetTransaction(req.Hash)
+	if IsNotFound(err) {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get transaction fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	payments, err := c.HorizonClient.GetTransactionPayments(req.Hash)
+	if err != nil {
+		log.Error("get transaction payments fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	var transfers []Transfer
+	for i := range payments {
+		if transfer, ok := parseTransfer(&payments[i]); ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   toTxMessage(tx, req.Hash, transfers),
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// height 为 0 时获取最新账本
+func (c *ChainAdaptor) getLedger(height int64) (*Ledger, error) {
+	if height == 0 {
+		return c.HorizonClient.GetLatestLedger()
+	}
+	return c.HorizonClient.GetLedger(uint32(height))
+}
+
+func decodeEnvelope(rawTx string) (*TransactionEnvelope, error) {
+	data, err := base64.StdEncoding.DecodeString(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalEnvelope(data)
+}
+
+// 三档单个操作的手续费（stroop），不低于基础费用，后一档不低于前一档
+func feeLevels(stats *FeeStats) [3]uint32 {
+	baseFee, _ := strconv.ParseUint(stats.LastLedgerBaseFee, 10, 32)
+	levels := [3]uint32{uint32(baseFee), uint32(baseFee), uint32(baseFee)}
+	for i, value := range []string{stats.FeeCharged.P10, stats.FeeCharged.P50, stats.FeeCharged.P90} {
+		fee, _ := strconv.ParseUint(value, 10, 32)
+		levels[i] = max(levels[i], uint32(fee))
+		if i > 0 {
+			levels[i] = max(levels[i], levels[i-1])
+		}
+	}
+	return levels
+}
+
+// 转换为 Horizon 的资产标识，XLM 为空，代币为 CODE:ISSUER
+func assetId(assetType, code, issuer string) string {
+	if assetType == "native" {
+		return ""
+	}
+	return code + ":" + issuer
+}
+
+// 7 位小数的金额转换为 stroop
+func toStroops(amount string) string {
+	value, err := decimal.NewFromString(amount)
+	if err != nil {
+		return "0"
+	}
+	return value.Shift(amountDecimals).Truncate(0).String()
+}
+
+// 解析 payments 接口的记录，path_payment 取目标资产和到账金额，account_merge 没有金额，不统计
+func parseTransfer(payment *PaymentRecord) (Transfer, bool) {
+	switch payment.Type {
+	case "create_account":
+		return Transfer{From: payment.Funder, To: payment.Account, Amount: toStroops(payment.StartingBalance)}, true
+	case "payment", "path_payment_strict_receive", "path_payment_strict_send":
+		return Transfer{
+			ContractAddress: assetId(payment.AssetType, payment.AssetCode, payment.AssetIssuer),
+			From:            payment.From,
+			To:              payment.To,
+			Amount:          toStroops(payment.Amount),
+		}, true
+	default:
+		return Transfer{}, false
+	}
+}
+
+// memo 的文本或数字，hash 和 return 类型为 base64
+func memo(tx *TransactionRecord) string {
+	if tx == nil || tx.MemoType == "none" {
+		return ""
+	}
+	return tx.Memo
+}
+
+func toTxMessage(tx *TransactionRecord, hash string, transfers []Transfer) *account.TxMessage {
+	txMessage := &account.TxMessage{Hash: hash, Status: account.TxStatus_Success}
+	if tx != nil {
+		txMessage.From = tx.SourceAccount
+		txMessage.Fee = tx.FeeCharged
+		txMessage.Height = strconv.FormatUint(uint64(tx.Ledger), 10)
+		txMessage.Memo = memo(tx)
+		if !tx.Successful {
+			txMessage.Status = account.TxStatus_Failed
+		}
+		if createdAt, err := time.Parse(time.RFC3339, tx.CreatedAt); err == nil {
+			txMessage.Datetime = strconv.FormatInt(createdAt.Unix(), 10)
+		}
+	}
+	if len(transfers) > 0 {
+		transfer := transfers[0]
+		txMessage.From = transfer.From
+		txMessage.To = transfer.To
+		txMessage.Value = transfer.Amount
+		txMessage.ContractAddress = transfer.ContractAddress
+		if transfer.ContractAddress != "" {
+			txMessage.Type = 1
+		}
+		data, _ := json.Marshal(transfers)
+		txMessage.Data = string(data)
+	}
+	return txMessage
+}
+
+func toBlockHeader(ledger *Ledger) *account.BlockHeader {
+	header := &account.BlockHeader{
+		Hash:       ledger.Hash,
+		ParentHash: ledger.PrevHash,
+		Number:     strconv.FormatUint(uint64(ledger.Sequence), 10),
+	}
+	if closedAt, err := time.Parse(time.RFC3339, ledger.ClosedAt); err == nil {
+		header.Time = uint64(closedAt.Unix())
+	}
+	return header
+}
@@ -0,0 +1,272 @@
+package stellar
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 全 0 公钥对应的账户地址
+const zeroAddress = "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF"
+
+// 模拟 Horizon，existing 之外的账户未激活
+type fakeHorizon struct {
+	IHorizon
+	existing map[string]bool
+	payments []PaymentRecord
+}
+
+func (f *fakeHorizon) GetAccount(address string) (*Account, error) {
+	if !f.existing[address] {
+		return nil, &ApiError{StatusCode: http.StatusNotFound, Title: "Resource Missing"}
+	}
+	return &Account{
+		AccountId: address,
+		Sequence:  "103420918407103888",
+		Balances: []Balance{
+			{Balance: "12.3456789", AssetType: "native"},
+			{Balance: "0.5000000", AssetType: "credit_alphanum4", AssetCode: "USDC", AssetIssuer: zeroAddress},
+		},
+	}, nil
+}
+
+func (f *fakeHorizon) GetFeeStats() (*FeeStats, error) {
+	stats := &FeeStats{LastLedgerBaseFee: "100"}
+	stats.FeeCharged.P10 = "100"
+	stats.FeeCharged.P50 = "150"
+	stats.FeeCharged.P90 = "5000"
+	return stats, nil
+}
+
+func (f *fakeHorizon) GetLedger(sequence uint32) (*Ledger, error) {
+	return &Ledger{Sequence: sequence, Hash: "ab", PrevHash: "cd", ClosedAt: "2024-01-01T00:00:00Z"}, nil
+}
+
+func (f *fakeHorizon) GetLedgerPayments(sequence uint32) ([]PaymentRecord, error) {
+	return f.payments, nil
+}
+
+func newTestAdaptor(existing ...string) (*ChainAdaptor, *fakeHorizon) {
+	horizon := &fakeHorizon{existing: make(map[string]bool)}
+	for _, address := range existing {
+		horizon.existing[address] = true
+	}
+	return &ChainAdaptor{HorizonClient: horizon, Passphrase: TestnetNetworkPassphrase}, horizon
+}
+
+func buildUnsigned(t *testing.T, adaptor *ChainAdaptor, transferTx StellarTransferTx) (*TransactionEnvelope, []byte, string) {
+	txJson, _ := json.Marshal(transferTx)
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	env, err := decodeEnvelope(resp.UnSignTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env.Signatures) != 0 {
+		t.Fatal("expected unsigned envelope")
+	}
+	hash, _ := hex.DecodeString(resp.SignHashes[0])
+	if !bytes.Equal(hash, env.Tx.Hash(TestnetNetworkPassphrase)) {
+		t.Fatal("expected sign hash to be the transaction hash")
+	}
+	return env, hash, resp.UnSignTx
+}
+
+func Test_Address(t *testing.T) {
+	if address := (PublicKey{}).Address(); address != zeroAddress {
+		t.Fatalf("unexpected address %s", address)
+	}
+	pubKey, _, _ := ed25519.GenerateKey(nil)
+	adaptor := &ChainAdaptor{}
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: hex.EncodeToString(pubKey)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("convert address fail: %s", resp.Msg)
+	}
+	parsed, err := ParseAddress(resp.Address)
+	if err != nil || !bytes.Equal(parsed[:], pubKey) {
+		t.Fatal("expected address to round trip")
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: hex.EncodeToString(pubKey), KeyType: chain.KeyTypeSecp256k1})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected secp256k1 key type to be rejected")
+	}
+	for _, address := range []string{
+		"GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHG",
+		"SAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
+		"GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWH",
+	} {
+		if ValidateAddress(address) {
+			t.Fatalf("expected %s to be invalid", address)
+		}
+	}
+}
+
+func Test_NetworkId(t *testing.T) {
+	networkId := sha256.Sum256([]byte(PublicNetworkPassphrase))
+	if hex.EncodeToString(networkId[:]) != "7ac33997544e3175d266bd022439b22cdb16508c01163f26e5cb2a3e1045a979" {
+		t.Fatal("unexpected public network id")
+	}
+}
+
+func Test_BuildTransaction(t *testing.T) {
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	var source PublicKey
+	copy(source[:], pubKey)
+	to := PublicKey{31: 1}.Address()
+	adaptor, _ := newTestAdaptor(source.Address(), to)
+
+	env, hash, unsignedTx := buildUnsigned(t, adaptor, StellarTransferTx{
+		FromAddress: source.Address(),
+		ToAddress:   to,
+		Amount:      "10000000",
+		Memo:        "user-12345",
+	})
+	tx := env.Tx
+	if tx.SeqNum != 103420918407103889 || tx.Fee != 150 || tx.TimeBounds == nil || tx.TimeBounds.MaxTime == 0 {
+		t.Fatalf("unexpected sequence %d fee %d", tx.SeqNum, tx.Fee)
+	}
+	if tx.Memo.Type != MemoText || tx.Memo.Text != "user-12345" {
+		t.Fatal("expected text memo")
+	}
+	if len(tx.Operations) != 1 || tx.Operations[0].Type != operationPayment || tx.Operations[0].Amount != 10000000 {
+		t.Fatal("expected native payment")
+	}
+
+	resp, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  unsignedTx,
+		Signature: hex.EncodeToString(ed25519.Sign(privKey, hash)),
+	})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Msg != hex.EncodeToString(hash) {
+		t.Fatalf("build signed transaction fail: %s", resp.Msg)
+	}
+	signed, err := decodeEnvelope(resp.SignedTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signed.Signatures) != 1 || !bytes.Equal(signed.Signatures[0].Hint[:], pubKey[28:]) {
+		t.Fatal("expected decorated signature with public key hint")
+	}
+	if !bytes.Equal(signed.Tx.MarshalBinary(), tx.MarshalBinary()) {
+		t.Fatal("expected signed envelope to keep the transaction")
+	}
+
+	resp, _ = adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  unsignedTx,
+		Signature: hex.EncodeToString(ed25519.Sign(privKey, []byte("other"))),
+	})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected invalid signature to be rejected")
+	}
+}
+
+func Test_BuildTransactionCreateAccount(t *testing.T) {
+	pubKey, _, _ := ed25519.GenerateKey(nil)
+	var source PublicKey
+	copy(source[:], pubKey)
+	adaptor, _ := newTestAdaptor(source.Address())
+
+	env, _, _ := buildUnsigned(t, adaptor, StellarTransferTx{
+		FromAddress: source.Address(),
+		ToAddress:   zeroAddress,
+		Amount:      "20000000",
+		Memo:        "987654321",
+		MemoType:    "id",
+		Fee:         200,
+		Sequence:    5,
+	})
+	tx := env.Tx
+	if tx.Operations[0].Type != operationCreateAccount || tx.Fee != 200 || tx.SeqNum != 5 {
+		t.Fatal("expected create account operation for unfunded destination")
+	}
+	if tx.Memo.Type != MemoId || tx.Memo.Id != 987654321 {
+		t.Fatal("expected id memo")
+	}
+}
+
+func Test_BuildTransactionAsset(t *testing.T) {
+	pubKey, _, _ := ed25519.GenerateKey(nil)
+	var source PublicKey
+	copy(source[:], pubKey)
+	adaptor, _ := newTestAdaptor(source.Address())
+
+	env, _, _ := buildUnsigned(t, adaptor, StellarTransferTx{
+		FromAddress:     source.Address(),
+		ToAddress:       zeroAddress,
+		Amount:          "1",
+		ContractAddress: "USDC:" + zeroAddress,
+	})
+	operation := env.Tx.Operations[0]
+	if operation.Type != operationPayment || operation.Asset.Code != "USDC" || env.Tx.Memo.Type != MemoNone {
+		t.Fatalf("unexpected operation %+v", operation)
+	}
+
+	txJson, _ := json.Marshal(StellarTransferTx{
+		FromAddress: source.Address(),
+		ToAddress:   zeroAddress,
+		Amount:      "1",
+		Memo:        "a memo longer than twenty-eight bytes",
+	})
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected long memo to be rejected")
+	}
+}
+
+func Test_GetAccount(t *testing.T) {
+	adaptor, _ := newTestAdaptor(zeroAddress)
+	resp, _ := adaptor.GetAccount(&account.AccountRequest{Address: zeroAddress})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Balance != "123456789" || resp.Sequence != "103420918407103888" {
+		t.Fatalf("unexpected account %v", resp)
+	}
+	resp, _ = adaptor.GetAccount(&account.AccountRequest{Address: zeroAddress, ContractAddress: "USDC:" + zeroAddress})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Balance != "5000000" {
+		t.Fatalf("unexpected token balance %s", resp.Balance)
+	}
+}
+
+func Test_GetBlockByNumber(t *testing.T) {
+	adaptor, horizon := newTestAdaptor()
+	to := PublicKey{31: 1}.Address()
+	horizon.payments = []PaymentRecord{
+		{
+			Type: "payment", TransactionHash: "h1", TransactionSuccessful: true,
+			From: zeroAddress, To: to, Amount: "1.5000000", AssetType: "native",
+			Transaction: &TransactionRecord{MemoType: "id", Memo: "42"},
+		},
+		{
+			Type: "create_account", TransactionHash: "h2", TransactionSuccessful: true,
+			Funder: zeroAddress, Account: to, StartingBalance: "2.0000000",
+			Transaction: &TransactionRecord{MemoType: "none"},
+		},
+		{Type: "account_merge", TransactionHash: "h3", TransactionSuccessful: true},
+	}
+	resp, _ := adaptor.GetBlockByNumber(&account.BlockNumberRequest{Height: 50000000})
+	if resp.Code != global_const.ReturnCode_SUCCESS || len(resp.Transactions) != 2 {
+		t.Fatalf("unexpected transactions %v", resp.Transactions)
+	}
+	if resp.Transactions[0].Memo != "42" || resp.Transactions[0].Amount != "15000000" {
+		t.Fatalf("unexpected payment %v", resp.Transactions[0])
+	}
+	if resp.Transactions[1].Memo != "" || resp.Transactions[1].Amount != "20000000" || resp.Transactions[1].To != to {
+		t.Fatalf("unexpected create account %v", resp.Transactions[1])
+	}
+}
+
+func Test_GetFee(t *testing.T) {
+	adaptor, _ := newTestAdaptor()
+	resp, _ := adaptor.GetFee(&account.FeeRequest{})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.SlowFee != "100" || resp.NormalFee != "150" || resp.FastFee != "5000" {
+		t.Fatalf("unexpected fee %v", resp)
+	}
+}
@@ -0,0 +1,193 @@
+package stellar
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 交易有效期（秒），超时未上链的交易不会再被执行
+const txTimeout = 300
+
+var errInvalidAsset = errors.New("invalid contract address")
+
+// 构建未签名交易：un_sign_tx 为 base64 编码的交易信封（不含签名），sign_hashes 为交易哈希（ed25519 对哈希签名）
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx StellarTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	tx, err := c.buildTransfer(&transferTx)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	env := &TransactionEnvelope{Tx: *tx}
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   base64.StdEncoding.EncodeToString(env.MarshalBinary()),
+		SignHashes: []string{hex.EncodeToString(tx.Hash(c.Passphrase))},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 un_sign_tx，signature 为发送方对交易哈希的 ed25519 签名。
+// 返回的 signed_tx 为 base64 编码的签名交易信封，msg 为交易哈希
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	env, err := decodeEnvelope(req.Base64Tx)
+	if err != nil {
+		log.Error("decode envelope fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	source := env.Tx.SourceAccount
+	hash := env.Tx.Hash(c.Passphrase)
+	if !ed25519.Verify(source[:], hash, signature) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "signature verification failed",
+		}, nil
+	}
+	var hint [4]byte
+	copy(hint[:], source[len(source)-4:])
+	env.Signatures = append(env.Signatures, DecoratedSignature{Hint: hint, Signature: signature})
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      hex.EncodeToString(hash),
+		SignedTx: base64.StdEncoding.EncodeToString(env.MarshalBinary()),
+	}, nil
+}
+
+// 构建转账：XLM 转入未激活的账户时使用 CREATE_ACCOUNT，其他为 PAYMENT；未指定时查询 sequence 和手续费
+func (c *ChainAdaptor) buildTransfer(transferTx *StellarTransferTx) (*Transaction, error) {
+	from, err := ParseAddress(transferTx.FromAddress)
+	if err != nil {
+		return nil, errors.New("invalid from address")
+	}
+	to, err := ParseAddress(transferTx.ToAddress)
+	if err != nil {
+		return nil, errors.New("invalid to address")
+	}
+	amount, err := strconv.ParseInt(transferTx.Amount, 10, 64)
+	if err != nil || amount <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+	txMemo, err := parseMemo(transferTx.Memo, transferTx.MemoType)
+	if err != nil {
+		return nil, err
+	}
+	operation := Operation{Type: operationPayment, Destination: to, Amount: amount}
+	if transferTx.ContractAddress != "" {
+		if operation.Asset, err = ParseAsset(transferTx.ContractAddress); err != nil {
+			return nil, err
+		}
+	} else {
+		_, err := c.HorizonClient.GetAccount(transferTx.ToAddress)
+		if IsNotFound(err) {
+			operation.Type = operationCreateAccount
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	tx := &Transaction{
+		SourceAccount: from,
+		Fee:           transferTx.Fee,
+		SeqNum:        transferTx.Sequence,
+		TimeBounds:    &TimeBounds{MaxTime: uint64(time.Now().Unix()) + txTimeout},
+		Memo:          txMemo,
+		Operations:    []Operation{operation},
+	}
+	if tx.SeqNum == 0 {
+		info, err := c.HorizonClient.GetAccount(transferTx.FromAddress)
+		if err != nil {
+			return nil, err
+		}
+		sequence, err := strconv.ParseInt(info.Sequence, 10, 64)
+		if err != nil {
+			return nil, errors.New("invalid account sequence")
+		}
+		tx.SeqNum = sequence + 1
+	}
+	if tx.Fee == 0 {
+		stats, err := c.HorizonClient.GetFeeStats()
+		if err != nil {
+			return nil, err
+		}
+		tx.Fee = feeLevels(stats)[1] * uint32(len(tx.Operations))
+	}
+	return tx, nil
+}
+
+// memo_type 为 text（默认）或 id
+func parseMemo(value string, memoType string) (Memo, error) {
+	if value == "" {
+		return Memo{Type: MemoNone}, nil
+	}
+	switch memoType {
+	case "", "text":
+		if len(value) > maxMemoTextLength {
+			return Memo{}, errors.New("memo text too long")
+		}
+		return Memo{Type: MemoText, Text: value}, nil
+	case "id":
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return Memo{}, errors.New("invalid memo id")
+		}
+		return Memo{Type: MemoId, Id: id}, nil
+	default:
+		return Memo{}, errors.New("unsupported memo type")
+	}
+}
+
+// 解析 CODE:ISSUER 格式的代币，CODE 为 1~12 位字母或数字
+func ParseAsset(contractAddress string) (Asset, error) {
+	code, issuer, ok := strings.Cut(contractAddress, ":")
+	if !ok || len(code) == 0 || len(code) > 12 {
+		return Asset{}, errInvalidAsset
+	}
+	for _, r := range code {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return Asset{}, errInvalidAsset
+		}
+	}
+	issuerKey, err := ParseAddress(issuer)
+	if err != nil {
+		return Asset{}, errInvalidAsset
+	}
+	return Asset{Code: code, Issuer: issuerKey}, nil
+}
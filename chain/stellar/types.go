@@ -0,0 +1,28 @@
+package stellar
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type StellarTransferTx struct {
+	// 发送方地址，即签名公钥
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	// 单位为 stroop（1 XLM = 10^7 stroop），代币同样按 7 位小数
+	Amount string `json:"amount"`
+	// 代币为 CODE:ISSUER，如 USDC:GA5Z...，为空时转账 XLM
+	ContractAddress string `json:"contract_address"`
+	// 交易所充值地址需要携带的 memo，为空时不设置
+	Memo string `json:"memo"`
+	// text（默认，最多 28 字节）或 id（uint64）
+	MemoType string `json:"memo_type"`
+	// 单位为 stroop，为 0 时使用近期手续费的中位数
+	Fee uint32 `json:"fee"`
+	// 为 0 时查询账户当前 sequence 后加 1
+	Sequence int64 `json:"sequence"`
+}
+
+// 交易中解析出的 XLM 或代币转账
+type Transfer struct {
+	ContractAddress string `json:"contract_address"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Amount          string `json:"amount"`
+}
@@ -0,0 +1,354 @@
+package stellar
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// XDR 联合体的类型标识
+const (
+	envelopeTypeTx         = 2
+	keyTypeEd25519         = 0
+	preconditionNone       = 0
+	preconditionTime       = 1
+	assetTypeNative        = 0
+	assetTypeAlphanum4     = 1
+	assetTypeAlphanum12    = 2
+	operationCreateAccount = 0
+	operationPayment       = 1
+)
+
+// memo 类型
+const (
+	MemoNone   = 0
+	MemoText   = 1
+	MemoId     = 2
+	MemoHash   = 3
+	MemoReturn = 4
+)
+
+// MEMO_TEXT 最多 28 字节
+const maxMemoTextLength = 28
+
+// 单笔交易最多 100 个操作，最多 20 个签名
+const (
+	maxOperations = 100
+	maxSignatures = 20
+)
+
+var errInvalidXdr = errors.New("invalid xdr")
+
+type TimeBounds struct {
+	MinTime uint64
+	MaxTime uint64
+}
+
+type Memo struct {
+	Type uint32
+	Text string
+	Id   uint64
+	// MEMO_HASH 和 MEMO_RETURN
+	Hash [32]byte
+}
+
+// 资产，Code 为空时为 XLM
+type Asset struct {
+	Code   string
+	Issuer PublicKey
+}
+
+// 只支持 CREATE_ACCOUNT 和 PAYMENT，CREATE_ACCOUNT 的 Amount 为初始余额
+type Operation struct {
+	SourceAccount *PublicKey
+	Type          uint32
+	Destination   PublicKey
+	Asset         Asset
+	Amount        int64 // stroop
+}
+
+// 交易（v1），来源账户和目标账户不支持复用账户
+type Transaction struct {
+	SourceAccount PublicKey
+	Fee           uint32 // 所有操作的手续费上限，stroop
+	SeqNum        int64
+	TimeBounds    *TimeBounds
+	Memo          Memo
+	Operations    []Operation
+}
+
+type DecoratedSignature struct {
+	// 公钥的最后 4 字节
+	Hint      [4]byte
+	Signature []byte
+}
+
+// ENVELOPE_TYPE_TX 交易信封
+type TransactionEnvelope struct {
+	Tx         Transaction
+	Signatures []DecoratedSignature
+}
+
+type xdrEncoder struct {
+	buf []byte
+}
+
+func (e *xdrEncoder) uint32(v uint32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, v)
+}
+
+func (e *xdrEncoder) uint64(v uint64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, v)
+}
+
+func (e *xdrEncoder) bool(v bool) {
+	if v {
+		e.uint32(1)
+	} else {
+		e.uint32(0)
+	}
+}
+
+// 定长数据，补齐到 4 字节
+func (e *xdrEncoder) fixed(data []byte) {
+	e.buf = append(e.buf, data...)
+	e.buf = append(e.buf, make([]byte, (4-len(data)%4)%4)...)
+}
+
+// 变长数据：长度 + 定长数据
+func (e *xdrEncoder) opaque(data []byte) {
+	e.uint32(uint32(len(data)))
+	e.fixed(data)
+}
+
+func (e *xdrEncoder) publicKey(p PublicKey) {
+	e.uint32(keyTypeEd25519)
+	e.fixed(p[:])
+}
+
+func (e *xdrEncoder) asset(a Asset) {
+	switch {
+	case a.Code == "":
+		e.uint32(assetTypeNative)
+		return
+	case len(a.Code) <= 4:
+		e.uint32(assetTypeAlphanum4)
+		e.fixed(append([]byte(a.Code), make([]byte, 4-len(a.Code))...))
+	default:
+		e.uint32(assetTypeAlphanum12)
+		e.fixed(append([]byte(a.Code), make([]byte, 12-len(a.Code))...))
+	}
+	e.publicKey(a.Issuer)
+}
+
+func (e *xdrEncoder) transaction(tx *Transaction) {
+	e.publicKey(tx.SourceAccount)
+	e.uint32(tx.Fee)
+	e.uint64(uint64(tx.SeqNum))
+	if tx.TimeBounds != nil {
+		e.uint32(preconditionTime)
+		e.uint64(tx.TimeBounds.MinTime)
+		e.uint64(tx.TimeBounds.MaxTime)
+	} else {
+		e.uint32(preconditionNone)
+	}
+	e.uint32(tx.Memo.Type)
+	switch tx.Memo.Type {
+	case MemoText:
+		e.opaque([]byte(tx.Memo.Text))
+	case MemoId:
+		e.uint64(tx.Memo.Id)
+	case MemoHash, MemoReturn:
+		e.fixed(tx.Memo.Hash[:])
+	}
+	e.uint32(uint32(len(tx.Operations)))
+	for _, op := range tx.Operations {
+		e.bool(op.SourceAccount != nil)
+		if op.SourceAccount != nil {
+			e.publicKey(*op.SourceAccount)
+		}
+		e.uint32(op.Type)
+		e.publicKey(op.Destination)
+		if op.Type == operationPayment {
+			e.asset(op.Asset)
+		}
+		e.uint64(uint64(op.Amount))
+	}
+	// ext
+	e.uint32(0)
+}
+
+// 交易的 XDR 编码
+func (tx *Transaction) MarshalBinary() []byte {
+	e := new(xdrEncoder)
+	e.transaction(tx)
+	return e.buf
+}
+
+// 交易哈希即签名内容：sha256(networkId || ENVELOPE_TYPE_TX || 交易 XDR)，networkId 为网络 passphrase 的 sha256
+func (tx *Transaction) Hash(passphrase string) []byte {
+	networkId := sha256.Sum256([]byte(passphrase))
+	e := &xdrEncoder{buf: networkId[:]}
+	e.uint32(envelopeTypeTx)
+	e.transaction(tx)
+	hash := sha256.Sum256(e.buf)
+	return hash[:]
+}
+
+func (env *TransactionEnvelope) MarshalBinary() []byte {
+	e := new(xdrEncoder)
+	e.uint32(envelopeTypeTx)
+	e.transaction(&env.Tx)
+	e.uint32(uint32(len(env.Signatures)))
+	for _, sig := range env.Signatures {
+		e.fixed(sig.Hint[:])
+		e.opaque(sig.Signature)
+	}
+	return e.buf
+}
+
+type xdrDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *xdrDecoder) next(n int) []byte {
+	padded := n + (4-n%4)%4
+	if d.err == nil && len(d.data) < padded {
+		d.err = errInvalidXdr
+	}
+	if d.err != nil {
+		return make([]byte, n)
+	}
+	value := d.data[:n]
+	d.data = d.data[padded:]
+	return value
+}
+
+func (d *xdrDecoder) uint32() uint32 {
+	return binary.BigEndian.Uint32(d.next(4))
+}
+
+func (d *xdrDecoder) uint64() uint64 {
+	return binary.BigEndian.Uint64(d.next(8))
+}
+
+func (d *xdrDecoder) opaque(maxLength uint32) []byte {
+	length := d.uint32()
+	if length > maxLength {
+		d.err = errInvalidXdr
+		return nil
+	}
+	return d.next(int(length))
+}
+
+func (d *xdrDecoder) publicKey() PublicKey {
+	var p PublicKey
+	if keyType := d.uint32(); keyType != keyTypeEd25519 && d.err == nil {
+		d.err = fmt.Errorf("unsupported key type %d", keyType)
+	}
+	copy(p[:], d.next(len(p)))
+	return p
+}
+
+func (d *xdrDecoder) asset() Asset {
+	var a Asset
+	var code []byte
+	switch assetType := d.uint32(); assetType {
+	case assetTypeNative:
+		return a
+	case assetTypeAlphanum4:
+		code = d.next(4)
+	case assetTypeAlphanum12:
+		code = d.next(12)
+	default:
+		d.err = fmt.Errorf("unsupported asset type %d", assetType)
+		return a
+	}
+	for len(code) > 0 && code[len(code)-1] == 0 {
+		code = code[:len(code)-1]
+	}
+	a.Code = string(code)
+	a.Issuer = d.publicKey()
+	return a
+}
+
+func (d *xdrDecoder) transaction() Transaction {
+	var tx Transaction
+	tx.SourceAccount = d.publicKey()
+	tx.Fee = d.uint32()
+	tx.SeqNum = int64(d.uint64())
+	switch cond := d.uint32(); cond {
+	case preconditionNone:
+	case preconditionTime:
+		tx.TimeBounds = &TimeBounds{MinTime: d.uint64(), MaxTime: d.uint64()}
+	default:
+		d.err = fmt.Errorf("unsupported precondition %d", cond)
+	}
+	tx.Memo.Type = d.uint32()
+	switch tx.Memo.Type {
+	case MemoNone:
+	case MemoText:
+		tx.Memo.Text = string(d.opaque(maxMemoTextLength))
+	case MemoId:
+		tx.Memo.Id = d.uint64()
+	case MemoHash, MemoReturn:
+		copy(tx.Memo.Hash[:], d.next(32))
+	default:
+		d.err = errInvalidXdr
+	}
+	count := d.uint32()
+	if count > maxOperations {
+		d.err = errInvalidXdr
+	}
+	for i := uint32(0); i < count && d.err == nil; i++ {
+		var op Operation
+		if d.uint32() == 1 {
+			source := d.publicKey()
+			op.SourceAccount = &source
+		}
+		op.Type = d.uint32()
+		switch op.Type {
+		case operationCreateAccount:
+			op.Destination = d.publicKey()
+		case operationPayment:
+			op.Destination = d.publicKey()
+			op.Asset = d.asset()
+		default:
+			d.err = fmt.Errorf("unsupported operation type %d", op.Type)
+		}
+		op.Amount = int64(d.uint64())
+		tx.Operations = append(tx.Operations, op)
+	}
+	if ext := d.uint32(); ext != 0 && d.err == nil {
+		d.err = errInvalidXdr
+	}
+	return tx
+}
+
+// 解析 ENVELOPE_TYPE_TX 交易信封，只支持 CREATE_ACCOUNT 和 PAYMENT 操作
+func UnmarshalEnvelope(data []byte) (*TransactionEnvelope, error) {
+	d := &xdrDecoder{data: data}
+	if envelopeType := d.uint32(); envelopeType != envelopeTypeTx {
+		return nil, fmt.Errorf("unsupported envelope type %d", envelopeType)
+	}
+	env := &TransactionEnvelope{Tx: d.transaction()}
+	count := d.uint32()
+	if count > maxSignatures {
+		d.err = errInvalidXdr
+	}
+	for i := uint32(0); i < count && d.err == nil; i++ {
+		var sig DecoratedSignature
+		copy(sig.Hint[:], d.next(4))
+		sig.Signature = d.opaque(64)
+		env.Signatures = append(env.Signatures, sig)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(d.data) != 0 {
+		return nil, errInvalidXdr
+	}
+	return env, nil
+}
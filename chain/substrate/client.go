@@ -0,0 +1,250 @@
+package substrate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// Substrate JSON-RPC 错误
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
+
+func (e *RpcError) Error() string {
+	if e.Data != nil {
+		return fmt.Sprintf("rpc error %d: %s: %v", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+type Header struct {
+	ParentHash     string `json:"parentHash"`
+	Number         string `json:"number"` // 十六进制
+	StateRoot      string `json:"stateRoot"`
+	ExtrinsicsRoot string `json:"extrinsicsRoot"`
+}
+
+func (h *Header) BlockNumber() (uint64, error) {
+	return strconv.ParseUint(strings.TrimPrefix(h.Number, "0x"), 16, 64)
+}
+
+type Block struct {
+	Header     Header   `json:"header"`
+	Extrinsics []string `json:"extrinsics"` // 十六进制编码
+}
+
+type RuntimeVersion struct {
+	SpecName           string `json:"specName"`
+	SpecVersion        uint32 `json:"specVersion"`
+	TransactionVersion uint32 `json:"transactionVersion"`
+}
+
+type rpcRequest struct {
+	JsonRpc string `json:"jsonrpc"`
+	Id      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RpcError       `json:"error"`
+	Id     uint64          `json:"id"`
+}
+
+// 定义 Substrate 节点接口
+type ISubstrate interface {
+	// 区块数据相关
+	GetBlockHash(number uint64) (string, error)
+	GetFinalizedHead() (string, error)
+	GetHeader(hash string) (*Header, error)
+	GetBlock(hash string) (*Block, error)
+	// 运行时
+	GetRuntimeVersion() (*RuntimeVersion, error)
+	GetMetadata() ([]byte, error)
+	GetStorage(key string) ([]byte, error)
+	// 交易
+	AccountNextIndex(address string) (uint64, error)
+	QueryFeeInfo(extrinsic string) (*big.Int, error)
+	SubmitExtrinsic(extrinsic string) (string, error)
+}
+
+type SubstrateClient struct {
+	url    string
+	client *http.Client
+	nextId atomic.Uint64
+}
+
+func NewSubstrateClient(rpcUrl string, timeout time.Duration) (ISubstrate, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &SubstrateClient{
+		url:    rpcUrl,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// 调用 JSON-RPC 方法并解析结果
+func (s *SubstrateClient) call(result any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(&rpcRequest{
+		JsonRpc: "2.0",
+		Id:      s.nextId.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("call %s fail, status %d: %s", method, resp.StatusCode, string(respBody))
+	}
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return err
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// 获取区块哈希，区块不存在时返回错误
+func (s *SubstrateClient) GetBlockHash(number uint64) (string, error) {
+	var hash *string
+	if err := s.call(&hash, "chain_getBlockHash", number); err != nil {
+		return "", err
+	}
+	if hash == nil {
+		return "", fmt.Errorf("block %d not found", number)
+	}
+	return *hash, nil
+}
+
+// 获取最新的已确认（finalized）区块哈希
+func (s *SubstrateClient) GetFinalizedHead() (string, error) {
+	var hash string
+	err := s.call(&hash, "chain_getFinalizedHead")
+	return hash, err
+}
+
+// 获取区块头，hash 为空时返回最新区块头
+func (s *SubstrateClient) GetHeader(hash string) (*Header, error) {
+	var header *Header
+	params := []any{}
+	if hash != "" {
+		params = append(params, hash)
+	}
+	if err := s.call(&header, "chain_getHeader", params...); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %s not found", hash)
+	}
+	return header, nil
+}
+
+func (s *SubstrateClient) GetBlock(hash string) (*Block, error) {
+	var result *struct {
+		Block Block `json:"block"`
+	}
+	if err := s.call(&result, "chain_getBlock", hash); err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("block %s not found", hash)
+	}
+	return &result.Block, nil
+}
+
+func (s *SubstrateClient) GetRuntimeVersion() (*RuntimeVersion, error) {
+	version := new(RuntimeVersion)
+	if err := s.call(version, "state_getRuntimeVersion"); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// 获取 SCALE 编码的运行时元数据
+func (s *SubstrateClient) GetMetadata() ([]byte, error) {
+	var metadata string
+	if err := s.call(&metadata, "state_getMetadata"); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimPrefix(metadata, "0x"))
+}
+
+// 读取存储，key 为十六进制，存储项不存在时返回 nil
+func (s *SubstrateClient) GetStorage(key string) ([]byte, error) {
+	var value *string
+	if err := s.call(&value, "state_getStorage", key); err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+	return hex.DecodeString(strings.TrimPrefix(*value, "0x"))
+}
+
+// 获取账户下一个可用的 nonce，包含交易池中的交易
+func (s *SubstrateClient) AccountNextIndex(address string) (uint64, error) {
+	var nonce uint64
+	err := s.call(&nonce, "system_accountNextIndex", address)
+	return nonce, err
+}
+
+// 估算交易手续费（partialFee），不含小费；节点按交易长度和权重计算，不校验签名
+func (s *SubstrateClient) QueryFeeInfo(extrinsic string) (*big.Int, error) {
+	var result struct {
+		PartialFee json.RawMessage `json:"partialFee"`
+	}
+	if err := s.call(&result, "payment_queryInfo", extrinsic); err != nil {
+		return nil, err
+	}
+	// 不同版本的节点返回数字或十进制字符串
+	fee, ok := new(big.Int).SetString(strings.Trim(string(result.PartialFee), `"`), 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid partial fee %s", result.PartialFee)
+	}
+	return fee, nil
+}
+
+// 广播十六进制编码的签名交易，返回交易哈希
+func (s *SubstrateClient) SubmitExtrinsic(extrinsic string) (string, error) {
+	var hash string
+	err := s.call(&hash, "author_submitExtrinsic", extrinsic)
+	return hash, err
+}
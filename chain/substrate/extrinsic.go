@@ -0,0 +1,257 @@
+package substrate
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// 签名交易版本：最高位表示已签名，低 7 位为交易格式版本 4
+const signedExtrinsicVersion = 0x84
+
+// MultiSignature 的签名类型
+const (
+	signatureEd25519 = 0x00
+	signatureSr25519 = 0x01
+)
+
+// 交易有效期（区块数），从最新的 finalized 区块开始计算
+const mortalPeriod = 64
+
+// 签名数据超过 256 字节时签名其 blake2b-256 哈希
+const maxSigningPayloadSize = 256
+
+// System.Account 存储键前缀：twox128("System") || twox128("Account")
+const systemAccountPrefix = "26aa394eea5630e07c48ae0c9558cef7b99d880ec681799c0cf30e8886371da9"
+
+// 待签名交易，call 和 extra 写入交易，additional 只参与签名
+type UnsignedExtrinsic struct {
+	Signer     string `json:"signer"`
+	KeyType    string `json:"key_type"`
+	Address    string `json:"address"` // 编码后的签名者地址参数
+	Call       string `json:"call"`
+	Extra      string `json:"extra"`
+	Additional string `json:"additional"`
+}
+
+type extrinsicParts struct {
+	address, call, extra, additional []byte
+}
+
+func (u *UnsignedExtrinsic) decode() (*extrinsicParts, error) {
+	var parts extrinsicParts
+	var err error
+	for _, item := range []struct {
+		dst   *[]byte
+		value string
+	}{{&parts.address, u.Address}, {&parts.call, u.Call}, {&parts.extra, u.Extra}, {&parts.additional, u.Additional}} {
+		if *item.dst, err = hex.DecodeString(strings.TrimPrefix(item.value, "0x")); err != nil {
+			return nil, err
+		}
+	}
+	if len(parts.address) == 0 || len(parts.call) < 2 {
+		return nil, errors.New("invalid unsigned extrinsic")
+	}
+	return &parts, nil
+}
+
+// 签名数据：call || extra || additional
+func (p *extrinsicParts) signingPayload() []byte {
+	payload := append(append(append([]byte{}, p.call...), p.extra...), p.additional...)
+	if len(payload) > maxSigningPayloadSize {
+		hash := blake2b.Sum256(payload)
+		return hash[:]
+	}
+	return payload
+}
+
+// 签名交易：compact(长度) || 0x84 || 地址 || 签名类型 || 签名 || extra || call
+func (p *extrinsicParts) encodeSigned(signatureType byte, signature []byte) []byte {
+	var body []byte
+	body = append(body, signedExtrinsicVersion)
+	body = append(body, p.address...)
+	body = append(body, signatureType)
+	body = append(body, signature...)
+	body = append(body, p.extra...)
+	body = append(body, p.call...)
+	return append(appendCompact(nil, uint64(len(body))), body...)
+}
+
+// 交易哈希为编码后交易的 blake2b-256
+func extrinsicHash(extrinsic []byte) string {
+	hash := blake2b.Sum256(extrinsic)
+	return "0x" + hex.EncodeToString(hash[:])
+}
+
+// 可变有效期（mortal era）编码，current 为起始区块
+func mortalEra(period, current uint64) []byte {
+	// period 取 2 的幂，范围 [4, 65536]
+	period = min(max(uint64(1)<<bits.Len64(period-1), 4), 1<<16)
+	phase := current % period
+	quantizeFactor := max(period>>12, 1)
+	encoded := uint16(min(max(bits.TrailingZeros64(period)-1, 1), 15)) | uint16(phase/quantizeFactor)<<4
+	return []byte{byte(encoded), byte(encoded >> 8)}
+}
+
+// 按交易扩展列表生成 extra 和 additional，未知的扩展只在两者都不占编码空间时允许
+func (m *Metadata) encodeSignedExtensions(era []byte, nonce uint64, tip *big.Int, specVersion, txVersion uint32, genesisHash, birthHash []byte) ([]byte, []byte, error) {
+	var extra, additional []byte
+	for _, ext := range m.SignedExtensions {
+		switch ext.Identifier {
+		case "CheckMortality", "CheckEra":
+			extra = append(extra, era...)
+			additional = append(additional, birthHash...)
+		case "CheckNonce":
+			extra = appendCompact(extra, nonce)
+		case "ChargeTransactionPayment":
+			extra = appendCompactBig(extra, tip)
+		case "ChargeAssetTxPayment":
+			// 小费 + 不使用资产支付手续费（None）
+			extra = appendCompactBig(extra, tip)
+			extra = append(extra, 0)
+		case "CheckMetadataHash":
+			// 不校验元数据哈希：mode = Disabled，additional = None
+			extra = append(extra, 0)
+			additional = append(additional, 0)
+		case "CheckSpecVersion":
+			additional = append(additional, byte(specVersion), byte(specVersion>>8), byte(specVersion>>16), byte(specVersion>>24))
+		case "CheckTxVersion":
+			additional = append(additional, byte(txVersion), byte(txVersion>>8), byte(txVersion>>16), byte(txVersion>>24))
+		case "CheckGenesis":
+			additional = append(additional, genesisHash...)
+		default:
+			if !m.isZeroSized(ext.Type) || !m.isZeroSized(ext.AdditionalSigned) {
+				return nil, nil, fmt.Errorf("unsupported signed extension %s", ext.Identifier)
+			}
+		}
+	}
+	return extra, additional, nil
+}
+
+// Balances 模块中解析的转账调用
+var transferCalls = map[string]bool{
+	"transfer_keep_alive":  true,
+	"transfer_allow_death": true,
+	"transfer":             true, // 旧版运行时
+}
+
+// 编码 balances.transferKeepAlive 调用，参数按元数据中的字段类型编码
+func (m *Metadata) encodeTransferCall(dest []byte, amount *big.Int) ([]byte, error) {
+	callIndex, call, err := m.FindCall("Balances", "transfer_keep_alive")
+	if err != nil {
+		return nil, err
+	}
+	encoded := callIndex[:]
+	for _, field := range call.Fields {
+		switch field.Name {
+		case "dest":
+			encoded = append(encoded, m.encodeAccount(field.Type, dest)...)
+		case "value":
+			encoded = appendCompactBig(encoded, amount)
+		default:
+			return nil, fmt.Errorf("unexpected field %s in transfer_keep_alive", field.Name)
+		}
+	}
+	return encoded, nil
+}
+
+// 解析签名交易中的余额转账，不是转账时返回 nil。只支持签名者和接收方为公钥地址（MultiAddress::Id）
+func (m *Metadata) decodeTransfer(extrinsic []byte) (*Transfer, error) {
+	d := &decoder{buf: extrinsic}
+	d.length()
+	if d.u8() != signedExtrinsicVersion {
+		return nil, d.err
+	}
+	from, ok := m.decodeAccount(d, m.addressType)
+	if !ok {
+		return nil, d.err
+	}
+	m.skip(d, m.signatureType)
+	for _, ext := range m.SignedExtensions {
+		m.skip(d, ext.Type)
+	}
+	palletIndex, callIndex := d.u8(), d.u8()
+	if d.err != nil {
+		return nil, d.err
+	}
+	pallet, ok := m.palletByIndex(palletIndex)
+	if !ok || pallet.Name != "Balances" || pallet.CallsType == nil {
+		return nil, nil
+	}
+	var call *typeVariant
+	for i, variant := range m.types[*pallet.CallsType].Variants {
+		if variant.Index == callIndex {
+			call = &m.types[*pallet.CallsType].Variants[i]
+		}
+	}
+	if call == nil || !transferCalls[call.Name] {
+		return nil, nil
+	}
+	var to []byte
+	var amount *big.Int
+	for _, field := range call.Fields {
+		switch field.Name {
+		case "dest":
+			if to, ok = m.decodeAccount(d, field.Type); !ok {
+				return nil, d.err
+			}
+		case "value":
+			amount = d.compactBig()
+		default:
+			m.skip(d, field.Type)
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	if to == nil || amount == nil {
+		return nil, nil
+	}
+	return &Transfer{From: from, To: to, Amount: amount}, nil
+}
+
+// 解析账户参数，MultiAddress 不是 Id 变体时返回 false
+func (m *Metadata) decodeAccount(d *decoder, typeId uint32) ([]byte, bool) {
+	index, ok := m.multiAddressIdIndex(typeId)
+	if !ok {
+		return d.bytes(accountIdSize), d.err == nil
+	}
+	if d.u8() != index {
+		return nil, false
+	}
+	return d.bytes(accountIdSize), d.err == nil
+}
+
+// 编码账户参数：MultiAddress 时为 Id 变体 + 公钥，否则为公钥
+func (m *Metadata) encodeAccount(typeId uint32, accountId []byte) []byte {
+	if index, ok := m.multiAddressIdIndex(typeId); ok {
+		return append([]byte{index}, accountId...)
+	}
+	return append([]byte{}, accountId...)
+}
+
+// System.Account 存储键：前缀 || blake2_128(accountId) || accountId
+func accountStorageKey(accountId []byte) string {
+	hasher, _ := blake2b.New(16, nil)
+	hasher.Write(accountId)
+	return "0x" + systemAccountPrefix + hex.EncodeToString(hasher.Sum(nil)) + hex.EncodeToString(accountId)
+}
+
+// 解析 AccountInfo：nonce、consumers、providers、sufficients 各 u32，随后是 free u128
+func decodeAccountInfo(data []byte) (uint32, *big.Int, error) {
+	d := &decoder{buf: data}
+	nonce := d.u32()
+	d.u32()
+	d.u32()
+	d.u32()
+	free := d.u128()
+	if d.err != nil {
+		return 0, nil, d.err
+	}
+	return nonce, free, nil
+}
@@ -0,0 +1,404 @@
+package substrate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+var metadataMagic = []byte("meta")
+
+// scale-info 类型定义的种类
+const (
+	typeDefComposite = iota
+	typeDefVariant
+	typeDefSequence
+	typeDefArray
+	typeDefTuple
+	typeDefPrimitive
+	typeDefCompact
+	typeDefBitSequence
+)
+
+type typeField struct {
+	Name string
+	Type uint32
+}
+
+type typeVariant struct {
+	Name   string
+	Index  uint8
+	Fields []typeField
+}
+
+// 泛型参数，Type 为空表示参数未指定具体类型
+type typeParam struct {
+	Name string
+	Type *uint32
+}
+
+type typeInfo struct {
+	Path      []string
+	Params    []typeParam
+	Def       uint8
+	Fields    []typeField   // Composite
+	Variants  []typeVariant // Variant
+	Elem      uint32        // Sequence、Array、Compact，BitSequence 为存储类型
+	Len       uint32        // Array
+	Tuple     []uint32
+	Primitive uint8
+}
+
+type palletInfo struct {
+	Name      string
+	Index     uint8
+	CallsType *uint32
+}
+
+// 交易扩展（signed extension），type 写入交易，additionalSigned 只参与签名
+type signedExtension struct {
+	Identifier       string
+	Type             uint32
+	AdditionalSigned uint32
+}
+
+// 运行时元数据，只解析构建和解析转账交易需要的类型、模块调用和交易扩展，支持 V14、V15
+type Metadata struct {
+	Version          uint8
+	types            map[uint32]*typeInfo
+	pallets          map[string]*palletInfo
+	SignedExtensions []signedExtension
+	// 交易中签名者地址和签名的类型
+	addressType   uint32
+	signatureType uint32
+}
+
+func DecodeMetadata(data []byte) (*Metadata, error) {
+	if !bytes.HasPrefix(data, metadataMagic) {
+		return nil, errors.New("invalid metadata magic")
+	}
+	d := &decoder{buf: data[len(metadataMagic):]}
+	meta := &Metadata{Version: d.u8(), types: map[uint32]*typeInfo{}, pallets: map[string]*palletInfo{}}
+	if meta.Version != 14 && meta.Version != 15 {
+		return nil, fmt.Errorf("unsupported metadata version %d", meta.Version)
+	}
+
+	numTypes := d.length()
+	for i := 0; i < numTypes && d.err == nil; i++ {
+		id := uint32(d.compact())
+		meta.types[id] = decodeType(d)
+	}
+
+	numPallets := d.length()
+	for i := 0; i < numPallets && d.err == nil; i++ {
+		pallet := &palletInfo{Name: d.string()}
+		if d.option() {
+			skipStorage(d)
+		}
+		if d.option() {
+			callsType := uint32(d.compact())
+			pallet.CallsType = &callsType
+		}
+		if d.option() {
+			d.compact() // event
+		}
+		numConstants := d.length()
+		for j := 0; j < numConstants && d.err == nil; j++ {
+			d.string()
+			d.compact()
+			d.byteSlice()
+			d.strings()
+		}
+		if d.option() {
+			d.compact() // error
+		}
+		pallet.Index = d.u8()
+		if meta.Version >= 15 {
+			d.strings()
+		}
+		meta.pallets[pallet.Name] = pallet
+	}
+
+	// V14 的地址和签名类型是交易类型 UncheckedExtrinsic<Address, Call, Signature, Extra> 的泛型参数
+	var extrinsicType uint32
+	if meta.Version == 14 {
+		extrinsicType = uint32(d.compact())
+		d.u8() // version
+	} else {
+		d.u8() // version
+		meta.addressType = uint32(d.compact())
+		d.compact() // call type
+		meta.signatureType = uint32(d.compact())
+		d.compact() // extra type
+	}
+	numExtensions := d.length()
+	for i := 0; i < numExtensions && d.err == nil; i++ {
+		meta.SignedExtensions = append(meta.SignedExtensions, signedExtension{
+			Identifier:       d.string(),
+			Type:             uint32(d.compact()),
+			AdditionalSigned: uint32(d.compact()),
+		})
+	}
+	if d.err != nil {
+		return nil, fmt.Errorf("decode metadata fail: %w", d.err)
+	}
+	if meta.Version == 14 {
+		extrinsic, ok := meta.types[extrinsicType]
+		if !ok {
+			return nil, errors.New("extrinsic type not found")
+		}
+		for _, param := range extrinsic.Params {
+			if param.Type == nil {
+				continue
+			}
+			switch param.Name {
+			case "Address":
+				meta.addressType = *param.Type
+			case "Signature":
+				meta.signatureType = *param.Type
+			}
+		}
+	}
+	return meta, nil
+}
+
+func decodeType(d *decoder) *typeInfo {
+	info := &typeInfo{Path: d.strings()}
+	numParams := d.length()
+	for i := 0; i < numParams && d.err == nil; i++ {
+		param := typeParam{Name: d.string()}
+		if d.option() {
+			paramType := uint32(d.compact())
+			param.Type = &paramType
+		}
+		info.Params = append(info.Params, param)
+	}
+	info.Def = d.u8()
+	switch info.Def {
+	case typeDefComposite:
+		info.Fields = decodeFields(d)
+	case typeDefVariant:
+		numVariants := d.length()
+		for i := 0; i < numVariants && d.err == nil; i++ {
+			variant := typeVariant{Name: d.string()}
+			variant.Fields = decodeFields(d)
+			variant.Index = d.u8()
+			d.strings()
+			info.Variants = append(info.Variants, variant)
+		}
+	case typeDefSequence, typeDefCompact:
+		info.Elem = uint32(d.compact())
+	case typeDefArray:
+		info.Len = d.u32()
+		info.Elem = uint32(d.compact())
+	case typeDefTuple:
+		numElems := d.length()
+		for i := 0; i < numElems && d.err == nil; i++ {
+			info.Tuple = append(info.Tuple, uint32(d.compact()))
+		}
+	case typeDefPrimitive:
+		info.Primitive = d.u8()
+	case typeDefBitSequence:
+		info.Elem = uint32(d.compact())
+		d.compact() // bit order
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("unknown type def %d", info.Def)
+		}
+	}
+	d.strings() // docs
+	return info
+}
+
+func decodeFields(d *decoder) []typeField {
+	numFields := d.length()
+	var fields []typeField
+	for i := 0; i < numFields && d.err == nil; i++ {
+		var field typeField
+		if d.option() {
+			field.Name = d.string()
+		}
+		field.Type = uint32(d.compact())
+		if d.option() {
+			d.string() // type name
+		}
+		d.strings()
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func skipStorage(d *decoder) {
+	d.string() // prefix
+	numEntries := d.length()
+	for i := 0; i < numEntries && d.err == nil; i++ {
+		d.string() // name
+		d.u8()     // modifier
+		switch d.u8() {
+		case 0: // Plain
+			d.compact()
+		case 1: // Map
+			d.byteSlice() // hashers，每个 hasher 一个字节
+			d.compact()
+			d.compact()
+		default:
+			if d.err == nil {
+				d.err = errors.New("unknown storage entry type")
+			}
+		}
+		d.byteSlice() // default
+		d.strings()
+	}
+}
+
+// 查找模块调用，返回 [模块索引, 调用索引] 和调用参数
+func (m *Metadata) FindCall(palletName, callName string) ([2]byte, *typeVariant, error) {
+	pallet, ok := m.pallets[palletName]
+	if !ok || pallet.CallsType == nil {
+		return [2]byte{}, nil, fmt.Errorf("pallet %s has no calls", palletName)
+	}
+	calls, ok := m.types[*pallet.CallsType]
+	if !ok || calls.Def != typeDefVariant {
+		return [2]byte{}, nil, fmt.Errorf("invalid call type for pallet %s", palletName)
+	}
+	for i := range calls.Variants {
+		if calls.Variants[i].Name == callName {
+			return [2]byte{pallet.Index, calls.Variants[i].Index}, &calls.Variants[i], nil
+		}
+	}
+	return [2]byte{}, nil, fmt.Errorf("call %s.%s not found", palletName, callName)
+}
+
+// 类型是否不占编码空间（空结构体、空元组、长度为 0 的数组）
+func (m *Metadata) isZeroSized(id uint32) bool {
+	return m.zeroSized(id, 0)
+}
+
+func (m *Metadata) zeroSized(id uint32, depth int) bool {
+	info, ok := m.types[id]
+	if !ok || depth > 16 {
+		return false
+	}
+	switch info.Def {
+	case typeDefComposite:
+		for _, field := range info.Fields {
+			if !m.zeroSized(field.Type, depth+1) {
+				return false
+			}
+		}
+		return true
+	case typeDefTuple:
+		for _, elem := range info.Tuple {
+			if !m.zeroSized(elem, depth+1) {
+				return false
+			}
+		}
+		return true
+	case typeDefArray:
+		return info.Len == 0
+	default:
+		return false
+	}
+}
+
+// 地址参数为 MultiAddress 时返回 Id 变体的索引
+func (m *Metadata) multiAddressIdIndex(id uint32) (uint8, bool) {
+	info, ok := m.types[id]
+	if !ok || info.Def != typeDefVariant {
+		return 0, false
+	}
+	for _, variant := range info.Variants {
+		if variant.Name == "Id" {
+			return variant.Index, true
+		}
+	}
+	return 0, false
+}
+
+// 按模块索引查找模块
+func (m *Metadata) palletByIndex(index uint8) (*palletInfo, bool) {
+	for _, pallet := range m.pallets {
+		if pallet.Index == index {
+			return pallet, true
+		}
+	}
+	return nil, false
+}
+
+// 基础类型的编码长度，str 为变长
+var primitiveSizes = map[uint8]int{
+	0: 1, 1: 4, 3: 1, 4: 2, 5: 4, 6: 8, 7: 16, 8: 32,
+	9: 1, 10: 2, 11: 4, 12: 8, 13: 16, 14: 32,
+}
+
+const primitiveStr = 2
+
+// 跳过一个指定类型的值，用于解析交易中不关心的部分（签名、交易扩展）
+func (m *Metadata) skip(d *decoder, id uint32) {
+	m.skipType(d, id, 0)
+}
+
+func (m *Metadata) skipType(d *decoder, id uint32, depth int) {
+	if d.err != nil {
+		return
+	}
+	info, ok := m.types[id]
+	if !ok || depth > 32 {
+		d.err = fmt.Errorf("cannot decode type %d", id)
+		return
+	}
+	switch info.Def {
+	case typeDefComposite:
+		for _, field := range info.Fields {
+			m.skipType(d, field.Type, depth+1)
+		}
+	case typeDefVariant:
+		index := d.u8()
+		for _, variant := range info.Variants {
+			if variant.Index == index {
+				for _, field := range variant.Fields {
+					m.skipType(d, field.Type, depth+1)
+				}
+				return
+			}
+		}
+		if d.err == nil {
+			d.err = fmt.Errorf("unknown variant %d of type %d", index, id)
+		}
+	case typeDefSequence:
+		n := d.length()
+		for i := 0; i < n && d.err == nil; i++ {
+			m.skipType(d, info.Elem, depth+1)
+		}
+	case typeDefArray:
+		for i := uint32(0); i < info.Len && d.err == nil; i++ {
+			m.skipType(d, info.Elem, depth+1)
+		}
+	case typeDefTuple:
+		for _, elem := range info.Tuple {
+			m.skipType(d, elem, depth+1)
+		}
+	case typeDefPrimitive:
+		if info.Primitive == primitiveStr {
+			d.byteSlice()
+			return
+		}
+		size, ok := primitiveSizes[info.Primitive]
+		if !ok {
+			d.err = fmt.Errorf("unknown primitive %d", info.Primitive)
+			return
+		}
+		d.bytes(size)
+	case typeDefCompact:
+		d.compactBig()
+	case typeDefBitSequence:
+		// 按存储类型的字长对齐
+		bitLen := d.compact()
+		storeSize := 1
+		if store, ok := m.types[info.Elem]; ok && store.Def == typeDefPrimitive {
+			storeSize = max(primitiveSizes[store.Primitive], 1)
+		}
+		storeBits := uint64(storeSize * 8)
+		d.bytes(int((bitLen+storeBits-1)/storeBits) * storeSize)
+	}
+}
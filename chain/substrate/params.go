@@ -0,0 +1,37 @@
+package substrate
+
+import "fmt"
+
+const (
+	PolkadotChainName = "Polkadot"
+	KusamaChainName   = "Kusama"
+)
+
+// 链参数，Networks 为各网络的 SS58 地址前缀
+type ChainParams struct {
+	Name     string
+	Networks map[string]uint16
+}
+
+var Polkadot = &ChainParams{
+	Name: PolkadotChainName,
+	// 测试网（Westend、Paseo）使用通用前缀 42
+	Networks: map[string]uint16{"mainnet": 0, "testnet": 42},
+}
+
+var Kusama = &ChainParams{
+	Name:     KusamaChainName,
+	Networks: map[string]uint16{"mainnet": 2},
+}
+
+// 网络对应的 SS58 前缀，network 为空时使用主网
+func (p *ChainParams) ss58Prefix(network string) (uint16, error) {
+	if network == "" {
+		network = "mainnet"
+	}
+	prefix, ok := p.Networks[network]
+	if !ok {
+		return 0, fmt.Errorf("%s does not support network %s", p.Name, network)
+	}
+	return prefix, nil
+}
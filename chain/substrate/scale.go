@@ -0,0 +1,169 @@
+package substrate
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// SCALE 紧凑编码
+func appendCompact(buf []byte, n uint64) []byte {
+	return appendCompactBig(buf, new(big.Int).SetUint64(n))
+}
+
+func appendCompactBig(buf []byte, n *big.Int) []byte {
+	switch {
+	case n.Cmp(big.NewInt(1<<6)) < 0:
+		return append(buf, byte(n.Uint64()<<2))
+	case n.Cmp(big.NewInt(1<<14)) < 0:
+		return binary.LittleEndian.AppendUint16(buf, uint16(n.Uint64()<<2|0b01))
+	case n.Cmp(big.NewInt(1<<30)) < 0:
+		return binary.LittleEndian.AppendUint32(buf, uint32(n.Uint64()<<2|0b10))
+	default:
+		// 大整数模式：首字节高 6 位为字节数 - 4
+		be := n.Bytes()
+		buf = append(buf, byte(len(be)-4)<<2|0b11)
+		for i := len(be) - 1; i >= 0; i-- {
+			buf = append(buf, be[i])
+		}
+		return buf
+	}
+}
+
+func appendBytes(buf []byte, data []byte) []byte {
+	buf = appendCompact(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+// SCALE 解码
+type decoder struct {
+	buf []byte
+	err error
+}
+
+var errUnexpectedEnd = errors.New("unexpected end of data")
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = errUnexpectedEnd
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) u8() uint8 {
+	b := d.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *decoder) u32() uint32 {
+	b := d.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (d *decoder) u128() *big.Int {
+	b := d.bytes(16)
+	if b == nil {
+		return new(big.Int)
+	}
+	be := make([]byte, 16)
+	for i := range b {
+		be[15-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+func (d *decoder) compact() uint64 {
+	first := d.u8()
+	switch first & 0b11 {
+	case 0b00:
+		return uint64(first >> 2)
+	case 0b01:
+		second := d.u8()
+		return uint64(binary.LittleEndian.Uint16([]byte{first, second}) >> 2)
+	case 0b10:
+		rest := d.bytes(3)
+		if rest == nil {
+			return 0
+		}
+		return uint64(binary.LittleEndian.Uint32([]byte{first, rest[0], rest[1], rest[2]}) >> 2)
+	default:
+		n := int(first>>2) + 4
+		b := d.bytes(n)
+		if n > 8 {
+			d.err = errors.New("compact integer overflows u64")
+			return 0
+		}
+		var v uint64
+		for i := len(b) - 1; i >= 0; i-- {
+			v = v<<8 | uint64(b[i])
+		}
+		return v
+	}
+}
+
+// 紧凑编码的大整数，如 Compact<u128> 类型的金额
+func (d *decoder) compactBig() *big.Int {
+	if d.err != nil || len(d.buf) == 0 || d.buf[0]&0b11 != 0b11 {
+		return new(big.Int).SetUint64(d.compact())
+	}
+	n := int(d.u8()>>2) + 4
+	b := d.bytes(n)
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+// 长度前缀不应超过剩余数据，避免恶意数据导致大量分配
+func (d *decoder) length() int {
+	n := d.compact()
+	if d.err == nil && n > uint64(len(d.buf)) {
+		d.err = errUnexpectedEnd
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) byteSlice() []byte {
+	return d.bytes(d.length())
+}
+
+func (d *decoder) string() string {
+	return string(d.byteSlice())
+}
+
+func (d *decoder) option() bool {
+	switch d.u8() {
+	case 0:
+		return false
+	case 1:
+		return true
+	default:
+		if d.err == nil {
+			d.err = errors.New("invalid option")
+		}
+		return false
+	}
+}
+
+func (d *decoder) strings() []string {
+	n := d.length()
+	var items []string
+	for i := 0; i < n && d.err == nil; i++ {
+		items = append(items, d.string())
+	}
+	return items
+}
@@ -0,0 +1,88 @@
+package substrate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"golang.org/x/crypto/blake2b"
+)
+
+const accountIdSize = 32
+
+var ss58Prefix = []byte("SS58PRE")
+
+// SS58 编码：base58(前缀 || 公钥 || blake2b-512("SS58PRE" || 前缀 || 公钥)[:2])
+func EncodeAddress(accountId []byte, prefix uint16) (string, error) {
+	if len(accountId) != accountIdSize {
+		return "", fmt.Errorf("invalid account id length %d", len(accountId))
+	}
+	var data []byte
+	switch {
+	case prefix < 64:
+		data = []byte{byte(prefix)}
+	case prefix < 16384:
+		data = []byte{byte((prefix&0xfc)>>2) | 0x40, byte(prefix>>8) | byte(prefix&0x03)<<6}
+	default:
+		return "", fmt.Errorf("invalid ss58 prefix %d", prefix)
+	}
+	data = append(data, accountId...)
+	checksum := ss58Checksum(data)
+	return base58.Encode(append(data, checksum[:2]...)), nil
+}
+
+// 解析 SS58 地址，返回账户公钥和网络前缀
+func DecodeAddress(address string) ([]byte, uint16, error) {
+	data := base58.Decode(address)
+	if len(data) < 3 {
+		return nil, 0, errors.New("invalid ss58 address")
+	}
+	var prefix uint16
+	prefixLen := 1
+	switch {
+	case data[0] < 64:
+		prefix = uint16(data[0])
+	case data[0] < 128:
+		prefixLen = 2
+		prefix = uint16(data[0]&0x3f)<<2 | uint16(data[1]>>6) | uint16(data[1]&0x3f)<<8
+	default:
+		return nil, 0, errors.New("invalid ss58 prefix")
+	}
+	if len(data) != prefixLen+accountIdSize+2 {
+		return nil, 0, errors.New("invalid ss58 address length")
+	}
+	body := data[:prefixLen+accountIdSize]
+	checksum := ss58Checksum(body)
+	if !bytes.Equal(checksum[:2], data[prefixLen+accountIdSize:]) {
+		return nil, 0, errors.New("invalid ss58 checksum")
+	}
+	return body[prefixLen:], prefix, nil
+}
+
+func ss58Checksum(data []byte) [blake2b.Size]byte {
+	return blake2b.Sum512(append(bytes.Clone(ss58Prefix), data...))
+}
+
+// 由 sr25519 或 ed25519 公钥生成地址，两种公钥都是 32 字节，地址格式相同
+func PubKeyToAddress(pubKeyHex string, prefix uint16) (string, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(pubKeyHex, "0x"))
+	if err != nil {
+		return "", err
+	}
+	return EncodeAddress(pubKey, prefix)
+}
+
+// 解析地址并校验网络前缀
+func decodeAddress(address string, prefix uint16) ([]byte, error) {
+	accountId, addressPrefix, err := DecodeAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	if addressPrefix != prefix {
+		return nil, fmt.Errorf("address %s has prefix %d, expected %d", address, addressPrefix, prefix)
+	}
+	return accountId, nil
+}
@@ -0,0 +1,478 @@
+package substrate
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+type ChainAdaptor struct {
+	SubstrateClient ISubstrate
+	Chain           *ChainParams
+	Ss58Prefix      uint16
+
+	// 按 specVersion 缓存元数据，运行时升级后重新获取
+	metaLock    sync.Mutex
+	meta        *Metadata
+	metaVersion uint32
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(Polkadot, con.WalletNode.Dot, con.NetWork)
+}
+
+func NewKusamaAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	return newChainAdaptor(Kusama, con.WalletNode.Ksm, con.NetWork)
+}
+
+// 按链参数创建 adaptor，节点未配置 network 时使用全局 network
+func newChainAdaptor(params *ChainParams, node config.Node, network string) (*ChainAdaptor, error) {
+	if node.Network != "" {
+		network = node.Network
+	}
+	prefix, err := params.ss58Prefix(network)
+	if err != nil {
+		return nil, err
+	}
+	substrateClient, err := NewSubstrateClient(node.RpcUrl, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		SubstrateClient: substrateClient,
+		Chain:           params,
+		Ss58Prefix:      prefix,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入 sr25519 或 ed25519 公钥 转换成 SS58 地址
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeSr25519, chain.KeyTypeEd25519); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	address, err := PubKeyToAddress(req.PublicKey, c.Ss58Prefix)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address,
+	}, nil
+}
+
+// 地址格式验证，需要匹配网络的 SS58 前缀
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if _, err := decodeAddress(req.Address, c.Ss58Prefix); err != nil {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 通过区块号获取区块数据，解析区块内的余额转账（不包含执行结果）
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	hash, err := c.SubstrateClient.GetBlockHash(uint64(req.Height))
+	if err != nil {
+		log.Error("get block hash fail", "height", req.Height, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return c.getBlockResponse(hash)
+}
+
+// 通过区块哈希获取区块数据
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return c.getBlockResponse(req.Hash)
+}
+
+// 通过区块号获取区块头信息，height 为 0 时返回最新区块
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	header, hash, err := c.getHeaderByNumber(uint64(req.Height))
+	if err != nil {
+		log.Error("get block header by number fail", "height", req.Height, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(header, hash),
+	}, nil
+}
+
+// 通过区块哈希获取区块头信息
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	header, err := c.SubstrateClient.GetHeader(req.Hash)
+	if err != nil {
+		log.Error("get block header by hash fail", "hash", req.Hash, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by hash success",
+		BlockHeader: toBlockHeader(header, req.Hash),
+	}, nil
+}
+
+// 获取区间内的区块头
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseUint(req.Start, 10, 64)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseUint(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for height := start; height <= end; height++ {
+		header, hash, err := c.getHeaderByNumber(height)
+		if err != nil {
+			log.Error("get block header fail", "height", height, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		headers = append(headers, toBlockHeader(header, hash))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取账户的可用余额（free）和下一个 nonce（包含交易池中的交易），账户不存在时余额为 0
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if req.ContractAddress != "" {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "assets are not supported",
+		}, nil
+	}
+	accountId, err := decodeAddress(req.Address, c.Ss58Prefix)
+	if err != nil {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	balance := "0"
+	data, err := c.SubstrateClient.GetStorage(accountStorageKey(accountId))
+	if err != nil {
+		log.Error("get account info fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	if data != nil {
+		_, free, err := decodeAccountInfo(data)
+		if err != nil {
+			log.Error("decode account info fail", "address", req.Address, "err", err)
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get account fail",
+			}, nil
+		}
+		balance = free.String()
+	}
+	nonce, err := c.SubstrateClient.AccountNextIndex(req.Address)
+	if err != nil {
+		log.Error("get account nonce fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      strconv.FormatUint(nonce, 10),
+		Balance:       balance,
+	}, nil
+}
+
+// 获取fee，单位为 Planck，通过 payment_queryInfo 估算，不含小费，三档返回相同结果。
+// 传入 rawTx（BuildUnSignTransaction 返回的交易）时估算该交易，否则按一笔转账估算
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	var unsigned *UnsignedExtrinsic
+	var err error
+	if req.RawTx != "" {
+		unsigned, err = decodeUnsignedExtrinsic(req.RawTx)
+	} else {
+		// 手续费与账户无关，使用全零账户构建
+		zeroAccount := make([]byte, accountIdSize)
+		unsigned, err = c.buildExtrinsic(zeroAccount, zeroAccount, chain.KeyTypeSr25519, big.NewInt(1), new(big.Int), 0)
+	}
+	if err != nil {
+		log.Error("build extrinsic fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	parts, err := unsigned.decode()
+	if err != nil {
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	// 估算不校验签名，使用空签名占位，交易长度与实际一致
+	signatureType, _ := signatureTypeOf(unsigned.KeyType)
+	extrinsic := parts.encodeSigned(signatureType, make([]byte, signatureSize))
+	fee, err := c.SubstrateClient.QueryFeeInfo("0x" + hex.EncodeToString(extrinsic))
+	if err != nil {
+		log.Error("query fee info fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get fee fail",
+		}, nil
+	}
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee.String(),
+		NormalFee: fee.String(),
+		FastFee:   fee.String(),
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的十六进制交易
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	rawTx := req.RawTx
+	if !strings.HasPrefix(rawTx, "0x") {
+		rawTx = "0x" + rawTx
+	}
+	if _, err := hex.DecodeString(rawTx[2:]); err != nil {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	txHash, err := c.SubstrateClient.SubmitExtrinsic(rawTx)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail: " + err.Error(),
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: txHash,
+	}, nil
+}
+
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get tx by address is not supported",
+	}, nil
+}
+
+// Substrate 节点不按交易哈希建立索引
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get tx by hash is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// 获取当前运行时的元数据，specVersion 变化时重新获取
+func (c *ChainAdaptor) metadata(specVersion uint32) (*Metadata, error) {
+	c.metaLock.Lock()
+	defer c.metaLock.Unlock()
+	if c.meta != nil && c.metaVersion == specVersion {
+		return c.meta, nil
+	}
+	data, err := c.SubstrateClient.GetMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("get metadata fail: %w", err)
+	}
+	meta, err := DecodeMetadata(data)
+	if err != nil {
+		return nil, err
+	}
+	c.meta, c.metaVersion = meta, specVersion
+	return meta, nil
+}
+
+// height 为 0 时获取最新区块头
+func (c *ChainAdaptor) getHeaderByNumber(height uint64) (*Header, string, error) {
+	if height == 0 {
+		latest, err := c.SubstrateClient.GetHeader("")
+		if err != nil {
+			return nil, "", err
+		}
+		if height, err = latest.BlockNumber(); err != nil {
+			return nil, "", err
+		}
+	}
+	hash, err := c.SubstrateClient.GetBlockHash(height)
+	if err != nil {
+		return nil, "", err
+	}
+	header, err := c.SubstrateClient.GetHeader(hash)
+	if err != nil {
+		return nil, "", err
+	}
+	return header, hash, nil
+}
+
+// 解析区块内的余额转账，使用当前运行时的元数据，无法解析的交易（如运行时升级前的交易）跳过
+func (c *ChainAdaptor) getBlockResponse(hash string) (*account.BlockResponse, error) {
+	block, err := c.SubstrateClient.GetBlock(hash)
+	if err != nil {
+		log.Error("get block fail", "hash", hash, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block fail",
+		}, nil
+	}
+	height, err := block.Header.BlockNumber()
+	if err != nil {
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid block number",
+		}, nil
+	}
+	version, err := c.SubstrateClient.GetRuntimeVersion()
+	if err != nil {
+		log.Error("get runtime version fail", "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block fail",
+		}, nil
+	}
+	meta, err := c.metadata(version.SpecVersion)
+	if err != nil {
+		log.Error("get metadata fail", "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block fail",
+		}, nil
+	}
+	var blockTxList []*account.BlockInfoTransactionList
+	for _, extrinsicHex := range block.Extrinsics {
+		extrinsic, err := hex.DecodeString(strings.TrimPrefix(extrinsicHex, "0x"))
+		if err != nil {
+			continue
+		}
+		transfer, err := meta.decodeTransfer(extrinsic)
+		if err != nil || transfer == nil {
+			continue
+		}
+		from, fromErr := EncodeAddress(transfer.From, c.Ss58Prefix)
+		to, toErr := EncodeAddress(transfer.To, c.Ss58Prefix)
+		if err := errors.Join(fromErr, toErr); err != nil {
+			continue
+		}
+		blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+			From:   from,
+			To:     to,
+			Hash:   extrinsicHash(extrinsic),
+			Height: height,
+			Amount: transfer.Amount.String(),
+		})
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          "get block success",
+		Height:       int64(height),
+		Hash:         hash,
+		Transactions: blockTxList,
+	}, nil
+}
+
+func toBlockHeader(header *Header, hash string) *account.BlockHeader {
+	number, _ := header.BlockNumber()
+	return &account.BlockHeader{
+		Hash:       hash,
+		ParentHash: header.ParentHash,
+		Root:       header.StateRoot,
+		TxHash:     header.ExtrinsicsRoot,
+		Number:     strconv.FormatUint(number, 10),
+	}
+}
@@ -0,0 +1,292 @@
+package substrate
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ChainSafe/go-schnorrkel"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// Alice 开发账户的 sr25519 公钥
+const alicePubKey = "d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"
+
+// 模拟节点，记录最后一次估算手续费的交易
+type fakeSubstrateNode struct {
+	ISubstrate
+	queried string
+}
+
+func (f *fakeSubstrateNode) GetRuntimeVersion() (*RuntimeVersion, error) {
+	return &RuntimeVersion{SpecName: "polkadot", SpecVersion: 1003000, TransactionVersion: 26}, nil
+}
+
+func (f *fakeSubstrateNode) GetBlockHash(number uint64) (string, error) {
+	return "0x" + strings.Repeat("11", 32), nil
+}
+
+func (f *fakeSubstrateNode) GetFinalizedHead() (string, error) {
+	return "0x" + strings.Repeat("22", 32), nil
+}
+
+func (f *fakeSubstrateNode) GetHeader(hash string) (*Header, error) {
+	return &Header{Number: "0x2a"}, nil
+}
+
+func (f *fakeSubstrateNode) AccountNextIndex(address string) (uint64, error) {
+	return 7, nil
+}
+
+func (f *fakeSubstrateNode) QueryFeeInfo(extrinsic string) (*big.Int, error) {
+	f.queried = extrinsic
+	return big.NewInt(156000000), nil
+}
+
+// 构造 Polkadot 运行时中与转账相关的类型
+func newTestMetadata() *Metadata {
+	era := &typeInfo{Def: typeDefVariant, Variants: []typeVariant{{Name: "Immortal", Index: 0}}}
+	for i := 1; i < 256; i++ {
+		era.Variants = append(era.Variants, typeVariant{Name: "Mortal", Index: uint8(i), Fields: []typeField{{Type: 2}}})
+	}
+	callsType := uint32(6)
+	return &Metadata{
+		Version: 14,
+		types: map[uint32]*typeInfo{
+			0: {Path: []string{"sp_core", "crypto", "AccountId32"}, Def: typeDefComposite, Fields: []typeField{{Type: 1}}},
+			1: {Def: typeDefArray, Len: 32, Elem: 2},
+			2: {Def: typeDefPrimitive, Primitive: 3},
+			3: {Def: typeDefVariant, Variants: []typeVariant{
+				{Name: "Id", Index: 0, Fields: []typeField{{Type: 0}}},
+				{Name: "Index", Index: 1, Fields: []typeField{{Type: 10}}},
+			}},
+			4: {Def: typeDefCompact, Elem: 5},
+			5: {Def: typeDefPrimitive, Primitive: 7},
+			6: {Def: typeDefVariant, Variants: []typeVariant{
+				{Name: "transfer_allow_death", Index: 0, Fields: []typeField{{Name: "dest", Type: 3}, {Name: "value", Type: 4}}},
+				{Name: "transfer_keep_alive", Index: 3, Fields: []typeField{{Name: "dest", Type: 3}, {Name: "value", Type: 4}}},
+				{Name: "transfer_all", Index: 4, Fields: []typeField{{Name: "dest", Type: 3}, {Name: "keep_alive", Type: 15}}},
+			}},
+			7: {Def: typeDefVariant, Variants: []typeVariant{
+				{Name: "Ed25519", Index: 0, Fields: []typeField{{Type: 8}}},
+				{Name: "Sr25519", Index: 1, Fields: []typeField{{Type: 8}}},
+			}},
+			8:  {Def: typeDefArray, Len: 64, Elem: 2},
+			9:  era,
+			10: {Def: typeDefCompact, Elem: 11},
+			11: {Def: typeDefPrimitive, Primitive: 5},
+			12: {Def: typeDefComposite, Fields: []typeField{{Type: 4}}},
+			13: {Def: typeDefTuple},
+			14: {Def: typeDefArray, Len: 32, Elem: 2},
+			15: {Def: typeDefPrimitive, Primitive: 0},
+		},
+		pallets: map[string]*palletInfo{
+			"System":   {Name: "System", Index: 0},
+			"Balances": {Name: "Balances", Index: 5, CallsType: &callsType},
+		},
+		SignedExtensions: []signedExtension{
+			{Identifier: "CheckNonZeroSender", Type: 13, AdditionalSigned: 13},
+			{Identifier: "CheckSpecVersion", Type: 13, AdditionalSigned: 11},
+			{Identifier: "CheckTxVersion", Type: 13, AdditionalSigned: 11},
+			{Identifier: "CheckGenesis", Type: 13, AdditionalSigned: 14},
+			{Identifier: "CheckMortality", Type: 9, AdditionalSigned: 14},
+			{Identifier: "CheckNonce", Type: 10, AdditionalSigned: 13},
+			{Identifier: "CheckWeight", Type: 13, AdditionalSigned: 13},
+			{Identifier: "ChargeTransactionPayment", Type: 12, AdditionalSigned: 13},
+		},
+		addressType:   3,
+		signatureType: 7,
+	}
+}
+
+func newTestAdaptor(node ISubstrate) *ChainAdaptor {
+	return &ChainAdaptor{
+		SubstrateClient: node,
+		Chain:           Polkadot,
+		Ss58Prefix:      0,
+		meta:            newTestMetadata(),
+		metaVersion:     1003000,
+	}
+}
+
+func buildUnSigned(t *testing.T, adaptor *ChainAdaptor, transferTx SubstrateTransferTx) *account.UnSignTransactionResponse {
+	txJson, _ := json.Marshal(transferTx)
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	return resp
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	adaptor := newTestAdaptor(nil)
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: alicePubKey})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Address != "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5" {
+		t.Fatalf("unexpected address %s", resp.Address)
+	}
+	// 测试网使用通用前缀 42
+	generic, _ := PubKeyToAddress(alicePubKey, 42)
+	if generic != "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY" {
+		t.Fatalf("unexpected generic address %s", generic)
+	}
+	valid, _ := adaptor.ValidAddress(&account.ValidAddressRequest{Address: generic})
+	if valid.Valid {
+		t.Fatal("expected address with other prefix to be invalid")
+	}
+	// 两字节前缀
+	address, _ := PubKeyToAddress(alicePubKey, 2254)
+	accountId, prefix, err := DecodeAddress(address)
+	if err != nil || prefix != 2254 || hex.EncodeToString(accountId) != alicePubKey {
+		t.Fatalf("unexpected decoded address %x %d %v", accountId, prefix, err)
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: alicePubKey, KeyType: "secp256k1"})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected secp256k1 key type to be rejected")
+	}
+}
+
+func Test_ScaleEncoding(t *testing.T) {
+	for value, want := range map[uint64]string{
+		0:          "00",
+		1:          "04",
+		63:         "fc",
+		64:         "0101",
+		16383:      "fdff",
+		16384:      "02000100",
+		1073741823: "feffffff",
+		1073741824: "0300000040",
+	} {
+		encoded := appendCompact(nil, value)
+		if hex.EncodeToString(encoded) != want {
+			t.Fatalf("compact %d: got %x, want %s", value, encoded, want)
+		}
+		d := &decoder{buf: encoded}
+		if got := d.compactBig(); d.err != nil || got.Uint64() != value {
+			t.Fatalf("decode compact %s: got %s", want, got)
+		}
+	}
+	// 超过 u64 的 u128 金额
+	amount, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	d := &decoder{buf: appendCompactBig(nil, amount)}
+	if got := d.compactBig(); got.Cmp(amount) != 0 {
+		t.Fatalf("unexpected u128 %s", got)
+	}
+}
+
+func Test_MortalEra(t *testing.T) {
+	// 周期 64，起始区块 42：低 4 位为 log2(64)-1=5，高 12 位为相位 42
+	if era := hex.EncodeToString(mortalEra(64, 42)); era != "a502" {
+		t.Fatalf("unexpected era %s", era)
+	}
+	// 周期向上取 2 的幂
+	if era := hex.EncodeToString(mortalEra(100, 1000)); era != hex.EncodeToString(mortalEra(128, 1000)) {
+		t.Fatalf("unexpected era %s", era)
+	}
+}
+
+func Test_BuildTransaction(t *testing.T) {
+	secretKey, publicKey, err := schnorrkel.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := publicKey.Encode()
+	from, _ := EncodeAddress(pubKey[:], 0)
+	to, _ := PubKeyToAddress(alicePubKey, 0)
+	node := &fakeSubstrateNode{}
+	adaptor := newTestAdaptor(node)
+
+	unSigned := buildUnSigned(t, adaptor, SubstrateTransferTx{FromAddress: from, ToAddress: to, Amount: "12345000000", Tip: "100"})
+	unsigned, _ := decodeUnsignedExtrinsic(unSigned.UnSignTx)
+	if unsigned.KeyType != "sr25519" || unsigned.Signer != from {
+		t.Fatalf("unexpected unsigned extrinsic %+v", unsigned)
+	}
+	// Balances(5).transfer_keep_alive(3)，MultiAddress::Id(0) + 公钥，compact 金额
+	wantCall := "0503" + "00" + alicePubKey + hex.EncodeToString(appendCompact(nil, 12345000000))
+	if unsigned.Call != wantCall {
+		t.Fatalf("unexpected call %s", unsigned.Call)
+	}
+	// era || compact(nonce 7) || compact(tip 100)
+	if unsigned.Extra != "a502"+"1c"+"9101" {
+		t.Fatalf("unexpected extra %s", unsigned.Extra)
+	}
+	// specVersion || txVersion || 创世哈希 || 起始区块哈希
+	wantAdditional := "f84d0f00" + "1a000000" + strings.Repeat("11", 32) + strings.Repeat("22", 32)
+	if unsigned.Additional != wantAdditional {
+		t.Fatalf("unexpected additional %s", unsigned.Additional)
+	}
+	payload, _ := hex.DecodeString(unSigned.SignHashes[0])
+	if hex.EncodeToString(payload) != wantCall+unsigned.Extra+wantAdditional {
+		t.Fatal("unexpected signing payload")
+	}
+
+	// 手续费估算使用同样长度的交易
+	fee, _ := adaptor.GetFee(&account.FeeRequest{RawTx: unSigned.UnSignTx})
+	if fee.Code != global_const.ReturnCode_SUCCESS || fee.NormalFee != "156000000" {
+		t.Fatalf("unexpected fee %+v", fee)
+	}
+
+	// 其他私钥的签名拒绝
+	otherKey, _, _ := schnorrkel.GenerateKeypair()
+	wrong, _ := otherKey.Sign(schnorrkel.NewSigningContext(sr25519SigningContext, payload))
+	wrongBytes := wrong.Encode()
+	rejected, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signature: hex.EncodeToString(wrongBytes[:])})
+	if rejected.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected signature from other key to be rejected")
+	}
+
+	signature, _ := secretKey.Sign(schnorrkel.NewSigningContext(sr25519SigningContext, payload))
+	signatureBytes := signature.Encode()
+	signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signature: hex.EncodeToString(signatureBytes[:])})
+	if signed.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", signed.Msg)
+	}
+	if len(signed.SignedTx) != len(node.queried) {
+		t.Fatal("expected fee to be estimated with a same-length extrinsic")
+	}
+	extrinsic, _ := hex.DecodeString(strings.TrimPrefix(signed.SignedTx, "0x"))
+	if signed.Msg != extrinsicHash(extrinsic) {
+		t.Fatalf("unexpected tx hash %s", signed.Msg)
+	}
+
+	// 区块解析得到同一笔转账
+	transfer, err := adaptor.meta.decodeTransfer(extrinsic)
+	if err != nil || transfer == nil {
+		t.Fatalf("decode transfer fail: %v", err)
+	}
+	if !bytes.Equal(transfer.From, pubKey[:]) || hex.EncodeToString(transfer.To) != alicePubKey || transfer.Amount.String() != "12345000000" {
+		t.Fatalf("unexpected transfer %+v", transfer)
+	}
+}
+
+func Test_BuildTransactionEd25519(t *testing.T) {
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	from, _ := EncodeAddress(pubKey, 0)
+	to, _ := PubKeyToAddress(alicePubKey, 0)
+	adaptor := newTestAdaptor(&fakeSubstrateNode{})
+
+	nonce := uint64(0)
+	unSigned := buildUnSigned(t, adaptor, SubstrateTransferTx{FromAddress: from, ToAddress: to, Amount: "1", KeyType: "ed25519", Nonce: &nonce})
+	payload, _ := hex.DecodeString(unSigned.SignHashes[0])
+	signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signature: hex.EncodeToString(ed25519.Sign(privKey, payload))})
+	if signed.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", signed.Msg)
+	}
+	extrinsic, _ := hex.DecodeString(strings.TrimPrefix(signed.SignedTx, "0x"))
+	// compact(长度) || 0x84 || Id(0) + 公钥 || Ed25519(0) + 签名
+	d := &decoder{buf: extrinsic}
+	d.length()
+	if d.u8() != signedExtrinsicVersion || d.u8() != 0 || !bytes.Equal(d.bytes(32), pubKey) || d.u8() != signatureEd25519 {
+		t.Fatal("unexpected signed extrinsic")
+	}
+	transfer, err := adaptor.meta.decodeTransfer(extrinsic)
+	if err != nil || transfer == nil || transfer.Amount.String() != "1" {
+		t.Fatalf("decode transfer fail: %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package substrate
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+const signatureSize = 64
+
+// sr25519 签名使用的签名上下文
+var sr25519SigningContext = []byte("substrate")
+
+// 构建未签名交易：un_sign_tx 为 base64 编码的 UnsignedExtrinsic，sign_hashes 为待签名数据（十六进制），
+// 超过 256 字节时为其 blake2b-256 哈希。交易有效期为 64 个区块
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx SubstrateTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	unsigned, err := c.buildTransfer(&transferTx)
+	if err != nil {
+		log.Error("build extrinsic fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	parts, err := unsigned.decode()
+	if err != nil {
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	unsignedJson, _ := json.Marshal(unsigned)
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   base64.StdEncoding.EncodeToString(unsignedJson),
+		SignHashes: []string{hex.EncodeToString(parts.signingPayload())},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的交易，signature 为 64 字节签名，按 key_type 校验。
+// 返回的 signed_tx 为十六进制编码的交易，msg 为交易哈希
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	unsigned, err := decodeUnsignedExtrinsic(req.Base64Tx)
+	if err != nil {
+		log.Error("decode unsigned extrinsic fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned extrinsic fail",
+		}, nil
+	}
+	parts, err := unsigned.decode()
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned extrinsic fail",
+		}, nil
+	}
+	signer, err := decodeAddress(unsigned.Signer, c.Ss58Prefix)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signer",
+		}, nil
+	}
+	signatureType, err := signatureTypeOf(unsigned.KeyType)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != signatureSize || !verifySignature(unsigned.KeyType, signer, parts.signingPayload(), signature) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	extrinsic := parts.encodeSigned(signatureType, signature)
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      extrinsicHash(extrinsic),
+		SignedTx: "0x" + hex.EncodeToString(extrinsic),
+	}, nil
+}
+
+// 构建 balances.transferKeepAlive 交易，转账后发送方余额不能低于存在性押金
+func (c *ChainAdaptor) buildTransfer(transferTx *SubstrateTransferTx) (*UnsignedExtrinsic, error) {
+	keyType, err := chain.CheckKeyType(transferTx.KeyType, chain.KeyTypeSr25519, chain.KeyTypeEd25519)
+	if err != nil {
+		return nil, err
+	}
+	from, err := decodeAddress(transferTx.FromAddress, c.Ss58Prefix)
+	if err != nil {
+		return nil, errors.New("invalid from address")
+	}
+	to, err := decodeAddress(transferTx.ToAddress, c.Ss58Prefix)
+	if err != nil {
+		return nil, errors.New("invalid to address")
+	}
+	amount, ok := new(big.Int).SetString(transferTx.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+	tip := new(big.Int)
+	if transferTx.Tip != "" {
+		if tip, ok = new(big.Int).SetString(transferTx.Tip, 10); !ok || tip.Sign() < 0 {
+			return nil, errors.New("invalid tip")
+		}
+	}
+	var nonce uint64
+	if transferTx.Nonce != nil {
+		nonce = *transferTx.Nonce
+	} else if nonce, err = c.SubstrateClient.AccountNextIndex(transferTx.FromAddress); err != nil {
+		return nil, fmt.Errorf("get nonce fail: %w", err)
+	}
+	return c.buildExtrinsic(from, to, keyType, amount, tip, nonce)
+}
+
+// 按当前运行时构建转账交易，有效期从最新的 finalized 区块开始
+func (c *ChainAdaptor) buildExtrinsic(from, to []byte, keyType string, amount, tip *big.Int, nonce uint64) (*UnsignedExtrinsic, error) {
+	version, err := c.SubstrateClient.GetRuntimeVersion()
+	if err != nil {
+		return nil, fmt.Errorf("get runtime version fail: %w", err)
+	}
+	meta, err := c.metadata(version.SpecVersion)
+	if err != nil {
+		return nil, err
+	}
+	genesisHash, err := c.blockHash(0)
+	if err != nil {
+		return nil, fmt.Errorf("get genesis hash fail: %w", err)
+	}
+	finalizedHash, err := c.SubstrateClient.GetFinalizedHead()
+	if err != nil {
+		return nil, fmt.Errorf("get finalized head fail: %w", err)
+	}
+	finalized, err := c.SubstrateClient.GetHeader(finalizedHash)
+	if err != nil {
+		return nil, fmt.Errorf("get finalized header fail: %w", err)
+	}
+	finalizedNumber, err := finalized.BlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	birthHash, err := hex.DecodeString(strings.TrimPrefix(finalizedHash, "0x"))
+	if err != nil {
+		return nil, err
+	}
+
+	call, err := meta.encodeTransferCall(to, amount)
+	if err != nil {
+		return nil, err
+	}
+	extra, additional, err := meta.encodeSignedExtensions(mortalEra(mortalPeriod, finalizedNumber), nonce, tip,
+		version.SpecVersion, version.TransactionVersion, genesisHash, birthHash)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := EncodeAddress(from, c.Ss58Prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &UnsignedExtrinsic{
+		Signer:     signer,
+		KeyType:    keyType,
+		Address:    hex.EncodeToString(meta.encodeAccount(meta.addressType, from)),
+		Call:       hex.EncodeToString(call),
+		Extra:      hex.EncodeToString(extra),
+		Additional: hex.EncodeToString(additional),
+	}, nil
+}
+
+func (c *ChainAdaptor) blockHash(number uint64) ([]byte, error) {
+	hash, err := c.SubstrateClient.GetBlockHash(number)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(strings.TrimPrefix(hash, "0x"))
+}
+
+func decodeUnsignedExtrinsic(base64Tx string) (*UnsignedExtrinsic, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var unsigned UnsignedExtrinsic
+	if err := json.Unmarshal(txJson, &unsigned); err != nil {
+		return nil, err
+	}
+	return &unsigned, nil
+}
+
+// 公钥类型对应的 MultiSignature 变体
+func signatureTypeOf(keyType string) (byte, error) {
+	switch keyType {
+	case chain.KeyTypeSr25519, "":
+		return signatureSr25519, nil
+	case chain.KeyTypeEd25519:
+		return signatureEd25519, nil
+	default:
+		return 0, fmt.Errorf("unsupported key type: %s", keyType)
+	}
+}
+
+func verifySignature(keyType string, pubKey, payload, signature []byte) bool {
+	if keyType == chain.KeyTypeEd25519 {
+		return ed25519.Verify(pubKey, payload, signature)
+	}
+	publicKey, err := schnorrkel.NewPublicKey([32]byte(pubKey))
+	if err != nil {
+		return false
+	}
+	var sig schnorrkel.Signature
+	if err := sig.Decode([64]byte(signature)); err != nil {
+		return false
+	}
+	ok, err := publicKey.Verify(&sig, schnorrkel.NewSigningContext(sr25519SigningContext, payload))
+	return err == nil && ok
+}
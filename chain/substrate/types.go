@@ -0,0 +1,23 @@
+package substrate
+
+import "math/big"
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type SubstrateTransferTx struct {
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	Amount      string `json:"amount"` // 最小单位（Planck）
+	// 签名类型：sr25519、ed25519，为空时使用 sr25519
+	KeyType string `json:"key_type"`
+	// 小费，为空时为 0
+	Tip string `json:"tip"`
+	// 为空时通过节点获取下一个 nonce
+	Nonce *uint64 `json:"nonce"`
+}
+
+// 交易中的余额转账，From、To 为账户公钥
+type Transfer struct {
+	From   []byte
+	To     []byte
+	Amount *big.Int
+}
@@ -0,0 +1,87 @@
+package sui
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"golang.org/x/crypto/blake2b"
+
+	"chain-account/chain"
+)
+
+// 签名方案标志位
+const (
+	flagEd25519   = 0x00
+	flagSecp256k1 = 0x01
+)
+
+// 账户地址，32 字节
+type Address [32]byte
+
+// 统一为 0x + 64 位十六进制
+func (a Address) String() string {
+	return "0x" + hex.EncodeToString(a[:])
+}
+
+// 解析 0x 开头的地址，允许省略前导 0（如 0x2）
+func ParseAddress(address string) (Address, error) {
+	var a Address
+	hexStr, ok := strings.CutPrefix(address, "0x")
+	if !ok || len(hexStr) == 0 || len(hexStr) > 64 {
+		return a, errors.New("invalid address")
+	}
+	if len(hexStr)%2 == 1 {
+		hexStr = "0" + hexStr
+	}
+	data, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return a, errors.New("invalid address")
+	}
+	copy(a[32-len(data):], data)
+	return a, nil
+}
+
+// 账户地址必须是完整的 0x + 64 位十六进制
+func ValidateAddress(address string) bool {
+	_, err := ParseAddress(address)
+	return err == nil && len(address) == 66
+}
+
+// 签名方案和公钥，secp256k1 公钥统一为 33 字节压缩格式
+type PublicKey struct {
+	Flag byte
+	Key  []byte
+}
+
+// 按密钥类型解析十六进制公钥，keyType 为空时按长度识别：32 字节为 ed25519，33 或 65 字节为 secp256k1
+func ParsePublicKey(publicKey, keyType string) (*PublicKey, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(publicKey, "0x"))
+	if err != nil {
+		return nil, errors.New("invalid public key")
+	}
+	if keyType == "" && len(pubKey) != ed25519.PublicKeySize {
+		keyType = chain.KeyTypeSecp256k1
+	}
+	switch keyType {
+	case "", chain.KeyTypeEd25519:
+		if len(pubKey) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 public key")
+		}
+		return &PublicKey{Flag: flagEd25519, Key: pubKey}, nil
+	case chain.KeyTypeSecp256k1:
+		key, err := btcec.ParsePubKey(pubKey)
+		if err != nil {
+			return nil, errors.New("invalid secp256k1 public key")
+		}
+		return &PublicKey{Flag: flagSecp256k1, Key: key.SerializeCompressed()}, nil
+	}
+	return nil, errors.New("unsupported key type")
+}
+
+// 地址为 blake2b-256(标志位 || 公钥)
+func (p *PublicKey) Address() Address {
+	return blake2b.Sum256(append([]byte{p.Flag}, p.Key...))
+}
@@ -0,0 +1,569 @@
+package sui
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Sui"
+
+// SUI 的 coin 类型
+const suiCoinType = "0x2::sui::SUI"
+
+// 单次 GetBlockByRange 最多查询的检查点数，每个检查点需要一次请求
+const blockRangeLimit = 100
+
+// GetFee 估算转账手续费使用的计算单位和存储费用（新建 coin 对象），单位 MIST
+const (
+	transferComputationUnits = 1000
+	transferStorageCost      = 2_000_000
+)
+
+// GetTxByAddress 默认每页数量
+const defaultPageSize = 20
+
+type ChainAdaptor struct {
+	SuiClient ISui
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.Sui
+	suiClient, err := NewSuiClient(node.RpcUrl, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		SuiClient: suiClient,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入公钥 转换成地址，key_type 支持 ed25519（默认）和 secp256k1
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	keyType, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeEd25519, chain.KeyTypeSecp256k1)
+	if err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	pubKey, err := ParsePublicKey(req.PublicKey, keyType)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: pubKey.Address().String(),
+	}, nil
+}
+
+// 地址格式验证
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 以检查点作为区块，height 为检查点序号
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	return c.getBlock(strconv.FormatInt(req.Height, 10), "get block by number")
+}
+
+// 通过检查点摘要获取区块
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return c.getBlock(req.Hash, "get block by hash")
+}
+
+// 通过检查点序号获取区块头信息，height 为 0 时返回最新检查点
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	sequence := uint64(req.Height)
+	if sequence == 0 {
+		latest, err := c.SuiClient.GetLatestCheckpointSequenceNumber()
+		if err != nil {
+			log.Error("get latest checkpoint fail", "err", err)
+			return &account.BlockHeaderResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block header by number fail",
+			}, nil
+		}
+		sequence = latest
+	}
+	checkpoint, err := c.SuiClient.GetCheckpoint(strconv.FormatUint(sequence, 10))
+	if err != nil {
+		log.Error("get block header by number fail", "sequence", sequence, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(checkpoint),
+	}, nil
+}
+
+// 通过检查点摘要获取区块头信息
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	checkpoint, err := c.SuiClient.GetCheckpoint(req.Hash)
+	if err != nil {
+		log.Error("get block header by hash fail", "hash", req.Hash, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by hash success",
+		BlockHeader: toBlockHeader(checkpoint),
+	}, nil
+}
+
+// 获取区间内的检查点头
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseUint(req.Start, 10, 64)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseUint(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	if end-start >= blockRangeLimit {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "block range too large",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for sequence := start; sequence <= end; sequence++ {
+		checkpoint, err := c.SuiClient.GetCheckpoint(strconv.FormatUint(sequence, 10))
+		if err != nil {
+			log.Error("get checkpoint fail", "sequence", sequence, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		headers = append(headers, toBlockHeader(checkpoint))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取余额，contract_address 为 coin 类型，为空时查询 SUI。Sui 账户没有 nonce，sequence 固定为 0
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	coinType := req.ContractAddress
+	if coinType == "" {
+		coinType = suiCoinType
+	}
+	balance, err := c.SuiClient.GetBalance(req.Address, coinType)
+	if err != nil {
+		log.Error("get balance fail", "address", req.Address, "coin_type", coinType, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get balance fail",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Balance:       balance.TotalBalance,
+	}, nil
+}
+
+// 获取fee，单位 MIST。
+// 传入 rawTx（BuildUnSignTransaction 返回的 un_sign_tx）时返回该交易的 gas 预算；
+// 否则按参考 gas 单价估算一笔转账的手续费，三档相同
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	if req.RawTx != "" {
+		txBytes, err := base64.StdEncoding.DecodeString(req.RawTx)
+		if err != nil {
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		tx, err := DeserializeTransactionData(txBytes)
+		if err != nil {
+			log.Error("decode transaction data fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		fee := strconv.FormatUint(tx.GasBudget, 10)
+		return &account.FeeResponse{
+			Code:      global_const.ReturnCode_SUCCESS,
+			Msg:       "get fee success",
+			SlowFee:   fee,
+			NormalFee: fee,
+			FastFee:   fee,
+		}, nil
+	}
+	gasPrice, err := c.SuiClient.GetReferenceGasPrice()
+	if err != nil {
+		log.Error("get reference gas price fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get reference gas price fail",
+		}, nil
+	}
+	fee := strconv.FormatUint(gasPrice*transferComputationUnits+transferStorageCost, 10)
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee,
+		NormalFee: fee,
+		FastFee:   fee,
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的 signed_tx
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	signedJson, err := base64.StdEncoding.DecodeString(req.RawTx)
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	var signed SignedTransaction
+	if err := json.Unmarshal(signedJson, &signed); err != nil || len(signed.Signatures) == 0 {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	txBytes, err := base64.StdEncoding.DecodeString(signed.TxBytes)
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	result, err := c.SuiClient.ExecuteTransactionBlock(txBytes, signed.Signatures)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: result.Digest,
+	}, nil
+}
+
+// 按地址分页查询发出或接收的交易，按时间倒序，page 从 1 开始，翻页需要逐页请求
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.TxAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	filter := map[string]any{"FromOrToAddress": map[string]string{"addr": req.Address}}
+	var cursor *string
+	var page *TransactionBlockPage
+	for i := uint32(1); i <= max(req.Page, 1); i++ {
+		var err error
+		if page, err = c.SuiClient.QueryTransactionBlocks(filter, cursor, pageSize); err != nil {
+			log.Error("query transaction blocks fail", "address", req.Address, "err", err)
+			return &account.TxAddressResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get tx by address fail",
+			}, nil
+		}
+		if !page.HasNextPage && i < req.Page {
+			page = &TransactionBlockPage{}
+			break
+		}
+		cursor = page.NextCursor
+	}
+	var txs []*account.TxMessage
+	for i := range page.Data {
+		txs = append(txs, toTxMessage(&page.Data[i]))
+	}
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txs,
+	}, nil
+}
+
+// 通过交易摘要获取交易，height 为所在检查点序号
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	tx, err := c.SuiClient.GetTransactionBlock(req.Hash)
+	if IsNotFound(err) {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get transaction fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   toTxMessage(tx),
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// 获取检查点及其中成功执行的交易的转账
+func (c *ChainAdaptor) getBlock(id string, action string) (*account.BlockResponse, error) {
+	checkpoint, err := c.SuiClient.GetCheckpoint(id)
+	if err != nil {
+		log.Error(action+" fail", "id", id, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  action + " fail",
+		}, nil
+	}
+	height, _ := strconv.ParseUint(checkpoint.SequenceNumber, 10, 64)
+	txs, err := c.SuiClient.MultiGetTransactionBlocks(checkpoint.Transactions)
+	if err != nil {
+		log.Error("get checkpoint transactions fail", "id", id, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  action + " fail",
+		}, nil
+	}
+	var blockTxList []*account.BlockInfoTransactionList
+	for i := range txs {
+		if txStatus(&txs[i]) != account.TxStatus_Success {
+			continue
+		}
+		for _, transfer := range parseTransfers(&txs[i]) {
+			blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+				From:         transfer.From,
+				To:           transfer.To,
+				TokenAddress: transfer.ContractAddress,
+				Hash:         txs[i].Digest,
+				Height:       height,
+				Amount:       transfer.Amount,
+			})
+		}
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          action + " success",
+		Height:       int64(height),
+		Hash:         checkpoint.Digest,
+		Transactions: blockTxList,
+	}, nil
+}
+
+// 通过余额变化解析转账：发送方以外的地址增加的余额视为发送方转出，SUI 的 contract_address 为空
+func parseTransfers(tx *TransactionBlock) []Transfer {
+	if tx.Transaction == nil {
+		return nil
+	}
+	sender, err := ParseAddress(tx.Transaction.Data.Sender)
+	if err != nil {
+		return nil
+	}
+	var transfers []Transfer
+	for _, change := range tx.BalanceChanges {
+		owner, ok := addressOwner(change.Owner)
+		if !ok || owner == sender {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(change.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			continue
+		}
+		transfer := Transfer{From: sender.String(), To: owner.String(), Amount: amount.String()}
+		if !isSuiCoinType(change.CoinType) {
+			transfer.ContractAddress = change.CoinType
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers
+}
+
+// 只处理地址持有的余额
+func addressOwner(owner json.RawMessage) (Address, bool) {
+	var value struct {
+		AddressOwner string `json:"AddressOwner"`
+	}
+	if json.Unmarshal(owner, &value) != nil || value.AddressOwner == "" {
+		return Address{}, false
+	}
+	address, err := ParseAddress(value.AddressOwner)
+	return address, err == nil
+}
+
+// coin 类型中的地址可能是短格式或长格式
+func isSuiCoinType(coinType string) bool {
+	address, name, ok := strings.Cut(coinType, "::")
+	if !ok || name != "sui::SUI" {
+		return false
+	}
+	parsed, err := ParseAddress(address)
+	return err == nil && parsed == Address{31: 2}
+}
+
+func toTxMessage(tx *TransactionBlock) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:   tx.Digest,
+		Status: txStatus(tx),
+		Height: tx.Checkpoint,
+	}
+	if timestamp, err := strconv.ParseUint(tx.TimestampMs, 10, 64); err == nil {
+		txMessage.Datetime = strconv.FormatUint(timestamp/1000, 10)
+	}
+	if tx.Effects != nil {
+		txMessage.Fee = gasFee(&tx.Effects.GasUsed).String()
+	}
+	if tx.Transaction != nil {
+		txMessage.From = tx.Transaction.Data.Sender
+	}
+	if transfers := parseTransfers(tx); len(transfers) > 0 {
+		transfer := transfers[0]
+		txMessage.From = transfer.From
+		txMessage.To = transfer.To
+		txMessage.Value = transfer.Amount
+		txMessage.ContractAddress = transfer.ContractAddress
+		if transfer.ContractAddress != "" {
+			txMessage.Type = 1
+		}
+		data, _ := json.Marshal(transfers)
+		txMessage.Data = string(data)
+	}
+	return txMessage
+}
+
+// 未出现在检查点中的交易为待确认，Move abort 视为合约执行失败
+func txStatus(tx *TransactionBlock) account.TxStatus {
+	switch {
+	case tx.Effects == nil || tx.Checkpoint == "":
+		return account.TxStatus_Pending
+	case tx.Effects.Status.Status == "success":
+		return account.TxStatus_Success
+	case strings.Contains(tx.Effects.Status.Error, "MoveAbort"):
+		return account.TxStatus_ContractExecuteFailed
+	default:
+		return account.TxStatus_Failed
+	}
+}
+
+// 实际手续费：计算费用 + 存储费用 - 存储返还
+func gasFee(gasUsed *GasCostSummary) *big.Int {
+	fee := new(big.Int)
+	for i, value := range []string{gasUsed.ComputationCost, gasUsed.StorageCost, gasUsed.StorageRebate} {
+		amount, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			continue
+		}
+		if i == 2 {
+			fee.Sub(fee, amount)
+		} else {
+			fee.Add(fee, amount)
+		}
+	}
+	return fee
+}
+
+func toBlockHeader(checkpoint *Checkpoint) *account.BlockHeader {
+	header := &account.BlockHeader{
+		Hash:       checkpoint.Digest,
+		ParentHash: checkpoint.PreviousDigest,
+		Number:     checkpoint.SequenceNumber,
+	}
+	if timestamp, err := strconv.ParseUint(checkpoint.TimestampMs, 10, 64); err == nil {
+		header.Time = timestamp / 1000
+	}
+	return header
+}
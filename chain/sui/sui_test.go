@@ -0,0 +1,284 @@
+package sui
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/blake2b"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+const (
+	testReceiver = "0x00000000000000000000000000000000000000000000000000000000000000bb"
+	testCoinType = "0xdba34672e30cb065b1f93e3ab55318768fd6fef66c15942c9f7cb846e2f900e7::usdc::USDC"
+)
+
+// 模拟 Sui 全节点，coins 按 coin 类型返回，记录提交的交易
+type fakeSuiNode struct {
+	ISui
+	coins    map[string][]Coin
+	executed *SignedTransaction
+}
+
+func (f *fakeSuiNode) GetReferenceGasPrice() (uint64, error) {
+	return 750, nil
+}
+
+func (f *fakeSuiNode) GetCoins(owner, coinType string, cursor *string) (*CoinPage, error) {
+	return &CoinPage{Data: f.coins[coinType]}, nil
+}
+
+func (f *fakeSuiNode) DryRunTransactionBlock(txBytes []byte) (*DryRunResult, error) {
+	if _, err := DeserializeTransactionData(txBytes); err != nil {
+		return nil, err
+	}
+	result := &DryRunResult{}
+	result.Effects.Status.Status = "success"
+	result.Effects.GasUsed = GasCostSummary{ComputationCost: "750000", StorageCost: "1976000", StorageRebate: "978120"}
+	return result, nil
+}
+
+func (f *fakeSuiNode) ExecuteTransactionBlock(txBytes []byte, signatures []string) (*TransactionBlock, error) {
+	f.executed = &SignedTransaction{TxBytes: base64.StdEncoding.EncodeToString(txBytes), Signatures: signatures}
+	return &TransactionBlock{Digest: TransactionDigest(txBytes)}, nil
+}
+
+func testCoin(id byte, balance string) Coin {
+	return Coin{
+		CoinObjectId: Address{31: id}.String(),
+		Version:      "12",
+		Digest:       base58.Encode(bytes.Repeat([]byte{id}, 32)),
+		Balance:      balance,
+	}
+}
+
+func buildUnsigned(t *testing.T, adaptor *ChainAdaptor, transferTx SuiTransferTx) (*TransactionData, *account.UnSignTransactionResponse) {
+	txJson, _ := json.Marshal(transferTx)
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	txBytes, _ := base64.StdEncoding.DecodeString(resp.UnSignTx)
+	tx, err := DeserializeTransactionData(txBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tx.Serialize(), txBytes) {
+		t.Fatal("expected transaction data to round trip")
+	}
+	return tx, resp
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	adaptor := &ChainAdaptor{}
+	pubKey := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	expected := blake2b.Sum256(append([]byte{flagEd25519}, pubKey...))
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: hex.EncodeToString(pubKey)})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Address != "0x"+hex.EncodeToString(expected[:]) {
+		t.Fatalf("unexpected ed25519 address %s", resp.Address)
+	}
+
+	key, _ := crypto.ToECDSA(bytes.Repeat([]byte{1}, 32))
+	compressed := crypto.CompressPubkey(&key.PublicKey)
+	expected = blake2b.Sum256(append([]byte{flagSecp256k1}, compressed...))
+	for _, publicKey := range [][]byte{compressed, crypto.FromECDSAPub(&key.PublicKey)} {
+		resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: hex.EncodeToString(publicKey), KeyType: chain.KeyTypeSecp256k1})
+		if resp.Code != global_const.ReturnCode_SUCCESS || resp.Address != "0x"+hex.EncodeToString(expected[:]) {
+			t.Fatalf("unexpected secp256k1 address %s", resp.Address)
+		}
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: hex.EncodeToString(compressed)})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected secp256k1 public key to be rejected as ed25519")
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: hex.EncodeToString(pubKey), KeyType: chain.KeyTypeSr25519})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected sr25519 key type to be rejected")
+	}
+
+	if !ValidateAddress(testReceiver) || ValidateAddress("0xbb") || ValidateAddress(testReceiver[2:]) {
+		t.Fatal("unexpected address validation")
+	}
+	if !isSuiCoinType("0x2::sui::SUI") || !isSuiCoinType("0x0000000000000000000000000000000000000000000000000000000000000002::sui::SUI") || isSuiCoinType(testCoinType) {
+		t.Fatal("unexpected sui coin type check")
+	}
+}
+
+func Test_BuildSuiTransfer(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	pubKey := hex.EncodeToString(key.Public().(ed25519.PublicKey))
+	sender, _ := ParsePublicKey(pubKey, "")
+	node := &fakeSuiNode{coins: map[string][]Coin{suiCoinType: {testCoin(1, "5000000"), testCoin(2, "100000000")}}}
+	adaptor := &ChainAdaptor{SuiClient: node}
+
+	tx, resp := buildUnsigned(t, adaptor, SuiTransferTx{
+		FromAddress: sender.Address().String(),
+		ToAddress:   testReceiver,
+		Amount:      "1000000",
+		PublicKey:   pubKey,
+	})
+	// 计算费用 750000 + 1000 * 750，加上净存储费用 1976000 - 978120
+	if tx.GasBudget != 2_497_880 || tx.GasPrice != 750 || len(tx.GasPayment) != 2 || tx.GasOwner != tx.Sender {
+		t.Fatalf("unexpected gas data %+v", tx)
+	}
+	if len(tx.Commands) != 2 || tx.Commands[0].Kind != commandSplitCoins || tx.Commands[0].Target != GasCoin() ||
+		tx.Commands[1].Kind != commandTransferObjects || tx.Commands[1].Arguments[0] != NestedResult(0, 0) {
+		t.Fatalf("unexpected commands %+v", tx.Commands)
+	}
+	if hex.EncodeToString(tx.Inputs[0].Pure) != "40420f0000000000" || "0x"+hex.EncodeToString(tx.Inputs[1].Pure) != testReceiver {
+		t.Fatalf("unexpected inputs %+v", tx.Inputs)
+	}
+	txBytes, _ := base64.StdEncoding.DecodeString(resp.UnSignTx)
+	digest := blake2b.Sum256(append([]byte{0, 0, 0}, txBytes...))
+	if resp.SignHashes[0] != hex.EncodeToString(digest[:]) {
+		t.Fatal("expected ed25519 sign hash to be the intent message digest")
+	}
+
+	signature := ed25519.Sign(key, digest[:])
+	signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  resp.UnSignTx,
+		Signature: hex.EncodeToString(signature),
+		PublicKey: pubKey,
+	})
+	if signed.Code != global_const.ReturnCode_SUCCESS || signed.Msg != TransactionDigest(txBytes) {
+		t.Fatalf("build signed transaction fail: %s", signed.Msg)
+	}
+	sendResp, _ := adaptor.SendTx(&account.SendTxRequest{RawTx: signed.SignedTx})
+	if sendResp.Code != global_const.ReturnCode_SUCCESS || sendResp.TxHash != signed.Msg || node.executed.TxBytes != resp.UnSignTx {
+		t.Fatalf("send tx fail: %s", sendResp.Msg)
+	}
+	serialized, _ := base64.StdEncoding.DecodeString(node.executed.Signatures[0])
+	if len(serialized) != 97 || serialized[0] != flagEd25519 || !bytes.Equal(serialized[1:65], signature) || hex.EncodeToString(serialized[65:]) != pubKey {
+		t.Fatalf("unexpected serialized signature %x", serialized)
+	}
+
+	signed, _ = adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  resp.UnSignTx,
+		Signature: hex.EncodeToString(ed25519.Sign(key, txBytes)),
+		PublicKey: pubKey,
+	})
+	if signed.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected signature over raw bytes to be rejected")
+	}
+	fee, _ := adaptor.GetFee(&account.FeeRequest{RawTx: resp.UnSignTx})
+	if fee.NormalFee != "2497880" {
+		t.Fatalf("unexpected fee %s", fee.NormalFee)
+	}
+
+	txJson, _ := json.Marshal(SuiTransferTx{FromAddress: sender.Address().String(), ToAddress: testReceiver, Amount: "200000000", PublicKey: pubKey})
+	failed, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if failed.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected insufficient balance to be rejected")
+	}
+}
+
+func Test_BuildTokenTransfer(t *testing.T) {
+	key, _ := crypto.ToECDSA(bytes.Repeat([]byte{3}, 32))
+	pubKey := hex.EncodeToString(crypto.CompressPubkey(&key.PublicKey))
+	sender, _ := ParsePublicKey(pubKey, "")
+	node := &fakeSuiNode{coins: map[string][]Coin{
+		suiCoinType:  {testCoin(1, "50000000")},
+		testCoinType: {testCoin(3, "400"), testCoin(4, "700"), testCoin(5, "900")},
+	}}
+	adaptor := &ChainAdaptor{SuiClient: node}
+
+	tx, resp := buildUnsigned(t, adaptor, SuiTransferTx{
+		FromAddress:     sender.Address().String(),
+		ToAddress:       testReceiver,
+		Amount:          "1000",
+		ContractAddress: testCoinType,
+		PublicKey:       pubKey,
+		GasBudget:       3_000_000,
+	})
+	// 选择前两个 coin 合并后拆分金额
+	if len(tx.Inputs) != 4 || tx.Inputs[0].Object == nil || tx.Inputs[1].Object == nil || tx.GasBudget != 3_000_000 {
+		t.Fatalf("unexpected inputs %+v", tx.Inputs)
+	}
+	expected := []Command{
+		{Kind: commandMergeCoins, Target: Input(0), Arguments: []Argument{Input(1)}},
+		{Kind: commandSplitCoins, Target: Input(0), Arguments: []Argument{Input(2)}},
+		{Kind: commandTransferObjects, Target: Input(3), Arguments: []Argument{NestedResult(1, 0)}},
+	}
+	if !reflect.DeepEqual(tx.Commands, expected) {
+		t.Fatalf("unexpected commands %+v", tx.Commands)
+	}
+
+	txBytes, _ := base64.StdEncoding.DecodeString(resp.UnSignTx)
+	hash, _ := hex.DecodeString(resp.SignHashes[0])
+	if !bytes.Equal(hash, SignHash(flagSecp256k1, txBytes)) {
+		t.Fatal("expected secp256k1 sign hash to be sha256 of the digest")
+	}
+	signature, _ := crypto.Sign(hash, key)
+	signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  resp.UnSignTx,
+		Signature: hex.EncodeToString(signature),
+		PublicKey: pubKey,
+	})
+	if signed.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", signed.Msg)
+	}
+	signedJson, _ := base64.StdEncoding.DecodeString(signed.SignedTx)
+	var signedTx SignedTransaction
+	_ = json.Unmarshal(signedJson, &signedTx)
+	serialized, _ := base64.StdEncoding.DecodeString(signedTx.Signatures[0])
+	if len(serialized) != 98 || serialized[0] != flagSecp256k1 || !bytes.Equal(serialized[1:65], signature[:64]) {
+		t.Fatalf("unexpected serialized signature %x", serialized)
+	}
+
+	other, _ := crypto.ToECDSA(bytes.Repeat([]byte{4}, 32))
+	signed, _ = adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  resp.UnSignTx,
+		Signature: hex.EncodeToString(signature),
+		PublicKey: hex.EncodeToString(crypto.CompressPubkey(&other.PublicKey)),
+	})
+	if signed.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected public key of another account to be rejected")
+	}
+}
+
+func Test_ParseTransfers(t *testing.T) {
+	sender := "0x00000000000000000000000000000000000000000000000000000000000000aa"
+	var tx TransactionBlock
+	err := json.Unmarshal([]byte(`{
+		"digest": "9vX1",
+		"transaction": {"data": {"sender": "`+sender+`"}},
+		"effects": {"status": {"status": "success"}, "gasUsed": {"computationCost": "750000", "storageCost": "2964000", "storageRebate": "978120"}},
+		"balanceChanges": [
+			{"owner": {"AddressOwner": "`+sender+`"}, "coinType": "0x2::sui::SUI", "amount": "-3735880"},
+			{"owner": {"AddressOwner": "`+testReceiver+`"}, "coinType": "0x2::sui::SUI", "amount": "1000"},
+			{"owner": {"AddressOwner": "`+testReceiver+`"}, "coinType": "`+testCoinType+`", "amount": "25"},
+			{"owner": {"ObjectOwner": "0x01"}, "coinType": "0x2::sui::SUI", "amount": "7"}
+		],
+		"timestampMs": "1700000000123",
+		"checkpoint": "1024"
+	}`), &tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfers := parseTransfers(&tx)
+	expected := []Transfer{
+		{From: sender, To: testReceiver, Amount: "1000"},
+		{ContractAddress: testCoinType, From: sender, To: testReceiver, Amount: "25"},
+	}
+	if !reflect.DeepEqual(transfers, expected) {
+		t.Fatalf("unexpected transfers %+v", transfers)
+	}
+	message := toTxMessage(&tx)
+	if message.Fee != "2735880" || message.Status != account.TxStatus_Success || message.Height != "1024" || message.Datetime != "1700000000" || message.Value != "1000" {
+		t.Fatalf("unexpected tx message %+v", message)
+	}
+	tx.Effects.Status.Status, tx.Effects.Status.Error = "failure", "MoveAbort(...) in command 0"
+	if txStatus(&tx) != account.TxStatus_ContractExecuteFailed {
+		t.Fatal("expected move abort to be contract execute failed")
+	}
+}
@@ -0,0 +1,322 @@
+package sui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// sui_multiGetTransactionBlocks 单次最多查询的交易数
+const multiGetLimit = 50
+
+// Sui JSON-RPC 错误
+type RpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// 交易或检查点不存在
+func IsNotFound(err error) bool {
+	var rpcErr *RpcError
+	return errors.As(err, &rpcErr) && (strings.Contains(rpcErr.Message, "Could not find") || strings.Contains(rpcErr.Message, "not found"))
+}
+
+type Coin struct {
+	CoinType     string `json:"coinType"`
+	CoinObjectId string `json:"coinObjectId"`
+	Version      string `json:"version"`
+	Digest       string `json:"digest"`
+	Balance      string `json:"balance"`
+}
+
+type CoinPage struct {
+	Data        []Coin  `json:"data"`
+	NextCursor  *string `json:"nextCursor"`
+	HasNextPage bool    `json:"hasNextPage"`
+}
+
+type Balance struct {
+	CoinType     string `json:"coinType"`
+	TotalBalance string `json:"totalBalance"`
+}
+
+// gas 费用明细，单位 MIST
+type GasCostSummary struct {
+	ComputationCost         string `json:"computationCost"`
+	StorageCost             string `json:"storageCost"`
+	StorageRebate           string `json:"storageRebate"`
+	NonRefundableStorageFee string `json:"nonRefundableStorageFee"`
+}
+
+type TransactionEffects struct {
+	Status struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"status"`
+	GasUsed GasCostSummary `json:"gasUsed"`
+}
+
+// owner 为 {"AddressOwner": 地址}、{"ObjectOwner": 地址}、{"Shared": {...}} 或 "Immutable"
+type BalanceChange struct {
+	Owner    json.RawMessage `json:"owner"`
+	CoinType string          `json:"coinType"`
+	Amount   string          `json:"amount"`
+}
+
+type TransactionBlock struct {
+	Digest      string `json:"digest"`
+	Transaction *struct {
+		Data struct {
+			Sender  string `json:"sender"`
+			GasData struct {
+				Owner  string `json:"owner"`
+				Price  string `json:"price"`
+				Budget string `json:"budget"`
+			} `json:"gasData"`
+		} `json:"data"`
+	} `json:"transaction"`
+	Effects        *TransactionEffects `json:"effects"`
+	BalanceChanges []BalanceChange     `json:"balanceChanges"`
+	TimestampMs    string              `json:"timestampMs"`
+	Checkpoint     string              `json:"checkpoint"`
+}
+
+type TransactionBlockPage struct {
+	Data        []TransactionBlock `json:"data"`
+	NextCursor  *string            `json:"nextCursor"`
+	HasNextPage bool               `json:"hasNextPage"`
+}
+
+type DryRunResult struct {
+	Effects        TransactionEffects `json:"effects"`
+	BalanceChanges []BalanceChange    `json:"balanceChanges"`
+}
+
+type Checkpoint struct {
+	Epoch          string   `json:"epoch"`
+	SequenceNumber string   `json:"sequenceNumber"`
+	Digest         string   `json:"digest"`
+	PreviousDigest string   `json:"previousDigest"`
+	TimestampMs    string   `json:"timestampMs"`
+	Transactions   []string `json:"transactions"`
+}
+
+type rpcRequest struct {
+	JsonRpc string `json:"jsonrpc"`
+	Id      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RpcError       `json:"error"`
+	Id     uint64          `json:"id"`
+}
+
+// 定义 Sui 节点接口
+type ISui interface {
+	// 检查点
+	GetLatestCheckpointSequenceNumber() (uint64, error)
+	GetCheckpoint(id string) (*Checkpoint, error)
+	// 账户
+	GetCoins(owner, coinType string, cursor *string) (*CoinPage, error)
+	GetBalance(owner, coinType string) (*Balance, error)
+	// 交易
+	GetReferenceGasPrice() (uint64, error)
+	DryRunTransactionBlock(txBytes []byte) (*DryRunResult, error)
+	ExecuteTransactionBlock(txBytes []byte, signatures []string) (*TransactionBlock, error)
+	GetTransactionBlock(digest string) (*TransactionBlock, error)
+	MultiGetTransactionBlocks(digests []string) ([]TransactionBlock, error)
+	QueryTransactionBlocks(filter map[string]any, cursor *string, limit uint32) (*TransactionBlockPage, error)
+}
+
+// 定义 Sui JSON-RPC 客户端
+type SuiClient struct {
+	url    string
+	client *http.Client
+	nextId atomic.Uint64
+}
+
+func NewSuiClient(rpcUrl string, timeout time.Duration) (ISui, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &SuiClient{
+		url:    rpcUrl,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// 查询交易时返回的内容，转账通过余额变化解析
+var transactionBlockOptions = map[string]bool{
+	"showInput":          true,
+	"showEffects":        true,
+	"showBalanceChanges": true,
+}
+
+// 调用 JSON-RPC 方法并解析结果
+func (s *SuiClient) call(result any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+	body, err := json.Marshal(&rpcRequest{
+		JsonRpc: "2.0",
+		Id:      s.nextId.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("call %s fail, status %d: %s", method, resp.StatusCode, string(respBody))
+	}
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return err
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// 节点以字符串返回 u64
+func (s *SuiClient) callUint(method string, params ...any) (uint64, error) {
+	var result json.RawMessage
+	if err := s.call(&result, method, params...); err != nil {
+		return 0, err
+	}
+	var value string
+	if err := json.Unmarshal(result, &value); err != nil {
+		var number uint64
+		if err := json.Unmarshal(result, &number); err != nil {
+			return 0, err
+		}
+		return number, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// 获取最新检查点序号
+func (s *SuiClient) GetLatestCheckpointSequenceNumber() (uint64, error) {
+	return s.callUint("sui_getLatestCheckpointSequenceNumber")
+}
+
+// 通过序号或摘要获取检查点
+func (s *SuiClient) GetCheckpoint(id string) (*Checkpoint, error) {
+	checkpoint := new(Checkpoint)
+	if err := s.call(checkpoint, "sui_getCheckpoint", id); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// 分页获取地址持有的指定类型 coin 对象
+func (s *SuiClient) GetCoins(owner, coinType string, cursor *string) (*CoinPage, error) {
+	page := new(CoinPage)
+	if err := s.call(page, "suix_getCoins", owner, coinType, cursor, multiGetLimit); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// 获取地址指定类型 coin 的总余额
+func (s *SuiClient) GetBalance(owner, coinType string) (*Balance, error) {
+	balance := new(Balance)
+	if err := s.call(balance, "suix_getBalance", owner, coinType); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// 获取当前 epoch 的参考 gas 单价，单位 MIST
+func (s *SuiClient) GetReferenceGasPrice() (uint64, error) {
+	return s.callUint("suix_getReferenceGasPrice")
+}
+
+// 模拟执行交易，不需要签名
+func (s *SuiClient) DryRunTransactionBlock(txBytes []byte) (*DryRunResult, error) {
+	result := new(DryRunResult)
+	if err := s.call(result, "sui_dryRunTransactionBlock", base64.StdEncoding.EncodeToString(txBytes)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 提交签名交易，signatures 为 base64 编码的序列化签名
+func (s *SuiClient) ExecuteTransactionBlock(txBytes []byte, signatures []string) (*TransactionBlock, error) {
+	result := new(TransactionBlock)
+	options := map[string]bool{"showEffects": true}
+	if err := s.call(result, "sui_executeTransactionBlock", base64.StdEncoding.EncodeToString(txBytes), signatures, options); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *SuiClient) GetTransactionBlock(digest string) (*TransactionBlock, error) {
+	tx := new(TransactionBlock)
+	if err := s.call(tx, "sui_getTransactionBlock", digest, transactionBlockOptions); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// 批量获取交易，按 multiGetLimit 分批请求
+func (s *SuiClient) MultiGetTransactionBlocks(digests []string) ([]TransactionBlock, error) {
+	var txs []TransactionBlock
+	for start := 0; start < len(digests); start += multiGetLimit {
+		end := min(start+multiGetLimit, len(digests))
+		var batch []TransactionBlock
+		if err := s.call(&batch, "sui_multiGetTransactionBlocks", digests[start:end], transactionBlockOptions); err != nil {
+			return nil, err
+		}
+		txs = append(txs, batch...)
+	}
+	return txs, nil
+}
+
+// 按过滤条件倒序分页查询交易
+func (s *SuiClient) QueryTransactionBlocks(filter map[string]any, cursor *string, limit uint32) (*TransactionBlockPage, error) {
+	page := new(TransactionBlockPage)
+	query := map[string]any{"filter": filter, "options": transactionBlockOptions}
+	if err := s.call(page, "suix_queryTransactionBlocks", query, cursor, limit, true); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
@@ -0,0 +1,334 @@
+package sui
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/bcs"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 模拟执行和选择 gas coin 时使用的 gas 预算（0.01 SUI），实际预算按模拟结果计算
+const defaultGasBudget = 10_000_000
+
+// 模拟执行结果的计算费用上额外预留的计算单位，与 Sui SDK 一致
+const gasSafeOverhead = 1000
+
+// 单笔交易最多使用的 coin 对象数
+const maxCoinObjects = 256
+
+// 构建未签名交易：un_sign_tx 为 base64 编码的 BCS 交易数据，sign_hashes 为待签名哈希，
+// ed25519 为 blake2b-256(intent || 交易数据)，secp256k1 为其 sha256
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx SuiTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	pubKey, tx, err := c.buildTransfer(&transferTx)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	txBytes := tx.Serialize()
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   base64.StdEncoding.EncodeToString(txBytes),
+		SignHashes: []string{hex.EncodeToString(SignHash(pubKey.Flag, txBytes))},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 un_sign_tx，public_key 为发送方公钥，
+// signature 为 64 字节签名（secp256k1 为 r || s，可附带 v）。
+// 返回的 signed_tx 为 base64 编码的 SignedTransaction，msg 为交易摘要
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	txBytes, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	tx, err := DeserializeTransactionData(txBytes)
+	if err != nil {
+		log.Error("decode transaction data fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	pubKey, err := ParsePublicKey(req.PublicKey, "")
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid public key",
+		}, nil
+	}
+	if pubKey.Address() != tx.Sender {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "public key does not match sender",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || (len(signature) != 64 && !(pubKey.Flag == flagSecp256k1 && len(signature) == crypto.SignatureLength)) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	signature = signature[:64]
+	hash := SignHash(pubKey.Flag, txBytes)
+	if pubKey.Flag == flagSecp256k1 {
+		signature = normalizeSignature(signature)
+	}
+	if !verifySignature(pubKey, hash, signature) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "signature verification failed",
+		}, nil
+	}
+	signedJson, _ := json.Marshal(&SignedTransaction{
+		TxBytes:    req.Base64Tx,
+		Signatures: []string{base64.StdEncoding.EncodeToString(serializeSignature(pubKey, signature))},
+	})
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      TransactionDigest(txBytes),
+		SignedTx: base64.StdEncoding.EncodeToString(signedJson),
+	}, nil
+}
+
+// 校验发送方地址，选择 coin 对象构建转账，未指定 gas 预算时通过模拟执行估算
+func (c *ChainAdaptor) buildTransfer(transferTx *SuiTransferTx) (*PublicKey, *TransactionData, error) {
+	pubKey, err := ParsePublicKey(transferTx.PublicKey, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	from, err := ParseAddress(transferTx.FromAddress)
+	if err != nil {
+		return nil, nil, errors.New("invalid from address")
+	}
+	if from != pubKey.Address() {
+		return nil, nil, errors.New("from address does not match public key")
+	}
+	if !ValidateAddress(transferTx.ToAddress) {
+		return nil, nil, errors.New("invalid to address")
+	}
+	to, _ := ParseAddress(transferTx.ToAddress)
+	amount, err := strconv.ParseUint(transferTx.Amount, 10, 64)
+	if err != nil || amount == 0 {
+		return nil, nil, errors.New("invalid amount")
+	}
+	native := transferTx.ContractAddress == "" || isSuiCoinType(transferTx.ContractAddress)
+
+	gasPrice := transferTx.GasPrice
+	if gasPrice == 0 {
+		if gasPrice, err = c.SuiClient.GetReferenceGasPrice(); err != nil {
+			return nil, nil, fmt.Errorf("get reference gas price fail: %w", err)
+		}
+	}
+	gasBudget := transferTx.GasBudget
+	if gasBudget == 0 {
+		gasBudget = defaultGasBudget
+	}
+	// SUI 转账从 gas coin 中拆分金额，gas coin 需要覆盖金额和 gas 预算
+	gasTarget := new(big.Int).SetUint64(gasBudget)
+	if native {
+		gasTarget.Add(gasTarget, new(big.Int).SetUint64(amount))
+	}
+	gasCoins, err := c.selectCoins(from, suiCoinType, gasTarget)
+	if err != nil {
+		return nil, nil, err
+	}
+	tx := &TransactionData{
+		Sender:   from,
+		GasOwner: from,
+		GasPrice: gasPrice,
+	}
+	for _, coin := range gasCoins {
+		tx.GasPayment = append(tx.GasPayment, coin.ref)
+	}
+	amountArg := bcs.AppendU64(nil, amount)
+	if native {
+		tx.Inputs = []CallArg{{Pure: amountArg}, {Pure: to[:]}}
+		tx.Commands = []Command{
+			{Kind: commandSplitCoins, Target: GasCoin(), Arguments: []Argument{Input(0)}},
+			{Kind: commandTransferObjects, Target: Input(1), Arguments: []Argument{NestedResult(0, 0)}},
+		}
+	} else {
+		coins, err := c.selectCoins(from, transferTx.ContractAddress, new(big.Int).SetUint64(amount))
+		if err != nil {
+			return nil, nil, err
+		}
+		var sources []Argument
+		for i := range coins {
+			tx.Inputs = append(tx.Inputs, CallArg{Object: &coins[i].ref})
+			if i > 0 {
+				sources = append(sources, Input(uint16(i)))
+			}
+		}
+		amountIndex := uint16(len(tx.Inputs))
+		tx.Inputs = append(tx.Inputs, CallArg{Pure: amountArg}, CallArg{Pure: to[:]})
+		if len(sources) > 0 {
+			tx.Commands = append(tx.Commands, Command{Kind: commandMergeCoins, Target: Input(0), Arguments: sources})
+		}
+		split := uint16(len(tx.Commands))
+		tx.Commands = append(tx.Commands,
+			Command{Kind: commandSplitCoins, Target: Input(0), Arguments: []Argument{Input(amountIndex)}},
+			Command{Kind: commandTransferObjects, Target: Input(amountIndex + 1), Arguments: []Argument{NestedResult(split, 0)}},
+		)
+	}
+
+	tx.GasBudget = gasBudget
+	if transferTx.GasBudget == 0 {
+		if tx.GasBudget, err = c.estimateGasBudget(tx); err != nil {
+			return nil, nil, err
+		}
+		required := new(big.Int).SetUint64(tx.GasBudget)
+		if native {
+			required.Add(required, new(big.Int).SetUint64(amount))
+		}
+		if required.Cmp(coinsTotal(gasCoins)) > 0 {
+			return nil, nil, errors.New("insufficient gas balance")
+		}
+	}
+	return pubKey, tx, nil
+}
+
+// 与 Sui SDK 一致：计算费用加 gasSafeOverhead 个计算单位，再加上净存储费用（不低于计算部分）
+func (c *ChainAdaptor) estimateGasBudget(tx *TransactionData) (uint64, error) {
+	result, err := c.SuiClient.DryRunTransactionBlock(tx.Serialize())
+	if err != nil {
+		return 0, fmt.Errorf("dry run transaction fail: %w", err)
+	}
+	if result.Effects.Status.Status != "success" {
+		return 0, fmt.Errorf("dry run transaction fail: %s", result.Effects.Status.Error)
+	}
+	computation, ok := new(big.Int).SetString(result.Effects.GasUsed.ComputationCost, 10)
+	if !ok {
+		return 0, errors.New("invalid computation cost")
+	}
+	computation.Add(computation, new(big.Int).SetUint64(gasSafeOverhead*tx.GasPrice))
+	budget := new(big.Int).Add(computation, gasFee(&GasCostSummary{StorageCost: result.Effects.GasUsed.StorageCost, StorageRebate: result.Effects.GasUsed.StorageRebate}))
+	if budget.Cmp(computation) < 0 {
+		budget = computation
+	}
+	if !budget.IsUint64() {
+		return 0, errors.New("invalid gas budget")
+	}
+	return budget.Uint64(), nil
+}
+
+type selectedCoin struct {
+	ref     ObjectRef
+	balance *big.Int
+}
+
+// 按节点返回的顺序选择 coin 对象，直到余额之和不小于 target
+func (c *ChainAdaptor) selectCoins(owner Address, coinType string, target *big.Int) ([]selectedCoin, error) {
+	var coins []selectedCoin
+	total := new(big.Int)
+	var cursor *string
+	for len(coins) < maxCoinObjects {
+		page, err := c.SuiClient.GetCoins(owner.String(), coinType, cursor)
+		if err != nil {
+			return nil, fmt.Errorf("get coins fail: %w", err)
+		}
+		for _, coin := range page.Data {
+			selected, err := toSelectedCoin(&coin)
+			if err != nil {
+				return nil, err
+			}
+			coins = append(coins, *selected)
+			if total.Add(total, selected.balance).Cmp(target) >= 0 {
+				return coins, nil
+			}
+			if len(coins) == maxCoinObjects {
+				break
+			}
+		}
+		if !page.HasNextPage {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	return nil, fmt.Errorf("insufficient %s balance", coinType)
+}
+
+func toSelectedCoin(coin *Coin) (*selectedCoin, error) {
+	objectId, err := ParseAddress(coin.CoinObjectId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coin object id %s", coin.CoinObjectId)
+	}
+	version, err := strconv.ParseUint(coin.Version, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coin version %s", coin.Version)
+	}
+	digest := base58.Decode(coin.Digest)
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("invalid coin digest %s", coin.Digest)
+	}
+	balance, ok := new(big.Int).SetString(coin.Balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid coin balance %s", coin.Balance)
+	}
+	return &selectedCoin{ref: ObjectRef{ObjectId: objectId, Version: version, Digest: digest}, balance: balance}, nil
+}
+
+func coinsTotal(coins []selectedCoin) *big.Int {
+	total := new(big.Int)
+	for _, coin := range coins {
+		total.Add(total, coin.balance)
+	}
+	return total
+}
+
+func verifySignature(pubKey *PublicKey, hash, signature []byte) bool {
+	if pubKey.Flag == flagEd25519 {
+		return ed25519.Verify(pubKey.Key, hash, signature)
+	}
+	return crypto.VerifySignature(pubKey.Key, hash, signature)
+}
+
+// 链上只接受 low-S 签名，高位 S 转换为 n - S
+func normalizeSignature(signature []byte) []byte {
+	var s btcec.ModNScalar
+	s.SetByteSlice(signature[32:64])
+	if !s.IsOverHalfOrder() {
+		return signature
+	}
+	s.Negate()
+	normalized := append([]byte{}, signature...)
+	sBytes := s.Bytes()
+	copy(normalized[32:], sBytes[:])
+	return normalized
+}
@@ -0,0 +1,273 @@
+package sui
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"golang.org/x/crypto/blake2b"
+
+	"chain-account/common/bcs"
+)
+
+// TransactionData::V1、TransactionKind::ProgrammableTransaction、TransactionExpiration::None 的枚举序号
+const (
+	transactionDataV1       = 0
+	programmableTransaction = 0
+	expirationNone          = 0
+)
+
+// CallArg 和 ObjectArg 的枚举序号，只使用纯值参数和自有对象
+const (
+	callArgPure         = 0
+	callArgObject       = 1
+	objectArgImmOrOwned = 0
+)
+
+// Command 的枚举序号，转账只用到 TransferObjects、SplitCoins、MergeCoins
+const (
+	commandTransferObjects = 1
+	commandSplitCoins      = 2
+	commandMergeCoins      = 3
+)
+
+// Argument 的枚举序号
+const (
+	argumentGasCoin      = 0
+	argumentInput        = 1
+	argumentResult       = 2
+	argumentNestedResult = 3
+)
+
+// 签名意图：TransactionData、V0、Sui
+var transactionIntent = []byte{0, 0, 0}
+
+// 交易摘要的域分隔前缀
+const transactionDigestSalt = "TransactionData::"
+
+// 对象引用，digest 为 32 字节
+type ObjectRef struct {
+	ObjectId Address
+	Version  uint64
+	Digest   []byte
+}
+
+// 交易输入，Object 为空时为纯值参数
+type CallArg struct {
+	Pure   []byte
+	Object *ObjectRef
+}
+
+type Argument struct {
+	Kind        uint8
+	Index       uint16
+	ResultIndex uint16
+}
+
+func GasCoin() Argument {
+	return Argument{Kind: argumentGasCoin}
+}
+
+func Input(index uint16) Argument {
+	return Argument{Kind: argumentInput, Index: index}
+}
+
+func NestedResult(command, index uint16) Argument {
+	return Argument{Kind: argumentNestedResult, Index: command, ResultIndex: index}
+}
+
+// TransferObjects(Arguments, Target)、SplitCoins(Target, Arguments)、MergeCoins(Target, Arguments)
+type Command struct {
+	Kind      uint8
+	Target    Argument
+	Arguments []Argument
+}
+
+// 可编程交易，只支持 GasData 中的单一 owner 和无过期时间
+type TransactionData struct {
+	Inputs     []CallArg
+	Commands   []Command
+	Sender     Address
+	GasPayment []ObjectRef
+	GasOwner   Address
+	GasPrice   uint64
+	GasBudget  uint64
+}
+
+func appendObjectRef(buf []byte, ref *ObjectRef) []byte {
+	buf = append(buf, ref.ObjectId[:]...)
+	buf = bcs.AppendU64(buf, ref.Version)
+	return bcs.AppendBytes(buf, ref.Digest)
+}
+
+func appendArgument(buf []byte, arg Argument) []byte {
+	buf = bcs.AppendUleb128(buf, uint64(arg.Kind))
+	switch arg.Kind {
+	case argumentInput, argumentResult:
+		buf = bcs.AppendU16(buf, arg.Index)
+	case argumentNestedResult:
+		buf = bcs.AppendU16(buf, arg.Index)
+		buf = bcs.AppendU16(buf, arg.ResultIndex)
+	}
+	return buf
+}
+
+func appendArguments(buf []byte, args []Argument) []byte {
+	buf = bcs.AppendUleb128(buf, uint64(len(args)))
+	for _, arg := range args {
+		buf = appendArgument(buf, arg)
+	}
+	return buf
+}
+
+func (tx *TransactionData) Serialize() []byte {
+	buf := bcs.AppendUleb128(nil, transactionDataV1)
+	buf = bcs.AppendUleb128(buf, programmableTransaction)
+	buf = bcs.AppendUleb128(buf, uint64(len(tx.Inputs)))
+	for _, input := range tx.Inputs {
+		if input.Object != nil {
+			buf = bcs.AppendUleb128(buf, callArgObject)
+			buf = bcs.AppendUleb128(buf, objectArgImmOrOwned)
+			buf = appendObjectRef(buf, input.Object)
+		} else {
+			buf = bcs.AppendUleb128(buf, callArgPure)
+			buf = bcs.AppendBytes(buf, input.Pure)
+		}
+	}
+	buf = bcs.AppendUleb128(buf, uint64(len(tx.Commands)))
+	for _, command := range tx.Commands {
+		buf = bcs.AppendUleb128(buf, uint64(command.Kind))
+		if command.Kind == commandTransferObjects {
+			buf = appendArguments(buf, command.Arguments)
+			buf = appendArgument(buf, command.Target)
+		} else {
+			buf = appendArgument(buf, command.Target)
+			buf = appendArguments(buf, command.Arguments)
+		}
+	}
+	buf = append(buf, tx.Sender[:]...)
+	buf = bcs.AppendUleb128(buf, uint64(len(tx.GasPayment)))
+	for i := range tx.GasPayment {
+		buf = appendObjectRef(buf, &tx.GasPayment[i])
+	}
+	buf = append(buf, tx.GasOwner[:]...)
+	buf = bcs.AppendU64(buf, tx.GasPrice)
+	buf = bcs.AppendU64(buf, tx.GasBudget)
+	return bcs.AppendUleb128(buf, expirationNone)
+}
+
+func decodeAddress(d *bcs.Decoder) Address {
+	var a Address
+	copy(a[:], d.FixedBytes(32))
+	return a
+}
+
+func decodeObjectRef(d *bcs.Decoder) ObjectRef {
+	return ObjectRef{ObjectId: decodeAddress(d), Version: d.U64(), Digest: d.Bytes()}
+}
+
+func decodeArgument(d *bcs.Decoder) Argument {
+	arg := Argument{Kind: uint8(d.Uleb128())}
+	switch arg.Kind {
+	case argumentGasCoin:
+	case argumentInput, argumentResult:
+		arg.Index = d.U16()
+	case argumentNestedResult:
+		arg.Index = d.U16()
+		arg.ResultIndex = d.U16()
+	default:
+		d.SetErr(fmt.Errorf("unsupported argument %d", arg.Kind))
+	}
+	return arg
+}
+
+func decodeArguments(d *bcs.Decoder) []Argument {
+	var args []Argument
+	for n := d.SeqLen(); n > 0 && d.Err() == nil; n-- {
+		args = append(args, decodeArgument(d))
+	}
+	return args
+}
+
+// 解码本服务构建的转账交易，不支持的输入和命令类型返回错误
+func DeserializeTransactionData(data []byte) (*TransactionData, error) {
+	d := bcs.NewDecoder(data)
+	if version, kind := d.Uleb128(), d.Uleb128(); d.Err() == nil && (version != transactionDataV1 || kind != programmableTransaction) {
+		return nil, fmt.Errorf("unsupported transaction data %d kind %d", version, kind)
+	}
+	tx := &TransactionData{}
+	for n := d.SeqLen(); n > 0 && d.Err() == nil; n-- {
+		switch variant := d.Uleb128(); variant {
+		case callArgPure:
+			tx.Inputs = append(tx.Inputs, CallArg{Pure: d.Bytes()})
+		case callArgObject:
+			if objectArg := d.Uleb128(); objectArg != objectArgImmOrOwned {
+				return nil, fmt.Errorf("unsupported object argument %d", objectArg)
+			}
+			ref := decodeObjectRef(d)
+			tx.Inputs = append(tx.Inputs, CallArg{Object: &ref})
+		default:
+			return nil, fmt.Errorf("unsupported call argument %d", variant)
+		}
+	}
+	for n := d.SeqLen(); n > 0 && d.Err() == nil; n-- {
+		command := Command{Kind: uint8(d.Uleb128())}
+		switch command.Kind {
+		case commandTransferObjects:
+			command.Arguments = decodeArguments(d)
+			command.Target = decodeArgument(d)
+		case commandSplitCoins, commandMergeCoins:
+			command.Target = decodeArgument(d)
+			command.Arguments = decodeArguments(d)
+		default:
+			return nil, fmt.Errorf("unsupported command %d", command.Kind)
+		}
+		tx.Commands = append(tx.Commands, command)
+	}
+	tx.Sender = decodeAddress(d)
+	for n := d.SeqLen(); n > 0 && d.Err() == nil; n-- {
+		tx.GasPayment = append(tx.GasPayment, decodeObjectRef(d))
+	}
+	tx.GasOwner = decodeAddress(d)
+	tx.GasPrice = d.U64()
+	tx.GasBudget = d.U64()
+	if expiration := d.Uleb128(); d.Err() == nil && expiration != expirationNone {
+		return nil, fmt.Errorf("unsupported expiration %d", expiration)
+	}
+	if d.Err() != nil {
+		return nil, d.Err()
+	}
+	if d.Len() != 0 {
+		return nil, errors.New("unexpected trailing bytes")
+	}
+	return tx, nil
+}
+
+// 签名摘要：blake2b-256(intent || BCS(TransactionData))
+func SigningDigest(txBytes []byte) []byte {
+	digest := blake2b.Sum256(append(append([]byte{}, transactionIntent...), txBytes...))
+	return digest[:]
+}
+
+// 待签名哈希：ed25519 直接对签名摘要签名，secp256k1 使用 sha256 作为消息哈希
+func SignHash(flag byte, txBytes []byte) []byte {
+	digest := SigningDigest(txBytes)
+	if flag == flagSecp256k1 {
+		hash := sha256.Sum256(digest)
+		return hash[:]
+	}
+	return digest
+}
+
+// 交易摘要：base58(blake2b-256("TransactionData::" || BCS(TransactionData)))
+func TransactionDigest(txBytes []byte) string {
+	digest := blake2b.Sum256(append([]byte(transactionDigestSalt), txBytes...))
+	return base58.Encode(digest[:])
+}
+
+// 序列化签名：base64 前的 标志位 || 签名 || 公钥
+func serializeSignature(pubKey *PublicKey, signature []byte) []byte {
+	buf := append([]byte{pubKey.Flag}, signature...)
+	return append(buf, pubKey.Key...)
+}
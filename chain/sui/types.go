@@ -0,0 +1,34 @@
+package sui
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type SuiTransferTx struct {
+	// 发送方地址，需与 public_key 推导出的地址一致
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	// 单位为币种的最小单位，SUI 为 MIST
+	Amount string `json:"amount"`
+	// coin 类型，如 0x2::sui::SUI，为空时转账 SUI
+	ContractAddress string `json:"contract_address"`
+	// 十六进制公钥，32 字节为 ed25519，33 字节（压缩）或 65 字节为 secp256k1
+	PublicKey string `json:"public_key"`
+	// 为 0 时通过模拟执行估算
+	GasBudget uint64 `json:"gas_budget"`
+	// 为 0 时使用参考 gas 单价
+	GasPrice uint64 `json:"gas_price"`
+}
+
+// BuildSignedTransaction 返回的 signed_tx（JSON 后 base64 编码），SendTx 据此提交交易
+type SignedTransaction struct {
+	// base64 编码的 BCS 交易数据
+	TxBytes string `json:"tx_bytes"`
+	// base64 编码的序列化签名
+	Signatures []string `json:"signatures"`
+}
+
+// 交易中解析出的 SUI 或代币转账
+type Transfer struct {
+	ContractAddress string `json:"contract_address"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Amount          string `json:"amount"`
+}
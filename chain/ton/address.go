@@ -0,0 +1,120 @@
+package ton
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// user-friendly 地址的标志字节
+const (
+	addressTagBounceable    = 0x11
+	addressTagNonBounceable = 0x51
+	addressTagTestnet       = 0x80
+)
+
+// 标准地址（addr_std）：工作链 + 账户哈希
+type Address struct {
+	Workchain int8
+	Hash      [32]byte
+}
+
+// raw 格式：workchain:hex
+func (a *Address) Raw() string {
+	return fmt.Sprintf("%d:%s", a.Workchain, hex.EncodeToString(a.Hash[:]))
+}
+
+// user-friendly 格式：base64url(标志 || 工作链 || 哈希 || crc16)
+func (a *Address) String(bounceable, testnet bool) string {
+	tag := byte(addressTagNonBounceable)
+	if bounceable {
+		tag = addressTagBounceable
+	}
+	if testnet {
+		tag |= addressTagTestnet
+	}
+	data := append([]byte{tag, byte(a.Workchain)}, a.Hash[:]...)
+	data = binary.BigEndian.AppendUint16(data, crc16(data))
+	return base64.URLEncoding.EncodeToString(data)
+}
+
+func (a *Address) Equal(other *Address) bool {
+	return other != nil && a.Workchain == other.Workchain && a.Hash == other.Hash
+}
+
+// 解析后的地址及 user-friendly 格式中的标志，raw 格式的地址 Friendly 为 false
+type ParsedAddress struct {
+	*Address
+	Friendly   bool
+	Bounceable bool
+	Testnet    bool
+}
+
+// 解析 raw 或 user-friendly（base64 / base64url）格式的地址
+func ParseAddress(address string) (*ParsedAddress, error) {
+	if workchain, hash, ok := strings.Cut(address, ":"); ok {
+		wc, err := strconv.ParseInt(workchain, 10, 8)
+		if err != nil {
+			return nil, errors.New("invalid workchain")
+		}
+		hashBytes, err := hex.DecodeString(hash)
+		if err != nil || len(hashBytes) != 32 {
+			return nil, errors.New("invalid address hash")
+		}
+		parsed := &ParsedAddress{Address: &Address{Workchain: int8(wc)}}
+		copy(parsed.Hash[:], hashBytes)
+		return parsed, nil
+	}
+
+	if len(address) != 48 {
+		return nil, errors.New("invalid address length")
+	}
+	data, err := base64.URLEncoding.DecodeString(strings.NewReplacer("+", "-", "/", "_").Replace(address))
+	if err != nil || len(data) != 36 {
+		return nil, errors.New("invalid address encoding")
+	}
+	if crc16(data[:34]) != binary.BigEndian.Uint16(data[34:]) {
+		return nil, errors.New("invalid address checksum")
+	}
+	tag := data[0]
+	parsed := &ParsedAddress{
+		Address:  &Address{Workchain: int8(data[1])},
+		Friendly: true,
+		Testnet:  tag&addressTagTestnet != 0,
+	}
+	switch tag &^ addressTagTestnet {
+	case addressTagBounceable:
+		parsed.Bounceable = true
+	case addressTagNonBounceable:
+	default:
+		return nil, errors.New("invalid address tag")
+	}
+	copy(parsed.Hash[:], data[2:34])
+	return parsed, nil
+}
+
+// 只接受 basechain（0）和 masterchain（-1）地址
+func ValidateAddress(address string) bool {
+	parsed, err := ParseAddress(address)
+	return err == nil && (parsed.Workchain == 0 || parsed.Workchain == -1)
+}
+
+// CRC16-XMODEM
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
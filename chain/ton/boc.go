@@ -0,0 +1,244 @@
+package ton
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+var bocMagic = []byte{0xb5, 0xee, 0x9c, 0x72}
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// 序列化为单根 BOC，不带索引，附加 crc32c 校验
+func SerializeBoc(root *Cell) []byte {
+	cells := topologicalCells(root)
+	index := make(map[string]int, len(cells))
+	for i, c := range cells {
+		index[string(c.Hash())] = i
+	}
+	sizeBytes := byteLen(uint64(len(cells)))
+
+	var payload []byte
+	for _, c := range cells {
+		descriptors := c.descriptors()
+		payload = append(payload, descriptors[:]...)
+		payload = append(payload, c.paddedData()...)
+		for _, ref := range c.refs {
+			payload = appendUintBytes(payload, uint64(index[string(ref.Hash())]), sizeBytes)
+		}
+	}
+	offsetBytes := byteLen(uint64(len(payload)))
+
+	boc := append([]byte{}, bocMagic...)
+	// has_idx = 0, has_crc32c = 1, has_cache_bits = 0, flags = 0, size_bytes
+	boc = append(boc, 0x40|byte(sizeBytes), byte(offsetBytes))
+	boc = appendUintBytes(boc, uint64(len(cells)), sizeBytes)
+	boc = appendUintBytes(boc, 1, sizeBytes) // roots
+	boc = appendUintBytes(boc, 0, sizeBytes) // absent
+	boc = appendUintBytes(boc, uint64(len(payload)), offsetBytes)
+	boc = appendUintBytes(boc, 0, sizeBytes) // root index
+	boc = append(boc, payload...)
+	return binary.LittleEndian.AppendUint32(boc, crc32.Checksum(boc, crc32c))
+}
+
+// 解析 BOC，返回根 cell 列表
+func ParseBoc(data []byte) ([]*Cell, error) {
+	if !bytes.HasPrefix(data, bocMagic) || len(data) < 6 {
+		return nil, errors.New("invalid boc magic")
+	}
+	flags := data[4]
+	hasIdx, hasCrc := flags&0x80 != 0, flags&0x40 != 0
+	sizeBytes, offsetBytes := int(flags&0x07), int(data[5])
+	if sizeBytes == 0 || sizeBytes > 4 || offsetBytes == 0 || offsetBytes > 8 {
+		return nil, errors.New("invalid boc header")
+	}
+	if hasCrc {
+		if len(data) < 4 {
+			return nil, errors.New("invalid boc length")
+		}
+		body := data[:len(data)-4]
+		if crc32.Checksum(body, crc32c) != binary.LittleEndian.Uint32(data[len(data)-4:]) {
+			return nil, errors.New("invalid boc checksum")
+		}
+		data = body
+	}
+
+	r := &bocReader{buf: data[6:]}
+	cellCount := int(r.uint(sizeBytes))
+	rootCount := int(r.uint(sizeBytes))
+	r.uint(sizeBytes) // absent
+	r.uint(offsetBytes)
+	if r.err == nil && (rootCount > cellCount || cellCount > len(r.buf)) {
+		return nil, errors.New("invalid boc cell count")
+	}
+	rootIndexes := make([]int, rootCount)
+	for i := range rootIndexes {
+		rootIndexes[i] = int(r.uint(sizeBytes))
+	}
+	if hasIdx {
+		r.bytes(cellCount * offsetBytes)
+	}
+
+	type rawCell struct {
+		data   []byte
+		bitLen int
+		refs   []int
+	}
+	raws := make([]rawCell, cellCount)
+	for i := 0; i < cellCount && r.err == nil; i++ {
+		d1, d2 := r.byte(), r.byte()
+		if d1&0x08 != 0 || d1>>5 != 0 {
+			return nil, errors.New("exotic cells are not supported")
+		}
+		refCount := int(d1 & 0x07)
+		if refCount > maxCellRefs {
+			return nil, errors.New("invalid cell refs")
+		}
+		raw := rawCell{data: append([]byte{}, r.bytes(int(d2+1)/2)...)}
+		raw.bitLen = len(raw.data) * 8
+		// d2 为奇数时末尾有补位：去掉最后一个 1 及其后的 0
+		if d2%2 == 1 && len(raw.data) > 0 {
+			last := raw.data[len(raw.data)-1]
+			if last == 0 {
+				return nil, errors.New("invalid cell padding")
+			}
+			for bit := 0; bit < 8; bit++ {
+				if last&(1<<bit) != 0 {
+					raw.bitLen -= bit + 1
+					raw.data[len(raw.data)-1] &^= 1 << bit
+					break
+				}
+			}
+		}
+		for j := 0; j < refCount; j++ {
+			ref := int(r.uint(sizeBytes))
+			if ref <= i || ref >= cellCount {
+				return nil, fmt.Errorf("invalid ref %d in cell %d", ref, i)
+			}
+			raw.refs = append(raw.refs, ref)
+		}
+		raws[i] = raw
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	// 引用总是指向后面的 cell，倒序构建
+	cells := make([]*Cell, cellCount)
+	for i := cellCount - 1; i >= 0; i-- {
+		c := &Cell{data: raws[i].data, bitLen: raws[i].bitLen}
+		for _, ref := range raws[i].refs {
+			c.refs = append(c.refs, cells[ref])
+		}
+		cells[i] = c
+	}
+	roots := make([]*Cell, rootCount)
+	for i, index := range rootIndexes {
+		if index >= cellCount {
+			return nil, errors.New("invalid root index")
+		}
+		roots[i] = cells[index]
+	}
+	return roots, nil
+}
+
+// 解析单根 BOC
+func ParseBocRoot(data []byte) (*Cell, error) {
+	roots, err := ParseBoc(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) != 1 {
+		return nil, fmt.Errorf("expected 1 root, got %d", len(roots))
+	}
+	return roots[0], nil
+}
+
+func mustParseBocHex(value string) *Cell {
+	data, err := hex.DecodeString(value)
+	if err != nil {
+		panic(err)
+	}
+	root, err := ParseBocRoot(data)
+	if err != nil {
+		panic(err)
+	}
+	return root
+}
+
+// 按拓扑序排列 cell（父 cell 在前），相同的 cell 只保留一份
+func topologicalCells(root *Cell) []*Cell {
+	visited := map[string]bool{}
+	var postOrder []*Cell
+	var visit func(c *Cell)
+	visit = func(c *Cell) {
+		hash := string(c.Hash())
+		if visited[hash] {
+			return
+		}
+		visited[hash] = true
+		for _, ref := range c.refs {
+			visit(ref)
+		}
+		postOrder = append(postOrder, c)
+	}
+	visit(root)
+	cells := make([]*Cell, len(postOrder))
+	for i, c := range postOrder {
+		cells[len(postOrder)-1-i] = c
+	}
+	return cells
+}
+
+func byteLen(n uint64) int {
+	size := 1
+	for n >= 1<<(8*size) {
+		size++
+	}
+	return size
+}
+
+func appendUintBytes(buf []byte, value uint64, size int) []byte {
+	for i := size - 1; i >= 0; i-- {
+		buf = append(buf, byte(value>>(8*i)))
+	}
+	return buf
+}
+
+type bocReader struct {
+	buf []byte
+	err error
+}
+
+func (r *bocReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > len(r.buf) {
+		r.err = errors.New("unexpected end of boc")
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *bocReader) byte() byte {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *bocReader) uint(size int) uint64 {
+	var value uint64
+	for _, item := range r.bytes(size) {
+		value = value<<8 | uint64(item)
+	}
+	return value
+}
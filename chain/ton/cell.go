@@ -0,0 +1,322 @@
+package ton
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// 单个 cell 最多 1023 位数据和 4 个引用
+const (
+	maxCellBits = 1023
+	maxCellRefs = 4
+)
+
+// 普通 cell（不支持 exotic cell），数据按位存储，最后一个字节不足 8 位时低位补 0
+type Cell struct {
+	data   []byte
+	bitLen int
+	refs   []*Cell
+}
+
+func (c *Cell) BitLen() int {
+	return c.bitLen
+}
+
+func (c *Cell) Refs() []*Cell {
+	return c.refs
+}
+
+// cell 深度：没有引用时为 0，否则为子 cell 最大深度 + 1
+func (c *Cell) depth() uint16 {
+	var depth uint16
+	for _, ref := range c.refs {
+		depth = max(depth, ref.depth()+1)
+	}
+	return depth
+}
+
+// 描述字节：d1 为引用数，d2 为数据字节数（不足 8 位的字节计为半个）
+func (c *Cell) descriptors() [2]byte {
+	return [2]byte{byte(len(c.refs)), byte(c.bitLen/8 + (c.bitLen+7)/8)}
+}
+
+// 数据不足整字节时在末尾补一个 1 位
+func (c *Cell) paddedData() []byte {
+	data := append([]byte{}, c.data[:(c.bitLen+7)/8]...)
+	if c.bitLen%8 != 0 {
+		data[len(data)-1] |= 0x80 >> (c.bitLen % 8)
+	}
+	return data
+}
+
+// cell 的表示哈希：sha256(描述字节 || 数据 || 子 cell 深度 || 子 cell 哈希)
+func (c *Cell) Hash() []byte {
+	descriptors := c.descriptors()
+	repr := append(descriptors[:], c.paddedData()...)
+	for _, ref := range c.refs {
+		repr = binary.BigEndian.AppendUint16(repr, ref.depth())
+	}
+	for _, ref := range c.refs {
+		repr = append(repr, ref.Hash()...)
+	}
+	hash := sha256.Sum256(repr)
+	return hash[:]
+}
+
+func (c *Cell) BeginParse() *Slice {
+	return &Slice{cell: c}
+}
+
+// 按位构建 cell，出错后后续写入忽略，EndCell 时返回错误
+type Builder struct {
+	data   []byte
+	bitLen int
+	refs   []*Cell
+	err    error
+}
+
+func BeginCell() *Builder {
+	return &Builder{}
+}
+
+func (b *Builder) BitLen() int {
+	return b.bitLen
+}
+
+func (b *Builder) setErr(err error) *Builder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+func (b *Builder) StoreBit(bit bool) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if b.bitLen >= maxCellBits {
+		return b.setErr(errors.New("cell overflow"))
+	}
+	if b.bitLen%8 == 0 {
+		b.data = append(b.data, 0)
+	}
+	if bit {
+		b.data[b.bitLen/8] |= 0x80 >> (b.bitLen % 8)
+	}
+	b.bitLen++
+	return b
+}
+
+func (b *Builder) StoreUint(value uint64, bits int) *Builder {
+	if bits < 64 && value>>bits != 0 {
+		return b.setErr(fmt.Errorf("value %d does not fit in %d bits", value, bits))
+	}
+	for i := bits - 1; i >= 0; i-- {
+		b.StoreBit(value>>i&1 == 1)
+	}
+	return b
+}
+
+func (b *Builder) StoreBigUint(value *big.Int, bits int) *Builder {
+	if value.Sign() < 0 || value.BitLen() > bits {
+		return b.setErr(fmt.Errorf("value %s does not fit in %d bits", value, bits))
+	}
+	for i := bits - 1; i >= 0; i-- {
+		b.StoreBit(value.Bit(i) == 1)
+	}
+	return b
+}
+
+func (b *Builder) StoreBytes(data []byte) *Builder {
+	for _, item := range data {
+		b.StoreUint(uint64(item), 8)
+	}
+	return b
+}
+
+// Coins（VarUInteger 16）：4 位字节数 + 大端数值
+func (b *Builder) StoreCoins(amount *big.Int) *Builder {
+	if amount.Sign() < 0 || amount.BitLen() > 120 {
+		return b.setErr(fmt.Errorf("invalid coins amount %s", amount))
+	}
+	size := (amount.BitLen() + 7) / 8
+	b.StoreUint(uint64(size), 4)
+	return b.StoreBigUint(amount, size*8)
+}
+
+// MsgAddress：nil 为 addr_none$00，否则为 addr_std$10 anycast:nothing workchain:int8 hash:bits256
+func (b *Builder) StoreAddress(address *Address) *Builder {
+	if address == nil {
+		return b.StoreUint(0, 2)
+	}
+	b.StoreUint(0b100, 3)
+	b.StoreUint(uint64(uint8(address.Workchain)), 8)
+	return b.StoreBytes(address.Hash[:])
+}
+
+func (b *Builder) StoreRef(ref *Cell) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(b.refs) >= maxCellRefs {
+		return b.setErr(errors.New("too many cell refs"))
+	}
+	b.refs = append(b.refs, ref)
+	return b
+}
+
+// Maybe ^Cell
+func (b *Builder) StoreMaybeRef(ref *Cell) *Builder {
+	if ref == nil {
+		return b.StoreBit(false)
+	}
+	return b.StoreBit(true).StoreRef(ref)
+}
+
+// 追加另一个 cell 的全部数据和引用
+func (b *Builder) StoreCell(c *Cell) *Builder {
+	for i := 0; i < c.bitLen; i++ {
+		b.StoreBit(c.data[i/8]&(0x80>>(i%8)) != 0)
+	}
+	for _, ref := range c.refs {
+		b.StoreRef(ref)
+	}
+	return b
+}
+
+// snake 格式：当前 cell 写满后续接到引用的 cell 中
+func (b *Builder) StoreSnakeBytes(data []byte) *Builder {
+	free := (maxCellBits - b.bitLen) / 8
+	if len(data) <= free {
+		return b.StoreBytes(data)
+	}
+	tail, err := BeginCell().StoreSnakeBytes(data[free:]).EndCell()
+	if err != nil {
+		return b.setErr(err)
+	}
+	return b.StoreBytes(data[:free]).StoreRef(tail)
+}
+
+func (b *Builder) EndCell() (*Cell, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return &Cell{data: append([]byte{}, b.data...), bitLen: b.bitLen, refs: append([]*Cell{}, b.refs...)}, nil
+}
+
+// 按位读取 cell，出错后后续读取返回零值，通过 Err 获取错误
+type Slice struct {
+	cell   *Cell
+	offset int
+	refIdx int
+	err    error
+}
+
+func (s *Slice) Err() error {
+	return s.err
+}
+
+func (s *Slice) LoadBit() bool {
+	if s.err != nil {
+		return false
+	}
+	if s.offset >= s.cell.bitLen {
+		s.err = errors.New("cell underflow")
+		return false
+	}
+	bit := s.cell.data[s.offset/8]&(0x80>>(s.offset%8)) != 0
+	s.offset++
+	return bit
+}
+
+func (s *Slice) LoadUint(bits int) uint64 {
+	var value uint64
+	for i := 0; i < bits; i++ {
+		value = value<<1 | boolToUint(s.LoadBit())
+	}
+	return value
+}
+
+func (s *Slice) LoadBigUint(bits int) *big.Int {
+	value := new(big.Int)
+	for i := 0; i < bits; i++ {
+		value.Lsh(value, 1)
+		value.SetBit(value, 0, uint(boolToUint(s.LoadBit())))
+	}
+	return value
+}
+
+func (s *Slice) LoadBytes(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(s.LoadUint(8))
+	}
+	return data
+}
+
+func (s *Slice) LoadCoins() *big.Int {
+	return s.LoadBigUint(int(s.LoadUint(4)) * 8)
+}
+
+// 读取 MsgAddress，addr_none 返回 nil，不支持外部地址和 anycast
+func (s *Slice) LoadAddress() *Address {
+	switch s.LoadUint(2) {
+	case 0b00:
+		return nil
+	case 0b10:
+		if s.LoadBit() {
+			s.setErr(errors.New("anycast address is not supported"))
+			return nil
+		}
+		address := &Address{Workchain: int8(s.LoadUint(8))}
+		copy(address.Hash[:], s.LoadBytes(32))
+		return address
+	default:
+		s.setErr(errors.New("unsupported address type"))
+		return nil
+	}
+}
+
+func (s *Slice) LoadRef() *Cell {
+	if s.err != nil {
+		return nil
+	}
+	if s.refIdx >= len(s.cell.refs) {
+		s.err = errors.New("no more cell refs")
+		return nil
+	}
+	ref := s.cell.refs[s.refIdx]
+	s.refIdx++
+	return ref
+}
+
+// 剩余的数据和引用组成的 cell
+func (s *Slice) ToCell() (*Cell, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	b := BeginCell()
+	for i := s.offset; i < s.cell.bitLen; i++ {
+		b.StoreBit(s.cell.data[i/8]&(0x80>>(i%8)) != 0)
+	}
+	for _, ref := range s.cell.refs[s.refIdx:] {
+		b.StoreRef(ref)
+	}
+	return b.EndCell()
+}
+
+func (s *Slice) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func boolToUint(bit bool) uint64 {
+	if bit {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,185 @@
+package ton
+
+import (
+	"errors"
+	"math/big"
+)
+
+// 钱包发送消息的模式：由发送方单独支付转发费用，忽略动作阶段的错误
+const sendModePayFeesSeparately = 3
+
+// 合约操作码
+const (
+	opComment        = 0
+	opJettonTransfer = 0x0f8a7ea5
+)
+
+// 文本备注：op = 0 + snake 格式的 UTF-8 文本
+func commentBody(comment string) (*Cell, error) {
+	return BeginCell().StoreUint(opComment, 32).StoreSnakeBytes([]byte(comment)).EndCell()
+}
+
+// 内部消息（MessageRelaxed）：int_msg_info$0 ihr_disabled bounce bounced src dest value ihr_fee fwd_fee created_lt created_at，
+// 不带 StateInit，消息体放在引用中
+func internalMessage(dest *Address, bounce bool, amount *big.Int, body *Cell) (*Cell, error) {
+	b := BeginCell().
+		StoreBit(false).          // int_msg_info$0
+		StoreBit(true).           // ihr_disabled
+		StoreBit(bounce).         // bounce
+		StoreBit(false).          // bounced
+		StoreAddress(nil).        // src，由合约填写
+		StoreAddress(dest).       // dest
+		StoreCoins(amount).       // value.grams
+		StoreBit(false).          // value.other，空字典
+		StoreCoins(new(big.Int)). // ihr_fee
+		StoreCoins(new(big.Int)). // fwd_fee
+		StoreUint(0, 64).         // created_lt
+		StoreUint(0, 32).         // created_at
+		StoreBit(false)           // init
+	if body == nil {
+		b.StoreBit(false) // 空消息体
+	} else {
+		b.StoreBit(true).StoreRef(body)
+	}
+	return b.EndCell()
+}
+
+// 外部消息：ext_in_msg_info$10 src:addr_none dest import_fee:0，stateInit 非空时随消息部署合约，消息体放在引用中
+func externalMessage(dest *Address, stateInit, body *Cell) (*Cell, error) {
+	b := BeginCell().
+		StoreUint(0b10, 2).
+		StoreAddress(nil).
+		StoreAddress(dest).
+		StoreCoins(new(big.Int))
+	if stateInit == nil {
+		b.StoreBit(false)
+	} else {
+		b.StoreBit(true).StoreBit(true).StoreRef(stateInit)
+	}
+	return b.StoreBit(true).StoreRef(body).EndCell()
+}
+
+// 规范化的外部消息哈希（TEP-467）：去掉 StateInit 后的消息哈希，部署和非部署交易得到相同的哈希，用于查询交易
+func normalizedMessageHash(dest *Address, body *Cell) ([]byte, error) {
+	msg, err := externalMessage(dest, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Hash(), nil
+}
+
+// jetton 转账消息体（TEP-74），发给发送方自己的 jetton 钱包：
+// transfer#0f8a7ea5 query_id amount destination response_destination custom_payload forward_ton_amount forward_payload
+func jettonTransferBody(queryId uint64, amount *big.Int, dest, responseDest *Address, forwardAmount *big.Int, forwardPayload *Cell) (*Cell, error) {
+	b := BeginCell().
+		StoreUint(opJettonTransfer, 32).
+		StoreUint(queryId, 64).
+		StoreCoins(amount).
+		StoreAddress(dest).
+		StoreAddress(responseDest).
+		StoreBit(false). // custom_payload
+		StoreCoins(forwardAmount)
+	if forwardPayload == nil {
+		b.StoreBit(false)
+	} else {
+		b.StoreBit(true).StoreRef(forwardPayload)
+	}
+	return b.EndCell()
+}
+
+// 解析外部消息，返回目标地址、StateInit（没有时为 nil）和消息体
+func parseExternalMessage(msg *Cell) (*Address, *Cell, *Cell, error) {
+	s := msg.BeginParse()
+	if s.LoadUint(2) != 0b10 {
+		return nil, nil, nil, errors.New("not an external message")
+	}
+	s.LoadAddress()
+	dest := s.LoadAddress()
+	s.LoadCoins()
+	var stateInit *Cell
+	if s.LoadBit() {
+		if !s.LoadBit() {
+			return nil, nil, nil, errors.New("inline state init is not supported")
+		}
+		stateInit = s.LoadRef()
+	}
+	var body *Cell
+	var err error
+	if s.LoadBit() {
+		body = s.LoadRef()
+	} else {
+		body, err = s.ToCell()
+	}
+	if s.Err() != nil {
+		return nil, nil, nil, s.Err()
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if dest == nil {
+		return nil, nil, nil, errors.New("empty destination")
+	}
+	return dest, stateInit, body, nil
+}
+
+// 解析文本备注，不是备注时返回 false
+func parseComment(body *Cell) (string, bool) {
+	if body == nil {
+		return "", false
+	}
+	s := body.BeginParse()
+	if body.BitLen() < 32 || s.LoadUint(32) != opComment {
+		return "", false
+	}
+	var text []byte
+	for {
+		text = append(text, s.LoadBytes((s.cell.bitLen-s.offset)/8)...)
+		if s.Err() != nil || len(s.cell.refs) == 0 {
+			break
+		}
+		s = s.cell.refs[0].BeginParse()
+	}
+	if s.Err() != nil {
+		return "", false
+	}
+	return string(text), true
+}
+
+// 解析出的 jetton transfer 消息体
+type jettonTransfer struct {
+	Amount      *big.Int
+	Destination *Address
+	Comment     string
+}
+
+// 解析 jetton transfer 消息体，forward_payload 为文本备注时一并返回
+func parseJettonTransfer(body *Cell) (*jettonTransfer, error) {
+	if body == nil || body.BitLen() < 32 {
+		return nil, errors.New("empty body")
+	}
+	s := body.BeginParse()
+	if s.LoadUint(32) != opJettonTransfer {
+		return nil, errors.New("not a jetton transfer")
+	}
+	s.LoadUint(64) // query_id
+	transfer := &jettonTransfer{Amount: s.LoadCoins(), Destination: s.LoadAddress()}
+	s.LoadAddress() // response_destination
+	if s.LoadBit() {
+		s.LoadRef() // custom_payload
+	}
+	s.LoadCoins() // forward_ton_amount
+	var payload *Cell
+	if s.LoadBit() {
+		payload = s.LoadRef()
+	} else if s.Err() == nil {
+		payload, _ = s.ToCell()
+	}
+	if s.Err() != nil {
+		return nil, s.Err()
+	}
+	if transfer.Destination == nil {
+		return nil, errors.New("empty jetton destination")
+	}
+	transfer.Comment, _ = parseComment(payload)
+	return transfer, nil
+}
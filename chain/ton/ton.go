@@ -0,0 +1,699 @@
+package ton
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Ton"
+
+// 单次 GetBlockByRange 最多查询的区块数，每个区块需要一次请求
+const blockRangeLimit = 100
+
+// 手续费估算的默认值（nanoton）：TON 转账、首次转账部署钱包的额外费用，jetton 转账按附带的 TON 计算，多余部分会退回
+const (
+	tonTransferFee  = 6_000_000
+	walletDeployFee = 4_000_000
+)
+
+// GetTxByAddress 默认每页数量
+const defaultPageSize = 20
+
+type ChainAdaptor struct {
+	TonClient ITon
+	Testnet   bool
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.Ton
+	network := con.NetWork
+	if node.Network != "" {
+		network = node.Network
+	}
+	tonClient, err := NewTonClient(node.RpcUrl, node.DataApiUrl, node.DataApiKey, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		TonClient: tonClient,
+		Testnet:   network == "testnet",
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入 ed25519 公钥 转换成钱包合约地址，type 为钱包版本（v4r2、v5r1，默认 v4r2），返回 non-bounceable 格式
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeEd25519); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	wallet, err := c.newWallet(req.Type, req.PublicKey)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	address, err := wallet.Address()
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address.String(false, c.Testnet),
+	}, nil
+}
+
+// 地址格式验证，支持 raw 和 user-friendly 格式
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// TON 的交易分布在各个分片链中，按区块获取交易需要遍历分片，这里不支持，按地址或哈希查询交易
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	return &account.BlockResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block by number is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return &account.BlockResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block by hash is not supported",
+	}, nil
+}
+
+// 通过 masterchain 区块号获取区块头信息，height 为 0 时返回最新区块
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	seqno := uint64(req.Height)
+	if seqno == 0 {
+		info, err := c.TonClient.GetMasterchainInfo()
+		if err != nil {
+			log.Error("get masterchain info fail", "err", err)
+			return &account.BlockHeaderResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block header by number fail",
+			}, nil
+		}
+		seqno = info.Last.Seqno
+	}
+	header, err := c.TonClient.GetBlockHeader(seqno)
+	if err != nil {
+		log.Error("get block header by number fail", "seqno", seqno, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(header),
+	}, nil
+}
+
+// toncenter 不支持按区块哈希查询
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	return &account.BlockHeaderResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get block header by hash is not supported",
+	}, nil
+}
+
+// 获取区间内的 masterchain 区块头
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseUint(req.Start, 10, 64)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseUint(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	if end-start >= blockRangeLimit {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "block range too large",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for seqno := start; seqno <= end; seqno++ {
+		header, err := c.TonClient.GetBlockHeader(seqno)
+		if err != nil {
+			log.Error("get block header fail", "seqno", seqno, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		headers = append(headers, toBlockHeader(header))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取账户余额和 seqno，contract_address 为 jetton master 时查询 jetton 余额
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	address, err := ParseAddress(req.Address)
+	if err != nil {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	info, err := c.TonClient.GetAddressInformation(address.Raw())
+	if err != nil {
+		log.Error("get address information fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	var seqno uint32
+	if info.State == AccountStateActive {
+		if seqno, err = c.getSeqno(address.Address); err != nil {
+			log.Error("get seqno fail", "address", req.Address, "err", err)
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get seqno fail",
+			}, nil
+		}
+	}
+	balance := info.Balance
+	if req.ContractAddress != "" {
+		master, err := ParseAddress(req.ContractAddress)
+		if err != nil {
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid contract address",
+			}, nil
+		}
+		amount, err := c.getJettonBalance(master.Address, address.Address)
+		if err != nil {
+			log.Error("get jetton balance fail", "address", req.Address, "contract", req.ContractAddress, "err", err)
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get account fail",
+			}, nil
+		}
+		balance = amount.String()
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      strconv.FormatUint(uint64(seqno), 10),
+		Balance:       balance,
+	}, nil
+}
+
+// 获取fee，单位 nanoton。
+// 传入 rawTx（BuildUnSignTransaction 返回的 un_sign_tx）时通过节点模拟执行估算，三档返回相同结果；
+// 否则 slow 为 TON 转账，normal 为首次转账（部署钱包）的 TON 转账，fast 为 jetton 转账需要附带的 TON
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	if req.RawTx == "" {
+		return &account.FeeResponse{
+			Code:      global_const.ReturnCode_SUCCESS,
+			Msg:       "get fee success",
+			SlowFee:   strconv.Itoa(tonTransferFee),
+			NormalFee: strconv.Itoa(tonTransferFee + walletDeployFee),
+			FastFee:   strconv.Itoa(defaultJettonTonAmount),
+		}, nil
+	}
+	unsigned, wallet, payload, err := c.decodeUnsignedMessage(req.RawTx)
+	if err != nil {
+		log.Error("decode unsigned message fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	// 模拟执行不校验签名，使用全 0 签名
+	body, err := wallet.SignedBody(payload, make([]byte, ed25519.SignatureSize))
+	if err != nil {
+		log.Error("build message body fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	var initCode, initData []byte
+	if unsigned.Deploy {
+		data, err := wallet.data()
+		if err != nil {
+			log.Error("build wallet data fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		initCode, initData = SerializeBoc(walletCodes[wallet.Version]), SerializeBoc(data)
+	}
+	address, _ := wallet.Address()
+	result, err := c.TonClient.EstimateFee(address.Raw(), SerializeBoc(body), initCode, initData)
+	if err != nil {
+		log.Error("estimate fee fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "estimate fee fail",
+		}, nil
+	}
+	fee := strconv.FormatInt(result.SourceFees.Total(), 10)
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee,
+		NormalFee: fee,
+		FastFee:   fee,
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的 base64 BOC，tx_hash 为外部消息的规范化哈希
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	boc, err := base64.StdEncoding.DecodeString(req.RawTx)
+	if err != nil {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	msgHash, err := externalMessageHash(boc)
+	if err != nil {
+		log.Error("parse external message fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	if err := c.TonClient.SendBoc(boc); err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: hex.EncodeToString(msgHash),
+	}, nil
+}
+
+// 按地址分页查询交易，按 lt 倒序，page 从 1 开始
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	address, err := ParseAddress(req.Address)
+	if err != nil {
+		return &account.TxAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	var offset uint32
+	if req.Page > 1 {
+		offset = (req.Page - 1) * pageSize
+	}
+	list, err := c.TonClient.GetAccountTransactions(address.Raw(), pageSize, offset)
+	if err != nil {
+		log.Error("get account transactions fail", "address", req.Address, "err", err)
+		return &account.TxAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get tx by address fail",
+		}, nil
+	}
+	var txs []*account.TxMessage
+	for i := range list.Transactions {
+		txs = append(txs, c.toTxMessage(&list.Transactions[i], list))
+	}
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txs,
+	}, nil
+}
+
+// 通过交易哈希获取交易，hash 可以带 lt（lt:hash），也可以是 SendTx 返回的外部消息哈希
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	lt, hash, ok := strings.Cut(req.Hash, ":")
+	if !ok {
+		lt, hash = "", req.Hash
+	}
+	list, err := c.TonClient.GetTransactions(hash, lt)
+	if err == nil && len(list.Transactions) == 0 && lt == "" {
+		list, err = c.TonClient.GetTransactionsByMessage(hash)
+	}
+	if err != nil {
+		log.Error("get transaction fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	if len(list.Transactions) == 0 {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   c.toTxMessage(&list.Transactions[0], list),
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// 按版本和十六进制公钥创建钱包
+func (c *ChainAdaptor) newWallet(version, publicKey string) (*Wallet, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(publicKey, "0x"))
+	if err != nil {
+		return nil, errors.New("invalid public key")
+	}
+	return NewWallet(version, pubKey, c.Testnet)
+}
+
+// 钱包合约的 seqno
+func (c *ChainAdaptor) getSeqno(address *Address) (uint32, error) {
+	result, err := c.TonClient.RunGetMethod(address.Raw(), "seqno", nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(result.Stack) == 0 {
+		return 0, errors.New("empty stack")
+	}
+	seqno, err := stackNum(result.Stack[0])
+	if err != nil {
+		return 0, err
+	}
+	if !seqno.IsUint64() || seqno.Uint64() > 1<<32-1 {
+		return 0, errors.New("invalid seqno")
+	}
+	return uint32(seqno.Uint64()), nil
+}
+
+// 通过 jetton master 的 get_wallet_address 获取持有者的 jetton 钱包地址
+func (c *ChainAdaptor) getJettonWallet(master, owner *Address) (*Address, error) {
+	ownerCell, err := BeginCell().StoreAddress(owner).EndCell()
+	if err != nil {
+		return nil, err
+	}
+	result, err := c.TonClient.RunGetMethod(master.Raw(), "get_wallet_address", [][]string{sliceArg(ownerCell)})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Stack) == 0 {
+		return nil, errors.New("empty stack")
+	}
+	cell, err := stackCell(result.Stack[0])
+	if err != nil {
+		return nil, err
+	}
+	s := cell.BeginParse()
+	address := s.LoadAddress()
+	if s.Err() != nil {
+		return nil, s.Err()
+	}
+	if address == nil {
+		return nil, errors.New("empty jetton wallet address")
+	}
+	return address, nil
+}
+
+// jetton 钱包未部署时余额为 0，否则通过 get_wallet_data 获取余额
+func (c *ChainAdaptor) getJettonBalance(master, owner *Address) (*big.Int, error) {
+	jettonWallet, err := c.getJettonWallet(master, owner)
+	if err != nil {
+		return nil, err
+	}
+	info, err := c.TonClient.GetAddressInformation(jettonWallet.Raw())
+	if err != nil {
+		return nil, err
+	}
+	if info.State != AccountStateActive {
+		return new(big.Int), nil
+	}
+	result, err := c.TonClient.RunGetMethod(jettonWallet.Raw(), "get_wallet_data", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Stack) < 3 {
+		return nil, errors.New("invalid get_wallet_data result")
+	}
+	return stackNum(result.Stack[0])
+}
+
+// 通过 jetton 钱包的 get_wallet_data 获取 jetton master 地址
+func (c *ChainAdaptor) getJettonMaster(jettonWallet *Address) (*Address, error) {
+	result, err := c.TonClient.RunGetMethod(jettonWallet.Raw(), "get_wallet_data", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Stack) < 3 {
+		return nil, errors.New("invalid get_wallet_data result")
+	}
+	cell, err := stackCell(result.Stack[2])
+	if err != nil {
+		return nil, err
+	}
+	s := cell.BeginParse()
+	master := s.LoadAddress()
+	if s.Err() != nil {
+		return nil, s.Err()
+	}
+	if master == nil {
+		return nil, errors.New("empty jetton master address")
+	}
+	return master, nil
+}
+
+// 外部消息的规范化哈希
+func externalMessageHash(boc []byte) ([]byte, error) {
+	msg, err := ParseBocRoot(boc)
+	if err != nil {
+		return nil, err
+	}
+	dest, _, body, err := parseExternalMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	return normalizedMessageHash(dest, body)
+}
+
+// 交易中的转账：外部消息触发的交易取第一条发出的消息（TON 或 jetton 转账），内部消息触发的交易取转入的 TON
+func (c *ChainAdaptor) parseTransfer(tx *Transaction, list *TransactionList) (*Transfer, int32) {
+	if tx.InMsg == nil {
+		return nil, 0
+	}
+	if tx.InMsg.Source != nil && *tx.InMsg.Source != "" {
+		transfer := &Transfer{
+			From:   c.formatAddress(*tx.InMsg.Source, list),
+			To:     c.formatAddress(tx.Account, list),
+			Amount: valueOf(tx.InMsg.Value),
+		}
+		transfer.Comment, _ = parseComment(messageBody(tx.InMsg))
+		return transfer, 0
+	}
+	if len(tx.OutMsgs) == 0 || tx.OutMsgs[0].Destination == nil {
+		return nil, 0
+	}
+	out := tx.OutMsgs[0]
+	body := messageBody(out)
+	if jetton, err := parseJettonTransfer(body); err == nil {
+		transfer := &Transfer{
+			From:    c.formatAddress(tx.Account, list),
+			To:      c.formatAddress(jetton.Destination.Raw(), list),
+			Amount:  jetton.Amount.String(),
+			Comment: jetton.Comment,
+		}
+		if jettonWallet, err := ParseAddress(*out.Destination); err == nil {
+			if master, err := c.getJettonMaster(jettonWallet.Address); err != nil {
+				log.Warn("get jetton master fail", "jetton_wallet", *out.Destination, "err", err)
+			} else {
+				transfer.ContractAddress = master.String(true, c.Testnet)
+			}
+		}
+		return transfer, 1
+	}
+	transfer := &Transfer{
+		From:   c.formatAddress(tx.Account, list),
+		To:     c.formatAddress(*out.Destination, list),
+		Amount: valueOf(out.Value),
+	}
+	transfer.Comment, _ = parseComment(body)
+	return transfer, 0
+}
+
+func (c *ChainAdaptor) toTxMessage(tx *Transaction, list *TransactionList) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:     hashHex(tx.Hash),
+		Fee:      tx.TotalFees,
+		Status:   txStatus(tx),
+		Datetime: strconv.FormatInt(tx.Now, 10),
+	}
+	if tx.McBlockSeqno != nil {
+		txMessage.Height = strconv.FormatUint(*tx.McBlockSeqno, 10)
+	}
+	if transfer, txType := c.parseTransfer(tx, list); transfer != nil {
+		txMessage.From = transfer.From
+		txMessage.To = transfer.To
+		txMessage.Value = transfer.Amount
+		txMessage.ContractAddress = transfer.ContractAddress
+		txMessage.Type = txType
+		data, _ := json.Marshal(transfer)
+		txMessage.Data = string(data)
+	}
+	return txMessage
+}
+
+// 优先使用索引接口返回的 user-friendly 地址
+func (c *ChainAdaptor) formatAddress(raw string, list *TransactionList) string {
+	address, err := ParseAddress(raw)
+	if err != nil {
+		return raw
+	}
+	for key, item := range list.AddressBook {
+		if other, err := ParseAddress(key); err == nil && other.Equal(address.Address) && item.UserFriendly != "" {
+			return item.UserFriendly
+		}
+	}
+	return address.String(true, c.Testnet)
+}
+
+// 计算阶段失败为合约执行失败，动作阶段失败为交易失败；
+// 未部署的账户接收转账时计算阶段被跳过，金额仍然到账
+func txStatus(tx *Transaction) account.TxStatus {
+	if tx.Description.Aborted && !tx.Description.ComputePh.Skipped && !tx.Description.ComputePh.Success {
+		return account.TxStatus_ContractExecuteFailed
+	}
+	if tx.Description.Action != nil && !tx.Description.Action.Success {
+		return account.TxStatus_Failed
+	}
+	return account.TxStatus_Success
+}
+
+func messageBody(msg *Message) *Cell {
+	if msg.MessageContent == nil || msg.MessageContent.Body == "" {
+		return nil
+	}
+	boc, err := base64.StdEncoding.DecodeString(msg.MessageContent.Body)
+	if err != nil {
+		return nil
+	}
+	body, err := ParseBocRoot(boc)
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+func valueOf(value *string) string {
+	if value == nil {
+		return "0"
+	}
+	return *value
+}
+
+// 索引接口返回 base64 格式的哈希，统一转为十六进制
+func hashHex(hash string) string {
+	data, err := base64.StdEncoding.DecodeString(hash)
+	if err != nil {
+		if data, err = base64.URLEncoding.DecodeString(hash); err != nil {
+			return hash
+		}
+	}
+	return hex.EncodeToString(data)
+}
+
+func toBlockHeader(header *BlockHeader) *account.BlockHeader {
+	blockHeader := &account.BlockHeader{
+		Hash:   hashHex(header.Id.RootHash),
+		Number: strconv.FormatUint(header.Id.Seqno, 10),
+		Time:   header.GenUtime,
+	}
+	if len(header.PrevBlocks) > 0 {
+		blockHeader.ParentHash = hashHex(header.PrevBlocks[0].RootHash)
+	}
+	return blockHeader
+}
@@ -0,0 +1,331 @@
+package ton
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 私钥种子为 0x00..01 时 v4r2、v5r1 钱包的地址
+const (
+	testWalletV4     = "EQAkxuybaeBflbgYW3vgQvcFgyAQVcLtXZwdrpeLNqzZ000c"
+	testWalletV5     = "EQCcCUPw6Gl_Q9MuCKaOFkqCJAnwNqDO-Tt_y4sIUdpyT_5l"
+	testWalletV5Non  = "UQCcCUPw6Gl_Q9MuCKaOFkqCJAnwNqDO-Tt_y4sIUdpyT6Og"
+	testWalletV5Test = "0QBnSM49YHTHiwAI0pAtP8Trk0npTjxR0vaIHf8wmedYR6gE"
+)
+
+// USDT jetton master 地址
+const usdtMaster = "EQCxE6mUtQJKFnGfaROTKOt1lZbDiiX1kCixRv7Nw2Id_sDs"
+
+// tonutils-go 生成的 v4r2 部署 + 转账外部消息
+const testExternalBoc = "b5ee9c7241021a010003be0002458800498dd936d3c0bf2b7030b6f7c085ee0b064020ab85dabb383b5d2f166d59b3a61e01020201340304019c698ebad5f3350eb949bd08a9cbb54323982b1cc9d833de4e0cc0395ac77f53272b41729ea1f809f64e05f46ea6a694d7b98d517fb744214d9591eb61e57d730f29a9a3176553f100000000000003050114ff00f4a413f4bcf2c80b0600510000000029a9a3174cb5abf6ad79fbf5abbccafcc269d85cd2651ed4b885b5869f241aedf0a5ba2940016842004e04a1f87434bfa1e9970453470b25411204f81b50677c9dbfe5c58428ed3927a1dcd650000000000000000000000000000107020120080900120000000068656c6c6f0201480a0b04f8f28308d71820d31fd31fd31f02f823bbf264ed44d0d31fd31fd3fff404d15143baf2a15151baf2a205f901541064f910f2a3f80024a4c8cb1f5240cb1f5230cbff5210f400c9ed54f80f01d30721c0009f6c519320d74a96d307d402fb00e830e021c001e30021c002e30001c0039130e30d03a4c8cb1f12cb1fcbff0c0d0e0f02e6d001d0d3032171b0925f04e022d749c120925f04e002d31f218210706c7567bd22821064737472bdb0925f05e003fa403020fa4401c8ca07cbffc9d0ed44d0810140d721f404305c810108f40a6fa131b3925f07e005d33fc8258210706c7567ba923830e30d03821064737472ba925f06e30d10110201201213006ed207fa00d4d422f90005c8ca0715cbffc9d077748018c8cb05cb0222cf165005fa0214cb6b12ccccc973fb00c84014810108f451f2a7020070810108d718fa00d33fc8542047810108f451f2a782106e6f746570748018c8cb05cb025006cf165004fa0214cb6a12cb1fcb3fc973fb0002006c810108d718fa00d33f305224810108f459f2a782106473747270748018c8cb05cb025005cf165003fa0213cb6acb1f12cb3fc973fb00000af400c9ed54007801fa00f40430f8276f2230500aa121bef2e0508210706c7567831eb17080185004cb0526cf1658fa0219f400cb6917cb1f5260cb3f20c98040fb0006008a5004810108f45930ed44d0810140d720c801cf16f400c9ed540172b08e23821064737472831eb17080185005cb055003cf1623fa0213cb6acb1fcb3fc98040fb00925f03e202012014150059bd242b6f6a2684080a06b90fa0218470d4080847a4937d29910ce6903e9ff9837812801b7810148987159f318402015816170011b8c97ed44d0d70b1f8003db29dfb513420405035c87d010c00b23281f2fff274006040423d029be84c6002012018190019adce76a26840206b90eb85ffc00019af1df6a26840106b90eb858fc0274a7288"
+
+// 模拟 toncenter，states 中不存在的账户视为未部署，jetton 钱包地址由 jettonWallet 返回
+type fakeTonNode struct {
+	ITon
+	states       map[string]string
+	seqno        uint64
+	jettonWallet *Address
+}
+
+func (f *fakeTonNode) GetAddressInformation(address string) (*AddressInformation, error) {
+	state, ok := f.states[address]
+	if !ok {
+		state = AccountStateUninitialized
+	}
+	return &AddressInformation{Balance: "1000000000", State: state}, nil
+}
+
+func (f *fakeTonNode) RunGetMethod(address, method string, stack [][]string) (*RunGetMethodResult, error) {
+	switch method {
+	case "seqno":
+		entry, _ := json.Marshal([]string{"num", "0x" + new(big.Int).SetUint64(f.seqno).Text(16)})
+		return &RunGetMethodResult{Stack: []json.RawMessage{entry}}, nil
+	default:
+		walletCell, _ := BeginCell().StoreAddress(f.jettonWallet).EndCell()
+		entry, _ := json.Marshal([]any{"cell", map[string]string{"bytes": base64.StdEncoding.EncodeToString(SerializeBoc(walletCell))}})
+		return &RunGetMethodResult{Stack: []json.RawMessage{entry}}, nil
+	}
+}
+
+func (f *fakeTonNode) EstimateFee(address string, body, initCode, initData []byte) (*EstimateFeeResult, error) {
+	return &EstimateFeeResult{SourceFees: Fees{InFwdFee: 1000, StorageFee: 1, GasFee: 3000}}, nil
+}
+
+func testKey() ed25519.PrivateKey {
+	seed := make([]byte, ed25519.SeedSize)
+	seed[31] = 1
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+func testPubKey() string {
+	return hex.EncodeToString(testKey().Public().(ed25519.PublicKey))
+}
+
+func mustAddress(t *testing.T, address string) *Address {
+	parsed, err := ParseAddress(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Address
+}
+
+func hashOf(t *testing.T, c *Cell, err error) string {
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(c.Hash())
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	adaptor := &ChainAdaptor{}
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPubKey()})
+	if resp.Code != global_const.ReturnCode_SUCCESS || !mustAddress(t, resp.Address).Equal(mustAddress(t, testWalletV4)) {
+		t.Fatalf("unexpected v4r2 address %s", resp.Address)
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPubKey(), Type: WalletV5R1})
+	if resp.Address != testWalletV5Non {
+		t.Fatalf("unexpected v5r1 address %s", resp.Address)
+	}
+	testnet := &ChainAdaptor{Testnet: true}
+	resp, _ = testnet.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPubKey(), Type: WalletV5R1})
+	if resp.Address != testWalletV5Test {
+		t.Fatalf("unexpected testnet v5r1 address %s", resp.Address)
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: testPubKey(), KeyType: chain.KeyTypeSecp256k1})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected secp256k1 key type to be rejected")
+	}
+
+	parsed, err := ParseAddress(testWalletV5)
+	if err != nil || !parsed.Friendly || !parsed.Bounceable || parsed.Testnet {
+		t.Fatalf("unexpected parsed address %+v %v", parsed, err)
+	}
+	if parsed.String(true, false) != testWalletV5 || parsed.String(false, false) != testWalletV5Non {
+		t.Fatal("expected user-friendly address to round trip")
+	}
+	raw, err := ParseAddress(mustAddress(t, testWalletV4).Raw())
+	if err != nil || raw.Friendly || raw.Raw() != "0:24c6ec9b69e05f95b8185b7be042f70583201055c2ed5d9c1dae978b36acd9d3" {
+		t.Fatalf("unexpected raw address %v", err)
+	}
+	if ValidateAddress("EQAkxuybaeBflbgYW3vgQvcFgyAQVcLtXZwdrpeLNqzZ000d") || ValidateAddress("1:"+hex.EncodeToString(make([]byte, 32))) {
+		t.Fatal("expected invalid address to be rejected")
+	}
+}
+
+// 与 tonutils-go 生成的结果对比
+func Test_Cells(t *testing.T) {
+	if hex.EncodeToString(walletCodes[WalletV4R2].Hash()) != "feb5ff6820e2ff0d9483e7e0d62c817d846789fb4ae580c878866d959dabd5c0" ||
+		hex.EncodeToString(walletCodes[WalletV5R1].Hash()) != "20834b7b72b112147e1b2fb457b84e74d1a30f04f737d4f62a668e9552d2b72f" {
+		t.Fatal("unexpected wallet code hash")
+	}
+	key := testKey()
+	walletV4, _ := NewWallet(WalletV4R2, key.Public().(ed25519.PublicKey), false)
+	walletV5, _ := NewWallet(WalletV5R1, key.Public().(ed25519.PublicKey), false)
+	if walletV5.WalletId != 2147483409 {
+		t.Fatalf("unexpected v5r1 wallet id %d", walletV5.WalletId)
+	}
+	dest := mustAddress(t, testWalletV5)
+	oneTon := big.NewInt(1_000_000_000)
+
+	empty, err := internalMessage(dest, true, oneTon, nil)
+	if hash := hashOf(t, empty, err); hash != "ed3d46b34372928726812c67c8f8125172e6f7a96d5748ece499b6ab0201d23b" {
+		t.Fatalf("unexpected internal message hash %s", hash)
+	}
+	comment, _ := commentBody("hello")
+	msg, err := internalMessage(dest, false, oneTon, comment)
+	if hash := hashOf(t, msg, err); hash != "8a2b25bf509ded98f377d5ddf1c0c8e6496a9f39ddc1e4f592c671f8c521a1bb" {
+		t.Fatalf("unexpected internal message hash %s", hash)
+	}
+	messages := []OutMessage{{Mode: sendModePayFeesSeparately, Message: msg}}
+	payloadV4, err := walletV4.SigningPayload(0, 1700000000, messages)
+	if hash := hashOf(t, payloadV4, err); hash != "e40c5fc7bcfd0b0c81980e692af464c9f441183a15af0b64ad2ae1cf60dcd02a" {
+		t.Fatalf("unexpected v4r2 payload hash %s", hash)
+	}
+	payloadV5, err := walletV5.SigningPayload(0, 1700000000, messages)
+	if hash := hashOf(t, payloadV5, err); hash != "b986ecd9f7a1b8ddebc1323b596bcb08730490af71d3de79c8f64fbf6fb6ec3c" {
+		t.Fatalf("unexpected v5r1 payload hash %s", hash)
+	}
+	jetton, err := jettonTransferBody(0, big.NewInt(5_000_000), dest, mustAddress(t, testWalletV4), big.NewInt(1), comment)
+	if hash := hashOf(t, jetton, err); hash != "9fb4839aa5488ed69002175486968c5b0d8f147e4ca5f70d2694cf50a268fa2f" {
+		t.Fatalf("unexpected jetton transfer hash %s", hash)
+	}
+	transfer, err := parseJettonTransfer(jetton)
+	if err != nil || transfer.Amount.Int64() != 5_000_000 || !transfer.Destination.Equal(dest) || transfer.Comment != "hello" {
+		t.Fatalf("unexpected jetton transfer %+v %v", transfer, err)
+	}
+
+	// 签名后的 v4r2 部署外部消息
+	signature := ed25519.Sign(key, payloadV4.Hash())
+	body, _ := walletV4.SignedBody(payloadV4, signature)
+	stateInit, _ := walletV4.StateInit()
+	walletAddress, _ := walletV4.Address()
+	ext, err := externalMessage(walletAddress, stateInit, body)
+	if hash := hashOf(t, ext, err); hash != "efc32d5b6e6058fb22338db80a4f20fe805e658ac80ce37979ae54f4f098ec60" {
+		t.Fatalf("unexpected external message hash %s", hash)
+	}
+	normalized, _ := normalizedMessageHash(walletAddress, body)
+	if hex.EncodeToString(normalized) != "40554f4c6d636318dec88f31adbf05dd23547b1ccf7d1fbc6675c314073396fb" {
+		t.Fatalf("unexpected normalized hash %x", normalized)
+	}
+	// cell 的排列顺序可以不同，比较根 cell 哈希
+	expected, _ := hex.DecodeString(testExternalBoc)
+	parsed, err := ParseBocRoot(expected)
+	if err != nil || !bytes.Equal(parsed.Hash(), ext.Hash()) {
+		t.Fatalf("unexpected boc root: %v", err)
+	}
+	parsed, err = ParseBocRoot(SerializeBoc(ext))
+	if err != nil || !bytes.Equal(parsed.Hash(), ext.Hash()) {
+		t.Fatalf("expected boc to round trip: %v", err)
+	}
+	extHash, err := externalMessageHash(expected)
+	if err != nil || !bytes.Equal(extHash, normalized) {
+		t.Fatalf("unexpected external message hash %x %v", extHash, err)
+	}
+}
+
+func buildUnSigned(t *testing.T, adaptor *ChainAdaptor, transferTx TonTransferTx) *account.UnSignTransactionResponse {
+	txJson, _ := json.Marshal(transferTx)
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	return resp
+}
+
+func buildSigned(t *testing.T, adaptor *ChainAdaptor, unSigned *account.UnSignTransactionResponse) (*account.SignedTransactionResponse, *Cell) {
+	hash, _ := hex.DecodeString(unSigned.SignHashes[0])
+	signature := hex.EncodeToString(ed25519.Sign(testKey(), hash))
+	resp, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signature: signature})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", resp.Msg)
+	}
+	boc, _ := base64.StdEncoding.DecodeString(resp.SignedTx)
+	msg, err := ParseBocRoot(boc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, msg
+}
+
+func Test_BuildTonTransfer(t *testing.T) {
+	node := &fakeTonNode{states: map[string]string{}}
+	adaptor := &ChainAdaptor{TonClient: node}
+
+	// 钱包未部署：seqno 为 0，外部消息附带 StateInit
+	unSigned := buildUnSigned(t, adaptor, TonTransferTx{
+		FromAddress: testWalletV4, ToAddress: testWalletV5Non, Amount: "1000000000", Comment: "hello", PublicKey: testPubKey(),
+	})
+	resp, msg := buildSigned(t, adaptor, unSigned)
+	dest, stateInit, body, err := parseExternalMessage(msg)
+	if err != nil || !dest.Equal(mustAddress(t, testWalletV4)) || stateInit == nil {
+		t.Fatalf("unexpected external message %v", err)
+	}
+	walletV4, _ := NewWallet(WalletV4R2, testKey().Public().(ed25519.PublicKey), false)
+	expectedInit, _ := walletV4.StateInit()
+	if !bytes.Equal(stateInit.Hash(), expectedInit.Hash()) {
+		t.Fatal("unexpected state init")
+	}
+	// v4r2 消息体：签名 + wallet_id + valid_until + seqno + op + mode + ^message
+	s := body.BeginParse()
+	signature := s.LoadBytes(ed25519.SignatureSize)
+	payload, _ := s.ToCell()
+	if !ed25519.Verify(testKey().Public().(ed25519.PublicKey), payload.Hash(), signature) {
+		t.Fatal("invalid signature in body")
+	}
+	_, _, seqno, _ := walletV4.parsePayload(payload)
+	internal := payload.Refs()[0].BeginParse()
+	internal.LoadUint(2)
+	bounce := internal.LoadBit()
+	internal.LoadBit()
+	internal.LoadAddress()
+	to := internal.LoadAddress()
+	amount := internal.LoadCoins()
+	if seqno != 0 || bounce || !to.Equal(mustAddress(t, testWalletV5)) || amount.Int64() != 1_000_000_000 {
+		t.Fatalf("unexpected internal message seqno %d bounce %v amount %s", seqno, bounce, amount)
+	}
+	if text, ok := parseComment(payload.Refs()[0].Refs()[0]); !ok || text != "hello" {
+		t.Fatalf("unexpected comment %q", text)
+	}
+	normalized, _ := normalizedMessageHash(dest, body)
+	if resp.Msg != hex.EncodeToString(normalized) {
+		t.Fatal("expected msg to be normalized message hash")
+	}
+
+	// 错误的签名被拒绝
+	hash, _ := hex.DecodeString(unSigned.SignHashes[0])
+	wrongKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{2}, ed25519.SeedSize))
+	wrong, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx: unSigned.UnSignTx, Signature: hex.EncodeToString(ed25519.Sign(wrongKey, hash)),
+	})
+	if wrong.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected wrong signature to be rejected")
+	}
+
+	// 钱包已部署：使用节点返回的 seqno，不附带 StateInit
+	node.states[mustAddress(t, testWalletV5).Raw()] = AccountStateActive
+	node.seqno = 7
+	unSigned = buildUnSigned(t, adaptor, TonTransferTx{
+		FromAddress: testWalletV5, ToAddress: testWalletV4, Amount: "1", PublicKey: testPubKey(), WalletVersion: WalletV5R1,
+	})
+	_, msg = buildSigned(t, adaptor, unSigned)
+	_, stateInit, body, _ = parseExternalMessage(msg)
+	walletV5, _ := NewWallet(WalletV5R1, testKey().Public().(ed25519.PublicKey), false)
+	walletId, _, seqno, err := walletV5.parsePayload(body)
+	if err != nil || stateInit != nil || seqno != 7 || walletId != walletV5.WalletId {
+		t.Fatalf("unexpected v5r1 message seqno %d %v", seqno, err)
+	}
+
+	// 手续费估算使用全 0 签名
+	fee, _ := adaptor.GetFee(&account.FeeRequest{RawTx: unSigned.UnSignTx})
+	if fee.Code != global_const.ReturnCode_SUCCESS || fee.NormalFee != "4001" {
+		t.Fatalf("unexpected fee %s %s", fee.NormalFee, fee.Msg)
+	}
+
+	// 公钥与发送方地址不匹配
+	txJson, _ := json.Marshal(TonTransferTx{FromAddress: testWalletV5, ToAddress: testWalletV4, Amount: "1", PublicKey: testPubKey()})
+	mismatch, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if mismatch.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected from address mismatch to be rejected")
+	}
+}
+
+func Test_BuildJettonTransfer(t *testing.T) {
+	jettonWallet := &Address{Hash: [32]byte{1, 2, 3}}
+	node := &fakeTonNode{
+		states:       map[string]string{mustAddress(t, testWalletV4).Raw(): AccountStateActive},
+		seqno:        3,
+		jettonWallet: jettonWallet,
+	}
+	adaptor := &ChainAdaptor{TonClient: node}
+	unSigned := buildUnSigned(t, adaptor, TonTransferTx{
+		FromAddress: testWalletV4, ToAddress: testWalletV5Non, Amount: "5000000", ContractAddress: usdtMaster,
+		Comment: "order-1", PublicKey: testPubKey(),
+	})
+	_, msg := buildSigned(t, adaptor, unSigned)
+	_, _, body, _ := parseExternalMessage(msg)
+
+	// 内部消息发给发送方的 jetton 钱包，附带默认的 TON
+	internal := body.Refs()[0].BeginParse()
+	internal.LoadUint(2)
+	bounce := internal.LoadBit()
+	internal.LoadBit()
+	internal.LoadAddress()
+	to := internal.LoadAddress()
+	amount := internal.LoadCoins()
+	if !bounce || !to.Equal(jettonWallet) || amount.Int64() != defaultJettonTonAmount {
+		t.Fatalf("unexpected internal message bounce %v amount %s", bounce, amount)
+	}
+	transfer, err := parseJettonTransfer(body.Refs()[0].Refs()[0])
+	if err != nil || transfer.Amount.Int64() != 5_000_000 || !transfer.Destination.Equal(mustAddress(t, testWalletV5)) || transfer.Comment != "order-1" {
+		t.Fatalf("unexpected jetton transfer %+v %v", transfer, err)
+	}
+}
@@ -0,0 +1,385 @@
+package ton
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// masterchain 的工作链和分片
+const (
+	masterchainWorkchain = -1
+	masterchainShard     = "-9223372036854775808"
+)
+
+// 账户状态
+const (
+	AccountStateActive        = "active"
+	AccountStateUninitialized = "uninitialized"
+	AccountStateFrozen        = "frozen"
+)
+
+type BlockId struct {
+	Workchain int32  `json:"workchain"`
+	Shard     string `json:"shard"`
+	Seqno     uint64 `json:"seqno"`
+	RootHash  string `json:"root_hash"`
+	FileHash  string `json:"file_hash"`
+}
+
+type MasterchainInfo struct {
+	Last BlockId `json:"last"`
+}
+
+type BlockHeader struct {
+	Id         BlockId   `json:"id"`
+	GenUtime   uint64    `json:"gen_utime"`
+	StartLt    string    `json:"start_lt"`
+	EndLt      string    `json:"end_lt"`
+	PrevBlocks []BlockId `json:"prev_blocks"`
+}
+
+type AddressInformation struct {
+	Balance string `json:"balance"`
+	State   string `json:"state"`
+}
+
+// get 方法的返回值，stack 中每一项为 [类型, 值]
+type RunGetMethodResult struct {
+	GasUsed  int64             `json:"gas_used"`
+	ExitCode int               `json:"exit_code"`
+	Stack    []json.RawMessage `json:"stack"`
+}
+
+type Fees struct {
+	InFwdFee   int64 `json:"in_fwd_fee"`
+	StorageFee int64 `json:"storage_fee"`
+	GasFee     int64 `json:"gas_fee"`
+	FwdFee     int64 `json:"fwd_fee"`
+}
+
+func (f *Fees) Total() int64 {
+	return f.InFwdFee + f.StorageFee + f.GasFee + f.FwdFee
+}
+
+type EstimateFeeResult struct {
+	SourceFees      Fees   `json:"source_fees"`
+	DestinationFees []Fees `json:"destination_fees"`
+}
+
+// v3 索引接口返回的消息
+type Message struct {
+	Hash           string  `json:"hash"`
+	Source         *string `json:"source"`
+	Destination    *string `json:"destination"`
+	Value          *string `json:"value"`
+	Opcode         *string `json:"opcode"`
+	Bounce         *bool   `json:"bounce"`
+	MessageContent *struct {
+		Body string `json:"body"`
+	} `json:"message_content"`
+}
+
+// v3 索引接口返回的交易
+type Transaction struct {
+	Account      string     `json:"account"`
+	Hash         string     `json:"hash"`
+	Lt           string     `json:"lt"`
+	Now          int64      `json:"now"`
+	McBlockSeqno *uint64    `json:"mc_block_seqno"`
+	TotalFees    string     `json:"total_fees"`
+	InMsg        *Message   `json:"in_msg"`
+	OutMsgs      []*Message `json:"out_msgs"`
+	Description  struct {
+		Aborted   bool `json:"aborted"`
+		ComputePh struct {
+			Skipped  bool `json:"skipped"`
+			Success  bool `json:"success"`
+			ExitCode int  `json:"exit_code"`
+		} `json:"compute_ph"`
+		Action *struct {
+			Success    bool `json:"success"`
+			ResultCode int  `json:"result_code"`
+		} `json:"action"`
+	} `json:"description"`
+}
+
+type TransactionList struct {
+	Transactions []Transaction `json:"transactions"`
+	AddressBook  map[string]struct {
+		UserFriendly string `json:"user_friendly"`
+	} `json:"address_book"`
+}
+
+type ITon interface {
+	GetMasterchainInfo() (*MasterchainInfo, error)
+	GetBlockHeader(seqno uint64) (*BlockHeader, error)
+	GetAddressInformation(address string) (*AddressInformation, error)
+	RunGetMethod(address, method string, stack [][]string) (*RunGetMethodResult, error)
+	EstimateFee(address string, body, initCode, initData []byte) (*EstimateFeeResult, error)
+	SendBoc(boc []byte) error
+	GetTransactions(hash, lt string) (*TransactionList, error)
+	GetTransactionsByMessage(msgHash string) (*TransactionList, error)
+	GetAccountTransactions(address string, limit, offset uint32) (*TransactionList, error)
+}
+
+// toncenter 客户端：url 为 v2 接口（节点查询、get 方法、广播），indexUrl 为 v3 索引接口（交易查询），
+// apiKey 通过 X-API-Key 请求头传入
+type TonClient struct {
+	url      string
+	indexUrl string
+	apiKey   string
+	client   *http.Client
+}
+
+func NewTonClient(rpcUrl, indexUrl, apiKey string, timeout time.Duration) (ITon, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &TonClient{
+		url:      strings.TrimSuffix(rpcUrl, "/"),
+		indexUrl: strings.TrimSuffix(indexUrl, "/"),
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (t *TonClient) do(method, rawUrl string, params any) ([]byte, error) {
+	var body io.Reader
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, rawUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	if params != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if t.apiKey != "" {
+		req.Header.Set("X-API-Key", t.apiKey)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// 调用 v2 接口，返回 {"ok": bool, "result": ..., "error": "...", "code": int}
+func (t *TonClient) call(method, path string, query url.Values, params any, result any) error {
+	rawUrl := t.url + path
+	if len(query) > 0 {
+		rawUrl += "?" + query.Encode()
+	}
+	respBody, err := t.do(method, rawUrl, params)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Ok     bool            `json:"ok"`
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+		Code   int             `json:"code"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("call %s fail: %s", path, string(respBody))
+	}
+	if !resp.Ok {
+		return fmt.Errorf("call %s fail, code %d: %s", path, resp.Code, resp.Error)
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// 调用 v3 索引接口，出错时返回 {"error": "..."}
+func (t *TonClient) index(path string, query url.Values, result any) error {
+	if t.indexUrl == "" {
+		return errors.New("index api url is not configured")
+	}
+	respBody, err := t.do(http.MethodGet, t.indexUrl+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	var indexErr struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &indexErr); err == nil && indexErr.Error != "" {
+		return fmt.Errorf("call %s fail: %s", path, indexErr.Error)
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// 获取最新的 masterchain 区块
+func (t *TonClient) GetMasterchainInfo() (*MasterchainInfo, error) {
+	info := new(MasterchainInfo)
+	if err := t.call(http.MethodGet, "/getMasterchainInfo", nil, nil, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// 获取 masterchain 区块头
+func (t *TonClient) GetBlockHeader(seqno uint64) (*BlockHeader, error) {
+	query := url.Values{
+		"workchain": {strconv.Itoa(masterchainWorkchain)},
+		"shard":     {masterchainShard},
+		"seqno":     {strconv.FormatUint(seqno, 10)},
+	}
+	header := new(BlockHeader)
+	if err := t.call(http.MethodGet, "/getBlockHeader", query, nil, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// 获取账户余额和状态，不存在的账户返回 uninitialized
+func (t *TonClient) GetAddressInformation(address string) (*AddressInformation, error) {
+	info := new(AddressInformation)
+	if err := t.call(http.MethodGet, "/getAddressInformation", url.Values{"address": {address}}, nil, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// 调用合约的 get 方法，exit_code 非 0 时返回错误
+func (t *TonClient) RunGetMethod(address, method string, stack [][]string) (*RunGetMethodResult, error) {
+	if stack == nil {
+		stack = [][]string{}
+	}
+	params := map[string]any{"address": address, "method": method, "stack": stack}
+	result := new(RunGetMethodResult)
+	if err := t.call(http.MethodPost, "/runGetMethod", nil, params, result); err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 && result.ExitCode != 1 {
+		return nil, fmt.Errorf("run get method %s fail, exit code %d", method, result.ExitCode)
+	}
+	return result, nil
+}
+
+// 估算外部消息的手续费，不校验签名
+func (t *TonClient) EstimateFee(address string, body, initCode, initData []byte) (*EstimateFeeResult, error) {
+	params := map[string]any{
+		"address":       address,
+		"body":          base64.StdEncoding.EncodeToString(body),
+		"ignore_chksig": true,
+	}
+	if initCode != nil {
+		params["init_code"] = base64.StdEncoding.EncodeToString(initCode)
+		params["init_data"] = base64.StdEncoding.EncodeToString(initData)
+	}
+	result := new(EstimateFeeResult)
+	if err := t.call(http.MethodPost, "/estimateFee", nil, params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 广播外部消息
+func (t *TonClient) SendBoc(boc []byte) error {
+	var result json.RawMessage
+	return t.call(http.MethodPost, "/sendBoc", nil, map[string]string{"boc": base64.StdEncoding.EncodeToString(boc)}, &result)
+}
+
+// 按交易哈希查询，lt 不为空时同时按 lt 过滤
+func (t *TonClient) GetTransactions(hash, lt string) (*TransactionList, error) {
+	query := url.Values{"hash": {hash}, "limit": {"1"}}
+	if lt != "" {
+		query.Set("lt", lt)
+	}
+	list := new(TransactionList)
+	if err := t.index("/transactions", query, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// 按外部消息哈希查询其触发的交易，支持 TEP-467 规范化哈希
+func (t *TonClient) GetTransactionsByMessage(msgHash string) (*TransactionList, error) {
+	query := url.Values{"msg_hash": {msgHash}, "direction": {"in"}, "limit": {"1"}}
+	list := new(TransactionList)
+	if err := t.index("/transactionsByMessage", query, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// 按账户查询交易，按 lt 倒序
+func (t *TonClient) GetAccountTransactions(address string, limit, offset uint32) (*TransactionList, error) {
+	query := url.Values{
+		"account": {address},
+		"limit":   {strconv.FormatUint(uint64(limit), 10)},
+		"offset":  {strconv.FormatUint(uint64(offset), 10)},
+		"sort":    {"desc"},
+	}
+	list := new(TransactionList)
+	if err := t.index("/transactions", query, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// 解析 get 方法返回的整数：["num", "0x..."]
+func stackNum(entry json.RawMessage) (*big.Int, error) {
+	var item []json.RawMessage
+	if err := json.Unmarshal(entry, &item); err != nil || len(item) != 2 {
+		return nil, errors.New("invalid stack entry")
+	}
+	var kind, value string
+	if json.Unmarshal(item[0], &kind) != nil || kind != "num" || json.Unmarshal(item[1], &value) != nil {
+		return nil, fmt.Errorf("expected num stack entry, got %s", string(entry))
+	}
+	negative := strings.HasPrefix(value, "-")
+	number, ok := new(big.Int).SetString(strings.TrimPrefix(strings.TrimPrefix(value, "-"), "0x"), 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid num %s", value)
+	}
+	if negative {
+		number.Neg(number)
+	}
+	return number, nil
+}
+
+// 解析 get 方法返回的 cell 或 slice：["cell", {"bytes": base64 BOC}]
+func stackCell(entry json.RawMessage) (*Cell, error) {
+	var item []json.RawMessage
+	if err := json.Unmarshal(entry, &item); err != nil || len(item) != 2 {
+		return nil, errors.New("invalid stack entry")
+	}
+	var kind string
+	var value struct {
+		Bytes string `json:"bytes"`
+	}
+	if json.Unmarshal(item[0], &kind) != nil || (kind != "cell" && kind != "slice") || json.Unmarshal(item[1], &value) != nil {
+		return nil, fmt.Errorf("expected cell stack entry, got %s", string(entry))
+	}
+	boc, err := base64.StdEncoding.DecodeString(value.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBocRoot(boc)
+}
+
+// get 方法的 slice 参数
+func sliceArg(c *Cell) []string {
+	return []string{"tvm.Slice", base64.StdEncoding.EncodeToString(SerializeBoc(c))}
+}
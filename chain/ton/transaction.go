@@ -0,0 +1,279 @@
+package ton
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 外部消息的有效期，需要在过期前完成签名和广播
+const messageTtl = 10 * time.Minute
+
+// jetton 转账默认附带给 jetton 钱包的 TON（0.05 TON），用于支付转发和接收方 jetton 钱包的手续费，多余部分退回
+const defaultJettonTonAmount = 50_000_000
+
+// jetton 转账默认转给接收方的 TON（1 nanoton），大于 0 时接收方会收到 transfer_notification
+const defaultForwardAmount = 1
+
+// 构建未签名交易：un_sign_tx 为 base64 编码的 UnsignedMessage，sign_hashes 为待签名消息体的 cell 哈希。
+// 钱包未部署时 seqno 为 0，签名后的外部消息附带 StateInit 部署钱包
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx TonTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	unsigned, payload, err := c.buildTransfer(&transferTx)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	unsignedJson, _ := json.Marshal(unsigned)
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   base64.StdEncoding.EncodeToString(unsignedJson),
+		SignHashes: []string{hex.EncodeToString(payload.Hash())},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 un_sign_tx，signature 为 64 字节 ed25519 签名。
+// 返回的 signed_tx 为外部消息的 base64 BOC，msg 为外部消息的规范化哈希
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	unsigned, wallet, payload, err := c.decodeUnsignedMessage(req.Base64Tx)
+	if err != nil {
+		log.Error("decode unsigned message fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	walletId, validUntil, _, err := wallet.parsePayload(payload)
+	if err != nil || walletId != wallet.WalletId {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid payload",
+		}, nil
+	}
+	if int64(validUntil) < time.Now().Unix() {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "transaction expired",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	if !ed25519.Verify(wallet.PublicKey, payload.Hash(), signature) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "signature verification failed",
+		}, nil
+	}
+
+	address, err := wallet.Address()
+	if err != nil {
+		log.Error("get wallet address fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "build signed transaction fail",
+		}, nil
+	}
+	body, err := wallet.SignedBody(payload, signature)
+	if err != nil {
+		log.Error("build message body fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "build signed transaction fail",
+		}, nil
+	}
+	var stateInit *Cell
+	if unsigned.Deploy {
+		if stateInit, err = wallet.StateInit(); err != nil {
+			log.Error("build state init fail", "err", err)
+			return &account.SignedTransactionResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "build signed transaction fail",
+			}, nil
+		}
+	}
+	msg, err := externalMessage(address, stateInit, body)
+	if err != nil {
+		log.Error("build external message fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "build signed transaction fail",
+		}, nil
+	}
+	msgHash, _ := normalizedMessageHash(address, body)
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      hex.EncodeToString(msgHash),
+		SignedTx: base64.StdEncoding.EncodeToString(SerializeBoc(msg)),
+	}, nil
+}
+
+// 校验发送方地址并查询钱包状态，构建待签名消息体
+func (c *ChainAdaptor) buildTransfer(transferTx *TonTransferTx) (*UnsignedMessage, *Cell, error) {
+	wallet, err := c.newWallet(transferTx.WalletVersion, transferTx.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	from, err := ParseAddress(transferTx.FromAddress)
+	if err != nil {
+		return nil, nil, errors.New("invalid from address")
+	}
+	walletAddress, err := wallet.Address()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !from.Equal(walletAddress) {
+		return nil, nil, errors.New("from address does not match public key and wallet version")
+	}
+	to, err := ParseAddress(transferTx.ToAddress)
+	if err != nil {
+		return nil, nil, errors.New("invalid to address")
+	}
+	amount, ok := new(big.Int).SetString(transferTx.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, nil, errors.New("invalid amount")
+	}
+
+	info, err := c.TonClient.GetAddressInformation(walletAddress.Raw())
+	if err != nil {
+		return nil, nil, fmt.Errorf("get address information fail: %w", err)
+	}
+	var seqno uint32
+	deploy := false
+	switch info.State {
+	case AccountStateActive:
+		if transferTx.Seqno != nil {
+			seqno = *transferTx.Seqno
+		} else if seqno, err = c.getSeqno(walletAddress); err != nil {
+			return nil, nil, fmt.Errorf("get seqno fail: %w", err)
+		}
+	case AccountStateUninitialized:
+		deploy = true
+	default:
+		return nil, nil, fmt.Errorf("wallet is %s", info.State)
+	}
+
+	var comment *Cell
+	if transferTx.Comment != "" {
+		if comment, err = commentBody(transferTx.Comment); err != nil {
+			return nil, nil, err
+		}
+	}
+	var msg *Cell
+	if transferTx.ContractAddress == "" {
+		// raw 格式的地址没有 bounceable 标志，按 non-bounceable 处理，避免转给未部署的钱包时被退回
+		bounce := to.Friendly && to.Bounceable
+		if msg, err = internalMessage(to.Address, bounce, amount, comment); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		master, err := ParseAddress(transferTx.ContractAddress)
+		if err != nil {
+			return nil, nil, errors.New("invalid contract address")
+		}
+		tonAmount, err := parseNanoton(transferTx.JettonTonAmount, defaultJettonTonAmount)
+		if err != nil {
+			return nil, nil, errors.New("invalid jetton ton amount")
+		}
+		forwardAmount, err := parseNanoton(transferTx.ForwardAmount, defaultForwardAmount)
+		if err != nil || forwardAmount.Cmp(tonAmount) >= 0 {
+			return nil, nil, errors.New("invalid forward amount")
+		}
+		jettonWallet, err := c.getJettonWallet(master.Address, walletAddress)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get jetton wallet fail: %w", err)
+		}
+		// 多余的 TON 退回发送方
+		body, err := jettonTransferBody(0, amount, to.Address, walletAddress, forwardAmount, comment)
+		if err != nil {
+			return nil, nil, err
+		}
+		if msg, err = internalMessage(jettonWallet, true, tonAmount, body); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	validUntil := uint32(time.Now().Add(messageTtl).Unix())
+	payload, err := wallet.SigningPayload(seqno, validUntil, []OutMessage{{Mode: sendModePayFeesSeparately, Message: msg}})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &UnsignedMessage{
+		WalletVersion: wallet.Version,
+		PublicKey:     hex.EncodeToString(wallet.PublicKey),
+		Seqno:         seqno,
+		Deploy:        deploy,
+		Payload:       base64.StdEncoding.EncodeToString(SerializeBoc(payload)),
+	}, payload, nil
+}
+
+// 解析 BuildUnSignTransaction 返回的 un_sign_tx
+func (c *ChainAdaptor) decodeUnsignedMessage(unSignTx string) (*UnsignedMessage, *Wallet, *Cell, error) {
+	unsignedJson, err := base64.StdEncoding.DecodeString(unSignTx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var unsigned UnsignedMessage
+	if err := json.Unmarshal(unsignedJson, &unsigned); err != nil {
+		return nil, nil, nil, err
+	}
+	wallet, err := c.newWallet(unsigned.WalletVersion, unsigned.PublicKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	boc, err := base64.StdEncoding.DecodeString(unsigned.Payload)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	payload, err := ParseBocRoot(boc)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &unsigned, wallet, payload, nil
+}
+
+// 解析 nanoton 数量，为空时使用默认值
+func parseNanoton(value string, defaultValue int64) (*big.Int, error) {
+	if value == "" {
+		return big.NewInt(defaultValue), nil
+	}
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %s", value)
+	}
+	return amount, nil
+}
@@ -0,0 +1,45 @@
+package ton
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type TonTransferTx struct {
+	// 发送方钱包地址，需与 public_key 和 wallet_version 推导出的地址一致
+	FromAddress string `json:"from_address"`
+	// 接收方地址，user-friendly 格式按地址的 bounceable 标志设置 bounce，raw 格式不 bounce
+	ToAddress string `json:"to_address"`
+	// TON 单位 nanoton，jetton 为代币最小单位
+	Amount string `json:"amount"`
+	// jetton master 地址，为空时转账 TON
+	ContractAddress string `json:"contract_address"`
+	// 文本备注，jetton 转账放在 forward_payload 中
+	Comment string `json:"comment"`
+	// 十六进制 ed25519 公钥
+	PublicKey string `json:"public_key"`
+	// 钱包合约版本 v4r2、v5r1，为空时使用 v4r2
+	WalletVersion string `json:"wallet_version"`
+	// 为空时通过节点获取
+	Seqno *uint32 `json:"seqno"`
+	// jetton 转账时转给接收方的 TON（nanoton），触发 transfer_notification，为空时为 1
+	ForwardAmount string `json:"forward_amount"`
+	// jetton 转账时附带给 jetton 钱包的 TON（nanoton），多余部分退回发送方，为空时为 defaultJettonTonAmount
+	JettonTonAmount string `json:"jetton_ton_amount"`
+}
+
+// BuildUnSignTransaction 返回的 un_sign_tx（JSON 后 base64 编码），BuildSignedTransaction 据此组装外部消息
+type UnsignedMessage struct {
+	WalletVersion string `json:"wallet_version"`
+	PublicKey     string `json:"public_key"`
+	Seqno         uint32 `json:"seqno"`
+	// 钱包未部署时随外部消息附带 StateInit
+	Deploy bool `json:"deploy"`
+	// 待签名消息体的 BOC（base64）
+	Payload string `json:"payload"`
+}
+
+// 交易中解析出的 TON 或 jetton 转账
+type Transfer struct {
+	ContractAddress string `json:"contract_address"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Amount          string `json:"amount"`
+	Comment         string `json:"comment"`
+}
@@ -0,0 +1,175 @@
+package ton
+
+import (
+	"crypto/ed25519"
+	"fmt"
+)
+
+// 钱包合约版本
+const (
+	WalletV4R2 = "v4r2"
+	WalletV5R1 = "v5r1"
+)
+
+// v4r2 的默认 subwallet_id，v5r1 的 wallet_id 由网络、工作链和 subwallet 编号计算
+const defaultSubwalletV4 = 698983191
+
+// 网络的 global_id，参与 v5r1 wallet_id 的计算
+const (
+	mainnetGlobalId = -239
+	testnetGlobalId = -3
+)
+
+// v5r1 外部消息的签名操作码 "sign" 和发送消息动作 action_send_msg
+const (
+	v5OpSign        = 0x7369676e
+	v5ActionSendMsg = 0x0ec3c86d
+)
+
+// 单个外部消息可以发出的内部消息数
+const (
+	maxV4Messages = 4
+	maxV5Messages = 255
+)
+
+const walletWorkchain = 0
+
+// 钱包合约代码（BOC）
+const (
+	walletCodeV4R2Hex = "b5ee9c72410214010002d4000114ff00f4a413f4bcf2c80b010201200203020148040504f8f28308d71820d31fd31fd31f02f823bbf264ed44d0d31fd31fd3fff404d15143baf2a15151baf2a205f901541064f910f2a3f80024a4c8cb1f5240cb1f5230cbff5210f400c9ed54f80f01d30721c0009f6c519320d74a96d307d402fb00e830e021c001e30021c002e30001c0039130e30d03a4c8cb1f12cb1fcbff1011121302e6d001d0d3032171b0925f04e022d749c120925f04e002d31f218210706c7567bd22821064737472bdb0925f05e003fa403020fa4401c8ca07cbffc9d0ed44d0810140d721f404305c810108f40a6fa131b3925f07e005d33fc8258210706c7567ba923830e30d03821064737472ba925f06e30d06070201200809007801fa00f40430f8276f2230500aa121bef2e0508210706c7567831eb17080185004cb0526cf1658fa0219f400cb6917cb1f5260cb3f20c98040fb0006008a5004810108f45930ed44d0810140d720c801cf16f400c9ed540172b08e23821064737472831eb17080185005cb055003cf1623fa0213cb6acb1fcb3fc98040fb00925f03e20201200a0b0059bd242b6f6a2684080a06b90fa0218470d4080847a4937d29910ce6903e9ff9837812801b7810148987159f31840201580c0d0011b8c97ed44d0d70b1f8003db29dfb513420405035c87d010c00b23281f2fff274006040423d029be84c600201200e0f0019adce76a26840206b90eb85ffc00019af1df6a26840106b90eb858fc0006ed207fa00d4d422f90005c8ca0715cbffc9d077748018c8cb05cb0222cf165005fa0214cb6b12ccccc973fb00c84014810108f451f2a7020070810108d718fa00d33fc8542047810108f451f2a782106e6f746570748018c8cb05cb025006cf165004fa0214cb6a12cb1fcb3fc973fb0002006c810108d718fa00d33f305224810108f459f2a782106473747270748018c8cb05cb025005cf165003fa0213cb6acb1f12cb3fc973fb00000af400c9ed54696225e5"
+	walletCodeV5R1Hex = "b5ee9c7241021401000281000114ff00f4a413f4bcf2c80b01020120020d020148030402dcd020d749c120915b8f6320d70b1f2082106578746ebd21821073696e74bdb0925f03e082106578746eba8eb48020d72101d074d721fa4030fa44f828fa443058bd915be0ed44d0810141d721f4058307f40e6fa1319130e18040d721707fdb3ce03120d749810280b99130e070e2100f020120050c020120060902016e07080019adce76a2684020eb90eb85ffc00019af1df6a2684010eb90eb858fc00201480a0b0017b325fb51341c75c875c2c7e00011b262fb513435c280200019be5f0f6a2684080a0eb90fa02c0102f20e011e20d70b1f82107369676ebaf2e08a7f0f01e68ef0eda2edfb218308d722028308d723208020d721d31fd31fd31fed44d0d200d31f20d31fd3ffd70a000af90140ccf9109a28945f0adb31e1f2c087df02b35007b0f2d0845125baf2e0855036baf2e086f823bbf2d0882292f800de01a47fc8ca00cb1f01cf16c9ed542092f80fde70db3cd81003f6eda2edfb02f404216e926c218e4c0221d73930709421c700b38e2d01d72820761e436c20d749c008f2e09320d74ac002f2e09320d71d06c712c2005230b0f2d089d74cd7393001a4e86c128407bbf2e093d74ac000f2e093ed55e2d20001c000915be0ebd72c08142091709601d72c081c12e25210b1e30f20d74a111213009601fa4001fa44f828fa443058baf2e091ed44d0810141d718f405049d7fc8ca0040048307f453f2e08b8e14038307f45bf2e08c22d70a00216e01b3b0f2d090e2c85003cf1612f400c9ed54007230d72c08248e2d21f2e092d200ed44d0d2005113baf2d08f54503091319c01810140d721d70a00f2e08ee2c8ca0058cf16c9ed5493f2c08de20010935bdb31e1d74cd0b4d6c35e"
+)
+
+var walletCodes = map[string]*Cell{
+	WalletV4R2: mustParseBocHex(walletCodeV4R2Hex),
+	WalletV5R1: mustParseBocHex(walletCodeV5R1Hex),
+}
+
+// 钱包合约，地址由合约代码和初始数据（公钥、wallet_id）决定
+type Wallet struct {
+	Version   string
+	PublicKey ed25519.PublicKey
+	WalletId  uint32
+}
+
+// 按版本创建钱包，version 为空时使用 v4r2
+func NewWallet(version string, pubKey ed25519.PublicKey, testnet bool) (*Wallet, error) {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key length %d", len(pubKey))
+	}
+	wallet := &Wallet{Version: version, PublicKey: pubKey}
+	switch version {
+	case WalletV4R2, "":
+		wallet.Version = WalletV4R2
+		wallet.WalletId = defaultSubwalletV4 + walletWorkchain
+	case WalletV5R1:
+		globalId := int32(mainnetGlobalId)
+		if testnet {
+			globalId = testnetGlobalId
+		}
+		wallet.WalletId = v5WalletId(globalId, walletWorkchain, 0)
+	default:
+		return nil, fmt.Errorf("unsupported wallet version %s", version)
+	}
+	return wallet, nil
+}
+
+// v5r1 的 wallet_id：network_global_id 异或 上下文（客户端标志 1 位、工作链 8 位、版本 8 位、subwallet 15 位）
+func v5WalletId(globalId int32, workchain int8, subwallet uint16) uint32 {
+	context := uint32(1)<<31 | uint32(uint8(workchain))<<23 | uint32(subwallet)
+	return context ^ uint32(globalId)
+}
+
+// 合约初始数据，seqno 为 0，插件/扩展字典为空
+func (w *Wallet) data() (*Cell, error) {
+	b := BeginCell()
+	if w.Version == WalletV5R1 {
+		b.StoreBit(true) // 允许签名
+	}
+	b.StoreUint(0, 32).StoreUint(uint64(w.WalletId), 32)
+	return b.StoreBytes(w.PublicKey).StoreBit(false).EndCell()
+}
+
+// StateInit：split_depth、special 为空，包含 code 和 data，无 library
+func (w *Wallet) StateInit() (*Cell, error) {
+	data, err := w.data()
+	if err != nil {
+		return nil, err
+	}
+	return BeginCell().StoreUint(0b00110, 5).StoreRef(walletCodes[w.Version]).StoreRef(data).EndCell()
+}
+
+// 合约地址为 StateInit 的哈希
+func (w *Wallet) Address() (*Address, error) {
+	stateInit, err := w.StateInit()
+	if err != nil {
+		return nil, err
+	}
+	address := &Address{Workchain: walletWorkchain}
+	copy(address.Hash[:], stateInit.Hash())
+	return address, nil
+}
+
+// 待发送的内部消息及发送模式
+type OutMessage struct {
+	Mode    uint8
+	Message *Cell
+}
+
+// 待签名的消息体，签名对象为其 cell 哈希
+func (w *Wallet) SigningPayload(seqno, validUntil uint32, messages []OutMessage) (*Cell, error) {
+	switch w.Version {
+	case WalletV4R2:
+		if len(messages) > maxV4Messages {
+			return nil, fmt.Errorf("wallet v4r2 supports at most %d messages", maxV4Messages)
+		}
+		b := BeginCell().StoreUint(uint64(w.WalletId), 32).StoreUint(uint64(validUntil), 32).
+			StoreUint(uint64(seqno), 32).StoreUint(0, 8) // op = 0：普通转账
+		for _, message := range messages {
+			b.StoreUint(uint64(message.Mode), 8).StoreRef(message.Message)
+		}
+		return b.EndCell()
+	case WalletV5R1:
+		if len(messages) > maxV5Messages {
+			return nil, fmt.Errorf("wallet v5r1 supports at most %d messages", maxV5Messages)
+		}
+		// out_list$_ prev:^OutList action:OutAction，第一条消息在链表最内层
+		actions, err := BeginCell().EndCell()
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range messages {
+			if actions, err = BeginCell().StoreRef(actions).StoreUint(v5ActionSendMsg, 32).
+				StoreUint(uint64(message.Mode), 8).StoreRef(message.Message).EndCell(); err != nil {
+				return nil, err
+			}
+		}
+		return BeginCell().StoreUint(v5OpSign, 32).StoreUint(uint64(w.WalletId), 32).
+			StoreUint(uint64(validUntil), 32).StoreUint(uint64(seqno), 32).
+			StoreMaybeRef(actions).StoreBit(false). // 无扩展动作
+			EndCell()
+	default:
+		return nil, fmt.Errorf("unsupported wallet version %s", w.Version)
+	}
+}
+
+// 外部消息体：v4r2 签名在前，v5r1 签名在后
+func (w *Wallet) SignedBody(payload *Cell, signature []byte) (*Cell, error) {
+	if w.Version == WalletV5R1 {
+		return BeginCell().StoreCell(payload).StoreBytes(signature).EndCell()
+	}
+	return BeginCell().StoreBytes(signature).StoreCell(payload).EndCell()
+}
+
+// 解析待签名消息体的 wallet_id、valid_until 和 seqno
+func (w *Wallet) parsePayload(payload *Cell) (walletId, validUntil, seqno uint32, err error) {
+	s := payload.BeginParse()
+	if w.Version == WalletV5R1 && s.LoadUint(32) != v5OpSign {
+		return 0, 0, 0, fmt.Errorf("invalid wallet v5r1 payload")
+	}
+	walletId, validUntil, seqno = uint32(s.LoadUint(32)), uint32(s.LoadUint(32)), uint32(s.LoadUint(32))
+	if s.Err() != nil {
+		return 0, 0, 0, s.Err()
+	}
+	return walletId, validUntil, seqno, nil
+}
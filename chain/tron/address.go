@@ -0,0 +1,65 @@
+package tron
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"chain-account/common/util"
+)
+
+// 主网地址前缀，base58check 编码后以 T 开头
+const addressPrefix byte = 0x41
+
+const addressLength = 21
+
+// 由公钥生成 base58check 格式的 T 地址
+func PubKeyToAddress(pubKeyHex string) (string, error) {
+	pubKeyBytes, err := hex.DecodeString(strings.TrimPrefix(pubKeyHex, "0x"))
+	if err != nil {
+		return "", err
+	}
+	pubKey, err := util.DecompressPublicKey(pubKeyBytes)
+	if err != nil {
+		return "", err
+	}
+	fullPubKey := pubKey.SerializeUncompressed()
+	return encodeAddress(append([]byte{addressPrefix}, crypto.Keccak256(fullPubKey[1:])[12:]...)), nil
+}
+
+// 21 字节地址编码为 base58check
+func encodeAddress(address []byte) string {
+	return base58.CheckEncode(address[1:], address[0])
+}
+
+// 解析 base58check 或 41 开头的十六进制地址，返回 21 字节地址
+func decodeAddress(address string) ([]byte, error) {
+	if len(address) == addressLength*2 && strings.HasPrefix(address, "41") {
+		decoded, err := hex.DecodeString(address)
+		if err != nil {
+			return nil, err
+		}
+		return decoded, nil
+	}
+	decoded, version, err := base58.CheckDecode(address)
+	if err != nil {
+		return nil, err
+	}
+	if version != addressPrefix || len(decoded) != addressLength-1 {
+		return nil, errors.New("invalid tron address")
+	}
+	return append([]byte{version}, decoded...), nil
+}
+
+func ValidateAddress(address string) bool {
+	_, err := decodeAddress(address)
+	return err == nil
+}
+
+// 日志和 ABI 参数中的地址只有 20 字节，补上前缀后编码
+func evmAddressToBase58(address []byte) string {
+	return encodeAddress(append([]byte{addressPrefix}, address[len(address)-20:]...))
+}
@@ -0,0 +1,205 @@
+package tron
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Tron 交易的合约类型
+const (
+	transferContractType        = 1
+	triggerSmartContractType    = 31
+	transferContractTypeUrl     = "type.googleapis.com/protocol.TransferContract"
+	triggerSmartContractTypeUrl = "type.googleapis.com/protocol.TriggerSmartContract"
+)
+
+// 从 Transaction.raw 中解析出的字段，只包含转账相关的合约参数
+type rawData struct {
+	RefBlockBytes []byte
+	RefBlockHash  []byte
+	Expiration    int64
+	Timestamp     int64
+	FeeLimit      int64
+	ContractType  int32
+	Owner         []byte
+	To            []byte // TransferContract 的接收地址
+	Amount        int64  // TransferContract 的金额
+	Contract      []byte // TriggerSmartContract 的合约地址
+	CallValue     int64
+	Data          []byte
+}
+
+// 交易哈希为 raw_data 的 sha256
+func txId(raw []byte) []byte {
+	hash := sha256.Sum256(raw)
+	return hash[:]
+}
+
+// 按 protocol.Transaction 编码：raw_data 为字段 1，签名为字段 2
+func encodeSignedTransaction(raw []byte, signatures ...[]byte) []byte {
+	var buf []byte
+	buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+	buf = protowire.AppendBytes(buf, raw)
+	for _, signature := range signatures {
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, signature)
+	}
+	return buf
+}
+
+// 解析 protocol.Transaction，返回 raw_data 和签名
+func decodeSignedTransaction(b []byte) ([]byte, [][]byte, error) {
+	var raw []byte
+	var signatures [][]byte
+	err := rangeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			raw = value
+		case 2:
+			signatures = append(signatures, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if raw == nil {
+		return nil, nil, errors.New("missing raw data")
+	}
+	return raw, signatures, nil
+}
+
+// 解析 protocol.Transaction.raw，只支持单个合约的交易
+func decodeRawData(b []byte) (*rawData, error) {
+	data := &rawData{}
+	var contracts [][]byte
+	err := rangeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			data.RefBlockBytes = value
+		case num == 4 && typ == protowire.BytesType:
+			data.RefBlockHash = value
+		case num == 8 && typ == protowire.VarintType:
+			data.Expiration = int64(varint)
+		case num == 11 && typ == protowire.BytesType:
+			contracts = append(contracts, value)
+		case num == 14 && typ == protowire.VarintType:
+			data.Timestamp = int64(varint)
+		case num == 18 && typ == protowire.VarintType:
+			data.FeeLimit = int64(varint)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(contracts) != 1 {
+		return nil, fmt.Errorf("expected 1 contract, got %d", len(contracts))
+	}
+
+	// Contract: type = 1, parameter(Any) = 2
+	var parameter []byte
+	err = rangeFields(contracts[0], func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		if num == 1 && typ == protowire.VarintType {
+			data.ContractType = int32(varint)
+		}
+		if num == 2 && typ == protowire.BytesType {
+			parameter = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Any: type_url = 1, value = 2
+	var typeUrl string
+	var value []byte
+	err = rangeFields(parameter, func(num protowire.Number, typ protowire.Type, field []byte, _ uint64) error {
+		if num == 1 && typ == protowire.BytesType {
+			typeUrl = string(field)
+		}
+		if num == 2 && typ == protowire.BytesType {
+			value = field
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch data.ContractType {
+	case transferContractType:
+		if typeUrl != transferContractTypeUrl {
+			return nil, fmt.Errorf("unexpected type url %s", typeUrl)
+		}
+		err = rangeFields(value, func(num protowire.Number, typ protowire.Type, field []byte, varint uint64) error {
+			switch {
+			case num == 1 && typ == protowire.BytesType:
+				data.Owner = field
+			case num == 2 && typ == protowire.BytesType:
+				data.To = field
+			case num == 3 && typ == protowire.VarintType:
+				data.Amount = int64(varint)
+			}
+			return nil
+		})
+	case triggerSmartContractType:
+		if typeUrl != triggerSmartContractTypeUrl {
+			return nil, fmt.Errorf("unexpected type url %s", typeUrl)
+		}
+		err = rangeFields(value, func(num protowire.Number, typ protowire.Type, field []byte, varint uint64) error {
+			switch {
+			case num == 1 && typ == protowire.BytesType:
+				data.Owner = field
+			case num == 2 && typ == protowire.BytesType:
+				data.Contract = field
+			case num == 3 && typ == protowire.VarintType:
+				data.CallValue = int64(varint)
+			case num == 4 && typ == protowire.BytesType:
+				data.Data = field
+			}
+			return nil
+		})
+	default:
+		return nil, fmt.Errorf("unsupported contract type %d", data.ContractType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// 遍历 protobuf 字段，bytes 类型通过 value 返回，varint 类型通过 varint 返回
+func rangeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := fn(num, typ, value, varint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package tron
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// TRC20 转账默认的手续费上限 100 TRX
+const defaultFeeLimit = 100_000_000
+
+// 构建未签名交易：通过节点生成交易后校验内容与请求一致，un_sign_tx 为 raw_data_hex，sign_hashes 为交易哈希。
+// 节点生成的交易 60 秒后过期，需要在过期前完成签名和广播
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx TronTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	tx, err := c.buildTransaction(&transferTx)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   tx.RawDataHex,
+		SignHashes: []string{tx.TxID},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 raw_data_hex，signature 为 65 字节 r||s||v。
+// 返回的 signed_tx 为十六进制编码的 protocol.Transaction，msg 为交易哈希
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(req.Base64Tx, "0x"))
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode raw data fail",
+		}, nil
+	}
+	data, err := decodeRawData(raw)
+	if err != nil {
+		log.Error("decode raw data fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode raw data fail",
+		}, nil
+	}
+	if data.Expiration > 0 && data.Expiration < time.Now().UnixMilli() {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "transaction expired",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	signature = bytes.Clone(signature)
+	if signature[64] >= 27 {
+		signature[64] -= 27
+	}
+
+	// 校验签名地址
+	hash := txId(raw)
+	pubKey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		log.Error("recover public key fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	signer := append([]byte{addressPrefix}, crypto.PubkeyToAddress(*pubKey).Bytes()...)
+	if !bytes.Equal(signer, data.Owner) {
+		log.Error("sender mismatch", "expected", encodeAddress(data.Owner), "got", encodeAddress(signer))
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "sender address mismatch",
+		}, nil
+	}
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      hex.EncodeToString(hash),
+		SignedTx: hex.EncodeToString(encodeSignedTransaction(raw, signature)),
+	}, nil
+}
+
+// 通过节点创建交易，并校验节点返回的交易内容
+func (c *ChainAdaptor) buildTransaction(transferTx *TronTransferTx) (*Transaction, error) {
+	from, err := decodeAddress(transferTx.FromAddress)
+	if err != nil {
+		return nil, errors.New("invalid from address")
+	}
+	to, err := decodeAddress(transferTx.ToAddress)
+	if err != nil {
+		return nil, errors.New("invalid to address")
+	}
+	amount, ok := new(big.Int).SetString(transferTx.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+
+	var tx *Transaction
+	var expected rawData
+	if transferTx.ContractAddress == "" {
+		if !amount.IsInt64() {
+			return nil, errors.New("invalid amount")
+		}
+		tx, err = c.TronClient.CreateTransaction(encodeAddress(from), encodeAddress(to), amount.Int64())
+		if err != nil {
+			return nil, fmt.Errorf("create transaction fail: %w", err)
+		}
+		expected = rawData{ContractType: transferContractType, Owner: from, To: to, Amount: amount.Int64()}
+	} else {
+		contract, err := decodeAddress(transferTx.ContractAddress)
+		if err != nil {
+			return nil, errors.New("invalid contract address")
+		}
+		if amount.BitLen() > 256 {
+			return nil, errors.New("invalid amount")
+		}
+		feeLimit := transferTx.FeeLimit
+		if feeLimit == 0 {
+			feeLimit = defaultFeeLimit
+		}
+		parameter := abiAddress(to) + fmt.Sprintf("%064x", amount)
+		tx, err = c.TronClient.TriggerSmartContract(encodeAddress(from), encodeAddress(contract), trc20TransferSelector, parameter, feeLimit)
+		if err != nil {
+			return nil, fmt.Errorf("trigger smart contract fail: %w", err)
+		}
+		callData, _ := hex.DecodeString(trc20TransferMethod + parameter)
+		expected = rawData{ContractType: triggerSmartContractType, Owner: from, Contract: contract, Data: callData, FeeLimit: feeLimit}
+	}
+
+	raw, err := hex.DecodeString(tx.RawDataHex)
+	if err != nil {
+		return nil, errors.New("invalid raw data from node")
+	}
+	data, err := decodeRawData(raw)
+	if err != nil {
+		return nil, fmt.Errorf("decode raw data from node fail: %w", err)
+	}
+	if hex.EncodeToString(txId(raw)) != tx.TxID {
+		return nil, errors.New("transaction id mismatch")
+	}
+	if data.ContractType != expected.ContractType ||
+		!bytes.Equal(data.Owner, expected.Owner) ||
+		!bytes.Equal(data.To, expected.To) ||
+		data.Amount != expected.Amount ||
+		!bytes.Equal(data.Contract, expected.Contract) ||
+		!bytes.Equal(data.Data, expected.Data) ||
+		data.CallValue != 0 ||
+		data.FeeLimit != expected.FeeLimit {
+		return nil, errors.New("transaction from node does not match request")
+	}
+	return tx, nil
+}
@@ -0,0 +1,583 @@
+package tron
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Tron"
+
+const (
+	trc20TransferSelector  = "transfer(address,uint256)"
+	trc20BalanceOfSelector = "balanceOf(address)"
+	trc20TransferMethod    = "a9059cbb"
+	// Transfer(address,address,uint256) 事件签名
+	trc20TransferTopic = "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+)
+
+// 手续费估算参数：带宽按交易字节数计算，能量为 USDT 转账的典型消耗
+const (
+	trxTransferBandwidth   = 268
+	trc20TransferBandwidth = 345
+	trc20TransferEnergy    = 65000
+	// 接收方没有代币余额时需要新建存储槽，能量约翻倍
+	trc20NewHolderEnergy = 130000
+	// 签名交易在 raw_data 之外的字节数：签名 65 字节及字段头，另加节点计算带宽时附加的 64 字节
+	signedTxExtraBandwidth = 67 + 3 + 64
+	// 链参数查询失败时使用的默认值
+	defaultBandwidthPrice = 1000
+	defaultEnergyPrice    = 420
+)
+
+// 每次获取区块的最大数量
+const blockRangeLimit = 100
+
+type ChainAdaptor struct {
+	TronClient ITron
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.Tron
+	tronClient, err := NewTronClient(node.RpcUrl, node.DataApiKey, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		TronClient: tronClient,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入公钥 转换成地址
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	if _, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeSecp256k1); err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	address, err := PubKeyToAddress(req.PublicKey)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: address,
+	}, nil
+}
+
+// 地址格式验证
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 通过区块号获取区块数据
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	block, err := c.TronClient.GetBlockByNum(req.Height)
+	if err != nil {
+		log.Error("get block by number fail", "height", req.Height, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return toBlockResponse(block, "get block by number success"), nil
+}
+
+// 通过区块Hash获取区块数据
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	block, err := c.TronClient.GetBlockById(req.Hash)
+	if err != nil {
+		log.Error("get block by hash fail", "hash", req.Hash, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return toBlockResponse(block, "get block by hash success"), nil
+}
+
+// 通过区块号获取区块头信息，height 为 0 时返回最新区块
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	var block *Block
+	var err error
+	if req.Height == 0 {
+		block, err = c.TronClient.GetNowBlock()
+	} else {
+		block, err = c.TronClient.GetBlockByNum(req.Height)
+	}
+	if err != nil {
+		log.Error("get block header by number fail", "height", req.Height, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by number fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by number success",
+		BlockHeader: toBlockHeader(block),
+	}, nil
+}
+
+// 通过区块Hash获取区块头信息
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	block, err := c.TronClient.GetBlockById(req.Hash)
+	if err != nil {
+		log.Error("get block header by hash fail", "hash", req.Hash, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block header by hash fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block header by hash success",
+		BlockHeader: toBlockHeader(block),
+	}, nil
+}
+
+// 获取区间内的区块头
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseInt(req.Start, 10, 64)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseInt(req.End, 10, 64)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for from := start; from <= end; from += blockRangeLimit {
+		to := min(from+blockRangeLimit, end+1)
+		blocks, err := c.TronClient.GetBlockByLimitNext(from, to)
+		if err != nil {
+			log.Error("get block by limit next fail", "start", from, "end", to, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		for i := range blocks {
+			headers = append(headers, toBlockHeader(&blocks[i]))
+		}
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取账户余额，contract_address 不为空时查询 TRC20 余额
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	address, err := decodeAddress(req.Address)
+	if err != nil {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	var balance string
+	if req.ContractAddress != "" {
+		if !ValidateAddress(req.ContractAddress) {
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid contract address",
+			}, nil
+		}
+		result, err := c.TronClient.TriggerConstantContract(req.Address, req.ContractAddress, trc20BalanceOfSelector, abiAddress(address))
+		if err != nil || len(result.ConstantResult) == 0 {
+			log.Error("get trc20 balance fail", "address", req.Address, "contract", req.ContractAddress, "err", err)
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get account fail",
+			}, nil
+		}
+		balance = abiUint256(result.ConstantResult[0]).String()
+	} else {
+		acc, err := c.TronClient.GetAccount(req.Address)
+		if err != nil {
+			log.Error("get account fail", "address", req.Address, "err", err)
+			return &account.AccountResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get account fail",
+			}, nil
+		}
+		balance = strconv.FormatInt(acc.Balance, 10)
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      "0",
+		Balance:       balance,
+	}, nil
+}
+
+// 获取fee，单位 sun，按带宽和能量价格计算需要燃烧的 TRX。
+// 传入 rawTx（未签名的 raw_data_hex）时估算该交易的手续费，传入 address 时扣除账户可用的带宽和能量，三档返回相同结果；
+// 否则 slow 为 TRX 转账，normal 为向已持币地址的 TRC20 转账，fast 为向新地址的 TRC20 转账
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	bandwidthPrice, energyPrice := int64(defaultBandwidthPrice), int64(defaultEnergyPrice)
+	if parameters, err := c.TronClient.GetChainParameters(); err != nil {
+		log.Warn("get chain parameters fail, use default price", "err", err)
+	} else {
+		if price, ok := parameters["getTransactionFee"]; ok {
+			bandwidthPrice = price
+		}
+		if price, ok := parameters["getEnergyFee"]; ok {
+			energyPrice = price
+		}
+	}
+
+	if req.RawTx == "" {
+		fees := [3]int64{
+			trxTransferBandwidth * bandwidthPrice,
+			trc20TransferBandwidth*bandwidthPrice + trc20TransferEnergy*energyPrice,
+			trc20TransferBandwidth*bandwidthPrice + trc20NewHolderEnergy*energyPrice,
+		}
+		return &account.FeeResponse{
+			Code:      global_const.ReturnCode_SUCCESS,
+			Msg:       "get fee success",
+			SlowFee:   strconv.FormatInt(fees[0], 10),
+			NormalFee: strconv.FormatInt(fees[1], 10),
+			FastFee:   strconv.FormatInt(fees[2], 10),
+		}, nil
+	}
+
+	raw, err := hex.DecodeString(strings.TrimPrefix(req.RawTx, "0x"))
+	if err != nil {
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	data, err := decodeRawData(raw)
+	if err != nil {
+		log.Error("decode raw data fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode raw tx fail",
+		}, nil
+	}
+	bandwidth := int64(len(raw)) + signedTxExtraBandwidth
+	var energy int64
+	if data.ContractType == triggerSmartContractType {
+		if len(data.Data) < 4 || hex.EncodeToString(data.Data[:4]) != trc20TransferMethod {
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "estimate energy only supports trc20 transfer",
+			}, nil
+		}
+		result, err := c.TronClient.TriggerConstantContract(encodeAddress(data.Owner), encodeAddress(data.Contract), trc20TransferSelector, hex.EncodeToString(data.Data[4:]))
+		if err != nil {
+			log.Error("estimate energy fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "estimate energy fail",
+			}, nil
+		}
+		energy = result.EnergyUsed
+	}
+
+	// 扣除账户可用的免费带宽、质押带宽和质押能量
+	if req.Address != "" {
+		resource, err := c.TronClient.GetAccountResource(req.Address)
+		if err != nil {
+			log.Error("get account resource fail", "address", req.Address, "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get account resource fail",
+			}, nil
+		}
+		// 带宽只能整体使用一种来源，任一来源足够时不需要燃烧 TRX
+		if resource.FreeNetLimit-resource.FreeNetUsed >= bandwidth || resource.NetLimit-resource.NetUsed >= bandwidth {
+			bandwidth = 0
+		}
+		energy = max(energy-(resource.EnergyLimit-resource.EnergyUsed), 0)
+	}
+	fee := strconv.FormatInt(bandwidth*bandwidthPrice+energy*energyPrice, 10)
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   fee,
+		NormalFee: fee,
+		FastFee:   fee,
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的十六进制交易
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	txHash, err := c.TronClient.BroadcastHex(strings.TrimPrefix(req.RawTx, "0x"))
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: txHash,
+	}, nil
+}
+
+// 按地址查询交易需要 TronGrid 的 v1 索引接口，这里不支持
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "get tx by address is not supported",
+	}, nil
+}
+
+// 通过交易哈希获取交易，TRC20 转账解析 Transfer 事件，全部事件以 JSON 写入 data
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	tx, err := c.TronClient.GetTransactionById(req.Hash)
+	if err != nil {
+		log.Error("get transaction fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	if tx == nil || len(tx.RawData.Contract) == 0 {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	info, err := c.TronClient.GetTransactionInfoById(req.Hash)
+	if err != nil {
+		log.Error("get transaction info fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction info fail",
+		}, nil
+	}
+
+	value := tx.RawData.Contract[0].Parameter.Value
+	txMessage := &account.TxMessage{
+		Hash:   tx.TxID,
+		From:   value.OwnerAddress,
+		To:     value.ToAddress,
+		Value:  strconv.FormatInt(value.Amount, 10),
+		Status: account.TxStatus_Pending,
+		Type:   0,
+	}
+	if tx.RawData.Contract[0].Type == "TriggerSmartContract" {
+		txMessage.Type = 1
+		txMessage.ContractAddress = value.ContractAddress
+		txMessage.To, txMessage.Value = decodeTrc20Transfer(value.Data)
+	}
+	if info != nil {
+		txMessage.Fee = strconv.FormatInt(info.Fee, 10)
+		txMessage.Height = strconv.FormatInt(info.BlockNumber, 10)
+		txMessage.Datetime = strconv.FormatInt(info.BlockTimeStamp/1000, 10)
+		txMessage.Status = txStatus(tx, info)
+
+		// 以事件为准，合约内部转账也能解析出来
+		transfers := decodeTrc20Events(info)
+		if len(transfers) > 0 {
+			txMessage.From = transfers[0].From
+			txMessage.To = transfers[0].To
+			txMessage.Value = transfers[0].Amount
+			txMessage.ContractAddress = transfers[0].ContractAddress
+			events, _ := json.Marshal(transfers)
+			txMessage.Data = string(events)
+		}
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   txMessage,
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// 合约执行失败时 receipt.result 为 REVERT、OUT_OF_ENERGY 等
+func txStatus(tx *Transaction, info *TransactionInfo) account.TxStatus {
+	if info.Result == "FAILED" {
+		return account.TxStatus_Failed
+	}
+	if info.Receipt.Result != "" && info.Receipt.Result != "SUCCESS" {
+		return account.TxStatus_ContractExecuteFailed
+	}
+	if len(tx.Ret) > 0 && tx.Ret[0].ContractRet != "" && tx.Ret[0].ContractRet != "SUCCESS" {
+		return account.TxStatus_ContractExecuteFailed
+	}
+	return account.TxStatus_Success
+}
+
+// 解析 Transfer 事件
+func decodeTrc20Events(info *TransactionInfo) []Trc20Transfer {
+	var transfers []Trc20Transfer
+	for _, item := range info.Log {
+		if len(item.Topics) != 3 || item.Topics[0] != trc20TransferTopic {
+			continue
+		}
+		from, err1 := hex.DecodeString(item.Topics[1])
+		to, err2 := hex.DecodeString(item.Topics[2])
+		contract, err3 := hex.DecodeString(item.Address)
+		if err1 != nil || err2 != nil || err3 != nil || len(from) < 20 || len(to) < 20 || len(contract) < 20 {
+			continue
+		}
+		transfers = append(transfers, Trc20Transfer{
+			ContractAddress: evmAddressToBase58(contract),
+			From:            evmAddressToBase58(from),
+			To:              evmAddressToBase58(to),
+			Amount:          abiUint256(item.Data).String(),
+		})
+	}
+	return transfers
+}
+
+// 解析 transfer(address,uint256) 调用数据，返回接收地址和金额
+func decodeTrc20Transfer(data string) (string, string) {
+	if len(data) < 8+128 || data[:8] != trc20TransferMethod {
+		return "", "0"
+	}
+	to, err := hex.DecodeString(data[8+24 : 8+64])
+	if err != nil {
+		return "", "0"
+	}
+	return evmAddressToBase58(to), abiUint256(data[8+64 : 8+128]).String()
+}
+
+// ABI 编码的地址参数：去掉 41 前缀后左侧补 0 到 32 字节
+func abiAddress(address []byte) string {
+	return strings.Repeat("0", 24) + hex.EncodeToString(address[1:])
+}
+
+func abiUint256(value string) *big.Int {
+	amount, ok := new(big.Int).SetString(value, 16)
+	if !ok {
+		return new(big.Int)
+	}
+	return amount
+}
+
+func toBlockHeader(block *Block) *account.BlockHeader {
+	header := block.BlockHeader.RawData
+	return &account.BlockHeader{
+		Hash:       block.BlockID,
+		ParentHash: header.ParentHash,
+		Root:       header.TxTrieRoot,
+		CoinBase:   header.WitnessAddress,
+		Number:     strconv.FormatInt(header.Number, 10),
+		Time:       uint64(header.Timestamp / 1000),
+	}
+}
+
+// 区块内的 TRX 和 TRC20 转账，TRC20 按调用数据解析，不包含合约内部转账
+func toBlockResponse(block *Block, msg string) *account.BlockResponse {
+	height := block.BlockHeader.RawData.Number
+	var blockTxList []*account.BlockInfoTransactionList
+	for _, tx := range block.Transactions {
+		if len(tx.RawData.Contract) == 0 {
+			continue
+		}
+		contract := tx.RawData.Contract[0]
+		value := contract.Parameter.Value
+		switch contract.Type {
+		case "TransferContract":
+			blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+				From:   value.OwnerAddress,
+				To:     value.ToAddress,
+				Hash:   tx.TxID,
+				Height: uint64(height),
+				Amount: strconv.FormatInt(value.Amount, 10),
+			})
+		case "TriggerSmartContract":
+			to, amount := decodeTrc20Transfer(value.Data)
+			if to == "" {
+				continue
+			}
+			blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+				From:         value.OwnerAddress,
+				To:           to,
+				TokenAddress: value.ContractAddress,
+				Hash:         tx.TxID,
+				Height:       uint64(height),
+				Amount:       amount,
+			})
+		}
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          msg,
+		Height:       height,
+		Hash:         block.BlockID,
+		Transactions: blockTxList,
+	}
+}
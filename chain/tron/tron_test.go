@@ -0,0 +1,249 @@
+package tron
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// USDT 合约地址
+const usdtContract = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+
+// 模拟节点，按请求构造交易，tamper 不为空时篡改金额
+type fakeTronNode struct {
+	ITron
+	params map[string]int64
+	tx     *Transaction
+	info   *TransactionInfo
+	tamper bool
+}
+
+// 按 protocol.Transaction.raw 编码单个合约的交易
+func encodeRawData(contractType int32, typeUrl string, value []byte, feeLimit int64) []byte {
+	var any []byte
+	any = protowire.AppendTag(any, 1, protowire.BytesType)
+	any = protowire.AppendString(any, typeUrl)
+	any = protowire.AppendTag(any, 2, protowire.BytesType)
+	any = protowire.AppendBytes(any, value)
+	var contract []byte
+	contract = protowire.AppendTag(contract, 1, protowire.VarintType)
+	contract = protowire.AppendVarint(contract, uint64(contractType))
+	contract = protowire.AppendTag(contract, 2, protowire.BytesType)
+	contract = protowire.AppendBytes(contract, any)
+
+	var raw []byte
+	raw = protowire.AppendTag(raw, 1, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, []byte{0x12, 0x34})
+	raw = protowire.AppendTag(raw, 4, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, make([]byte, 8))
+	raw = protowire.AppendTag(raw, 8, protowire.VarintType)
+	raw = protowire.AppendVarint(raw, uint64(time.Now().Add(time.Minute).UnixMilli()))
+	raw = protowire.AppendTag(raw, 11, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, contract)
+	raw = protowire.AppendTag(raw, 14, protowire.VarintType)
+	raw = protowire.AppendVarint(raw, uint64(time.Now().UnixMilli()))
+	if feeLimit > 0 {
+		raw = protowire.AppendTag(raw, 18, protowire.VarintType)
+		raw = protowire.AppendVarint(raw, uint64(feeLimit))
+	}
+	return raw
+}
+
+func toTransaction(raw []byte) *Transaction {
+	return &Transaction{TxID: hex.EncodeToString(txId(raw)), RawDataHex: hex.EncodeToString(raw)}
+}
+
+func (f *fakeTronNode) CreateTransaction(owner, to string, amount int64) (*Transaction, error) {
+	ownerBytes, _ := decodeAddress(owner)
+	toBytes, _ := decodeAddress(to)
+	if f.tamper {
+		amount++
+	}
+	var value []byte
+	value = protowire.AppendTag(value, 1, protowire.BytesType)
+	value = protowire.AppendBytes(value, ownerBytes)
+	value = protowire.AppendTag(value, 2, protowire.BytesType)
+	value = protowire.AppendBytes(value, toBytes)
+	value = protowire.AppendTag(value, 3, protowire.VarintType)
+	value = protowire.AppendVarint(value, uint64(amount))
+	return toTransaction(encodeRawData(transferContractType, transferContractTypeUrl, value, 0)), nil
+}
+
+func (f *fakeTronNode) TriggerSmartContract(owner, contract, selector, parameter string, feeLimit int64) (*Transaction, error) {
+	ownerBytes, _ := decodeAddress(owner)
+	contractBytes, _ := decodeAddress(contract)
+	data, _ := hex.DecodeString(trc20TransferMethod + parameter)
+	if f.tamper {
+		data[len(data)-1]++
+	}
+	var value []byte
+	value = protowire.AppendTag(value, 1, protowire.BytesType)
+	value = protowire.AppendBytes(value, ownerBytes)
+	value = protowire.AppendTag(value, 2, protowire.BytesType)
+	value = protowire.AppendBytes(value, contractBytes)
+	value = protowire.AppendTag(value, 4, protowire.BytesType)
+	value = protowire.AppendBytes(value, data)
+	return toTransaction(encodeRawData(triggerSmartContractType, triggerSmartContractTypeUrl, value, feeLimit)), nil
+}
+
+func (f *fakeTronNode) TriggerConstantContract(owner, contract, selector, parameter string) (*ConstantResult, error) {
+	result := &ConstantResult{EnergyUsed: 64285}
+	result.Result.Result = true
+	return result, nil
+}
+
+func (f *fakeTronNode) GetChainParameters() (map[string]int64, error) {
+	return f.params, nil
+}
+
+func (f *fakeTronNode) GetAccountResource(address string) (*AccountResource, error) {
+	return &AccountResource{FreeNetLimit: 600, EnergyLimit: 30000}, nil
+}
+
+func (f *fakeTronNode) GetTransactionById(txid string) (*Transaction, error) {
+	return f.tx, nil
+}
+
+func (f *fakeTronNode) GetTransactionInfoById(txid string) (*TransactionInfo, error) {
+	return f.info, nil
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	adaptor := &ChainAdaptor{}
+	// secp256k1 生成元 G 对应私钥 1，以太坊地址为 0x7E5F...5Bdf
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"})
+	address, err := decodeAddress(resp.Address)
+	if err != nil || resp.Address != "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC" {
+		t.Fatalf("unexpected address %s", resp.Address)
+	}
+	if common.BytesToAddress(address[1:]) != common.HexToAddress("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf") {
+		t.Fatalf("unexpected address %s", resp.Address)
+	}
+
+	hexAddress, _ := decodeAddress(usdtContract)
+	if hex.EncodeToString(hexAddress) != "41a614f803b6fd780986a42c78ec9c7f77e6ded13c" {
+		t.Fatalf("unexpected hex address %x", hexAddress)
+	}
+	if fromHex, _ := decodeAddress("41a614f803b6fd780986a42c78ec9c7f77e6ded13c"); encodeAddress(fromHex) != usdtContract {
+		t.Fatal("expected hex address to round trip")
+	}
+	if ValidateAddress("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u") || ValidateAddress("1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH") {
+		t.Fatal("expected invalid address")
+	}
+}
+
+func Test_BuildAndSignTransaction(t *testing.T) {
+	privKey, _ := crypto.GenerateKey()
+	from := encodeAddress(append([]byte{addressPrefix}, crypto.PubkeyToAddress(privKey.PublicKey).Bytes()...))
+	to := "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC"
+	if !ValidateAddress(to) {
+		t.Fatal("expected valid to address")
+	}
+	node := &fakeTronNode{}
+	adaptor := &ChainAdaptor{TronClient: node}
+
+	for _, contract := range []string{"", usdtContract} {
+		txJson, _ := json.Marshal(&TronTransferTx{FromAddress: from, ToAddress: to, Amount: "1500000", ContractAddress: contract})
+		base64Tx := base64.StdEncoding.EncodeToString(txJson)
+
+		node.tamper = true
+		if resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64Tx}); resp.Code != global_const.ReturnCode_ERROR {
+			t.Fatal("expected tampered transaction to be rejected")
+		}
+		node.tamper = false
+		unSigned, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64Tx})
+		if unSigned.Code != global_const.ReturnCode_SUCCESS {
+			t.Fatalf("build unsigned tx fail: %s", unSigned.Msg)
+		}
+
+		hash, _ := hex.DecodeString(unSigned.SignHashes[0])
+		otherKey, _ := crypto.GenerateKey()
+		otherSig, _ := crypto.Sign(hash, otherKey)
+		if resp, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signature: hex.EncodeToString(otherSig)}); resp.Code != global_const.ReturnCode_ERROR {
+			t.Fatal("expected signature from other key to be rejected")
+		}
+
+		sig, _ := crypto.Sign(hash, privKey)
+		sig[64] += 27
+		signed, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{Base64Tx: unSigned.UnSignTx, Signature: hex.EncodeToString(sig)})
+		if signed.Code != global_const.ReturnCode_SUCCESS || signed.Msg != unSigned.SignHashes[0] {
+			t.Fatalf("build signed tx fail: %s", signed.Msg)
+		}
+		rawTx, _ := hex.DecodeString(signed.SignedTx)
+		raw, signatures, err := decodeSignedTransaction(rawTx)
+		if err != nil || hex.EncodeToString(raw) != unSigned.UnSignTx || len(signatures) != 1 || signatures[0][64] > 1 {
+			t.Fatalf("unexpected signed tx %s", signed.SignedTx)
+		}
+	}
+}
+
+func Test_GetTxByHash(t *testing.T) {
+	from, _ := decodeAddress("TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC")
+	to, _ := decodeAddress(usdtContract)
+	tx := &Transaction{TxID: "ab"}
+	tx.RawData.Contract = []Contract{{Type: "TriggerSmartContract"}}
+	tx.RawData.Contract[0].Parameter.Value = ContractValue{
+		OwnerAddress:    "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC",
+		ContractAddress: usdtContract,
+		Data:            trc20TransferMethod + abiAddress(to) + "00000000000000000000000000000000000000000000000000000000000f4240",
+	}
+	info := &TransactionInfo{Id: "ab", Fee: 13845000, BlockNumber: 100, BlockTimeStamp: 1700000000000}
+	info.Receipt.Result = "SUCCESS"
+	info.Log = append(info.Log, struct {
+		Address string   `json:"address"`
+		Topics  []string `json:"topics"`
+		Data    string   `json:"data"`
+	}{
+		Address: "a614f803b6fd780986a42c78ec9c7f77e6ded13c",
+		Topics:  []string{trc20TransferTopic, abiAddress(from), abiAddress(to)},
+		Data:    "00000000000000000000000000000000000000000000000000000000000f4240",
+	})
+	adaptor := &ChainAdaptor{TronClient: &fakeTronNode{tx: tx, info: info}}
+
+	resp, _ := adaptor.GetTxByHash(&account.TxHashRequest{Hash: "ab"})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Tx.Status != account.TxStatus_Success {
+		t.Fatalf("get tx by hash fail: %s", resp.Msg)
+	}
+	if resp.Tx.From != "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC" || resp.Tx.To != usdtContract || resp.Tx.Value != "1000000" ||
+		resp.Tx.ContractAddress != usdtContract || resp.Tx.Fee != "13845000" || resp.Tx.Height != "100" {
+		t.Fatalf("unexpected tx %+v", resp.Tx)
+	}
+
+	info.Receipt.Result = "OUT_OF_ENERGY"
+	info.Log = nil
+	resp, _ = adaptor.GetTxByHash(&account.TxHashRequest{Hash: "ab"})
+	if resp.Tx.Status != account.TxStatus_ContractExecuteFailed || resp.Tx.To != usdtContract || resp.Tx.Value != "1000000" {
+		t.Fatalf("unexpected failed tx %+v", resp.Tx)
+	}
+
+	adaptor.TronClient = &fakeTronNode{}
+	if resp, _ = adaptor.GetTxByHash(&account.TxHashRequest{Hash: "ab"}); resp.Tx.Status != account.TxStatus_NotFound {
+		t.Fatal("expected not found")
+	}
+}
+
+func Test_GetFee(t *testing.T) {
+	node := &fakeTronNode{params: map[string]int64{"getTransactionFee": 1000, "getEnergyFee": 210}}
+	adaptor := &ChainAdaptor{TronClient: node}
+	resp, _ := adaptor.GetFee(&account.FeeRequest{})
+	if resp.SlowFee != strconv.Itoa(268*1000) || resp.NormalFee != strconv.Itoa(345*1000+65000*210) {
+		t.Fatalf("unexpected fee %+v", resp)
+	}
+
+	// 免费带宽足够，扣除 30000 质押能量后燃烧 34285 能量
+	tx, _ := node.TriggerSmartContract("TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC", usdtContract, trc20TransferSelector, abiAddress(make([]byte, 21))+"0000000000000000000000000000000000000000000000000000000000000001", defaultFeeLimit)
+	resp, _ = adaptor.GetFee(&account.FeeRequest{RawTx: tx.RawDataHex, Address: "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC"})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.NormalFee != strconv.Itoa(34285*210) {
+		t.Fatalf("unexpected fee %+v", resp)
+	}
+}
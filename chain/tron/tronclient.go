@@ -0,0 +1,379 @@
+package tron
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// 区块头
+type BlockHeaderRaw struct {
+	Number         int64  `json:"number"`
+	TxTrieRoot     string `json:"txTrieRoot"`
+	WitnessAddress string `json:"witness_address"`
+	ParentHash     string `json:"parentHash"`
+	Version        int64  `json:"version"`
+	Timestamp      int64  `json:"timestamp"` // 毫秒
+}
+
+type Block struct {
+	BlockID     string `json:"blockID"`
+	BlockHeader struct {
+		RawData BlockHeaderRaw `json:"raw_data"`
+	} `json:"block_header"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+// 合约参数，visible 为 true 时地址为 base58 格式
+type ContractValue struct {
+	OwnerAddress    string `json:"owner_address"`
+	ToAddress       string `json:"to_address"`
+	Amount          int64  `json:"amount"`
+	ContractAddress string `json:"contract_address"`
+	Data            string `json:"data"`
+	CallValue       int64  `json:"call_value"`
+}
+
+type Contract struct {
+	Parameter struct {
+		Value   ContractValue `json:"value"`
+		TypeUrl string        `json:"type_url"`
+	} `json:"parameter"`
+	Type string `json:"type"`
+}
+
+type Transaction struct {
+	TxID    string `json:"txID"`
+	RawData struct {
+		Contract   []Contract `json:"contract"`
+		Expiration int64      `json:"expiration"`
+		Timestamp  int64      `json:"timestamp"`
+		FeeLimit   int64      `json:"fee_limit"`
+	} `json:"raw_data"`
+	RawDataHex string   `json:"raw_data_hex"`
+	Signature  []string `json:"signature"`
+	Ret        []struct {
+		ContractRet string `json:"contractRet"`
+	} `json:"ret"`
+}
+
+// 交易执行结果，日志中的地址为不带 41 前缀的十六进制
+type TransactionInfo struct {
+	Id             string `json:"id"`
+	Fee            int64  `json:"fee"`
+	BlockNumber    int64  `json:"blockNumber"`
+	BlockTimeStamp int64  `json:"blockTimeStamp"`
+	Result         string `json:"result"` // 失败时为 FAILED
+	ResMessage     string `json:"resMessage"`
+	Receipt        struct {
+		EnergyUsageTotal int64  `json:"energy_usage_total"`
+		NetUsage         int64  `json:"net_usage"`
+		Result           string `json:"result"`
+	} `json:"receipt"`
+	Log []struct {
+		Address string   `json:"address"`
+		Topics  []string `json:"topics"`
+		Data    string   `json:"data"`
+	} `json:"log"`
+}
+
+type Account struct {
+	Address string `json:"address"`
+	Balance int64  `json:"balance"`
+}
+
+// 账户可用带宽和能量
+type AccountResource struct {
+	FreeNetUsed  int64 `json:"freeNetUsed"`
+	FreeNetLimit int64 `json:"freeNetLimit"`
+	NetUsed      int64 `json:"NetUsed"`
+	NetLimit     int64 `json:"NetLimit"`
+	EnergyUsed   int64 `json:"EnergyUsed"`
+	EnergyLimit  int64 `json:"EnergyLimit"`
+}
+
+// 只读合约调用结果
+type ConstantResult struct {
+	Result struct {
+		Result  bool   `json:"result"`
+		Message string `json:"message"`
+	} `json:"result"`
+	EnergyUsed     int64    `json:"energy_used"`
+	ConstantResult []string `json:"constant_result"`
+}
+
+type triggerResult struct {
+	Result struct {
+		Result  bool   `json:"result"`
+		Message string `json:"message"`
+	} `json:"result"`
+	Transaction *Transaction `json:"transaction"`
+}
+
+type broadcastResult struct {
+	Result  bool   `json:"result"`
+	TxId    string `json:"txid"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// 定义 TronGrid 兼容的 HTTP 接口
+type ITron interface {
+	// 区块数据相关
+	GetNowBlock() (*Block, error)
+	GetBlockByNum(num int64) (*Block, error)
+	GetBlockById(id string) (*Block, error)
+	GetBlockByLimitNext(start, end int64) ([]Block, error)
+	// 账户
+	GetAccount(address string) (*Account, error)
+	GetAccountResource(address string) (*AccountResource, error)
+	GetChainParameters() (map[string]int64, error)
+	// 交易
+	CreateTransaction(owner, to string, amount int64) (*Transaction, error)
+	TriggerSmartContract(owner, contract, selector, parameter string, feeLimit int64) (*Transaction, error)
+	TriggerConstantContract(owner, contract, selector, parameter string) (*ConstantResult, error)
+	BroadcastHex(rawTx string) (string, error)
+	GetTransactionById(txid string) (*Transaction, error)
+	GetTransactionInfoById(txid string) (*TransactionInfo, error)
+}
+
+// 定义 TronGrid 客户端，apiKey 通过 TRON-PRO-API-KEY 请求头传入
+type TronClient struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+func NewTronClient(rpcUrl, apiKey string, timeout time.Duration) (ITron, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &TronClient{
+		url:    strings.TrimSuffix(rpcUrl, "/"),
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// 调用 /wallet 接口，节点出错时返回 {"Error": "..."}
+func (t *TronClient) post(path string, params any, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, t.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.apiKey != "" {
+		req.Header.Set("TRON-PRO-API-KEY", t.apiKey)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("call %s fail, status %d: %s", path, resp.StatusCode, string(respBody))
+	}
+	var nodeErr struct {
+		Error string `json:"Error"`
+	}
+	if err := json.Unmarshal(respBody, &nodeErr); err == nil && nodeErr.Error != "" {
+		return fmt.Errorf("call %s fail: %s", path, nodeErr.Error)
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// 节点返回的错误信息有时是十六进制编码的
+func decodeMessage(message string) string {
+	if decoded, err := hex.DecodeString(message); err == nil {
+		return string(decoded)
+	}
+	return message
+}
+
+// 获取最新区块
+func (t *TronClient) GetNowBlock() (*Block, error) {
+	block := new(Block)
+	if err := t.post("/wallet/getnowblock", map[string]any{"visible": true}, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// 通过区块高度获取区块，区块不存在时返回空的 blockID
+func (t *TronClient) GetBlockByNum(num int64) (*Block, error) {
+	block := new(Block)
+	if err := t.post("/wallet/getblockbynum", map[string]any{"num": num, "visible": true}, block); err != nil {
+		return nil, err
+	}
+	if block.BlockID == "" {
+		return nil, fmt.Errorf("block %d not found", num)
+	}
+	return block, nil
+}
+
+// 通过区块哈希获取区块
+func (t *TronClient) GetBlockById(id string) (*Block, error) {
+	block := new(Block)
+	if err := t.post("/wallet/getblockbyid", map[string]any{"value": id, "visible": true}, block); err != nil {
+		return nil, err
+	}
+	if block.BlockID == "" {
+		return nil, fmt.Errorf("block %s not found", id)
+	}
+	return block, nil
+}
+
+// 获取 [start, end) 区间内的区块，节点限制单次最多 100 个
+func (t *TronClient) GetBlockByLimitNext(start, end int64) ([]Block, error) {
+	var result struct {
+		Block []Block `json:"block"`
+	}
+	if err := t.post("/wallet/getblockbylimitnext", map[string]any{"startNum": start, "endNum": end, "visible": true}, &result); err != nil {
+		return nil, err
+	}
+	return result.Block, nil
+}
+
+// 获取账户信息，未激活的账户返回空结构
+func (t *TronClient) GetAccount(address string) (*Account, error) {
+	acc := new(Account)
+	if err := t.post("/wallet/getaccount", map[string]any{"address": address, "visible": true}, acc); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+// 获取账户带宽和能量
+func (t *TronClient) GetAccountResource(address string) (*AccountResource, error) {
+	resource := new(AccountResource)
+	if err := t.post("/wallet/getaccountresource", map[string]any{"address": address, "visible": true}, resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// 获取链参数，如 getTransactionFee（sun/带宽）、getEnergyFee（sun/能量）
+func (t *TronClient) GetChainParameters() (map[string]int64, error) {
+	var result struct {
+		ChainParameter []struct {
+			Key   string `json:"key"`
+			Value int64  `json:"value"`
+		} `json:"chainParameter"`
+	}
+	if err := t.post("/wallet/getchainparameters", map[string]any{}, &result); err != nil {
+		return nil, err
+	}
+	parameters := make(map[string]int64, len(result.ChainParameter))
+	for _, item := range result.ChainParameter {
+		parameters[item.Key] = item.Value
+	}
+	return parameters, nil
+}
+
+// 创建 TRX 转账交易
+func (t *TronClient) CreateTransaction(owner, to string, amount int64) (*Transaction, error) {
+	tx := new(Transaction)
+	params := map[string]any{"owner_address": owner, "to_address": to, "amount": amount, "visible": true}
+	if err := t.post("/wallet/createtransaction", params, tx); err != nil {
+		return nil, err
+	}
+	if tx.RawDataHex == "" {
+		return nil, errors.New("create transaction fail: empty raw data")
+	}
+	return tx, nil
+}
+
+// 创建合约调用交易
+func (t *TronClient) TriggerSmartContract(owner, contract, selector, parameter string, feeLimit int64) (*Transaction, error) {
+	var result triggerResult
+	params := map[string]any{
+		"owner_address":     owner,
+		"contract_address":  contract,
+		"function_selector": selector,
+		"parameter":         parameter,
+		"fee_limit":         feeLimit,
+		"call_value":        0,
+		"visible":           true,
+	}
+	if err := t.post("/wallet/triggersmartcontract", params, &result); err != nil {
+		return nil, err
+	}
+	if !result.Result.Result || result.Transaction == nil {
+		return nil, fmt.Errorf("trigger smart contract fail: %s", decodeMessage(result.Result.Message))
+	}
+	return result.Transaction, nil
+}
+
+// 只读调用合约，用于查询余额和估算能量
+func (t *TronClient) TriggerConstantContract(owner, contract, selector, parameter string) (*ConstantResult, error) {
+	result := new(ConstantResult)
+	params := map[string]any{
+		"owner_address":     owner,
+		"contract_address":  contract,
+		"function_selector": selector,
+		"parameter":         parameter,
+		"visible":           true,
+	}
+	if err := t.post("/wallet/triggerconstantcontract", params, result); err != nil {
+		return nil, err
+	}
+	if !result.Result.Result {
+		return nil, fmt.Errorf("trigger constant contract fail: %s", decodeMessage(result.Result.Message))
+	}
+	return result, nil
+}
+
+// 广播十六进制编码的签名交易，返回交易哈希
+func (t *TronClient) BroadcastHex(rawTx string) (string, error) {
+	var result broadcastResult
+	if err := t.post("/wallet/broadcasthex", map[string]any{"transaction": rawTx}, &result); err != nil {
+		return "", err
+	}
+	if !result.Result {
+		return "", fmt.Errorf("broadcast fail, code %s: %s", result.Code, decodeMessage(result.Message))
+	}
+	return result.TxId, nil
+}
+
+// 获取交易，交易不存在时返回 nil
+func (t *TronClient) GetTransactionById(txid string) (*Transaction, error) {
+	tx := new(Transaction)
+	if err := t.post("/wallet/gettransactionbyid", map[string]any{"value": txid, "visible": true}, tx); err != nil {
+		return nil, err
+	}
+	if tx.TxID == "" {
+		return nil, nil
+	}
+	return tx, nil
+}
+
+// 获取交易执行结果，未上链时返回 nil
+func (t *TronClient) GetTransactionInfoById(txid string) (*TransactionInfo, error) {
+	info := new(TransactionInfo)
+	if err := t.post("/wallet/gettransactioninfobyid", map[string]any{"value": txid}, info); err != nil {
+		return nil, err
+	}
+	if info.Id == "" {
+		return nil, nil
+	}
+	return info, nil
+}
@@ -0,0 +1,21 @@
+package tron
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type TronTransferTx struct {
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	// TRX 单位 sun，TRC20 为代币最小单位
+	Amount string `json:"amount"`
+	// TRC20 合约地址，为空时转账 TRX
+	ContractAddress string `json:"contract_address"`
+	// TRC20 转账愿意燃烧的最大手续费（sun），为 0 时使用 defaultFeeLimit
+	FeeLimit int64 `json:"fee_limit"`
+}
+
+// TRC20 Transfer 事件
+type Trc20Transfer struct {
+	ContractAddress string `json:"contract_address"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Amount          string `json:"amount"`
+}
@@ -0,0 +1,114 @@
+package xrp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/base58"
+
+	"chain-account/chain"
+)
+
+// 经典地址的版本字节
+const accountIdVersion = 0x00
+
+// ed25519 公钥在 SigningPubKey 中的前缀
+const ed25519Prefix = 0xED
+
+// 比特币与 XRPL 的 base58 字母表，XRPL 地址按字符替换后复用比特币的 base58 实现
+const (
+	bitcoinAlphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	rippleAlphabet  = "rpshnaf39wBUDNEGHJKLM4PQRST7VWXYZ2bcdeCg65jkm8oFqi1tuvAxyz"
+)
+
+var (
+	toRipple  = strings.NewReplacer(pairs(bitcoinAlphabet, rippleAlphabet)...)
+	toBitcoin = strings.NewReplacer(pairs(rippleAlphabet, bitcoinAlphabet)...)
+)
+
+func pairs(from, to string) []string {
+	var result []string
+	for i := range from {
+		result = append(result, from[i:i+1], to[i:i+1])
+	}
+	return result
+}
+
+// 账户 ID，公钥的 ripemd160(sha256)
+type AccountID [20]byte
+
+// 经典地址：r 开头的 base58check 编码
+func (a AccountID) String() string {
+	payload := append([]byte{accountIdVersion}, a[:]...)
+	return toRipple.Replace(base58.Encode(append(payload, checksum(payload)...)))
+}
+
+func checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
+
+// 解析经典地址，不支持 X 地址
+func ParseAddress(address string) (AccountID, error) {
+	var a AccountID
+	if !strings.HasPrefix(address, "r") {
+		return a, errors.New("invalid address")
+	}
+	data := base58.Decode(toBitcoin.Replace(address))
+	if len(data) != 1+len(a)+4 || data[0] != accountIdVersion {
+		return a, errors.New("invalid address")
+	}
+	if !bytes.Equal(checksum(data[:len(data)-4]), data[len(data)-4:]) {
+		return a, errors.New("invalid address")
+	}
+	copy(a[:], data[1:len(data)-4])
+	return a, nil
+}
+
+func ValidateAddress(address string) bool {
+	_, err := ParseAddress(address)
+	return err == nil
+}
+
+// 解析十六进制公钥，返回交易 SigningPubKey 使用的 33 字节格式：
+// secp256k1 为压缩公钥（可传入 65 字节未压缩公钥），ed25519 为 0xED + 32 字节公钥
+func ParsePublicKey(publicKey string, keyType string) ([]byte, error) {
+	pubKey, err := hex.DecodeString(strings.TrimPrefix(publicKey, "0x"))
+	if err != nil {
+		return nil, errors.New("invalid public key")
+	}
+	switch keyType {
+	case chain.KeyTypeEd25519:
+		if len(pubKey) == 33 && pubKey[0] == ed25519Prefix {
+			return pubKey, nil
+		}
+		if len(pubKey) != 32 {
+			return nil, errors.New("invalid ed25519 public key")
+		}
+		return append([]byte{ed25519Prefix}, pubKey...), nil
+	default:
+		key, err := btcec.ParsePubKey(pubKey)
+		if err != nil {
+			return nil, errors.New("invalid secp256k1 public key")
+		}
+		return key.SerializeCompressed(), nil
+	}
+}
+
+// 公钥类型由 SigningPubKey 的前缀区分
+func isEd25519(signingPubKey []byte) bool {
+	return len(signingPubKey) == 33 && signingPubKey[0] == ed25519Prefix
+}
+
+// 由 SigningPubKey 计算账户 ID
+func PubKeyToAccountID(signingPubKey []byte) AccountID {
+	var a AccountID
+	copy(a[:], btcutil.Hash160(signingPubKey))
+	return a
+}
@@ -0,0 +1,275 @@
+package xrp
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 签名数据和交易哈希的前缀
+var (
+	signingPrefix         = []byte{0x53, 0x54, 0x58, 0x00} // "STX\0"
+	transactionIdPrefix   = []byte{0x54, 0x58, 0x4E, 0x00} // "TXN\0"
+	errUnsupportedField   = errors.New("unsupported transaction field")
+	errInvalidTransaction = errors.New("invalid transaction")
+)
+
+// 字段类型编码
+const (
+	typeUInt16    = 1
+	typeUInt32    = 2
+	typeAmount    = 6
+	typeBlob      = 7
+	typeAccountID = 8
+)
+
+// 交易中使用的字段，按 (类型, 字段编码) 排序序列化
+type fieldId struct {
+	typeCode  int
+	fieldCode int
+}
+
+var (
+	fieldTransactionType    = fieldId{typeUInt16, 2}
+	fieldFlags              = fieldId{typeUInt32, 2}
+	fieldSequence           = fieldId{typeUInt32, 4}
+	fieldDestinationTag     = fieldId{typeUInt32, 14}
+	fieldLastLedgerSequence = fieldId{typeUInt32, 27}
+	fieldAmount             = fieldId{typeAmount, 1}
+	fieldFee                = fieldId{typeAmount, 8}
+	fieldSigningPubKey      = fieldId{typeBlob, 3}
+	fieldTxnSignature       = fieldId{typeBlob, 4}
+	fieldAccount            = fieldId{typeAccountID, 1}
+	fieldDestination        = fieldId{typeAccountID, 3}
+)
+
+// Payment 的交易类型编码
+const transactionTypePayment = 0
+
+// XRP 金额：最高位为 0（非代币），次高位为 1（正数），其余为 drops
+const (
+	nativeAmountPositive = uint64(1) << 62
+	issuedAmountFlag     = uint64(1) << 63
+	maxDrops             = uint64(100_000_000_000) * 1_000_000
+)
+
+// XRP 转账（Payment），只支持 XRP，不支持代币和路径支付
+type Payment struct {
+	Account            AccountID
+	Destination        AccountID
+	Amount             uint64 // drops
+	Fee                uint64 // drops
+	Sequence           uint32
+	DestinationTag     *uint32
+	LastLedgerSequence uint32
+	Flags              uint32
+	SigningPubKey      []byte
+	TxnSignature       []byte
+}
+
+type field struct {
+	id    fieldId
+	value []byte
+}
+
+func (p *Payment) fields(withSignature bool) []field {
+	fields := []field{
+		{fieldTransactionType, binary.BigEndian.AppendUint16(nil, transactionTypePayment)},
+		{fieldFlags, binary.BigEndian.AppendUint32(nil, p.Flags)},
+		{fieldSequence, binary.BigEndian.AppendUint32(nil, p.Sequence)},
+		{fieldAmount, binary.BigEndian.AppendUint64(nil, nativeAmountPositive|p.Amount)},
+		{fieldFee, binary.BigEndian.AppendUint64(nil, nativeAmountPositive|p.Fee)},
+		{fieldSigningPubKey, appendVL(nil, p.SigningPubKey)},
+		{fieldAccount, appendVL(nil, p.Account[:])},
+		{fieldDestination, appendVL(nil, p.Destination[:])},
+	}
+	if p.DestinationTag != nil {
+		fields = append(fields, field{fieldDestinationTag, binary.BigEndian.AppendUint32(nil, *p.DestinationTag)})
+	}
+	if p.LastLedgerSequence != 0 {
+		fields = append(fields, field{fieldLastLedgerSequence, binary.BigEndian.AppendUint32(nil, p.LastLedgerSequence)})
+	}
+	if withSignature && len(p.TxnSignature) > 0 {
+		fields = append(fields, field{fieldTxnSignature, appendVL(nil, p.TxnSignature)})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].id.typeCode != fields[j].id.typeCode {
+			return fields[i].id.typeCode < fields[j].id.typeCode
+		}
+		return fields[i].id.fieldCode < fields[j].id.fieldCode
+	})
+	return fields
+}
+
+func (p *Payment) serialize(withSignature bool) []byte {
+	var data []byte
+	for _, f := range p.fields(withSignature) {
+		data = appendFieldHeader(data, f.id)
+		data = append(data, f.value...)
+	}
+	return data
+}
+
+// 完整的交易二进制（tx_blob），未签名时不含 TxnSignature
+func (p *Payment) Serialize() []byte {
+	return p.serialize(true)
+}
+
+// 签名数据："STX\0" || 不含 TxnSignature 的交易二进制
+func (p *Payment) SigningData() []byte {
+	return append(append([]byte{}, signingPrefix...), p.serialize(false)...)
+}
+
+// secp256k1 签名的哈希：签名数据的 SHA-512Half，ed25519 直接对签名数据签名
+func (p *Payment) SigningHash() []byte {
+	return sha512Half(p.SigningData())
+}
+
+// 交易哈希：SHA-512Half("TXN\0" || tx_blob)，大写十六进制
+func (p *Payment) Hash() string {
+	data := append(append([]byte{}, transactionIdPrefix...), p.Serialize()...)
+	return strings.ToUpper(hex.EncodeToString(sha512Half(data)))
+}
+
+func sha512Half(data []byte) []byte {
+	hash := sha512.Sum512(data)
+	return hash[:32]
+}
+
+// 字段头：类型和字段编码都小于 16 时合并为一个字节
+func appendFieldHeader(data []byte, id fieldId) []byte {
+	switch {
+	case id.typeCode < 16 && id.fieldCode < 16:
+		return append(data, byte(id.typeCode<<4|id.fieldCode))
+	case id.typeCode < 16:
+		return append(data, byte(id.typeCode<<4), byte(id.fieldCode))
+	case id.fieldCode < 16:
+		return append(data, byte(id.fieldCode), byte(id.typeCode))
+	default:
+		return append(data, 0, byte(id.typeCode), byte(id.fieldCode))
+	}
+}
+
+// 变长字段，交易中的公钥、签名和账户都不超过 192 字节，长度占一个字节
+func appendVL(data []byte, value []byte) []byte {
+	return append(append(data, byte(len(value))), value...)
+}
+
+// 解析 Payment 的交易二进制，遇到其他字段或代币金额时返回错误
+func DeserializePayment(data []byte) (*Payment, error) {
+	p := new(Payment)
+	seen := make(map[fieldId]bool)
+	for len(data) > 0 {
+		id, rest, err := readFieldHeader(data)
+		if err != nil {
+			return nil, err
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("duplicate field %d:%d", id.typeCode, id.fieldCode)
+		}
+		seen[id] = true
+		var value []byte
+		switch id.typeCode {
+		case typeUInt16:
+			value, data, err = readFixed(rest, 2)
+		case typeUInt32:
+			value, data, err = readFixed(rest, 4)
+		case typeAmount:
+			value, data, err = readFixed(rest, 8)
+			if err == nil && binary.BigEndian.Uint64(value)&issuedAmountFlag != 0 {
+				return nil, errors.New("issued currency amount is not supported")
+			}
+		case typeBlob, typeAccountID:
+			value, data, err = readVL(rest)
+		default:
+			return nil, errUnsupportedField
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := p.setField(id, value); err != nil {
+			return nil, err
+		}
+	}
+	if !seen[fieldTransactionType] || !seen[fieldAccount] || !seen[fieldDestination] || !seen[fieldAmount] || !seen[fieldFee] {
+		return nil, errInvalidTransaction
+	}
+	return p, nil
+}
+
+func (p *Payment) setField(id fieldId, value []byte) error {
+	switch id {
+	case fieldTransactionType:
+		if binary.BigEndian.Uint16(value) != transactionTypePayment {
+			return errors.New("transaction is not a payment")
+		}
+	case fieldFlags:
+		p.Flags = binary.BigEndian.Uint32(value)
+	case fieldSequence:
+		p.Sequence = binary.BigEndian.Uint32(value)
+	case fieldDestinationTag:
+		tag := binary.BigEndian.Uint32(value)
+		p.DestinationTag = &tag
+	case fieldLastLedgerSequence:
+		p.LastLedgerSequence = binary.BigEndian.Uint32(value)
+	case fieldAmount:
+		p.Amount = binary.BigEndian.Uint64(value) &^ nativeAmountPositive
+	case fieldFee:
+		p.Fee = binary.BigEndian.Uint64(value) &^ nativeAmountPositive
+	case fieldSigningPubKey:
+		p.SigningPubKey = value
+	case fieldTxnSignature:
+		p.TxnSignature = value
+	case fieldAccount, fieldDestination:
+		if len(value) != len(AccountID{}) {
+			return errInvalidTransaction
+		}
+		if id == fieldAccount {
+			copy(p.Account[:], value)
+		} else {
+			copy(p.Destination[:], value)
+		}
+	default:
+		return errUnsupportedField
+	}
+	return nil
+}
+
+func readFieldHeader(data []byte) (fieldId, []byte, error) {
+	if len(data) == 0 {
+		return fieldId{}, nil, errInvalidTransaction
+	}
+	typeCode, fieldCode := int(data[0]>>4), int(data[0]&0x0F)
+	data = data[1:]
+	if typeCode == 0 {
+		if len(data) == 0 {
+			return fieldId{}, nil, errInvalidTransaction
+		}
+		typeCode, data = int(data[0]), data[1:]
+	}
+	if fieldCode == 0 {
+		if len(data) == 0 {
+			return fieldId{}, nil, errInvalidTransaction
+		}
+		fieldCode, data = int(data[0]), data[1:]
+	}
+	return fieldId{typeCode, fieldCode}, data, nil
+}
+
+func readFixed(data []byte, size int) ([]byte, []byte, error) {
+	if len(data) < size {
+		return nil, nil, errInvalidTransaction
+	}
+	return data[:size], data[size:], nil
+}
+
+func readVL(data []byte) ([]byte, []byte, error) {
+	if len(data) == 0 || data[0] > 192 {
+		return nil, nil, errInvalidTransaction
+	}
+	return readFixed(data[1:], int(data[0]))
+}
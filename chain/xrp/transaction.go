@@ -0,0 +1,212 @@
+package xrp
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// 未指定 last_ledger_sequence 时，交易在当前账本序号之后多少个账本内有效
+const lastLedgerOffset = 20
+
+// 构建未签名交易：un_sign_tx 为十六进制交易二进制（含 SigningPubKey），
+// sign_hashes 为待签名数据，secp256k1 为 SHA-512Half 哈希，ed25519 为完整签名数据（ed25519 不预先哈希）
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	txJson, err := base64.StdEncoding.DecodeString(req.Base64Tx)
+	if err != nil {
+		log.Error("decode base64 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	var transferTx XrpTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		log.Error("parse json fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "parse json fail",
+		}, nil
+	}
+	payment, err := c.buildPayment(&transferTx)
+	if err != nil {
+		log.Error("build transaction fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   strings.ToUpper(hex.EncodeToString(payment.Serialize())),
+		SignHashes: []string{hex.EncodeToString(signPayload(payment))},
+	}, nil
+}
+
+// 构建签名交易：base64_tx 为 BuildUnSignTransaction 返回的 un_sign_tx，
+// signature 为 64 字节签名（secp256k1 为 r || s，可附带 v），公钥取自交易的 SigningPubKey。
+// 返回的 signed_tx 为十六进制 tx_blob，msg 为交易哈希
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	txBytes, err := hex.DecodeString(req.Base64Tx)
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	payment, err := DeserializePayment(txBytes)
+	if err != nil {
+		log.Error("decode payment fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	if PubKeyToAccountID(payment.SigningPubKey) != payment.Account {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "signing public key does not match account",
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || (len(signature) != 64 && !(!isEd25519(payment.SigningPubKey) && len(signature) == crypto.SignatureLength)) {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	txnSignature, err := encodeSignature(payment, signature[:64])
+	if err != nil {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	payment.TxnSignature = txnSignature
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      payment.Hash(),
+		SignedTx: strings.ToUpper(hex.EncodeToString(payment.Serialize())),
+	}, nil
+}
+
+// 校验发送方地址和目标标签，未指定时查询 sequence、手续费和当前账本序号
+func (c *ChainAdaptor) buildPayment(transferTx *XrpTransferTx) (*Payment, error) {
+	keyType, err := chain.CheckKeyType(transferTx.KeyType, chain.KeyTypeSecp256k1, chain.KeyTypeEd25519)
+	if err != nil {
+		return nil, err
+	}
+	signingPubKey, err := ParsePublicKey(transferTx.PublicKey, keyType)
+	if err != nil {
+		return nil, err
+	}
+	from, err := ParseAddress(transferTx.FromAddress)
+	if err != nil {
+		return nil, errors.New("invalid from address")
+	}
+	if from != PubKeyToAccountID(signingPubKey) {
+		return nil, errors.New("from address does not match public key")
+	}
+	to, err := ParseAddress(transferTx.ToAddress)
+	if err != nil {
+		return nil, errors.New("invalid to address")
+	}
+	amount, err := strconv.ParseUint(transferTx.Amount, 10, 64)
+	if err != nil || amount == 0 || amount > maxDrops {
+		return nil, errors.New("invalid amount")
+	}
+	payment := &Payment{
+		Account:            from,
+		Destination:        to,
+		Amount:             amount,
+		Fee:                transferTx.Fee,
+		Sequence:           transferTx.Sequence,
+		LastLedgerSequence: transferTx.LastLedgerSequence,
+		SigningPubKey:      signingPubKey,
+	}
+	if transferTx.Memo != "" {
+		tag, err := strconv.ParseUint(transferTx.Memo, 10, 32)
+		if err != nil {
+			return nil, errors.New("invalid destination tag")
+		}
+		destinationTag := uint32(tag)
+		payment.DestinationTag = &destinationTag
+	}
+	if payment.Sequence == 0 {
+		info, err := c.XrpClient.GetAccountInfo(from.String())
+		if err != nil {
+			return nil, err
+		}
+		payment.Sequence = info.AccountData.Sequence
+	}
+	if payment.Fee == 0 || payment.LastLedgerSequence == 0 {
+		fee, err := c.XrpClient.GetFee()
+		if err != nil {
+			return nil, err
+		}
+		if payment.Fee == 0 {
+			payment.Fee = feeLevels(fee)[1]
+		}
+		if payment.LastLedgerSequence == 0 {
+			payment.LastLedgerSequence = fee.LedgerCurrentIndex + lastLedgerOffset
+		}
+	}
+	if payment.Fee > maxDrops {
+		return nil, errors.New("invalid fee")
+	}
+	return payment, nil
+}
+
+// 待签名数据，见 BuildUnSignTransaction
+func signPayload(payment *Payment) []byte {
+	if isEd25519(payment.SigningPubKey) {
+		return payment.SigningData()
+	}
+	return payment.SigningHash()
+}
+
+// 验证签名并转换为 TxnSignature：secp256k1 规范化为 low-S 后 DER 编码，ed25519 为原始 64 字节
+func encodeSignature(payment *Payment, signature []byte) ([]byte, error) {
+	if isEd25519(payment.SigningPubKey) {
+		if !ed25519.Verify(payment.SigningPubKey[1:], payment.SigningData(), signature) {
+			return nil, errors.New("signature verification failed")
+		}
+		return signature, nil
+	}
+	signature = normalizeSignature(signature)
+	if !crypto.VerifySignature(payment.SigningPubKey, payment.SigningHash(), signature) {
+		return nil, errors.New("signature verification failed")
+	}
+	var r, s btcec.ModNScalar
+	r.SetByteSlice(signature[:32])
+	s.SetByteSlice(signature[32:])
+	return ecdsa.NewSignature(&r, &s).Serialize(), nil
+}
+
+// XRPL 要求 s 不大于 N/2
+func normalizeSignature(signature []byte) []byte {
+	var s btcec.ModNScalar
+	s.SetByteSlice(signature[32:64])
+	if !s.IsOverHalfOrder() {
+		return signature
+	}
+	s.Negate()
+	normalized := append([]byte{}, signature...)
+	sBytes := s.Bytes()
+	copy(normalized[32:], sBytes[:])
+	return normalized
+}
@@ -0,0 +1,22 @@
+package xrp
+
+// BuildUnSignTransaction 的 base64_tx 解码后的结构
+type XrpTransferTx struct {
+	// 发送方地址，需与 public_key 推导出的地址一致
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	// 单位为 drops
+	Amount string `json:"amount"`
+	// 目标标签（destination tag），十进制 uint32，交易所充值地址需要携带，为空时不设置
+	Memo string `json:"memo"`
+	// 十六进制公钥，secp256k1 为 33 字节压缩公钥，ed25519 为 32 字节公钥或 0xED 开头的 33 字节
+	PublicKey string `json:"public_key"`
+	// 为空时使用 secp256k1
+	KeyType string `json:"key_type"`
+	// 单位为 drops，为 0 时使用节点的 open_ledger_fee
+	Fee uint64 `json:"fee"`
+	// 为 0 时查询账户的下一个 sequence
+	Sequence uint32 `json:"sequence"`
+	// 为 0 时为当前账本序号 + lastLedgerOffset
+	LastLedgerSequence uint32 `json:"last_ledger_sequence"`
+}
@@ -0,0 +1,519 @@
+package xrp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "Ripple"
+
+// XRPL 时间自 2000-01-01 起计算
+const rippleEpoch = 946684800
+
+// 单次 GetBlockByRange 最多查询的账本数，每个账本需要一次请求
+const blockRangeLimit = 100
+
+// GetTxByAddress 默认每页数量
+const defaultPageSize = 20
+
+// 交易执行成功的结果码
+const resultSuccess = "tesSUCCESS"
+
+type ChainAdaptor struct {
+	XrpClient IXrp
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.Xrp
+	xrpClient, err := NewXrpClient(node.RpcUrl, time.Duration(node.TimeOut)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		XrpClient: xrpClient,
+	}, nil
+}
+
+// 验证 是否满足当前节点
+func (c *ChainAdaptor) GetSupportChains(req *account.SupportChainsRequest) (*account.SupportChainsResponse, error) {
+	return &account.SupportChainsResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "Support this chain",
+		Support: true,
+	}, nil
+}
+
+// 传入公钥 转换成经典地址，key_type 支持 secp256k1（默认）和 ed25519
+func (c *ChainAdaptor) ConvertAddress(req *account.ConvertAddressRequest) (*account.ConvertAddressResponse, error) {
+	keyType, err := chain.CheckKeyType(req.KeyType, chain.KeyTypeSecp256k1, chain.KeyTypeEd25519)
+	if err != nil {
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	signingPubKey, err := ParsePublicKey(req.PublicKey, keyType)
+	if err != nil {
+		log.Error("convert address fail", "err", err)
+		return &account.ConvertAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "convert address fail",
+		}, nil
+	}
+	return &account.ConvertAddressResponse{
+		Code:    global_const.ReturnCode_SUCCESS,
+		Msg:     "convert address success",
+		Address: PubKeyToAccountID(signingPubKey).String(),
+	}, nil
+}
+
+// 地址格式验证
+func (c *ChainAdaptor) ValidAddress(req *account.ValidAddressRequest) (*account.ValidAddressResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.ValidAddressResponse{
+			Code:  global_const.ReturnCode_SUCCESS,
+			Msg:   "invalid address",
+			Valid: false,
+		}, nil
+	}
+	return &account.ValidAddressResponse{
+		Code:  global_const.ReturnCode_SUCCESS,
+		Msg:   "valid address",
+		Valid: true,
+	}, nil
+}
+
+// 以账本作为区块，height 为账本序号，为 0 时返回最新已验证账本
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	return c.getBlock(ledgerIndex(req.Height), "get block by number")
+}
+
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	return c.getBlock(req.Hash, "get block by hash")
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByNumber(req *account.BlockHeaderNumberRequest) (*account.BlockHeaderResponse, error) {
+	return c.getBlockHeader(ledgerIndex(req.Height), "get block header by number")
+}
+
+func (c *ChainAdaptor) GetBlockHeaderByHash(req *account.BlockHeaderHashRequest) (*account.BlockHeaderResponse, error) {
+	return c.getBlockHeader(req.Hash, "get block header by hash")
+}
+
+// 获取区间内的账本头
+func (c *ChainAdaptor) GetBlockByRange(req *account.BlockByRangeRequest) (*account.BlockByRangeResponse, error) {
+	start, err := strconv.ParseUint(req.Start, 10, 32)
+	if err != nil {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid start height",
+		}, nil
+	}
+	end, err := strconv.ParseUint(req.End, 10, 32)
+	if err != nil || end < start {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid end height",
+		}, nil
+	}
+	if end-start >= blockRangeLimit {
+		return &account.BlockByRangeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "block range too large",
+		}, nil
+	}
+	var headers []*account.BlockHeader
+	for index := start; index <= end; index++ {
+		ledger, err := c.XrpClient.GetLedger(strconv.FormatUint(index, 10), false)
+		if err != nil {
+			log.Error("get ledger fail", "index", index, "err", err)
+			return &account.BlockByRangeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get block range fail",
+			}, nil
+		}
+		headers = append(headers, toBlockHeader(ledger))
+	}
+	return &account.BlockByRangeResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         "get block range success",
+		BlockHeader: headers,
+	}, nil
+}
+
+// 获取 XRP 余额（drops）和下一个 sequence，未激活的账户返回 0
+func (c *ChainAdaptor) GetAccount(req *account.AccountRequest) (*account.AccountResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	if req.ContractAddress != "" {
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "issued currency is not supported",
+		}, nil
+	}
+	info, err := c.XrpClient.GetAccountInfo(req.Address)
+	if IsNotFound(err) {
+		return &account.AccountResponse{
+			Code:          global_const.ReturnCode_SUCCESS,
+			Msg:           "get account response success",
+			AccountNumber: "0",
+			Sequence:      "0",
+			Balance:       "0",
+		}, nil
+	}
+	if err != nil {
+		log.Error("get account info fail", "address", req.Address, "err", err)
+		return &account.AccountResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get account fail",
+		}, nil
+	}
+	return &account.AccountResponse{
+		Code:          global_const.ReturnCode_SUCCESS,
+		Msg:           "get account response success",
+		AccountNumber: "0",
+		Sequence:      strconv.FormatUint(uint64(info.AccountData.Sequence), 10),
+		Balance:       info.AccountData.Balance,
+	}, nil
+}
+
+// 获取fee，单位 drops。
+// 传入 rawTx（BuildUnSignTransaction 返回的 un_sign_tx）时返回该交易的 Fee；
+// 否则 slow 为基础费用，normal 为进入当前账本所需费用，fast 为近期交易的中位数费用
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	if req.RawTx != "" {
+		txBytes, err := hex.DecodeString(req.RawTx)
+		if err != nil {
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		payment, err := DeserializePayment(txBytes)
+		if err != nil {
+			log.Error("decode payment fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		fee := strconv.FormatUint(payment.Fee, 10)
+		return &account.FeeResponse{
+			Code:      global_const.ReturnCode_SUCCESS,
+			Msg:       "get fee success",
+			SlowFee:   fee,
+			NormalFee: fee,
+			FastFee:   fee,
+		}, nil
+	}
+	fee, err := c.XrpClient.GetFee()
+	if err != nil {
+		log.Error("get fee fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get fee fail",
+		}, nil
+	}
+	levels := feeLevels(fee)
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   strconv.FormatUint(levels[0], 10),
+		NormalFee: strconv.FormatUint(levels[1], 10),
+		FastFee:   strconv.FormatUint(levels[2], 10),
+	}, nil
+}
+
+// 广播交易，raw_tx 为 BuildSignedTransaction 返回的 signed_tx。
+// tes（成功）和 terQUEUED（进入队列）视为广播成功，其他结果码交易不会被执行
+func (c *ChainAdaptor) SendTx(req *account.SendTxRequest) (*account.SendTxResponse, error) {
+	if _, err := hex.DecodeString(req.RawTx); err != nil || req.RawTx == "" {
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	result, err := c.XrpClient.Submit(req.RawTx)
+	if err != nil {
+		log.Error("send tx fail", "err", err)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail",
+		}, nil
+	}
+	if !strings.HasPrefix(result.EngineResult, "tes") && result.EngineResult != "terQUEUED" {
+		log.Error("send tx fail", "engine_result", result.EngineResult, "message", result.EngineResultMessage)
+		return &account.SendTxResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "send tx fail: " + result.EngineResult,
+		}, nil
+	}
+	return &account.SendTxResponse{
+		Code:   global_const.ReturnCode_SUCCESS,
+		Msg:    "send tx success",
+		TxHash: result.TxJson.Hash,
+	}, nil
+}
+
+// 按地址分页查询已验证的交易，按时间倒序，page 从 1 开始，翻页需要逐页请求
+func (c *ChainAdaptor) GetTxByAddress(req *account.TxAddressRequest) (*account.TxAddressResponse, error) {
+	if !ValidateAddress(req.Address) {
+		return &account.TxAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid address",
+		}, nil
+	}
+	pageSize := req.Pagesize
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	var marker json.RawMessage
+	var page *AccountTransactions
+	for i := uint32(1); i <= max(req.Page, 1); i++ {
+		var err error
+		if page, err = c.XrpClient.GetAccountTransactions(req.Address, pageSize, marker); err != nil {
+			log.Error("get account transactions fail", "address", req.Address, "err", err)
+			return &account.TxAddressResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "get tx by address fail",
+			}, nil
+		}
+		if len(page.Marker) == 0 && i < req.Page {
+			page = &AccountTransactions{}
+			break
+		}
+		marker = page.Marker
+	}
+	var txs []*account.TxMessage
+	for _, item := range page.Transactions {
+		tx := item.Tx
+		tx.Meta = item.Meta
+		tx.Validated = item.Validated
+		txs = append(txs, toTxMessage(&tx))
+	}
+	return &account.TxAddressResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get tx by address success",
+		Tx:   txs,
+	}, nil
+}
+
+// 通过交易哈希获取交易，未验证的交易为待确认，memo 为目标标签
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	tx, err := c.XrpClient.GetTransaction(req.Hash)
+	if IsNotFound(err) {
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx:   &account.TxMessage{Hash: req.Hash, Status: account.TxStatus_NotFound},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get transaction fail", "hash", req.Hash, "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get transaction fail",
+		}, nil
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   toTxMessage(tx),
+	}, nil
+}
+
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	return &account.DecodeTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "decode transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) VerifySignedTransaction(req *account.VerifyTransactionRequest) (*account.VerifyTransactionResponse, error) {
+	return &account.VerifyTransactionResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "verify signed transaction is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetExtraData(req *account.ExtraDataRequest) (*account.ExtraDataResponse, error) {
+	return &account.ExtraDataResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "extra data is not supported",
+	}, nil
+}
+
+func (c *ChainAdaptor) GetNftListByAddress(req *account.NftAddressRequest) (*account.NftAddressResponse, error) {
+	return &account.NftAddressResponse{
+		Code: global_const.ReturnCode_ERROR,
+		Msg:  "nft is not supported",
+	}, nil
+}
+
+// 获取账本及其中成功的 XRP 转账，金额为实际到账金额 delivered_amount，避免部分支付伪造充值
+func (c *ChainAdaptor) getBlock(ledgerId string, action string) (*account.BlockResponse, error) {
+	ledger, err := c.XrpClient.GetLedger(ledgerId, true)
+	if err != nil {
+		log.Error(action+" fail", "ledger", ledgerId, "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  action + " fail",
+		}, nil
+	}
+	var blockTxList []*account.BlockInfoTransactionList
+	for i := range ledger.Ledger.Transactions {
+		tx := &ledger.Ledger.Transactions[i]
+		if tx.Meta == nil {
+			tx.Meta = tx.MetaData
+		}
+		tx.Validated = ledger.Validated
+		if txStatus(tx) != account.TxStatus_Success {
+			continue
+		}
+		amount, ok := deliveredDrops(tx)
+		if !ok {
+			continue
+		}
+		blockTxList = append(blockTxList, &account.BlockInfoTransactionList{
+			From:   tx.Account,
+			To:     tx.Destination,
+			Hash:   tx.Hash,
+			Height: uint64(ledger.LedgerIndex),
+			Amount: amount,
+			Memo:   destinationTag(tx),
+		})
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          action + " success",
+		Height:       int64(ledger.LedgerIndex),
+		Hash:         ledgerHash(ledger),
+		Transactions: blockTxList,
+	}, nil
+}
+
+func (c *ChainAdaptor) getBlockHeader(ledgerId string, action string) (*account.BlockHeaderResponse, error) {
+	ledger, err := c.XrpClient.GetLedger(ledgerId, false)
+	if err != nil {
+		log.Error(action+" fail", "ledger", ledgerId, "err", err)
+		return &account.BlockHeaderResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  action + " fail",
+		}, nil
+	}
+	return &account.BlockHeaderResponse{
+		Code:        global_const.ReturnCode_SUCCESS,
+		Msg:         action + " success",
+		BlockHeader: toBlockHeader(ledger),
+	}, nil
+}
+
+// height 为 0 时使用最新已验证账本
+func ledgerIndex(height int64) string {
+	if height == 0 {
+		return "validated"
+	}
+	return strconv.FormatInt(height, 10)
+}
+
+func ledgerHash(ledger *LedgerResult) string {
+	if ledger.LedgerHash != "" {
+		return ledger.LedgerHash
+	}
+	return ledger.Ledger.LedgerHash
+}
+
+// 三档手续费（drops）：基础费用、进入当前账本所需费用、中位数费用，后一档不低于前一档
+func feeLevels(fee *FeeResult) [3]uint64 {
+	var levels [3]uint64
+	for i, value := range []string{fee.Drops.BaseFee, fee.Drops.OpenLedgerFee, fee.Drops.MedianFee} {
+		levels[i], _ = strconv.ParseUint(value, 10, 64)
+		if i > 0 {
+			levels[i] = max(levels[i], levels[i-1])
+		}
+	}
+	return levels
+}
+
+// 只统计 XRP 的 Payment，代币的 delivered_amount 为对象
+func deliveredDrops(tx *Transaction) (string, bool) {
+	if tx.TransactionType != "Payment" || tx.Meta == nil {
+		return "", false
+	}
+	var drops string
+	if json.Unmarshal(tx.Meta.DeliveredAmount, &drops) != nil {
+		return "", false
+	}
+	if _, err := strconv.ParseUint(drops, 10, 64); err != nil {
+		return "", false
+	}
+	return drops, true
+}
+
+func destinationTag(tx *Transaction) string {
+	if tx.DestinationTag == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*tx.DestinationTag), 10)
+}
+
+func toTxMessage(tx *Transaction) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:   tx.Hash,
+		From:   tx.Account,
+		To:     tx.Destination,
+		Fee:    tx.Fee,
+		Status: txStatus(tx),
+		Memo:   destinationTag(tx),
+	}
+	if tx.LedgerIndex != 0 {
+		txMessage.Height = strconv.FormatUint(uint64(tx.LedgerIndex), 10)
+	}
+	if tx.Date != 0 {
+		txMessage.Datetime = strconv.FormatUint(tx.Date+rippleEpoch, 10)
+	}
+	if amount, ok := deliveredDrops(tx); ok {
+		txMessage.Value = amount
+	} else if tx.Amount != nil {
+		txMessage.Data = string(tx.Amount)
+	}
+	return txMessage
+}
+
+// 未验证的交易为待确认，tec 等结果码表示交易已上链但执行失败
+func txStatus(tx *Transaction) account.TxStatus {
+	switch {
+	case !tx.Validated || tx.Meta == nil:
+		return account.TxStatus_Pending
+	case tx.Meta.TransactionResult == resultSuccess:
+		return account.TxStatus_Success
+	default:
+		return account.TxStatus_Failed
+	}
+}
+
+func toBlockHeader(ledger *LedgerResult) *account.BlockHeader {
+	header := &account.BlockHeader{
+		Hash:       ledgerHash(ledger),
+		ParentHash: ledger.Ledger.ParentHash,
+		Number:     strconv.FormatUint(uint64(ledger.LedgerIndex), 10),
+	}
+	if ledger.Ledger.CloseTime != 0 {
+		header.Time = ledger.Ledger.CloseTime + rippleEpoch
+	}
+	return header
+}
@@ -0,0 +1,282 @@
+package xrp
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"chain-account/chain"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+// rippled 创世账户（masterpassphrase）和 ripple-keypairs 的 ed25519 测试公钥及对应地址
+const (
+	genesisPublicKey = "0330E7FC9D56BB25D6893BA3F317AE5BCF33B3291BD63DB32654A313222F7FD020"
+	genesisAddress   = "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh"
+	ed25519PublicKey = "ED01FA53FA5A7E77798F882ECE20B1ABC00BB358A9E55A202D0D0676BD0CE37A63"
+	ed25519Address   = "rLUEXYuLiQptky37CqLcm9USQpPiz5rkpD"
+)
+
+// 模拟 rippled 节点
+type fakeXrpNode struct {
+	IXrp
+	ledger *LedgerResult
+}
+
+func (f *fakeXrpNode) GetAccountInfo(address string) (*AccountInfo, error) {
+	info := new(AccountInfo)
+	info.AccountData.Account = address
+	info.AccountData.Sequence = 7
+	return info, nil
+}
+
+func (f *fakeXrpNode) GetFee() (*FeeResult, error) {
+	fee := &FeeResult{LedgerCurrentIndex: 1000}
+	fee.Drops.BaseFee = "10"
+	fee.Drops.OpenLedgerFee = "12"
+	fee.Drops.MedianFee = "5000"
+	return fee, nil
+}
+
+func (f *fakeXrpNode) GetLedger(ledger string, withTransactions bool) (*LedgerResult, error) {
+	return f.ledger, nil
+}
+
+func buildUnsigned(t *testing.T, adaptor *ChainAdaptor, transferTx XrpTransferTx) (*Payment, []byte) {
+	txJson, _ := json.Marshal(transferTx)
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build unsigned transaction fail: %s", resp.Msg)
+	}
+	txBytes, _ := hex.DecodeString(resp.UnSignTx)
+	payment, err := DeserializePayment(txBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(payment.Serialize(), txBytes) {
+		t.Fatal("expected deserialized payment to serialize to the same bytes")
+	}
+	payload, _ := hex.DecodeString(resp.SignHashes[0])
+	return payment, payload
+}
+
+func Test_ConvertAddress(t *testing.T) {
+	adaptor := &ChainAdaptor{}
+	resp, _ := adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: genesisPublicKey})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Address != genesisAddress {
+		t.Fatalf("unexpected address %s", resp.Address)
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: ed25519PublicKey[2:], KeyType: chain.KeyTypeEd25519})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Address != ed25519Address {
+		t.Fatalf("unexpected ed25519 address %s", resp.Address)
+	}
+	resp, _ = adaptor.ConvertAddress(&account.ConvertAddressRequest{PublicKey: genesisPublicKey, KeyType: chain.KeyTypeSr25519})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected sr25519 key type to be rejected")
+	}
+}
+
+func Test_ValidAddress(t *testing.T) {
+	for address, valid := range map[string]bool{
+		genesisAddress:                                    true,
+		ed25519Address:                                    true,
+		"rrrrrrrrrrrrrrrrrrrrrhoLvTp":                     true,
+		"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTi":              false,
+		"1Hb9CJAWyB4rj91VRWn96DkukG4bwdtyTh":              false,
+		"XVLhHMPHU98es4dbozjVtdWzVrDjtV8AqEL4xcZj5whKbmc": false,
+	} {
+		if ValidateAddress(address) != valid {
+			t.Fatalf("expected %s valid=%v", address, valid)
+		}
+	}
+}
+
+func Test_PaymentSerialize(t *testing.T) {
+	from, _ := ParseAddress(genesisAddress)
+	to, _ := ParseAddress(ed25519Address)
+	signingPubKey, _ := hex.DecodeString(genesisPublicKey)
+	tag := uint32(12345)
+	payment := &Payment{
+		Account:            from,
+		Destination:        to,
+		Amount:             1_000_000,
+		Fee:                12,
+		Sequence:           1,
+		DestinationTag:     &tag,
+		LastLedgerSequence: 1020,
+		SigningPubKey:      signingPubKey,
+	}
+	expected := "120000" + "2200000000" + "2400000001" + "2E00003039" + "201B000003FC" +
+		"6140000000000F4240" + "68400000000000000C" +
+		"7321" + genesisPublicKey +
+		"8114" + strings.ToUpper(hex.EncodeToString(from[:])) +
+		"8314" + strings.ToUpper(hex.EncodeToString(to[:]))
+	if blob := strings.ToUpper(hex.EncodeToString(payment.Serialize())); blob != expected {
+		t.Fatalf("unexpected blob %s", blob)
+	}
+	if !bytes.HasPrefix(payment.SigningData(), []byte("STX\x00")) {
+		t.Fatal("expected signing data to start with the STX prefix")
+	}
+}
+
+func Test_BuildTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pubKey := hex.EncodeToString(crypto.CompressPubkey(&key.PublicKey))
+	signingPubKey, _ := ParsePublicKey(pubKey, chain.KeyTypeSecp256k1)
+	from := PubKeyToAccountID(signingPubKey).String()
+	adaptor := &ChainAdaptor{XrpClient: &fakeXrpNode{}}
+
+	payment, hash := buildUnsigned(t, adaptor, XrpTransferTx{
+		FromAddress: from,
+		ToAddress:   genesisAddress,
+		Amount:      "25000000",
+		Memo:        "104857",
+		PublicKey:   pubKey,
+	})
+	if payment.Sequence != 7 || payment.Fee != 12 || payment.LastLedgerSequence != 1000+lastLedgerOffset {
+		t.Fatalf("unexpected sequence %d fee %d last ledger %d", payment.Sequence, payment.Fee, payment.LastLedgerSequence)
+	}
+	if payment.DestinationTag == nil || *payment.DestinationTag != 104857 {
+		t.Fatal("expected destination tag from memo")
+	}
+	if !bytes.Equal(hash, payment.SigningHash()) {
+		t.Fatal("expected sign hash to be the SHA-512Half of the signing data")
+	}
+
+	signature, _ := crypto.Sign(hash, key)
+	unsignedTx := strings.ToUpper(hex.EncodeToString(payment.Serialize()))
+	resp, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  unsignedTx,
+		Signature: hex.EncodeToString(signature),
+	})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", resp.Msg)
+	}
+	signedBytes, _ := hex.DecodeString(resp.SignedTx)
+	signed, err := DeserializePayment(signedBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := ecdsa.ParseDERSignature(signed.TxnSignature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	btcecPubKey, _ := btcec.ParsePubKey(signingPubKey)
+	if !der.Verify(hash, btcecPubKey) {
+		t.Fatal("expected DER signature to verify")
+	}
+	if resp.Msg != signed.Hash() || len(resp.Msg) != 64 {
+		t.Fatalf("unexpected tx hash %s", resp.Msg)
+	}
+
+	signature[0] ^= 1
+	resp, _ = adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  unsignedTx,
+		Signature: hex.EncodeToString(signature),
+	})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected tampered signature to be rejected")
+	}
+}
+
+func Test_BuildTransactionEd25519(t *testing.T) {
+	pubKey, privKey, _ := ed25519.GenerateKey(nil)
+	signingPubKey, _ := ParsePublicKey(hex.EncodeToString(pubKey), chain.KeyTypeEd25519)
+	adaptor := &ChainAdaptor{XrpClient: &fakeXrpNode{}}
+
+	payment, payload := buildUnsigned(t, adaptor, XrpTransferTx{
+		FromAddress: PubKeyToAccountID(signingPubKey).String(),
+		ToAddress:   genesisAddress,
+		Amount:      "1",
+		PublicKey:   hex.EncodeToString(pubKey),
+		KeyType:     chain.KeyTypeEd25519,
+		Fee:         15,
+		Sequence:    3,
+	})
+	if payment.DestinationTag != nil || payment.Fee != 15 || payment.Sequence != 3 {
+		t.Fatal("unexpected payment fields")
+	}
+	if !bytes.Equal(payload, payment.SigningData()) {
+		t.Fatal("expected ed25519 to sign the full signing data")
+	}
+	resp, _ := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  hex.EncodeToString(payment.Serialize()),
+		Signature: hex.EncodeToString(ed25519.Sign(privKey, payload)),
+	})
+	if resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("build signed transaction fail: %s", resp.Msg)
+	}
+}
+
+func Test_BuildTransactionInvalidTag(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pubKey := hex.EncodeToString(crypto.CompressPubkey(&key.PublicKey))
+	signingPubKey, _ := ParsePublicKey(pubKey, chain.KeyTypeSecp256k1)
+	adaptor := &ChainAdaptor{XrpClient: &fakeXrpNode{}}
+	txJson, _ := json.Marshal(XrpTransferTx{
+		FromAddress: PubKeyToAccountID(signingPubKey).String(),
+		ToAddress:   genesisAddress,
+		Amount:      "1",
+		Memo:        "4294967296",
+		PublicKey:   pubKey,
+	})
+	resp, _ := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{Base64Tx: base64.StdEncoding.EncodeToString(txJson)})
+	if resp.Code != global_const.ReturnCode_ERROR {
+		t.Fatal("expected destination tag overflow to be rejected")
+	}
+}
+
+func Test_GetBlockByNumber(t *testing.T) {
+	tag := uint32(42)
+	ledger := &LedgerResult{LedgerIndex: 90000000, LedgerHash: strings.Repeat("AB", 32), Validated: true}
+	ledger.Ledger.Transactions = []Transaction{
+		{
+			TransactionType: "Payment", Account: genesisAddress, Destination: ed25519Address, DestinationTag: &tag, Hash: "H1",
+			Amount:   json.RawMessage(`"1000000"`),
+			MetaData: &TransactionMeta{TransactionResult: resultSuccess, DeliveredAmount: json.RawMessage(`"1000000"`)},
+		},
+		// 部分支付按实际到账金额统计
+		{
+			TransactionType: "Payment", Account: genesisAddress, Destination: ed25519Address, Hash: "H2",
+			Amount:   json.RawMessage(`"9000000"`),
+			MetaData: &TransactionMeta{TransactionResult: resultSuccess, DeliveredAmount: json.RawMessage(`"1"`)},
+		},
+		{
+			TransactionType: "Payment", Account: genesisAddress, Destination: ed25519Address, Hash: "H3",
+			MetaData: &TransactionMeta{TransactionResult: "tecUNFUNDED_PAYMENT", DeliveredAmount: json.RawMessage(`"5"`)},
+		},
+		{
+			TransactionType: "Payment", Account: genesisAddress, Destination: ed25519Address, Hash: "H4",
+			MetaData: &TransactionMeta{TransactionResult: resultSuccess, DeliveredAmount: json.RawMessage(`{"currency":"USD","issuer":"` + genesisAddress + `","value":"1"}`)},
+		},
+		{TransactionType: "OfferCreate", Account: genesisAddress, Hash: "H5", MetaData: &TransactionMeta{TransactionResult: resultSuccess}},
+	}
+	adaptor := &ChainAdaptor{XrpClient: &fakeXrpNode{ledger: ledger}}
+	resp, _ := adaptor.GetBlockByNumber(&account.BlockNumberRequest{Height: 90000000})
+	if resp.Code != global_const.ReturnCode_SUCCESS || len(resp.Transactions) != 2 {
+		t.Fatalf("unexpected transactions %v", resp.Transactions)
+	}
+	if resp.Transactions[0].Memo != "42" || resp.Transactions[0].Amount != "1000000" {
+		t.Fatalf("unexpected transfer %v", resp.Transactions[0])
+	}
+	if resp.Transactions[1].Memo != "" || resp.Transactions[1].Amount != "1" {
+		t.Fatalf("expected delivered amount for partial payment, got %v", resp.Transactions[1])
+	}
+}
+
+func Test_GetFee(t *testing.T) {
+	adaptor := &ChainAdaptor{XrpClient: &fakeXrpNode{}}
+	resp, _ := adaptor.GetFee(&account.FeeRequest{})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.SlowFee != "10" || resp.NormalFee != "12" || resp.FastFee != "5000" {
+		t.Fatalf("unexpected fee %v", resp)
+	}
+}
@@ -0,0 +1,259 @@
+package xrp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// rippled 返回的错误，如 txnNotFound、actNotFound、lgrNotFound
+type RpcError struct {
+	Code    string `json:"error"`
+	Message string `json:"error_message"`
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("rpc error %s: %s", e.Code, e.Message)
+}
+
+// 交易、账户或账本不存在
+func IsNotFound(err error) bool {
+	var rpcErr *RpcError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	switch rpcErr.Code {
+	case "txnNotFound", "actNotFound", "lgrNotFound":
+		return true
+	default:
+		return false
+	}
+}
+
+type AccountInfo struct {
+	AccountData struct {
+		Account  string `json:"Account"`
+		Balance  string `json:"Balance"`
+		Sequence uint32 `json:"Sequence"`
+	} `json:"account_data"`
+	LedgerCurrentIndex uint32 `json:"ledger_current_index"`
+}
+
+// 手续费，单位 drops
+type FeeResult struct {
+	Drops struct {
+		BaseFee       string `json:"base_fee"`
+		MedianFee     string `json:"median_fee"`
+		MinimumFee    string `json:"minimum_fee"`
+		OpenLedgerFee string `json:"open_ledger_fee"`
+	} `json:"drops"`
+	LedgerCurrentIndex uint32 `json:"ledger_current_index"`
+}
+
+type SubmitResult struct {
+	EngineResult        string `json:"engine_result"`
+	EngineResultMessage string `json:"engine_result_message"`
+	TxJson              struct {
+		Hash string `json:"hash"`
+	} `json:"tx_json"`
+}
+
+type TransactionMeta struct {
+	TransactionResult string `json:"TransactionResult"`
+	// 实际到账金额，XRP 为 drops 字符串，代币为对象；部分支付时小于 Amount
+	DeliveredAmount json.RawMessage `json:"delivered_amount"`
+}
+
+type Transaction struct {
+	TransactionType string          `json:"TransactionType"`
+	Account         string          `json:"Account"`
+	Destination     string          `json:"Destination"`
+	Amount          json.RawMessage `json:"Amount"`
+	Fee             string          `json:"Fee"`
+	Sequence        uint32          `json:"Sequence"`
+	DestinationTag  *uint32         `json:"DestinationTag"`
+	Hash            string          `json:"hash"`
+	LedgerIndex     uint32          `json:"ledger_index"`
+	// 自 2000-01-01 起的秒数
+	Date      uint64 `json:"date"`
+	Validated bool   `json:"validated"`
+	// tx 方法返回 meta，账本中展开的交易为 metaData
+	Meta     *TransactionMeta `json:"meta"`
+	MetaData *TransactionMeta `json:"metaData"`
+}
+
+type Ledger struct {
+	LedgerHash string `json:"ledger_hash"`
+	ParentHash string `json:"parent_hash"`
+	// 自 2000-01-01 起的秒数
+	CloseTime    uint64        `json:"close_time"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+type LedgerResult struct {
+	Ledger      Ledger `json:"ledger"`
+	LedgerHash  string `json:"ledger_hash"`
+	LedgerIndex uint32 `json:"ledger_index"`
+	Validated   bool   `json:"validated"`
+}
+
+type AccountTransaction struct {
+	Tx        Transaction      `json:"tx"`
+	Meta      *TransactionMeta `json:"meta"`
+	Validated bool             `json:"validated"`
+}
+
+type AccountTransactions struct {
+	Transactions []AccountTransaction `json:"transactions"`
+	// 下一页的分页标记，不透明对象
+	Marker json.RawMessage `json:"marker"`
+}
+
+type rpcRequest struct {
+	Method string `json:"method"`
+	Params []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+}
+
+// 定义 rippled 节点接口
+type IXrp interface {
+	GetAccountInfo(address string) (*AccountInfo, error)
+	GetFee() (*FeeResult, error)
+	Submit(txBlob string) (*SubmitResult, error)
+	GetTransaction(hash string) (*Transaction, error)
+	// ledger 为账本序号、账本哈希或 validated
+	GetLedger(ledger string, withTransactions bool) (*LedgerResult, error)
+	GetAccountTransactions(address string, limit uint32, marker json.RawMessage) (*AccountTransactions, error)
+}
+
+// 定义 rippled JSON-RPC 客户端
+type XrpClient struct {
+	url    string
+	client *http.Client
+}
+
+func NewXrpClient(rpcUrl string, timeout time.Duration) (IXrp, error) {
+	if rpcUrl == "" {
+		return nil, fmt.Errorf("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	return &XrpClient{
+		url:    rpcUrl,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// 调用 JSON-RPC 方法，rippled 的错误放在 result 中，status 为 error
+func (x *XrpClient) call(result any, method string, params map[string]any) error {
+	body, err := json.Marshal(&rpcRequest{Method: method, Params: []any{params}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, x.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("call %s fail, status %d: %s", method, resp.StatusCode, string(respBody))
+	}
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return err
+	}
+	var status struct {
+		Status string `json:"status"`
+		RpcError
+	}
+	if err := json.Unmarshal(rpcResp.Result, &status); err != nil {
+		return err
+	}
+	if status.Status == "error" || status.Code != "" {
+		return &status.RpcError
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// 获取账户余额和下一个 sequence，基于当前未关闭的账本
+func (x *XrpClient) GetAccountInfo(address string) (*AccountInfo, error) {
+	info := new(AccountInfo)
+	if err := x.call(info, "account_info", map[string]any{"account": address, "ledger_index": "current"}); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (x *XrpClient) GetFee() (*FeeResult, error) {
+	fee := new(FeeResult)
+	if err := x.call(fee, "fee", map[string]any{}); err != nil {
+		return nil, err
+	}
+	return fee, nil
+}
+
+// 提交签名交易，tx_blob 为十六进制交易二进制
+func (x *XrpClient) Submit(txBlob string) (*SubmitResult, error) {
+	result := new(SubmitResult)
+	if err := x.call(result, "submit", map[string]any{"tx_blob": txBlob}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (x *XrpClient) GetTransaction(hash string) (*Transaction, error) {
+	tx := new(Transaction)
+	if err := x.call(tx, "tx", map[string]any{"transaction": hash}); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (x *XrpClient) GetLedger(ledger string, withTransactions bool) (*LedgerResult, error) {
+	params := map[string]any{"transactions": withTransactions, "expand": withTransactions}
+	if index, err := strconv.ParseUint(ledger, 10, 32); err == nil {
+		params["ledger_index"] = index
+	} else if len(ledger) == 64 {
+		params["ledger_hash"] = ledger
+	} else {
+		params["ledger_index"] = ledger
+	}
+	result := new(LedgerResult)
+	if err := x.call(result, "ledger", params); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 按时间倒序分页查询账户相关的已验证交易
+func (x *XrpClient) GetAccountTransactions(address string, limit uint32, marker json.RawMessage) (*AccountTransactions, error) {
+	params := map[string]any{"account": address, "limit": limit}
+	if len(marker) > 0 {
+		params["marker"] = marker
+	}
+	result := new(AccountTransactions)
+	if err := x.call(result, "account_tx", params); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
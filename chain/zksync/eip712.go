@@ -0,0 +1,205 @@
+package zksync
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// zkSync EIP-712 交易类型
+const Eip712TxType = 0x71
+
+// 未指定时每字节 pubdata 的 gas 上限
+const DefaultGasPerPubdata = 50000
+
+var (
+	eip712DomainTypeHash = crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId)"))
+	eip712TxTypeHash     = crypto.Keccak256([]byte("Transaction(uint256 txType,uint256 from,uint256 to,uint256 gasLimit,uint256 gasPerPubdataByteLimit," +
+		"uint256 maxFeePerGas,uint256 maxPriorityFeePerGas,uint256 paymaster,uint256 nonce,uint256 value,bytes data,bytes32[] factoryDeps,bytes paymasterInput)"))
+)
+
+// zkSync EIP-712 交易，签名放在 customSignature 中
+type Eip712Tx struct {
+	ChainId         *big.Int
+	Nonce           uint64
+	GasTipCap       *big.Int
+	GasFeeCap       *big.Int
+	Gas             uint64
+	To              *common.Address
+	Value           *big.Int
+	Data            []byte
+	From            common.Address
+	GasPerPubdata   uint64
+	FactoryDeps     [][]byte
+	CustomSignature []byte
+	Paymaster       *common.Address
+	PaymasterInput  []byte
+}
+
+// RLP 编码的字段顺序，未签名时 v、r、s 位置依次为 chainId、空、空
+type eip712Rlp struct {
+	Nonce           uint64
+	GasTipCap       *big.Int
+	GasFeeCap       *big.Int
+	Gas             uint64
+	To              []byte
+	Value           *big.Int
+	Data            []byte
+	V               []byte
+	R               []byte
+	S               []byte
+	ChainId         *big.Int
+	From            common.Address
+	GasPerPubdata   uint64
+	FactoryDeps     [][]byte
+	CustomSignature []byte
+	PaymasterParams [][]byte
+}
+
+// EIP-712 签名哈希：keccak256(0x1901 || domainSeparator || structHash)，domain 为 {name: zkSync, version: 2, chainId}
+func (tx *Eip712Tx) SigningHash() common.Hash {
+	domainSeparator := crypto.Keccak256(
+		eip712DomainTypeHash,
+		crypto.Keccak256([]byte("zkSync")),
+		crypto.Keccak256([]byte("2")),
+		uint256(tx.ChainId),
+	)
+	var factoryDeps []byte
+	for _, dep := range tx.FactoryDeps {
+		depHash := hashBytecode(dep)
+		factoryDeps = append(factoryDeps, depHash[:]...)
+	}
+	structHash := crypto.Keccak256(
+		eip712TxTypeHash,
+		uint256(big.NewInt(Eip712TxType)),
+		common.LeftPadBytes(tx.From.Bytes(), 32),
+		addressWord(tx.To),
+		uint256(new(big.Int).SetUint64(tx.Gas)),
+		uint256(new(big.Int).SetUint64(tx.GasPerPubdata)),
+		uint256(tx.GasFeeCap),
+		uint256(tx.GasTipCap),
+		addressWord(tx.Paymaster),
+		uint256(new(big.Int).SetUint64(tx.Nonce)),
+		uint256(tx.Value),
+		crypto.Keccak256(tx.Data),
+		crypto.Keccak256(factoryDeps),
+		crypto.Keccak256(tx.PaymasterInput),
+	)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domainSeparator, structHash)
+}
+
+// 交易哈希：keccak256(signingHash || keccak256(customSignature))
+func (tx *Eip712Tx) Hash() common.Hash {
+	return crypto.Keccak256Hash(tx.SigningHash().Bytes(), crypto.Keccak256(tx.CustomSignature))
+}
+
+// 序列化为 0x71 || RLP(fields)
+func (tx *Eip712Tx) MarshalBinary() ([]byte, error) {
+	if tx.ChainId == nil || tx.ChainId.Sign() <= 0 {
+		return nil, errors.New("invalid chain id")
+	}
+	item := &eip712Rlp{
+		Nonce:           tx.Nonce,
+		GasTipCap:       bigOrZero(tx.GasTipCap),
+		GasFeeCap:       bigOrZero(tx.GasFeeCap),
+		Gas:             tx.Gas,
+		Value:           bigOrZero(tx.Value),
+		Data:            tx.Data,
+		V:               tx.ChainId.Bytes(),
+		ChainId:         tx.ChainId,
+		From:            tx.From,
+		GasPerPubdata:   tx.GasPerPubdata,
+		FactoryDeps:     tx.FactoryDeps,
+		CustomSignature: tx.CustomSignature,
+		PaymasterParams: [][]byte{},
+	}
+	if tx.To != nil {
+		item.To = tx.To.Bytes()
+	}
+	if item.FactoryDeps == nil {
+		item.FactoryDeps = [][]byte{}
+	}
+	if tx.Paymaster != nil {
+		item.PaymasterParams = [][]byte{tx.Paymaster.Bytes(), tx.PaymasterInput}
+	}
+	payload, err := rlp.EncodeToBytes(item)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{Eip712TxType}, payload...), nil
+}
+
+// 解码 0x71 交易
+func UnmarshalEip712Tx(raw []byte) (*Eip712Tx, error) {
+	if len(raw) == 0 || raw[0] != Eip712TxType {
+		return nil, errors.New("not an eip712 transaction")
+	}
+	var item eip712Rlp
+	if err := rlp.DecodeBytes(raw[1:], &item); err != nil {
+		return nil, err
+	}
+	tx := &Eip712Tx{
+		ChainId:         item.ChainId,
+		Nonce:           item.Nonce,
+		GasTipCap:       item.GasTipCap,
+		GasFeeCap:       item.GasFeeCap,
+		Gas:             item.Gas,
+		Value:           item.Value,
+		Data:            item.Data,
+		From:            item.From,
+		GasPerPubdata:   item.GasPerPubdata,
+		FactoryDeps:     item.FactoryDeps,
+		CustomSignature: item.CustomSignature,
+	}
+	switch len(item.To) {
+	case 0:
+	case common.AddressLength:
+		to := common.BytesToAddress(item.To)
+		tx.To = &to
+	default:
+		return nil, errors.New("invalid to address")
+	}
+	switch len(item.PaymasterParams) {
+	case 0:
+	case 2:
+		if len(item.PaymasterParams[0]) != common.AddressLength {
+			return nil, errors.New("invalid paymaster address")
+		}
+		paymaster := common.BytesToAddress(item.PaymasterParams[0])
+		tx.Paymaster, tx.PaymasterInput = &paymaster, item.PaymasterParams[1]
+	default:
+		return nil, errors.New("invalid paymaster params")
+	}
+	return tx, nil
+}
+
+// zkSync 合约字节码哈希：版本号 1、0、字长（2 字节大端）后接 sha256 的后 28 字节
+func hashBytecode(bytecode []byte) common.Hash {
+	hash := common.Hash(sha256.Sum256(bytecode))
+	hash[0], hash[1] = 1, 0
+	binary.BigEndian.PutUint16(hash[2:4], uint16(len(bytecode)/32))
+	return hash
+}
+
+func addressWord(addr *common.Address) []byte {
+	if addr == nil {
+		return make([]byte, 32)
+	}
+	return common.LeftPadBytes(addr.Bytes(), 32)
+}
+
+func uint256(v *big.Int) []byte {
+	return common.LeftPadBytes(bigOrZero(v).Bytes(), 32)
+}
+
+func bigOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
@@ -0,0 +1,126 @@
+package zksync
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// L1→L2 优先级交易类型
+const PriorityTxType = 0xFF
+
+// BuildUnSignTransaction 和 BuildSignedTransaction 的 base64_tx 解码后的结构
+type ZkSyncTransferTx struct {
+	ChainId     string `json:"chain_id"`
+	Nonce       uint64 `json:"nonce"`
+	FromAddress string `json:"from_address"`
+	ToAddress   string `json:"to_address"`
+	// gas_limit 为 0 时通过 zks_estimateFee 估算 gas 参数
+	GasLimit             uint64 `json:"gas_limit"`
+	MaxFeePerGas         string `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas string `json:"max_priority_fee_per_gas"`
+	// 为 0 时使用 50000
+	GasPerPubdata uint64 `json:"gas_per_pubdata"`
+	// eth/erc20 amount
+	Amount string `json:"amount"`
+	// erc20 合约地址，为空时为 ETH 转账
+	ContractAddress string `json:"contract_address"`
+	// 原始调用数据（0x 开头），设置后按原样发送到 to_address，忽略 contract_address
+	Data string `json:"data,omitempty"`
+	// paymaster 合约及其参数（0x 开头），为空时由发送方支付手续费
+	Paymaster      string `json:"paymaster,omitempty"`
+	PaymasterInput string `json:"paymaster_input,omitempty"`
+}
+
+// zks_estimateFee 的请求
+type CallRequest struct {
+	From       common.Address  `json:"from"`
+	To         *common.Address `json:"to,omitempty"`
+	Value      *hexutil.Big    `json:"value,omitempty"`
+	Data       hexutil.Bytes   `json:"data,omitempty"`
+	Eip712Meta *Eip712Meta     `json:"eip712Meta,omitempty"`
+}
+
+type Eip712Meta struct {
+	GasPerPubdata   *hexutil.Big     `json:"gasPerPubdata,omitempty"`
+	PaymasterParams *PaymasterParams `json:"paymasterParams,omitempty"`
+}
+
+type PaymasterParams struct {
+	Paymaster      common.Address `json:"paymaster"`
+	PaymasterInput ByteArray      `json:"paymasterInput"`
+}
+
+// 节点要求字节数组编码为数字数组，而不是十六进制字符串
+type ByteArray []byte
+
+func (b ByteArray) MarshalJSON() ([]byte, error) {
+	items := make([]uint16, len(b))
+	for i, v := range b {
+		items[i] = uint16(v)
+	}
+	return json.Marshal(items)
+}
+
+// zks_estimateFee 的返回值
+type Fee struct {
+	GasLimit             *hexutil.Big `json:"gas_limit"`
+	GasPerPubdataLimit   *hexutil.Big `json:"gas_per_pubdata_limit"`
+	MaxFeePerGas         *hexutil.Big `json:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *hexutil.Big `json:"max_priority_fee_per_gas"`
+}
+
+// 节点返回的交易，0x71 和 0xff 交易无法按以太坊标准交易解析
+type RpcTransaction struct {
+	Type                 hexutil.Uint64  `json:"type"`
+	Hash                 common.Hash     `json:"hash"`
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Value                *hexutil.Big    `json:"value"`
+	Input                hexutil.Bytes   `json:"input"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	BlockNumber          *hexutil.Big    `json:"blockNumber"`
+	L1BatchNumber        *hexutil.Big    `json:"l1BatchNumber"`
+}
+
+// 包含完整交易的区块
+type RpcBlock struct {
+	Hash         common.Hash      `json:"hash"`
+	Number       hexutil.Uint64   `json:"number"`
+	BaseFee      string           `json:"baseFeePerGas"`
+	Transactions []RpcTransaction `json:"transactions"`
+}
+
+// 交易收据，gasUsed 已扣除退款
+type RpcReceipt struct {
+	Status            hexutil.Uint64  `json:"status"`
+	BlockNumber       *hexutil.Big    `json:"blockNumber"`
+	TransactionIndex  hexutil.Uint64  `json:"transactionIndex"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+	EffectiveGasPrice *hexutil.Big    `json:"effectiveGasPrice"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+}
+
+// DecodeTransaction 返回的 base64_tx 解码后的结构
+type DecodedTx struct {
+	Type            uint8  `json:"type"`
+	Hash            string `json:"hash"`
+	ChainId         string `json:"chain_id"`
+	Nonce           uint64 `json:"nonce"`
+	From            string `json:"from"`
+	To              string `json:"to,omitempty"`
+	Value           string `json:"value"`
+	Gas             uint64 `json:"gas"`
+	GasFeeCap       string `json:"gas_fee_cap"`
+	GasTipCap       string `json:"gas_tip_cap"`
+	GasPerPubdata   uint64 `json:"gas_per_pubdata"`
+	Data            string `json:"data,omitempty"`
+	FactoryDeps     int    `json:"factory_deps,omitempty"`
+	Paymaster       string `json:"paymaster,omitempty"`
+	PaymasterInput  string `json:"paymaster_input,omitempty"`
+	CustomSignature string `json:"custom_signature,omitempty"`
+}
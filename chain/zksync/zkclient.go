@@ -0,0 +1,116 @@
+package zksync
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"chain-account/chain/ethereum"
+	"chain-account/config"
+)
+
+const defaultRequestTimeout = 10 * time.Second
+
+// zkSync 特有的节点接口，交易按 zkSync 格式解析以支持 0x71 和 0xff 交易
+type IZkSync interface {
+	EstimateFee(req *CallRequest) (*Fee, error)
+	BlockByNumber(*big.Int) (*RpcBlock, error)
+	BlockByHash(common.Hash) (*RpcBlock, error)
+	TxByHash(common.Hash) (*RpcTransaction, error)
+	TxReceiptByHash(common.Hash) (*RpcReceipt, error)
+	Close()
+}
+
+type ZkClient struct {
+	rpc     ethereum.IRpc
+	timeout time.Duration
+}
+
+// 与 EthClient 使用同样的 RPC 连接栈（重试、熔断、请求合并等）
+func NewZkClient(rpcUrl string, timeout time.Duration, conf config.Rpc) (IZkSync, error) {
+	if rpcUrl == "" {
+		return nil, errors.New("empty rpc url")
+	}
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	client, err := ethereum.DialRpc(context.Background(), rpcUrl, conf)
+	if err != nil {
+		return nil, err
+	}
+	return &ZkClient{rpc: client, timeout: timeout}, nil
+}
+
+// zks_estimateFee 估算 gas limit、gas 价格和 gasPerPubdata
+func (c *ZkClient) EstimateFee(req *CallRequest) (*Fee, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var fee *Fee
+	if err := c.rpc.CallContext(ctx, &fee, "zks_estimateFee", req); err != nil {
+		return nil, err
+	} else if fee == nil || fee.GasLimit == nil || fee.MaxFeePerGas == nil {
+		return nil, errors.New("invalid estimate fee result")
+	}
+	return fee, nil
+}
+
+func (c *ZkClient) BlockByNumber(number *big.Int) (*RpcBlock, error) {
+	arg := "latest"
+	if number != nil {
+		arg = hexutil.EncodeBig(number)
+	}
+	return c.getBlock("eth_getBlockByNumber", arg)
+}
+
+func (c *ZkClient) BlockByHash(hash common.Hash) (*RpcBlock, error) {
+	return c.getBlock("eth_getBlockByHash", hash)
+}
+
+func (c *ZkClient) getBlock(method string, arg interface{}) (*RpcBlock, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var block *RpcBlock
+	if err := c.rpc.CallContext(ctx, &block, method, arg, true); err != nil {
+		return nil, err
+	} else if block == nil {
+		return nil, geth.NotFound
+	}
+	return block, nil
+}
+
+func (c *ZkClient) TxByHash(hash common.Hash) (*RpcTransaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var tx *RpcTransaction
+	if err := c.rpc.CallContext(ctx, &tx, "eth_getTransactionByHash", hash); err != nil {
+		return nil, err
+	} else if tx == nil {
+		return nil, geth.NotFound
+	}
+	return tx, nil
+}
+
+func (c *ZkClient) TxReceiptByHash(hash common.Hash) (*RpcReceipt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var receipt *RpcReceipt
+	if err := c.rpc.CallContext(ctx, &receipt, "eth_getTransactionReceipt", hash); err != nil {
+		return nil, err
+	} else if receipt == nil {
+		return nil, geth.NotFound
+	}
+	return receipt, nil
+}
+
+func (c *ZkClient) Close() {
+	c.rpc.Close()
+}
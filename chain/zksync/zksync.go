@@ -0,0 +1,554 @@
+package zksync
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/status-im/keycard-go/hexutils"
+
+	"chain-account/chain"
+	"chain-account/chain/ethereum"
+	"chain-account/common/global_const"
+	"chain-account/config"
+	"chain-account/rpc/account"
+)
+
+const ChainName = "ZkSync"
+
+// 在以太坊适配器的基础上构建 EIP-712（0x71）交易、通过 zks_estimateFee 估算手续费，并解析 zkSync 交易类型，
+// 其余接口沿用以太坊的实现
+type ChainAdaptor struct {
+	*ethereum.ChainAdaptor
+	ZkClient IZkSync
+	chainId  *big.Int
+}
+
+func NewChainAdaptor(con *config.Config) (chain.IChainAdaptor, error) {
+	node := con.WalletNode.ZkSync
+	network := con.NetWork
+	if node.Network != "" {
+		network = node.Network
+	}
+	if node.ChainId == 0 {
+		node.ChainId = global_const.ZkSyncChainId
+		if network == "testnet" {
+			node.ChainId = global_const.ZkSyncSepoliaChainId
+		}
+	}
+	evmAdaptor, err := ethereum.NewEvmChainAdaptor(ChainName, "zksync", node, con.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	zkClient, err := NewZkClient(node.RpcUrl, time.Duration(node.TimeOut)*time.Second, node.Rpc)
+	if err != nil {
+		return nil, err
+	}
+	return &ChainAdaptor{
+		ChainAdaptor: evmAdaptor,
+		ZkClient:     zkClient,
+		chainId:      new(big.Int).SetUint64(node.ChainId),
+	}, nil
+}
+
+// 通过 zks_estimateFee 估算手续费，格式为 maxFeePerGas|maxPriorityFeePerGas|倍数|gasLimit|gasPerPubdata。
+// raw_tx 为 BuildUnSignTransaction 的 base64_tx，为空时按 address 发起的 ETH 转账估算
+func (c *ChainAdaptor) GetFee(req *account.FeeRequest) (*account.FeeResponse, error) {
+	callReq := &CallRequest{From: common.HexToAddress(req.Address)}
+	callReq.To = &callReq.From
+	if req.RawTx != "" {
+		transferTx, err := decodeTransferTx(req.RawTx)
+		var tx *Eip712Tx
+		if err == nil {
+			tx, err = c.buildTx(transferTx)
+		}
+		if err != nil {
+			log.Error("build eip712 tx fail", "err", err)
+			return &account.FeeResponse{
+				Code: global_const.ReturnCode_ERROR,
+				Msg:  "invalid raw tx",
+			}, nil
+		}
+		callReq = callRequest(tx)
+	}
+	fee, err := c.ZkClient.EstimateFee(callReq)
+	if err != nil {
+		log.Error("estimate fee fail", "err", err)
+		return &account.FeeResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "estimate fee fail",
+		}, nil
+	}
+	price := fee.MaxFeePerGas.ToInt().String() + "|" + bigOrZero(fee.MaxPriorityFeePerGas.ToInt()).String()
+	suffix := "|" + fee.GasLimit.ToInt().String() + "|" + bigOrZero(fee.GasPerPubdataLimit.ToInt()).String()
+	return &account.FeeResponse{
+		Code:      global_const.ReturnCode_SUCCESS,
+		Msg:       "get fee success",
+		SlowFee:   price + suffix,
+		NormalFee: price + "|*2" + suffix,
+		FastFee:   price + "|*3" + suffix,
+	}, nil
+}
+
+// 构建未签名交易：un_sign_tx 为补全 gas 参数后的 base64_tx，需原样传给 BuildSignedTransaction，
+// sign_hashes 为 EIP-712 签名哈希
+func (c *ChainAdaptor) BuildUnSignTransaction(req *account.UnSignTransactionRequest) (*account.UnSignTransactionResponse, error) {
+	transferTx, err := decodeTransferTx(req.Base64Tx)
+	if err != nil {
+		log.Error("decode transfer tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode base64 tx fail",
+		}, nil
+	}
+	tx, err := c.buildTx(transferTx)
+	if err == nil {
+		err = c.setGas(transferTx, tx, true)
+	}
+	if err != nil {
+		log.Error("build eip712 tx fail", "err", err)
+		return &account.UnSignTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	txJson, _ := json.Marshal(transferTx)
+	return &account.UnSignTransactionResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "build unsigned transaction success",
+		UnSignTx:   base64.StdEncoding.EncodeToString(txJson),
+		SignHashes: []string{tx.SigningHash().Hex()},
+	}, nil
+}
+
+// 构建签名交易：signature 为 65 字节 r || s || v，作为 customSignature 序列化为 0x71 交易，msg 为交易哈希
+func (c *ChainAdaptor) BuildSignedTransaction(req *account.SignedTransactionRequest) (*account.SignedTransactionResponse, error) {
+	transferTx, err := decodeTransferTx(req.Base64Tx)
+	if err != nil {
+		log.Error("decode transfer tx fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode unsigned tx fail",
+		}, nil
+	}
+	tx, err := c.buildTx(transferTx)
+	if err == nil {
+		err = c.setGas(transferTx, tx, false)
+	}
+	if err != nil {
+		log.Error("build eip712 tx fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  err.Error(),
+		}, nil
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(req.Signature, "0x"))
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	signature = bytes.Clone(signature)
+	if signature[64] >= 27 {
+		signature[64] -= 27
+	}
+
+	// 校验签名地址
+	pubKey, err := crypto.SigToPub(tx.SigningHash().Bytes(), signature)
+	if err != nil {
+		log.Error("recover public key fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid signature",
+		}, nil
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != tx.From {
+		log.Error("sender mismatch", "expected", tx.From.Hex(), "got", signer.Hex())
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "sender address mismatch",
+		}, nil
+	}
+	// customSignature 中的 v 为 27/28
+	signature[64] += 27
+	tx.CustomSignature = signature
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		log.Error("marshal eip712 tx fail", "err", err)
+		return &account.SignedTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "marshal eip712 tx fail",
+		}, nil
+	}
+	return &account.SignedTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      tx.Hash().Hex(),
+		SignedTx: hexutil.Encode(rawTx),
+	}, nil
+}
+
+// 解码十六进制原始交易，支持 0x71 交易和以太坊标准交易，base64_tx 为 base64 编码的 DecodedTx
+func (c *ChainAdaptor) DecodeTransaction(req *account.DecodeTransactionRequest) (*account.DecodeTransactionResponse, error) {
+	raw, err := hexutil.Decode(req.RawTx)
+	if err != nil {
+		return &account.DecodeTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "invalid raw tx",
+		}, nil
+	}
+	decoded, err := decodeRawTx(raw)
+	if err != nil {
+		log.Error("decode transaction fail", "err", err)
+		return &account.DecodeTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "decode transaction fail",
+		}, nil
+	}
+	txJson, _ := json.Marshal(decoded)
+	return &account.DecodeTransactionResponse{
+		Code:     global_const.ReturnCode_SUCCESS,
+		Msg:      "decode transaction success",
+		Base64Tx: base64.StdEncoding.EncodeToString(txJson),
+	}, nil
+}
+
+// 按Hash获取交易详情，type 为交易类型（0x71 为 113），L1 优先级交易的 l1_origin 为 priority
+func (c *ChainAdaptor) GetTxByHash(req *account.TxHashRequest) (*account.TxHashResponse, error) {
+	hash := common.HexToHash(req.Hash)
+	tx, err := c.ZkClient.TxByHash(hash)
+	if errors.Is(err, geth.NotFound) {
+		// 节点上查不到的交易（如已被替换或丢弃）以跟踪记录为准
+		if c.Outgoing != nil {
+			return c.ChainAdaptor.GetTxByHash(req)
+		}
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_SUCCESS,
+			Msg:  "transaction not found",
+			Tx: &account.TxMessage{
+				Hash:   req.Hash,
+				Status: account.TxStatus_NotFound,
+			},
+		}, nil
+	}
+	if err != nil {
+		log.Error("get tx by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get tx by hash fail",
+		}, nil
+	}
+
+	// 没有收据说明交易还在 mempool 中
+	receipt, err := c.ZkClient.TxReceiptByHash(hash)
+	if err != nil && !errors.Is(err, geth.NotFound) {
+		log.Error("get tx receipt by hash fail", "err", err)
+		return &account.TxHashResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get tx receipt by hash fail",
+		}, nil
+	}
+
+	txMessage := transferMessage(tx)
+	txMessage.Status = account.TxStatus_Pending
+	txMessage.Type = int32(tx.Type)
+	if receipt != nil {
+		txMessage.Index = uint32(receipt.TransactionIndex)
+		txMessage.Height = bigOrZero(receipt.BlockNumber.ToInt()).String()
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(uint64(receipt.GasUsed)), bigOrZero(receipt.EffectiveGasPrice.ToInt()))
+		txMessage.Fee = fee.String()
+		txMessage.Status = account.TxStatus_Failed
+		if uint64(receipt.Status) == types.ReceiptStatusSuccessful {
+			txMessage.Status = account.TxStatus_Success
+		}
+		if tx.To == nil && receipt.ContractAddress != nil {
+			txMessage.To = receipt.ContractAddress.Hex()
+		}
+	}
+	return &account.TxHashResponse{
+		Code: global_const.ReturnCode_SUCCESS,
+		Msg:  "get transaction success",
+		Tx:   txMessage,
+	}, nil
+}
+
+// 通过区块号获取区块数据，L1 优先级交易的 l1_origin 为 priority
+func (c *ChainAdaptor) GetBlockByNumber(req *account.BlockNumberRequest) (*account.BlockResponse, error) {
+	var number *big.Int
+	if req.Height != 0 {
+		number = big.NewInt(req.Height)
+	}
+	block, err := c.ZkClient.BlockByNumber(number)
+	if err != nil {
+		log.Error("get block by number fail", "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by number fail",
+		}, nil
+	}
+	return blockResponse(block, "get block by number success"), nil
+}
+
+// 通过区块Hash获取区块数据
+func (c *ChainAdaptor) GetBlockByHash(req *account.BlockHashRequest) (*account.BlockResponse, error) {
+	block, err := c.ZkClient.BlockByHash(common.HexToHash(req.Hash))
+	if err != nil {
+		log.Error("get block by hash fail", "err", err)
+		return &account.BlockResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get block by hash fail",
+		}, nil
+	}
+	return blockResponse(block, "get block by hash success"), nil
+}
+
+// 将请求转换为 0x71 交易，不包含 gas limit 和 gas 价格
+func (c *ChainAdaptor) buildTx(transferTx *ZkSyncTransferTx) (*Eip712Tx, error) {
+	if !common.IsHexAddress(transferTx.FromAddress) {
+		return nil, errors.New("invalid from address")
+	}
+	if !common.IsHexAddress(transferTx.ToAddress) {
+		return nil, errors.New("invalid to address")
+	}
+	chainId := c.chainId
+	if transferTx.ChainId != "" && transferTx.ChainId != chainId.String() {
+		return nil, errors.New("chain id mismatch")
+	}
+	transferTx.ChainId = chainId.String()
+	amount, ok := new(big.Int).SetString(transferTx.Amount, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, errors.New("invalid amount")
+	}
+
+	tx := &Eip712Tx{
+		ChainId: chainId,
+		Nonce:   transferTx.Nonce,
+		From:    common.HexToAddress(transferTx.FromAddress),
+	}
+	to := common.HexToAddress(transferTx.ToAddress)
+	if transferTx.Data != "" {
+		// 原始调用数据，按原样发送
+		data, err := hexutil.Decode(transferTx.Data)
+		if err != nil {
+			return nil, errors.New("invalid data")
+		}
+		tx.To, tx.Value, tx.Data = &to, amount, data
+	} else if transferTx.ContractAddress == "" || transferTx.ContractAddress == global_const.ZeroAddress {
+		tx.To, tx.Value = &to, amount
+	} else {
+		if !common.IsHexAddress(transferTx.ContractAddress) {
+			return nil, errors.New("invalid contract address")
+		}
+		contract := common.HexToAddress(transferTx.ContractAddress)
+		tx.To, tx.Value, tx.Data = &contract, new(big.Int), ethereum.BuildErc20Data(to, amount)
+	}
+	if transferTx.Paymaster != "" {
+		if !common.IsHexAddress(transferTx.Paymaster) {
+			return nil, errors.New("invalid paymaster")
+		}
+		paymaster := common.HexToAddress(transferTx.Paymaster)
+		input, err := hexutil.Decode(transferTx.PaymasterInput)
+		if err != nil {
+			return nil, errors.New("invalid paymaster input")
+		}
+		tx.Paymaster, tx.PaymasterInput = &paymaster, input
+	}
+	tx.GasPerPubdata = transferTx.GasPerPubdata
+	if tx.GasPerPubdata == 0 {
+		tx.GasPerPubdata = DefaultGasPerPubdata
+	}
+	return tx, nil
+}
+
+// 设置 gas 参数，estimate 为 true 且未指定 gas_limit 时通过 zks_estimateFee 补全并回写到请求中
+func (c *ChainAdaptor) setGas(transferTx *ZkSyncTransferTx, tx *Eip712Tx, estimate bool) error {
+	if transferTx.GasLimit == 0 && estimate {
+		fee, err := c.ZkClient.EstimateFee(callRequest(tx))
+		if err != nil {
+			return err
+		}
+		transferTx.GasLimit = fee.GasLimit.ToInt().Uint64()
+		transferTx.MaxFeePerGas = fee.MaxFeePerGas.ToInt().String()
+		transferTx.MaxPriorityFeePerGas = bigOrZero(fee.MaxPriorityFeePerGas.ToInt()).String()
+		if fee.GasPerPubdataLimit != nil {
+			transferTx.GasPerPubdata = fee.GasPerPubdataLimit.ToInt().Uint64()
+			tx.GasPerPubdata = transferTx.GasPerPubdata
+		}
+	}
+	if transferTx.GasLimit == 0 {
+		return errors.New("invalid gas limit")
+	}
+	tx.Gas = transferTx.GasLimit
+	var ok bool
+	if tx.GasFeeCap, ok = new(big.Int).SetString(transferTx.MaxFeePerGas, 10); !ok {
+		return errors.New("invalid max fee per gas")
+	}
+	if tx.GasTipCap, ok = new(big.Int).SetString(transferTx.MaxPriorityFeePerGas, 10); !ok {
+		tx.GasTipCap = new(big.Int)
+	}
+	if tx.GasTipCap.Cmp(tx.GasFeeCap) > 0 {
+		return errors.New("invalid max priority fee per gas")
+	}
+	return nil
+}
+
+func callRequest(tx *Eip712Tx) *CallRequest {
+	callReq := &CallRequest{
+		From:  tx.From,
+		To:    tx.To,
+		Value: (*hexutil.Big)(bigOrZero(tx.Value)),
+		Data:  tx.Data,
+		Eip712Meta: &Eip712Meta{
+			GasPerPubdata: (*hexutil.Big)(new(big.Int).SetUint64(tx.GasPerPubdata)),
+		},
+	}
+	if tx.Paymaster != nil {
+		callReq.Eip712Meta.PaymasterParams = &PaymasterParams{Paymaster: *tx.Paymaster, PaymasterInput: tx.PaymasterInput}
+	}
+	return callReq
+}
+
+func decodeTransferTx(base64Tx string) (*ZkSyncTransferTx, error) {
+	txJson, err := base64.StdEncoding.DecodeString(base64Tx)
+	if err != nil {
+		return nil, err
+	}
+	var transferTx ZkSyncTransferTx
+	if err := json.Unmarshal(txJson, &transferTx); err != nil {
+		return nil, err
+	}
+	return &transferTx, nil
+}
+
+func decodeRawTx(raw []byte) (*DecodedTx, error) {
+	if len(raw) > 0 && raw[0] == Eip712TxType {
+		tx, err := UnmarshalEip712Tx(raw)
+		if err != nil {
+			return nil, err
+		}
+		decoded := &DecodedTx{
+			Type:          Eip712TxType,
+			Hash:          tx.Hash().Hex(),
+			ChainId:       bigOrZero(tx.ChainId).String(),
+			Nonce:         tx.Nonce,
+			From:          tx.From.Hex(),
+			Value:         bigOrZero(tx.Value).String(),
+			Gas:           tx.Gas,
+			GasFeeCap:     bigOrZero(tx.GasFeeCap).String(),
+			GasTipCap:     bigOrZero(tx.GasTipCap).String(),
+			GasPerPubdata: tx.GasPerPubdata,
+			FactoryDeps:   len(tx.FactoryDeps),
+		}
+		if tx.To != nil {
+			decoded.To = tx.To.Hex()
+		}
+		if len(tx.Data) > 0 {
+			decoded.Data = hexutil.Encode(tx.Data)
+		}
+		if tx.Paymaster != nil {
+			decoded.Paymaster = tx.Paymaster.Hex()
+			decoded.PaymasterInput = hexutil.Encode(tx.PaymasterInput)
+		}
+		if len(tx.CustomSignature) > 0 {
+			decoded.CustomSignature = hexutil.Encode(tx.CustomSignature)
+		}
+		return decoded, nil
+	}
+
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	decoded := &DecodedTx{
+		Type:      tx.Type(),
+		Hash:      tx.Hash().Hex(),
+		ChainId:   tx.ChainId().String(),
+		Nonce:     tx.Nonce(),
+		Value:     tx.Value().String(),
+		Gas:       tx.Gas(),
+		GasFeeCap: tx.GasFeeCap().String(),
+		GasTipCap: tx.GasTipCap().String(),
+	}
+	if tx.To() != nil {
+		decoded.To = tx.To().Hex()
+	}
+	if len(tx.Data()) > 0 {
+		decoded.Data = hexutil.Encode(tx.Data())
+	}
+	if _, r, s := tx.RawSignatureValues(); r.Sign() != 0 && s.Sign() != 0 {
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), &tx)
+		if err != nil {
+			return nil, err
+		}
+		decoded.From = from.Hex()
+	}
+	return decoded, nil
+}
+
+func blockResponse(block *RpcBlock, msg string) *account.BlockResponse {
+	height := uint64(block.Number)
+	var blockTxList []*account.BlockInfoTransactionList
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		itemTx := &account.BlockInfoTransactionList{
+			From:     tx.From.Hex(),
+			Hash:     tx.Hash.Hex(),
+			Amount:   bigOrZero(tx.Value.ToInt()).String(),
+			Height:   height,
+			L1Origin: l1Origin(tx),
+		}
+		if tx.To != nil {
+			itemTx.To = tx.To.Hex()
+		}
+		blockTxList = append(blockTxList, itemTx)
+	}
+	return &account.BlockResponse{
+		Code:         global_const.ReturnCode_SUCCESS,
+		Msg:          msg,
+		Height:       int64(height),
+		Hash:         block.Hash.Hex(),
+		BaseFee:      block.BaseFee,
+		Transactions: blockTxList,
+	}
+}
+
+// 解析 ETH 或 ERC20 转账，ERC20 转账的 to 和 value 取自调用数据
+func transferMessage(tx *RpcTransaction) *account.TxMessage {
+	txMessage := &account.TxMessage{
+		Hash:            tx.Hash.Hex(),
+		From:            tx.From.Hex(),
+		Value:           bigOrZero(tx.Value.ToInt()).String(),
+		ContractAddress: global_const.ZeroAddress,
+		Data:            hexutils.BytesToHex(tx.Input),
+		L1Origin:        l1Origin(tx),
+	}
+	if tx.To == nil {
+		return txMessage
+	}
+	txMessage.To = tx.To.Hex()
+	if len(tx.Input) >= 68 && hexutil.Encode(tx.Input[:4]) == "0xa9059cbb" {
+		txMessage.To = common.BytesToAddress(tx.Input[16:36]).Hex()
+		txMessage.Value = new(big.Int).SetBytes(tx.Input[36:68]).String()
+		txMessage.ContractAddress = tx.To.Hex()
+	}
+	return txMessage
+}
+
+// L1 优先级交易的来源
+func l1Origin(tx *RpcTransaction) string {
+	if tx.Type == PriorityTxType {
+		return global_const.L1OriginPriority
+	}
+	return ""
+}
@@ -0,0 +1,278 @@
+package zksync
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"chain-account/chain/ethereum"
+	"chain-account/common/global_const"
+	"chain-account/rpc/account"
+)
+
+var (
+	testReceiver  = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	testPaymaster = common.HexToAddress("0x2222222222222222222222222222222222222222")
+)
+
+// 模拟 zkSync 节点
+type fakeZkSync struct {
+	IZkSync
+	feeCalls []*CallRequest
+	block    *RpcBlock
+	txs      map[common.Hash]*RpcTransaction
+	receipts map[common.Hash]*RpcReceipt
+}
+
+func (f *fakeZkSync) EstimateFee(req *CallRequest) (*Fee, error) {
+	f.feeCalls = append(f.feeCalls, req)
+	return &Fee{
+		GasLimit:             (*hexutil.Big)(big.NewInt(300000)),
+		GasPerPubdataLimit:   (*hexutil.Big)(big.NewInt(800)),
+		MaxFeePerGas:         (*hexutil.Big)(big.NewInt(45_250_000)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(big.NewInt(0)),
+	}, nil
+}
+
+func (f *fakeZkSync) BlockByNumber(number *big.Int) (*RpcBlock, error) {
+	return f.block, nil
+}
+
+func (f *fakeZkSync) TxByHash(hash common.Hash) (*RpcTransaction, error) {
+	if tx, ok := f.txs[hash]; ok {
+		return tx, nil
+	}
+	return nil, geth.NotFound
+}
+
+func (f *fakeZkSync) TxReceiptByHash(hash common.Hash) (*RpcReceipt, error) {
+	if receipt, ok := f.receipts[hash]; ok {
+		return receipt, nil
+	}
+	return nil, geth.NotFound
+}
+
+func newTestAdaptor(client *fakeZkSync) *ChainAdaptor {
+	return &ChainAdaptor{
+		ChainAdaptor: &ethereum.ChainAdaptor{},
+		ZkClient:     client,
+		chainId:      new(big.Int).SetUint64(global_const.ZkSyncChainId),
+	}
+}
+
+func encodeTransferTx(t *testing.T, transferTx *ZkSyncTransferTx) string {
+	txJson, err := json.Marshal(transferTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(txJson)
+}
+
+func testTx(from common.Address) *Eip712Tx {
+	return &Eip712Tx{
+		ChainId:       new(big.Int).SetUint64(global_const.ZkSyncChainId),
+		Nonce:         7,
+		GasTipCap:     big.NewInt(0),
+		GasFeeCap:     big.NewInt(45_250_000),
+		Gas:           300000,
+		To:            &testReceiver,
+		Value:         big.NewInt(1_000_000_000_000_000),
+		From:          from,
+		GasPerPubdata: DefaultGasPerPubdata,
+	}
+}
+
+func Test_Eip712TxRoundTrip(t *testing.T) {
+	tx := testTx(common.HexToAddress("0x62EccDa8bB2Ae5690E319F3eFde897dEAeD86631"))
+	tx.Data = []byte{0xde, 0xad, 0xbe, 0xef}
+	tx.Paymaster, tx.PaymasterInput = &testPaymaster, []byte{0x8c, 0x5a, 0x34, 0x45}
+	tx.CustomSignature = bytes.Repeat([]byte{0x01}, 65)
+
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw[0] != Eip712TxType {
+		t.Fatalf("unexpected tx type %x", raw[0])
+	}
+	decoded, err := UnmarshalEip712Tx(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != tx.Hash() || decoded.SigningHash() != tx.SigningHash() {
+		t.Fatalf("hash mismatch after round trip")
+	}
+	if *decoded.Paymaster != testPaymaster || !bytes.Equal(decoded.PaymasterInput, tx.PaymasterInput) || decoded.From != tx.From {
+		t.Fatalf("unexpected decoded tx %+v", decoded)
+	}
+	// 交易哈希为 keccak256(signingHash || keccak256(customSignature))
+	want := crypto.Keccak256Hash(tx.SigningHash().Bytes(), crypto.Keccak256(tx.CustomSignature))
+	if tx.Hash() != want {
+		t.Fatalf("unexpected tx hash %s", tx.Hash().Hex())
+	}
+
+	// 签名哈希覆盖 paymaster
+	noPaymaster := *tx
+	noPaymaster.Paymaster, noPaymaster.PaymasterInput = nil, nil
+	if noPaymaster.SigningHash() == tx.SigningHash() {
+		t.Fatalf("signing hash should cover paymaster")
+	}
+}
+
+func Test_HashBytecode(t *testing.T) {
+	hash := hashBytecode(make([]byte, 64))
+	if hash[0] != 1 || hash[1] != 0 || hash[2] != 0 || hash[3] != 2 {
+		t.Fatalf("unexpected bytecode hash %s", hash.Hex())
+	}
+}
+
+func Test_BuildTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	client := &fakeZkSync{}
+	adaptor := newTestAdaptor(client)
+
+	unsignedResp, err := adaptor.BuildUnSignTransaction(&account.UnSignTransactionRequest{
+		Base64Tx: encodeTransferTx(t, &ZkSyncTransferTx{
+			Nonce:       7,
+			FromAddress: from.Hex(),
+			ToAddress:   testReceiver.Hex(),
+			Amount:      "1000000000000000",
+		}),
+	})
+	if err != nil || unsignedResp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("BuildUnSignTransaction: %v %v", err, unsignedResp)
+	}
+	// gas_limit 为 0 时通过 zks_estimateFee 补全
+	if len(client.feeCalls) != 1 || client.feeCalls[0].Eip712Meta.GasPerPubdata.ToInt().Uint64() != DefaultGasPerPubdata {
+		t.Fatalf("unexpected fee calls %+v", client.feeCalls)
+	}
+	transferTx, _ := decodeTransferTx(unsignedResp.UnSignTx)
+	if transferTx.GasLimit != 300000 || transferTx.GasPerPubdata != 800 || transferTx.ChainId != "324" {
+		t.Fatalf("unexpected unsigned tx %+v", transferTx)
+	}
+
+	signHash := common.HexToHash(unsignedResp.SignHashes[0])
+	signature, _ := crypto.Sign(signHash.Bytes(), key)
+	signedResp, err := adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  unsignedResp.UnSignTx,
+		Signature: hexutil.Encode(signature),
+	})
+	if err != nil || signedResp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("BuildSignedTransaction: %v %v", err, signedResp)
+	}
+	// 已补全 gas 参数的交易不再估算
+	if len(client.feeCalls) != 1 {
+		t.Fatalf("unexpected fee calls %d", len(client.feeCalls))
+	}
+	raw, _ := hexutil.Decode(signedResp.SignedTx)
+	tx, err := UnmarshalEip712Tx(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.SigningHash() != signHash || tx.Hash().Hex() != signedResp.Msg || tx.CustomSignature[64] != signature[64]+27 {
+		t.Fatalf("unexpected signed tx %+v", tx)
+	}
+
+	decodeResp, _ := adaptor.DecodeTransaction(&account.DecodeTransactionRequest{RawTx: signedResp.SignedTx})
+	txJson, _ := base64.StdEncoding.DecodeString(decodeResp.Base64Tx)
+	var decoded DecodedTx
+	if err := json.Unmarshal(txJson, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Type != Eip712TxType || decoded.From != from.Hex() || decoded.Value != "1000000000000000" || decoded.GasPerPubdata != 800 {
+		t.Fatalf("unexpected decoded tx %+v", decoded)
+	}
+
+	// 签名地址与 from_address 不一致
+	otherKey, _ := crypto.GenerateKey()
+	signature, _ = crypto.Sign(signHash.Bytes(), otherKey)
+	signedResp, _ = adaptor.BuildSignedTransaction(&account.SignedTransactionRequest{
+		Base64Tx:  unsignedResp.UnSignTx,
+		Signature: hexutil.Encode(signature),
+	})
+	if signedResp.Code != global_const.ReturnCode_ERROR || signedResp.Msg != "sender address mismatch" {
+		t.Fatalf("expected sender mismatch, got %v", signedResp)
+	}
+}
+
+func Test_GetFee(t *testing.T) {
+	client := &fakeZkSync{}
+	adaptor := newTestAdaptor(client)
+	resp, err := adaptor.GetFee(&account.FeeRequest{
+		RawTx: encodeTransferTx(t, &ZkSyncTransferTx{
+			FromAddress:    testReceiver.Hex(),
+			ToAddress:      testReceiver.Hex(),
+			Amount:         "0",
+			Paymaster:      testPaymaster.Hex(),
+			PaymasterInput: "0x8c5a3445",
+		}),
+	})
+	if err != nil || resp.Code != global_const.ReturnCode_SUCCESS {
+		t.Fatalf("GetFee: %v %v", err, resp)
+	}
+	if resp.SlowFee != "45250000|0|300000|800" || resp.NormalFee != "45250000|0|*2|300000|800" {
+		t.Fatalf("unexpected fee %v", resp)
+	}
+	params := client.feeCalls[0].Eip712Meta.PaymasterParams
+	if params == nil || params.Paymaster != testPaymaster {
+		t.Fatalf("unexpected paymaster params %+v", params)
+	}
+	paramsJson, _ := json.Marshal(params)
+	if string(paramsJson) != `{"paymaster":"0x2222222222222222222222222222222222222222","paymasterInput":[140,90,52,69]}` {
+		t.Fatalf("unexpected paymaster params json %s", paramsJson)
+	}
+}
+
+func Test_GetBlockByNumber(t *testing.T) {
+	client := &fakeZkSync{block: &RpcBlock{
+		Hash:   common.HexToHash("0xb1"),
+		Number: 100,
+		Transactions: []RpcTransaction{
+			{Type: Eip712TxType, Hash: common.HexToHash("0x71"), To: &testReceiver, Value: (*hexutil.Big)(big.NewInt(5))},
+			{Type: PriorityTxType, Hash: common.HexToHash("0xff"), To: &testReceiver, Value: (*hexutil.Big)(big.NewInt(6))},
+		},
+	}}
+	resp, _ := newTestAdaptor(client).GetBlockByNumber(&account.BlockNumberRequest{Height: 100})
+	if resp.Code != global_const.ReturnCode_SUCCESS || len(resp.Transactions) != 2 {
+		t.Fatalf("unexpected block %v", resp)
+	}
+	if resp.Transactions[0].L1Origin != "" || resp.Transactions[1].L1Origin != global_const.L1OriginPriority ||
+		resp.Transactions[1].Memo != "" || resp.Transactions[1].Amount != "6" {
+		t.Fatalf("unexpected transactions %v", resp.Transactions)
+	}
+}
+
+func Test_GetTxByHash(t *testing.T) {
+	hash := common.HexToHash("0x71")
+	contract := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	client := &fakeZkSync{
+		txs: map[common.Hash]*RpcTransaction{
+			hash: {Type: Eip712TxType, Hash: hash, To: &contract, Value: (*hexutil.Big)(big.NewInt(0)),
+				Input: ethereum.BuildErc20Data(testReceiver, big.NewInt(9))},
+		},
+		receipts: map[common.Hash]*RpcReceipt{
+			hash: {Status: 1, BlockNumber: (*hexutil.Big)(big.NewInt(100)), GasUsed: 1000, EffectiveGasPrice: (*hexutil.Big)(big.NewInt(3))},
+		},
+	}
+	resp, _ := newTestAdaptor(client).GetTxByHash(&account.TxHashRequest{Hash: hash.Hex()})
+	tx := resp.Tx
+	if tx.Status != account.TxStatus_Success || tx.Type != Eip712TxType || tx.Fee != "3000" || tx.Height != "100" {
+		t.Fatalf("unexpected tx %v", tx)
+	}
+	if tx.To != testReceiver.Hex() || tx.Value != "9" || tx.ContractAddress != contract.Hex() {
+		t.Fatalf("unexpected erc20 transfer %v", tx)
+	}
+
+	resp, _ = newTestAdaptor(client).GetTxByHash(&account.TxHashRequest{Hash: common.HexToHash("0x99").Hex()})
+	if resp.Code != global_const.ReturnCode_SUCCESS || resp.Tx.Status != account.TxStatus_NotFound {
+		t.Fatalf("unexpected not found response %v", resp)
+	}
+}
@@ -0,0 +1,170 @@
+// Package bcs 实现 Move 链（Aptos、Sui）使用的 BCS 编码
+package bcs
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+var ErrUnexpectedEnd = errors.New("unexpected end of data")
+
+func AppendBool(buf []byte, v bool) []byte {
+	if v {
+		return append(buf, 1)
+	}
+	return append(buf, 0)
+}
+
+func AppendU16(buf []byte, v uint16) []byte {
+	return binary.LittleEndian.AppendUint16(buf, v)
+}
+
+func AppendU64(buf []byte, v uint64) []byte {
+	return binary.LittleEndian.AppendUint64(buf, v)
+}
+
+// u128 小端 16 字节，超出范围时截断
+func AppendU128(buf []byte, v *big.Int) []byte {
+	be := make([]byte, 16)
+	v.FillBytes(be)
+	for i := 15; i >= 0; i-- {
+		buf = append(buf, be[i])
+	}
+	return buf
+}
+
+// ULEB128，用于序列长度和枚举变体序号
+func AppendUleb128(buf []byte, v uint64) []byte {
+	for v >= 0x80 {
+		buf = append(buf, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(buf, byte(v))
+}
+
+// 变长字节序列：长度 + 数据
+func AppendBytes(buf []byte, data []byte) []byte {
+	buf = AppendUleb128(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+func AppendString(buf []byte, s string) []byte {
+	return AppendBytes(buf, []byte(s))
+}
+
+// BCS 解码，出错后后续读取返回零值，通过 Err 获取错误
+type Decoder struct {
+	buf []byte
+	err error
+}
+
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{buf: data}
+}
+
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// 未读取的字节数
+func (d *Decoder) Len() int {
+	return len(d.buf)
+}
+
+func (d *Decoder) FixedBytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = ErrUnexpectedEnd
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *Decoder) U8() uint8 {
+	b := d.FixedBytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (d *Decoder) Bool() bool {
+	v := d.U8()
+	if v > 1 {
+		d.SetErr(errors.New("invalid bool"))
+	}
+	return v == 1
+}
+
+func (d *Decoder) U16() uint16 {
+	b := d.FixedBytes(2)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (d *Decoder) U64() uint64 {
+	b := d.FixedBytes(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (d *Decoder) U128() *big.Int {
+	b := d.FixedBytes(16)
+	if b == nil {
+		return new(big.Int)
+	}
+	be := make([]byte, 16)
+	for i := range b {
+		be[15-i] = b[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
+
+func (d *Decoder) Uleb128() uint64 {
+	var v uint64
+	for shift := 0; shift < 64; shift += 7 {
+		b := d.U8()
+		if d.err != nil {
+			return 0
+		}
+		v |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return v
+		}
+	}
+	d.SetErr(errors.New("uleb128 overflows u64"))
+	return 0
+}
+
+// 序列长度，不能超过剩余字节数
+func (d *Decoder) SeqLen() int {
+	n := d.Uleb128()
+	if n > uint64(len(d.buf)) {
+		d.SetErr(ErrUnexpectedEnd)
+		return 0
+	}
+	return int(n)
+}
+
+func (d *Decoder) Bytes() []byte {
+	return d.FixedBytes(d.SeqLen())
+}
+
+func (d *Decoder) Str() string {
+	return string(d.Bytes())
+}
+
+func (d *Decoder) SetErr(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
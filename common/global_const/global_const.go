@@ -40,5 +40,18 @@ const (
 	OpChinId               uint64 = 10
 	OpTestChinId           uint64 = 11155420
 	LineaChainId           uint64 = 59144
+	ArbitrumChainId        uint64 = 42161
+	ArbitrumNovaChainId    uint64 = 42170
+	ArbitrumSepoliaChainId uint64 = 421614
+	ZkSyncChainId          uint64 = 324
+	ZkSyncSepoliaChainId   uint64 = 300
 	BlocksLimit                   = 10000
 )
+
+// L2 交易的 L1 来源，对应 TxMessage.l1_origin
+const (
+	L1OriginDeposit         = "deposit"
+	L1OriginMessage         = "message"
+	L1OriginPriority        = "priority"
+	L1OriginRetryableRedeem = "retryable_redeem"
+)
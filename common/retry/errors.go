@@ -0,0 +1,156 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 节点已处理但拒绝的请求，重试也不会成功
+var permanentMessages = []string{
+	"nonce too low",
+	"nonce too high",
+	"already known",
+	"known transaction",
+	"replacement transaction underpriced",
+	"transaction underpriced",
+	"insufficient funds",
+	"intrinsic gas too low",
+	"exceeds block gas limit",
+	"gas limit reached",
+	"max fee per gas less than block base fee",
+	"execution reverted",
+	"invalid sender",
+	"invalid signature",
+	"invalid address",
+	"method not found",
+	"does not exist/is not available",
+	"no transactions found",
+}
+
+// 限流或节点暂时不可用，等待后可以重试
+var transientMessages = []string{
+	"rate limit",
+	"too many requests",
+	"limit exceeded",
+	"request timeout",
+	"header not found",
+	"service unavailable",
+	"connection reset",
+	"connection refused",
+}
+
+// JSON-RPC 错误码中可重试的：-32005 限流，-32603 节点内部错误
+var transientCodes = map[int]bool{
+	-32005: true,
+	-32603: true,
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// 标记为不可重试的错误，Do 遇到后立即返回
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// 带 Retry-After 的错误，Do 在下次尝试前至少等待 After
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+func RetryAfter(err error, after time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryAfterError{Err: err, After: after}
+}
+
+// 解析 Retry-After 头，支持秒数和 HTTP 日期两种格式
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// 错误是否值得重试：传输错误、超时、5xx、429 和限流类 JSON-RPC 错误可以重试，
+// 其余 4xx、JSON-RPC 错误和被 Permanent 标记的错误不重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var permanent *permanentError
+	if errors.As(err, &permanent) || errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) {
+		return true
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests ||
+			httpErr.StatusCode == http.StatusRequestTimeout ||
+			httpErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, transient := range transientMessages {
+		if strings.Contains(message, transient) {
+			return true
+		}
+	}
+	for _, permanent := range permanentMessages {
+		if strings.Contains(message, permanent) {
+			return false
+		}
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return transientCodes[rpcErr.ErrorCode()]
+	}
+	return true
+}
+
+// 错误中携带的最小等待时间
+func retryAfterOf(err error) time.Duration {
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) {
+		return retryAfter.After
+	}
+	return 0
+}
@@ -33,27 +33,72 @@ func Do2[T, U any](ctx context.Context, maxAttempts int, strategy Strategy, op f
 	return res.a, res.b, err
 }
 
+// 执行 op，遇到可重试的错误时按 strategy 等待后重试，等待期间 ctx 取消立即返回；
+// 不可重试的错误直接返回，不再消耗剩余的尝试次数
 func Do[T any](ctx context.Context, maxAttempts int, strategy Strategy, op func() (T, error)) (T, error) {
-	var empty, ret T
-	var err error
+	var empty T
 	if maxAttempts < 1 {
 		return empty, fmt.Errorf("need at least 1 attempt to run op, but have %d max attempts", maxAttempts)
 	}
 
-	for i := 0; i < maxAttempts; i++ {
+	for i := 0; ; i++ {
 		if ctx.Err() != nil {
 			return empty, ctx.Err()
 		}
-		ret, err = op()
+		ret, err := op()
 		if err == nil {
 			return ret, nil
 		}
-		if i != maxAttempts-1 {
-			time.Sleep(strategy.Duration(i))
+		delay, failed := next(i, maxAttempts, strategy, err)
+		if failed != nil {
+			return empty, failed
 		}
+		if err := wait(ctx, delay); err != nil {
+			return empty, err
+		}
+	}
+}
+
+// 非阻塞的单步执行：只执行第 attempt 次（从 0 开始）尝试，不在调用方协程中等待。
+// 失败且可以重试时返回 op 的错误和下次尝试前应等待的时间，由调用方自行安排（例如持久化后到期再调用）；
+// 不可重试或次数用尽时返回 *ErrFailedPermanently。错误分类和等待时间与 Do 相同
+func Step[T any](attempt, maxAttempts int, strategy Strategy, op func() (T, error)) (T, time.Duration, error) {
+	var empty T
+	if maxAttempts < 1 {
+		return empty, 0, fmt.Errorf("need at least 1 attempt to run op, but have %d max attempts", maxAttempts)
+	}
+	ret, err := op()
+	if err == nil {
+		return ret, 0, nil
+	}
+	delay, failed := next(attempt, maxAttempts, strategy, err)
+	if failed != nil {
+		return empty, 0, failed
+	}
+	return empty, delay, err
+}
+
+// 第 attempt 次尝试失败后的处理：可以重试时返回等待时间，否则返回 *ErrFailedPermanently
+func next(attempt, maxAttempts int, strategy Strategy, err error) (time.Duration, error) {
+	if !IsRetryable(err) || attempt >= maxAttempts-1 {
+		return 0, &ErrFailedPermanently{
+			attempts: attempt + 1,
+			LastErr:  err,
+		}
+	}
+	return max(strategy.Duration(attempt), retryAfterOf(err)), nil
+}
+
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
 	}
-	return empty, &ErrFailedPermanently{
-		attempts: maxAttempts,
-		LastErr:  err,
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
@@ -0,0 +1,185 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type jsonRpcError struct {
+	code    int
+	message string
+}
+
+func (e *jsonRpcError) Error() string  { return e.message }
+func (e *jsonRpcError) ErrorCode() int { return e.code }
+
+func Test_IsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("dial tcp: connection refused"), true},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+		{rpc.HTTPError{StatusCode: 502}, true},
+		{rpc.HTTPError{StatusCode: 429}, true},
+		{rpc.HTTPError{StatusCode: 401}, false},
+		{&jsonRpcError{-32000, "nonce too low"}, false},
+		{&jsonRpcError{-32000, "header not found"}, true},
+		{&jsonRpcError{-32005, "daily request count exceeded"}, true},
+		{&jsonRpcError{-32601, "the method eth_foo does not exist/is not available"}, false},
+		{fmt.Errorf("send tx: %w", &jsonRpcError{-32000, "insufficient funds for gas * price + value"}), false},
+		{Permanent(errors.New("bad input")), false},
+		{ErrCircuitOpen, false},
+	}
+	for _, c := range cases {
+		if got := IsRetryable(c.err); got != c.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func Test_DoClassification(t *testing.T) {
+	calls := 0
+	_, err := Do(context.Background(), 5, Fixed(0), func() (int, error) {
+		calls++
+		return 0, &jsonRpcError{-32000, "nonce too low"}
+	})
+	var failed *ErrFailedPermanently
+	if !errors.As(err, &failed) || calls != 1 {
+		t.Fatalf("expected to stop after 1 call, got %d calls, err %v", calls, err)
+	}
+
+	calls = 0
+	value, err := Do(context.Background(), 5, Fixed(0), func() (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errors.New("connection reset by peer")
+		}
+		return 7, nil
+	})
+	if err != nil || value != 7 || calls != 3 {
+		t.Fatalf("Do: %v %d, calls %d", err, value, calls)
+	}
+}
+
+func Test_DoWait(t *testing.T) {
+	// 等待期间 ctx 取消立即返回
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := Do(ctx, 3, Fixed(time.Minute), func() (int, error) {
+		return 0, errors.New("request timeout")
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Fatalf("expected deadline exceeded, got %v after %v", err, time.Since(start))
+	}
+
+	// Retry-After 比退避时间长时按 Retry-After 等待
+	calls := 0
+	start = time.Now()
+	Do(context.Background(), 2, Fixed(0), func() (int, error) {
+		calls++
+		return 0, RetryAfter(rpc.HTTPError{StatusCode: 429}, 30*time.Millisecond)
+	})
+	if elapsed := time.Since(start); calls != 2 || elapsed < 30*time.Millisecond {
+		t.Fatalf("expected to wait for retry-after, got %v", elapsed)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if d, ok := ParseRetryAfter("Mon, 01 Jan 2024 00:00:05 GMT", now); !ok || d != 5*time.Second {
+		t.Fatalf("ParseRetryAfter: %v %v", d, ok)
+	}
+	if d, ok := ParseRetryAfter("3", now); !ok || d != 3*time.Second {
+		t.Fatalf("ParseRetryAfter: %v %v", d, ok)
+	}
+}
+
+func Test_Step(t *testing.T) {
+	// 可以重试时不等待，返回原始错误和下次尝试前的等待时间
+	start := time.Now()
+	_, delay, err := Step(0, 3, Fixed(time.Minute), func() (int, error) {
+		return 0, RetryAfter(rpc.HTTPError{StatusCode: 503}, 2*time.Minute)
+	})
+	var failed *ErrFailedPermanently
+	if err == nil || errors.As(err, &failed) || delay != 2*time.Minute || time.Since(start) > time.Second {
+		t.Fatalf("expected retry after 2m, got %v %v", delay, err)
+	}
+
+	// 最后一次尝试失败或错误不可重试时返回 ErrFailedPermanently
+	_, _, err = Step(2, 3, Fixed(time.Minute), func() (int, error) {
+		return 0, errors.New("connection reset by peer")
+	})
+	if !errors.As(err, &failed) {
+		t.Fatalf("expected failed permanently after last attempt, got %v", err)
+	}
+	_, _, err = Step(0, 3, Fixed(time.Minute), func() (int, error) {
+		return 0, Permanent(errors.New("bad request"))
+	})
+	if !errors.As(err, &failed) {
+		t.Fatalf("expected failed permanently for permanent error, got %v", err)
+	}
+
+	value, delay, err := Step(1, 3, Fixed(time.Minute), func() (int, error) { return 7, nil })
+	if err != nil || value != 7 || delay != 0 {
+		t.Fatalf("Step: %v %d %v", err, value, delay)
+	}
+}
+
+func Test_ExponentialStrategy(t *testing.T) {
+	strategy := &ExponentialStrategy{Min: 100 * time.Millisecond, Max: time.Second}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, d := range want {
+		if got := strategy.Duration(i); got != d {
+			t.Fatalf("Duration(%d) = %v, want %v", i, got, d)
+		}
+	}
+	if got := strategy.Duration(100); got != time.Second {
+		t.Fatalf("Duration(100) = %v", got)
+	}
+}
+
+func Test_Breaker(t *testing.T) {
+	now := time.Now()
+	breaker := NewBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	transient := errors.New("connection refused")
+
+	// 不可重试的错误不计入失败
+	breaker.Do(func() error { return errors.New("execution reverted") })
+	breaker.Do(func() error { return transient })
+	if breaker.State() != BreakerClosed {
+		t.Fatalf("unexpected state %v", breaker.State())
+	}
+	breaker.Do(func() error { return transient })
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("unexpected state %v", breaker.State())
+	}
+	if err := breaker.Do(func() error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+
+	// 冷却后半开，只放行一个探测请求，探测失败重新打开
+	now = now.Add(time.Minute)
+	if err := breaker.Allow(); err != nil || breaker.State() != BreakerHalfOpen {
+		t.Fatalf("expected half-open probe, got %v %v", err, breaker.State())
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected single probe, got %v", err)
+	}
+	breaker.Record(transient)
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("unexpected state %v", breaker.State())
+	}
+
+	// 探测成功后关闭
+	now = now.Add(time.Minute)
+	if err := breaker.Do(func() error { return nil }); err != nil || breaker.State() != BreakerClosed {
+		t.Fatalf("expected closed, got %v %v", err, breaker.State())
+	}
+}
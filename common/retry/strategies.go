@@ -24,11 +24,15 @@ func (e *ExponentialStrategy) Duration(attempt int) time.Duration {
 	if attempt < 0 {
 		return e.Min + jitter
 	}
-	durFloat := float64(e.Min)
-	durFloat += math.Pow(2, float64(attempt)) * float64(time.Second)
-	dur := time.Duration(durFloat)
-	if durFloat > float64(e.Max) {
-		dur = e.Max
+	// 第 attempt 次等待 Min*2^attempt，Min 未设置时从 1s 开始
+	base := e.Min
+	if base <= 0 {
+		base = time.Second
+	}
+	durFloat := float64(base) * math.Pow(2, float64(attempt))
+	dur := e.Max
+	if e.Max <= 0 || durFloat < float64(e.Max) {
+		dur = time.Duration(min(durFloat, math.MaxInt64))
 	}
 	dur += jitter
 
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	levelCache   = 16 // MB
+	levelHandles = 16
+)
+
+// LevelDB 存储，每次写入只落盘对应的 key，适合数据量大、写入频繁的场景
+type LevelStore struct {
+	db *leveldb.Database
+}
+
+// 打开 path 目录下的 LevelDB；同名的旧版 JSON 文件（path + ".json"）存在时先导入，导入后改名为 .migrated
+func NewLevelStore(path string) (*LevelStore, error) {
+	db, err := leveldb.New(path, levelCache, levelHandles, "", false)
+	if err != nil {
+		return nil, err
+	}
+	s := &LevelStore{db: db}
+	if err := s.migrate(path + ".json"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *LevelStore) migrate(legacy string) error {
+	if _, err := os.Stat(legacy); os.IsNotExist(err) {
+		return nil
+	}
+	old, err := NewFileStore(legacy)
+	if err != nil {
+		return err
+	}
+	batch := s.db.NewBatch()
+	for key, raw := range old.data {
+		if err := batch.Put([]byte(key), raw); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("migrated legacy json store", "path", legacy, "keys", len(old.data))
+	return os.Rename(legacy, legacy+".migrated")
+}
+
+func (s *LevelStore) Get(key string, value any) (bool, error) {
+	ok, err := s.db.Has([]byte(key))
+	if err != nil || !ok {
+		return false, err
+	}
+	raw, err := s.db.Get([]byte(key))
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(raw, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *LevelStore) Put(key string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.db.Put([]byte(key), raw)
+}
+
+// 通过 LevelDB 的 batch 原子写入
+func (s *LevelStore) PutBatch(values map[string]any) error {
+	raws, err := marshalAll(values)
+	if err != nil {
+		return err
+	}
+	batch := s.db.NewBatch()
+	for key, raw := range raws {
+		if err := batch.Put([]byte(key), raw); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+func (s *LevelStore) Delete(key string) error {
+	return s.db.Delete([]byte(key))
+}
+
+// 按前缀列出 key，结果按字典序排列
+func (s *LevelStore) Keys(prefix string) ([]string, error) {
+	it := s.db.NewIterator([]byte(prefix), nil)
+	defer it.Release()
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	return keys, it.Error()
+}
+
+func (s *LevelStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,211 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 嵌入式 KV 存储，value 统一以 JSON 序列化
+type Store interface {
+	Get(key string, value any) (bool, error)
+	Put(key string, value any) error
+	// 原子地写入多个 key，要么全部写入，要么都不写入
+	PutBatch(values map[string]any) error
+	Delete(key string) error
+	Keys(prefix string) ([]string, error)
+}
+
+// 根据路径初始化存储，路径为空时只保存在内存中，否则使用 LevelDB
+func NewStore(path string) (Store, error) {
+	if path == "" {
+		return NewMemoryStore(), nil
+	}
+	return NewLevelStore(path)
+}
+
+// 内存存储
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data map[string]json.RawMessage
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data: make(map[string]json.RawMessage),
+	}
+}
+
+func (m *MemoryStore) Get(key string, value any) (bool, error) {
+	m.mu.RLock()
+	raw, ok := m.data[key]
+	m.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (m *MemoryStore) Put(key string, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.data[key] = raw
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemoryStore) PutBatch(values map[string]any) error {
+	raws, err := marshalAll(values)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	for key, raw := range raws {
+		m.data[key] = raw
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MemoryStore) Delete(key string) error {
+	m.mu.Lock()
+	delete(m.data, key)
+	m.mu.Unlock()
+	return nil
+}
+
+// 按前缀列出 key，结果按字典序排列
+func (m *MemoryStore) Keys(prefix string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var keys []string
+	for key := range m.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// 文件存储，每次写入后整体落盘（先写临时文件并 fsync 再 rename，保证原子性），只适合小数据量
+type FileStore struct {
+	*MemoryStore
+	path string
+	wmu  sync.Mutex
+}
+
+func NewFileStore(path string) (*FileStore, error) {
+	fs := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		path:        path,
+	}
+	file, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		return fs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(file) > 0 {
+		if err := json.Unmarshal(file, &fs.data); err != nil {
+			return nil, err
+		}
+	}
+	return fs, nil
+}
+
+func (f *FileStore) Put(key string, value any) error {
+	if err := f.MemoryStore.Put(key, value); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+func (f *FileStore) PutBatch(values map[string]any) error {
+	if err := f.MemoryStore.PutBatch(values); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+func (f *FileStore) Delete(key string) error {
+	if err := f.MemoryStore.Delete(key); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+func (f *FileStore) flush() error {
+	f.wmu.Lock()
+	defer f.wmu.Unlock()
+
+	f.mu.RLock()
+	file, err := json.Marshal(f.data)
+	f.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := writeSync(tmp, file); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return err
+	}
+	// rename 本身也需要落盘
+	dir, err := os.Open(filepath.Dir(f.path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+func marshalAll(values map[string]any) (map[string]json.RawMessage, error) {
+	raws := make(map[string]json.RawMessage, len(values))
+	for key, value := range values {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		raws[key] = raw
+	}
+	return raws, nil
+}
+
+func writeSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// 拼接存储文件路径，未配置数据目录时返回空（使用内存存储）
+func Path(dataDir, name string) string {
+	if dataDir == "" {
+		return ""
+	}
+	return filepath.Join(dataDir, name)
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_LevelStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deposit")
+
+	// 旧版 JSON 文件在首次打开时导入
+	legacy, err := NewFileStore(path + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = legacy.Put("a/1", 1)
+	_ = legacy.Put("b/1", 2)
+
+	s, err := NewLevelStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".json.migrated"); err != nil {
+		t.Fatalf("expected legacy file to be renamed: %v", err)
+	}
+	_ = s.Put("a/2", 3)
+	_ = s.Delete("b/1")
+	if keys, _ := s.Keys("a/"); len(keys) != 2 || keys[0] != "a/1" || keys[1] != "a/2" {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+	if err := s.PutBatch(map[string]any{"c/1": 4, "c/2": 5}); err != nil {
+		t.Fatal(err)
+	}
+	if keys, _ := s.Keys("c/"); len(keys) != 2 {
+		t.Fatalf("unexpected batch keys %v", keys)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = NewLevelStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var value int
+	if ok, err := s.Get("a/2", &value); err != nil || !ok || value != 3 {
+		t.Fatalf("Get: %v %v %d", ok, err, value)
+	}
+	if ok, _ := s.Get("b/1", &value); ok {
+		t.Fatalf("expected deleted key to be missing")
+	}
+}
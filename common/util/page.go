@@ -0,0 +1,20 @@
+package util
+
+// 分页，page 从 1 开始，pageSize 为 0 时默认 20 条
+func Paginate[T any](list []T, page, pageSize uint32) []T {
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = 20
+	}
+	start := int((page - 1) * pageSize)
+	if start >= len(list) {
+		return nil
+	}
+	end := start + int(pageSize)
+	if end > len(list) {
+		end = len(list)
+	}
+	return list[start:end]
+}
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// 解压公钥（兼容压缩和非压缩格式）
+func DecompressPublicKey(pubKeyBytes []byte) (*btcec.PublicKey, error) {
+	if len(pubKeyBytes) == 33 {
+		// 压缩公钥（0x02/0x03 开头）
+		return btcec.ParsePubKey(pubKeyBytes)
+	} else if len(pubKeyBytes) == 65 && pubKeyBytes[0] == 0x04 {
+		// 非压缩公钥（0x04 开头）
+		return btcec.ParsePubKey(pubKeyBytes)
+	} else {
+		return nil, fmt.Errorf("invalid public key format")
+	}
+}
@@ -13,17 +13,138 @@ type Server struct {
 }
 
 type Node struct {
+	RpcUrl       string   `yaml:"rpc_url"`
+	RpcUser      string   `yaml:"rpc_user"`
+	RpcPass      string   `yaml:"rpc_pass"`
+	DataApiUrl   string   `yaml:"data_api_url"`
+	DataApiKey   string   `yaml:"data_api_key"`
+	DataApiToken string   `yaml:"data_api_token"`
+	TimeOut      uint64   `yaml:"time_out"`
+	ChainId      uint64   `yaml:"chain_id"`
+	Network      string   `yaml:"network"` // 节点所在网络，为空时使用全局 network
+	Deposit      Deposit  `yaml:"deposit"`
+	Outgoing     Outgoing `yaml:"outgoing"`
+	Nonce        Nonce    `yaml:"nonce"`
+	Rpc          Rpc      `yaml:"rpc"`
+	Cache        Cache    `yaml:"cache"`
+}
+
+// Cosmos SDK 链配置，rpc_url 为 LCD（gRPC gateway）地址
+type CosmosNode struct {
+	Name         string `yaml:"name"`          // 请求中的 chain 字段，如 Cosmos、Osmosis
+	ChainId      string `yaml:"chain_id"`      // 如 cosmoshub-4、osmosis-1
+	Bech32Prefix string `yaml:"bech32_prefix"` // 地址前缀，如 cosmos、osmo
+	Denom        string `yaml:"denom"`         // 原生币种，如 uatom、uosmo
+	GasPrice     string `yaml:"gas_price"`     // 每单位 gas 的价格（denom 最小单位），如 0.025
 	RpcUrl       string `yaml:"rpc_url"`
-	RpcUser      string `yaml:"rpc_user"`
-	RpcPass      string `yaml:"rpc_pass"`
-	DataApiUrl   string `yaml:"data_api_url"`
-	DataApiKey   string `yaml:"data_api_key"`
-	DataApiToken string `yaml:"data_api_token"`
 	TimeOut      uint64 `yaml:"time_out"`
 }
 
+// EVM 节点 RPC 配置，未设置的能力项在启动时探测
+type Rpc struct {
+	Batch         *bool  `yaml:"batch"`          // 是否支持 JSON-RPC 批量请求
+	MaxBatchSize  int    `yaml:"max_batch_size"` // 单次批量请求的最大条数，为 0 时探测
+	MaxLogsRange  uint64 `yaml:"max_logs_range"` // eth_getLogs 单次查询的最大区块数，为 0 时探测
+	BlockReceipts *bool  `yaml:"block_receipts"` // 是否支持 eth_getBlockReceipts
+	FinalizedTag  *bool  `yaml:"finalized_tag"`  // 是否支持 finalized/safe 区块标签
+	Eip1559       *bool  `yaml:"eip1559"`        // 是否支持 EIP-1559
+	// 批量获取区块头时的分段配置
+	RangeChunkSize   int    `yaml:"range_chunk_size"`  // 每段的区块数，为 0 时使用 max_batch_size
+	RangeConcurrency int    `yaml:"range_concurrency"` // 同时请求的段数，默认 4
+	RangeAttempts    int    `yaml:"range_attempts"`    // 每段的最大尝试次数，默认 3
+	MaxBlockRange    uint64 `yaml:"max_block_range"`   // GetBlockByRange 单次请求的最大区块数，默认 10000
+	// 读请求的合并、短时缓存和对冲
+	Coalesce        *bool   `yaml:"coalesce"`         // 是否合并相同的并发读请求，默认开启
+	LatestTtl       uint64  `yaml:"latest_ttl"`       // latest 区块、手续费等结果的缓存时间（毫秒），为 0 时不缓存
+	HedgeUrl        string  `yaml:"hedge_url"`        // 备用节点，为空时不发送对冲请求
+	HedgePercentile float64 `yaml:"hedge_percentile"` // 主节点耗时超过该分位数时同时请求备用节点，默认 95
+	// 重试和熔断，每个节点单独熔断
+	RetryAttempts    int    `yaml:"retry_attempts"`    // 读请求遇到可重试错误时的最大尝试次数，默认 3
+	BreakerThreshold int    `yaml:"breaker_threshold"` // 连续失败多少次后熔断，默认 5
+	BreakerCooldown  uint64 `yaml:"breaker_cooldown"`  // 熔断后多久（秒）放行探测请求，默认 30
+}
+
+// 不可变数据缓存配置，只缓存按 hash 查询和已最终确认区块的数据
+type Cache struct {
+	Enable        bool   `yaml:"enable"`
+	Size          int    `yaml:"size"`           // 内存中缓存的最大条数，默认 10000
+	Persist       bool   `yaml:"persist"`        // 是否同时缓存到 data_dir，重启后保留
+	DiskSize      int    `yaml:"disk_size"`      // 磁盘缓存的最大条数，默认 50000
+	Confirmations uint64 `yaml:"confirmations"`  // 节点不支持 finalized 标签时，达到该确认数的区块视为已最终确认，默认 64
+	StatsInterval int    `yaml:"stats_interval"` // 输出命中统计日志的间隔秒数，默认 300
+}
+
+// 充值监控配置
+type Deposit struct {
+	Enable                 bool   `yaml:"enable"`
+	StartHeight            uint64 `yaml:"start_height"`            // 起始扫块高度，为 0 时从最新区块开始
+	Confirmations          uint64 `yaml:"confirmations"`           // 达到该确认数视为已确认
+	FinalizedConfirmations uint64 `yaml:"finalized_confirmations"` // 达到该确认数视为最终确认，为 0 时使用节点的 finalized 区块，节点不支持时按 64 个确认判断
+	ScanInterval           uint64 `yaml:"scan_interval"`           // 扫块间隔（秒）
+	Retention              uint64 `yaml:"retention"`               // 最终确认或已回滚的充值保留的区块数，超过后删除，为 0 时不删除
+}
+
+// 发出交易跟踪配置
+type Outgoing struct {
+	Enable         bool   `yaml:"enable"`
+	PollInterval   uint64 `yaml:"poll_interval"`   // 轮询间隔（秒）
+	DropBlocks     uint64 `yaml:"drop_blocks"`     // 连续多少个区块在 mempool 中查不到视为丢失
+	MaxRebroadcast int    `yaml:"max_rebroadcast"` // 丢失后最多重新广播的次数
+	Retention      uint64 `yaml:"retention"`       // 已完成的交易保留的区块数，超过后删除，为 0 时不删除
+}
+
+// nonce 分配配置
+type Nonce struct {
+	Enable         bool   `yaml:"enable"`
+	Persist        bool   `yaml:"persist"`         // 是否持久化到 data_dir，重启后保留已分配的 nonce
+	ReserveTimeout uint64 `yaml:"reserve_timeout"` // 分配后未广播的超时时间（秒），超时后可重新分配
+}
+
+// webhook 通知地址，events 为空时接收全部事件
+type Webhook struct {
+	Tenant string   `yaml:"tenant"`
+	Url    string   `yaml:"url"`
+	Secret string   `yaml:"secret"`
+	Events []string `yaml:"events"`
+}
+
+// 通知配置
+type Notify struct {
+	Webhooks    []Webhook `yaml:"webhooks"`
+	MaxAttempts int       `yaml:"max_attempts"` // 单次投递的最大重试次数，超过后进入死信
+	RetryMin    uint64    `yaml:"retry_min"`    // 重试最小间隔（秒）
+	RetryMax    uint64    `yaml:"retry_max"`    // 重试最大间隔（秒）
+	TimeOut     uint64    `yaml:"time_out"`     // 请求超时（秒）
+	Workers     int       `yaml:"workers"`
+	MaxPending  int       `yaml:"max_pending"` // 内存中待投递的最大数量，其余保留在存储中稍后加载，默认 1024
+}
+
 type WalletNode struct {
-	Eth Node `yaml:"eth"`
+	Eth     Node `yaml:"eth"`
+	Btc     Node `yaml:"btc"`
+	Ltc     Node `yaml:"ltc"`
+	Doge    Node `yaml:"doge"`
+	Bch     Node `yaml:"bch"`
+	Tron    Node `yaml:"tron"` // data_api_key 为 TronGrid 的 API key
+	Sol     Node `yaml:"sol"`
+	Dot     Node `yaml:"dot"` // network 为 testnet 时使用通用前缀 42（Westend、Paseo）
+	Ksm     Node `yaml:"ksm"`
+	Ton     Node `yaml:"ton"` // rpc_url 为 toncenter v2 接口，data_api_url 为 v3 索引接口
+	Apt     Node `yaml:"apt"` // rpc_url 为全节点 REST 地址（不含 /v1）
+	Sui     Node `yaml:"sui"`
+	Xrp     Node `yaml:"xrp"`  // rpc_url 为 rippled JSON-RPC 地址
+	Xlm     Node `yaml:"xlm"`  // rpc_url 为 Horizon 地址，network 为 testnet 时使用测试网 passphrase
+	Ada     Node `yaml:"ada"`  // rpc_url 为 Blockfrost 地址，data_api_key 为 project_id
+	Near    Node `yaml:"near"` // rpc_url 为 NEAR JSON-RPC 地址
+	Fil     Node `yaml:"fil"`  // rpc_url 为 Lotus JSON-RPC 地址，rpc_pass 为 API token，network 为 testnet 时使用 t 前缀地址
+	Op      Node `yaml:"op"`   // OP Stack 链，chain_id 为空时按 network 使用主网或测试网的 chain id
+	Base    Node `yaml:"base"`
+	Mantle  Node `yaml:"mantle"`
+	Arb     Node `yaml:"arb"` // Arbitrum One，network 为 testnet 时使用 Arbitrum Sepolia
+	ArbNova Node `yaml:"arb_nova"`
+	ZkSync  Node `yaml:"zksync"` // zkSync Era，network 为 testnet 时使用 zkSync Sepolia
+	// Cosmos SDK 链，每一项按 name 注册为一条链
+	Cosmos []CosmosNode `yaml:"cosmos"`
 }
 
 type Config struct {
@@ -31,6 +152,8 @@ type Config struct {
 	WalletNode WalletNode `yaml:"wallet_node"`
 	NetWork    string     `yaml:"network"`
 	Chains     []string   `yaml:"chains"`
+	DataDir    string     `yaml:"data_dir"`
+	Notify     Notify     `yaml:"notify"`
 }
 
 func NewConfig(path string) (*Config, error) {
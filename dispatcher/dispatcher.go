@@ -12,9 +12,28 @@ import (
 	"google.golang.org/grpc/status"
 
 	"chain-account/chain"
+	"chain-account/chain/aptos"
+	"chain-account/chain/arbitrum"
+	"chain-account/chain/bitcoin"
+	"chain-account/chain/cardano"
+	"chain-account/chain/cosmos"
 	"chain-account/chain/ethereum"
+	"chain-account/chain/filecoin"
+	"chain-account/chain/near"
+	"chain-account/chain/opstack"
+	"chain-account/chain/solana"
+	"chain-account/chain/stellar"
+	"chain-account/chain/substrate"
+	"chain-account/chain/sui"
+	"chain-account/chain/ton"
+	"chain-account/chain/tron"
+	"chain-account/chain/xrp"
+	"chain-account/chain/zksync"
 	"chain-account/common/global_const"
+	"chain-account/common/store"
+	"chain-account/common/util"
 	"chain-account/config"
+	"chain-account/notifier"
 	"chain-account/rpc/account"
 )
 
@@ -31,6 +50,7 @@ type CommonReply struct {
 
 type ChainDispatcher struct {
 	registry map[string]chain.IChainAdaptor // 每一条链 都对应一套接口
+	webhook  *notifier.WebhookNotifier
 }
 
 // 初始化适配器
@@ -40,10 +60,73 @@ func NewChainDispatcher(conf *config.Config) (*ChainDispatcher, error) {
 	}
 
 	chainAdaptorFactoryMap := map[string]func(*config.Config) (chain.IChainAdaptor, error){
-		ethereum.ChainName: ethereum.NewChainAdaptor,
+		ethereum.ChainName:           ethereum.NewChainAdaptor,
+		bitcoin.ChainName:            bitcoin.NewChainAdaptor,
+		bitcoin.LitecoinChainName:    bitcoin.NewLitecoinAdaptor,
+		bitcoin.DogecoinChainName:    bitcoin.NewDogecoinAdaptor,
+		bitcoin.BitcoinCashChainName: bitcoin.NewBitcoinCashAdaptor,
+		tron.ChainName:               tron.NewChainAdaptor,
+		solana.ChainName:             solana.NewChainAdaptor,
+		substrate.PolkadotChainName:  substrate.NewChainAdaptor,
+		substrate.KusamaChainName:    substrate.NewKusamaAdaptor,
+		ton.ChainName:                ton.NewChainAdaptor,
+		aptos.ChainName:              aptos.NewChainAdaptor,
+		sui.ChainName:                sui.NewChainAdaptor,
+		xrp.ChainName:                xrp.NewChainAdaptor,
+		stellar.ChainName:            stellar.NewChainAdaptor,
+		cardano.ChainName:            cardano.NewChainAdaptor,
+		near.ChainName:               near.NewChainAdaptor,
+		filecoin.ChainName:           filecoin.NewChainAdaptor,
+		opstack.ChainName:            opstack.NewChainAdaptor,
+		opstack.BaseChainName:        opstack.NewBaseAdaptor,
+		opstack.MantleChainName:      opstack.NewMantleAdaptor,
+		arbitrum.ChainName:           arbitrum.NewChainAdaptor,
+		arbitrum.NovaChainName:       arbitrum.NewNovaAdaptor,
+		zksync.ChainName:             zksync.NewChainAdaptor,
 	}
 	supportedChains := []string{
 		ethereum.ChainName,
+		bitcoin.ChainName,
+		bitcoin.LitecoinChainName,
+		bitcoin.DogecoinChainName,
+		bitcoin.BitcoinCashChainName,
+		tron.ChainName,
+		solana.ChainName,
+		substrate.PolkadotChainName,
+		substrate.KusamaChainName,
+		ton.ChainName,
+		aptos.ChainName,
+		sui.ChainName,
+		xrp.ChainName,
+		stellar.ChainName,
+		cardano.ChainName,
+		near.ChainName,
+		filecoin.ChainName,
+		opstack.ChainName,
+		opstack.BaseChainName,
+		opstack.MantleChainName,
+		arbitrum.ChainName,
+		arbitrum.NovaChainName,
+		zksync.ChainName,
+	}
+	// Cosmos SDK 链按配置注册，链名称即配置中的 name
+	for _, node := range conf.WalletNode.Cosmos {
+		chainAdaptorFactoryMap[node.Name] = func(*config.Config) (chain.IChainAdaptor, error) {
+			return cosmos.NewChainAdaptor(node)
+		}
+		supportedChains = append(supportedChains, node.Name)
+	}
+
+	// webhook 通知
+	if len(conf.Notify.Webhooks) > 0 {
+		webhookStore, err := store.NewStore(store.Path(conf.DataDir, "webhook"))
+		if err != nil {
+			return nil, err
+		}
+		dispatcher.webhook = notifier.NewWebhookNotifier(conf.Notify, webhookStore)
+		if err := dispatcher.webhook.Start(conf.Notify.Workers); err != nil {
+			return nil, err
+		}
 	}
 
 	for _, chainName := range conf.Chains {
@@ -52,6 +135,12 @@ func NewChainDispatcher(conf *config.Config) (*ChainDispatcher, error) {
 			if err != nil {
 				log.Crit("failed to setup chain", "chain", chainName, "error", err)
 			}
+			if source, ok := adaptor.(chain.IEventSource); ok && dispatcher.webhook != nil {
+				source.SetNotifier(dispatcher.webhook)
+			}
+			if background, ok := adaptor.(chain.IBackgroundAdaptor); ok {
+				background.Start()
+			}
 			dispatcher.registry[chainName] = adaptor
 		} else {
 			log.Error("unsupported chain", "chain", chainName, "supportedChains", supportedChains)
@@ -303,6 +392,240 @@ func (d *ChainDispatcher) GetExtraData(ctx context.Context, request *account.Ext
 	return d.registry[chainName].GetExtraData(request)
 }
 
+func (d *ChainDispatcher) WatchAddress(ctx context.Context, request *account.WatchAddressRequest) (*account.WatchAddressResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.WatchAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "watch address fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.IDepositAdaptor)
+	if !ok {
+		return &account.WatchAddressResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.WatchAddress(request)
+}
+
+func (d *ChainDispatcher) GetDepositList(ctx context.Context, request *account.DepositListRequest) (*account.DepositListResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.DepositListResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get deposit list fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.IDepositAdaptor)
+	if !ok {
+		return &account.DepositListResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.GetDepositList(request)
+}
+
+func (d *ChainDispatcher) GetDepositNotify(ctx context.Context, request *account.DepositNotifyRequest) (*account.DepositNotifyResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.DepositNotifyResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get deposit notify fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.IDepositAdaptor)
+	if !ok {
+		return &account.DepositNotifyResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.GetDepositNotify(request)
+}
+
+func (d *ChainDispatcher) AckDepositNotify(ctx context.Context, request *account.AckDepositNotifyRequest) (*account.AckDepositNotifyResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.AckDepositNotifyResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "ack deposit notify fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.IDepositAdaptor)
+	if !ok {
+		return &account.AckDepositNotifyResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.AckDepositNotify(request)
+}
+
+func (d *ChainDispatcher) ListOutgoingTx(ctx context.Context, request *account.OutgoingTxListRequest) (*account.OutgoingTxListResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.OutgoingTxListResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "list outgoing tx fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.IOutgoingAdaptor)
+	if !ok {
+		return &account.OutgoingTxListResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.ListOutgoingTx(request)
+}
+
+func (d *ChainDispatcher) BuildSpeedUpTransaction(ctx context.Context, request *account.ReplaceTransactionRequest) (*account.ReplaceTransactionResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.ReplaceTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "build speed up tx fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.IReplaceAdaptor)
+	if !ok {
+		return &account.ReplaceTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.BuildSpeedUpTransaction(request)
+}
+
+func (d *ChainDispatcher) BuildCancelTransaction(ctx context.Context, request *account.ReplaceTransactionRequest) (*account.ReplaceTransactionResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.ReplaceTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "build cancel tx fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.IReplaceAdaptor)
+	if !ok {
+		return &account.ReplaceTransactionResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.BuildCancelTransaction(request)
+}
+
+func (d *ChainDispatcher) GetNextNonce(ctx context.Context, request *account.NextNonceRequest) (*account.NextNonceResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.NextNonceResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get next nonce fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.INonceAdaptor)
+	if !ok {
+		return &account.NextNonceResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.GetNextNonce(request)
+}
+
+func (d *ChainDispatcher) ReleaseNonce(ctx context.Context, request *account.ReleaseNonceRequest) (*account.ReleaseNonceResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.ReleaseNonceResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "release nonce fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.INonceAdaptor)
+	if !ok {
+		return &account.ReleaseNonceResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.ReleaseNonce(request)
+}
+
+func (d *ChainDispatcher) GetNonceGaps(ctx context.Context, request *account.NonceGapsRequest) (*account.NonceGapsResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.NonceGapsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get nonce gaps fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.INonceAdaptor)
+	if !ok {
+		return &account.NonceGapsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.GetNonceGaps(request)
+}
+
+func (d *ChainDispatcher) GetUnspentOutputs(ctx context.Context, request *account.UnspentOutputsRequest) (*account.UnspentOutputsResponse, error) {
+	resp, chainName := d.preHandler(request)
+	if resp != nil {
+		return &account.UnspentOutputsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get unspent outputs fail at pre handle",
+		}, nil
+	}
+	adaptor, ok := d.registry[chainName].(chain.IUtxoAdaptor)
+	if !ok {
+		return &account.UnspentOutputsResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  config.UnsupportedOperation,
+		}, nil
+	}
+	return adaptor.GetUnspentOutputs(request)
+}
+
+// webhook 死信不区分链，chain 字段仅用于日志
+func (d *ChainDispatcher) GetWebhookDeadLetters(ctx context.Context, request *account.WebhookDeadLetterRequest) (*account.WebhookDeadLetterResponse, error) {
+	if d.webhook == nil {
+		return &account.WebhookDeadLetterResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "webhook not configured",
+		}, nil
+	}
+	deliveries, err := d.webhook.DeadLetters(request.Tenant)
+	if err != nil {
+		log.Error("get webhook dead letters fail", "err", err)
+		return &account.WebhookDeadLetterResponse{
+			Code: global_const.ReturnCode_ERROR,
+			Msg:  "get webhook dead letters fail",
+		}, nil
+	}
+	var deliveryList []*account.WebhookDelivery
+	for _, delivery := range util.Paginate(deliveries, request.Page, request.Pagesize) {
+		deliveryList = append(deliveryList, &account.WebhookDelivery{
+			Id:        delivery.Id,
+			Tenant:    delivery.Tenant,
+			Url:       delivery.Url,
+			EventType: string(delivery.EventType),
+			Payload:   string(delivery.Payload),
+			Attempts:  uint32(delivery.Attempts),
+			LastError: delivery.LastError,
+			CreatedAt: delivery.CreatedAt,
+		})
+	}
+	return &account.WebhookDeadLetterResponse{
+		Code:       global_const.ReturnCode_SUCCESS,
+		Msg:        "get webhook dead letters success",
+		Deliveries: deliveryList,
+	}, nil
+}
+
 func (d *ChainDispatcher) GetNftListByAddress(ctx context.Context, request *account.NftAddressRequest) (*account.NftAddressResponse, error) {
 	panic("implement me")
 }
@@ -0,0 +1,53 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// 事件类型
+type EventType string
+
+const (
+	EventDepositDetected  EventType = "deposit.detected"
+	EventDepositConfirmed EventType = "deposit.confirmed"
+	EventDepositFinalized EventType = "deposit.finalized"
+	EventDepositReorged   EventType = "deposit.reorged"
+	EventTxMined          EventType = "tx.mined"
+	EventTxFailed         EventType = "tx.failed"
+	EventTxDropped        EventType = "tx.dropped"
+)
+
+// 通知事件
+type Event struct {
+	Id        string          `json:"id"`
+	Type      EventType       `json:"type"`
+	Chain     string          `json:"chain"`
+	Timestamp int64           `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// 通知发送方，可替换为 webhook、消息队列等不同实现
+type Notifier interface {
+	Notify(event *Event) error
+}
+
+var eventSeq atomic.Uint64
+
+// 创建事件，data 序列化为 JSON
+func NewEvent(eventType EventType, chain string, data any) (*Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Event{
+		Id:        fmt.Sprintf("%d%06d", now.UnixNano(), eventSeq.Add(1)%1000000),
+		Type:      eventType,
+		Chain:     chain,
+		Timestamp: now.Unix(),
+		Data:      raw,
+	}, nil
+}
@@ -0,0 +1,416 @@
+package notifier
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"chain-account/common/retry"
+	"chain-account/common/store"
+	"chain-account/config"
+)
+
+const (
+	defaultMaxAttempts = 8
+	defaultRetryMin    = time.Second
+	defaultRetryMax    = 5 * time.Minute
+	defaultTimeout     = 10 * time.Second
+	defaultWorkers     = 4
+	defaultMaxPending  = 1024
+	reloadInterval     = 5 * time.Second
+
+	queueKeyPrefix = "queue/"
+	deadKeyPrefix  = "dead/"
+
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+)
+
+// 一次 webhook 投递
+type Delivery struct {
+	Id        string    `json:"id"`
+	Tenant    string    `json:"tenant"`
+	Url       string    `json:"url"`
+	EventType EventType `json:"event_type"`
+	Payload   []byte    `json:"payload"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	CreatedAt int64     `json:"created_at"`
+	// 下次投递时间（毫秒时间戳），失败后按退避策略推迟，不占用投递协程等待
+	NextAttempt int64 `json:"next_attempt"`
+}
+
+// 按下次投递时间排序的小顶堆
+type deliveryHeap []*Delivery
+
+func (h deliveryHeap) Len() int           { return len(h) }
+func (h deliveryHeap) Less(i, j int) bool { return h[i].NextAttempt < h[j].NextAttempt }
+func (h deliveryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *deliveryHeap) Push(x any)        { *h = append(*h, x.(*Delivery)) }
+func (h *deliveryHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// webhook 通知：按租户配置推送签名后的 JSON，失败的投递持久化在队列中并记录下次投递时间，
+// 到期后重新投递，重试耗尽或遇到不可重试的错误后进入死信。内存中最多保留 maxPending 个投递，其余留在存储中稍后加载。
+// 每次投递通过 retry.Step 执行，与 retry.Do 共用错误分类和退避策略，但等待由调度器完成，不阻塞投递协程且重启后不丢失
+type WebhookNotifier struct {
+	webhooks    []config.Webhook
+	store       store.Store
+	client      *http.Client
+	maxAttempts int
+	maxPending  int
+	strategy    retry.Strategy
+
+	mu       sync.Mutex
+	pending  map[string]struct{} // 已加载到内存中的投递
+	delayed  deliveryHeap        // 等待到期的投递
+	overflow bool                // 存储中还有未加载的投递
+	wake     chan struct{}
+
+	ready chan *Delivery
+	ctx   context.Context
+	stop  context.CancelFunc
+	wg    sync.WaitGroup
+}
+
+func NewWebhookNotifier(conf config.Notify, db store.Store) *WebhookNotifier {
+	maxAttempts := conf.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	retryMin, retryMax, timeout := defaultRetryMin, defaultRetryMax, defaultTimeout
+	if conf.RetryMin > 0 {
+		retryMin = time.Duration(conf.RetryMin) * time.Second
+	}
+	if conf.RetryMax > 0 {
+		retryMax = time.Duration(conf.RetryMax) * time.Second
+	}
+	if conf.TimeOut > 0 {
+		timeout = time.Duration(conf.TimeOut) * time.Second
+	}
+	maxPending := conf.MaxPending
+	if maxPending <= 0 {
+		maxPending = defaultMaxPending
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WebhookNotifier{
+		webhooks:    conf.Webhooks,
+		store:       db,
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+		maxPending:  maxPending,
+		strategy: &retry.ExponentialStrategy{
+			Min:       retryMin,
+			Max:       retryMax,
+			MaxJitter: 250 * time.Millisecond,
+		},
+		pending: make(map[string]struct{}),
+		wake:    make(chan struct{}, 1),
+		ready:   make(chan *Delivery, maxPending),
+		ctx:     ctx,
+		stop:    cancel,
+	}
+}
+
+// 启动投递协程，并重新投递上次未完成的队列
+func (w *WebhookNotifier) Start(workers int) error {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if err := w.reload(); err != nil {
+		return err
+	}
+	for i := 0; i < workers; i++ {
+		w.wg.Add(1)
+		go w.work()
+	}
+	w.wg.Add(1)
+	go w.schedule()
+	return nil
+}
+
+// 停止投递，未完成的投递保留在队列中，下次启动时继续
+func (w *WebhookNotifier) Stop() {
+	w.stop()
+	w.wg.Wait()
+}
+
+// 将事件写入每个订阅了该事件的 webhook 的投递队列
+func (w *WebhookNotifier) Notify(event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	for i, hook := range w.webhooks {
+		if !subscribed(hook, event.Type) {
+			continue
+		}
+		delivery := &Delivery{
+			Id:        fmt.Sprintf("%s-%02d", event.Id, i),
+			Tenant:    hook.Tenant,
+			Url:       hook.Url,
+			EventType: event.Type,
+			Payload:   payload,
+			CreatedAt: event.Timestamp,
+		}
+		if err := w.store.Put(queueKeyPrefix+delivery.Id, delivery); err != nil {
+			return err
+		}
+		w.enqueue(delivery)
+	}
+	return nil
+}
+
+// 加入内存队列，超过 maxPending 时只保留在存储中，等有空位时再加载
+func (w *WebhookNotifier) enqueue(delivery *Delivery) {
+	w.mu.Lock()
+	if _, ok := w.pending[delivery.Id]; ok {
+		w.mu.Unlock()
+		return
+	}
+	if len(w.pending) >= w.maxPending {
+		w.overflow = true
+		w.mu.Unlock()
+		return
+	}
+	w.pending[delivery.Id] = struct{}{}
+	heap.Push(&w.delayed, delivery)
+	w.mu.Unlock()
+	w.signal()
+}
+
+func (w *WebhookNotifier) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// 从存储中加载未在内存中的投递
+func (w *WebhookNotifier) reload() error {
+	w.mu.Lock()
+	w.overflow = false
+	w.mu.Unlock()
+	keys, err := w.store.Keys(queueKeyPrefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		w.mu.Lock()
+		_, loaded := w.pending[key[len(queueKeyPrefix):]]
+		full := len(w.pending) >= w.maxPending
+		if full {
+			w.overflow = true
+		}
+		w.mu.Unlock()
+		if full {
+			break
+		}
+		if loaded {
+			continue
+		}
+		delivery := new(Delivery)
+		if ok, err := w.store.Get(key, delivery); err != nil || !ok {
+			continue
+		}
+		w.enqueue(delivery)
+	}
+	return nil
+}
+
+// 把到期的投递交给投递协程；ready 的容量等于 maxPending，发送不会阻塞
+func (w *WebhookNotifier) schedule() {
+	defer w.wg.Done()
+	for {
+		now := time.Now().UnixMilli()
+		next := now + reloadInterval.Milliseconds()
+		w.mu.Lock()
+		for w.delayed.Len() > 0 && w.delayed[0].NextAttempt <= now {
+			w.ready <- heap.Pop(&w.delayed).(*Delivery)
+		}
+		if w.delayed.Len() > 0 {
+			next = min(next, w.delayed[0].NextAttempt)
+		}
+		overflow := w.overflow && len(w.pending) < w.maxPending
+		w.mu.Unlock()
+		if overflow {
+			if err := w.reload(); err != nil {
+				log.Error("reload webhook queue fail", "err", err)
+			}
+			continue
+		}
+
+		timer := time.NewTimer(time.Duration(next-now) * time.Millisecond)
+		select {
+		case <-w.ctx.Done():
+			timer.Stop()
+			return
+		case <-w.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (w *WebhookNotifier) work() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case delivery := <-w.ready:
+			w.deliver(delivery)
+		}
+	}
+}
+
+// 投递一次事件，失败时记录下次投递时间后放回队列，重试耗尽或错误不可重试时移入死信
+func (w *WebhookNotifier) deliver(delivery *Delivery) {
+	// 被 retry.Permanent 标记的 4xx 直接进入死信，可重试的错误按退避策略推迟，响应带 Retry-After 时至少等待该时间
+	_, delay, err := retry.Step(delivery.Attempts, w.maxAttempts, w.strategy, func() (struct{}, error) {
+		return struct{}{}, w.post(delivery)
+	})
+	// 服务停止导致的中断保留在队列中
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if err == nil {
+		w.done(delivery)
+		return
+	}
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+	log.Warn("webhook delivery fail", "id", delivery.Id, "url", delivery.Url, "attempts", delivery.Attempts, "err", err)
+	var failed *retry.ErrFailedPermanently
+	if !errors.As(err, &failed) {
+		delivery.NextAttempt = time.Now().Add(delay).UnixMilli()
+		if err := w.store.Put(queueKeyPrefix+delivery.Id, delivery); err != nil {
+			log.Error("save webhook delivery fail", "id", delivery.Id, "err", err)
+		}
+		w.mu.Lock()
+		heap.Push(&w.delayed, delivery)
+		w.mu.Unlock()
+		w.signal()
+		return
+	}
+
+	log.Error("webhook delivery failed permanently", "id", delivery.Id, "url", delivery.Url, "err", err)
+	if err := w.store.Put(deadKeyPrefix+delivery.Id, delivery); err != nil {
+		log.Error("save webhook dead letter fail", "id", delivery.Id, "err", err)
+		return
+	}
+	w.done(delivery)
+}
+
+// 投递结束，从队列中删除并释放内存中的位置
+func (w *WebhookNotifier) done(delivery *Delivery) {
+	if err := w.store.Delete(queueKeyPrefix + delivery.Id); err != nil {
+		log.Error("delete webhook delivery fail", "id", delivery.Id, "err", err)
+	}
+	w.mu.Lock()
+	delete(w.pending, delivery.Id)
+	w.mu.Unlock()
+	w.signal()
+}
+
+func (w *WebhookNotifier) post(delivery *Delivery) error {
+	secret := ""
+	for _, hook := range w.webhooks {
+		if hook.Tenant == delivery.Tenant && hook.Url == delivery.Url {
+			secret = hook.Secret
+			break
+		}
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	if after, ok := retry.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		return retry.RetryAfter(err, after)
+	}
+	// 408、429 以外的 4xx 重试也不会成功
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return retry.Permanent(err)
+	}
+	return err
+}
+
+// 查询死信，tenant 为空时返回全部
+func (w *WebhookNotifier) DeadLetters(tenant string) ([]*Delivery, error) {
+	keys, err := w.store.Keys(deadKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	var deliveries []*Delivery
+	for _, key := range keys {
+		delivery := new(Delivery)
+		if _, err := w.store.Get(key, delivery); err != nil {
+			return nil, err
+		}
+		if tenant != "" && delivery.Tenant != tenant {
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt > deliveries[j].CreatedAt
+	})
+	return deliveries, nil
+}
+
+// 签名：hex(HMAC-SHA256(secret, timestamp + "." + body))，时间戳参与签名防止重放
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func subscribed(hook config.Webhook, eventType EventType) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, item := range hook.Events {
+		if EventType(item) == eventType {
+			return true
+		}
+	}
+	return false
+}
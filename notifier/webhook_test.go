@@ -0,0 +1,254 @@
+package notifier
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"chain-account/common/retry"
+	"chain-account/common/store"
+	"chain-account/config"
+)
+
+func newTestNotifier(hooks []config.Webhook, maxAttempts int) *WebhookNotifier {
+	w := NewWebhookNotifier(config.Notify{
+		Webhooks:    hooks,
+		MaxAttempts: maxAttempts,
+	}, store.NewMemoryStore())
+	w.strategy = retry.Fixed(10 * time.Millisecond)
+	return w
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func Test_WebhookSignedDeliveryWithRetry(t *testing.T) {
+	var calls, delivered atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderSignature) != Sign("secret", r.Header.Get(HeaderTimestamp), body) {
+			t.Errorf("bad signature")
+		}
+		if r.Header.Get(HeaderEvent) != string(EventDepositDetected) {
+			t.Errorf("unexpected event header %s", r.Header.Get(HeaderEvent))
+		}
+		// 前两次返回 500，触发重试
+		if calls.Add(1) <= 2 {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		delivered.Add(1)
+	}))
+	defer server.Close()
+
+	w := newTestNotifier([]config.Webhook{
+		{Tenant: "a", Url: server.URL, Secret: "secret"},
+		{Tenant: "b", Url: server.URL, Secret: "other", Events: []string{string(EventTxMined)}},
+	}, 5)
+	if err := w.Start(1); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	event, _ := NewEvent(EventDepositDetected, "Ethereum", map[string]string{"hash": "0x01"})
+	if err := w.Notify(event); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return delivered.Load() == 1 })
+
+	waitFor(t, func() bool {
+		keys, _ := w.store.Keys(queueKeyPrefix)
+		return len(keys) == 0
+	})
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls.Load())
+	}
+}
+
+func Test_WebhookDeadLetter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	w := newTestNotifier([]config.Webhook{{Tenant: "a", Url: server.URL, Secret: "secret"}}, 3)
+	if err := w.Start(1); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	event, _ := NewEvent(EventTxFailed, "Ethereum", map[string]string{"hash": "0x02"})
+	if err := w.Notify(event); err != nil {
+		t.Fatal(err)
+	}
+	var dead []*Delivery
+	waitFor(t, func() bool {
+		dead, _ = w.DeadLetters("a")
+		return len(dead) == 1
+	})
+	if dead[0].Attempts != 3 || dead[0].LastError == "" {
+		t.Fatalf("unexpected dead letter %+v", dead[0])
+	}
+	if other, _ := w.DeadLetters("b"); len(other) != 0 {
+		t.Fatalf("expected no dead letters for tenant b, got %d", len(other))
+	}
+}
+
+func Test_WebhookRetryClassification(t *testing.T) {
+	var rejected, limited atomic.Int32
+	reject := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rejected.Add(1)
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	defer reject.Close()
+	limit := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		limited.Add(1)
+		rw.Header().Set("Retry-After", "3600")
+		rw.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer limit.Close()
+
+	w := newTestNotifier([]config.Webhook{
+		{Tenant: "a", Url: reject.URL, Secret: "secret"},
+		{Tenant: "b", Url: limit.URL, Secret: "secret"},
+	}, 5)
+	if err := w.Start(1); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	event, _ := NewEvent(EventTxMined, "Ethereum", map[string]string{"hash": "0x04"})
+	if err := w.Notify(event); err != nil {
+		t.Fatal(err)
+	}
+
+	// 4xx 不重试，直接进入死信
+	var dead []*Delivery
+	waitFor(t, func() bool {
+		dead, _ = w.DeadLetters("a")
+		return len(dead) == 1
+	})
+	if dead[0].Attempts != 1 || rejected.Load() != 1 {
+		t.Fatalf("unexpected dead letter %+v, calls %d", dead[0], rejected.Load())
+	}
+
+	// 429 按 Retry-After 推迟，不使用更短的退避时间
+	waitFor(t, func() bool {
+		keys, _ := w.store.Keys(queueKeyPrefix)
+		if len(keys) != 1 {
+			return false
+		}
+		delivery := new(Delivery)
+		_, _ = w.store.Get(keys[0], delivery)
+		return delivery.Attempts == 1 && delivery.NextAttempt >= time.Now().Add(59*time.Minute).UnixMilli()
+	})
+	if limited.Load() != 1 {
+		t.Fatalf("expected one rate limited call, got %d", limited.Load())
+	}
+}
+
+func Test_WebhookQueueSurvivesRestart(t *testing.T) {
+	var delivered atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+	}))
+	defer server.Close()
+
+	db := store.NewMemoryStore()
+	hooks := []config.Webhook{{Tenant: "a", Url: server.URL, Secret: "secret"}}
+	// 未启动投递协程时写入的事件保留在持久化队列中
+	w := NewWebhookNotifier(config.Notify{Webhooks: hooks}, db)
+	event, _ := NewEvent(EventTxDropped, "Ethereum", map[string]string{"hash": "0x03"})
+	if err := w.Notify(event); err != nil {
+		t.Fatal(err)
+	}
+	w.Stop()
+
+	restarted := NewWebhookNotifier(config.Notify{Webhooks: hooks}, db)
+	if err := restarted.Start(1); err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Stop()
+	waitFor(t, func() bool { return delivered.Load() == 1 })
+}
+
+func Test_WebhookFailingTenantDoesNotBlock(t *testing.T) {
+	var failed atomic.Int32
+	down := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		failed.Add(1)
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	var delivered atomic.Int32
+	up := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+	}))
+	defer up.Close()
+
+	w := newTestNotifier([]config.Webhook{
+		{Tenant: "a", Url: down.URL, Secret: "secret"},
+		{Tenant: "b", Url: up.URL, Secret: "secret"},
+	}, 5)
+	// 失败的投递推迟到一小时后，不占用唯一的投递协程
+	w.strategy = retry.Fixed(time.Hour)
+	if err := w.Start(1); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	for i := 0; i < 3; i++ {
+		event, _ := NewEvent(EventDepositDetected, "Ethereum", map[string]int{"index": i})
+		if err := w.Notify(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool { return delivered.Load() == 3 })
+	if failed.Load() != 3 {
+		t.Fatalf("expected each failing delivery to be tried once, got %d", failed.Load())
+	}
+	keys, _ := w.store.Keys(queueKeyPrefix)
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 delayed deliveries, got %d", len(keys))
+	}
+	delivery := new(Delivery)
+	_, _ = w.store.Get(keys[0], delivery)
+	if delivery.Attempts != 1 || delivery.NextAttempt < time.Now().Add(59*time.Minute).UnixMilli() {
+		t.Fatalf("unexpected delayed delivery %+v", delivery)
+	}
+}
+
+func Test_WebhookPendingBound(t *testing.T) {
+	var delivered atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+	}))
+	defer server.Close()
+
+	w := NewWebhookNotifier(config.Notify{
+		Webhooks:   []config.Webhook{{Tenant: "a", Url: server.URL, Secret: "secret"}},
+		MaxPending: 2,
+	}, store.NewMemoryStore())
+	for i := 0; i < 5; i++ {
+		event, _ := NewEvent(EventTxMined, "Ethereum", map[string]int{"index": i})
+		if err := w.Notify(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 超出的投递只保留在存储中
+	if len(w.pending) != 2 || !w.overflow {
+		t.Fatalf("expected 2 pending deliveries, got %d", len(w.pending))
+	}
+	if err := w.Start(1); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	waitFor(t, func() bool { return delivered.Load() == 5 })
+}
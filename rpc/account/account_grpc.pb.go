@@ -43,6 +43,18 @@ const (
 	WalletAccountService_GetNftHolderList_FullMethodName          = "/dapplink.account.WalletAccountService/getNftHolderList"
 	WalletAccountService_GetNftTradeHistory_FullMethodName        = "/dapplink.account.WalletAccountService/getNftTradeHistory"
 	WalletAccountService_GetAddressNftTradeHistory_FullMethodName = "/dapplink.account.WalletAccountService/getAddressNftTradeHistory"
+	WalletAccountService_WatchAddress_FullMethodName              = "/dapplink.account.WalletAccountService/watchAddress"
+	WalletAccountService_GetDepositList_FullMethodName            = "/dapplink.account.WalletAccountService/getDepositList"
+	WalletAccountService_GetDepositNotify_FullMethodName          = "/dapplink.account.WalletAccountService/getDepositNotify"
+	WalletAccountService_AckDepositNotify_FullMethodName          = "/dapplink.account.WalletAccountService/ackDepositNotify"
+	WalletAccountService_GetWebhookDeadLetters_FullMethodName     = "/dapplink.account.WalletAccountService/getWebhookDeadLetters"
+	WalletAccountService_ListOutgoingTx_FullMethodName            = "/dapplink.account.WalletAccountService/listOutgoingTx"
+	WalletAccountService_BuildSpeedUpTransaction_FullMethodName   = "/dapplink.account.WalletAccountService/buildSpeedUpTransaction"
+	WalletAccountService_BuildCancelTransaction_FullMethodName    = "/dapplink.account.WalletAccountService/buildCancelTransaction"
+	WalletAccountService_GetNextNonce_FullMethodName              = "/dapplink.account.WalletAccountService/getNextNonce"
+	WalletAccountService_ReleaseNonce_FullMethodName              = "/dapplink.account.WalletAccountService/releaseNonce"
+	WalletAccountService_GetNonceGaps_FullMethodName              = "/dapplink.account.WalletAccountService/getNonceGaps"
+	WalletAccountService_GetUnspentOutputs_FullMethodName         = "/dapplink.account.WalletAccountService/getUnspentOutputs"
 )
 
 // WalletAccountServiceClient is the client API for WalletAccountService service.
@@ -73,6 +85,18 @@ type WalletAccountServiceClient interface {
 	GetNftHolderList(ctx context.Context, in *NftHolderListRequest, opts ...grpc.CallOption) (*NftHolderListResponse, error)
 	GetNftTradeHistory(ctx context.Context, in *NftTradeHistoryRequest, opts ...grpc.CallOption) (*NftTradeHistoryResponse, error)
 	GetAddressNftTradeHistory(ctx context.Context, in *AddressNftTradeHistoryRequest, opts ...grpc.CallOption) (*AddressNftTradeHistoryResponse, error)
+	WatchAddress(ctx context.Context, in *WatchAddressRequest, opts ...grpc.CallOption) (*WatchAddressResponse, error)
+	GetDepositList(ctx context.Context, in *DepositListRequest, opts ...grpc.CallOption) (*DepositListResponse, error)
+	GetDepositNotify(ctx context.Context, in *DepositNotifyRequest, opts ...grpc.CallOption) (*DepositNotifyResponse, error)
+	AckDepositNotify(ctx context.Context, in *AckDepositNotifyRequest, opts ...grpc.CallOption) (*AckDepositNotifyResponse, error)
+	GetWebhookDeadLetters(ctx context.Context, in *WebhookDeadLetterRequest, opts ...grpc.CallOption) (*WebhookDeadLetterResponse, error)
+	ListOutgoingTx(ctx context.Context, in *OutgoingTxListRequest, opts ...grpc.CallOption) (*OutgoingTxListResponse, error)
+	BuildSpeedUpTransaction(ctx context.Context, in *ReplaceTransactionRequest, opts ...grpc.CallOption) (*ReplaceTransactionResponse, error)
+	BuildCancelTransaction(ctx context.Context, in *ReplaceTransactionRequest, opts ...grpc.CallOption) (*ReplaceTransactionResponse, error)
+	GetNextNonce(ctx context.Context, in *NextNonceRequest, opts ...grpc.CallOption) (*NextNonceResponse, error)
+	ReleaseNonce(ctx context.Context, in *ReleaseNonceRequest, opts ...grpc.CallOption) (*ReleaseNonceResponse, error)
+	GetNonceGaps(ctx context.Context, in *NonceGapsRequest, opts ...grpc.CallOption) (*NonceGapsResponse, error)
+	GetUnspentOutputs(ctx context.Context, in *UnspentOutputsRequest, opts ...grpc.CallOption) (*UnspentOutputsResponse, error)
 }
 
 type walletAccountServiceClient struct {
@@ -323,6 +347,126 @@ func (c *walletAccountServiceClient) GetAddressNftTradeHistory(ctx context.Conte
 	return out, nil
 }
 
+func (c *walletAccountServiceClient) WatchAddress(ctx context.Context, in *WatchAddressRequest, opts ...grpc.CallOption) (*WatchAddressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WatchAddressResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_WatchAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) GetDepositList(ctx context.Context, in *DepositListRequest, opts ...grpc.CallOption) (*DepositListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DepositListResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetDepositList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) GetDepositNotify(ctx context.Context, in *DepositNotifyRequest, opts ...grpc.CallOption) (*DepositNotifyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DepositNotifyResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetDepositNotify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) AckDepositNotify(ctx context.Context, in *AckDepositNotifyRequest, opts ...grpc.CallOption) (*AckDepositNotifyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AckDepositNotifyResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_AckDepositNotify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) GetWebhookDeadLetters(ctx context.Context, in *WebhookDeadLetterRequest, opts ...grpc.CallOption) (*WebhookDeadLetterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WebhookDeadLetterResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetWebhookDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) ListOutgoingTx(ctx context.Context, in *OutgoingTxListRequest, opts ...grpc.CallOption) (*OutgoingTxListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OutgoingTxListResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_ListOutgoingTx_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) BuildSpeedUpTransaction(ctx context.Context, in *ReplaceTransactionRequest, opts ...grpc.CallOption) (*ReplaceTransactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplaceTransactionResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_BuildSpeedUpTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) BuildCancelTransaction(ctx context.Context, in *ReplaceTransactionRequest, opts ...grpc.CallOption) (*ReplaceTransactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplaceTransactionResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_BuildCancelTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) GetNextNonce(ctx context.Context, in *NextNonceRequest, opts ...grpc.CallOption) (*NextNonceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NextNonceResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetNextNonce_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) ReleaseNonce(ctx context.Context, in *ReleaseNonceRequest, opts ...grpc.CallOption) (*ReleaseNonceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseNonceResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_ReleaseNonce_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) GetNonceGaps(ctx context.Context, in *NonceGapsRequest, opts ...grpc.CallOption) (*NonceGapsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NonceGapsResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetNonceGaps_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletAccountServiceClient) GetUnspentOutputs(ctx context.Context, in *UnspentOutputsRequest, opts ...grpc.CallOption) (*UnspentOutputsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnspentOutputsResponse)
+	err := c.cc.Invoke(ctx, WalletAccountService_GetUnspentOutputs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // WalletAccountServiceServer is the server API for WalletAccountService service.
 // All implementations should embed UnimplementedWalletAccountServiceServer
 // for forward compatibility.
@@ -351,6 +495,18 @@ type WalletAccountServiceServer interface {
 	GetNftHolderList(context.Context, *NftHolderListRequest) (*NftHolderListResponse, error)
 	GetNftTradeHistory(context.Context, *NftTradeHistoryRequest) (*NftTradeHistoryResponse, error)
 	GetAddressNftTradeHistory(context.Context, *AddressNftTradeHistoryRequest) (*AddressNftTradeHistoryResponse, error)
+	WatchAddress(context.Context, *WatchAddressRequest) (*WatchAddressResponse, error)
+	GetDepositList(context.Context, *DepositListRequest) (*DepositListResponse, error)
+	GetDepositNotify(context.Context, *DepositNotifyRequest) (*DepositNotifyResponse, error)
+	AckDepositNotify(context.Context, *AckDepositNotifyRequest) (*AckDepositNotifyResponse, error)
+	GetWebhookDeadLetters(context.Context, *WebhookDeadLetterRequest) (*WebhookDeadLetterResponse, error)
+	ListOutgoingTx(context.Context, *OutgoingTxListRequest) (*OutgoingTxListResponse, error)
+	BuildSpeedUpTransaction(context.Context, *ReplaceTransactionRequest) (*ReplaceTransactionResponse, error)
+	BuildCancelTransaction(context.Context, *ReplaceTransactionRequest) (*ReplaceTransactionResponse, error)
+	GetNextNonce(context.Context, *NextNonceRequest) (*NextNonceResponse, error)
+	ReleaseNonce(context.Context, *ReleaseNonceRequest) (*ReleaseNonceResponse, error)
+	GetNonceGaps(context.Context, *NonceGapsRequest) (*NonceGapsResponse, error)
+	GetUnspentOutputs(context.Context, *UnspentOutputsRequest) (*UnspentOutputsResponse, error)
 }
 
 // UnimplementedWalletAccountServiceServer should be embedded to have
@@ -432,6 +588,42 @@ func (UnimplementedWalletAccountServiceServer) GetNftTradeHistory(context.Contex
 func (UnimplementedWalletAccountServiceServer) GetAddressNftTradeHistory(context.Context, *AddressNftTradeHistoryRequest) (*AddressNftTradeHistoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAddressNftTradeHistory not implemented")
 }
+func (UnimplementedWalletAccountServiceServer) WatchAddress(context.Context, *WatchAddressRequest) (*WatchAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WatchAddress not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) GetDepositList(context.Context, *DepositListRequest) (*DepositListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDepositList not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) GetDepositNotify(context.Context, *DepositNotifyRequest) (*DepositNotifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDepositNotify not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) AckDepositNotify(context.Context, *AckDepositNotifyRequest) (*AckDepositNotifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AckDepositNotify not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) GetWebhookDeadLetters(context.Context, *WebhookDeadLetterRequest) (*WebhookDeadLetterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWebhookDeadLetters not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) ListOutgoingTx(context.Context, *OutgoingTxListRequest) (*OutgoingTxListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOutgoingTx not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) BuildSpeedUpTransaction(context.Context, *ReplaceTransactionRequest) (*ReplaceTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuildSpeedUpTransaction not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) BuildCancelTransaction(context.Context, *ReplaceTransactionRequest) (*ReplaceTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BuildCancelTransaction not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) GetNextNonce(context.Context, *NextNonceRequest) (*NextNonceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNextNonce not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) ReleaseNonce(context.Context, *ReleaseNonceRequest) (*ReleaseNonceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseNonce not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) GetNonceGaps(context.Context, *NonceGapsRequest) (*NonceGapsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNonceGaps not implemented")
+}
+func (UnimplementedWalletAccountServiceServer) GetUnspentOutputs(context.Context, *UnspentOutputsRequest) (*UnspentOutputsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUnspentOutputs not implemented")
+}
 func (UnimplementedWalletAccountServiceServer) testEmbeddedByValue() {}
 
 // UnsafeWalletAccountServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -884,6 +1076,222 @@ func _WalletAccountService_GetAddressNftTradeHistory_Handler(srv interface{}, ct
 	return interceptor(ctx, in, info, handler)
 }
 
+func _WalletAccountService_WatchAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WatchAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).WatchAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_WatchAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).WatchAddress(ctx, req.(*WatchAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_GetDepositList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepositListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetDepositList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetDepositList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetDepositList(ctx, req.(*DepositListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_GetDepositNotify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepositNotifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetDepositNotify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetDepositNotify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetDepositNotify(ctx, req.(*DepositNotifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_AckDepositNotify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckDepositNotifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).AckDepositNotify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_AckDepositNotify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).AckDepositNotify(ctx, req.(*AckDepositNotifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_GetWebhookDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WebhookDeadLetterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetWebhookDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetWebhookDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetWebhookDeadLetters(ctx, req.(*WebhookDeadLetterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_ListOutgoingTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OutgoingTxListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).ListOutgoingTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_ListOutgoingTx_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).ListOutgoingTx(ctx, req.(*OutgoingTxListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_BuildSpeedUpTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplaceTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).BuildSpeedUpTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_BuildSpeedUpTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).BuildSpeedUpTransaction(ctx, req.(*ReplaceTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_BuildCancelTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplaceTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).BuildCancelTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_BuildCancelTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).BuildCancelTransaction(ctx, req.(*ReplaceTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_GetNextNonce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NextNonceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetNextNonce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetNextNonce_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetNextNonce(ctx, req.(*NextNonceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_ReleaseNonce_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseNonceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).ReleaseNonce(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_ReleaseNonce_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).ReleaseNonce(ctx, req.(*ReleaseNonceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_GetNonceGaps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NonceGapsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetNonceGaps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetNonceGaps_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetNonceGaps(ctx, req.(*NonceGapsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletAccountService_GetUnspentOutputs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnspentOutputsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletAccountServiceServer).GetUnspentOutputs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WalletAccountService_GetUnspentOutputs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletAccountServiceServer).GetUnspentOutputs(ctx, req.(*UnspentOutputsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // WalletAccountService_ServiceDesc is the grpc.ServiceDesc for WalletAccountService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -987,6 +1395,54 @@ var WalletAccountService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "getAddressNftTradeHistory",
 			Handler:    _WalletAccountService_GetAddressNftTradeHistory_Handler,
 		},
+		{
+			MethodName: "watchAddress",
+			Handler:    _WalletAccountService_WatchAddress_Handler,
+		},
+		{
+			MethodName: "getDepositList",
+			Handler:    _WalletAccountService_GetDepositList_Handler,
+		},
+		{
+			MethodName: "getDepositNotify",
+			Handler:    _WalletAccountService_GetDepositNotify_Handler,
+		},
+		{
+			MethodName: "ackDepositNotify",
+			Handler:    _WalletAccountService_AckDepositNotify_Handler,
+		},
+		{
+			MethodName: "getWebhookDeadLetters",
+			Handler:    _WalletAccountService_GetWebhookDeadLetters_Handler,
+		},
+		{
+			MethodName: "listOutgoingTx",
+			Handler:    _WalletAccountService_ListOutgoingTx_Handler,
+		},
+		{
+			MethodName: "buildSpeedUpTransaction",
+			Handler:    _WalletAccountService_BuildSpeedUpTransaction_Handler,
+		},
+		{
+			MethodName: "buildCancelTransaction",
+			Handler:    _WalletAccountService_BuildCancelTransaction_Handler,
+		},
+		{
+			MethodName: "getNextNonce",
+			Handler:    _WalletAccountService_GetNextNonce_Handler,
+		},
+		{
+			MethodName: "releaseNonce",
+			Handler:    _WalletAccountService_ReleaseNonce_Handler,
+		},
+		{
+			MethodName: "getNonceGaps",
+			Handler:    _WalletAccountService_GetNonceGaps_Handler,
+		},
+		{
+			MethodName: "getUnspentOutputs",
+			Handler:    _WalletAccountService_GetUnspentOutputs_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "dapplink/account.proto",